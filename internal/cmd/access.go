@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var accessCmd = &cobra.Command{
+	Use:   "access",
+	Short: "Manage resource access control",
+	Long: `Show and change who can access stacks and containers.
+
+Select the resource with --stack (ID or name) or --container (ID or name).
+Names and containers also require --endpoint.`,
+}
+
+var accessShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show access control for a resource",
+	Long:  `Display the ownership and user/team access of a stack or container.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newAccessClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		target, err := resolveAccessTarget(cmd, c)
+		if err != nil {
+			return err
+		}
+
+		format := getOutputFormat()
+
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			formatter := newFormatter(format)
+			return formatter.Format(target.control)
+
+		default:
+			fmt.Printf("Resource:    %s %s\n", target.kind, target.name)
+			rc := target.control
+			if rc == nil {
+				fmt.Printf("Access:      administrators only (no access control set)\n")
+				return nil
+			}
+
+			fmt.Printf("Access:      %s\n", accessLevelString(rc))
+			if !rc.Public && !rc.AdministratorsOnly {
+				fmt.Printf("Users:       %s\n", formatAccessNames(userNames(c), rc.UserIDs()))
+				fmt.Printf("Teams:       %s\n", formatAccessNames(teamNames(c), rc.TeamIDs()))
+			}
+			return nil
+		}
+	},
+}
+
+var accessSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set access control for a resource",
+	Long: `Change the ownership and user/team access of a stack or container.
+
+--user and --team accept names or IDs and replace the current lists. Settings
+that are not given are kept. For example:
+  portainer-cli access set --stack 12 --team devs --public=false
+  portainer-cli access set --container web --endpoint 1 --admin-only`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		if !flags.Changed("public") && !flags.Changed("admin-only") && !flags.Changed("user") && !flags.Changed("team") {
+			return fmt.Errorf("nothing to change: use --public, --admin-only, --user, or --team")
+		}
+
+		public, err := flags.GetBool("public")
+		if err != nil {
+			return err
+		}
+		adminOnly, err := flags.GetBool("admin-only")
+		if err != nil {
+			return err
+		}
+		if public && adminOnly {
+			return fmt.Errorf("--public and --admin-only cannot be used together")
+		}
+
+		userArgs, err := flags.GetStringArray("user")
+		if err != nil {
+			return err
+		}
+		teamArgs, err := flags.GetStringArray("team")
+		if err != nil {
+			return err
+		}
+
+		c, err := newAccessClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		target, err := resolveAccessTarget(cmd, c)
+		if err != nil {
+			return err
+		}
+
+		update := portainer.ResourceControlUpdateRequest{
+			Users: []int{},
+			Teams: []int{},
+		}
+		if rc := target.control; rc != nil {
+			update.Public = rc.Public
+			update.AdministratorsOnly = rc.AdministratorsOnly
+			update.Users = rc.UserIDs()
+			update.Teams = rc.TeamIDs()
+		}
+
+		// Granting users or teams implies restricted access unless the
+		// access level is given explicitly
+		if flags.Changed("user") || flags.Changed("team") {
+			update.Public = false
+			update.AdministratorsOnly = false
+		}
+		if flags.Changed("public") {
+			update.Public = public
+			if public {
+				update.AdministratorsOnly = false
+			}
+		}
+		if flags.Changed("admin-only") {
+			update.AdministratorsOnly = adminOnly
+			if adminOnly {
+				update.Public = false
+			}
+		}
+		if flags.Changed("user") {
+			update.Users, err = resolveUserIDs(c, userArgs)
+			if err != nil {
+				return err
+			}
+		}
+		if flags.Changed("team") {
+			update.Teams, err = resolveTeamIDs(c, teamArgs)
+			if err != nil {
+				return err
+			}
+		}
+
+		rcService := portainer.NewResourceControlService(c)
+		if target.control != nil {
+			if target.control.System {
+				return fmt.Errorf("access control of %s %s is managed by the system and cannot be changed", target.kind, target.name)
+			}
+			if _, err := rcService.Update(target.control.Id, &update); err != nil {
+				return err
+			}
+		} else {
+			if _, err := rcService.Create(&portainer.ResourceControlCreateRequest{
+				ResourceID:         target.resourceID,
+				Type:               target.resourceType,
+				Public:             update.Public,
+				AdministratorsOnly: update.AdministratorsOnly,
+				Users:              update.Users,
+				Teams:              update.Teams,
+				SubResourceIDs:     []string{},
+			}); err != nil {
+				return err
+			}
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Access control for %s %s updated\n", target.kind, target.name)
+		}
+
+		return nil
+	},
+}
+
+// accessTarget is the resource selected by --stack or --container
+type accessTarget struct {
+	kind         string
+	name         string
+	resourceID   string
+	resourceType int
+	control      *portainer.ResourceControl
+}
+
+func newAccessClient(cmd *cobra.Command) (*portainer.Client, error) {
+	profile, err := ResolveProfile(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return c, nil
+}
+
+func resolveAccessTarget(cmd *cobra.Command, c *portainer.Client) (*accessTarget, error) {
+	endpointID, err := cmd.Flags().GetInt("endpoint")
+	if err != nil {
+		return nil, err
+	}
+	stackArg, err := cmd.Flags().GetString("stack")
+	if err != nil {
+		return nil, err
+	}
+	containerArg, err := cmd.Flags().GetString("container")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case stackArg != "" && containerArg != "":
+		return nil, fmt.Errorf("--stack and --container cannot be used together")
+
+	case stackArg != "":
+		stack, err := resolveStack(portainer.NewStackService(c), stackArg, endpointID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &accessTarget{
+			kind:         "stack",
+			name:         stack.Name,
+			resourceID:   portainer.StackResourceID(stack.EndpointId, stack.Name),
+			resourceType: portainer.ResourceControlTypeStack,
+			control:      stack.ResourceControl,
+		}, nil
+
+	case containerArg != "":
+		if endpointID == 0 {
+			return nil, fmt.Errorf("--endpoint flag is required for containers")
+		}
+
+		containerService := portainer.NewContainerService(c)
+		container, err := containerService.Inspect(endpointID, containerArg)
+		if err != nil {
+			return nil, err
+		}
+
+		target := &accessTarget{
+			kind:         "container",
+			name:         strings.TrimPrefix(container.Name, "/"),
+			resourceID:   container.Id,
+			resourceType: portainer.ResourceControlTypeContainer,
+		}
+		if container.Portainer != nil {
+			target.control = container.Portainer.ResourceControl
+		}
+		return target, nil
+
+	default:
+		return nil, fmt.Errorf("specify a resource with --stack or --container")
+	}
+}
+
+func accessLevelString(rc *portainer.ResourceControl) string {
+	switch {
+	case rc.Public:
+		return "public"
+	case rc.AdministratorsOnly:
+		return "administrators only"
+	default:
+		return "restricted"
+	}
+}
+
+func resolveUserIDs(c *portainer.Client, args []string) ([]int, error) {
+	userService := portainer.NewUserService(c)
+	ids := []int{}
+	for _, arg := range args {
+		if id, err := strconv.Atoi(arg); err == nil {
+			ids = append(ids, id)
+			continue
+		}
+		user, err := userService.GetByUsername(arg)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, user.Id)
+	}
+	return ids, nil
+}
+
+func resolveTeamIDs(c *portainer.Client, args []string) ([]int, error) {
+	teamService := portainer.NewTeamService(c)
+	ids := []int{}
+	for _, arg := range args {
+		if id, err := strconv.Atoi(arg); err == nil {
+			ids = append(ids, id)
+			continue
+		}
+		team, err := teamService.GetByName(arg)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, team.Id)
+	}
+	return ids, nil
+}
+
+// userNames maps user IDs to names. Lookup failures, e.g. for non-admin
+// users, leave the map empty so IDs are shown instead.
+func userNames(c *portainer.Client) map[int]string {
+	names := map[int]string{}
+	users, err := portainer.NewUserService(c).List()
+	if err != nil {
+		return names
+	}
+	for _, user := range users {
+		names[user.Id] = user.Username
+	}
+	return names
+}
+
+func teamNames(c *portainer.Client) map[int]string {
+	names := map[int]string{}
+	teams, err := portainer.NewTeamService(c).List()
+	if err != nil {
+		return names
+	}
+	for _, team := range teams {
+		names[team.Id] = team.Name
+	}
+	return names
+}
+
+func formatAccessNames(names map[int]string, ids []int) string {
+	if len(ids) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := names[id]; ok {
+			parts = append(parts, name)
+		} else {
+			parts = append(parts, strconv.Itoa(id))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(accessCmd)
+	accessCmd.AddCommand(accessShowCmd)
+	accessCmd.AddCommand(accessSetCmd)
+
+	for _, cmd := range []*cobra.Command{accessShowCmd, accessSetCmd} {
+		cmd.Flags().String("stack", "", "Stack ID or name")
+		cmd.Flags().String("container", "", "Container ID or name")
+		cmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required for containers and stack names)")
+	}
+
+	accessSetCmd.Flags().Bool("public", false, "Allow all users to access the resource")
+	accessSetCmd.Flags().Bool("admin-only", false, "Restrict access to administrators")
+	accessSetCmd.Flags().StringArray("user", []string{}, "User name or ID to grant access (repeatable)")
+	accessSetCmd.Flags().StringArray("team", []string{}, "Team name or ID to grant access (repeatable)")
+}
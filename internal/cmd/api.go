@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var apiCmd = &cobra.Command{
+	Use:   "api <method> <path>",
+	Short: "Make an authenticated request to the Portainer API",
+	Long: `Send a request to any Portainer API path with the credentials of the current
+profile. Use it for API calls that have no dedicated command. Paths are
+relative to /api, as listed in the Portainer API reference.
+
+JSON responses are pretty-printed, or formatted with -o json|yaml and
+filtered with --query. Other responses are written as-is.
+
+Examples:
+  # Docker info of environment 1
+  portainer-cli api GET /endpoints/1/docker/info
+
+  # Query parameters
+  portainer-cli api GET /endpoints/1/docker/containers/json --param all=true
+
+  # Send a JSON body from a file, or from stdin with @-
+  portainer-cli api POST /endpoints/1/docker/networks/create --data @network.json
+
+  # Inline body and extra headers
+  portainer-cli api PUT /settings --data '{"SnapshotInterval":"10m"}' -H 'X-Custom: 1'`,
+	Args: cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return []string{"GET", "POST", "PUT", "PATCH", "DELETE"}, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		method := strings.ToUpper(args[0])
+
+		data, err := cmd.Flags().GetString("data")
+		if err != nil {
+			return err
+		}
+		headers, err := cmd.Flags().GetStringArray("header")
+		if err != nil {
+			return err
+		}
+		params, err := cmd.Flags().GetStringArray("param")
+		if err != nil {
+			return err
+		}
+		include, err := cmd.Flags().GetBool("include")
+		if err != nil {
+			return err
+		}
+
+		path, err := apiPath(args[1], params)
+		if err != nil {
+			return err
+		}
+
+		header, err := parseHeaders(headers)
+		if err != nil {
+			return err
+		}
+
+		body, err := readRequestData(data, os.Stdin)
+		if err != nil {
+			return err
+		}
+		if body != nil && header.Get("Content-Type") == "" {
+			header.Set("Content-Type", "application/json")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		resp, err := c.RawRequest(method, path, body, header)
+		if err != nil {
+			return err
+		}
+		if resp == nil {
+			return nil
+		}
+
+		if include {
+			printResponseHeaders(resp)
+		}
+
+		return printAPIResponse(resp)
+	},
+}
+
+// apiPath strips the leading slash and /api prefix from path and adds the
+// key=value query parameters
+func apiPath(path string, params []string) (string, error) {
+	path = strings.TrimPrefix(path, "/")
+	path = strings.TrimPrefix(path, "api/")
+
+	if len(params) == 0 {
+		return path, nil
+	}
+
+	parsed, err := neturl.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+
+	query := parsed.Query()
+	for _, param := range params {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok || key == "" {
+			return "", fmt.Errorf("invalid parameter %q, expected key=value", param)
+		}
+		query.Add(key, value)
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String(), nil
+}
+
+// parseHeaders parses "Key: Value" header flags
+func parseHeaders(headers []string) (http.Header, error) {
+	header := http.Header{}
+	for _, h := range headers {
+		key, value, ok := strings.Cut(h, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid header %q, expected 'Key: Value'", h)
+		}
+		header.Add(key, strings.TrimSpace(value))
+	}
+	return header, nil
+}
+
+// readRequestData returns the request body for --data: inline content,
+// @file to read a file, or @- to read stdin
+func readRequestData(data string, stdin io.Reader) ([]byte, error) {
+	switch {
+	case data == "":
+		return nil, nil
+	case data == "@-":
+		body, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body from stdin: %w", err)
+		}
+		return body, nil
+	case strings.HasPrefix(data, "@"):
+		body, err := os.ReadFile(data[1:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		return body, nil
+	default:
+		return []byte(data), nil
+	}
+}
+
+func printResponseHeaders(resp *portainer.RawResponse) {
+	fmt.Printf("HTTP %s\n", resp.Status)
+
+	keys := make([]string, 0, len(resp.Header))
+	for key := range resp.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range resp.Header[key] {
+			fmt.Printf("%s: %s\n", key, value)
+		}
+	}
+	fmt.Println()
+}
+
+// printAPIResponse formats JSON responses with the output flags and writes
+// any other content unchanged
+func printAPIResponse(resp *portainer.RawResponse) error {
+	if len(bytes.TrimSpace(resp.Body)) == 0 {
+		return nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(resp.Body, &data); err != nil {
+		_, err := os.Stdout.Write(resp.Body)
+		return err
+	}
+
+	format := getOutputFormat()
+	if format == output.FormatTable {
+		format = output.FormatJSON
+	}
+	return newFormatter(format).Format(data)
+}
+
+func init() {
+	rootCmd.AddCommand(apiCmd)
+
+	apiCmd.Flags().StringP("data", "d", "", "Request body: inline content, @file, or @- for stdin")
+	apiCmd.Flags().StringArrayP("header", "H", nil, "Additional request header as 'Key: Value' (repeatable)")
+	apiCmd.Flags().StringArrayP("param", "p", nil, "Query parameter as key=value (repeatable)")
+	apiCmd.Flags().BoolP("include", "i", false, "Print the response status and headers")
+}
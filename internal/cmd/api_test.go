@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAPIPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		params   []string
+		expected string
+		wantErr  bool
+	}{
+		{path: "/endpoints/1/docker/info", expected: "endpoints/1/docker/info"},
+		{path: "/api/status", expected: "status"},
+		{path: "stacks", params: []string{"filters={}"}, expected: "stacks?filters=%7B%7D"},
+		{path: "/endpoints?start=0", params: []string{"limit=10"}, expected: "endpoints?limit=10&start=0"},
+		{path: "status", params: []string{"novalue"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := apiPath(tt.path, tt.params)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("apiPath(%q, %v): expected error", tt.path, tt.params)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("apiPath(%q, %v): unexpected error: %v", tt.path, tt.params, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("apiPath(%q, %v) = %q, want %q", tt.path, tt.params, got, tt.expected)
+		}
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	header, err := parseHeaders([]string{"Content-Type: text/plain", "X-Trace:abc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if header.Get("Content-Type") != "text/plain" || header.Get("X-Trace") != "abc" {
+		t.Errorf("unexpected headers: %v", header)
+	}
+
+	if _, err := parseHeaders([]string{"missing-colon"}); err == nil {
+		t.Error("expected error for header without colon")
+	}
+}
+
+func TestReadRequestData(t *testing.T) {
+	body, err := readRequestData("@-", strings.NewReader(`{"a":1}`))
+	if err != nil || string(body) != `{"a":1}` {
+		t.Errorf("expected stdin body, got %q (%v)", body, err)
+	}
+
+	body, err = readRequestData(`{"b":2}`, nil)
+	if err != nil || string(body) != `{"b":2}` {
+		t.Errorf("expected inline body, got %q (%v)", body, err)
+	}
+
+	body, err = readRequestData("", nil)
+	if err != nil || body != nil {
+		t.Errorf("expected no body, got %q (%v)", body, err)
+	}
+
+	if _, err := readRequestData("@/nonexistent/file.json", nil); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit environment resources",
+	Long:  `Inspect the resources of an environment for cleanup opportunities.`,
+}
+
+var auditUnusedCmd = &cobra.Command{
+	Use:   "unused",
+	Short: "List unused images, volumes and networks",
+	Long: `Cross-reference the containers of an environment, stopped ones included,
+with its images, volumes and networks and list the resources that no
+container uses:
+
+  - images no container was created from
+  - volumes no container mounts
+  - user-defined networks no container is connected to
+
+The reclaimable space is the sum of the unused image and volume sizes. Image
+sizes include layers shared with other images, so the space actually freed
+can be lower.
+
+With --prune exactly the listed resources are removed, unlike the prune
+commands which also remove resources the audit keeps, such as tagged images
+of stopped containers. Swarm-scoped networks and the default bridge, host and
+none networks are never reported.
+
+Example:
+  portainer-cli audit unused --endpoint 1
+  portainer-cli audit unused --endpoint 1 --prune`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		prune, err := cmd.Flags().GetBool("prune")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		usage, err := portainer.NewHostService(c).DiskUsage(endpointID)
+		if err != nil {
+			return err
+		}
+		networks, err := portainer.NewNetworkService(c).List(endpointID)
+		if err != nil {
+			return err
+		}
+
+		unused := findUnusedResources(usage, networks)
+
+		if err := printUnusedResources(unused); err != nil {
+			return err
+		}
+
+		if !prune {
+			return nil
+		}
+		return pruneUnusedResources(c, endpointID, unused)
+	},
+}
+
+// unusedResources lists the resources of an environment that no container
+// uses
+type unusedResources struct {
+	Images   []unusedImage   `json:"Images"`
+	Volumes  []unusedVolume  `json:"Volumes"`
+	Networks []unusedNetwork `json:"Networks"`
+	// Reclaimable is the total size of the unused images and volumes
+	Reclaimable int64 `json:"Reclaimable"`
+}
+
+type unusedImage struct {
+	ID      string    `json:"ID"`
+	Tags    []string  `json:"Tags"`
+	Size    int64     `json:"Size"`
+	Created time.Time `json:"Created"`
+}
+
+type unusedVolume struct {
+	Name   string `json:"Name"`
+	Driver string `json:"Driver"`
+	// Size is -1 when the engine did not report it
+	Size int64 `json:"Size"`
+}
+
+type unusedNetwork struct {
+	ID     string `json:"ID"`
+	Name   string `json:"Name"`
+	Driver string `json:"Driver"`
+}
+
+// findUnusedResources cross-references the containers of usage with its
+// images and volumes and with networks
+func findUnusedResources(usage *portainer.DiskUsage, networks []portainer.Network) *unusedResources {
+	usedImages := map[string]bool{}
+	usedVolumes := map[string]bool{}
+	usedNetworks := map[string]bool{}
+	for _, container := range usage.Containers {
+		usedImages[container.ImageID] = true
+		for _, mount := range container.Mounts {
+			if mount.Type == "volume" {
+				usedVolumes[mount.Name] = true
+			}
+		}
+		usedNetworks[container.HostConfig.NetworkMode] = true
+		for name, settings := range container.NetworkSettings.Networks {
+			usedNetworks[name] = true
+			usedNetworks[settings.NetworkID] = true
+		}
+	}
+
+	unused := &unusedResources{
+		Images:   []unusedImage{},
+		Volumes:  []unusedVolume{},
+		Networks: []unusedNetwork{},
+	}
+
+	for _, image := range usage.Images {
+		if usedImages[image.Id] {
+			continue
+		}
+		tags := []string{}
+		for _, tag := range image.RepoTags {
+			if tag != "<none>:<none>" {
+				tags = append(tags, tag)
+			}
+		}
+		unused.Images = append(unused.Images, unusedImage{
+			ID:      image.Id,
+			Tags:    tags,
+			Size:    image.Size,
+			Created: time.Unix(image.Created, 0),
+		})
+		unused.Reclaimable += image.Size
+	}
+
+	for _, volume := range usage.Volumes {
+		size := int64(-1)
+		if volume.UsageData != nil {
+			if volume.UsageData.RefCount > 0 {
+				continue
+			}
+			size = volume.UsageData.Size
+		}
+		if usedVolumes[volume.Name] {
+			continue
+		}
+		unused.Volumes = append(unused.Volumes, unusedVolume{
+			Name:   volume.Name,
+			Driver: volume.Driver,
+			Size:   size,
+		})
+		if size > 0 {
+			unused.Reclaimable += size
+		}
+	}
+
+	for _, network := range networks {
+		switch {
+		case network.Name == "bridge" || network.Name == "host" || network.Name == "none":
+			continue
+		case network.Ingress || network.Scope == "swarm":
+			// Containers on other nodes may use swarm networks
+			continue
+		case usedNetworks[network.Id] || usedNetworks[network.Name]:
+			continue
+		}
+		unused.Networks = append(unused.Networks, unusedNetwork{
+			ID:     network.Id,
+			Name:   network.Name,
+			Driver: network.Driver,
+		})
+	}
+
+	sort.Slice(unused.Images, func(i, j int) bool {
+		return unused.Images[i].Size > unused.Images[j].Size
+	})
+	sort.Slice(unused.Volumes, func(i, j int) bool {
+		return unused.Volumes[i].Size > unused.Volumes[j].Size
+	})
+	sort.Slice(unused.Networks, func(i, j int) bool {
+		return unused.Networks[i].Name < unused.Networks[j].Name
+	})
+
+	return unused
+}
+
+func printUnusedResources(unused *unusedResources) error {
+	format := getOutputFormat()
+	switch format {
+	case output.FormatJSON, output.FormatYAML:
+		return newFormatter(format).Format(unused)
+	}
+
+	if GetQuiet() {
+		for _, image := range unused.Images {
+			fmt.Println(image.ID)
+		}
+		for _, volume := range unused.Volumes {
+			fmt.Println(volume.Name)
+		}
+		for _, network := range unused.Networks {
+			fmt.Println(network.ID)
+		}
+		return nil
+	}
+
+	if len(unused.Images) > 0 {
+		fmt.Println("Unused images:")
+		table := output.NewTableData([]string{"ID", "Tags", "Size", "Created"})
+		for _, image := range unused.Images {
+			tags := "<none>"
+			if len(image.Tags) > 0 {
+				tags = output.TruncateString(strings.Join(image.Tags, ", "), 60)
+			}
+			id := strings.TrimPrefix(image.ID, "sha256:")
+			if len(id) > 12 {
+				id = id[:12]
+			}
+			table.AddRow([]string{
+				id,
+				tags,
+				output.FormatSize(image.Size),
+				output.FormatDuration(int64(time.Since(image.Created).Seconds())),
+			})
+		}
+		if err := output.PrintTable(*table); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+
+	if len(unused.Volumes) > 0 {
+		fmt.Println("Unused volumes:")
+		table := output.NewTableData([]string{"Name", "Driver", "Size"})
+		for _, volume := range unused.Volumes {
+			size := "-"
+			if volume.Size >= 0 {
+				size = output.FormatSize(volume.Size)
+			}
+			table.AddRow([]string{volume.Name, volume.Driver, size})
+		}
+		if err := output.PrintTable(*table); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+
+	if len(unused.Networks) > 0 {
+		fmt.Println("Unused networks:")
+		table := output.NewTableData([]string{"ID", "Name", "Driver"})
+		for _, network := range unused.Networks {
+			id := network.ID
+			if len(id) > 12 {
+				id = id[:12]
+			}
+			table.AddRow([]string{id, network.Name, network.Driver})
+		}
+		if err := output.PrintTable(*table); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%d unused images, %d unused volumes, %d unused networks\n",
+		len(unused.Images), len(unused.Volumes), len(unused.Networks))
+	fmt.Printf("Total reclaimable space: %s\n", output.FormatSize(unused.Reclaimable))
+	return nil
+}
+
+// pruneUnusedResources removes the resources found by the audit. Failures
+// are reported and do not stop the removal of the other resources.
+func pruneUnusedResources(c *portainer.Client, endpointID int, unused *unusedResources) error {
+	imageService := portainer.NewImageService(c)
+	volumeService := portainer.NewVolumeService(c)
+	networkService := portainer.NewNetworkService(c)
+
+	failed := 0
+	removed := 0
+	report := func(kind, name string, err error) {
+		if err != nil {
+			failed++
+			fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("failed to remove %s %s: %v", kind, name, err)))
+			return
+		}
+		removed++
+	}
+
+	// The images have no containers, so forcing only untags images with
+	// several tags
+	for _, image := range unused.Images {
+		report("image", image.ID, imageService.Remove(endpointID, image.ID, true))
+	}
+	for _, volume := range unused.Volumes {
+		report("volume", volume.Name, volumeService.Remove(endpointID, volume.Name, false))
+	}
+	for _, network := range unused.Networks {
+		report("network", network.Name, networkService.Remove(endpointID, network.ID))
+	}
+
+	if !GetQuiet() && !GetDryRun() && getOutputFormat() == output.FormatTable {
+		fmt.Printf("Removed %d unused resources\n", removed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %d resources", failed)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditUnusedCmd)
+
+	auditUnusedCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	auditUnusedCmd.Flags().Bool("prune", false, "Remove the unused resources that were found")
+	_ = auditUnusedCmd.MarkFlagRequired("endpoint")
+}
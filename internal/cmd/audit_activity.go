@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+// formatCSV is the extra --output format of the activity commands
+const formatCSV = output.Format("csv")
+
+var auditActivityCmd = &cobra.Command{
+	Use:   "activity",
+	Short: "Show the user activity log",
+	Long: `Show the API calls users made, such as deploying stacks or removing
+containers, from the activity log of Portainer Business Edition.
+
+Use -o csv or -o json with --file to export the entries as audit evidence.
+
+Example:
+  portainer-cli audit activity --since 24h --user alice
+  portainer-cli audit activity --since 2024-01-01 --until 2024-02-01 -o csv --file january.csv`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := getActivityFilter(cmd)
+		if err != nil {
+			return err
+		}
+		keyword, err := cmd.Flags().GetString("keyword")
+		if err != nil {
+			return err
+		}
+		if keyword != "" {
+			filter.opts.Keyword = keyword
+		}
+
+		c, err := newActivityClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		logs, err := portainer.NewActivityService(c).ListUserActivity(filter.opts)
+		if err != nil {
+			return err
+		}
+
+		records := [][]string{}
+		entries := []activityEntry{}
+		for _, log := range logs {
+			if filter.user != "" && !strings.EqualFold(log.Username, filter.user) {
+				continue
+			}
+			entry := activityEntry{
+				Time:    formatActivityTime(log.Timestamp),
+				User:    log.Username,
+				Context: log.Context,
+				Action:  log.Action,
+				Payload: string(log.Payload),
+			}
+			entries = append(entries, entry)
+			records = append(records, []string{entry.Time, entry.User, entry.Context, entry.Action, entry.Payload})
+		}
+
+		return writeActivity(cmd, entries, []string{"Time", "User", "Context", "Action", "Payload"}, records)
+	},
+}
+
+var auditAuthCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Show the authentication log",
+	Long: `Show logins, failed logins and logouts from the authentication log of
+Portainer Business Edition.
+
+Use -o csv or -o json with --file to export the entries as audit evidence.
+
+Example:
+  portainer-cli audit auth --since 7d --type failed
+  portainer-cli audit auth --since 24h --user alice -o json --file alice.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := getActivityFilter(cmd)
+		if err != nil {
+			return err
+		}
+		typeArg, err := cmd.Flags().GetString("type")
+		if err != nil {
+			return err
+		}
+		authType := 0
+		switch typeArg {
+		case "":
+		case "login":
+			authType = portainer.AuthActivitySuccess
+		case "failed":
+			authType = portainer.AuthActivityFailure
+		case "logout":
+			authType = portainer.AuthActivityLogout
+		default:
+			return fmt.Errorf("invalid --type %q (expected login, failed or logout)", typeArg)
+		}
+
+		c, err := newActivityClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		logs, err := portainer.NewActivityService(c).ListAuthActivity(filter.opts)
+		if err != nil {
+			return err
+		}
+
+		records := [][]string{}
+		entries := []authEntry{}
+		for _, log := range logs {
+			if filter.user != "" && !strings.EqualFold(log.Username, filter.user) {
+				continue
+			}
+			if authType != 0 && log.Type != authType {
+				continue
+			}
+			entry := authEntry{
+				Time:   formatActivityTime(log.Timestamp),
+				User:   log.Username,
+				Type:   log.TypeString(),
+				Method: log.ContextString(),
+				Origin: log.Origin,
+			}
+			entries = append(entries, entry)
+			records = append(records, []string{entry.Time, entry.User, entry.Type, entry.Method, entry.Origin})
+		}
+
+		return writeActivity(cmd, entries, []string{"Time", "User", "Type", "Method", "Origin"}, records)
+	},
+}
+
+// activityEntry is a user activity log entry as exported
+type activityEntry struct {
+	Time    string `json:"Time"`
+	User    string `json:"User"`
+	Context string `json:"Context"`
+	Action  string `json:"Action"`
+	Payload string `json:"Payload,omitempty"`
+}
+
+// authEntry is an authentication log entry as exported
+type authEntry struct {
+	Time   string `json:"Time"`
+	User   string `json:"User"`
+	Type   string `json:"Type"`
+	Method string `json:"Method"`
+	Origin string `json:"Origin"`
+}
+
+// activityFilter holds the flags shared by the activity commands
+type activityFilter struct {
+	opts portainer.ActivityLogOptions
+	user string
+}
+
+func getActivityFilter(cmd *cobra.Command) (*activityFilter, error) {
+	since, err := cmd.Flags().GetString("since")
+	if err != nil {
+		return nil, err
+	}
+	until, err := cmd.Flags().GetString("until")
+	if err != nil {
+		return nil, err
+	}
+	user, err := cmd.Flags().GetString("user")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	filter := &activityFilter{user: user}
+	if since != "" {
+		if filter.opts.After, err = parseTimeFlag(since, now); err != nil {
+			return nil, fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if until != "" {
+		if filter.opts.Before, err = parseTimeFlag(until, now); err != nil {
+			return nil, fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+	// The server search narrows the entries down before the exact match
+	filter.opts.Keyword = user
+
+	if _, err := activityFormat(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// parseTimeFlag parses a point in time given as a duration before now, such
+// as 30m, 24h or 7d, as a date (2006-01-02) or as an RFC 3339 timestamp
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration (e.g. 24h, 7d), date or RFC 3339 time", value)
+}
+
+// activityFormat maps the global --output flag to a format of the activity
+// commands, which add csv to the usual formats
+func activityFormat() (output.Format, error) {
+	switch strings.ToLower(outputFormat) {
+	case "csv":
+		if queryExpr != "" {
+			return "", fmt.Errorf("--query is not supported with csv output")
+		}
+		return formatCSV, nil
+	case "", "table", "json", "yaml", "yml":
+		return getOutputFormat(), nil
+	}
+	return "", fmt.Errorf("unsupported output format %q (supported: table, json, yaml, csv)", outputFormat)
+}
+
+func newActivityClient(cmd *cobra.Command) (*portainer.Client, error) {
+	profile, err := ResolveProfile(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return c, nil
+}
+
+// writeActivity writes the entries in the --output format to stdout or the
+// --file flag. records holds the table and CSV rows of the entries.
+func writeActivity(cmd *cobra.Command, entries interface{}, headers []string, records [][]string) error {
+	file, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return err
+	}
+	format, err := activityFormat()
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch format {
+	case formatCSV:
+		err = writeCSV(w, headers, records)
+	case output.FormatJSON, output.FormatYAML:
+		err = output.NewFormatter(output.Options{Format: format, Writer: w, Query: queryExpr}).Format(entries)
+	default:
+		table := output.NewTableData(headers)
+		for _, record := range records {
+			// Long values such as request payloads are truncated
+			row := make([]string, len(record))
+			for i, value := range record {
+				row[i] = output.TruncateString(value, 60)
+			}
+			table.AddRow(row)
+		}
+		err = output.NewFormatter(output.Options{Format: output.FormatTable, Writer: w}).Format(*table)
+	}
+	if err != nil {
+		return err
+	}
+
+	if file != "" && !GetQuiet() {
+		fmt.Fprintf(os.Stderr, "%d entries written to %s\n", len(records), file)
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, headers []string, records [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+func formatActivityTime(timestamp int64) string {
+	return time.Unix(timestamp, 0).UTC().Format(time.RFC3339)
+}
+
+func init() {
+	auditCmd.AddCommand(auditActivityCmd)
+	auditCmd.AddCommand(auditAuthCmd)
+
+	for _, cmd := range []*cobra.Command{auditActivityCmd, auditAuthCmd} {
+		cmd.Flags().String("since", "", "Show entries after this time (e.g. 24h, 7d, 2024-01-31 or an RFC 3339 time)")
+		cmd.Flags().String("until", "", "Show entries before this time")
+		cmd.Flags().String("user", "", "Show only the entries of this username")
+		cmd.Flags().String("file", "", "Write the entries to a file instead of stdout")
+	}
+	auditActivityCmd.Flags().String("keyword", "", "Show only entries containing this text")
+	auditAuthCmd.Flags().String("type", "", "Show only this type of event (login, failed, logout)")
+}
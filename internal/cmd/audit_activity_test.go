@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestParseTimeFlag(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]time.Time{
+		"24h":                  now.Add(-24 * time.Hour),
+		"7d":                   now.AddDate(0, 0, -7),
+		"90m":                  now.Add(-90 * time.Minute),
+		"2024-01-31":           time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local),
+		"2024-02-01T08:00:00Z": time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC),
+	}
+	for value, expected := range tests {
+		got, err := parseTimeFlag(value, now)
+		if err != nil || !got.Equal(expected) {
+			t.Errorf("%s: expected %v, got %v (%v)", value, expected, got, err)
+		}
+	}
+
+	for _, value := range []string{"yesterday", "-d", "31/01/2024"} {
+		if _, err := parseTimeFlag(value, now); err == nil {
+			t.Errorf("%s: expected an error", value)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	records := [][]string{{"2024-01-31T10:00:00Z", "alice", `{"Name":"web, api"}`}}
+	if err := writeCSV(&buf, []string{"Time", "User", "Payload"}, records); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "Time,User,Payload\n2024-01-31T10:00:00Z,alice,\"{\"\"Name\"\":\"\"web, api\"\"}\"\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestFindUnusedResources(t *testing.T) {
+	usage := &portainer.DiskUsage{
+		Containers: []portainer.Container{
+			{
+				ImageID: "sha256:web",
+				Mounts:  []portainer.Mount{{Type: "volume", Name: "webdata"}, {Type: "bind", Name: "", Source: "/srv"}},
+				NetworkSettings: portainer.NetworkSettings{Networks: map[string]portainer.EndpointSettings{
+					"frontend": {NetworkID: "net1"},
+				}},
+			},
+		},
+		Images: []portainer.Image{
+			{Id: "sha256:web", RepoTags: []string{"web:latest"}, Size: 100},
+			{Id: "sha256:old", RepoTags: []string{"web:1.0"}, Size: 80},
+			{Id: "sha256:dangling", RepoTags: []string{"<none>:<none>"}, Size: 20},
+		},
+		Volumes: []portainer.VolumeDetails{
+			{Name: "webdata", UsageData: &portainer.VolumeUsageData{Size: 500, RefCount: 1}},
+			{Name: "orphan", UsageData: &portainer.VolumeUsageData{Size: 300, RefCount: 0}},
+			{Name: "unknown"},
+		},
+	}
+	networks := []portainer.Network{
+		{Id: "net0", Name: "bridge"},
+		{Id: "net1", Name: "frontend"},
+		{Id: "net2", Name: "backend", Driver: "bridge"},
+		{Id: "net3", Name: "overlay", Scope: "swarm"},
+	}
+
+	unused := findUnusedResources(usage, networks)
+
+	if len(unused.Images) != 2 || unused.Images[0].ID != "sha256:old" || len(unused.Images[1].Tags) != 0 {
+		t.Errorf("unexpected unused images: %+v", unused.Images)
+	}
+	if len(unused.Volumes) != 2 || unused.Volumes[0].Name != "orphan" || unused.Volumes[1].Size != -1 {
+		t.Errorf("unexpected unused volumes: %+v", unused.Volumes)
+	}
+	if len(unused.Networks) != 1 || unused.Networks[0].Name != "backend" {
+		t.Errorf("unexpected unused networks: %+v", unused.Networks)
+	}
+	if unused.Reclaimable != 80+20+300 {
+		t.Errorf("expected 400 bytes reclaimable, got %d", unused.Reclaimable)
+	}
+}
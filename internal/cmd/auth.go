@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"syscall"
 
-	"github.com/robversluis/portainer-cli/internal/client"
 	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
@@ -52,7 +52,7 @@ The JWT token will be stored in the current profile for future use.`,
 			return fmt.Errorf("username and password are required")
 		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
@@ -61,7 +61,7 @@ The JWT token will be stored in the current profile for future use.`,
 			fmt.Printf("Logging in to %s as %s...\n", profile.URL, username)
 		}
 
-		token, err := client.LoginAndSaveToken(profile, username, password)
+		token, err := loginAndSaveToken(profile, username, password)
 		if err != nil {
 			return fmt.Errorf("login failed: %w", err)
 		}
@@ -77,6 +77,47 @@ The JWT token will be stored in the current profile for future use.`,
 	},
 }
 
+// loginAndSaveToken logs in and stores the JWT and username in the profile
+// the connection settings were resolved from
+func loginAndSaveToken(profile *config.Profile, username, password string) (string, error) {
+	c, err := portainer.NewClient(profile.ClientConfig(), portainer.WithVerbose(false))
+	if err != nil {
+		return "", err
+	}
+
+	token, err := portainer.NewAuthService(c).Login(username, password)
+	if err != nil {
+		return "", err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return token, fmt.Errorf("logged in but failed to load config: %w", err)
+	}
+
+	profileName := profile.Name
+	if profileName == "" {
+		profileName = cfg.CurrentProfile
+	}
+	if profileName == "" {
+		return token, fmt.Errorf("logged in but no current profile set")
+	}
+
+	storedProfile, err := cfg.GetProfile(profileName)
+	if err != nil {
+		return token, fmt.Errorf("logged in but failed to get profile: %w", err)
+	}
+
+	storedProfile.Token = token
+	storedProfile.Username = username
+
+	if err := cfg.Save(); err != nil {
+		return token, fmt.Errorf("logged in but failed to save token: %w", err)
+	}
+
+	return token, nil
+}
+
 var authLogoutCmd = &cobra.Command{
 	Use:   "logout",
 	Short: "Logout from Portainer",
@@ -87,7 +128,10 @@ var authLogoutCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		profileName := cfg.CurrentProfile
+		profileName := flagOrEnv(cmd, "profile", "profile")
+		if profileName == "" {
+			profileName = cfg.CurrentProfile
+		}
 		if profileName == "" {
 			return fmt.Errorf("no current profile set")
 		}
@@ -116,17 +160,17 @@ var authStatusCmd = &cobra.Command{
 	Short: "Check authentication status",
 	Long:  `Display current authentication status and validate credentials.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		authService := client.NewAuthService(c)
+		authService := portainer.NewAuthService(c)
 
 		status, err := authService.GetStatus()
 		if err != nil {
@@ -1,13 +1,23 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/terminal"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var configCmd = &cobra.Command{
@@ -52,6 +62,10 @@ var configSetCmd = &cobra.Command{
 Examples:
   portainer-cli config set url https://portainer.example.com
   portainer-cli config set api_key YOUR_API_KEY
+  portainer-cli config set endpoint 3
+  portainer-cli config set registry_mirror mirror.internal/hub
+  portainer-cli config set retries 5
+  portainer-cli config set retry_delay 500ms
   portainer-cli config set --profile prod url https://prod.example.com`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -93,6 +107,27 @@ Examples:
 			profile.Token = value
 		case "insecure":
 			profile.Insecure = strings.ToLower(value) == "true"
+		case "endpoint":
+			id, err := strconv.Atoi(value)
+			if err != nil || id < 0 {
+				return fmt.Errorf("invalid endpoint ID: %s", value)
+			}
+			profile.Endpoint = id
+		case "registry_mirror":
+			profile.RegistryMirror = value
+		case "image_prefix":
+			profile.ImagePrefix = value
+		case "retries":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid number of retries: %s", value)
+			}
+			profile.Retries = &n
+		case "retry_delay":
+			if d, err := time.ParseDuration(value); err != nil || d < 0 {
+				return fmt.Errorf("invalid retry delay: %s (e.g. 500ms or 2s)", value)
+			}
+			profile.RetryDelay = value
 		default:
 			return fmt.Errorf("unknown configuration key: %s", key)
 		}
@@ -146,6 +181,21 @@ Examples:
 			fmt.Printf("Username: %s\n", profile.Username)
 			fmt.Printf("Token: %s\n", maskSecret(profile.Token))
 			fmt.Printf("Insecure: %t\n", profile.Insecure)
+			if profile.Endpoint != 0 {
+				fmt.Printf("Default Environment: %d\n", profile.Endpoint)
+			}
+			if profile.RegistryMirror != "" {
+				fmt.Printf("Registry Mirror: %s\n", profile.RegistryMirror)
+			}
+			if profile.ImagePrefix != "" {
+				fmt.Printf("Image Prefix: %s\n", profile.ImagePrefix)
+			}
+			if profile.Retries != nil {
+				fmt.Printf("Retries: %d\n", *profile.Retries)
+			}
+			if profile.RetryDelay != "" {
+				fmt.Printf("Retry Delay: %s\n", profile.RetryDelay)
+			}
 		} else {
 			key := args[0]
 			switch key {
@@ -159,6 +209,18 @@ Examples:
 				fmt.Println(profile.Token)
 			case "insecure":
 				fmt.Println(profile.Insecure)
+			case "endpoint":
+				fmt.Println(profile.Endpoint)
+			case "registry_mirror":
+				fmt.Println(profile.RegistryMirror)
+			case "image_prefix":
+				fmt.Println(profile.ImagePrefix)
+			case "retries":
+				if profile.Retries != nil {
+					fmt.Println(*profile.Retries)
+				}
+			case "retry_delay":
+				fmt.Println(profile.RetryDelay)
 			default:
 				return fmt.Errorf("unknown configuration key: %s", key)
 			}
@@ -219,24 +281,27 @@ var configUseProfileCmd = &cobra.Command{
 	Long:  `Set the specified profile as the current/default profile.`,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		profileName := args[0]
-
 		cfg, err := config.Load()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		if err := cfg.SetCurrentProfile(profileName); err != nil {
-			return err
-		}
+		return switchProfile(cfg, args[0])
+	},
+}
 
-		if err := cfg.Save(); err != nil {
-			return fmt.Errorf("failed to save config: %w", err)
-		}
+// switchProfile makes name the current profile and saves the config
+func switchProfile(cfg *config.Config, name string) error {
+	if err := cfg.SetCurrentProfile(name); err != nil {
+		return err
+	}
 
-		fmt.Printf("Switched to profile '%s'\n", profileName)
-		return nil
-	},
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Switched to profile '%s'\n", name)
+	return nil
 }
 
 var configCreateProfileCmd = &cobra.Command{
@@ -345,6 +410,248 @@ var configViewCmd = &cobra.Command{
 	},
 }
 
+var configExportCmd = &cobra.Command{
+	Use:   "export [profile...]",
+	Short: "Export profiles to a shareable bundle",
+	Long: `Export profiles, or all profiles when none are named, to a YAML bundle that
+can be shared and loaded with 'config import'.
+
+API keys and tokens are left out unless --include-secrets is given.
+
+Examples:
+  portainer-cli config export --file team-profiles.yaml
+  portainer-cli config export production staging --file team-profiles.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			return err
+		}
+		includeSecrets, err := cmd.Flags().GetBool("include-secrets")
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		bundle, err := cfg.Export(args, includeSecrets)
+		if err != nil {
+			return err
+		}
+
+		data, err := yaml.Marshal(bundle)
+		if err != nil {
+			return fmt.Errorf("failed to marshal bundle: %w", err)
+		}
+
+		if file == "" || file == "-" {
+			fmt.Print(string(data))
+			return nil
+		}
+
+		perm := os.FileMode(0644)
+		if includeSecrets {
+			perm = 0600
+		}
+		if err := os.WriteFile(file, data, perm); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Exported %d profile(s) to %s\n", len(bundle.Profiles), file)
+		}
+		return nil
+	},
+}
+
+var configImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import profiles from a bundle",
+	Long: `Merge the profiles of a bundle created with 'config export' into the local
+configuration.
+
+Profiles that already exist are skipped by default. Use --on-conflict
+overwrite to replace them, keeping local API keys and tokens when the bundle
+has none, or --on-conflict rename to import them under a new name.
+
+Examples:
+  portainer-cli config import --file team-profiles.yaml
+  portainer-cli config import --file team-profiles.yaml --on-conflict overwrite`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			return err
+		}
+		onConflict, err := cmd.Flags().GetString("on-conflict")
+		if err != nil {
+			return err
+		}
+
+		bundle, err := config.LoadBundle(file)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		result, err := cfg.Import(bundle, onConflict)
+		if err != nil {
+			return err
+		}
+
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+
+		if !GetQuiet() {
+			for _, name := range result.Added {
+				fmt.Printf("Added profile '%s'\n", name)
+			}
+			for _, name := range result.Updated {
+				fmt.Printf("Updated profile '%s'\n", name)
+			}
+			for _, name := range sortedKeys(result.Renamed) {
+				fmt.Printf("Imported profile '%s' as '%s'\n", name, result.Renamed[name])
+			}
+			for _, name := range result.Skipped {
+				fmt.Printf("Skipped existing profile '%s'\n", name)
+			}
+		}
+		return nil
+	},
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the configuration file",
+	Long: `Open the configuration file in your editor ($VISUAL or $EDITOR).
+
+The edited file is validated before it is saved: unknown keys, profiles
+without a URL, and invalid values are reported with their line numbers, and
+the configuration is only replaced once it is valid.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, err := config.GetConfigPath()
+		if err != nil {
+			return err
+		}
+
+		original, err := os.ReadFile(configPath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read config: %w", err)
+		}
+
+		if err := config.EnsureConfigDir(); err != nil {
+			return err
+		}
+
+		// Edit a copy so an invalid result never replaces the config
+		tmp, err := os.CreateTemp(filepath.Dir(configPath), "config-*.yaml")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary file: %w", err)
+		}
+		defer os.Remove(tmp.Name())
+
+		if _, err := tmp.Write(original); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write temporary file: %w", err)
+		}
+		if err := tmp.Close(); err != nil {
+			return fmt.Errorf("failed to write temporary file: %w", err)
+		}
+
+		for {
+			if err := runEditor(tmp.Name()); err != nil {
+				return err
+			}
+
+			edited, err := os.ReadFile(tmp.Name())
+			if err != nil {
+				return fmt.Errorf("failed to read edited config: %w", err)
+			}
+
+			if bytes.Equal(edited, original) {
+				if !GetQuiet() {
+					fmt.Println("No changes made")
+				}
+				return nil
+			}
+
+			errs := config.Validate(edited)
+			if len(errs) == 0 {
+				if err := os.WriteFile(configPath, edited, 0600); err != nil {
+					return fmt.Errorf("failed to write config: %w", err)
+				}
+				if !GetQuiet() {
+					fmt.Printf("Configuration saved to %s\n", configPath)
+				}
+				return nil
+			}
+
+			fmt.Fprintf(os.Stderr, "The configuration is invalid:\n")
+			for _, e := range errs {
+				fmt.Fprintf(os.Stderr, "  %s: %s\n", filepath.Base(configPath), e.Error())
+			}
+
+			if !terminal.IsTerminal(os.Stdin) || !promptYesNo("Edit again?") {
+				return fmt.Errorf("configuration not saved: %d error(s)", len(errs))
+			}
+		}
+	},
+}
+
+// runEditor opens path in the user's editor and waits for it to exit
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	// Editors are often configured with arguments, e.g. "code --wait"
+	parts := strings.Fields(editor)
+	editorCmd := exec.Command(parts[0], append(parts[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", parts[0], err)
+	}
+	return nil
+}
+
+// promptYesNo asks a question on the terminal, defaulting to yes
+func promptYesNo(question string) bool {
+	fmt.Printf("%s [Y/n] ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
 func maskSecret(secret string) string {
 	if secret == "" {
 		return ""
@@ -365,6 +672,9 @@ func init() {
 	configCmd.AddCommand(configCreateProfileCmd)
 	configCmd.AddCommand(configDeleteProfileCmd)
 	configCmd.AddCommand(configViewCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
 
 	configSetCmd.Flags().String("profile", "", "Profile to modify")
 	configGetCmd.Flags().String("profile", "", "Profile to view")
@@ -373,4 +683,11 @@ func init() {
 	configCreateProfileCmd.Flags().String("api-key", "", "API key")
 	configCreateProfileCmd.Flags().String("username", "", "Username")
 	configCreateProfileCmd.Flags().Bool("insecure", false, "Skip TLS verification")
+
+	configExportCmd.Flags().String("file", "", "Bundle file to write (default: stdout)")
+	configExportCmd.Flags().Bool("include-secrets", false, "Include API keys and tokens")
+
+	configImportCmd.Flags().String("file", "", "Bundle file to import (required)")
+	configImportCmd.Flags().String("on-conflict", config.ConflictSkip, "How to handle existing profiles (skip, overwrite, rename)")
+	_ = configImportCmd.MarkFlagRequired("file")
 }
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var configSetTargetCmd = &cobra.Command{
+	Use:   "set-target <name> <profile> [endpoint]",
+	Short: "Define a named target",
+	Long: `Define a target: a name for a profile and, optionally, an environment of it.
+Commands run with --target <name> use both, instead of --profile and
+--endpoint, so runbooks can name where they act in one word.
+
+Examples:
+  portainer-cli config set-target prod-web prod 4
+  portainer-cli --target prod-web containers list`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := &config.Target{Profile: args[1]}
+		if len(args) == 3 {
+			id, err := strconv.Atoi(args[2])
+			if err != nil || id < 1 {
+				return fmt.Errorf("invalid endpoint ID: %s", args[2])
+			}
+			target.Endpoint = id
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if err := cfg.SetTarget(args[0], target); err != nil {
+			return err
+		}
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Target '%s' set to %s\n", args[0], describeTarget(target))
+		}
+		return nil
+	},
+}
+
+var configDeleteTargetCmd = &cobra.Command{
+	Use:   "delete-target <name>",
+	Short: "Delete a named target",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if err := cfg.DeleteTarget(args[0]); err != nil {
+			return err
+		}
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Target '%s' deleted\n", args[0])
+		}
+		return nil
+	},
+}
+
+var configListTargetsCmd = &cobra.Command{
+	Use:     "list-targets",
+	Aliases: []string{"targets"},
+	Short:   "List named targets",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			targets := cfg.Targets
+			if targets == nil {
+				targets = map[string]*config.Target{}
+			}
+			return newFormatter(format).Format(targets)
+		}
+
+		if len(cfg.Targets) == 0 {
+			fmt.Println("No targets configured")
+			return nil
+		}
+
+		names := make([]string, 0, len(cfg.Targets))
+		for name := range cfg.Targets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Target", "Profile", "Endpoint"})
+		table.SetBorder(false)
+		table.SetColumnSeparator("")
+		table.SetHeaderLine(false)
+
+		for _, name := range names {
+			target := cfg.Targets[name]
+			endpoint := "-"
+			if target.Endpoint != 0 {
+				endpoint = strconv.Itoa(target.Endpoint)
+			}
+			table.Append([]string{name, target.Profile, endpoint})
+		}
+
+		table.Render()
+		return nil
+	},
+}
+
+// describeTarget names the profile and environment of a target
+func describeTarget(target *config.Target) string {
+	if target.Endpoint == 0 {
+		return fmt.Sprintf("profile '%s'", target.Profile)
+	}
+	return fmt.Sprintf("profile '%s', endpoint %d", target.Profile, target.Endpoint)
+}
+
+func init() {
+	configCmd.AddCommand(configSetTargetCmd)
+	configCmd.AddCommand(configDeleteTargetCmd)
+	configCmd.AddCommand(configListTargetsCmd)
+}
@@ -1,19 +1,23 @@
 package cmd
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
+	"regexp"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/robversluis/portainer-cli/internal/client"
-	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/logfile"
+	"github.com/robversluis/portainer-cli/internal/logstream"
+	"github.com/robversluis/portainer-cli/internal/notify"
 	"github.com/robversluis/portainer-cli/internal/output"
-	"github.com/robversluis/portainer-cli/internal/watch"
+	"github.com/robversluis/portainer-cli/internal/terminal"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
 	"github.com/spf13/cobra"
 )
 
@@ -27,7 +31,15 @@ var containersListCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List containers",
-	Long:    `Display a list of containers in the specified environment.`,
+	Long: `Display a list of containers in the specified environment.
+
+--size adds the disk usage of each container: SIZE is the data written to the
+container's writable layer and VIRTUAL also counts its image. Use
+--sort size --reverse to find the containers using the most space.
+
+Table and --quiet output is printed while the list is received, so the
+first rows show up quickly even with thousands of containers; --sort and
+the json and yaml formats wait for the whole list.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		endpointID, err := cmd.Flags().GetInt("endpoint")
 		if err != nil {
@@ -41,87 +53,134 @@ var containersListCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		size, err := cmd.Flags().GetBool("size")
+		if err != nil {
+			return err
+		}
 
-		watchMode, err := cmd.Flags().GetBool("watch")
+		health, err := cmd.Flags().GetString("health")
 		if err != nil {
 			return err
 		}
 
-		interval, err := cmd.Flags().GetInt("interval")
+		var filters map[string][]string
+		if health != "" {
+			switch health {
+			case portainer.HealthStatusHealthy, portainer.HealthStatusUnhealthy, portainer.HealthStatusStarting, portainer.HealthStatusNone:
+				filters = map[string][]string{"health": {health}}
+			default:
+				return fmt.Errorf("invalid health status: %s (expected healthy, unhealthy, starting, or none)", health)
+			}
+		}
+
+		listOpts, err := getListOptions(cmd)
 		if err != nil {
 			return err
 		}
+		// The SIZE column is the SizeRw field in structured output
+		itemOpts := listOpts
+		if strings.EqualFold(listOpts.sort, "size") {
+			if !size {
+				return fmt.Errorf("--sort size requires --size")
+			}
+			itemOpts.sort = "SizeRw"
+		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		containerService := client.NewContainerService(c)
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		containerService := portainer.NewContainerService(c)
+		format := getOutputFormat()
+
+		listOptions := portainer.ContainerListOptions{All: all, Filters: filters, Size: size}
 
+		var values notify.Values
 		listFunc := func() error {
-			containers, err := containerService.List(endpointID, all)
+			// Rows are printed while the list is decoded unless they must
+			// be sorted first
+			if (format == output.FormatTable || GetQuiet()) && listOpts.sort == "" {
+				streamed, err := streamContainers(containerService, endpointID, listOptions, listOpts)
+				if err != nil {
+					return err
+				}
+				values = streamed
+				return nil
+			}
+
+			containers, err := containerService.ListWithOptions(endpointID, listOptions)
 			if err != nil {
 				return err
 			}
 
+			values = containerWatchValues(containers)
+
+			if GetQuiet() {
+				return printQuiet(itemOpts, containers, func(item portainer.Container) string {
+					return item.GetShortID()
+				})
+			}
+
 			switch format {
 			case output.FormatJSON, output.FormatYAML:
-				formatter := output.NewFormatter(output.Options{Format: format})
-				return formatter.Format(containers)
+				formatter := newFormatter(format)
+				items, err := itemOpts.applyItems(containers)
+				if err != nil {
+					return err
+				}
+				return formatter.Format(items)
 
 			default:
-				table := output.NewTableData([]string{"ID", "Name", "Image", "Status", "Ports"})
-				for _, container := range containers {
-					ports := container.GetPorts()
-					if len(ports) > 50 {
-						ports = output.TruncateString(ports, 50)
-					}
-					table.AddRow([]string{
-						container.GetShortID(),
-						container.GetName(),
-						container.Image,
-						container.GetStatus(),
-						ports,
-					})
+				table := output.NewTableData(containerListHeaders(size))
+				for i := range containers {
+					table.AddRow(containerListRow(&containers[i], size))
+				}
+				if err := itemOpts.applyTable(table, containers); err != nil {
+					return err
 				}
 				return output.PrintTable(*table)
 			}
 		}
 
-		if watchMode {
-			ctx, cancel := context.WithCancel(context.Background())
-			defer cancel()
-
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-			go func() {
-				<-sigChan
-				cancel()
-			}()
-
-			opts := watch.DefaultOptions()
-			opts.Interval = time.Duration(interval) * time.Second
-
-			fmt.Println("Watching containers... (Press Ctrl+C to exit)")
-			return watch.Watch(ctx, opts, listFunc)
-		}
-
-		return listFunc()
+		return RunWithWatch(cmd, "containers", listFunc, func() notify.Values { return values })
 	},
 }
 
 var containersLogsCmd = &cobra.Command{
-	Use:   "logs [container]",
+	Use:   "logs [container...]",
 	Short: "View container logs",
-	Long:  `Display logs from a specific container.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Display logs from one or more containers.
+
+Containers are given by name or ID, or selected by label with --label, which
+matches the running containers carrying all the given labels. The logs of
+several containers are interleaved line by line, each line prefixed with the
+container name in its own color.
+
+--grep keeps only the lines matching a regular expression and --highlight
+colors the matches of another one. Both are applied while following, so no
+lines are lost to the buffering of a "logs -f | grep" pipeline. Without
+--highlight the --grep matches are colored.
+
+With -o json each line is written as a JSON object with the timestamp,
+stream (stdout or stderr), container and message fields, one per line, for
+jq or log collectors.
+
+With --output-file the logs are appended to a file instead of printed, and
+--rotate-size rotates the file once it reaches the given size. Together with
+--follow this turns the CLI into a lightweight log shipper for hosts that
+cannot run a full agent.
+
+Example:
+  portainer-cli containers logs web -f --endpoint 1 --output-file web.log \
+    --rotate-size 50MB --rotate-keep 10 --rotate-compress
+  portainer-cli containers logs web worker -f --endpoint 1
+  portainer-cli containers logs --label app=web -f --endpoint 1`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		endpointID, err := cmd.Flags().GetInt("endpoint")
 		if err != nil {
@@ -131,7 +190,49 @@ var containersLogsCmd = &cobra.Command{
 			return fmt.Errorf("--endpoint flag is required")
 		}
 
-		containerID := args[0]
+		labels, err := cmd.Flags().GetStringArray("label")
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 && len(labels) == 0 {
+			return fmt.Errorf("a container or --label is required")
+		}
+		if len(args) > 0 && len(labels) > 0 {
+			return fmt.Errorf("containers and --label cannot be used together")
+		}
+
+		grepExpr, err := cmd.Flags().GetString("grep")
+		if err != nil {
+			return err
+		}
+		highlightExpr, err := cmd.Flags().GetString("highlight")
+		if err != nil {
+			return err
+		}
+		var jsonLines bool
+		switch output.ParseFormat(outputFormat) {
+		case output.FormatJSON:
+			jsonLines = true
+		case output.FormatYAML:
+			return fmt.Errorf("logs support table and json output")
+		}
+		if queryExpr != "" {
+			return fmt.Errorf("--query is not supported by logs")
+		}
+
+		var filter, highlight *regexp.Regexp
+		if grepExpr != "" {
+			if filter, err = regexp.Compile(grepExpr); err != nil {
+				return fmt.Errorf("invalid --grep expression: %w", err)
+			}
+			highlight = filter
+		}
+		if highlightExpr != "" {
+			if highlight, err = regexp.Compile(highlightExpr); err != nil {
+				return fmt.Errorf("invalid --highlight expression: %w", err)
+			}
+		}
+
 		follow, err := cmd.Flags().GetBool("follow")
 		if err != nil {
 			return err
@@ -141,37 +242,105 @@ var containersLogsCmd = &cobra.Command{
 			return err
 		}
 
-		profile, err := config.GetProfileFromViper()
+		outputFile, err := cmd.Flags().GetString("output-file")
+		if err != nil {
+			return err
+		}
+		rotateSize, err := cmd.Flags().GetString("rotate-size")
+		if err != nil {
+			return err
+		}
+		rotateKeep, err := cmd.Flags().GetInt("rotate-keep")
+		if err != nil {
+			return err
+		}
+		rotateCompress, err := cmd.Flags().GetBool("rotate-compress")
+		if err != nil {
+			return err
+		}
+		if outputFile == "" && (rotateSize != "" || cmd.Flags().Changed("rotate-keep") || rotateCompress) {
+			return fmt.Errorf("--rotate-size, --rotate-keep and --rotate-compress require --output-file")
+		}
+		if rotateKeep < 0 {
+			return fmt.Errorf("--rotate-keep cannot be negative")
+		}
+		var maxSize int64
+		if rotateSize != "" {
+			maxSize, err = output.ParseSize(rotateSize)
+			if err != nil {
+				return err
+			}
+		}
+
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		containerService := client.NewContainerService(c)
-		logReader, err := containerService.Logs(endpointID, containerID, follow, tail, true, true)
-		if err != nil {
-			return err
+		containerService := portainer.NewContainerService(c)
+
+		names := args
+		if len(labels) > 0 {
+			containers, err := containerService.ListWithFilters(endpointID, false, map[string][]string{"label": labels})
+			if err != nil {
+				return err
+			}
+			if len(containers) == 0 {
+				return fmt.Errorf("no running containers match %s", strings.Join(labels, ", "))
+			}
+			names = make([]string, len(containers))
+			for i := range containers {
+				names[i] = containers[i].GetName()
+			}
 		}
-		defer logReader.Close()
 
-		scanner := bufio.NewScanner(logReader)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if len(line) > 8 {
-				line = line[8:]
+		sources := make([]logstream.Source, 0, len(names))
+		for _, name := range names {
+			reader, err := containerService.Logs(endpointID, name, follow, tail, true, true)
+			if err != nil {
+				for _, source := range sources {
+					source.Reader.Close()
+				}
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			if reader != nil {
+				sources = append(sources, logstream.Source{Name: name, Reader: reader})
 			}
-			fmt.Println(line)
+		}
+		if len(sources) == 0 {
+			// Dry-run: the requests were printed
+			return nil
 		}
 
-		if err := scanner.Err(); err != nil && err != io.EOF {
-			return fmt.Errorf("error reading logs: %w", err)
+		var w io.Writer = os.Stdout
+		if outputFile != "" {
+			file, err := logfile.Open(outputFile, logfile.Options{
+				MaxSize:    maxSize,
+				MaxBackups: rotateKeep,
+				Compress:   rotateCompress,
+			})
+			if err != nil {
+				for _, source := range sources {
+					source.Reader.Close()
+				}
+				return err
+			}
+			defer file.Close()
+			w = file
 		}
 
-		return nil
+		return logstream.Multiplex(w, sources, logstream.Options{
+			Prefix:    len(names) > 1,
+			Color:     outputFile == "" && output.ColorEnabled(),
+			Filter:    filter,
+			Highlight: highlight,
+			JSON:      jsonLines,
+		})
 	},
 }
 
@@ -191,39 +360,45 @@ var containersInspectCmd = &cobra.Command{
 
 		containerID := args[0]
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		containerService := client.NewContainerService(c)
+		containerService := portainer.NewContainerService(c)
 		container, err := containerService.Inspect(endpointID, containerID)
 		if err != nil {
 			return err
 		}
 
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		format := getOutputFormat()
 
 		switch format {
 		case output.FormatJSON, output.FormatYAML:
-			formatter := output.NewFormatter(output.Options{Format: format})
+			formatter := newFormatter(format)
 			return formatter.Format(container)
 
 		default:
 			fmt.Printf("ID:           %s\n", container.Id)
 			fmt.Printf("Name:         %s\n", container.Name)
 			fmt.Printf("Image:        %s\n", container.Image)
-			fmt.Printf("Status:       %s\n", container.State.Status)
+			fmt.Printf("Status:       %s\n", output.ColorizeStatus(container.State.Status))
 			fmt.Printf("Running:      %s\n", output.FormatBool(container.State.Running))
 			fmt.Printf("Paused:       %s\n", output.FormatBool(container.State.Paused))
 			fmt.Printf("Restarting:   %s\n", output.FormatBool(container.State.Restarting))
 			fmt.Printf("Pid:          %d\n", container.State.Pid)
 			fmt.Printf("Exit Code:    %d\n", container.State.ExitCode)
+			if container.State.Health != nil {
+				fmt.Printf("Health:       %s\n", output.ColorizeStatus(container.State.Health.Status))
+				if container.State.Health.FailingStreak > 0 {
+					fmt.Printf("Failing:      %d consecutive checks\n", container.State.Health.FailingStreak)
+				}
+			}
 
 			if container.State.StartedAt != "" {
 				fmt.Printf("Started At:   %s\n", container.State.StartedAt)
@@ -274,17 +449,17 @@ var containersStartCmd = &cobra.Command{
 
 		containerID := args[0]
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		containerService := client.NewContainerService(c)
+		containerService := portainer.NewContainerService(c)
 		if err := containerService.Start(endpointID, containerID); err != nil {
 			return err
 		}
@@ -313,17 +488,17 @@ var containersStopCmd = &cobra.Command{
 
 		containerID := args[0]
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		containerService := client.NewContainerService(c)
+		containerService := portainer.NewContainerService(c)
 		if err := containerService.Stop(endpointID, containerID); err != nil {
 			return err
 		}
@@ -352,17 +527,17 @@ var containersRestartCmd = &cobra.Command{
 
 		containerID := args[0]
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		containerService := client.NewContainerService(c)
+		containerService := portainer.NewContainerService(c)
 		if err := containerService.Restart(endpointID, containerID); err != nil {
 			return err
 		}
@@ -396,17 +571,17 @@ var containersRemoveCmd = &cobra.Command{
 			return err
 		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		containerService := client.NewContainerService(c)
+		containerService := portainer.NewContainerService(c)
 		if err := containerService.Remove(endpointID, containerID, force); err != nil {
 			return err
 		}
@@ -419,40 +594,1042 @@ var containersRemoveCmd = &cobra.Command{
 	},
 }
 
-func init() {
-	rootCmd.AddCommand(containersCmd)
-	containersCmd.AddCommand(containersListCmd)
-	containersCmd.AddCommand(containersLogsCmd)
-	containersCmd.AddCommand(containersInspectCmd)
-	containersCmd.AddCommand(containersStartCmd)
-	containersCmd.AddCommand(containersStopCmd)
-	containersCmd.AddCommand(containersRestartCmd)
-	containersCmd.AddCommand(containersRemoveCmd)
+var containersPauseCmd = &cobra.Command{
+	Use:   "pause [container]",
+	Short: "Pause a container",
+	Long:  `Suspend all processes in a running container.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
 
-	containersListCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
-	containersListCmd.Flags().BoolP("all", "a", false, "Show all containers (default shows just running)")
-	containersListCmd.Flags().BoolP("watch", "w", false, "Watch for changes and continuously update")
-	containersListCmd.Flags().Int("interval", 2, "Refresh interval in seconds for watch mode")
-	_ = containersListCmd.MarkFlagRequired("endpoint")
+		containerID := args[0]
 
-	containersLogsCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
-	containersLogsCmd.Flags().BoolP("follow", "f", false, "Follow log output")
-	containersLogsCmd.Flags().IntP("tail", "n", 100, "Number of lines to show from the end")
-	_ = containersLogsCmd.MarkFlagRequired("endpoint")
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
 
-	containersInspectCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
-	_ = containersInspectCmd.MarkFlagRequired("endpoint")
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
 
-	containersStartCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
-	_ = containersStartCmd.MarkFlagRequired("endpoint")
+		containerService := portainer.NewContainerService(c)
+		if err := containerService.Pause(endpointID, containerID); err != nil {
+			return err
+		}
 
-	containersStopCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
-	_ = containersStopCmd.MarkFlagRequired("endpoint")
+		if !GetQuiet() {
+			fmt.Printf("Container %s paused\n", containerID)
+		}
 
-	containersRestartCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
-	_ = containersRestartCmd.MarkFlagRequired("endpoint")
+		return nil
+	},
+}
 
-	containersRemoveCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
-	containersRemoveCmd.Flags().BoolP("force", "f", false, "Force removal of running container")
-	_ = containersRemoveCmd.MarkFlagRequired("endpoint")
+var containersUnpauseCmd = &cobra.Command{
+	Use:   "unpause [container]",
+	Short: "Unpause a container",
+	Long:  `Resume all processes in a paused container.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		containerID := args[0]
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		containerService := portainer.NewContainerService(c)
+		if err := containerService.Unpause(endpointID, containerID); err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Container %s unpaused\n", containerID)
+		}
+
+		return nil
+	},
+}
+
+var containersKillCmd = &cobra.Command{
+	Use:   "kill [container]",
+	Short: "Kill a container",
+	Long:  `Send a signal (SIGKILL by default) to the main process of a running container.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		containerID := args[0]
+		sig, err := cmd.Flags().GetString("signal")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		containerService := portainer.NewContainerService(c)
+		if err := containerService.Kill(endpointID, containerID, sig); err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Container %s killed\n", containerID)
+		}
+
+		return nil
+	},
+}
+
+var containersRenameCmd = &cobra.Command{
+	Use:   "rename [container] [new-name]",
+	Short: "Rename a container",
+	Long:  `Rename an existing container.`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		containerID := args[0]
+		newName := args[1]
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		containerService := portainer.NewContainerService(c)
+		if err := containerService.Rename(endpointID, containerID, newName); err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Container %s renamed to %s\n", containerID, newName)
+		}
+
+		return nil
+	},
+}
+
+var containersRecreateCmd = &cobra.Command{
+	Use:   "recreate [container]",
+	Short: "Recreate a container",
+	Long: `Replace a container with a new one using the same configuration, name,
+networks, and mounts. With --pull, the image is pulled first so the new
+container runs the latest image for its tag. A running container is started
+again after it has been recreated.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		pull, err := cmd.Flags().GetBool("pull")
+		if err != nil {
+			return err
+		}
+		registryID, err := cmd.Flags().GetInt("registry")
+		if err != nil {
+			return err
+		}
+
+		containerID := args[0]
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		containerService := portainer.NewContainerService(c)
+		newID, err := containerService.Recreate(endpointID, containerID, portainer.RecreateOptions{
+			PullImage:  pull,
+			RegistryID: registryID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !GetQuiet() && newID != "" {
+			fmt.Printf("Container %s recreated\n", containerID)
+		}
+
+		return nil
+	},
+}
+
+var containersCloneCmd = &cobra.Command{
+	Use:   "clone [container]",
+	Short: "Create a container from an existing one",
+	Long: `Create a new container with the configuration, networks, and mounts of an
+existing container, e.g. to start a new version next to the running one before
+switching traffic over.
+
+--image, --env, and --no-ports override the copied settings. Published host
+ports are kept by default, so use --no-ports when the source container keeps
+running. Static IP and MAC addresses are never copied.
+
+Example:
+  portainer-cli containers clone web --name web-green --image nginx:1.25 --endpoint 1 --no-ports`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		name, err := cmd.Flags().GetString("name")
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			return fmt.Errorf("--name flag is required")
+		}
+		image, err := cmd.Flags().GetString("image")
+		if err != nil {
+			return err
+		}
+		env, err := cmd.Flags().GetStringArray("env")
+		if err != nil {
+			return err
+		}
+		for _, e := range env {
+			if key, _, ok := strings.Cut(e, "="); !ok || key == "" {
+				return fmt.Errorf("invalid environment variable %q, expected KEY=VALUE", e)
+			}
+		}
+		noPorts, err := cmd.Flags().GetBool("no-ports")
+		if err != nil {
+			return err
+		}
+		start, err := cmd.Flags().GetBool("start")
+		if err != nil {
+			return err
+		}
+		pull, err := cmd.Flags().GetBool("pull")
+		if err != nil {
+			return err
+		}
+		registryID, err := cmd.Flags().GetInt("registry")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		containerService := portainer.NewContainerService(c)
+		newID, err := containerService.Clone(endpointID, args[0], portainer.CloneOptions{
+			Name:       name,
+			Image:      image,
+			PullImage:  pull,
+			RegistryID: registryID,
+			Env:        env,
+			NoPorts:    noPorts,
+			Start:      start,
+		})
+		if err != nil {
+			return err
+		}
+		if newID == "" {
+			return nil
+		}
+
+		if GetQuiet() {
+			fmt.Println(newID)
+		} else {
+			fmt.Printf("Container %s cloned to %s (ID: %s)\n", args[0], name, (&portainer.Container{Id: newID}).GetShortID())
+		}
+
+		return nil
+	},
+}
+
+var containersOutdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "List containers running outdated images",
+	Long: `Compare the image of each running container with the current digest of its
+tag in the registry and list the containers that are behind.
+
+With --recreate, the image of each outdated container is pulled and the
+container is recreated with the same configuration. For example:
+  portainer-cli containers outdated --endpoint 1
+  portainer-cli containers outdated --endpoint 1 --recreate`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		recreate, err := cmd.Flags().GetBool("recreate")
+		if err != nil {
+			return err
+		}
+		registryID, err := cmd.Flags().GetInt("registry")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		containerService := portainer.NewContainerService(c)
+		imageService := portainer.NewImageService(c)
+
+		containers, err := containerService.List(endpointID, false)
+		if err != nil {
+			return err
+		}
+
+		images, err := imageService.List(endpointID)
+		if err != nil {
+			return err
+		}
+
+		results := checkContainerImages(imageService, endpointID, containers, images)
+
+		format := getOutputFormat()
+
+		switch {
+		case GetQuiet():
+			for _, result := range results {
+				if result.Status == registryOutdated {
+					fmt.Println(result.Container)
+				}
+			}
+
+		case format == output.FormatJSON || format == output.FormatYAML:
+			formatter := newFormatter(format)
+			if err := formatter.Format(results); err != nil {
+				return err
+			}
+
+		default:
+			table := output.NewTableData([]string{"Container", "Image", "Image ID", "Registry"})
+			for _, result := range results {
+				table.AddRow([]string{
+					result.Container,
+					result.Image,
+					result.ImageID,
+					result.Status,
+				})
+			}
+			if err := output.PrintTable(*table); err != nil {
+				return err
+			}
+		}
+
+		if !recreate {
+			return nil
+		}
+
+		var failed int
+		for _, result := range results {
+			if result.Status != registryOutdated {
+				continue
+			}
+
+			if _, err := containerService.Recreate(endpointID, result.Id, portainer.RecreateOptions{
+				PullImage:  true,
+				RegistryID: registryID,
+			}); err != nil {
+				fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("failed to recreate %s: %v", result.Container, err)))
+				failed++
+				continue
+			}
+
+			if !GetQuiet() && format == output.FormatTable {
+				fmt.Printf("Recreated %s with the latest %s\n", result.Container, result.Image)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("failed to recreate %d container(s)", failed)
+		}
+		return nil
+	},
+}
+
+// containerImageStatus describes whether a container runs the current image
+// of its tag
+type containerImageStatus struct {
+	Container string `json:"Container"`
+	Id        string `json:"Id"`
+	Image     string `json:"Image"`
+	ImageID   string `json:"ImageID"`
+	Status    string `json:"Status"`
+}
+
+// Registry state of containers whose image reference cannot be checked
+const registryPinned = "pinned"
+
+// checkContainerImages resolves the tag of each container's image against
+// the registry, looking up every tag only once
+func checkContainerImages(imageService *portainer.ImageService, endpointID int, containers []portainer.Container, images []portainer.Image) []containerImageStatus {
+	imagesByID := map[string]portainer.Image{}
+	for _, image := range images {
+		imagesByID[image.Id] = image
+	}
+
+	digests := map[string]string{}
+	results := make([]containerImageStatus, 0, len(containers))
+
+	for _, container := range containers {
+		result := containerImageStatus{
+			Container: container.GetName(),
+			Id:        container.Id,
+			Image:     container.Image,
+			ImageID:   container.GetShortImageID(),
+			Status:    registryUnknown,
+		}
+
+		switch {
+		case strings.Contains(container.Image, "@"):
+			result.Status = registryPinned
+
+		case !strings.HasPrefix(container.Image, "sha256:"):
+			repository, tag := splitImageRef(container.Image)
+			ref := repository + ":" + tag
+
+			digest, ok := digests[ref]
+			if !ok {
+				var err error
+				digest, err = imageService.RegistryDigest(endpointID, ref)
+				if err != nil {
+					if GetVerbose() {
+						fmt.Fprintln(os.Stderr, output.Warning(err.Error()))
+					}
+					digest = ""
+				}
+				digests[ref] = digest
+			}
+
+			image, found := imagesByID[container.ImageID]
+			switch {
+			case digest == "" || !found:
+				result.Status = registryUnknown
+			case image.HasDigest(repository, digest):
+				result.Status = registryUpToDate
+			default:
+				result.Status = registryOutdated
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+var containersWaitHealthyCmd = &cobra.Command{
+	Use:   "wait-healthy [container]",
+	Short: "Wait until a container is healthy",
+	Long: `Poll a container until its health check reports healthy.
+
+Exits with an error if the timeout expires, the container stops, or the
+health check reports unhealthy. The container must define a health check.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return err
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("--timeout must be positive")
+		}
+
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			return err
+		}
+		if interval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+
+		containerID := args[0]
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		ctx, cancelTimeout := context.WithTimeout(ctx, timeout)
+		defer cancelTimeout()
+
+		// The timeout also bounds inspect requests that hang
+		containerService := portainer.NewContainerService(c.WithContext(ctx))
+
+		lastStatus := ""
+		for {
+			container, err := containerService.Inspect(endpointID, containerID)
+			if err != nil {
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return fmt.Errorf("timed out after %s waiting for container %s to become healthy (health: %s)", timeout, containerID, lastStatus)
+				}
+				return err
+			}
+			if GetDryRun() {
+				return nil
+			}
+
+			health := container.State.Health
+			if health == nil {
+				return fmt.Errorf("container %s has no health check", containerID)
+			}
+			if !container.State.Running && !container.State.Restarting {
+				return fmt.Errorf("container %s is not running (status: %s)", containerID, container.State.Status)
+			}
+
+			if health.Status != lastStatus && GetVerbose() {
+				fmt.Printf("Container %s health: %s\n", containerID, health.Status)
+			}
+			lastStatus = health.Status
+
+			switch health.Status {
+			case portainer.HealthStatusHealthy:
+				if !GetQuiet() {
+					fmt.Printf("Container %s is healthy\n", containerID)
+				}
+				return nil
+			case portainer.HealthStatusUnhealthy:
+				message := fmt.Sprintf("container %s is unhealthy", containerID)
+				if n := len(health.Log); n > 0 {
+					if lastOutput := strings.TrimSpace(health.Log[n-1].Output); lastOutput != "" {
+						message += ": " + lastOutput
+					}
+				}
+				return fmt.Errorf("%s", message)
+			}
+
+			select {
+			case <-ctx.Done():
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return fmt.Errorf("timed out after %s waiting for container %s to become healthy (health: %s)", timeout, containerID, lastStatus)
+				}
+				return fmt.Errorf("interrupted while waiting for container %s", containerID)
+			case <-time.After(interval):
+			}
+		}
+	},
+}
+
+var containersPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove stopped containers",
+	Long: `Remove all stopped containers.
+
+Filters are passed through to the Docker API, for example:
+  portainer-cli containers prune --endpoint 1 --filter until=24h
+  portainer-cli containers prune --endpoint 1 --filter label=env=dev`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		filterArgs, err := cmd.Flags().GetStringArray("filter")
+		if err != nil {
+			return err
+		}
+
+		filters, err := parseFilters(filterArgs)
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		containerService := portainer.NewContainerService(c)
+		emitProgress(progressEvent{Event: "pruning", Endpoint: endpointID, Resource: "containers"})
+		report, err := containerService.Prune(endpointID, filters)
+		if err != nil {
+			emitPruned(endpointID, "containers", 0, 0, err)
+			return err
+		}
+		emitPruned(endpointID, "containers", len(report.ContainersDeleted), report.SpaceReclaimed, nil)
+
+		return printPruneReport(report, pruneResult{
+			kind:      "containers",
+			deleted:   report.ContainersDeleted,
+			reclaimed: report.SpaceReclaimed,
+		})
+	},
+}
+
+var containersAttachCmd = &cobra.Command{
+	Use:   "attach [container]",
+	Short: "Attach to a running container",
+	Long: `Attach local standard input, output, and error streams to a running container.
+
+Use the detach key sequence (ctrl-p,ctrl-q by default) to detach without
+stopping the container.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		containerID := args[0]
+		noStdin, err := cmd.Flags().GetBool("no-stdin")
+		if err != nil {
+			return err
+		}
+		detachKeys, err := cmd.Flags().GetString("detach-keys")
+		if err != nil {
+			return err
+		}
+
+		keys, err := terminal.ParseDetachKeys(detachKeys)
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		containerService := portainer.NewContainerService(c)
+		container, err := containerService.Inspect(endpointID, containerID)
+		if err != nil {
+			return err
+		}
+		if !GetDryRun() && !container.State.Running {
+			return fmt.Errorf("container %s is not running", containerID)
+		}
+
+		withStdin := !noStdin && container.Config.OpenStdin
+		conn, err := containerService.Attach(endpointID, containerID, withStdin)
+		if err != nil {
+			return err
+		}
+		if conn == nil {
+			return nil
+		}
+		defer conn.Close()
+
+		tty := container.Config.Tty
+		if tty && withStdin && terminal.IsTerminal(os.Stdin) {
+			state, err := terminal.MakeRaw(os.Stdin)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = state.Restore() }()
+
+			if height, width, err := terminal.Size(os.Stdout); err == nil {
+				_ = containerService.Resize(endpointID, containerID, height, width)
+			}
+		}
+
+		err = streamHijacked(conn, tty, withStdin, keys)
+		if err == terminal.ErrDetached {
+			if !GetQuiet() {
+				fmt.Fprintf(os.Stderr, "\r\nDetached from container %s\r\n", containerID)
+			}
+			return nil
+		}
+		return err
+	},
+}
+
+var containersConsoleCmd = &cobra.Command{
+	Use:     "console [container]",
+	Aliases: []string{"shell"},
+	Short:   "Open an interactive console in a container",
+	Long: `Open an interactive shell in a running container through Portainer's
+console websocket, which also works for agent and Edge environments.
+
+The session ends when the shell exits. For example:
+  portainer-cli containers console web --endpoint 1
+  portainer-cli containers console web --endpoint 1 --command bash --user root`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		command, err := cmd.Flags().GetString("command")
+		if err != nil {
+			return err
+		}
+		user, err := cmd.Flags().GetString("user")
+		if err != nil {
+			return err
+		}
+
+		containerID := args[0]
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		containerService := portainer.NewContainerService(c)
+		execID, err := containerService.CreateExec(endpointID, containerID, &portainer.ExecConfig{
+			AttachStdin:  true,
+			AttachStdout: true,
+			AttachStderr: true,
+			Tty:          true,
+			Cmd:          strings.Fields(command),
+			User:         user,
+		})
+		if err != nil {
+			return err
+		}
+
+		ws, err := containerService.Console(endpointID, execID)
+		if err != nil {
+			return err
+		}
+		if ws == nil {
+			return nil
+		}
+		defer ws.Close()
+
+		if terminal.IsTerminal(os.Stdin) {
+			state, err := terminal.MakeRaw(os.Stdin)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = state.Restore() }()
+
+			resize := func() {
+				if height, width, err := terminal.Size(os.Stdout); err == nil {
+					_ = containerService.ResizeExec(endpointID, execID, height, width)
+				}
+			}
+			resize()
+
+			resizeCh := make(chan os.Signal, 1)
+			stop := terminal.NotifyResize(resizeCh)
+			defer stop()
+			go func() {
+				for range resizeCh {
+					resize()
+				}
+			}()
+		}
+
+		outputDone := make(chan error, 1)
+		go func() {
+			_, err := io.Copy(os.Stdout, ws)
+			outputDone <- err
+		}()
+		go func() {
+			_, _ = io.Copy(ws, os.Stdin)
+		}()
+
+		if err := <-outputDone; err != nil && err != io.EOF {
+			return fmt.Errorf("console connection lost: %w", err)
+		}
+		return nil
+	},
+}
+
+// streamHijacked copies the local terminal to and from a hijacked connection
+// until the remote side closes the stream or the detach sequence is typed.
+func streamHijacked(conn *portainer.HijackedResponse, tty, withStdin bool, detachKeys []byte) error {
+	outputDone := make(chan error, 1)
+	go func() {
+		if tty {
+			_, err := io.Copy(os.Stdout, conn.Reader)
+			outputDone <- err
+			return
+		}
+		outputDone <- portainer.StdCopy(os.Stdout, os.Stderr, conn.Reader)
+	}()
+
+	inputDone := make(chan error, 1)
+	if withStdin {
+		go func() {
+			_, err := io.Copy(conn.Conn, terminal.NewDetachReader(os.Stdin, detachKeys))
+			if err == nil {
+				_ = conn.CloseWrite()
+			}
+			inputDone <- err
+		}()
+	}
+
+	select {
+	case err := <-outputDone:
+		return err
+	case err := <-inputDone:
+		if err == terminal.ErrDetached {
+			return err
+		}
+		return <-outputDone
+	}
+}
+
+func parseFilters(filterArgs []string) (map[string][]string, error) {
+	filters := map[string][]string{}
+	for _, f := range filterArgs {
+		parts := strings.SplitN(f, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid filter format: %s (expected KEY=VALUE)", f)
+		}
+		filters[parts[0]] = append(filters[parts[0]], parts[1])
+	}
+	return filters, nil
+}
+
+// containerWatchValues returns the --notify-on variables for containers:
+// the count, the count per state and the count per health status
+func containerWatchValues(containers []portainer.Container) notify.Values {
+	values := newContainerWatchValues()
+	for i := range containers {
+		addContainerWatchValues(values, &containers[i])
+	}
+	return values
+}
+
+func newContainerWatchValues() notify.Values {
+	return notify.Values{
+		"count": 0, "running": 0, "stopped": 0, "paused": 0, "restarting": 0, "exited": 0,
+		"healthy": 0, "unhealthy": 0, "starting": 0,
+	}
+}
+
+// addContainerWatchValues counts container in values
+func addContainerWatchValues(values notify.Values, container *portainer.Container) {
+	values["count"]++
+	if container.IsRunning() {
+		values["running"]++
+	} else {
+		values["stopped"]++
+	}
+	switch container.State {
+	case "paused", "restarting", "exited":
+		values[container.State]++
+	}
+	if health := container.GetHealth(); health != portainer.HealthStatusNone {
+		values[health]++
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(containersCmd)
+	containersCmd.AddCommand(containersListCmd)
+	containersCmd.AddCommand(containersLogsCmd)
+	containersCmd.AddCommand(containersInspectCmd)
+	containersCmd.AddCommand(containersStartCmd)
+	containersCmd.AddCommand(containersStopCmd)
+	containersCmd.AddCommand(containersRestartCmd)
+	containersCmd.AddCommand(containersRemoveCmd)
+	containersCmd.AddCommand(containersPauseCmd)
+	containersCmd.AddCommand(containersUnpauseCmd)
+	containersCmd.AddCommand(containersKillCmd)
+	containersCmd.AddCommand(containersRenameCmd)
+	containersCmd.AddCommand(containersPruneCmd)
+	containersCmd.AddCommand(containersWaitHealthyCmd)
+	containersCmd.AddCommand(containersOutdatedCmd)
+	containersCmd.AddCommand(containersRecreateCmd)
+	containersCmd.AddCommand(containersCloneCmd)
+	containersCmd.AddCommand(containersConsoleCmd)
+	containersCmd.AddCommand(containersAttachCmd)
+
+	containersListCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersListCmd.Flags().BoolP("all", "a", false, "Show all containers (default shows just running)")
+	containersListCmd.Flags().BoolP("size", "s", false, "Show the disk usage of each container")
+	AddWatchFlags(containersListCmd)
+	containersListCmd.Flags().String("health", "", "Filter by health status (healthy, unhealthy, starting, none)")
+	addListFlags(containersListCmd)
+	_ = containersListCmd.MarkFlagRequired("endpoint")
+
+	containersLogsCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersLogsCmd.Flags().BoolP("follow", "f", false, "Follow log output")
+	containersLogsCmd.Flags().IntP("tail", "n", 100, "Number of lines to show from the end")
+	containersLogsCmd.Flags().String("grep", "", "Only show lines matching this regular expression")
+	containersLogsCmd.Flags().String("highlight", "", "Color the parts of lines matching this regular expression")
+	containersLogsCmd.Flags().StringArrayP("label", "l", nil, "Show the logs of the running containers with this label (KEY or KEY=VALUE, repeatable)")
+	containersLogsCmd.Flags().String("output-file", "", "Append the logs to a file instead of printing them")
+	containersLogsCmd.Flags().String("rotate-size", "", "Rotate the output file when it reaches this size (e.g. 100MB)")
+	containersLogsCmd.Flags().Int("rotate-keep", 5, "Number of rotated files to keep (0 keeps all)")
+	containersLogsCmd.Flags().Bool("rotate-compress", false, "Gzip rotated files")
+	_ = containersLogsCmd.MarkFlagRequired("endpoint")
+
+	containersInspectCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	_ = containersInspectCmd.MarkFlagRequired("endpoint")
+
+	containersStartCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	_ = containersStartCmd.MarkFlagRequired("endpoint")
+
+	containersStopCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	_ = containersStopCmd.MarkFlagRequired("endpoint")
+
+	containersRestartCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	_ = containersRestartCmd.MarkFlagRequired("endpoint")
+
+	containersRemoveCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersRemoveCmd.Flags().BoolP("force", "f", false, "Force removal of running container")
+	_ = containersRemoveCmd.MarkFlagRequired("endpoint")
+
+	containersPauseCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	_ = containersPauseCmd.MarkFlagRequired("endpoint")
+
+	containersUnpauseCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	_ = containersUnpauseCmd.MarkFlagRequired("endpoint")
+
+	containersKillCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersKillCmd.Flags().StringP("signal", "s", "SIGKILL", "Signal to send to the container")
+	_ = containersKillCmd.MarkFlagRequired("endpoint")
+
+	containersRenameCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	_ = containersRenameCmd.MarkFlagRequired("endpoint")
+
+	containersPruneCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersPruneCmd.Flags().StringArray("filter", []string{}, "Filter containers to prune (KEY=VALUE, e.g. until=24h, label=env=dev)")
+	_ = containersPruneCmd.MarkFlagRequired("endpoint")
+
+	containersWaitHealthyCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersWaitHealthyCmd.Flags().Duration("timeout", 2*time.Minute, "Maximum time to wait (e.g. 30s, 5m)")
+	containersWaitHealthyCmd.Flags().Duration("interval", 2*time.Second, "Time between health checks")
+	_ = containersWaitHealthyCmd.MarkFlagRequired("endpoint")
+
+	containersRecreateCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersRecreateCmd.Flags().Bool("pull", false, "Pull the latest image before recreating")
+	containersRecreateCmd.Flags().Int("registry", 0, "Registry ID for authenticating the pull")
+	_ = containersRecreateCmd.MarkFlagRequired("endpoint")
+
+	containersCloneCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersCloneCmd.Flags().String("name", "", "Name of the new container (required)")
+	containersCloneCmd.Flags().String("image", "", "Image of the new container (default: the source container's image)")
+	containersCloneCmd.Flags().StringArray("env", nil, "Add or replace an environment variable (KEY=VALUE)")
+	containersCloneCmd.Flags().Bool("no-ports", false, "Do not publish the source container's host ports")
+	containersCloneCmd.Flags().Bool("start", true, "Start the new container")
+	containersCloneCmd.Flags().Bool("pull", false, "Pull the image before creating the container")
+	containersCloneCmd.Flags().Int("registry", 0, "Registry ID for authenticating the pull")
+	_ = containersCloneCmd.MarkFlagRequired("endpoint")
+	_ = containersCloneCmd.MarkFlagRequired("name")
+
+	containersOutdatedCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersOutdatedCmd.Flags().Bool("recreate", false, "Pull the latest image and recreate outdated containers")
+	containersOutdatedCmd.Flags().Int("registry", 0, "Registry ID for authenticating pulls")
+	_ = containersOutdatedCmd.MarkFlagRequired("endpoint")
+
+	containersConsoleCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersConsoleCmd.Flags().StringP("command", "c", "/bin/sh", "Shell or command to run")
+	containersConsoleCmd.Flags().StringP("user", "u", "", "User to run the command as")
+	_ = containersConsoleCmd.MarkFlagRequired("endpoint")
+
+	containersAttachCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersAttachCmd.Flags().Bool("no-stdin", false, "Do not attach standard input")
+	containersAttachCmd.Flags().String("detach-keys", terminal.DefaultDetachKeys, "Key sequence for detaching from the container")
+	_ = containersAttachCmd.MarkFlagRequired("endpoint")
 }
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+// committedImage is the result of containers commit
+type committedImage struct {
+	Container string `json:"Container"`
+	Image     string `json:"Image,omitempty"`
+	Id        string `json:"Id"`
+	Digest    string `json:"Digest,omitempty"`
+}
+
+var containersCommitCmd = &cobra.Command{
+	Use:   "commit [container] [repository[:tag]]",
+	Short: "Create an image from a container",
+	Long: `Capture the filesystem and configuration of a container in a new image, e.g.
+to keep the state of a misbehaving container for debugging. The container is
+paused while it is captured; use --pause=false to keep it running. Without a
+repository the image is untagged.
+
+With --push the image is pushed afterwards, with the credentials of
+--registry.
+
+Examples:
+  portainer-cli containers commit web myrepo/web:debug --endpoint 1
+  portainer-cli containers commit web registry.example.com/web:debug --endpoint 1 --push --registry 2
+  portainer-cli containers commit web myrepo/web:debug --endpoint 1 --change 'CMD ["sleep", "infinity"]' -m "state before restart"`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		var opts portainer.CommitOptions
+		if opts.Pause, err = cmd.Flags().GetBool("pause"); err != nil {
+			return err
+		}
+		if opts.Comment, err = cmd.Flags().GetString("message"); err != nil {
+			return err
+		}
+		if opts.Author, err = cmd.Flags().GetString("author"); err != nil {
+			return err
+		}
+		if opts.Changes, err = cmd.Flags().GetStringArray("change"); err != nil {
+			return err
+		}
+		push, err := cmd.Flags().GetBool("push")
+		if err != nil {
+			return err
+		}
+		registryID, err := cmd.Flags().GetInt("registry")
+		if err != nil {
+			return err
+		}
+
+		result := committedImage{Container: args[0]}
+		if len(args) == 2 {
+			parts := splitImageName(args[1])
+			opts.Repository, opts.Tag = parts[0], parts[1]
+			result.Image = parts[0] + ":" + parts[1]
+		}
+		if push && result.Image == "" {
+			return fmt.Errorf("--push requires a repository to push the image to")
+		}
+		if registryID != 0 && !push {
+			return fmt.Errorf("--registry requires --push")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		format := getOutputFormat()
+		report := !GetQuiet() && format != output.FormatJSON && format != output.FormatYAML
+
+		if result.Id, err = portainer.NewContainerService(c).Commit(endpointID, args[0], opts); err != nil {
+			return err
+		}
+		if report && !GetDryRun() {
+			if result.Image != "" {
+				fmt.Printf("Committed container %s as %s (%s)\n", args[0], result.Image, result.Id)
+			} else {
+				fmt.Printf("Committed container %s as %s\n", args[0], result.Id)
+			}
+		}
+
+		if push {
+			if report {
+				fmt.Printf("Pushing %s\n", result.Image)
+			}
+			emitProgress(progressEvent{Event: "push_started", Endpoint: endpointID, Image: result.Image})
+			pushed, err := portainer.NewImageService(c).PushWithProgress(endpointID, result.Image, registryID, func(progress portainer.PushProgress) {
+				emitProgress(pushProgressEvent(endpointID, result.Image, progress))
+				if report && progress.ID != "" && isPushedLayerStatus(progress.Status) {
+					fmt.Printf("  %s: %s\n", progress.ID, progress.Status)
+				}
+			})
+			if err != nil {
+				emitProgress(progressEvent{Event: "push_failed", Endpoint: endpointID, Image: result.Image, Error: err.Error()})
+				return fmt.Errorf("%w (the image %s was committed)", err, result.Image)
+			}
+			if pushed != nil {
+				result.Digest = pushed.Digest
+			}
+			emitProgress(progressEvent{Event: "pushed", Endpoint: endpointID, Image: result.Image, Digest: result.Digest})
+			if report && !GetDryRun() {
+				fmt.Printf("Pushed %s\n", result.Image)
+			}
+		}
+
+		if GetDryRun() {
+			return nil
+		}
+		switch {
+		case GetQuiet():
+			fmt.Println(result.Id)
+		case format == output.FormatJSON || format == output.FormatYAML:
+			return newFormatter(format).Format(result)
+		}
+		return nil
+	},
+}
+
+func init() {
+	containersCmd.AddCommand(containersCommitCmd)
+
+	containersCommitCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersCommitCmd.Flags().Bool("pause", true, "Pause the container while it is committed")
+	containersCommitCmd.Flags().StringP("message", "m", "", "Commit message")
+	containersCommitCmd.Flags().StringP("author", "a", "", "Author of the image")
+	containersCommitCmd.Flags().StringArrayP("change", "c", nil, "Dockerfile instruction to apply to the image, e.g. 'ENV DEBUG=1' (repeatable)")
+	containersCommitCmd.Flags().Bool("push", false, "Push the image after committing it")
+	containersCommitCmd.Flags().Int("registry", 0, "Registry ID for authentication when pushing (with --push)")
+	_ = containersCommitCmd.MarkFlagRequired("endpoint")
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var containersDiffCmd = &cobra.Command{
+	Use:   "diff [container]",
+	Short: "Show filesystem changes of a container",
+	Long: `List the files and directories added (A), changed (C) or deleted (D) in a
+container relative to its image, sorted by path. --filter only shows paths
+below the given prefixes.
+
+Examples:
+  portainer-cli containers diff web --endpoint 1
+  portainer-cli containers diff web --endpoint 1 --filter /etc --filter /var/log`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+		prefixes, err := cmd.Flags().GetStringArray("filter")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		changes, err := portainer.NewContainerService(c).Changes(endpointID, args[0])
+		if err != nil {
+			return err
+		}
+		if GetDryRun() {
+			return nil
+		}
+		changes = filterContainerChanges(changes, prefixes)
+
+		if GetQuiet() {
+			for _, change := range changes {
+				fmt.Println(change.Path)
+			}
+			return nil
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			return newFormatter(format).Format(changes)
+		default:
+			if len(changes) == 0 {
+				fmt.Println("No filesystem changes found")
+				return nil
+			}
+			table := output.NewTableData([]string{"Type", "Path"})
+			for _, change := range changes {
+				table.AddRow([]string{change.KindString(), change.Path})
+			}
+			return output.PrintTable(*table)
+		}
+	},
+}
+
+// filterContainerChanges returns the changes below one of prefixes, sorted
+// by path. A prefix matches whole path elements: /var/log matches
+// /var/log/app.log but not /var/logs.
+func filterContainerChanges(changes []portainer.ContainerChange, prefixes []string) []portainer.ContainerChange {
+	filtered := make([]portainer.ContainerChange, 0, len(changes))
+	for _, change := range changes {
+		if len(prefixes) == 0 || hasAnyPathPrefix(change.Path, prefixes) {
+			filtered = append(filtered, change)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].Path < filtered[j].Path
+	})
+	return filtered
+}
+
+func hasAnyPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		prefix = "/" + strings.Trim(prefix, "/")
+		if prefix == "/" || path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	containersCmd.AddCommand(containersDiffCmd)
+
+	containersDiffCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersDiffCmd.Flags().StringArray("filter", nil, "Only show paths below this prefix (repeatable)")
+	_ = containersDiffCmd.MarkFlagRequired("endpoint")
+}
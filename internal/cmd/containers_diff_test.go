@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestFilterContainerChanges(t *testing.T) {
+	changes := []portainer.ContainerChange{
+		{Path: "/var/logs", Kind: portainer.ContainerChangeAdded},
+		{Path: "/var/log/app.log", Kind: portainer.ContainerChangeAdded},
+		{Path: "/etc/nginx/nginx.conf", Kind: portainer.ContainerChangeModified},
+		{Path: "/var/log", Kind: portainer.ContainerChangeModified},
+		{Path: "/tmp/cache", Kind: portainer.ContainerChangeDeleted},
+	}
+
+	tests := []struct {
+		name     string
+		prefixes []string
+		expected []string
+	}{
+		{name: "no filter", expected: []string{"/etc/nginx/nginx.conf", "/tmp/cache", "/var/log", "/var/log/app.log", "/var/logs"}},
+		{name: "whole path elements", prefixes: []string{"/var/log/"}, expected: []string{"/var/log", "/var/log/app.log"}},
+		{name: "several prefixes", prefixes: []string{"etc", "/tmp"}, expected: []string{"/etc/nginx/nginx.conf", "/tmp/cache"}},
+		{name: "root", prefixes: []string{"/"}, expected: []string{"/etc/nginx/nginx.conf", "/tmp/cache", "/var/log", "/var/log/app.log", "/var/logs"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := filterContainerChanges(changes, tt.prefixes)
+			if len(filtered) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, filtered)
+			}
+			for i, change := range filtered {
+				if change.Path != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, filtered)
+					break
+				}
+			}
+		})
+	}
+
+	if kinds := changes[0].KindString() + changes[2].KindString() + changes[4].KindString(); kinds != "ACD" {
+		t.Errorf("expected kinds ACD, got %s", kinds)
+	}
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var containersLabelsCmd = &cobra.Command{
+	Use:   "labels [container]",
+	Short: "Show container labels",
+	Long:  `List the labels of a container, sorted by key.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		container, err := portainer.NewContainerService(c).Inspect(endpointID, args[0])
+		if err != nil {
+			return err
+		}
+		labels := container.Config.Labels
+		if labels == nil {
+			labels = map[string]string{}
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			return newFormatter(format).Format(labels)
+		}
+
+		keys := sortedKeys(labels)
+		if GetQuiet() {
+			for _, key := range keys {
+				fmt.Printf("%s=%s\n", key, labels[key])
+			}
+			return nil
+		}
+
+		if len(keys) == 0 {
+			fmt.Println("No labels found")
+			return nil
+		}
+
+		table := output.NewTableData([]string{"KEY", "VALUE"})
+		for _, key := range keys {
+			table.AddRow([]string{key, labels[key]})
+		}
+		return output.PrintTable(*table)
+	},
+}
+
+var containersRelabelCmd = &cobra.Command{
+	Use:   "relabel [container]",
+	Short: "Add or remove container labels",
+	Long: `Change the labels of a container. Docker cannot change labels in place, so
+the container is recreated with the same configuration, name, networks, and
+mounts, and started again if it was running.
+
+Example:
+  portainer-cli containers relabel web --endpoint 1 --add team=ops --add prometheus.scrape=true --remove legacy`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		add, err := cmd.Flags().GetStringArray("add")
+		if err != nil {
+			return err
+		}
+		remove, err := cmd.Flags().GetStringArray("remove")
+		if err != nil {
+			return err
+		}
+		if len(add) == 0 && len(remove) == 0 {
+			return fmt.Errorf("--add or --remove is required")
+		}
+
+		labels, err := parseLabels(add)
+		if err != nil {
+			return err
+		}
+		for _, key := range remove {
+			if _, ok := labels[key]; ok {
+				return fmt.Errorf("label %s is both added and removed", key)
+			}
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		newID, err := portainer.NewContainerService(c).Recreate(endpointID, args[0], portainer.RecreateOptions{
+			Labels:       labels,
+			RemoveLabels: remove,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !GetQuiet() && newID != "" {
+			fmt.Printf("Container %s relabeled\n", args[0])
+		}
+
+		return nil
+	},
+}
+
+// parseLabels parses KEY=VALUE label arguments. The value may be empty.
+func parseLabels(args []string) (map[string]string, error) {
+	labels := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label %q, expected KEY=VALUE", arg)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+func init() {
+	containersCmd.AddCommand(containersLabelsCmd)
+	containersCmd.AddCommand(containersRelabelCmd)
+
+	containersLabelsCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	_ = containersLabelsCmd.MarkFlagRequired("endpoint")
+
+	containersRelabelCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersRelabelCmd.Flags().StringArray("add", nil, "Add or replace a label (KEY=VALUE, repeatable)")
+	containersRelabelCmd.Flags().StringArray("remove", nil, "Remove a label by key (repeatable)")
+	_ = containersRelabelCmd.MarkFlagRequired("endpoint")
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import "testing"
+
+func TestParseLabels(t *testing.T) {
+	labels, err := parseLabels([]string{"team=ops", "traefik.http.routers.web.rule=Host(`a=b`)", "empty="})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(labels) != 3 || labels["team"] != "ops" || labels["traefik.http.routers.web.rule"] != "Host(`a=b`)" || labels["empty"] != "" {
+		t.Errorf("unexpected labels: %v", labels)
+	}
+
+	for _, arg := range []string{"team", "=ops"} {
+		if _, err := parseLabels([]string{arg}); err == nil {
+			t.Errorf("expected error for %q", arg)
+		}
+	}
+}
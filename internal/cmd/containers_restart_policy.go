@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/internal/pool"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+// Outcomes of a restart policy change
+const (
+	restartPolicyUpdated   = "updated"
+	restartPolicyUnchanged = "unchanged"
+	restartPolicyPlanned   = "would update"
+	restartPolicySkipped   = "skipped"
+	restartPolicyFailed    = "failed"
+)
+
+// restartPolicyChange is the outcome of set-restart-policy for a container
+type restartPolicyChange struct {
+	Container string   `json:"Container"`
+	ID        string   `json:"ID"`
+	From      string   `json:"From"`
+	To        string   `json:"To"`
+	Status    string   `json:"Status"`
+	Error     string   `json:"Error,omitempty"`
+	Warnings  []string `json:"Warnings,omitempty"`
+}
+
+var containersSetRestartPolicyCmd = &cobra.Command{
+	Use:   "set-restart-policy [container...]",
+	Short: "Change the restart policy of containers",
+	Long: `Change the restart policy of the given containers, or of all containers
+matching --filter, in place: the containers are updated, not recreated, and
+keep running. Containers that have the policy already are left alone, as
+are containers started with --rm, which the engine allows no restart
+policy.
+
+The policy is given as to docker run --restart: no, always, unless-stopped,
+or on-failure[:max-retries]. With --dry-run the matching containers and
+their current policy are listed, and nothing is changed.
+
+Examples:
+  portainer-cli containers set-restart-policy --endpoint 1 --filter label=app=web --policy unless-stopped
+  portainer-cli containers set-restart-policy web worker --endpoint 1 --policy on-failure:5
+  portainer-cli containers set-restart-policy --endpoint 1 --filter name=job- --policy no --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		policyArg, err := cmd.Flags().GetString("policy")
+		if err != nil {
+			return err
+		}
+		policy, err := portainer.ParseRestartPolicy(policyArg)
+		if err != nil {
+			return err
+		}
+
+		filterArgs, err := cmd.Flags().GetStringArray("filter")
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 && len(filterArgs) == 0 {
+			return fmt.Errorf("specify one or more containers, or --filter")
+		}
+		if len(args) > 0 && len(filterArgs) > 0 {
+			return fmt.Errorf("containers cannot be combined with --filter")
+		}
+		filters, err := parseFilters(filterArgs)
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		// The containers are looked up even in dry-run mode, so the list of
+		// what would change is complete
+		reader, err := portainer.NewClient(profile.ClientConfig(), append(GetClientOptions(), portainer.WithDryRun(false))...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		containerService := portainer.NewContainerService(c)
+		readService := portainer.NewContainerService(reader)
+
+		containers := args
+		if len(filters) > 0 {
+			matches, err := readService.ListWithFilters(endpointID, true, filters)
+			if err != nil {
+				return err
+			}
+			containers = make([]string, 0, len(matches))
+			for _, match := range matches {
+				containers = append(containers, match.Id)
+			}
+		}
+		if len(containers) == 0 {
+			if !GetQuiet() {
+				fmt.Println("No containers match the filters")
+			}
+			return nil
+		}
+
+		changes := pool.Map(GetParallel(), containers, func(container string) restartPolicyChange {
+			return setRestartPolicy(containerService, readService, endpointID, container, policy)
+		})
+
+		var failed int
+		for _, change := range changes {
+			if change.Status == restartPolicyFailed {
+				failed++
+				fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("failed to update container %s: %s", change.Container, change.Error)))
+			}
+			for _, warning := range change.Warnings {
+				fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("container %s: %s", change.Container, warning)))
+			}
+		}
+
+		if GetQuiet() {
+			for _, change := range changes {
+				if change.Status == restartPolicyUpdated {
+					fmt.Println(change.ID)
+				}
+			}
+		} else {
+			format := getOutputFormat()
+			switch format {
+			case output.FormatJSON, output.FormatYAML:
+				if err := newFormatter(format).Format(changes); err != nil {
+					return err
+				}
+			default:
+				table := output.NewTableData([]string{"Container", "ID", "From", "To", "Status"})
+				for _, change := range changes {
+					table.AddRow([]string{change.Container, shortContainerID(change.ID), change.From, change.To, change.Status})
+				}
+				if err := output.PrintTable(*table); err != nil {
+					return err
+				}
+				fmt.Printf("\n%s\n", restartPolicySummary(changes))
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("failed to update %d of %d containers", failed, len(changes))
+		}
+		return nil
+	},
+}
+
+// setRestartPolicy changes the restart policy of a container unless it has
+// the policy already. In dry-run mode the update is only printed.
+func setRestartPolicy(containerService, readService *portainer.ContainerService, endpointID int, container string, policy portainer.RestartPolicy) restartPolicyChange {
+	change := restartPolicyChange{Container: container, ID: container, To: policy.String()}
+
+	details, err := readService.Inspect(endpointID, container)
+	if err != nil {
+		change.Status = restartPolicyFailed
+		change.Error = err.Error()
+		return change
+	}
+	change.Container = strings.TrimPrefix(details.Name, "/")
+	change.ID = details.Id
+	change.From = details.HostConfig.RestartPolicy.String()
+
+	if change.From == change.To {
+		change.Status = restartPolicyUnchanged
+		return change
+	}
+	// The engine rejects restart policies for containers removed on exit
+	if details.HostConfig.AutoRemove && policy.Name != portainer.RestartPolicyNo {
+		change.Status = restartPolicySkipped
+		change.Warnings = []string{"skipped, the container is removed on exit (--rm) and cannot have a restart policy"}
+		return change
+	}
+
+	warnings, err := containerService.UpdateRestartPolicy(endpointID, details.Id, policy)
+	switch {
+	case err != nil:
+		change.Status = restartPolicyFailed
+		change.Error = err.Error()
+	case GetDryRun():
+		change.Status = restartPolicyPlanned
+	default:
+		change.Status = restartPolicyUpdated
+		change.Warnings = warnings
+	}
+	return change
+}
+
+// restartPolicySummary counts the containers per outcome, e.g. "2 updated,
+// 1 unchanged"
+func restartPolicySummary(changes []restartPolicyChange) string {
+	counts := map[string]int{}
+	for _, change := range changes {
+		counts[change.Status]++
+	}
+
+	var parts []string
+	for _, status := range []string{restartPolicyUpdated, restartPolicyPlanned, restartPolicyUnchanged, restartPolicySkipped, restartPolicyFailed} {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// shortContainerID returns the 12-character form of a container ID
+func shortContainerID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func init() {
+	containersCmd.AddCommand(containersSetRestartPolicyCmd)
+
+	containersSetRestartPolicyCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	containersSetRestartPolicyCmd.Flags().String("policy", "", "Restart policy: no, always, unless-stopped, or on-failure[:max-retries] (required)")
+	containersSetRestartPolicyCmd.Flags().StringArray("filter", []string{}, "Filter containers to update (KEY=VALUE, e.g. label=app=web, name=web)")
+	_ = containersSetRestartPolicyCmd.MarkFlagRequired("endpoint")
+	_ = containersSetRestartPolicyCmd.MarkFlagRequired("policy")
+}
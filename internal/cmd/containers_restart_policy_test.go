@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestSetRestartPolicy(t *testing.T) {
+	var updates []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/containers/web/json"):
+			w.Write([]byte(`{"Id":"aaaaaaaaaaaaaaaa","Name":"/web","HostConfig":{"RestartPolicy":{"Name":"no"}}}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/containers/db/json"):
+			w.Write([]byte(`{"Id":"bbbbbbbbbbbbbbbb","Name":"/db","HostConfig":{"RestartPolicy":{"Name":"unless-stopped"}}}`))
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/containers/job/json"):
+			w.Write([]byte(`{"Id":"cccccccccccccccc","Name":"/job","HostConfig":{"RestartPolicy":{"Name":"no"},"AutoRemove":true}}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/update"):
+			updates = append(updates, r.URL.Path)
+			w.Write([]byte(`{"Warnings":null}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"No such container"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := portainer.NewClient(&portainer.Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service := portainer.NewContainerService(client)
+	policy := portainer.RestartPolicy{Name: portainer.RestartPolicyUnlessStopped}
+
+	changes := []restartPolicyChange{
+		setRestartPolicy(service, service, 1, "web", policy),
+		setRestartPolicy(service, service, 1, "db", policy),
+		setRestartPolicy(service, service, 1, "missing", policy),
+		setRestartPolicy(service, service, 1, "job", policy),
+	}
+
+	if c := changes[0]; c.Container != "web" || c.From != "no" || c.To != "unless-stopped" || c.Status != restartPolicyUpdated {
+		t.Errorf("unexpected change: %+v", c)
+	}
+	if c := changes[1]; c.Status != restartPolicyUnchanged {
+		t.Errorf("expected db to be unchanged, got %+v", c)
+	}
+	if c := changes[2]; c.Status != restartPolicyFailed || c.Error == "" {
+		t.Errorf("expected missing to fail, got %+v", c)
+	}
+	if c := changes[3]; c.Status != restartPolicySkipped || len(c.Warnings) != 1 {
+		t.Errorf("expected the auto-removed job to be skipped, got %+v", c)
+	}
+	if len(updates) != 1 || updates[0] != "/api/endpoints/1/docker/containers/aaaaaaaaaaaaaaaa/update" {
+		t.Errorf("unexpected updates: %v", updates)
+	}
+
+	expected := "1 updated, 1 unchanged, 1 skipped, 1 failed"
+	if got := restartPolicySummary(changes); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/notify"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+// containerStreamBatch is the number of rows that size the columns of a
+// streamed container table
+const containerStreamBatch = 200
+
+// streamContainers prints the containers of an environment while the list
+// is decoded, so memory stays flat and the first rows show up early on
+// hosts with thousands of containers. --offset and --limit are applied on
+// the fly; --sort needs the whole list and is not supported. The --notify-on
+// values count every container, including those not printed.
+func streamContainers(containerService *portainer.ContainerService, endpointID int, opts portainer.ContainerListOptions, listOpts listOptions) (notify.Values, error) {
+	values := newContainerWatchValues()
+
+	var table *output.TableStream
+	if !GetQuiet() {
+		table = output.NewTableStream(os.Stdout, containerListHeaders(opts.Size), containerStreamBatch)
+	}
+
+	index := 0
+	err := containerService.Each(endpointID, opts, func(container *portainer.Container) error {
+		addContainerWatchValues(values, container)
+
+		shown := index >= listOpts.offset && (listOpts.limit == 0 || index < listOpts.offset+listOpts.limit)
+		index++
+		if !shown {
+			return nil
+		}
+
+		if table == nil {
+			_, err := fmt.Println(container.GetShortID())
+			return err
+		}
+		return table.AddRow(containerListRow(container, opts.Size))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if table != nil {
+		if err := table.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func containerListHeaders(size bool) []string {
+	headers := []string{"ID", "Name", "Image", "Created", "Status", "Health", "Ports"}
+	if size {
+		headers = append(headers, "Size", "Virtual")
+	}
+	return headers
+}
+
+// containerListRow renders a container as a row of the containers list table
+func containerListRow(container *portainer.Container, size bool) []string {
+	ports := container.GetPorts()
+	if len(ports) > 50 {
+		ports = output.TruncateString(ports, 50)
+	}
+	healthStatus := container.GetHealth()
+	if healthStatus == portainer.HealthStatusNone {
+		healthStatus = "-"
+	}
+	row := []string{
+		container.GetShortID(),
+		container.GetName(),
+		container.Image,
+		output.FormatDuration(int64(time.Since(time.Unix(container.Created, 0)).Seconds())),
+		container.GetStatus(),
+		healthStatus,
+		ports,
+	}
+	if size {
+		row = append(row, output.FormatSize(container.SizeRw), output.FormatSize(container.SizeRootFs))
+	}
+	return row
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestStreamContainers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"Id": "aaaaaaaaaaaa1", "State": "running", "Status": "Up 1 hour (healthy)"},
+			{"Id": "bbbbbbbbbbbb2", "State": "exited", "Status": "Exited (0) 1 hour ago"},
+			{"Id": "cccccccccccc3", "State": "running", "Status": "Up 2 hours"},
+			{"Id": "dddddddddddd4", "State": "running", "Status": "Up 3 hours"}
+		]`))
+	}))
+	defer server.Close()
+
+	client, err := portainer.NewClient(&portainer.Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	savedQuiet, stdout := quiet, os.Stdout
+	defer func() { quiet, os.Stdout = savedQuiet, stdout }()
+	quiet = true
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+
+	values, err := streamContainers(portainer.NewContainerService(client), 1, portainer.ContainerListOptions{All: true}, listOptions{offset: 1, limit: 2})
+	w.Close()
+	os.Stdout = stdout
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	printed, _ := io.ReadAll(r)
+	if string(printed) != "bbbbbbbbbbbb\ncccccccccccc\n" {
+		t.Errorf("expected the second and third container, got %q", printed)
+	}
+	if values["count"] != 4 || values["running"] != 3 || values["exited"] != 1 || values["healthy"] != 1 {
+		t.Errorf("expected values counting every container, got %v", values)
+	}
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContainersWaitHealthyTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The engine never answers the inspect request
+		if r.URL.Path == "/api/endpoints/1/docker/containers/web/json" {
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	err := executeCommand(t, server.URL, "containers", "wait-healthy", "web", "--endpoint", "1", "--timeout", "200ms")
+	if err == nil || !strings.Contains(err.Error(), "timed out after 200ms") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the timeout to bound the inspect request, took %s", elapsed)
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/terminal"
+	"github.com/spf13/cobra"
+)
+
+var ctxCmd = &cobra.Command{
+	Use:   "ctx [profile]",
+	Short: "Switch the current profile",
+	Long: `Switch the current profile. With a profile name this is the same as
+'config use-profile'. Without arguments an interactive list of profiles is
+shown: type to filter, use the arrow keys to select and press enter to switch.
+
+When stdin is not a terminal the profile names are printed instead, with the
+current profile marked by '*'.
+
+Examples:
+  # Pick a profile interactively
+  portainer-cli ctx
+
+  # Switch directly
+  portainer-cli ctx production`,
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return cfg.ListProfiles(), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if len(args) == 1 {
+			return switchProfile(cfg, args[0])
+		}
+
+		if len(cfg.Profiles) == 0 {
+			return fmt.Errorf("no profiles configured, create one with 'config create-profile'")
+		}
+
+		names := cfg.ListProfiles()
+		sort.Strings(names)
+
+		if !terminal.IsTerminal(os.Stdin) || !terminal.IsTerminal(os.Stdout) {
+			for _, name := range names {
+				marker := "  "
+				if name == cfg.CurrentProfile {
+					marker = "* "
+				}
+				fmt.Println(marker + name)
+			}
+			return nil
+		}
+
+		index, err := terminal.Pick(os.Stdin, os.Stdout, "profile> ", profileChoices(cfg, names), currentProfileIndex(cfg, names))
+		if errors.Is(err, terminal.ErrCancelled) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if names[index] == cfg.CurrentProfile {
+			fmt.Printf("Already using profile '%s'\n", names[index])
+			return nil
+		}
+		return switchProfile(cfg, names[index])
+	},
+}
+
+// profileChoices formats the picker entries for the given profile names,
+// marking the current profile and showing each profile's URL
+func profileChoices(cfg *config.Config, names []string) []string {
+	width := 0
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+
+	choices := make([]string, len(names))
+	for i, name := range names {
+		marker := " "
+		if name == cfg.CurrentProfile {
+			marker = "*"
+		}
+		choices[i] = fmt.Sprintf("%s %-*s  %s", marker, width, name, cfg.Profiles[name].URL)
+	}
+	return choices
+}
+
+func currentProfileIndex(cfg *config.Config, names []string) int {
+	for i, name := range names {
+		if name == cfg.CurrentProfile {
+			return i
+		}
+	}
+	return 0
+}
+
+func init() {
+	rootCmd.AddCommand(ctxCmd)
+}
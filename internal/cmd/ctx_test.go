@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/robversluis/portainer-cli/internal/config"
+)
+
+func TestProfileChoices(t *testing.T) {
+	cfg := &config.Config{
+		CurrentProfile: "staging",
+		Profiles: map[string]*config.Profile{
+			"prod":    {URL: "https://prod.example.com"},
+			"staging": {URL: "https://staging.example.com"},
+		},
+	}
+	names := []string{"prod", "staging"}
+
+	choices := profileChoices(cfg, names)
+	if len(choices) != 2 {
+		t.Fatalf("expected 2 choices, got %d", len(choices))
+	}
+	if choices[0] != "  prod     https://prod.example.com" {
+		t.Errorf("unexpected choice %q", choices[0])
+	}
+	if !strings.HasPrefix(choices[1], "* staging") {
+		t.Errorf("current profile not marked: %q", choices[1])
+	}
+
+	if index := currentProfileIndex(cfg, names); index != 1 {
+		t.Errorf("expected current profile index 1, got %d", index)
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sync"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+// deprecationHint steers users away from a deprecated route the CLI still
+// uses in some cases
+type deprecationHint struct {
+	method string
+	path   *regexp.Regexp
+	hint   string
+}
+
+// deprecationHints are the deprecated routes with a known way around them
+var deprecationHints = []deprecationHint{
+	{
+		// Servers older than 2.19 only have this route, so it is used when
+		// the server version is unknown
+		method: http.MethodPost,
+		path:   regexp.MustCompile(`^/api/stacks$`),
+		hint:   "portainer-cli only creates stacks with this route when the server version is unknown; run with --verbose to see why it could not be detected",
+	},
+	{
+		// Servers older than 2.19 only update namespaces with this route
+		method: http.MethodPut,
+		path:   regexp.MustCompile(`^/api/kubernetes/\d+/namespaces$`),
+		hint:   "portainer-cli only updates namespaces with this route when the server version is unknown; run with --verbose to see why it could not be detected",
+	},
+}
+
+var (
+	// deprecationOut receives the deprecation notices
+	deprecationOut io.Writer = os.Stderr
+	deprecationMu  sync.Mutex
+	// deprecationsShown holds the routes whose deprecation was reported in
+	// this run
+	deprecationsShown = map[string]bool{}
+)
+
+// reportDeprecation prints a notice the first time the server reports a
+// deprecated route in a run, so one command does not repeat it for every
+// request. With --verbose every deprecated route is reported once.
+func reportDeprecation(d portainer.Deprecation) {
+	if GetQuiet() {
+		return
+	}
+
+	deprecationMu.Lock()
+	defer deprecationMu.Unlock()
+	key := d.Method + " " + d.Path
+	if deprecationsShown[key] || (len(deprecationsShown) > 0 && !GetVerbose()) {
+		return
+	}
+	deprecationsShown[key] = true
+
+	notice := "the Portainer API reports that " + d.String()
+	for _, h := range deprecationHints {
+		if h.method == d.Method && h.path.MatchString(d.Path) {
+			notice += "; " + h.hint
+			break
+		}
+	}
+	fmt.Fprintln(deprecationOut, output.Warning(notice))
+}
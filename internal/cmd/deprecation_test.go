@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestReportDeprecation(t *testing.T) {
+	var out bytes.Buffer
+	deprecationOut = &out
+	deprecationsShown = map[string]bool{}
+	defer func() {
+		deprecationOut = os.Stderr
+		deprecationsShown = map[string]bool{}
+	}()
+
+	stacks := portainer.Deprecation{Method: http.MethodPost, Path: "/api/stacks"}
+	reportDeprecation(stacks)
+	reportDeprecation(stacks)
+	reportDeprecation(portainer.Deprecation{Method: http.MethodGet, Path: "/api/endpoints"})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected a single notice, got %q", out.String())
+	}
+	if !strings.Contains(lines[0], "POST /api/stacks is deprecated") || !strings.Contains(lines[0], "server version is unknown") {
+		t.Errorf("unexpected notice %q", lines[0])
+	}
+}
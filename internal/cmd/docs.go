@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Generate the command reference",
+	Long: `Generate man pages or a Markdown reference of all commands from the
+installed version of portainer-cli, so packaged manuals and wikis match it.`,
+}
+
+var docsManCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Generate man pages",
+	Long: `Write a man page for every command to a directory, in section 1.
+
+Example:
+  portainer-cli docs man --dir /usr/local/share/man/man1`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := docsDir(cmd)
+		if err != nil {
+			return err
+		}
+
+		header := &doc.GenManHeader{
+			Title:   "PORTAINER-CLI",
+			Section: "1",
+			Source:  "portainer-cli " + Version,
+			Manual:  "Portainer CLI Manual",
+		}
+		root := docsRoot(cmd)
+		if err := doc.GenManTree(root, header, dir); err != nil {
+			return fmt.Errorf("failed to generate man pages: %w", err)
+		}
+		reportDocs(root, dir)
+		return nil
+	},
+}
+
+var docsMarkdownCmd = &cobra.Command{
+	Use:     "markdown",
+	Aliases: []string{"md"},
+	Short:   "Generate a Markdown reference",
+	Long: `Write a Markdown page for every command to a directory, linked to the pages
+of their parent and subcommands.
+
+Example:
+  portainer-cli docs markdown --dir ./wiki/portainer-cli`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := docsDir(cmd)
+		if err != nil {
+			return err
+		}
+
+		root := docsRoot(cmd)
+		if err := doc.GenMarkdownTree(root, dir); err != nil {
+			return fmt.Errorf("failed to generate markdown: %w", err)
+		}
+		reportDocs(root, dir)
+		return nil
+	},
+}
+
+// docsRoot returns the root of the command tree, without the generation
+// date footer so regenerated pages only change with the commands
+func docsRoot(cmd *cobra.Command) *cobra.Command {
+	root := cmd.Root()
+	root.DisableAutoGenTag = true
+	return root
+}
+
+// docsDir creates the --dir directory. A directory that already has files,
+// such as hand-written docs, is only written to with --force.
+func docsDir(cmd *cobra.Command) (string, error) {
+	dir, err := cmd.Flags().GetString("dir")
+	if err != nil {
+		return "", err
+	}
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read directory: %w", err)
+	}
+	if len(entries) > 0 && !force {
+		return "", fmt.Errorf("directory %s is not empty, use --force to write the pages into it", dir)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	return dir, nil
+}
+
+// reportDocs prints the number of pages generated for the command tree,
+// without counting other files of the directory
+func reportDocs(root *cobra.Command, dir string) {
+	if !GetQuiet() {
+		fmt.Printf("Wrote %d pages to %s\n", docsPages(root), dir)
+	}
+}
+
+// docsPages counts the commands the generators write a page for: the
+// available commands that are not help topics
+func docsPages(cmd *cobra.Command) int {
+	if !cmd.IsAvailableCommand() || cmd.IsAdditionalHelpTopicCommand() {
+		return 0
+	}
+	pages := 1
+	for _, sub := range cmd.Commands() {
+		pages += docsPages(sub)
+	}
+	return pages
+}
+
+func init() {
+	rootCmd.AddCommand(docsCmd)
+	docsCmd.AddCommand(docsManCmd)
+	docsCmd.AddCommand(docsMarkdownCmd)
+
+	docsManCmd.Flags().String("dir", "man", "Directory to write the man pages to")
+	docsManCmd.Flags().Bool("force", false, "Write the pages into a directory that is not empty")
+	docsMarkdownCmd.Flags().String("dir", "reference", "Directory to write the Markdown pages to")
+	docsMarkdownCmd.Flags().Bool("force", false, "Write the pages into a directory that is not empty")
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestDocsCommands(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		cmd  *cobra.Command
+		page string
+		want string
+	}{
+		{
+			name: "markdown",
+			cmd:  docsMarkdownCmd,
+			page: "portainer-cli_stacks_deploy.md",
+			want: "## portainer-cli stacks deploy",
+		},
+		{
+			name: "man",
+			cmd:  docsManCmd,
+			page: "portainer-cli-stacks-deploy.1",
+			want: `.TH "PORTAINER-CLI" "1"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(dir, tt.name)
+			if err := tt.cmd.Flags().Set("dir", out); err != nil {
+				t.Fatalf("failed to set --dir: %v", err)
+			}
+
+			if err := tt.cmd.RunE(tt.cmd, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(out, tt.page))
+			if err != nil {
+				t.Fatalf("expected page %s: %v", tt.page, err)
+			}
+			if !strings.Contains(string(data), tt.want) {
+				t.Errorf("expected %q in %s:\n%s", tt.want, tt.page, data)
+			}
+			if strings.Contains(string(data), "Auto generated by spf13/cobra") {
+				t.Errorf("expected no generation date in %s", tt.page)
+			}
+
+			pages, err := os.ReadDir(out)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := docsPages(tt.cmd.Root()); got != len(pages) {
+				t.Errorf("expected a count of %d pages, got %d", len(pages), got)
+			}
+
+			// The pages are not written over existing files without --force
+			err = tt.cmd.RunE(tt.cmd, nil)
+			if err == nil || !strings.Contains(err.Error(), "--force") {
+				t.Errorf("expected an error for the non-empty directory, got %v", err)
+			}
+			if err := tt.cmd.Flags().Set("force", "true"); err != nil {
+				t.Fatalf("failed to set --force: %v", err)
+			}
+			defer tt.cmd.Flags().Set("force", "false")
+			if err := tt.cmd.RunE(tt.cmd, nil); err != nil {
+				t.Errorf("unexpected error with --force: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,497 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// doctorDialTimeout limits the DNS lookup and the TLS handshake
+	doctorDialTimeout = 10 * time.Second
+	// certExpiryWarning is how long before its expiry a certificate is reported
+	certExpiryWarning = 30 * 24 * time.Hour
+	// clockSkewWarning and clockSkewError are the differences to the server
+	// clock that are reported. Portainer rejects tokens issued "in the
+	// future", so large skews break logins.
+	clockSkewWarning = 30 * time.Second
+	clockSkewError   = 5 * time.Minute
+)
+
+// Results of a doctor check
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose connectivity and authentication problems",
+	Long: `Check the configuration and the connection to Portainer step by step and
+print a hint for every problem found:
+
+  - the config file is valid
+  - the host name of the URL resolves
+  - the TLS handshake succeeds and the certificate chain is trusted
+  - the Portainer API answers
+  - the local clock agrees with the server clock
+  - the API key or token is accepted
+  - the environments are up, and the one of --endpoint answers
+
+The command exits with an error when a check fails.
+
+Example:
+  portainer-cli doctor
+  portainer-cli doctor --profile production --endpoint 3`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+
+		var checks []doctorCheck
+		if configPath, err := doctorConfigPath(); err == nil {
+			checks = append(checks, checkConfigFile(configPath))
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			checks = append(checks, doctorCheck{
+				Name:   "Profile",
+				Status: checkFail,
+				Detail: err.Error(),
+				Hint:   `run "portainer-cli init" to set up a profile, or pass --url and --api-key`,
+			})
+		} else {
+			d := &doctor{
+				profile:    profile,
+				endpointID: endpointID,
+				options:    append(GetClientOptions(), portainer.WithDryRun(false), portainer.WithMaxRetries(0)),
+			}
+			checks = append(checks, d.run()...)
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			if err := newFormatter(format).Format(checks); err != nil {
+				return err
+			}
+		default:
+			printDoctorChecks(checks)
+		}
+
+		failed := 0
+		for _, check := range checks {
+			if check.Status == checkFail {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+		}
+		return nil
+	},
+}
+
+// doctorCheck is the result of one diagnostic step
+type doctorCheck struct {
+	Name string `json:"Name"`
+	// Status is ok, warn, fail or skip
+	Status string `json:"Status"`
+	Detail string `json:"Detail"`
+	// Chain describes the certificates presented by the server
+	Chain []string `json:"Chain,omitempty"`
+	Hint  string   `json:"Hint,omitempty"`
+}
+
+// doctor runs the network checks against the URL of a profile
+type doctor struct {
+	profile    *config.Profile
+	endpointID int
+	options    []portainer.ClientOption
+	// serverTime is the Date header of the status response
+	serverTime time.Time
+}
+
+func doctorConfigPath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	return config.GetConfigPath()
+}
+
+// checkConfigFile validates the config file at path against the schema
+func checkConfigFile(path string) doctorCheck {
+	check := doctorCheck{Name: "Config file"}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		check.Status = checkWarn
+		check.Detail = fmt.Sprintf("%s does not exist", path)
+		check.Hint = `run "portainer-cli init" to create it`
+		return check
+	}
+	if err != nil {
+		check.Status = checkFail
+		check.Detail = err.Error()
+		check.Hint = "check the permissions of the config file"
+		return check
+	}
+
+	if errs := config.Validate(data); len(errs) > 0 {
+		messages := make([]string, len(errs))
+		for i, e := range errs {
+			messages[i] = e.Error()
+		}
+		check.Status = checkFail
+		check.Detail = fmt.Sprintf("%s: %s", path, strings.Join(messages, "; "))
+		check.Hint = `fix the file with "portainer-cli config edit", which validates changes before saving`
+		return check
+	}
+
+	check.Status = checkOK
+	check.Detail = path
+	return check
+}
+
+// run performs the checks in order. Checks that depend on a failed one are
+// skipped.
+func (d *doctor) run() []doctorCheck {
+	u, err := neturl.Parse(d.profile.URL)
+	if err != nil || u.Hostname() == "" {
+		return []doctorCheck{{
+			Name:   "URL",
+			Status: checkFail,
+			Detail: fmt.Sprintf("invalid URL '%s'", d.profile.URL),
+			Hint:   `set a URL such as https://portainer.example.com with "portainer-cli config set url"`,
+		}}
+	}
+
+	checks := []doctorCheck{d.checkDNS(u.Hostname())}
+	if checks[0].Status == checkFail {
+		return checks
+	}
+
+	tlsCheck := d.checkTLS(u)
+	checks = append(checks, tlsCheck)
+	if tlsCheck.Status == checkFail && !d.profile.Insecure {
+		return checks
+	}
+
+	c, apiCheck := d.checkAPI()
+	checks = append(checks, apiCheck)
+	if apiCheck.Status == checkFail {
+		return checks
+	}
+
+	checks = append(checks, d.checkClock(time.Now()))
+
+	authCheck := d.checkAuth(c)
+	checks = append(checks, authCheck)
+	if authCheck.Status == checkFail {
+		return checks
+	}
+
+	return append(checks, d.checkEnvironments(c)...)
+}
+
+func (d *doctor) checkDNS(host string) doctorCheck {
+	check := doctorCheck{Name: "DNS"}
+	if net.ParseIP(host) != nil {
+		check.Status = checkSkip
+		check.Detail = fmt.Sprintf("%s is an IP address", host)
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorDialTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		check.Status = checkFail
+		check.Detail = err.Error()
+		check.Hint = "check the host name in the URL, your DNS settings, and whether a VPN is needed to reach it"
+		return check
+	}
+
+	check.Status = checkOK
+	check.Detail = fmt.Sprintf("%s resolves to %s", host, strings.Join(addrs, ", "))
+	return check
+}
+
+func (d *doctor) checkTLS(u *neturl.URL) doctorCheck {
+	check := doctorCheck{Name: "TLS"}
+	if u.Scheme != "https" {
+		check.Status = checkSkip
+		check.Detail = "plain HTTP, credentials are sent unencrypted"
+		return check
+	}
+
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "443")
+	}
+
+	// The chain is verified below, so it can be described even when it is
+	// not trusted
+	dialer := &net.Dialer{Timeout: doctorDialTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		check.Status = checkFail
+		check.Detail = err.Error()
+		check.Hint = fmt.Sprintf("check that Portainer listens on %s and that no firewall or proxy blocks it", address)
+		return check
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	certs := state.PeerCertificates
+	for _, cert := range certs {
+		check.Chain = append(check.Chain, fmt.Sprintf("%s (issued by %s, expires %s)",
+			cert.Subject.CommonName, cert.Issuer.CommonName, cert.NotAfter.Format("2006-01-02")))
+	}
+	if len(certs) == 0 {
+		check.Status = checkFail
+		check.Detail = "the server presented no certificate"
+		return check
+	}
+
+	leaf := certs[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, verifyErr := leaf.Verify(x509.VerifyOptions{DNSName: u.Hostname(), Intermediates: intermediates})
+	remaining := time.Until(leaf.NotAfter)
+
+	switch {
+	case remaining < 0:
+		check.Status = checkFail
+		check.Detail = fmt.Sprintf("the certificate expired on %s", leaf.NotAfter.Format("2006-01-02"))
+		check.Hint = "renew the TLS certificate of Portainer"
+	case verifyErr != nil && d.profile.Insecure:
+		check.Status = checkWarn
+		check.Detail = fmt.Sprintf("%s; accepted because the profile skips TLS verification", verifyErr)
+		check.Hint = "add the CA certificate to the system trust store and set insecure to false"
+	case verifyErr != nil:
+		check.Status = checkFail
+		check.Detail = verifyErr.Error()
+		check.Hint = `add the CA certificate to the system trust store, or skip verification with "portainer-cli config set insecure true"`
+	case remaining < certExpiryWarning:
+		check.Status = checkWarn
+		check.Detail = fmt.Sprintf("%s, the certificate expires in %d days", tls.VersionName(state.Version), int(remaining.Hours()/24))
+		check.Hint = "renew the TLS certificate of Portainer"
+	default:
+		check.Status = checkOK
+		check.Detail = fmt.Sprintf("%s, certificate valid until %s", tls.VersionName(state.Version), leaf.NotAfter.Format("2006-01-02"))
+	}
+	return check
+}
+
+func (d *doctor) checkAPI() (*portainer.Client, doctorCheck) {
+	check := doctorCheck{Name: "API"}
+
+	c, err := portainer.NewClient(d.profile.ClientConfig(), d.options...)
+	if err != nil {
+		check.Status = checkFail
+		check.Detail = err.Error()
+		check.Hint = `set an API key with "portainer-cli config set api_key", or log in with "portainer-cli auth login"`
+		return nil, check
+	}
+
+	start := time.Now()
+	resp, err := c.RawRequest(http.MethodGet, "status", nil, nil)
+	elapsed := time.Since(start)
+	if err != nil {
+		check.Status = checkFail
+		check.Detail = err.Error()
+		check.Hint = "check that Portainer is running and that the URL points at it"
+		if portainer.IsNotFoundError(err) {
+			check.Hint = "the URL does not point at Portainer; use its address without a path, such as https://portainer.example.com"
+		}
+		return nil, check
+	}
+
+	var status portainer.StatusResponse
+	if err := json.Unmarshal(resp.Body, &status); err != nil || status.Version == "" {
+		check.Status = checkFail
+		check.Detail = "the response is not a Portainer status"
+		check.Hint = "check that the URL points at Portainer and not at a proxy or login page"
+		return nil, check
+	}
+
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		d.serverTime = date
+	}
+
+	check.Status = checkOK
+	check.Detail = fmt.Sprintf("Portainer %s answered in %s", status.Version, elapsed.Round(time.Millisecond))
+	return c, check
+}
+
+// checkClock compares now to the server time of the status response
+func (d *doctor) checkClock(now time.Time) doctorCheck {
+	check := doctorCheck{Name: "Clock"}
+	if d.serverTime.IsZero() {
+		check.Status = checkSkip
+		check.Detail = "the server did not send its time"
+		return check
+	}
+
+	skew := now.Sub(d.serverTime)
+	if skew < 0 {
+		skew = -skew
+	}
+	// The Date header has a resolution of one second
+	skew = skew.Truncate(time.Second)
+
+	switch {
+	case skew >= clockSkewError:
+		check.Status = checkFail
+		check.Hint = "synchronize the clocks of this machine and the Portainer host with NTP; tokens are rejected otherwise"
+	case skew >= clockSkewWarning:
+		check.Status = checkWarn
+		check.Hint = "synchronize the clocks of this machine and the Portainer host with NTP"
+	default:
+		check.Status = checkOK
+	}
+	check.Detail = fmt.Sprintf("local clock differs from the server by %s", skew)
+	return check
+}
+
+func (d *doctor) checkAuth(c *portainer.Client) doctorCheck {
+	check := doctorCheck{Name: "Authentication"}
+
+	method := "API key"
+	hint := `create an API key under My account > Access tokens and set it with "portainer-cli config set api_key"`
+	if d.profile.APIKey == "" {
+		method = "token"
+		hint = `the session has probably expired; run "portainer-cli auth login"`
+	}
+
+	if _, err := portainer.NewAuthService(c).ValidateToken(); err != nil {
+		check.Status = checkFail
+		check.Detail = fmt.Sprintf("the %s was rejected: %v", method, err)
+		check.Hint = hint
+		if !portainer.IsUnauthorizedError(errors.Unwrap(err)) {
+			check.Hint = "check the Portainer logs for the cause"
+		}
+		return check
+	}
+
+	check.Status = checkOK
+	check.Detail = fmt.Sprintf("the %s is accepted", method)
+	return check
+}
+
+// checkEnvironments reports environments that are down, and pings the
+// --endpoint environment
+func (d *doctor) checkEnvironments(c *portainer.Client) []doctorCheck {
+	check := doctorCheck{Name: "Environments"}
+
+	environments, err := portainer.NewEnvironmentService(c).List()
+	if err != nil {
+		check.Status = checkFail
+		check.Detail = err.Error()
+		check.Hint = "the user may lack the permission to list environments"
+		return []doctorCheck{check}
+	}
+
+	var down []string
+	for _, env := range environments {
+		if env.Status != portainer.EnvironmentStatusUp {
+			down = append(down, env.Name)
+		}
+	}
+	switch {
+	case len(environments) == 0:
+		check.Status = checkWarn
+		check.Detail = "no environments are available"
+		check.Hint = "add an environment in Portainer, or ask an administrator for access"
+	case len(down) > 0:
+		check.Status = checkWarn
+		check.Detail = fmt.Sprintf("%d of %d environments are down: %s", len(down), len(environments), strings.Join(down, ", "))
+		check.Hint = `check the agents of these environments; "portainer-cli environments status" shows details`
+	default:
+		check.Status = checkOK
+		check.Detail = fmt.Sprintf("all %d environments are up", len(environments))
+	}
+
+	checks := []doctorCheck{check}
+	if d.endpointID == 0 {
+		return checks
+	}
+
+	endpointCheck := doctorCheck{Name: fmt.Sprintf("Environment %d", d.endpointID)}
+	var env *portainer.Environment
+	for i := range environments {
+		if environments[i].Id == d.endpointID {
+			env = &environments[i]
+		}
+	}
+	switch {
+	case env == nil:
+		endpointCheck.Status = checkFail
+		endpointCheck.Detail = "the environment does not exist or is not accessible"
+		endpointCheck.Hint = `list the available environments with "portainer-cli environments list"`
+	case !env.IsDocker():
+		endpointCheck.Status = checkSkip
+		endpointCheck.Detail = fmt.Sprintf("%s is not a Docker environment", env.Name)
+	default:
+		if _, err := c.RawRequest(http.MethodGet, fmt.Sprintf("endpoints/%d/docker/_ping", env.Id), nil, nil); err != nil {
+			endpointCheck.Status = checkFail
+			endpointCheck.Detail = fmt.Sprintf("Docker on %s does not answer: %v", env.Name, err)
+			endpointCheck.Hint = "check that the Docker engine and the Portainer agent are running on the host"
+		} else {
+			endpointCheck.Status = checkOK
+			endpointCheck.Detail = fmt.Sprintf("Docker on %s answers", env.Name)
+		}
+	}
+	return append(checks, endpointCheck)
+}
+
+func printDoctorChecks(checks []doctorCheck) {
+	for _, check := range checks {
+		var label string
+		switch check.Status {
+		case checkOK:
+			label = output.Colorize("OK  ", output.ColorGreen)
+		case checkWarn:
+			label = output.Colorize("WARN", output.ColorYellow)
+		case checkFail:
+			label = output.Colorize("FAIL", output.ColorRed)
+		default:
+			label = "SKIP"
+		}
+
+		fmt.Printf("%s  %-16s %s\n", label, check.Name, check.Detail)
+		for _, cert := range check.Chain {
+			fmt.Printf("      %-16s %s\n", "", cert)
+		}
+		if check.Hint != "" {
+			fmt.Printf("      %-16s Hint: %s\n", "", check.Hint)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().Int("endpoint", 0, "Environment endpoint ID to check (default: the default environment of the profile)")
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestCheckConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	check := checkConfigFile(filepath.Join(dir, "missing.yaml"))
+	if check.Status != checkWarn {
+		t.Errorf("expected a warning for a missing file, got %+v", check)
+	}
+
+	path := filepath.Join(dir, "config.yaml")
+	os.WriteFile(path, []byte("profiles:\n  prod:\n    url: https://prod.example.com\n    insecure: maybe\n"), 0600)
+	check = checkConfigFile(path)
+	if check.Status != checkFail || !strings.Contains(check.Detail, "invalid boolean 'maybe'") {
+		t.Errorf("expected a validation failure, got %+v", check)
+	}
+
+	os.WriteFile(path, []byte("profiles:\n  prod:\n    url: https://prod.example.com\n"), 0600)
+	if check = checkConfigFile(path); check.Status != checkOK {
+		t.Errorf("expected a valid file, got %+v", check)
+	}
+}
+
+func TestDoctor_CheckClock(t *testing.T) {
+	now := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		skew     time.Duration
+		expected string
+	}{
+		{2 * time.Second, checkOK},
+		{-time.Minute, checkWarn},
+		{10 * time.Minute, checkFail},
+	}
+	for _, tt := range tests {
+		d := &doctor{serverTime: now.Add(tt.skew)}
+		if check := d.checkClock(now); check.Status != tt.expected {
+			t.Errorf("skew %s: expected %s, got %+v", tt.skew, tt.expected, check)
+		}
+	}
+
+	if check := (&doctor{}).checkClock(now); check.Status != checkSkip {
+		t.Errorf("expected the check to be skipped without a server time, got %+v", check)
+	}
+}
+
+func TestDoctor_Run(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/status" && r.Header.Get("X-API-Key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/status":
+			json.NewEncoder(w).Encode(portainer.StatusResponse{Version: "2.19.4"})
+		case "/api/users":
+			json.NewEncoder(w).Encode([]portainer.UserInfo{{ID: 1, Username: "admin"}})
+		case "/api/endpoints":
+			json.NewEncoder(w).Encode([]portainer.Environment{
+				{Id: 1, Name: "local", Type: portainer.EnvironmentTypeDockerLocal, Status: portainer.EnvironmentStatusUp},
+				{Id: 2, Name: "remote", Type: portainer.EnvironmentTypeAgentOnDocker, Status: portainer.EnvironmentStatusDown},
+			})
+		case "/api/endpoints/1/docker/_ping":
+			w.Write([]byte("OK"))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	profile := &config.Profile{URL: server.URL, APIKey: "test-key"}
+	d := &doctor{profile: profile, endpointID: 1, options: []portainer.ClientOption{portainer.WithMaxRetries(0)}}
+
+	// The test certificate is not trusted, so nothing after TLS is checked
+	checks := d.run()
+	if len(checks) != 2 || checks[1].Name != "TLS" || checks[1].Status != checkFail {
+		t.Fatalf("expected a TLS failure, got %+v", checks)
+	}
+	if len(checks[1].Chain) == 0 {
+		t.Error("expected the certificate chain")
+	}
+
+	profile.Insecure = true
+	checks = d.run()
+	statuses := map[string]string{}
+	for _, check := range checks {
+		statuses[check.Name] = check.Status
+	}
+	expected := map[string]string{
+		"DNS":            checkSkip,
+		"TLS":            checkWarn,
+		"API":            checkOK,
+		"Clock":          checkOK,
+		"Authentication": checkOK,
+		"Environments":   checkWarn,
+		"Environment 1":  checkOK,
+	}
+	for name, status := range expected {
+		if statuses[name] != status {
+			t.Errorf("expected %s to be %s, got %+v", name, status, checks)
+		}
+	}
+
+	profile.APIKey = "wrong-key"
+	checks = d.run()
+	last := checks[len(checks)-1]
+	if last.Name != "Authentication" || last.Status != checkFail || !strings.Contains(last.Hint, "API key") {
+		t.Errorf("expected an authentication failure with a hint, got %+v", last)
+	}
+}
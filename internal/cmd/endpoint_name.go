@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+// noDefaultEndpoint is the command annotation that keeps the default
+// environment of the profile out of its --endpoint flag, for commands where
+// omitting --endpoint has a meaning of its own
+const noDefaultEndpoint = "no-default-endpoint"
+
+// fanOutFlag is the command annotation naming a flag, such as
+// --all-endpoints, that runs the command on several environments instead of
+// the one of --endpoint. The default environment of the profile or target is
+// not applied when the flag is set.
+const fanOutFlag = "fan-out-flag"
+
+// fansOut reports whether the fan-out flag of the running command is set
+func fansOut(cmd *cobra.Command) bool {
+	name, ok := cmd.Annotations[fanOutFlag]
+	return ok && cmd.Flags().Changed(name)
+}
+
+// resolveEndpointName sets the --endpoint flag of the running command from
+// the global --endpoint-name flag, or else from the default environment of
+// the profile
+func resolveEndpointName(cmd *cobra.Command, args []string) error {
+	if endpointName == "" {
+		return applyDefaultEndpoint(cmd)
+	}
+
+	flag := cmd.Flags().Lookup("endpoint")
+	if flag == nil {
+		return fmt.Errorf("--endpoint-name is not supported by %s", cmd.CommandPath())
+	}
+	if flag.Changed {
+		return fmt.Errorf("--endpoint and --endpoint-name cannot be used together")
+	}
+
+	profile, err := ResolveProfile(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	id, err := lookupEndpointID(profile, endpointName)
+	if err != nil {
+		return err
+	}
+
+	return cmd.Flags().Set("endpoint", strconv.Itoa(id))
+}
+
+// applyDefaultEndpoint sets an omitted --endpoint flag to the default
+// environment of the profile
+func applyDefaultEndpoint(cmd *cobra.Command) error {
+	flag := cmd.Flags().Lookup("endpoint")
+	if flag == nil || flag.Changed || flag.Value.Type() != "int" {
+		return nil
+	}
+	if _, ok := cmd.Annotations[noDefaultEndpoint]; ok || fansOut(cmd) {
+		return nil
+	}
+
+	// Commands report a missing or invalid profile themselves
+	profile, err := ResolveProfile(cmd)
+	if err != nil || profile.Endpoint == 0 {
+		return nil
+	}
+	return cmd.Flags().Set("endpoint", strconv.Itoa(profile.Endpoint))
+}
+
+// lookupEndpointID resolves an environment name through the endpoint cache,
+// refreshing the cached names of the instance when the name is unknown or
+// its cached ID no longer names the environment
+func lookupEndpointID(profile *config.Profile, name string) (int, error) {
+	cache, err := config.LoadEndpointCache()
+	if err != nil {
+		// A broken cache only costs a refresh
+		if GetVerbose() {
+			fmt.Fprintln(os.Stderr, output.Warning(err.Error()))
+		}
+		cache = &config.EndpointCache{}
+	}
+
+	// The lookup runs even in dry-run mode so the printed requests carry the
+	// resolved ID
+	opts := append(GetClientOptions(), portainer.WithDryRun(false))
+	c, err := portainer.NewClient(profile.ClientConfig(), opts...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create client: %w", err)
+	}
+	envService := portainer.NewEnvironmentService(c)
+
+	// Recorded sessions always include the lookup, so replaying them does not
+	// depend on the contents of the local cache
+	usingCassette := recordDir != "" || replayDir != ""
+	if id, ok := cache.Lookup(profile.URL, name); ok && !usingCassette {
+		// A cached ID is stale once the environment was removed or
+		// recreated; other errors are left to the command to report
+		env, err := envService.Get(id)
+		if !portainer.IsNotFoundError(err) && (err != nil || env.Name == name) {
+			return id, nil
+		}
+		if GetVerbose() {
+			fmt.Fprintf(os.Stderr, "Cached ID %d of environment %s is stale, resolving it again\n", id, name)
+		}
+	}
+
+	environments, err := envService.List()
+	if err != nil {
+		return 0, err
+	}
+
+	endpoints := make(map[string]int, len(environments))
+	for _, env := range environments {
+		endpoints[env.Name] = env.Id
+	}
+
+	cache.Replace(profile.URL, endpoints)
+	if err := cache.Save(); err != nil && GetVerbose() {
+		fmt.Fprintln(os.Stderr, output.Warning(err.Error()))
+	}
+
+	id, ok := endpoints[name]
+	if !ok {
+		return 0, fmt.Errorf("environment '%s' not found", name)
+	}
+	return id, nil
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestResolveEndpointName(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var requests int
+	environments := []portainer.Environment{
+		{Id: 3, Name: "prod"},
+		{Id: 4, Name: "staging"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/endpoints" {
+			requests++
+			json.NewEncoder(w).Encode(environments)
+			return
+		}
+		for _, env := range environments {
+			if r.URL.Path == fmt.Sprintf("/api/endpoints/%d", env.Id) {
+				json.NewEncoder(w).Encode(env)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	viper.Set("url", server.URL)
+	viper.Set("api_key", "test-key")
+	defer viper.Set("url", "")
+	defer viper.Set("api_key", "")
+
+	defer func() { endpointName = "" }()
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().Int("endpoint", 0, "")
+		return cmd
+	}
+
+	endpointName = "staging"
+	cmd := newCmd()
+	if err := resolveEndpointName(cmd, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id, _ := cmd.Flags().GetInt("endpoint"); id != 4 {
+		t.Errorf("expected endpoint 4, got %d", id)
+	}
+
+	// Known names are served from the cache
+	endpointName = "prod"
+	cmd = newCmd()
+	if err := resolveEndpointName(cmd, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id, _ := cmd.Flags().GetInt("endpoint"); id != 3 {
+		t.Errorf("expected endpoint 3, got %d", id)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 list request, got %d", requests)
+	}
+
+	// A recreated environment gets a new ID, so its cached ID is resolved
+	// again
+	environments[0].Id = 5
+	cmd = newCmd()
+	if err := resolveEndpointName(cmd, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id, _ := cmd.Flags().GetInt("endpoint"); id != 5 {
+		t.Errorf("expected endpoint 5, got %d", id)
+	}
+	if requests != 2 {
+		t.Errorf("expected the stale ID to refresh the cache, got %d list requests", requests)
+	}
+
+	endpointName = "missing"
+	if err := resolveEndpointName(newCmd(), nil); err == nil {
+		t.Error("expected error for unknown environment")
+	}
+
+	endpointName = "prod"
+	if err := resolveEndpointName(&cobra.Command{Use: "other"}, nil); err == nil {
+		t.Error("expected error for command without --endpoint")
+	}
+}
+
+func TestApplyDefaultEndpoint(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg := &config.Config{CurrentProfile: "prod", Profiles: map[string]*config.Profile{
+		"prod": {URL: "https://portainer.example.com", APIKey: "key", Endpoint: 3},
+	}}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().Int("endpoint", 0, "")
+		return cmd
+	}
+
+	cmd := newCmd()
+	if err := resolveEndpointName(cmd, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id, _ := cmd.Flags().GetInt("endpoint"); id != 3 {
+		t.Errorf("expected the default endpoint 3, got %d", id)
+	}
+
+	cmd = newCmd()
+	cmd.Flags().Set("endpoint", "5")
+	if err := resolveEndpointName(cmd, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id, _ := cmd.Flags().GetInt("endpoint"); id != 5 {
+		t.Errorf("expected the given endpoint 5, got %d", id)
+	}
+
+	cmd = newCmd()
+	cmd.Annotations = map[string]string{noDefaultEndpoint: ""}
+	if err := resolveEndpointName(cmd, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id, _ := cmd.Flags().GetInt("endpoint"); id != 0 {
+		t.Errorf("expected no default endpoint, got %d", id)
+	}
+
+	cmd = newCmd()
+	cmd.Flags().Bool("all-endpoints", false, "")
+	cmd.Annotations = map[string]string{fanOutFlag: "all-endpoints"}
+	cmd.Flags().Set("all-endpoints", "true")
+	if err := resolveEndpointName(cmd, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id, _ := cmd.Flags().GetInt("endpoint"); id != 0 {
+		t.Errorf("expected no default endpoint with --all-endpoints, got %d", id)
+	}
+}
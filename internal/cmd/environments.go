@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/robversluis/portainer-cli/internal/client"
-	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/notify"
 	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
 	"github.com/spf13/cobra"
 )
 
@@ -21,48 +21,108 @@ var environmentsListCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List all environments",
-	Long:    `Display a list of all Portainer environments with their status and details.`,
+	Long: `Display a list of all Portainer environments with their status and details.
+Servers that paginate the list are paged through; use --no-paginate to fetch
+only the first page.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		profile, err := config.GetProfileFromViper()
+		listOpts, err := getListOptions(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to get profile: %w", err)
+			return err
+		}
+		noPaginate, err := cmd.Flags().GetBool("no-paginate")
+		if err != nil {
+			return err
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to create client: %w", err)
+			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		envService := client.NewEnvironmentService(c)
-		environments, err := envService.List()
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		envService := portainer.NewEnvironmentService(c)
+		format := getOutputFormat()
+
+		var values notify.Values
+		listFunc := func() error {
+			// Server-side paging adjusts the options, so each refresh starts
+			// from a fresh copy
+			listOpts := listOpts
+
+			var environments []portainer.Environment
+			var err error
+			if listOpts.limit > 0 && listOpts.sort == "" {
+				// Let the server do the paging when no client-side ordering is
+				// needed. Portainer ignores an offset without a limit, so
+				// --offset alone is applied below.
+				var total int
+				environments, total, err = envService.ListPage(listOpts.offset, listOpts.limit)
+				if err != nil {
+					return err
+				}
+				// Servers without pagination return every environment
+				if total >= 0 {
+					listOpts.offset = 0
+					listOpts.limit = 0
+				}
+			} else if noPaginate {
+				var total int
+				environments, total, err = envService.ListPage(0, portainer.PageSize)
+				if err != nil {
+					return err
+				}
+				warnFirstPage(len(environments), total, "environments")
+			} else {
+				environments, err = envService.List()
+				if err != nil {
+					return err
+				}
+			}
 
-		switch format {
-		case output.FormatJSON, output.FormatYAML:
-			formatter := output.NewFormatter(output.Options{Format: format})
-			return formatter.Format(environments)
+			values = environmentWatchValues(environments)
 
-		default:
-			table := output.NewTableData([]string{"ID", "Name", "Type", "URL", "Status"})
-			for _, env := range environments {
-				url := env.URL
-				if len(url) > 40 {
-					url = output.TruncateString(url, 40)
-				}
-				table.AddRow([]string{
-					fmt.Sprintf("%d", env.Id),
-					env.Name,
-					env.TypeString(),
-					url,
-					env.StatusString(),
+			if GetQuiet() {
+				return printQuiet(listOpts, environments, func(item portainer.Environment) string {
+					return strconv.Itoa(item.Id)
 				})
 			}
-			return output.PrintTable(*table)
+
+			switch format {
+			case output.FormatJSON, output.FormatYAML:
+				formatter := newFormatter(format)
+				items, err := listOpts.applyItems(environments)
+				if err != nil {
+					return err
+				}
+				return formatter.Format(items)
+
+			default:
+				table := output.NewTableData([]string{"ID", "Name", "Type", "URL", "Status"})
+				for _, env := range environments {
+					url := env.URL
+					if len(url) > 40 {
+						url = output.TruncateString(url, 40)
+					}
+					table.AddRow([]string{
+						fmt.Sprintf("%d", env.Id),
+						env.Name,
+						env.TypeString(),
+						url,
+						env.StatusString(),
+					})
+				}
+				if err := listOpts.applyTable(table, environments); err != nil {
+					return err
+				}
+				return output.PrintTable(*table)
+			}
 		}
+
+		return RunWithWatch(cmd, "environments", listFunc, func() notify.Values { return values })
 	},
 }
 
@@ -72,37 +132,26 @@ var environmentsGetCmd = &cobra.Command{
 	Long:  `Retrieve detailed information about a specific environment by ID or name.`,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		envService := client.NewEnvironmentService(c)
-
-		var env *client.Environment
-
-		if id, err := strconv.Atoi(args[0]); err == nil {
-			env, err = envService.Get(id)
-			if err != nil {
-				return err
-			}
-		} else {
-			env, err = envService.GetByName(args[0])
-			if err != nil {
-				return err
-			}
+		env, err := resolveEnvironment(portainer.NewEnvironmentService(c), args[0])
+		if err != nil {
+			return err
 		}
 
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		format := getOutputFormat()
 
 		switch format {
 		case output.FormatJSON, output.FormatYAML:
-			formatter := output.NewFormatter(output.Options{Format: format})
+			formatter := newFormatter(format)
 			return formatter.Format(env)
 
 		default:
@@ -113,7 +162,7 @@ var environmentsGetCmd = &cobra.Command{
 			if env.PublicURL != "" {
 				fmt.Printf("Public URL:  %s\n", env.PublicURL)
 			}
-			fmt.Printf("Status:      %s\n", env.StatusString())
+			fmt.Printf("Status:      %s\n", output.ColorizeStatus(env.StatusString()))
 			fmt.Printf("Group ID:    %d\n", env.GroupId)
 
 			if env.EdgeID != "" {
@@ -171,9 +220,35 @@ var environmentsInspectCmd = &cobra.Command{
 	RunE:  environmentsGetCmd.RunE,
 }
 
+// resolveEnvironment looks up an environment by ID or name
+func resolveEnvironment(envService *portainer.EnvironmentService, idOrName string) (*portainer.Environment, error) {
+	if id, err := strconv.Atoi(idOrName); err == nil {
+		return envService.Get(id)
+	}
+	return envService.GetByName(idOrName)
+}
+
+// environmentWatchValues returns the --notify-on variables for
+// environments: the count and the number that are up and down
+func environmentWatchValues(environments []portainer.Environment) notify.Values {
+	values := notify.Values{"count": float64(len(environments)), "up": 0, "down": 0}
+	for _, env := range environments {
+		if env.Status == portainer.EnvironmentStatusUp {
+			values["up"]++
+		} else {
+			values["down"]++
+		}
+	}
+	return values
+}
+
 func init() {
 	rootCmd.AddCommand(environmentsCmd)
 	environmentsCmd.AddCommand(environmentsListCmd)
 	environmentsCmd.AddCommand(environmentsGetCmd)
 	environmentsCmd.AddCommand(environmentsInspectCmd)
+
+	AddWatchFlags(environmentsListCmd)
+	addListFlags(environmentsListCmd)
+	environmentsListCmd.Flags().Bool("no-paginate", false, "Fetch only the first page of environments from servers that paginate the list")
 }
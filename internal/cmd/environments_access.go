@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var environmentsAccessCmd = &cobra.Command{
+	Use:   "access",
+	Short: "Manage user and team access to environments",
+	Long: `Show, grant and revoke the access of users and teams to an environment or
+an environment group. Access granted on a group applies to all environments
+in it.`,
+}
+
+var environmentsAccessShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show who has access to an environment or group",
+	Long: `List the users and teams with access to an environment, including the
+access inherited from its group, or to an environment group, with their
+roles.
+
+Example:
+  portainer-cli environments access show --endpoint 3
+  portainer-cli environments access show --group production -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, groupArg, err := getAccessTarget(cmd)
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		names := accessNames{users: userNames(c), teams: teamNames(c), roles: roleNames(c)}
+		groupService := portainer.NewEnvironmentGroupService(c)
+
+		var entries []accessEntry
+		if endpointID != 0 {
+			env, err := portainer.NewEnvironmentService(c).Get(endpointID)
+			if err != nil {
+				return err
+			}
+			entries = accessEntries("environment", env.UserAccessPolicies, env.TeamAccessPolicies, names)
+
+			group, err := groupService.Get(env.GroupId)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, accessEntries("group "+group.Name, group.UserAccessPolicies, group.TeamAccessPolicies, names)...)
+		} else {
+			group, err := resolveEnvironmentGroup(groupService, groupArg)
+			if err != nil {
+				return err
+			}
+			entries = accessEntries("group "+group.Name, group.UserAccessPolicies, group.TeamAccessPolicies, names)
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			return newFormatter(format).Format(entries)
+		}
+
+		if GetQuiet() {
+			for _, entry := range entries {
+				fmt.Printf("%s:%d\n", entry.Type, entry.ID)
+			}
+			return nil
+		}
+
+		table := output.NewTableData([]string{"Type", "ID", "Name", "Role", "Source"})
+		for _, entry := range entries {
+			role := entry.Role
+			if role == "" {
+				role = "-"
+			}
+			table.AddRow([]string{entry.Type, strconv.Itoa(entry.ID), entry.Name, role, entry.Source})
+		}
+		return output.PrintTable(*table)
+	},
+}
+
+var environmentsAccessGrantCmd = &cobra.Command{
+	Use:   "grant",
+	Short: "Grant users or teams access to an environment or group",
+	Long: `Give users and teams, by ID or name, access to an environment or an
+environment group with an RBAC role (Business Edition, see "roles list").
+On Community Edition, which has no roles, omit --role. Existing access of the
+given users and teams is replaced; the access of others is kept.
+
+Example:
+  portainer-cli environments access grant --team devs --endpoint 3 --role standard
+  portainer-cli environments access grant --user alice --user bob --group production --role "Read-only user"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAccessUpdate(cmd, true)
+	},
+}
+
+var environmentsAccessRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke the access of users or teams to an environment or group",
+	Long: `Remove the access of users and teams, by ID or name, to an environment or
+an environment group. Access inherited from the group of an environment is
+not changed by revoking it on the environment.
+
+Example:
+  portainer-cli environments access revoke --team contractors --endpoint 3
+  portainer-cli environments access revoke --user bob --group production`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAccessUpdate(cmd, false)
+	},
+}
+
+// accessEntry is a user or team with access to an environment or group
+type accessEntry struct {
+	// Type is user or team
+	Type   string `json:"Type"`
+	ID     int    `json:"ID"`
+	Name   string `json:"Name"`
+	RoleID int    `json:"RoleID"`
+	Role   string `json:"Role,omitempty"`
+	// Source is where the access is granted: the environment or its group
+	Source string `json:"Source"`
+}
+
+// accessNames maps user, team and role IDs to names. Missing names are
+// shown as IDs.
+type accessNames struct {
+	users map[int]string
+	teams map[int]string
+	roles map[int]string
+}
+
+// accessEntries lists the user and team policies granted by source, users
+// first, each sorted by name
+func accessEntries(source string, users, teams portainer.AccessPolicies, names accessNames) []accessEntry {
+	var entries []accessEntry
+	add := func(kind string, policies portainer.AccessPolicies, kindNames map[int]string) {
+		start := len(entries)
+		for key, policy := range policies {
+			id, err := strconv.Atoi(key)
+			if err != nil {
+				continue
+			}
+			name := kindNames[id]
+			if name == "" {
+				name = key
+			}
+			role := names.roles[policy.RoleId]
+			if role == "" && policy.RoleId != 0 {
+				role = strconv.Itoa(policy.RoleId)
+			}
+			entries = append(entries, accessEntry{
+				Type:   kind,
+				ID:     id,
+				Name:   name,
+				RoleID: policy.RoleId,
+				Role:   role,
+				Source: source,
+			})
+		}
+		added := entries[start:]
+		sort.Slice(added, func(i, j int) bool { return added[i].Name < added[j].Name })
+	}
+	add("user", users, names.users)
+	add("team", teams, names.teams)
+	return entries
+}
+
+// roleNames maps role IDs to names. Lookup failures, e.g. on Community
+// Edition, leave the map empty.
+func roleNames(c *portainer.Client) map[int]string {
+	names := map[int]string{}
+	roles, err := portainer.NewRoleService(c).List()
+	if err != nil {
+		return names
+	}
+	for _, role := range roles {
+		names[role.Id] = role.Name
+	}
+	return names
+}
+
+// getAccessTarget returns the --endpoint or --group flag, exactly one of
+// which must be set
+func getAccessTarget(cmd *cobra.Command) (int, string, error) {
+	endpointID, err := cmd.Flags().GetInt("endpoint")
+	if err != nil {
+		return 0, "", err
+	}
+	group, err := cmd.Flags().GetString("group")
+	if err != nil {
+		return 0, "", err
+	}
+	if (endpointID == 0) == (group == "") {
+		return 0, "", fmt.Errorf("specify either --endpoint or --group")
+	}
+	return endpointID, group, nil
+}
+
+// resolveEnvironmentGroup looks up an environment group by ID or name
+func resolveEnvironmentGroup(groupService *portainer.EnvironmentGroupService, idOrName string) (*portainer.EnvironmentGroup, error) {
+	if id, err := strconv.Atoi(idOrName); err == nil {
+		return groupService.Get(id)
+	}
+	return groupService.GetByName(idOrName)
+}
+
+// runAccessUpdate grants or revokes the access of the --user and --team
+// flags on the --endpoint or --group flag
+func runAccessUpdate(cmd *cobra.Command, grant bool) error {
+	endpointID, groupArg, err := getAccessTarget(cmd)
+	if err != nil {
+		return err
+	}
+	userArgs, err := cmd.Flags().GetStringArray("user")
+	if err != nil {
+		return err
+	}
+	teamArgs, err := cmd.Flags().GetStringArray("team")
+	if err != nil {
+		return err
+	}
+	if len(userArgs) == 0 && len(teamArgs) == 0 {
+		return fmt.Errorf("specify at least one --user or --team")
+	}
+
+	profile, err := ResolveProfile(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	userIDs, err := resolveUserIDs(c, userArgs)
+	if err != nil {
+		return err
+	}
+	teamIDs, err := resolveTeamIDs(c, teamArgs)
+	if err != nil {
+		return err
+	}
+
+	var policy *portainer.AccessPolicy
+	roleName := ""
+	if grant {
+		policy = &portainer.AccessPolicy{}
+		roleArg, err := cmd.Flags().GetString("role")
+		if err != nil {
+			return err
+		}
+		if roleArg != "" {
+			role, err := resolveRole(c, roleArg)
+			if err != nil {
+				return err
+			}
+			policy.RoleId = role.Id
+			roleName = role.Name
+		}
+	}
+
+	update := portainer.AccessUpdate{Users: map[int]*portainer.AccessPolicy{}, Teams: map[int]*portainer.AccessPolicy{}}
+	for _, id := range userIDs {
+		update.Users[id] = policy
+	}
+	for _, id := range teamIDs {
+		update.Teams[id] = policy
+	}
+
+	var target string
+	if endpointID != 0 {
+		target = fmt.Sprintf("environment %d", endpointID)
+		if err := portainer.NewEnvironmentService(c).UpdateAccess(endpointID, update); err != nil {
+			return err
+		}
+	} else {
+		groupService := portainer.NewEnvironmentGroupService(c)
+		group, err := resolveEnvironmentGroup(groupService, groupArg)
+		if err != nil {
+			return err
+		}
+		target = "group " + group.Name
+		if err := groupService.UpdateAccess(group.Id, update); err != nil {
+			return err
+		}
+	}
+
+	if GetQuiet() || GetDryRun() {
+		return nil
+	}
+	count := len(userIDs) + len(teamIDs)
+	switch {
+	case !grant:
+		fmt.Printf("Revoked the access of %d users and teams to %s\n", count, target)
+	case roleName != "":
+		fmt.Printf("Granted %d users and teams access to %s with role %s\n", count, target, roleName)
+	default:
+		fmt.Printf("Granted %d users and teams access to %s\n", count, target)
+	}
+	return nil
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsAccessCmd)
+	environmentsAccessCmd.AddCommand(environmentsAccessShowCmd)
+	environmentsAccessCmd.AddCommand(environmentsAccessGrantCmd)
+	environmentsAccessCmd.AddCommand(environmentsAccessRevokeCmd)
+
+	for _, cmd := range []*cobra.Command{environmentsAccessShowCmd, environmentsAccessGrantCmd, environmentsAccessRevokeCmd} {
+		cmd.Flags().Int("endpoint", 0, "Environment endpoint ID")
+		cmd.Flags().String("group", "", "Environment group ID or name")
+		// --endpoint and --group exclude each other
+		cmd.Annotations = map[string]string{noDefaultEndpoint: ""}
+	}
+	for _, cmd := range []*cobra.Command{environmentsAccessGrantCmd, environmentsAccessRevokeCmd} {
+		cmd.Flags().StringArray("user", nil, "User ID or username (repeatable)")
+		cmd.Flags().StringArray("team", nil, "Team ID or name (repeatable)")
+	}
+	environmentsAccessGrantCmd.Flags().String("role", "", "Role ID or name (Business Edition)")
+}
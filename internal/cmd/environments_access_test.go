@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestAccessEntries(t *testing.T) {
+	names := accessNames{
+		users: map[int]string{2: "bob", 3: "alice"},
+		teams: map[int]string{1: "devs"},
+		roles: map[int]string{2: "Operator"},
+	}
+	users := portainer.AccessPolicies{"2": {RoleId: 2}, "3": {RoleId: 9}, "4": {}}
+	teams := portainer.AccessPolicies{"1": {RoleId: 2}}
+
+	entries := accessEntries("environment", users, teams, names)
+
+	expected := []accessEntry{
+		{Type: "user", ID: 4, Name: "4", Source: "environment"},
+		{Type: "user", ID: 3, Name: "alice", RoleID: 9, Role: "9", Source: "environment"},
+		{Type: "user", ID: 2, Name: "bob", RoleID: 2, Role: "Operator", Source: "environment"},
+		{Type: "team", ID: 1, Name: "devs", RoleID: 2, Role: "Operator", Source: "environment"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %v", len(expected), entries)
+	}
+	for i := range expected {
+		if entries[i] != expected[i] {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entries[i])
+		}
+	}
+}
+
+func TestFindRole(t *testing.T) {
+	roles := []portainer.Role{
+		{Id: 1, Name: "Environment administrator"},
+		{Id: 2, Name: "Operator"},
+		{Id: 3, Name: "Helpdesk"},
+		{Id: 4, Name: "Standard user"},
+		{Id: 5, Name: "Read-only user"},
+	}
+
+	tests := map[string]int{"standard": 4, "OPERATOR": 2, "5": 5, "read-only user": 5}
+	for arg, id := range tests {
+		role, err := findRole(roles, arg)
+		if err != nil || role.Id != id {
+			t.Errorf("%s: expected role %d, got %v (%v)", arg, id, role, err)
+		}
+	}
+
+	for _, arg := range []string{"admin", "9", ""} {
+		if _, err := findRole(roles, arg); err == nil {
+			t.Errorf("%q: expected an error", arg)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/dockercontext"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+// dockerContextNameInvalid matches the characters Docker does not allow in
+// context names
+var dockerContextNameInvalid = regexp.MustCompile(`[^a-zA-Z0-9_.+-]+`)
+
+// dockerContextResult describes a Docker context written by
+// environments to-docker-context
+type dockerContextResult struct {
+	Name          string `json:"Name"`
+	Host          string `json:"Host"`
+	SkipTLSVerify bool   `json:"SkipTLSVerify"`
+	Path          string `json:"Path"`
+}
+
+var environmentsToDockerContextCmd = &cobra.Command{
+	Use:   "to-docker-context [id or name]",
+	Short: "Create a Docker CLI context for an environment",
+	Long: `Create a Docker CLI context that points at the Docker API of an environment,
+as proxied by Portainer, so docker and other tools using Docker contexts
+can manage the environment directly.
+
+The Docker CLI sends no Portainer credentials by itself; the command prints
+the HttpHeaders entry to add to the Docker configuration so it does. For
+HTTPS servers the Docker CLI needs the certificate authority of the server:
+give it with --ca-file (e.g. /etc/ssl/certs/ca-certificates.crt for public
+certificates), or use a profile with insecure set to skip verification.
+
+Examples:
+  portainer-cli environments to-docker-context production --ca-file /etc/ssl/certs/ca-certificates.crt
+  docker --context portainer-production ps`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, err := cmd.Flags().GetString("name")
+		if err != nil {
+			return err
+		}
+		caFile, err := cmd.Flags().GetString("ca-file")
+		if err != nil {
+			return err
+		}
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			return err
+		}
+		dir, err := cmd.Flags().GetString("docker-config")
+		if err != nil {
+			return err
+		}
+		if dir == "" {
+			if dir, err = dockercontext.DefaultDir(); err != nil {
+				return err
+			}
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		env, err := resolveEnvironment(portainer.NewEnvironmentService(c), args[0])
+		if err != nil {
+			return err
+		}
+		if GetDryRun() {
+			return nil
+		}
+		if !env.IsDocker() {
+			return fmt.Errorf("environment %s (%d) is not a Docker environment", env.Name, env.Id)
+		}
+
+		host, secure, err := dockerContextHost(profile.URL, env.Id)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = defaultDockerContextName(env.Name)
+		}
+		if !force && dockercontext.Exists(dir, name) {
+			return fmt.Errorf("docker context '%s' exists already, use --force to replace it", name)
+		}
+
+		dockerContext := &dockercontext.Context{
+			Name:        name,
+			Description: fmt.Sprintf("Portainer environment %s (%d)", env.Name, env.Id),
+			Host:        host,
+		}
+		switch {
+		case !secure:
+		case caFile != "":
+			if dockerContext.CA, err = os.ReadFile(caFile); err != nil {
+				return fmt.Errorf("failed to read CA file: %w", err)
+			}
+		case profile.Insecure:
+			dockerContext.SkipTLSVerify = true
+		default:
+			return fmt.Errorf("--ca-file is required for HTTPS servers: the Docker CLI only trusts the certificate authority given to the context (e.g. --ca-file /etc/ssl/certs/ca-certificates.crt)")
+		}
+
+		if err := dockercontext.Write(dir, dockerContext); err != nil {
+			return err
+		}
+
+		result := dockerContextResult{
+			Name:          name,
+			Host:          host,
+			SkipTLSVerify: dockerContext.SkipTLSVerify,
+			Path:          filepath.Dir(dockercontext.MetaPath(dir, name)),
+		}
+
+		if GetQuiet() {
+			fmt.Println(name)
+			return nil
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			return newFormatter(format).Format(result)
+		default:
+			fmt.Printf("Docker context '%s' created for environment %s (%d)\n", name, env.Name, env.Id)
+			fmt.Printf("Host: %s\n\n", host)
+			printDockerContextAuth(profile, filepath.Join(dir, "config.json"))
+			fmt.Printf("\nThen run:\n\n  docker --context %s ps\n", name)
+			return nil
+		}
+	},
+}
+
+// dockerContextHost returns the Docker host of the Docker proxy of an
+// environment, e.g. tcp://portainer.example.com:443/api/endpoints/3/docker,
+// and whether it is served over HTTPS
+func dockerContextHost(portainerURL string, endpointID int) (string, bool, error) {
+	u, err := neturl.Parse(portainerURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false, fmt.Errorf("invalid Portainer URL: %s", portainerURL)
+	}
+
+	hostPort := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		hostPort += ":" + port
+	}
+	path := strings.TrimSuffix(u.Path, "/") + fmt.Sprintf("/api/endpoints/%d/docker", endpointID)
+	return "tcp://" + hostPort + path, u.Scheme == "https", nil
+}
+
+// defaultDockerContextName returns the context name of an environment, e.g.
+// portainer-production, with the characters Docker rejects replaced
+func defaultDockerContextName(envName string) string {
+	name := strings.Trim(dockerContextNameInvalid.ReplaceAllString(envName, "-"), "-")
+	return "portainer-" + strings.ToLower(name)
+}
+
+// printDockerContextAuth prints how to make the Docker CLI authenticate
+// with Portainer. The credentials are not printed or written, since the
+// Docker CLI sends its headers to every host.
+func printDockerContextAuth(profile *config.Profile, configPath string) {
+	header, value := "X-API-Key", "<API key>"
+	if profile.APIKey == "" && profile.Token != "" {
+		header, value = "Authorization", "Bearer <token>"
+	}
+
+	fmt.Printf("The Docker CLI does not send Portainer credentials by itself. Add them to\n")
+	fmt.Printf("the HttpHeaders of %s:\n\n", configPath)
+	fmt.Printf("  \"HttpHeaders\": {\"%s\": \"%s\"}\n\n", header, value)
+	fmt.Printf("The Docker CLI sends these headers to every Docker host it connects to.\n")
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsToDockerContextCmd)
+
+	environmentsToDockerContextCmd.Flags().String("name", "", "Context name (default: portainer-<environment name>)")
+	environmentsToDockerContextCmd.Flags().String("ca-file", "", "PEM file of the certificate authority of the Portainer server, for HTTPS servers")
+	environmentsToDockerContextCmd.Flags().Bool("force", false, "Replace an existing context with the same name")
+	environmentsToDockerContextCmd.Flags().String("docker-config", "", "Docker configuration directory (default: $DOCKER_CONFIG or ~/.docker)")
+}
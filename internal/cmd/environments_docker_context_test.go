@@ -0,0 +1,35 @@
+package cmd
+
+import "testing"
+
+func TestDockerContextHost(t *testing.T) {
+	tests := []struct {
+		url    string
+		host   string
+		secure bool
+	}{
+		{"https://portainer.example.com", "tcp://portainer.example.com:443/api/endpoints/3/docker", true},
+		{"https://portainer.example.com:9443/", "tcp://portainer.example.com:9443/api/endpoints/3/docker", true},
+		{"http://10.0.0.5/portainer", "tcp://10.0.0.5:80/portainer/api/endpoints/3/docker", false},
+	}
+
+	for _, tt := range tests {
+		host, secure, err := dockerContextHost(tt.url, 3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if host != tt.host || secure != tt.secure {
+			t.Errorf("dockerContextHost(%s): expected %s (secure %t), got %s (secure %t)", tt.url, tt.host, tt.secure, host, secure)
+		}
+	}
+
+	if _, _, err := dockerContextHost("portainer.example.com", 3); err == nil {
+		t.Error("expected error for URL without scheme")
+	}
+}
+
+func TestDefaultDockerContextName(t *testing.T) {
+	if name := defaultDockerContextName("Prod Web (EU)"); name != "portainer-prod-web-eu" {
+		t.Errorf("expected portainer-prod-web-eu, got %s", name)
+	}
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var environmentsEdgeKeyCmd = &cobra.Command{
+	Use:   "edge-key [id or name]",
+	Short: "Print the Edge key of an Edge environment",
+	Long: `Print the key an Edge agent uses to join an Edge environment, for use in
+provisioning scripts.
+
+Example:
+  portainer-cli environments edge-key 5
+  EDGE_KEY=$(portainer-cli environments edge-key edge-site-1)`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		env, err := getEdgeEnvironment(c, args[0])
+		if err != nil {
+			return err
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			return newFormatter(format).Format(map[string]interface{}{
+				"ID":      env.Id,
+				"Name":    env.Name,
+				"EdgeID":  env.EdgeID,
+				"EdgeKey": env.EdgeKey,
+			})
+		}
+
+		fmt.Println(env.EdgeKey)
+		return nil
+	},
+}
+
+var environmentsEdgeScriptCmd = &cobra.Command{
+	Use:   "edge-script [id or name]",
+	Short: "Print the command that deploys the Edge agent",
+	Long: `Print the docker command that deploys an Edge agent joining an Edge
+environment, as shown by Portainer when the environment is created.
+
+The Edge ID defaults to the ID of the device already associated with the
+environment, or a newly generated one. The agent version defaults to the
+version of the Portainer server. Use --os windows for a PowerShell command
+for Windows hosts and --swarm to deploy the agent as a global service on a
+Swarm cluster.
+
+Example:
+  portainer-cli environments edge-script 5 --os linux | ssh edge-device sh
+  portainer-cli environments edge-script edge-site-1 --swarm --insecure-poll`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := edgeScriptOptions{}
+		var err error
+		if opts.OS, err = cmd.Flags().GetString("os"); err != nil {
+			return err
+		}
+		if opts.Swarm, err = cmd.Flags().GetBool("swarm"); err != nil {
+			return err
+		}
+		if opts.InsecurePoll, err = cmd.Flags().GetBool("insecure-poll"); err != nil {
+			return err
+		}
+		if opts.EdgeID, err = cmd.Flags().GetString("edge-id"); err != nil {
+			return err
+		}
+		if opts.AgentVersion, err = cmd.Flags().GetString("agent-version"); err != nil {
+			return err
+		}
+		if opts.OS != "linux" && opts.OS != "windows" {
+			return fmt.Errorf("invalid --os %q (expected linux or windows)", opts.OS)
+		}
+		if opts.Swarm && opts.OS != "linux" {
+			return fmt.Errorf("--swarm is only supported with --os linux")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		env, err := getEdgeEnvironment(c, args[0])
+		if err != nil {
+			return err
+		}
+		if env.Type != portainer.EnvironmentTypeEdgeAgentOnDocker {
+			return fmt.Errorf("environment %s is a %s environment, only Docker Edge environments are supported", env.Name, env.TypeString())
+		}
+		opts.EdgeKey = env.EdgeKey
+
+		if opts.EdgeID == "" {
+			opts.EdgeID = env.EdgeID
+		}
+		if opts.EdgeID == "" {
+			if opts.EdgeID, err = newUUID(); err != nil {
+				return err
+			}
+		}
+
+		if opts.AgentVersion == "" {
+			status, err := portainer.NewAuthService(c).GetStatus()
+			if err != nil {
+				return err
+			}
+			opts.AgentVersion = status.Version
+		}
+
+		fmt.Print(edgeAgentScript(opts))
+		return nil
+	},
+}
+
+// getEdgeEnvironment resolves an environment and checks that it is an Edge
+// environment with a key
+func getEdgeEnvironment(c *portainer.Client, idOrName string) (*portainer.Environment, error) {
+	env, err := resolveEnvironment(portainer.NewEnvironmentService(c), idOrName)
+	if err != nil {
+		return nil, err
+	}
+	if env.Type != portainer.EnvironmentTypeEdgeAgentOnDocker && env.Type != portainer.EnvironmentTypeEdgeAgentOnKubernetes {
+		return nil, fmt.Errorf("environment %s is not an Edge environment", env.Name)
+	}
+	if env.EdgeKey == "" {
+		return nil, fmt.Errorf("environment %s has no Edge key (administrator access is required)", env.Name)
+	}
+	return env, nil
+}
+
+// edgeScriptOptions describes an Edge agent deployment
+type edgeScriptOptions struct {
+	// OS is linux or windows
+	OS           string
+	Swarm        bool
+	EdgeID       string
+	EdgeKey      string
+	AgentVersion string
+	// InsecurePoll lets the agent poll a Portainer server with a
+	// self-signed certificate
+	InsecurePoll bool
+}
+
+// edgeAgentScript returns the command that deploys the Edge agent, with
+// one option per line
+func edgeAgentScript(opts edgeScriptOptions) string {
+	env := []string{"-e EDGE=1", "-e EDGE_ID=" + opts.EdgeID, "-e EDGE_KEY=" + opts.EdgeKey}
+	if opts.InsecurePoll {
+		env = append(env, "-e EDGE_INSECURE_POLL=1")
+	}
+	image := "portainer/agent:" + opts.AgentVersion
+
+	var lines []string
+	continuation := " \\"
+	var prefix string
+
+	switch {
+	case opts.Swarm:
+		prefix = "docker network create --driver overlay portainer_agent_network\n"
+		lines = append(lines,
+			"docker service create",
+			"--name portainer_edge_agent",
+			"--network portainer_agent_network",
+			"-e AGENT_CLUSTER_ADDR=tasks.portainer_edge_agent",
+		)
+		lines = append(lines, env...)
+		lines = append(lines,
+			"--mode global",
+			"--constraint 'node.platform.os == linux'",
+			"--mount type=bind,src=//var/run/docker.sock,dst=/var/run/docker.sock",
+			"--mount type=bind,src=//var/lib/docker/volumes,dst=/var/lib/docker/volumes",
+			"--mount type=bind,src=//,dst=/host",
+			"--mount type=volume,src=portainer_agent_data,dst=/data",
+			image,
+		)
+
+	case opts.OS == "windows":
+		// PowerShell continues lines with a backtick
+		continuation = " `"
+		lines = append(lines,
+			"docker run -d",
+			`--mount type=npipe,src=\\.\pipe\docker_engine,dst=\\.\pipe\docker_engine`,
+			`--mount type=bind,src=C:\ProgramData\docker\volumes,dst=C:\ProgramData\docker\volumes`,
+			`--mount type=volume,src=portainer_agent_data,dst=C:\data`,
+			"--restart always",
+		)
+		lines = append(lines, env...)
+		lines = append(lines, "--name portainer_edge_agent", image)
+
+	default:
+		lines = append(lines,
+			"docker run -d",
+			"-v /var/run/docker.sock:/var/run/docker.sock",
+			"-v /var/lib/docker/volumes:/var/lib/docker/volumes",
+			"-v /:/host",
+			"-v portainer_agent_data:/data",
+			"--restart always",
+		)
+		lines = append(lines, env...)
+		lines = append(lines, "--name portainer_edge_agent", image)
+	}
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		b.WriteString(line)
+		if i < len(lines)-1 {
+			b.WriteString(continuation)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsEdgeKeyCmd)
+	environmentsCmd.AddCommand(environmentsEdgeScriptCmd)
+
+	environmentsEdgeScriptCmd.Flags().String("os", "linux", "Operating system of the Edge device (linux, windows)")
+	environmentsEdgeScriptCmd.Flags().Bool("swarm", false, "Deploy the agent as a global service on a Swarm cluster")
+	environmentsEdgeScriptCmd.Flags().Bool("insecure-poll", false, "Allow polling a Portainer server with a self-signed certificate")
+	environmentsEdgeScriptCmd.Flags().String("edge-id", "", "Edge ID of the device (defaults to the associated or a generated ID)")
+	environmentsEdgeScriptCmd.Flags().String("agent-version", "", "Agent image version (defaults to the Portainer server version)")
+}
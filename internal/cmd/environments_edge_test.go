@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEdgeAgentScript(t *testing.T) {
+	opts := edgeScriptOptions{
+		OS:           "linux",
+		EdgeID:       "device-1",
+		EdgeKey:      "a2V5",
+		AgentVersion: "2.19.4",
+		InsecurePoll: true,
+	}
+
+	script := edgeAgentScript(opts)
+	expected := `docker run -d \
+  -v /var/run/docker.sock:/var/run/docker.sock \
+  -v /var/lib/docker/volumes:/var/lib/docker/volumes \
+  -v /:/host \
+  -v portainer_agent_data:/data \
+  --restart always \
+  -e EDGE=1 \
+  -e EDGE_ID=device-1 \
+  -e EDGE_KEY=a2V5 \
+  -e EDGE_INSECURE_POLL=1 \
+  --name portainer_edge_agent \
+  portainer/agent:2.19.4
+`
+	if script != expected {
+		t.Errorf("unexpected linux script:\n%s", script)
+	}
+
+	opts.OS = "windows"
+	opts.InsecurePoll = false
+	script = edgeAgentScript(opts)
+	if !strings.Contains(script, "docker run -d `\n") || !strings.Contains(script, `src=\\.\pipe\docker_engine`) {
+		t.Errorf("unexpected windows script:\n%s", script)
+	}
+	if strings.Contains(script, "EDGE_INSECURE_POLL") {
+		t.Errorf("expected no insecure poll:\n%s", script)
+	}
+
+	opts.OS = "linux"
+	opts.Swarm = true
+	script = edgeAgentScript(opts)
+	if !strings.HasPrefix(script, "docker network create --driver overlay portainer_agent_network\ndocker service create \\\n") ||
+		!strings.Contains(script, "--mode global") {
+		t.Errorf("unexpected swarm script:\n%s", script)
+	}
+}
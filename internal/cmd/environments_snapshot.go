@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var environmentsRefreshCmd = &cobra.Command{
+	Use:   "refresh [id or name...]",
+	Short: "Refresh environment snapshots",
+	Long: `Make Portainer take a new snapshot of one or more environments, so their
+status and resource counts are current instead of waiting for the next
+scheduled snapshot.
+
+Example:
+  portainer-cli environments refresh 1
+  portainer-cli environments refresh production staging
+  portainer-cli environments refresh --all`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			return err
+		}
+		if all && len(args) > 0 {
+			return fmt.Errorf("--all cannot be combined with environment arguments")
+		}
+		if !all && len(args) == 0 {
+			return fmt.Errorf("specify one or more environments, or --all")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		envService := portainer.NewEnvironmentService(c)
+		report := !GetQuiet() && !GetDryRun()
+
+		if all {
+			if err := envService.SnapshotAll(); err != nil {
+				return err
+			}
+			if report {
+				fmt.Println("Refreshed the snapshots of all environments")
+			}
+			return nil
+		}
+
+		for _, arg := range args {
+			env, err := resolveEnvironment(envService, arg)
+			if err != nil {
+				return err
+			}
+			if err := envService.Snapshot(env.Id); err != nil {
+				return err
+			}
+			if report {
+				fmt.Printf("Refreshed the snapshot of environment %s (%d)\n", env.Name, env.Id)
+			}
+		}
+		return nil
+	},
+}
+
+var environmentsSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Show environment snapshots",
+	Long:  `Show the snapshots Portainer takes of environments.`,
+}
+
+var environmentsSnapshotShowCmd = &cobra.Command{
+	Use:   "show [id or name]",
+	Short: "Show the latest snapshot of an environment",
+	Long: `Show the latest snapshot of an environment with its resource totals: engine
+version, CPUs, memory, containers by state, images, volumes, services and
+stacks. Run "environments refresh" first to take a new snapshot.
+
+Example:
+  portainer-cli environments snapshot show 1
+  portainer-cli environments snapshot show production -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		env, err := resolveEnvironment(portainer.NewEnvironmentService(c), args[0])
+		if err != nil {
+			return err
+		}
+
+		snapshot := env.GetLatestSnapshot()
+		if snapshot == nil {
+			return fmt.Errorf("environment %s has no snapshot", env.Name)
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			return newFormatter(format).Format(snapshot)
+		}
+
+		printSnapshot(env, snapshot)
+		return nil
+	},
+}
+
+// printSnapshot prints a snapshot in a readable layout
+func printSnapshot(env *portainer.Environment, snapshot *portainer.Snapshot) {
+	taken := time.Unix(snapshot.Time, 0)
+
+	fmt.Printf("Environment:  %s (%d)\n", env.Name, env.Id)
+	fmt.Printf("Taken:        %s (%s ago)\n", taken.Format(time.RFC3339),
+		output.FormatDuration(int64(time.Since(taken).Seconds())))
+	if snapshot.DockerVersion != "" {
+		fmt.Printf("Docker:       %s\n", snapshot.DockerVersion)
+	}
+	if snapshot.Swarm {
+		if snapshot.NodeCount > 0 {
+			fmt.Printf("Mode:         Swarm (%d nodes)\n", snapshot.NodeCount)
+		} else {
+			fmt.Printf("Mode:         Swarm\n")
+		}
+	} else {
+		fmt.Printf("Mode:         Standalone\n")
+	}
+
+	fmt.Printf("\nResources:\n")
+	fmt.Printf("  CPU:        %d\n", snapshot.TotalCPU)
+	fmt.Printf("  Memory:     %s\n", output.FormatSize(snapshot.TotalMemory))
+
+	// Older Portainer versions do not report the total
+	total := snapshot.ContainerCount
+	if total == 0 {
+		total = snapshot.RunningContainerCount + snapshot.StoppedContainerCount
+	}
+	fmt.Printf("\nContainers:   %d\n", total)
+	fmt.Printf("  Running:    %d\n", snapshot.RunningContainerCount)
+	fmt.Printf("  Stopped:    %d\n", snapshot.StoppedContainerCount)
+	fmt.Printf("  Healthy:    %d\n", snapshot.HealthyContainerCount)
+	fmt.Printf("  Unhealthy:  %d\n", snapshot.UnhealthyContainerCount)
+
+	fmt.Printf("\nImages:       %d\n", snapshot.ImageCount)
+	fmt.Printf("Volumes:      %d\n", snapshot.VolumeCount)
+	if snapshot.Swarm {
+		fmt.Printf("Services:     %d\n", snapshot.ServiceCount)
+	}
+	fmt.Printf("Stacks:       %d\n", snapshot.StackCount)
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsRefreshCmd)
+	environmentsCmd.AddCommand(environmentsSnapshotCmd)
+	environmentsSnapshotCmd.AddCommand(environmentsSnapshotShowCmd)
+
+	environmentsRefreshCmd.Flags().Bool("all", false, "Refresh the snapshots of all environments")
+}
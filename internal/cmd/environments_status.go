@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/notify"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+const (
+	environmentHealthUp       = "Up"
+	environmentHealthDegraded = "Degraded"
+	environmentHealthDown     = "Down"
+)
+
+var environmentsStatusCmd = &cobra.Command{
+	Use:   "status [id or name...]",
+	Short: "Show a health overview of environments",
+	Long: `Aggregate the latest snapshots of environments into one table: status,
+running, stopped and unhealthy containers, stacks, images, CPUs, memory,
+agent version and the age of the snapshot.
+
+An environment that is up but has unhealthy containers is reported as
+Degraded. Down and Degraded environments are highlighted. Combine with
+--watch for a continuously refreshed wall view.
+
+Example:
+  portainer-cli environments status --all
+  portainer-cli environments status production staging
+  portainer-cli environments status --all --watch --interval 30
+  portainer-cli environments status --all --watch --notify-on 'down>0'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			return err
+		}
+		if all && len(args) > 0 {
+			return fmt.Errorf("--all cannot be combined with environment arguments")
+		}
+		if !all && len(args) == 0 {
+			return fmt.Errorf("specify one or more environments, or --all")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		envService := portainer.NewEnvironmentService(c)
+		format := getOutputFormat()
+
+		var values notify.Values
+		statusFunc := func() error {
+			var environments []portainer.Environment
+			if all {
+				environments, err = envService.List()
+				if err != nil {
+					return err
+				}
+			} else {
+				for _, arg := range args {
+					env, err := resolveEnvironment(envService, arg)
+					if err != nil {
+						return err
+					}
+					environments = append(environments, *env)
+				}
+			}
+
+			statuses := buildEnvironmentStatuses(environments, time.Now())
+			values = environmentStatusValues(statuses)
+
+			switch format {
+			case output.FormatJSON, output.FormatYAML:
+				return newFormatter(format).Format(statuses)
+			}
+
+			return printEnvironmentStatuses(statuses)
+		}
+
+		return RunWithWatch(cmd, "environment status", statusFunc, func() notify.Values { return values })
+	},
+}
+
+// environmentStatus is the health summary of one environment
+type environmentStatus struct {
+	ID     int    `json:"ID"`
+	Name   string `json:"Name"`
+	Type   string `json:"Type"`
+	Status string `json:"Status"`
+	// Snapshot is false when Portainer has no snapshot of the environment,
+	// in which case the counts are zero
+	Snapshot  bool  `json:"Snapshot"`
+	Running   int   `json:"Running"`
+	Stopped   int   `json:"Stopped"`
+	Healthy   int   `json:"Healthy"`
+	Unhealthy int   `json:"Unhealthy"`
+	Stacks    int   `json:"Stacks"`
+	Images    int   `json:"Images"`
+	CPU       int   `json:"CPU"`
+	Memory    int64 `json:"Memory"`
+	// SnapshotAge is the age of the snapshot in seconds
+	SnapshotAge  int64  `json:"SnapshotAge"`
+	AgentVersion string `json:"AgentVersion,omitempty"`
+}
+
+// buildEnvironmentStatuses summarizes the latest snapshot of each
+// environment as of now
+func buildEnvironmentStatuses(environments []portainer.Environment, now time.Time) []environmentStatus {
+	statuses := make([]environmentStatus, 0, len(environments))
+	for i := range environments {
+		env := &environments[i]
+		status := environmentStatus{
+			ID:           env.Id,
+			Name:         env.Name,
+			Type:         env.TypeString(),
+			Status:       environmentHealthDown,
+			AgentVersion: env.Agent.Version,
+		}
+
+		if snapshot := env.GetLatestSnapshot(); snapshot != nil {
+			status.Snapshot = true
+			status.Running = snapshot.RunningContainerCount
+			status.Stopped = snapshot.StoppedContainerCount
+			status.Healthy = snapshot.HealthyContainerCount
+			status.Unhealthy = snapshot.UnhealthyContainerCount
+			status.Stacks = snapshot.StackCount
+			status.Images = snapshot.ImageCount
+			status.CPU = snapshot.TotalCPU
+			status.Memory = snapshot.TotalMemory
+			status.SnapshotAge = max(0, int64(now.Sub(time.Unix(snapshot.Time, 0)).Seconds()))
+		}
+
+		if env.Status == portainer.EnvironmentStatusUp {
+			status.Status = environmentHealthUp
+			if status.Unhealthy > 0 {
+				status.Status = environmentHealthDegraded
+			}
+		}
+
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// environmentStatusValues returns the --notify-on variables: the count, the
+// number of up, degraded and down environments and the total number of
+// unhealthy containers
+func environmentStatusValues(statuses []environmentStatus) notify.Values {
+	values := notify.Values{"count": float64(len(statuses)), "up": 0, "degraded": 0, "down": 0, "unhealthy": 0}
+	for _, status := range statuses {
+		switch status.Status {
+		case environmentHealthUp:
+			values["up"]++
+		case environmentHealthDegraded:
+			values["degraded"]++
+		default:
+			values["down"]++
+		}
+		values["unhealthy"] += float64(status.Unhealthy)
+	}
+	return values
+}
+
+func printEnvironmentStatuses(statuses []environmentStatus) error {
+	if GetQuiet() {
+		for _, status := range statuses {
+			if status.Status != environmentHealthUp {
+				fmt.Println(status.ID)
+			}
+		}
+		return nil
+	}
+
+	table := output.NewTableData([]string{
+		"ID", "Name", "Status", "Running", "Stopped", "Unhealthy", "Stacks", "Images", "CPU", "Memory", "Agent", "Snapshot",
+	})
+	up, degraded, down := 0, 0, 0
+	for _, status := range statuses {
+		switch status.Status {
+		case environmentHealthUp:
+			up++
+		case environmentHealthDegraded:
+			degraded++
+		default:
+			down++
+		}
+
+		agent := status.AgentVersion
+		if agent == "" {
+			agent = "-"
+		}
+		if !status.Snapshot {
+			table.AddRow([]string{
+				fmt.Sprintf("%d", status.ID), status.Name, status.Status,
+				"-", "-", "-", "-", "-", "-", "-", agent, "never",
+			})
+			continue
+		}
+
+		unhealthy := fmt.Sprintf("%d", status.Unhealthy)
+		if status.Unhealthy > 0 {
+			unhealthy = output.Colorize(unhealthy, output.ColorRed)
+		}
+		table.AddRow([]string{
+			fmt.Sprintf("%d", status.ID),
+			status.Name,
+			status.Status,
+			fmt.Sprintf("%d", status.Running),
+			fmt.Sprintf("%d", status.Stopped),
+			unhealthy,
+			fmt.Sprintf("%d", status.Stacks),
+			fmt.Sprintf("%d", status.Images),
+			fmt.Sprintf("%d", status.CPU),
+			output.FormatSize(status.Memory),
+			agent,
+			output.FormatDuration(status.SnapshotAge) + " ago",
+		})
+	}
+	if err := output.PrintTable(*table); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d environments: %d up, %d degraded, %d down\n", len(statuses), up, degraded, down)
+	return nil
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsStatusCmd)
+
+	environmentsStatusCmd.Flags().Bool("all", false, "Show all environments")
+	AddWatchFlags(environmentsStatusCmd)
+}
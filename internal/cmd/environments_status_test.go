@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestBuildEnvironmentStatuses(t *testing.T) {
+	now := time.Unix(10000, 0)
+	environments := []portainer.Environment{
+		{
+			Id: 1, Name: "healthy", Status: portainer.EnvironmentStatusUp,
+			Agent:     portainer.AgentInfo{Version: "2.19.0"},
+			Snapshots: []portainer.Snapshot{{Time: 9400, RunningContainerCount: 4, StackCount: 2, TotalCPU: 8}},
+		},
+		{
+			Id: 2, Name: "degraded", Status: portainer.EnvironmentStatusUp,
+			Snapshots: []portainer.Snapshot{{Time: 9900, RunningContainerCount: 3, UnhealthyContainerCount: 1}},
+		},
+		{
+			Id: 3, Name: "down", Status: portainer.EnvironmentStatusDown,
+			Snapshots: []portainer.Snapshot{{Time: 1000, UnhealthyContainerCount: 2}},
+		},
+		{Id: 4, Name: "new", Status: portainer.EnvironmentStatusUp},
+	}
+
+	statuses := buildEnvironmentStatuses(environments, now)
+
+	expected := []string{environmentHealthUp, environmentHealthDegraded, environmentHealthDown, environmentHealthUp}
+	for i, status := range statuses {
+		if status.Status != expected[i] {
+			t.Errorf("%s: expected status %s, got %s", status.Name, expected[i], status.Status)
+		}
+	}
+	if statuses[0].SnapshotAge != 600 || statuses[0].Running != 4 || statuses[0].AgentVersion != "2.19.0" {
+		t.Errorf("unexpected summary %+v", statuses[0])
+	}
+	if statuses[3].Snapshot {
+		t.Errorf("expected no snapshot for %s", statuses[3].Name)
+	}
+
+	values := environmentStatusValues(statuses)
+	if values["up"] != 2 || values["degraded"] != 1 || values["down"] != 1 || values["unhealthy"] != 3 {
+		t.Errorf("unexpected values %v", values)
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestEnvironmentsListPaging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/endpoints" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		ids := []int{1, 2, 3, 4, 5}
+		// As Portainer, start is only applied together with limit
+		if limit, _ := strconv.Atoi(r.URL.Query().Get("limit")); limit > 0 {
+			start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+			ids = ids[min(start, len(ids)):min(start+limit, len(ids))]
+		}
+		w.Header().Set("X-Total-Count", "5")
+		w.Write([]byte("["))
+		for i, id := range ids {
+			if i > 0 {
+				w.Write([]byte(","))
+			}
+			fmt.Fprintf(w, `{"Id":%d,"Name":"env-%d"}`, id, id)
+		}
+		w.Write([]byte("]"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "offset",
+			args:     []string{"--offset", "3"},
+			expected: "4\n5\n",
+		},
+		{
+			name:     "offset and limit",
+			args:     []string{"--offset", "1", "--limit", "2"},
+			expected: "2\n3\n",
+		},
+		{
+			name:     "page",
+			args:     []string{"--page", "2", "--limit", "2"},
+			expected: "3\n4\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			stdout := os.Stdout
+			os.Stdout = w
+			err = executeCommand(t, server.URL, append([]string{"environments", "list", "--quiet"}, tt.args...)...)
+			w.Close()
+			os.Stdout = stdout
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			printed, _ := io.ReadAll(r)
+			if string(printed) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, printed)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/notify"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Monitor Docker events",
+	Long:  `Follow the Docker events stream of an environment.`,
+}
+
+var eventsWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Stream events",
+	Long: `Stream Docker events from an environment until interrupted.
+
+Events can be narrowed with --filter using the Docker filter keys, for example:
+  portainer-cli events watch --endpoint 1 --filter type=container --filter event=die
+
+With --webhook, each event is also POSTed as JSON to the given URL. Failed
+deliveries are reported as warnings and do not stop the stream:
+  portainer-cli events watch --endpoint 1 --filter event=oom --webhook https://hooks.example.com/alerts`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		filterArgs, err := cmd.Flags().GetStringArray("filter")
+		if err != nil {
+			return err
+		}
+
+		filters, err := parseFilters(filterArgs)
+		if err != nil {
+			return err
+		}
+
+		webhook, err := cmd.Flags().GetString("webhook")
+		if err != nil {
+			return err
+		}
+		if webhook != "" {
+			parsed, err := neturl.Parse(webhook)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("invalid webhook URL: %s", webhook)
+			}
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		eventService := portainer.NewEventService(c)
+		stream, err := eventService.Stream(endpointID, filters)
+		if err != nil {
+			return err
+		}
+		if stream == nil {
+			return nil
+		}
+		defer stream.Close()
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		// Closing the stream unblocks the pending read on interrupt
+		go func() {
+			<-ctx.Done()
+			stream.Close()
+		}()
+
+		format := getOutputFormat()
+		var formatter output.Formatter
+		if format == output.FormatJSON || format == output.FormatYAML {
+			formatter = newFormatter(format)
+		}
+
+		webhookClient := &http.Client{Timeout: notify.WebhookTimeout}
+
+		if !GetQuiet() && format == output.FormatTable {
+			fmt.Fprintln(os.Stderr, "Watching events... (Press Ctrl+C to exit)")
+		}
+
+		for {
+			event, err := stream.Next()
+			if err != nil {
+				if ctx.Err() != nil || err == io.EOF {
+					return nil
+				}
+				return err
+			}
+
+			if !GetQuiet() {
+				if formatter != nil {
+					if format == output.FormatYAML {
+						fmt.Println("---")
+					}
+					if err := formatter.Format(event); err != nil {
+						return err
+					}
+				} else {
+					printEvent(event)
+				}
+			}
+
+			if webhook != "" {
+				if err := forwardEvent(webhookClient, webhook, event); err != nil {
+					fmt.Fprintln(os.Stderr, output.Warning(err.Error()))
+				}
+			}
+		}
+	},
+}
+
+func printEvent(event *portainer.Event) {
+	fmt.Printf("%s  %-10s %-16s %s\n",
+		event.GetTime().Format(time.RFC3339),
+		event.Type,
+		event.Action,
+		event.GetActorName())
+}
+
+// forwardEvent POSTs an event as JSON to a webhook URL
+func forwardEvent(httpClient *http.Client, webhook string, event *portainer.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "portainer-cli")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver event to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+	eventsCmd.AddCommand(eventsWatchCmd)
+
+	eventsWatchCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	eventsWatchCmd.Flags().StringArray("filter", []string{}, "Filter events (KEY=VALUE, e.g. type=container, event=die)")
+	eventsWatchCmd.Flags().String("webhook", "", "POST each event as JSON to this URL")
+	_ = eventsWatchCmd.MarkFlagRequired("endpoint")
+}
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/notify"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var helmCmd = &cobra.Command{
+	Use:   "helm",
+	Short: "Manage Helm releases",
+	Long:  `List, install, and uninstall Helm charts in Kubernetes environments.`,
+}
+
+var helmListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List Helm releases",
+	Long:    `Display the Helm releases installed in a Kubernetes environment.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		namespace, err := cmd.Flags().GetString("namespace")
+		if err != nil {
+			return err
+		}
+
+		listOpts, err := getListOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		helmService := portainer.NewHelmService(c)
+		format := getOutputFormat()
+
+		var values notify.Values
+		listFunc := func() error {
+			releases, err := helmService.List(endpointID, namespace)
+			if err != nil {
+				return err
+			}
+
+			values = helmWatchValues(releases)
+
+			if GetQuiet() {
+				return printQuiet(listOpts, releases, func(item portainer.HelmRelease) string {
+					return item.Name
+				})
+			}
+
+			switch format {
+			case output.FormatJSON, output.FormatYAML:
+				formatter := newFormatter(format)
+				items, err := listOpts.applyItems(releases)
+				if err != nil {
+					return err
+				}
+				return formatter.Format(items)
+
+			default:
+				table := output.NewTableData([]string{"Name", "Namespace", "Revision", "Status", "Chart", "App Version"})
+				for _, release := range releases {
+					table.AddRow([]string{
+						release.Name,
+						release.Namespace,
+						release.Revision,
+						release.Status,
+						release.Chart,
+						release.AppVersion,
+					})
+				}
+				if err := listOpts.applyTable(table, releases); err != nil {
+					return err
+				}
+				return output.PrintTable(*table)
+			}
+		}
+
+		return RunWithWatch(cmd, "helm releases", listFunc, func() notify.Values { return values })
+	},
+}
+
+var helmInstallCmd = &cobra.Command{
+	Use:   "install [release-name]",
+	Short: "Install a Helm chart",
+	Long: `Install a chart from a Helm repository into a Kubernetes environment.
+
+For example:
+  portainer-cli helm install my-nginx --endpoint 3 --chart nginx \
+    --repo https://charts.bitnami.com/bitnami --values values.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		chart, err := cmd.Flags().GetString("chart")
+		if err != nil {
+			return err
+		}
+		if chart == "" {
+			return fmt.Errorf("--chart flag is required")
+		}
+
+		repo, err := cmd.Flags().GetString("repo")
+		if err != nil {
+			return err
+		}
+		if repo == "" {
+			return fmt.Errorf("--repo flag is required")
+		}
+
+		namespace, err := cmd.Flags().GetString("namespace")
+		if err != nil {
+			return err
+		}
+
+		valuesFile, err := cmd.Flags().GetString("values")
+		if err != nil {
+			return err
+		}
+
+		var values string
+		if valuesFile != "" {
+			content, err := os.ReadFile(valuesFile)
+			if err != nil {
+				return fmt.Errorf("failed to read values file: %w", err)
+			}
+			var parsed interface{}
+			if err := yaml.Unmarshal(content, &parsed); err != nil {
+				return fmt.Errorf("invalid values file %s: %w", valuesFile, err)
+			}
+			values = string(content)
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		helmService := portainer.NewHelmService(c)
+		release, err := helmService.Install(endpointID, &portainer.HelmInstallRequest{
+			Name:      args[0],
+			Namespace: namespace,
+			Chart:     chart,
+			Repo:      repo,
+			Values:    values,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Helm release %s installed (status: %s)\n", args[0], release.Status)
+		}
+
+		return nil
+	},
+}
+
+var helmUninstallCmd = &cobra.Command{
+	Use:     "uninstall [release-name]",
+	Aliases: []string{"rm"},
+	Short:   "Uninstall a Helm release",
+	Long:    `Remove a Helm release from a Kubernetes environment.`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		namespace, err := cmd.Flags().GetString("namespace")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		helmService := portainer.NewHelmService(c)
+		if err := helmService.Uninstall(endpointID, args[0], namespace); err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Helm release %s uninstalled\n", args[0])
+		}
+
+		return nil
+	},
+}
+
+// helmWatchValues returns the --notify-on variables for Helm releases: the
+// count and the number that are deployed, failed and pending
+func helmWatchValues(releases []portainer.HelmRelease) notify.Values {
+	values := notify.Values{"count": float64(len(releases)), "deployed": 0, "failed": 0, "pending": 0}
+	for _, release := range releases {
+		status := strings.ToLower(release.Status)
+		switch {
+		case status == "deployed" || status == "failed":
+			values[status]++
+		case strings.HasPrefix(status, "pending"):
+			values["pending"]++
+		}
+	}
+	return values
+}
+
+func init() {
+	rootCmd.AddCommand(helmCmd)
+	helmCmd.AddCommand(helmListCmd)
+	helmCmd.AddCommand(helmInstallCmd)
+	helmCmd.AddCommand(helmUninstallCmd)
+
+	helmListCmd.Flags().Int("endpoint", 0, "Kubernetes environment endpoint ID (required)")
+	helmListCmd.Flags().StringP("namespace", "n", "", "Namespace to list releases from (all if omitted)")
+	AddWatchFlags(helmListCmd)
+	addListFlags(helmListCmd)
+	_ = helmListCmd.MarkFlagRequired("endpoint")
+
+	helmInstallCmd.Flags().Int("endpoint", 0, "Kubernetes environment endpoint ID (required)")
+	helmInstallCmd.Flags().String("chart", "", "Chart name (required)")
+	helmInstallCmd.Flags().String("repo", "", "Helm repository URL (required)")
+	helmInstallCmd.Flags().StringP("namespace", "n", "", "Namespace to install into")
+	helmInstallCmd.Flags().StringP("values", "f", "", "Path to a values YAML file")
+	_ = helmInstallCmd.MarkFlagRequired("endpoint")
+	_ = helmInstallCmd.MarkFlagRequired("chart")
+	_ = helmInstallCmd.MarkFlagRequired("repo")
+
+	helmUninstallCmd.Flags().Int("endpoint", 0, "Kubernetes environment endpoint ID (required)")
+	helmUninstallCmd.Flags().StringP("namespace", "n", "", "Namespace of the release")
+	_ = helmUninstallCmd.MarkFlagRequired("endpoint")
+}
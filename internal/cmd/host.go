@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var hostCmd = &cobra.Command{
+	Use:   "host",
+	Short: "Inspect environment hosts",
+	Long:  `Show host details and browse the host filesystem of Docker environments.`,
+}
+
+var hostInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show host information",
+	Long: `Display the engine version and resources of an environment's host. Agent
+environments also report PCI devices and physical disks.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		hostService := portainer.NewHostService(c)
+		info, err := hostService.Info(endpointID)
+		if err != nil {
+			return err
+		}
+
+		format := getOutputFormat()
+
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			formatter := newFormatter(format)
+			return formatter.Format(info)
+
+		default:
+			engine := info.Engine
+			fmt.Printf("Hostname:       %s\n", engine.Name)
+			fmt.Printf("OS:             %s (%s/%s)\n", engine.OperatingSystem, engine.OSType, engine.Architecture)
+			fmt.Printf("Kernel:         %s\n", engine.KernelVersion)
+			fmt.Printf("Engine Version: %s\n", engine.ServerVersion)
+			fmt.Printf("CPUs:           %d\n", engine.NCPU)
+			fmt.Printf("Memory:         %s\n", output.FormatSize(engine.MemTotal))
+			fmt.Printf("Storage Driver: %s\n", engine.Driver)
+			fmt.Printf("Root Dir:       %s\n", engine.DockerRootDir)
+			fmt.Printf("Containers:     %d (%d running, %d paused, %d stopped)\n",
+				engine.Containers, engine.ContainersRunning, engine.ContainersPaused, engine.ContainersStopped)
+			fmt.Printf("Images:         %d\n", engine.Images)
+			if engine.Swarm != nil && engine.Swarm.LocalNodeState != "" {
+				fmt.Printf("Swarm:          %s\n", engine.Swarm.LocalNodeState)
+			}
+
+			if info.Agent != nil {
+				if len(info.Agent.PhysicalDisks) > 0 {
+					fmt.Printf("\nPhysical Disks:\n")
+					for _, disk := range info.Agent.PhysicalDisks {
+						fmt.Printf("  %s (%s)\n", disk.Vendor, output.FormatSize(disk.Size))
+					}
+				}
+				if len(info.Agent.PCIDevices) > 0 {
+					fmt.Printf("\nPCI Devices:\n")
+					for _, device := range info.Agent.PCIDevices {
+						fmt.Printf("  %s %s\n", device.Vendor, device.Name)
+					}
+				}
+			}
+
+			return nil
+		}
+	},
+}
+
+var hostBrowseCmd = &cobra.Command{
+	Use:   "browse [path]",
+	Short: "List files on the host",
+	Long: `List a directory of the host filesystem using the Portainer agent. The
+path defaults to the root of the host filesystem mounted into the agent.
+Requires an agent environment with host management features enabled.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		path := "/"
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		hostService := portainer.NewHostService(c)
+		files, err := hostService.Browse(endpointID, path)
+		if err != nil {
+			return err
+		}
+
+		return printFileList(files)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hostCmd)
+	hostCmd.AddCommand(hostInfoCmd)
+	hostCmd.AddCommand(hostBrowseCmd)
+
+	hostInfoCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	_ = hostInfoCmd.MarkFlagRequired("endpoint")
+
+	hostBrowseCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	_ = hostBrowseCmd.MarkFlagRequired("endpoint")
+}
@@ -1,17 +1,17 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/robversluis/portainer-cli/internal/client"
-	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/notify"
 	"github.com/robversluis/portainer-cli/internal/output"
-	"github.com/robversluis/portainer-cli/internal/watch"
+	"github.com/robversluis/portainer-cli/internal/pool"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
 	"github.com/spf13/cobra"
 )
 
@@ -35,39 +35,47 @@ var imagesListCmd = &cobra.Command{
 			return fmt.Errorf("--endpoint flag is required")
 		}
 
-		watchMode, err := cmd.Flags().GetBool("watch")
+		listOpts, err := getListOptions(cmd)
 		if err != nil {
 			return err
 		}
 
-		interval, err := cmd.Flags().GetInt("interval")
-		if err != nil {
-			return err
-		}
-
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		imageService := client.NewImageService(c)
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		imageService := portainer.NewImageService(c)
+		format := getOutputFormat()
 
+		var values notify.Values
 		listFunc := func() error {
 			images, err := imageService.List(endpointID)
 			if err != nil {
 				return err
 			}
 
+			values = imageWatchValues(images)
+
+			if GetQuiet() {
+				return printQuiet(listOpts, images, func(item portainer.Image) string {
+					return item.GetShortID()
+				})
+			}
+
 			switch format {
 			case output.FormatJSON, output.FormatYAML:
-				formatter := output.NewFormatter(output.Options{Format: format})
-				return formatter.Format(images)
+				formatter := newFormatter(format)
+				items, err := listOpts.applyItems(images)
+				if err != nil {
+					return err
+				}
+				return formatter.Format(items)
 
 			default:
 				table := output.NewTableData([]string{"ID", "Repository", "Tag", "Size", "Created"})
@@ -81,29 +89,14 @@ var imagesListCmd = &cobra.Command{
 						output.FormatDuration(int64(time.Since(createdTime).Seconds())),
 					})
 				}
+				if err := listOpts.applyTable(table, images); err != nil {
+					return err
+				}
 				return output.PrintTable(*table)
 			}
 		}
 
-		if watchMode {
-			ctx, cancel := context.WithCancel(context.Background())
-			defer cancel()
-
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-			go func() {
-				<-sigChan
-				cancel()
-			}()
-
-			opts := watch.DefaultOptions()
-			opts.Interval = time.Duration(interval) * time.Second
-
-			fmt.Println("Watching images... (Press Ctrl+C to exit)")
-			return watch.Watch(ctx, opts, listFunc)
-		}
-
-		return listFunc()
+		return RunWithWatch(cmd, "images", listFunc, func() notify.Values { return values })
 	},
 }
 
@@ -123,27 +116,27 @@ var imagesInspectCmd = &cobra.Command{
 
 		imageID := args[0]
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		imageService := client.NewImageService(c)
+		imageService := portainer.NewImageService(c)
 		image, err := imageService.Inspect(endpointID, imageID)
 		if err != nil {
 			return err
 		}
 
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		format := getOutputFormat()
 
 		switch format {
 		case output.FormatJSON, output.FormatYAML:
-			formatter := output.NewFormatter(output.Options{Format: format})
+			formatter := newFormatter(format)
 			return formatter.Format(image)
 
 		default:
@@ -191,6 +184,460 @@ var imagesInspectCmd = &cobra.Command{
 	},
 }
 
+var imagesHistoryCmd = &cobra.Command{
+	Use:   "history [image]",
+	Short: "Show the layer history of an image",
+	Long: `Display the layers of an image with the instruction that created each one
+and its size, newest first.
+
+Use --human to add a summary of where the image size comes from, listing the
+largest layers and their share of the total.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		human, err := cmd.Flags().GetBool("human")
+		if err != nil {
+			return err
+		}
+		noTrunc, err := cmd.Flags().GetBool("no-trunc")
+		if err != nil {
+			return err
+		}
+
+		imageID := args[0]
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		imageService := portainer.NewImageService(c)
+		history, err := imageService.History(endpointID, imageID)
+		if err != nil {
+			return err
+		}
+
+		format := getOutputFormat()
+
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			formatter := newFormatter(format)
+			if human {
+				return formatter.Format(summarizeImageHistory(history))
+			}
+			return formatter.Format(history)
+
+		default:
+			table := output.NewTableData([]string{"Layer", "Created", "Created By", "Size"})
+			for _, item := range history {
+				createdBy := item.GetCreatedBy()
+				if !noTrunc {
+					createdBy = output.TruncateString(createdBy, 60)
+				}
+				table.AddRow([]string{
+					item.GetShortID(),
+					output.FormatDuration(int64(time.Since(time.Unix(item.Created, 0)).Seconds())),
+					createdBy,
+					output.FormatSize(item.Size),
+				})
+			}
+			if err := output.PrintTable(*table); err != nil {
+				return err
+			}
+
+			if human {
+				printImageHistorySummary(summarizeImageHistory(history), noTrunc)
+			}
+			return nil
+		}
+	},
+}
+
+// imageHistorySummary aggregates the layer sizes of an image
+type imageHistorySummary struct {
+	TotalSize     int64             `json:"TotalSize"`
+	Layers        int               `json:"Layers"`
+	EmptyLayers   int               `json:"EmptyLayers"`
+	LargestLayers []imageLayerShare `json:"LargestLayers"`
+}
+
+type imageLayerShare struct {
+	CreatedBy string  `json:"CreatedBy"`
+	Size      int64   `json:"Size"`
+	Percent   float64 `json:"Percent"`
+}
+
+// maxSummaryLayers is the number of largest layers listed in the summary
+const maxSummaryLayers = 5
+
+func summarizeImageHistory(history []portainer.ImageHistoryItem) imageHistorySummary {
+	summary := imageHistorySummary{
+		Layers:        len(history),
+		LargestLayers: []imageLayerShare{},
+	}
+
+	var sized []portainer.ImageHistoryItem
+	for _, item := range history {
+		summary.TotalSize += item.Size
+		if item.Size == 0 {
+			summary.EmptyLayers++
+			continue
+		}
+		sized = append(sized, item)
+	}
+
+	sort.SliceStable(sized, func(i, j int) bool {
+		return sized[i].Size > sized[j].Size
+	})
+	if len(sized) > maxSummaryLayers {
+		sized = sized[:maxSummaryLayers]
+	}
+
+	for _, item := range sized {
+		summary.LargestLayers = append(summary.LargestLayers, imageLayerShare{
+			CreatedBy: item.GetCreatedBy(),
+			Size:      item.Size,
+			Percent:   float64(item.Size) * 100 / float64(summary.TotalSize),
+		})
+	}
+
+	return summary
+}
+
+func printImageHistorySummary(summary imageHistorySummary, noTrunc bool) {
+	fmt.Printf("\nTotal size: %s in %d layers (%d empty)\n",
+		output.FormatSize(summary.TotalSize), summary.Layers, summary.EmptyLayers)
+
+	if len(summary.LargestLayers) == 0 {
+		return
+	}
+
+	fmt.Printf("\nLargest layers:\n")
+	for _, layer := range summary.LargestLayers {
+		createdBy := layer.CreatedBy
+		if !noTrunc {
+			createdBy = output.TruncateString(createdBy, 60)
+		}
+		fmt.Printf("  %9s  %5.1f%%  %s\n", output.FormatSize(layer.Size), layer.Percent, createdBy)
+	}
+}
+
+var imagesReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report images across environments",
+	Long: `Aggregate the images of several Docker environments into one report.
+
+Images are grouped by repository, tag, and image ID. A tag is flagged as
+duplicate when environments hold different images for it. With
+--check-updates, each tag is resolved against its registry and images whose
+digest no longer matches are reported as outdated.
+
+Without --endpoint, all Docker environments that are up are included. For
+example:
+  portainer-cli images report --endpoint 1 --endpoint 2 --check-updates
+  portainer-cli images report -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointIDs, err := cmd.Flags().GetIntSlice("endpoint")
+		if err != nil {
+			return err
+		}
+		checkUpdates, err := cmd.Flags().GetBool("check-updates")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		environments, err := reportEnvironments(c, endpointIDs)
+		if err != nil {
+			return err
+		}
+		if len(environments) == 0 {
+			return fmt.Errorf("no Docker environments to report on")
+		}
+
+		imageService := portainer.NewImageService(c)
+		results := fetchEndpointImages(imageService, environments)
+		for _, result := range results {
+			if result.err != nil {
+				fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("skipping environment %s: %v", result.environment.Name, result.err)))
+			}
+		}
+
+		report := buildImageReport(results)
+		if checkUpdates {
+			checkImageUpdates(imageService, &report)
+		}
+
+		format := getOutputFormat()
+
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			formatter := newFormatter(format)
+			return formatter.Format(report)
+
+		default:
+			headers := []string{"Repository", "Tag", "Image ID", "Size", "Environments", "Duplicate"}
+			if checkUpdates {
+				headers = append(headers, "Registry")
+			}
+			table := output.NewTableData(headers)
+			for _, entry := range report.Images {
+				row := []string{
+					entry.Repository,
+					entry.Tag,
+					entry.ImageID,
+					output.FormatSize(entry.Size),
+					strings.Join(entry.Environments, ", "),
+					output.FormatBool(entry.Duplicate),
+				}
+				if checkUpdates {
+					row = append(row, entry.Registry)
+				}
+				table.AddRow(row)
+			}
+			if err := output.PrintTable(*table); err != nil {
+				return err
+			}
+
+			summary := report.Summary
+			fmt.Printf("\n%d images in %d environments using %s, %d duplicate tags",
+				summary.Images, summary.Environments, output.FormatSize(summary.TotalSize), summary.Duplicates)
+			if checkUpdates {
+				fmt.Printf(", %d outdated", summary.Outdated)
+			}
+			fmt.Println()
+			return nil
+		}
+	},
+}
+
+// Registry states reported by images report --check-updates
+const (
+	registryUpToDate = "up to date"
+	registryOutdated = "outdated"
+	registryUnknown  = "unknown"
+)
+
+type imageReport struct {
+	Summary imageReportSummary `json:"Summary"`
+	Images  []imageReportEntry `json:"Images"`
+}
+
+type imageReportSummary struct {
+	Environments int   `json:"Environments"`
+	Images       int   `json:"Images"`
+	TotalSize    int64 `json:"TotalSize"`
+	Duplicates   int   `json:"Duplicates"`
+	Outdated     int   `json:"Outdated"`
+}
+
+type imageReportEntry struct {
+	Repository   string   `json:"Repository"`
+	Tag          string   `json:"Tag"`
+	ImageID      string   `json:"ImageID"`
+	Size         int64    `json:"Size"`
+	Environments []string `json:"Environments"`
+	Duplicate    bool     `json:"Duplicate"`
+	Registry     string   `json:"Registry,omitempty"`
+
+	image       portainer.Image
+	endpointIDs []int
+}
+
+type endpointImages struct {
+	environment portainer.Environment
+	images      []portainer.Image
+	err         error
+}
+
+// reportEnvironments returns the selected environments, or all Docker
+// environments that are up when none are selected
+func reportEnvironments(c *portainer.Client, endpointIDs []int) ([]portainer.Environment, error) {
+	envService := portainer.NewEnvironmentService(c)
+
+	if len(endpointIDs) > 0 {
+		environments := make([]portainer.Environment, 0, len(endpointIDs))
+		for _, id := range endpointIDs {
+			env, err := envService.Get(id)
+			if err != nil {
+				return nil, err
+			}
+			environments = append(environments, *env)
+		}
+		return environments, nil
+	}
+
+	all, err := envService.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var environments []portainer.Environment
+	for _, env := range all {
+		if env.IsDocker() && env.Status == portainer.EnvironmentStatusUp {
+			environments = append(environments, env)
+		}
+	}
+	return environments, nil
+}
+
+// fetchEndpointImages lists the images of all environments concurrently
+func fetchEndpointImages(imageService *portainer.ImageService, environments []portainer.Environment) []endpointImages {
+	return pool.Map(GetParallel(), environments, func(env portainer.Environment) endpointImages {
+		images, err := imageService.List(env.Id)
+		return endpointImages{environment: env, images: images, err: err}
+	})
+}
+
+// buildImageReport groups images by repository, tag, and image ID and flags
+// tags that resolve to different images across environments
+func buildImageReport(results []endpointImages) imageReport {
+	var report imageReport
+	entries := map[string]*imageReportEntry{}
+	tagImages := map[string]map[string]bool{}
+
+	for _, result := range results {
+		if result.err != nil {
+			continue
+		}
+		report.Summary.Environments++
+
+		for _, image := range result.images {
+			report.Summary.TotalSize += image.Size
+
+			refs := image.RepoTags
+			if len(refs) == 0 || (len(refs) == 1 && refs[0] == "<none>:<none>") {
+				refs = []string{"<none>:<none>"}
+			}
+
+			for _, ref := range refs {
+				repository, tag := splitImageRef(ref)
+				key := repository + ":" + tag + "@" + image.Id
+
+				entry, ok := entries[key]
+				if !ok {
+					entry = &imageReportEntry{
+						Repository: repository,
+						Tag:        tag,
+						ImageID:    image.GetShortID(),
+						Size:       image.Size,
+						image:      image,
+					}
+					entries[key] = entry
+				}
+				entry.Environments = append(entry.Environments, result.environment.Name)
+				entry.endpointIDs = append(entry.endpointIDs, result.environment.Id)
+
+				if tag != "<none>" {
+					ref := repository + ":" + tag
+					if tagImages[ref] == nil {
+						tagImages[ref] = map[string]bool{}
+					}
+					tagImages[ref][image.Id] = true
+				}
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		if len(tagImages[entry.Repository+":"+entry.Tag]) > 1 {
+			entry.Duplicate = true
+		}
+		report.Images = append(report.Images, *entry)
+	}
+
+	sort.Slice(report.Images, func(i, j int) bool {
+		a, b := report.Images[i], report.Images[j]
+		if a.Repository != b.Repository {
+			return a.Repository < b.Repository
+		}
+		if a.Tag != b.Tag {
+			return a.Tag < b.Tag
+		}
+		return a.ImageID < b.ImageID
+	})
+
+	report.Summary.Images = len(report.Images)
+	for _, ids := range tagImages {
+		if len(ids) > 1 {
+			report.Summary.Duplicates++
+		}
+	}
+
+	return report
+}
+
+// checkImageUpdates resolves each tag against its registry once and marks
+// the images whose digest differs from the registry as outdated
+func checkImageUpdates(imageService *portainer.ImageService, report *imageReport) {
+	digests := map[string]string{}
+
+	for i := range report.Images {
+		entry := &report.Images[i]
+		if entry.Tag == "<none>" {
+			entry.Registry = "-"
+			continue
+		}
+
+		ref := entry.Repository + ":" + entry.Tag
+		digest, ok := digests[ref]
+		if !ok {
+			var err error
+			digest, err = imageService.RegistryDigest(entry.endpointIDs[0], ref)
+			if err != nil {
+				if GetVerbose() {
+					fmt.Fprintln(os.Stderr, output.Warning(err.Error()))
+				}
+				digest = ""
+			}
+			digests[ref] = digest
+		}
+
+		switch {
+		case digest == "":
+			entry.Registry = registryUnknown
+		case entry.image.HasDigest(entry.Repository, digest):
+			entry.Registry = registryUpToDate
+		default:
+			entry.Registry = registryOutdated
+			report.Summary.Outdated++
+		}
+	}
+}
+
+// splitImageRef splits "registry:5000/app:1.0" into repository and tag. A
+// reference without a tag is reported with the implied "latest" tag.
+func splitImageRef(ref string) (string, string) {
+	if ref == "<none>:<none>" {
+		return "<none>", "<none>"
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, "latest"
+}
+
 var imagesPullCmd = &cobra.Command{
 	Use:   "pull [image]",
 	Short: "Pull an image",
@@ -211,20 +658,26 @@ var imagesPullCmd = &cobra.Command{
 			return err
 		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		imageService := client.NewImageService(c)
-		if err := imageService.Pull(endpointID, imageName, registryID); err != nil {
+		imageService := portainer.NewImageService(c)
+		emitProgress(progressEvent{Event: "pull_started", Endpoint: endpointID, Image: imageName})
+		err = imageService.PullWithProgress(endpointID, imageName, registryID, func(progress portainer.PullProgress) {
+			emitProgress(pullProgressEvent(endpointID, imageName, progress))
+		})
+		if err != nil {
+			emitProgress(progressEvent{Event: "pull_failed", Endpoint: endpointID, Image: imageName, Error: err.Error()})
 			return err
 		}
+		emitProgress(progressEvent{Event: "pulled", Endpoint: endpointID, Image: imageName})
 
 		if !GetQuiet() {
 			fmt.Printf("Image '%s' pulled successfully\n", imageName)
@@ -255,17 +708,17 @@ var imagesRemoveCmd = &cobra.Command{
 			return err
 		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		imageService := client.NewImageService(c)
+		imageService := portainer.NewImageService(c)
 		if err := imageService.Remove(endpointID, imageID, force); err != nil {
 			return err
 		}
@@ -281,44 +734,187 @@ var imagesRemoveCmd = &cobra.Command{
 var imagesPruneCmd = &cobra.Command{
 	Use:   "prune",
 	Short: "Remove unused images",
-	Long:  `Remove all dangling or unused images.`,
+	Long: `Remove dangling images, or all unused images with --dangling=false.
+
+The removed tags and images are listed with the reclaimed space. With
+--all-endpoints, every Docker environment that is up is pruned concurrently
+and the reclaimed space is summarized per environment; add --verbose to list
+the removed images. Use --min-age to keep images created recently.
+
+Examples:
+  # Dangling images of one environment
+  portainer-cli images prune --endpoint 1
+
+  # Unused images older than a week, in all environments
+  portainer-cli images prune --all-endpoints --dangling=false --min-age 168h`,
+	Annotations: map[string]string{fanOutFlag: "all-endpoints"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		endpointID, err := cmd.Flags().GetInt("endpoint")
 		if err != nil {
 			return err
 		}
-		if endpointID == 0 {
-			return fmt.Errorf("--endpoint flag is required")
+		allEndpoints, err := cmd.Flags().GetBool("all-endpoints")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 && !allEndpoints {
+			return fmt.Errorf("--endpoint or --all-endpoints is required")
+		}
+		if endpointID != 0 && allEndpoints {
+			return fmt.Errorf("--endpoint and --all-endpoints cannot be used together")
 		}
 
 		dangling, err := cmd.Flags().GetBool("dangling")
 		if err != nil {
 			return err
 		}
+		minAge, err := cmd.Flags().GetDuration("min-age")
+		if err != nil {
+			return err
+		}
+		if minAge < 0 {
+			return fmt.Errorf("--min-age cannot be negative")
+		}
 
-		profile, err := config.GetProfileFromViper()
+		filters := map[string][]string{"dangling": {strconv.FormatBool(dangling)}}
+		if minAge > 0 {
+			filters["until"] = []string{minAge.String()}
+		}
+
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		imageService := client.NewImageService(c)
-		if err := imageService.Prune(endpointID, dangling); err != nil {
+		imageService := portainer.NewImageService(c)
+		format := getOutputFormat()
+
+		if !allEndpoints {
+			emitProgress(progressEvent{Event: "pruning", Endpoint: endpointID, Resource: "images"})
+			report, err := imageService.Prune(endpointID, filters)
+			if err != nil {
+				emitPruned(endpointID, "images", 0, 0, err)
+				return err
+			}
+			emitPruned(endpointID, "images", report.DeletedCount(), report.SpaceReclaimed, nil)
+
+			return printPruneReport(report, pruneResult{
+				kind:      "images",
+				deleted:   imagePruneItems(report.ImagesDeleted),
+				reclaimed: report.SpaceReclaimed,
+			})
+		}
+
+		environments, err := reportEnvironments(c, nil)
+		if err != nil {
 			return err
 		}
+		if len(environments) == 0 {
+			return fmt.Errorf("no Docker environments to prune")
+		}
 
-		if !GetQuiet() {
-			fmt.Println("Images pruned successfully")
+		summary := pruneEndpointImages(imageService, environments, filters)
+		if GetDryRun() {
+			return nil
+		}
+
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			if err := newFormatter(format).Format(summary); err != nil {
+				return err
+			}
+		default:
+			if !GetQuiet() {
+				table := output.NewTableData([]string{"ID", "Environment", "Images Deleted", "Space Reclaimed", "Status"})
+				for _, result := range summary.Endpoints {
+					status := "ok"
+					deleted, reclaimed := "-", "-"
+					if result.Error != "" {
+						status = "failed: " + result.Error
+					} else {
+						deleted = strconv.Itoa(result.ImagesDeleted)
+						reclaimed = output.FormatSize(result.SpaceReclaimed)
+					}
+					table.AddRow([]string{strconv.Itoa(result.EndpointID), result.Environment, deleted, reclaimed, status})
+				}
+				if err := output.PrintTable(*table); err != nil {
+					return err
+				}
+				if GetVerbose() {
+					for _, result := range summary.Endpoints {
+						if len(result.Deleted) == 0 {
+							continue
+						}
+						fmt.Printf("\n%s:\n", result.Environment)
+						for _, item := range imagePruneItems(result.Deleted) {
+							fmt.Printf("  %s\n", item)
+						}
+					}
+				}
+				fmt.Printf("\nTotal: %d images deleted, %s reclaimed in %d environments\n",
+					summary.ImagesDeleted, output.FormatSize(summary.SpaceReclaimed), len(summary.Endpoints)-summary.Failed)
+			}
 		}
 
+		if summary.Failed > 0 {
+			return fmt.Errorf("failed to prune %d of %d environments", summary.Failed, len(summary.Endpoints))
+		}
 		return nil
 	},
 }
 
+// imagePruneSummary is the result of pruning images in several environments
+type imagePruneSummary struct {
+	Endpoints      []endpointPruneResult `json:"Endpoints"`
+	ImagesDeleted  int                   `json:"ImagesDeleted"`
+	SpaceReclaimed int64                 `json:"SpaceReclaimed"`
+	Failed         int                   `json:"Failed"`
+}
+
+type endpointPruneResult struct {
+	EndpointID     int                         `json:"EndpointID"`
+	Environment    string                      `json:"Environment"`
+	ImagesDeleted  int                         `json:"ImagesDeleted"`
+	SpaceReclaimed int64                       `json:"SpaceReclaimed"`
+	Deleted        []portainer.ImageDeleteItem `json:"Deleted,omitempty"`
+	Error          string                      `json:"Error,omitempty"`
+}
+
+// pruneEndpointImages prunes the images of all environments concurrently.
+// The results are totalled once all have finished.
+func pruneEndpointImages(imageService *portainer.ImageService, environments []portainer.Environment, filters map[string][]string) imagePruneSummary {
+	results := pool.Map(GetParallel(), environments, func(env portainer.Environment) endpointPruneResult {
+		result := endpointPruneResult{EndpointID: env.Id, Environment: env.Name}
+		emitProgress(progressEvent{Event: "pruning", Endpoint: env.Id, Resource: "images"})
+		report, err := imageService.Prune(env.Id, filters)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.ImagesDeleted = report.DeletedCount()
+			result.SpaceReclaimed = report.SpaceReclaimed
+			result.Deleted = report.ImagesDeleted
+		}
+		emitPruned(env.Id, "images", result.ImagesDeleted, result.SpaceReclaimed, err)
+		return result
+	})
+
+	summary := imagePruneSummary{Endpoints: results}
+	for _, result := range results {
+		if result.Error != "" {
+			summary.Failed++
+			continue
+		}
+		summary.ImagesDeleted += result.ImagesDeleted
+		summary.SpaceReclaimed += result.SpaceReclaimed
+	}
+	return summary
+}
+
 var imagesTagCmd = &cobra.Command{
 	Use:   "tag [source] [target]",
 	Short: "Tag an image",
@@ -336,18 +932,18 @@ var imagesTagCmd = &cobra.Command{
 		sourceImage := args[0]
 		targetImage := args[1]
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
 		parts := splitImageName(targetImage)
-		imageService := client.NewImageService(c)
+		imageService := portainer.NewImageService(c)
 		if err := imageService.Tag(endpointID, sourceImage, parts[0], parts[1]); err != nil {
 			return err
 		}
@@ -384,23 +980,46 @@ func splitImageName(imageName string) [2]string {
 	return parts
 }
 
+// imageWatchValues returns the --notify-on variables for images: the count,
+// the number of untagged images and the total size in bytes
+func imageWatchValues(images []portainer.Image) notify.Values {
+	values := notify.Values{"count": float64(len(images)), "dangling": 0, "size": 0}
+	for _, image := range images {
+		if len(image.RepoTags) == 0 || (len(image.RepoTags) == 1 && image.RepoTags[0] == "<none>:<none>") {
+			values["dangling"]++
+		}
+		values["size"] += float64(image.Size)
+	}
+	return values
+}
+
 func init() {
 	rootCmd.AddCommand(imagesCmd)
 	imagesCmd.AddCommand(imagesListCmd)
 	imagesCmd.AddCommand(imagesInspectCmd)
+	imagesCmd.AddCommand(imagesHistoryCmd)
+	imagesCmd.AddCommand(imagesReportCmd)
 	imagesCmd.AddCommand(imagesPullCmd)
 	imagesCmd.AddCommand(imagesRemoveCmd)
 	imagesCmd.AddCommand(imagesPruneCmd)
 	imagesCmd.AddCommand(imagesTagCmd)
 
 	imagesListCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
-	imagesListCmd.Flags().BoolP("watch", "w", false, "Watch for changes and continuously update")
-	imagesListCmd.Flags().Int("interval", 2, "Refresh interval in seconds for watch mode")
+	AddWatchFlags(imagesListCmd)
+	addListFlags(imagesListCmd)
 	_ = imagesListCmd.MarkFlagRequired("endpoint")
 
 	imagesInspectCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
 	_ = imagesInspectCmd.MarkFlagRequired("endpoint")
 
+	imagesHistoryCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	imagesHistoryCmd.Flags().Bool("human", false, "Show a summary of the largest layers and their share of the image size")
+	imagesHistoryCmd.Flags().Bool("no-trunc", false, "Do not truncate build instructions")
+	_ = imagesHistoryCmd.MarkFlagRequired("endpoint")
+
+	imagesReportCmd.Flags().IntSlice("endpoint", nil, "Environment endpoint ID, repeatable (default: all Docker environments)")
+	imagesReportCmd.Flags().Bool("check-updates", false, "Compare image digests with the registry to find outdated tags")
+
 	imagesPullCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
 	imagesPullCmd.Flags().Int("registry", 0, "Registry ID for authentication")
 	_ = imagesPullCmd.MarkFlagRequired("endpoint")
@@ -409,9 +1028,10 @@ func init() {
 	imagesRemoveCmd.Flags().BoolP("force", "f", false, "Force removal of the image")
 	_ = imagesRemoveCmd.MarkFlagRequired("endpoint")
 
-	imagesPruneCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
-	imagesPruneCmd.Flags().Bool("dangling", true, "Remove only dangling images")
-	_ = imagesPruneCmd.MarkFlagRequired("endpoint")
+	imagesPruneCmd.Flags().Int("endpoint", 0, "Environment endpoint ID")
+	imagesPruneCmd.Flags().Bool("dangling", true, "Remove only dangling images (--dangling=false removes all unused images)")
+	imagesPruneCmd.Flags().Bool("all-endpoints", false, "Prune all Docker environments that are up, concurrently")
+	imagesPruneCmd.Flags().Duration("min-age", 0, "Only remove images created longer ago than this (e.g. 168h)")
 
 	imagesTagCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
 	_ = imagesTagCmd.MarkFlagRequired("endpoint")
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/compose"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+// resolvedDigest is an image resolved by images resolve-digest
+type resolvedDigest struct {
+	Image  string `json:"Image"`
+	Digest string `json:"Digest"`
+	Pinned string `json:"Pinned"`
+}
+
+var imagesResolveDigestCmd = &cobra.Command{
+	Use:   "resolve-digest [image...]",
+	Short: "Resolve image tags to manifest digests",
+	Long: `Resolve image references such as repo:tag to the manifest digest their
+registry currently serves, through the Docker engine of an environment. Use
+--registry for private repositories of a registry configured in Portainer.
+
+With --file, every service image of a compose file is resolved and the file
+is printed with its images pinned by digest (nginx:1.25 becomes
+nginx:1.25@sha256:...), keeping comments and formatting. Images pinned
+already and images set from variables are left as they are. Deploying the
+pinned file always runs the same images, even when tags are moved.
+
+Examples:
+  portainer-cli images resolve-digest nginx:1.27 --endpoint 1
+  portainer-cli images resolve-digest team/app:2.3 --endpoint 1 --registry 2 -q
+
+  # Pin the images of a stack file in place
+  portainer-cli images resolve-digest --file docker-compose.yml --endpoint 1 --output-file docker-compose.yml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+		registryID, err := cmd.Flags().GetInt("registry")
+		if err != nil {
+			return err
+		}
+		filePath, err := cmd.Flags().GetString("file")
+		if err != nil {
+			return err
+		}
+		if filePath == "" && len(args) == 0 {
+			return fmt.Errorf("an image or --file is required")
+		}
+		if filePath != "" && len(args) > 0 {
+			return fmt.Errorf("images cannot be given with --file")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		imageService := portainer.NewImageService(c)
+
+		if filePath != "" {
+			return pinStackFile(imageService, endpointID, registryID, filePath)
+		}
+
+		resolved := make([]resolvedDigest, 0, len(args))
+		for _, image := range args {
+			digest, err := imageService.RegistryDigestWithAuth(endpointID, image, registryID)
+			if err != nil {
+				return err
+			}
+			resolved = append(resolved, resolvedDigest{
+				Image:  image,
+				Digest: digest,
+				Pinned: compose.PinDigest(map[string]string{image: digest})(image),
+			})
+		}
+		if GetDryRun() {
+			return nil
+		}
+
+		if GetQuiet() {
+			for _, r := range resolved {
+				fmt.Println(r.Digest)
+			}
+			return nil
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			return newFormatter(format).Format(resolved)
+		default:
+			table := output.NewTableData([]string{"Image", "Digest"})
+			for _, r := range resolved {
+				table.AddRow([]string{r.Image, r.Digest})
+			}
+			return output.PrintTable(*table)
+		}
+	},
+}
+
+// pinStackFile prints a compose file with its service images pinned by
+// digest
+func pinStackFile(imageService *portainer.ImageService, endpointID, registryID int, filePath string) error {
+	content, err := readStackFile(filePath, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	pinned, changes, err := pinStackImages(imageService, endpointID, registryID, []byte(content))
+	if err != nil || GetDryRun() {
+		return err
+	}
+	if GetVerbose() {
+		for _, change := range changes {
+			fmt.Fprintf(os.Stderr, "Service %s: image %s -> %s\n", change.Service, change.From, change.To)
+		}
+	}
+	_, err = os.Stdout.Write(pinned)
+	return err
+}
+
+// pinStackImages pins the service images of a compose file by the digests
+// resolved for them. The first pass only collects the images, so each
+// distinct image is resolved once.
+func pinStackImages(imageService *portainer.ImageService, endpointID, registryID int, content []byte) ([]byte, []compose.ImageChange, error) {
+	digests := map[string]string{}
+	var resolveErr error
+	_, _, err := compose.RewriteImages(content, func(image string) string {
+		if _, ok := digests[image]; ok || resolveErr != nil || strings.Contains(image, "@") {
+			return image
+		}
+		digests[image], resolveErr = imageService.RegistryDigestWithAuth(endpointID, image, registryID)
+		return image
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if resolveErr != nil {
+		return nil, nil, resolveErr
+	}
+	return compose.RewriteImages(content, compose.PinDigest(digests))
+}
+
+func init() {
+	imagesCmd.AddCommand(imagesResolveDigestCmd)
+
+	imagesResolveDigestCmd.Flags().Int("endpoint", 0, "Environment endpoint ID whose engine contacts the registry (required)")
+	imagesResolveDigestCmd.Flags().Int("registry", 0, "Registry ID for authentication")
+	imagesResolveDigestCmd.Flags().String("file", "", "Compose file to pin by digest, or - to read it from stdin")
+	_ = imagesResolveDigestCmd.MarkFlagRequired("endpoint")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestPinStackImages(t *testing.T) {
+	var lookups int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		switch r.URL.Path {
+		case "/api/endpoints/1/docker/distribution/nginx:1.25/json":
+			w.Write([]byte(`{"Descriptor":{"digest":"sha256:abc"}}`))
+		case "/api/endpoints/1/docker/distribution/acme/api:2/json":
+			w.Write([]byte(`{"Descriptor":{"digest":"sha256:def"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := portainer.NewClient(&portainer.Config{URL: server.URL, APIKey: "test-key"}, portainer.WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	content := `services:
+  web:
+    image: nginx:1.25 # frontend
+  proxy:
+    image: nginx:1.25
+  api:
+    image: "acme/api:2"
+  db:
+    image: postgres@sha256:123
+  worker:
+    image: ${WORKER_IMAGE}
+`
+	pinned, changes, err := pinStackImages(portainer.NewImageService(c), 1, 0, []byte(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `services:
+  web:
+    image: nginx:1.25@sha256:abc # frontend
+  proxy:
+    image: nginx:1.25@sha256:abc
+  api:
+    image: "acme/api:2@sha256:def"
+  db:
+    image: postgres@sha256:123
+  worker:
+    image: ${WORKER_IMAGE}
+`
+	if string(pinned) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, pinned)
+	}
+	if len(changes) != 3 {
+		t.Errorf("expected 3 changes, got %d", len(changes))
+	}
+	if lookups != 2 {
+		t.Errorf("expected each image to be resolved once, got %d lookups", lookups)
+	}
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+// promotedImage is the result of images promote
+type promotedImage struct {
+	Source string `json:"Source"`
+	Target string `json:"Target"`
+	Digest string `json:"Digest,omitempty"`
+}
+
+var imagesPromoteCmd = &cobra.Command{
+	Use:   "promote [source] [target]",
+	Short: "Tag an image and push it to another registry",
+	Long: `Promote an image from one registry to another, e.g. from staging to
+production: the source image is tagged as the target and pushed with the
+credentials of --registry, through the Docker engine of an environment.
+Once the push succeeded, the target tag is removed from the engine again,
+so promotions leave no images behind; use --keep-tag to keep it. A target
+tag that was already on the engine is always kept.
+
+The source image must be present on the engine, unless --pull is given to
+pull it first, with the credentials of --source-registry.
+
+Examples:
+  portainer-cli images promote staging.example.com/app:1.4 registry.example.com/app:1.4 --endpoint 1 --registry 2
+  portainer-cli images promote staging.example.com/app:1.4 registry.example.com/app:1.4 --endpoint 1 --registry 2 --pull --source-registry 1`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+		registryID, err := cmd.Flags().GetInt("registry")
+		if err != nil {
+			return err
+		}
+		pull, err := cmd.Flags().GetBool("pull")
+		if err != nil {
+			return err
+		}
+		sourceRegistryID, err := cmd.Flags().GetInt("source-registry")
+		if err != nil {
+			return err
+		}
+		if sourceRegistryID != 0 && !pull {
+			return fmt.Errorf("--source-registry requires --pull")
+		}
+		keepTag, err := cmd.Flags().GetBool("keep-tag")
+		if err != nil {
+			return err
+		}
+
+		source := args[0]
+		// Without a tag the engine would push every tag of the repository
+		parts := splitImageName(args[1])
+		target := parts[0] + ":" + parts[1]
+		sourceParts := splitImageName(source)
+		if target == sourceParts[0]+":"+sourceParts[1] {
+			return fmt.Errorf("the target must differ from the source image")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		imageService := portainer.NewImageService(c)
+
+		format := getOutputFormat()
+		report := !GetQuiet() && format != output.FormatJSON && format != output.FormatYAML
+
+		if pull {
+			if report {
+				fmt.Printf("Pulling %s\n", source)
+			}
+			emitProgress(progressEvent{Event: "pull_started", Endpoint: endpointID, Image: source})
+			err := imageService.PullWithProgress(endpointID, source, sourceRegistryID, func(progress portainer.PullProgress) {
+				emitProgress(pullProgressEvent(endpointID, source, progress))
+			})
+			if err != nil {
+				emitProgress(progressEvent{Event: "pull_failed", Endpoint: endpointID, Image: source, Error: err.Error()})
+				return err
+			}
+			emitProgress(progressEvent{Event: "pulled", Endpoint: endpointID, Image: source})
+		}
+
+		// A tag that was on the engine before is never removed
+		if !keepTag {
+			if _, err := imageService.Inspect(endpointID, target); err == nil {
+				keepTag = true
+			} else if !portainer.IsNotFoundError(err) {
+				return err
+			}
+		}
+
+		if report {
+			fmt.Printf("Tagging %s as %s\n", source, target)
+		}
+		if err := imageService.Tag(endpointID, source, parts[0], parts[1]); err != nil {
+			return err
+		}
+
+		if report {
+			fmt.Printf("Pushing %s\n", target)
+		}
+		emitProgress(progressEvent{Event: "push_started", Endpoint: endpointID, Image: target})
+		result, err := imageService.PushWithProgress(endpointID, target, registryID, func(progress portainer.PushProgress) {
+			emitProgress(pushProgressEvent(endpointID, target, progress))
+			if report && progress.ID != "" && isPushedLayerStatus(progress.Status) {
+				fmt.Printf("  %s: %s\n", progress.ID, progress.Status)
+			}
+		})
+		if err != nil {
+			emitProgress(progressEvent{Event: "push_failed", Endpoint: endpointID, Image: target, Error: err.Error()})
+			return fmt.Errorf("%w (the tag %s was kept on the engine)", err, target)
+		}
+		promoted := promotedImage{Source: source, Target: target}
+		if result != nil {
+			promoted.Digest = result.Digest
+		}
+		emitProgress(progressEvent{Event: "pushed", Endpoint: endpointID, Image: target, Digest: promoted.Digest})
+
+		if !keepTag {
+			if report {
+				fmt.Printf("Removing tag %s\n", target)
+			}
+			// Removing a tag of an image with other tags only untags it
+			if err := imageService.Remove(endpointID, target, false); err != nil {
+				fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("failed to remove tag %s: %v", target, err)))
+			}
+		}
+
+		if GetDryRun() {
+			return nil
+		}
+		if GetQuiet() {
+			if promoted.Digest != "" {
+				fmt.Println(promoted.Digest)
+			}
+			return nil
+		}
+
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			return newFormatter(format).Format(promoted)
+		default:
+			if promoted.Digest != "" {
+				fmt.Printf("Promoted '%s' to '%s' (%s)\n", source, target, promoted.Digest)
+			} else {
+				fmt.Printf("Promoted '%s' to '%s'\n", source, target)
+			}
+			return nil
+		}
+	},
+}
+
+// isPushedLayerStatus reports whether a push status marks a layer as done:
+// "Pushed", "Layer already exists" or "Mounted from <repository>"
+func isPushedLayerStatus(status string) bool {
+	return status == "Pushed" || status == "Layer already exists" || strings.HasPrefix(status, "Mounted from")
+}
+
+func init() {
+	imagesCmd.AddCommand(imagesPromoteCmd)
+
+	imagesPromoteCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	imagesPromoteCmd.Flags().Int("registry", 0, "Registry ID for authentication with the target registry")
+	imagesPromoteCmd.Flags().Bool("pull", false, "Pull the source image before promoting it")
+	imagesPromoteCmd.Flags().Int("source-registry", 0, "Registry ID for authentication with the source registry (with --pull)")
+	imagesPromoteCmd.Flags().Bool("keep-tag", false, "Keep the target tag on the engine after pushing")
+	_ = imagesPromoteCmd.MarkFlagRequired("endpoint")
+}
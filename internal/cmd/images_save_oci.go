@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/robversluis/portainer-cli/internal/ocilayout"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+// savedImage is an image written by images save-oci
+type savedImage struct {
+	Image  string `json:"Image"`
+	Dir    string `json:"Dir"`
+	Ref    string `json:"Ref,omitempty"`
+	Digest string `json:"Digest"`
+	Layers int    `json:"Layers"`
+	Size   int64  `json:"Size"`
+}
+
+var imagesSaveOCICmd = &cobra.Command{
+	Use:   "save-oci [image]",
+	Short: "Export an image to an OCI image layout",
+	Long: `Export an image of an environment into an OCI image layout directory, so it
+can be copied, inspected or scanned offline by tools such as skopeo, crane or
+umoci. The archive of docker save is translated into the layout while it is
+downloaded.
+
+The directory is created when missing. Images saved to an existing layout are
+added to it, replacing the image with the same reference. The reference is
+the tag of the image unless --ref is given.
+
+Examples:
+  portainer-cli images save-oci nginx:1.27 --endpoint 1 --dir ./oci
+  skopeo copy oci:./oci:1.27 docker-archive:nginx.tar
+
+  portainer-cli images save-oci team/app:2.3 --endpoint 1 --dir ./oci --ref app-2.3
+  crane push ./oci registry.example.com/team/app:2.3`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+		dir, err := cmd.Flags().GetString("dir")
+		if err != nil {
+			return err
+		}
+		if dir == "" {
+			return fmt.Errorf("--dir flag is required")
+		}
+		ref, err := cmd.Flags().GetString("ref")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		body, err := portainer.NewImageService(c).Save(endpointID, args[0])
+		if err != nil {
+			return err
+		}
+		if body == nil {
+			return nil
+		}
+		defer body.Close()
+
+		format := getOutputFormat()
+		report := !GetQuiet() && format != output.FormatJSON && format != output.FormatYAML
+		if report {
+			fmt.Printf("Saving %s to %s\n", args[0], dir)
+		}
+
+		images, err := ocilayout.Write(dir, body, ref)
+		if err != nil {
+			return fmt.Errorf("failed to write OCI layout: %w", err)
+		}
+
+		saved := make([]savedImage, 0, len(images))
+		for _, image := range images {
+			saved = append(saved, savedImage{
+				Image:  args[0],
+				Dir:    dir,
+				Ref:    image.Manifest.Annotations[ocilayout.AnnotationRefName],
+				Digest: image.Manifest.Digest,
+				Layers: image.Layers,
+				Size:   image.Size,
+			})
+		}
+
+		switch {
+		case GetQuiet():
+			for _, s := range saved {
+				fmt.Println(s.Digest)
+			}
+		case format == output.FormatJSON || format == output.FormatYAML:
+			return newFormatter(format).Format(saved)
+		default:
+			for _, s := range saved {
+				if s.Layers > 0 {
+					fmt.Printf("Saved %s to %s (%s, %d layers, %s)\n", s.Image, s.Dir, s.Digest, s.Layers, output.FormatSize(s.Size))
+				} else {
+					fmt.Printf("Saved %s to %s (%s)\n", s.Image, s.Dir, s.Digest)
+				}
+				if s.Ref != "" {
+					fmt.Printf("  skopeo copy oci:%s:%s ...\n", s.Dir, s.Ref)
+				}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	imagesCmd.AddCommand(imagesSaveOCICmd)
+
+	imagesSaveOCICmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	imagesSaveOCICmd.Flags().String("dir", "", "OCI image layout directory to write the image to (required)")
+	imagesSaveOCICmd.Flags().String("ref", "", "Reference name of the image in the layout (default: the image tag)")
+	_ = imagesSaveOCICmd.MarkFlagRequired("endpoint")
+	_ = imagesSaveOCICmd.MarkFlagRequired("dir")
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestSummarizeImageHistory(t *testing.T) {
+	history := []portainer.ImageHistoryItem{
+		{CreatedBy: "/bin/sh -c #(nop)  CMD [\"nginx\"]"},
+		{CreatedBy: "/bin/sh -c apt-get install -y curl", Size: 30},
+		{CreatedBy: "/bin/sh -c #(nop) ADD file:abc in /", Size: 70},
+	}
+
+	summary := summarizeImageHistory(history)
+	if summary.TotalSize != 100 || summary.Layers != 3 || summary.EmptyLayers != 1 {
+		t.Errorf("unexpected totals: %+v", summary)
+	}
+	if len(summary.LargestLayers) != 2 {
+		t.Fatalf("expected 2 sized layers, got %d", len(summary.LargestLayers))
+	}
+	if summary.LargestLayers[0].Size != 70 || summary.LargestLayers[0].Percent != 70 {
+		t.Errorf("expected largest layer first, got %+v", summary.LargestLayers[0])
+	}
+}
+
+func TestBuildImageReport(t *testing.T) {
+	results := []endpointImages{
+		{
+			environment: portainer.Environment{Id: 1, Name: "prod"},
+			images: []portainer.Image{
+				{Id: "sha256:aaaaaaaaaaaaaaaa", RepoTags: []string{"nginx:latest"}, Size: 100},
+				{Id: "sha256:cccccccccccccccc", RepoTags: []string{"<none>:<none>"}, Size: 5},
+			},
+		},
+		{
+			environment: portainer.Environment{Id: 2, Name: "staging"},
+			images: []portainer.Image{
+				{Id: "sha256:bbbbbbbbbbbbbbbb", RepoTags: []string{"nginx:latest"}, Size: 110},
+				{Id: "sha256:dddddddddddddddd", RepoTags: []string{"registry:5000/app:1.0"}, Size: 20},
+			},
+		},
+		{
+			environment: portainer.Environment{Id: 3, Name: "down"},
+			err:         fmt.Errorf("unreachable"),
+		},
+	}
+
+	report := buildImageReport(results)
+
+	if report.Summary.Environments != 2 || report.Summary.Images != 4 || report.Summary.Duplicates != 1 {
+		t.Errorf("unexpected summary: %+v", report.Summary)
+	}
+	if report.Summary.TotalSize != 235 {
+		t.Errorf("expected total size 235, got %d", report.Summary.TotalSize)
+	}
+
+	for _, entry := range report.Images {
+		switch entry.Repository {
+		case "nginx":
+			if !entry.Duplicate {
+				t.Errorf("expected nginx:latest to be flagged duplicate")
+			}
+		case "registry:5000/app":
+			if entry.Tag != "1.0" || entry.Duplicate {
+				t.Errorf("unexpected entry: %+v", entry)
+			}
+		case "<none>":
+			if entry.Duplicate {
+				t.Errorf("untagged images must not be flagged duplicate")
+			}
+		default:
+			t.Errorf("unexpected repository %q", entry.Repository)
+		}
+	}
+}
+
+func TestSplitImageRef(t *testing.T) {
+	tests := []struct {
+		ref        string
+		repository string
+		tag        string
+	}{
+		{ref: "nginx:1.25", repository: "nginx", tag: "1.25"},
+		{ref: "registry:5000/team/app", repository: "registry:5000/team/app", tag: "latest"},
+		{ref: "registry:5000/team/app:v2", repository: "registry:5000/team/app", tag: "v2"},
+		{ref: "<none>:<none>", repository: "<none>", tag: "<none>"},
+	}
+
+	for _, tt := range tests {
+		repository, tag := splitImageRef(tt.ref)
+		if repository != tt.repository || tag != tt.tag {
+			t.Errorf("%s: expected (%q, %q), got (%q, %q)", tt.ref, tt.repository, tt.tag, repository, tag)
+		}
+	}
+}
+
+func TestPruneEndpointImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/endpoints/1/docker/images/prune":
+			w.Write([]byte(`{"ImagesDeleted":[{"Untagged":"app:1"},{"Deleted":"sha256:aaa"}],"SpaceReclaimed":1000}`))
+		case "/api/endpoints/2/docker/images/prune":
+			w.Write([]byte(`{"ImagesDeleted":[{"Deleted":"sha256:bbb"},{"Deleted":"sha256:ccc"}],"SpaceReclaimed":500}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message":"engine unavailable"}`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := portainer.NewClient(&portainer.Config{URL: server.URL, APIKey: "test-key"}, portainer.WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	environments := []portainer.Environment{{Id: 1, Name: "local"}, {Id: 2, Name: "edge"}, {Id: 3, Name: "broken"}}
+	summary := pruneEndpointImages(portainer.NewImageService(c), environments, map[string][]string{"dangling": {"true"}})
+
+	if summary.ImagesDeleted != 3 || summary.SpaceReclaimed != 1500 || summary.Failed != 1 {
+		t.Errorf("unexpected totals: %+v", summary)
+	}
+	if len(summary.Endpoints) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(summary.Endpoints))
+	}
+	if summary.Endpoints[0].Environment != "local" || summary.Endpoints[0].ImagesDeleted != 1 {
+		t.Errorf("unexpected first result: %+v", summary.Endpoints[0])
+	}
+	if summary.Endpoints[2].Error == "" {
+		t.Error("expected an error for the failing environment")
+	}
+}
+
+func TestImagePruneItems(t *testing.T) {
+	items := imagePruneItems([]portainer.ImageDeleteItem{
+		{Untagged: "nginx:1.24"},
+		{Untagged: "nginx@sha256:abc"},
+		{Deleted: "sha256:def"},
+	})
+
+	expected := []string{"untagged: nginx:1.24", "untagged: nginx@sha256:abc", "deleted: sha256:def"}
+	if fmt.Sprint(items) != fmt.Sprint(expected) {
+		t.Errorf("expected %v, got %v", expected, items)
+	}
+}
+
+func TestImagesPruneAllEndpointsCommand(t *testing.T) {
+	var pruned []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/endpoints":
+			w.Write([]byte(`[{"Id":1,"Name":"local","Type":1,"Status":1},{"Id":2,"Name":"down","Type":1,"Status":2}]`))
+		case "/api/endpoints/1/docker/images/prune":
+			pruned = append(pruned, r.URL.Path)
+			w.Write([]byte(`{"ImagesDeleted":[{"Deleted":"sha256:aaa"}],"SpaceReclaimed":1000}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	if err := executeCommand(t, server.URL, "images", "prune", "--all-endpoints", "-q"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pruned) != 1 {
+		t.Errorf("expected environment 1 to be pruned, got %v", pruned)
+	}
+}
+
+func TestImagesPromoteCommand(t *testing.T) {
+	for _, tc := range []struct {
+		name, source string
+		existing     bool
+		removed      bool
+		wantErr      bool
+	}{
+		{name: "new tag", source: "staging.example.com/app:1.4", removed: true},
+		{name: "existing tag", source: "staging.example.com/app:1.4", existing: true},
+		{name: "same image", source: "registry.example.com/app", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, r.Method+" "+r.URL.Path)
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/api/endpoints/1/docker/images/registry.example.com/app:latest/json":
+					if !tc.existing {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					w.Write([]byte(`{"Id":"sha256:old"}`))
+				case strings.HasSuffix(r.URL.Path, "/push"):
+					w.Write([]byte(`{"aux":{"Tag":"latest","Digest":"sha256:abc","Size":1}}`))
+				default:
+					w.WriteHeader(http.StatusOK)
+				}
+			}))
+			defer server.Close()
+
+			err := executeCommand(t, server.URL, "images", "promote", tc.source, "registry.example.com/app", "--endpoint", "1", "-q")
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected an error for a target equal to the source")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			removed := false
+			for _, call := range calls {
+				if strings.HasPrefix(call, http.MethodDelete) {
+					removed = true
+				}
+			}
+			if removed != tc.removed {
+				t.Errorf("expected tag removal %v, got calls %v", tc.removed, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	neturl "net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/terminal"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up a profile interactively",
+	Long: `Guide through the first-time setup: asks for the Portainer URL and how to
+authenticate, with an API key or by logging in with a username and password,
+checks that Portainer can be reached, optionally picks a default environment
+for commands taking --endpoint, and saves the profile.
+
+Use "config create-profile" to set up profiles non-interactively.
+
+Example:
+  portainer-cli init`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		wizard := newSetupWizard(os.Stdin, os.Stdout)
+		if terminal.IsTerminal(os.Stdin) {
+			wizard.readSecret = func() (string, error) {
+				secret, err := term.ReadPassword(int(syscall.Stdin))
+				fmt.Fprintln(wizard.out)
+				return string(secret), err
+			}
+			if terminal.IsTerminal(os.Stdout) {
+				wizard.pick = func(prompt string, items []string) (int, error) {
+					return terminal.Pick(os.Stdin, os.Stdout, prompt, items, 0)
+				}
+			}
+		}
+
+		name, profile, err := wizard.run(cfg)
+		if err != nil {
+			return err
+		}
+
+		cfg.SetProfile(name, profile)
+		if cfg.CurrentProfile == "" || cfg.CurrentProfile == name ||
+			wizard.confirm(fmt.Sprintf("Switch to profile '%s' now?", name), true) {
+			if err := cfg.SetCurrentProfile(name); err != nil {
+				return err
+			}
+		}
+
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+
+		fmt.Printf("\nSaved profile '%s'. Try: portainer-cli environments list\n", name)
+		return nil
+	},
+}
+
+// setupWizard asks the questions of the init command. Answers are read line
+// by line from in; readSecret and pick are replaced with terminal prompts
+// when running in a terminal.
+type setupWizard struct {
+	in         *bufio.Reader
+	out        io.Writer
+	readSecret func() (string, error)
+	pick       func(prompt string, items []string) (int, error)
+	options    []portainer.ClientOption
+}
+
+func newSetupWizard(in io.Reader, out io.Writer) *setupWizard {
+	w := &setupWizard{in: bufio.NewReader(in), out: out}
+	w.readSecret = w.readLine
+	w.pick = w.pickNumber
+	return w
+}
+
+// run asks for the profile settings and verifies them against Portainer. It
+// returns the profile name and the profile to save.
+func (w *setupWizard) run(cfg *config.Config) (string, *config.Profile, error) {
+	fmt.Fprintln(w.out, "Set up a connection to Portainer.")
+	fmt.Fprintln(w.out)
+
+	name, err := w.ask("Profile name", "default")
+	if err != nil {
+		return "", nil, err
+	}
+	if _, exists := cfg.Profiles[name]; exists && !w.confirm(fmt.Sprintf("Profile '%s' exists. Overwrite it?", name), false) {
+		return "", nil, fmt.Errorf("setup cancelled")
+	}
+
+	profile := &config.Profile{}
+	for profile.URL == "" {
+		answer, err := w.ask("Portainer URL", "")
+		if err != nil {
+			return "", nil, err
+		}
+		if profile.URL, err = normalizeURL(answer); err != nil {
+			fmt.Fprintln(w.out, err)
+		}
+	}
+
+	choice, err := w.pick("Authenticate with> ", []string{"API key", "Username and password"})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var password string
+	if choice == 0 {
+		fmt.Fprintln(w.out, "Create an API key under My account > Access tokens in Portainer.")
+		for profile.APIKey == "" {
+			fmt.Fprint(w.out, "API key: ")
+			if profile.APIKey, err = w.readSecret(); err != nil {
+				return "", nil, err
+			}
+		}
+	} else {
+		for profile.Username == "" {
+			if profile.Username, err = w.ask("Username", ""); err != nil {
+				return "", nil, err
+			}
+		}
+		for password == "" {
+			fmt.Fprint(w.out, "Password: ")
+			if password, err = w.readSecret(); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+
+	c, err := w.connect(profile)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if password != "" {
+		token, err := portainer.NewAuthService(c).Login(profile.Username, password)
+		if err != nil {
+			return "", nil, err
+		}
+		profile.Token = token
+		fmt.Fprintf(w.out, "Logged in as %s\n", profile.Username)
+	}
+
+	environments, err := portainer.NewEnvironmentService(c).List()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+	fmt.Fprintf(w.out, "Found %d environments\n", len(environments))
+
+	if len(environments) > 0 && w.confirm("Set a default environment for commands taking --endpoint?", true) {
+		items := make([]string, len(environments))
+		for i, env := range environments {
+			items[i] = fmt.Sprintf("%s (ID %d, %s)", env.Name, env.Id, env.TypeString())
+		}
+		index, err := w.pick("environment> ", items)
+		switch {
+		case errors.Is(err, terminal.ErrCancelled):
+		case err != nil:
+			return "", nil, err
+		default:
+			profile.Endpoint = environments[index].Id
+		}
+	}
+
+	return name, profile, nil
+}
+
+// connect checks that Portainer answers on the profile URL and prints its
+// version. A certificate that cannot be verified may be accepted, which
+// sets the profile to skip TLS verification.
+func (w *setupWizard) connect(profile *config.Profile) (*portainer.Client, error) {
+	for {
+		c, err := portainer.NewClient(profile.ClientConfig(), w.options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client: %w", err)
+		}
+
+		status, err := portainer.NewAuthService(c).GetStatus()
+		if err == nil {
+			fmt.Fprintf(w.out, "Connected to Portainer %s\n", status.Version)
+			return c, nil
+		}
+
+		if profile.Insecure || !strings.Contains(err.Error(), "x509") {
+			return nil, fmt.Errorf("failed to connect to %s: %w", profile.URL, err)
+		}
+		fmt.Fprintf(w.out, "The TLS certificate of %s could not be verified: %v\n", profile.URL, err)
+		if !w.confirm("Skip TLS verification for this profile?", false) {
+			return nil, fmt.Errorf("failed to connect to %s: %w", profile.URL, err)
+		}
+		profile.Insecure = true
+	}
+}
+
+// ask prints a question and returns the answer, or def for an empty answer
+func (w *setupWizard) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", question)
+	}
+
+	answer, err := w.readLine()
+	if err != nil {
+		return "", err
+	}
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// confirm asks a yes/no question, returning def for an empty answer or when
+// the input has ended
+func (w *setupWizard) confirm(question string, def bool) bool {
+	if def {
+		fmt.Fprintf(w.out, "%s [Y/n] ", question)
+	} else {
+		fmt.Fprintf(w.out, "%s [y/N] ", question)
+	}
+
+	answer, err := w.readLine()
+	if err != nil || answer == "" {
+		return def
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes"
+}
+
+// pickNumber lists the items numbered from 1 and asks for a number
+func (w *setupWizard) pickNumber(prompt string, items []string) (int, error) {
+	for i, item := range items {
+		fmt.Fprintf(w.out, "  %d) %s\n", i+1, item)
+	}
+	for {
+		answer, err := w.ask(strings.TrimSuffix(strings.TrimSpace(prompt), ">"), "1")
+		if err != nil {
+			return 0, err
+		}
+		n, err := strconv.Atoi(answer)
+		if err == nil && n >= 1 && n <= len(items) {
+			return n - 1, nil
+		}
+		fmt.Fprintf(w.out, "Enter a number from 1 to %d\n", len(items))
+	}
+}
+
+func (w *setupWizard) readLine() (string, error) {
+	line, err := w.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", fmt.Errorf("setup cancelled: input ended")
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// normalizeURL checks a Portainer URL, defaulting to https:// when no scheme
+// is given
+func normalizeURL(raw string) (string, error) {
+	if raw == "" {
+		return "", fmt.Errorf("a URL is required")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	parsed, err := neturl.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("invalid URL '%s' (expected http:// or https://)", raw)
+	}
+	return strings.TrimSuffix(raw, "/"), nil
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func newInitTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/status":
+			json.NewEncoder(w).Encode(portainer.StatusResponse{Version: "2.19.4"})
+		case "/api/auth":
+			var login portainer.LoginRequest
+			json.NewDecoder(r.Body).Decode(&login)
+			if login.Username != "admin" || login.Password != "secret" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			json.NewEncoder(w).Encode(portainer.LoginResponse{JWT: "jwt-token"})
+		case "/api/endpoints":
+			if r.Header.Get("X-API-Key") != "ptr_key" && r.Header.Get("Authorization") != "Bearer jwt-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode([]portainer.Environment{
+				{Id: 1, Name: "local", Type: portainer.EnvironmentTypeDockerLocal},
+				{Id: 3, Name: "prod", Type: portainer.EnvironmentTypeDockerLocal},
+			})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+}
+
+func TestSetupWizard(t *testing.T) {
+	server := newInitTestServer(t)
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		input    []string
+		expected config.Profile
+	}{
+		{
+			name:     "api key with default environment",
+			input:    []string{"", server.URL + "/", "1", "ptr_key", "", "2"},
+			expected: config.Profile{URL: server.URL, APIKey: "ptr_key", Endpoint: 3},
+		},
+		{
+			name:     "login without default environment",
+			input:    []string{"", server.URL, "2", "admin", "secret", "n"},
+			expected: config.Profile{URL: server.URL, Username: "admin", Token: "jwt-token"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			wizard := newSetupWizard(strings.NewReader(strings.Join(tt.input, "\n")+"\n"), &out)
+
+			name, profile, err := wizard.run(&config.Config{Profiles: map[string]*config.Profile{}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v\n%s", err, out.String())
+			}
+			if name != "default" {
+				t.Errorf("expected profile name default, got %s", name)
+			}
+			if *profile != tt.expected {
+				t.Errorf("expected profile %+v, got %+v", tt.expected, *profile)
+			}
+			if !strings.Contains(out.String(), "Connected to Portainer 2.19.4") {
+				t.Errorf("expected the version in the output:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestSetupWizard_ExistingProfile(t *testing.T) {
+	var out bytes.Buffer
+	wizard := newSetupWizard(strings.NewReader("prod\n\n"), &out)
+
+	cfg := &config.Config{Profiles: map[string]*config.Profile{"prod": {URL: "https://prod.example.com"}}}
+	if _, _, err := wizard.run(cfg); err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("expected the setup to be cancelled, got %v", err)
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := map[string]string{
+		"portainer.example.com":         "https://portainer.example.com",
+		"http://localhost:9000/":        "http://localhost:9000",
+		"https://portainer.example.com": "https://portainer.example.com",
+	}
+	for input, expected := range tests {
+		url, err := normalizeURL(input)
+		if err != nil {
+			t.Errorf("normalizeURL(%q): unexpected error: %v", input, err)
+		} else if url != expected {
+			t.Errorf("normalizeURL(%q) = %q, expected %q", input, url, expected)
+		}
+	}
+
+	for _, input := range []string{"", "ftp://example.com", "https://"} {
+		if _, err := normalizeURL(input); err == nil {
+			t.Errorf("normalizeURL(%q): expected error", input)
+		}
+	}
+}
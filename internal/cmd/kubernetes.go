@@ -0,0 +1,479 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/kubeconfig"
+	"github.com/robversluis/portainer-cli/internal/notify"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var kubernetesCmd = &cobra.Command{
+	Use:     "kubernetes",
+	Aliases: []string{"k8s"},
+	Short:   "Manage Kubernetes environments",
+	Long:    `Manage namespaces and kubeconfig access for Kubernetes environments.`,
+}
+
+var namespacesCmd = &cobra.Command{
+	Use:     "namespaces",
+	Aliases: []string{"ns"},
+	Short:   "Manage Kubernetes namespaces",
+	Long:    `List, create, and delete namespaces and configure their resource quotas.`,
+}
+
+var namespacesListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List namespaces",
+	Long:    `Display the namespaces of a Kubernetes environment with their quotas.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		listOpts, err := getListOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		kubernetesService := portainer.NewKubernetesService(c)
+		format := getOutputFormat()
+
+		var values notify.Values
+		listFunc := func() error {
+			namespaces, err := kubernetesService.ListNamespaces(endpointID)
+			if err != nil {
+				return err
+			}
+
+			values = namespaceWatchValues(namespaces)
+
+			if GetQuiet() {
+				return printQuiet(listOpts, namespaces, func(item portainer.Namespace) string {
+					return item.Name
+				})
+			}
+
+			switch format {
+			case output.FormatJSON, output.FormatYAML:
+				formatter := newFormatter(format)
+				items, err := listOpts.applyItems(namespaces)
+				if err != nil {
+					return err
+				}
+				return formatter.Format(items)
+
+			default:
+				table := output.NewTableData([]string{"Name", "Status", "Owner", "System", "CPU Quota", "Memory Quota"})
+				for _, namespace := range namespaces {
+					cpu, memory := "-", "-"
+					if quota := namespace.Quota(); quota != nil {
+						cpu, memory = quotaValue(quota.CPU), quotaValue(quota.Memory)
+					}
+					owner := namespace.NamespaceOwner
+					if owner == "" {
+						owner = "-"
+					}
+					table.AddRow([]string{
+						namespace.Name,
+						namespace.StatusString(),
+						owner,
+						output.FormatBool(namespace.IsSystem),
+						cpu,
+						memory,
+					})
+				}
+				if err := listOpts.applyTable(table, namespaces); err != nil {
+					return err
+				}
+				return output.PrintTable(*table)
+			}
+		}
+
+		return RunWithWatch(cmd, "namespaces", listFunc, func() notify.Values { return values })
+	},
+}
+
+var namespacesCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a namespace",
+	Long: `Create a Kubernetes namespace, optionally with a resource quota.
+
+For example:
+  portainer-cli kubernetes namespaces create team-a --endpoint 3 --cpu 2 --memory 4Gi`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		owner, err := cmd.Flags().GetString("owner")
+		if err != nil {
+			return err
+		}
+
+		quota, err := getNamespaceQuota(cmd)
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		kubernetesService := portainer.NewKubernetesService(c)
+		if err := kubernetesService.CreateNamespace(endpointID, &portainer.NamespaceRequest{
+			Name:          args[0],
+			Owner:         owner,
+			ResourceQuota: quota,
+		}); err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Namespace %s created successfully\n", args[0])
+		}
+
+		return nil
+	},
+}
+
+var namespacesDeleteCmd = &cobra.Command{
+	Use:     "delete [name...]",
+	Aliases: []string{"rm"},
+	Short:   "Delete namespaces",
+	Long:    `Delete one or more Kubernetes namespaces and everything in them.`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		kubernetesService := portainer.NewKubernetesService(c)
+		if err := kubernetesService.DeleteNamespaces(endpointID, args); err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			for _, name := range args {
+				fmt.Printf("Namespace %s deleted successfully\n", name)
+			}
+		}
+
+		return nil
+	},
+}
+
+var namespacesQuotaCmd = &cobra.Command{
+	Use:   "quota [name]",
+	Short: "Configure a namespace resource quota",
+	Long: `Set or remove the CPU and memory quota of a namespace.
+
+For example:
+  portainer-cli kubernetes namespaces quota team-a --endpoint 3 --cpu 4 --memory 8Gi
+  portainer-cli kubernetes namespaces quota team-a --endpoint 3 --disable`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		disable, err := cmd.Flags().GetBool("disable")
+		if err != nil {
+			return err
+		}
+
+		quota, err := getNamespaceQuota(cmd)
+		if err != nil {
+			return err
+		}
+		switch {
+		case disable && quota != nil:
+			return fmt.Errorf("--disable cannot be combined with --cpu or --memory")
+		case disable:
+			quota = &portainer.NamespaceQuota{Enabled: false}
+		case quota == nil:
+			return fmt.Errorf("specify --cpu and/or --memory, or --disable")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		kubernetesService := portainer.NewKubernetesService(c)
+
+		// Keep the existing owner, annotations and quota limits, which the
+		// update replaces
+		namespace, err := kubernetesService.GetNamespace(endpointID, args[0])
+		if err != nil {
+			return err
+		}
+
+		if !disable {
+			quota = mergeNamespaceQuota(quota, namespace.Quota())
+		}
+
+		if err := kubernetesService.UpdateNamespace(endpointID, &portainer.NamespaceRequest{
+			Name:          args[0],
+			Owner:         namespace.NamespaceOwner,
+			Annotations:   namespace.Annotations,
+			ResourceQuota: quota,
+		}); err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			if disable {
+				fmt.Printf("Resource quota removed from namespace %s\n", args[0])
+			} else {
+				fmt.Printf("Resource quota of namespace %s updated\n", args[0])
+			}
+		}
+
+		return nil
+	},
+}
+
+var kubeconfigCmd = &cobra.Command{
+	Use:   "kubeconfig",
+	Short: "Download a kubeconfig",
+	Long: `Download a kubeconfig for your user that connects kubectl to Kubernetes
+environments through Portainer.
+
+Without --merge the kubeconfig is printed, or written to --file. With --merge
+it is merged into --file, $KUBECONFIG, or ~/.kube/config (a backup of the
+previous file is kept with a .bak suffix) and the current context is
+switched to the downloaded one unless --no-switch is given. For example:
+  portainer-cli kubernetes kubeconfig --endpoint 3 --merge`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointIDs, err := cmd.Flags().GetIntSlice("endpoint")
+		if err != nil {
+			return err
+		}
+		if len(endpointIDs) == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		merge, err := cmd.Flags().GetBool("merge")
+		if err != nil {
+			return err
+		}
+		noSwitch, err := cmd.Flags().GetBool("no-switch")
+		if err != nil {
+			return err
+		}
+		path, err := cmd.Flags().GetString("file")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		kubernetesService := portainer.NewKubernetesService(c)
+		content, err := kubernetesService.Kubeconfig(endpointIDs)
+		if err != nil {
+			return err
+		}
+		if content == nil {
+			return nil
+		}
+
+		if !merge {
+			if path == "" {
+				fmt.Print(string(content))
+				return nil
+			}
+			if err := kubeconfig.WriteFile(path, content); err != nil {
+				return err
+			}
+			if !GetQuiet() {
+				fmt.Printf("Kubeconfig written to %s\n", path)
+			}
+			return nil
+		}
+
+		if path == "" {
+			path, err = kubeconfig.DefaultPath()
+			if err != nil {
+				return err
+			}
+		}
+
+		existing, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read kubeconfig: %w", err)
+		}
+
+		merged, err := kubeconfig.Merge(existing, content, !noSwitch)
+		if err != nil {
+			return err
+		}
+
+		if len(existing) > 0 {
+			if err := kubeconfig.WriteFile(path+".bak", existing); err != nil {
+				return err
+			}
+		}
+		if err := kubeconfig.WriteFile(path, merged); err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Kubeconfig merged into %s\n", path)
+		}
+
+		return nil
+	},
+}
+
+// getNamespaceQuota builds a quota from --cpu and --memory, returning nil
+// when neither is set
+func getNamespaceQuota(cmd *cobra.Command) (*portainer.NamespaceQuota, error) {
+	cpu, err := cmd.Flags().GetString("cpu")
+	if err != nil {
+		return nil, err
+	}
+	memory, err := cmd.Flags().GetString("memory")
+	if err != nil {
+		return nil, err
+	}
+	if cpu == "" && memory == "" {
+		return nil, nil
+	}
+	return &portainer.NamespaceQuota{Enabled: true, CPU: cpu, Memory: memory}, nil
+}
+
+// mergeNamespaceQuota keeps the limits of current that quota leaves unset,
+// so setting --cpu does not remove the memory limit and vice versa
+func mergeNamespaceQuota(quota, current *portainer.NamespaceQuota) *portainer.NamespaceQuota {
+	if current == nil {
+		return quota
+	}
+	merged := *quota
+	if merged.CPU == "" {
+		merged.CPU = current.CPU
+	}
+	if merged.Memory == "" {
+		merged.Memory = current.Memory
+	}
+	return &merged
+}
+
+func quotaValue(value string) string {
+	if value == "" || value == "0" {
+		return "unlimited"
+	}
+	return value
+}
+
+// namespaceWatchValues returns the --notify-on variables for namespaces: the
+// count and the number that are active and terminating
+func namespaceWatchValues(namespaces []portainer.Namespace) notify.Values {
+	values := notify.Values{"count": float64(len(namespaces)), "active": 0, "terminating": 0}
+	for _, namespace := range namespaces {
+		switch strings.ToLower(namespace.Status.Phase) {
+		case "active":
+			values["active"]++
+		case "terminating":
+			values["terminating"]++
+		}
+	}
+	return values
+}
+
+func init() {
+	rootCmd.AddCommand(kubernetesCmd)
+	kubernetesCmd.AddCommand(namespacesCmd)
+	namespacesCmd.AddCommand(namespacesListCmd)
+	namespacesCmd.AddCommand(namespacesCreateCmd)
+	namespacesCmd.AddCommand(namespacesDeleteCmd)
+	namespacesCmd.AddCommand(namespacesQuotaCmd)
+	kubernetesCmd.AddCommand(kubeconfigCmd)
+
+	namespacesListCmd.Flags().Int("endpoint", 0, "Kubernetes environment endpoint ID (required)")
+	AddWatchFlags(namespacesListCmd)
+	addListFlags(namespacesListCmd)
+	_ = namespacesListCmd.MarkFlagRequired("endpoint")
+
+	namespacesCreateCmd.Flags().Int("endpoint", 0, "Kubernetes environment endpoint ID (required)")
+	namespacesCreateCmd.Flags().String("owner", "", "Namespace owner (defaults to the current user)")
+	namespacesCreateCmd.Flags().String("cpu", "", "CPU quota, e.g. 2 or 500m")
+	namespacesCreateCmd.Flags().String("memory", "", "Memory quota, e.g. 4Gi or 512Mi")
+	_ = namespacesCreateCmd.MarkFlagRequired("endpoint")
+
+	namespacesDeleteCmd.Flags().Int("endpoint", 0, "Kubernetes environment endpoint ID (required)")
+	_ = namespacesDeleteCmd.MarkFlagRequired("endpoint")
+
+	namespacesQuotaCmd.Flags().Int("endpoint", 0, "Kubernetes environment endpoint ID (required)")
+	namespacesQuotaCmd.Flags().String("cpu", "", "CPU quota, e.g. 2 or 500m")
+	namespacesQuotaCmd.Flags().String("memory", "", "Memory quota, e.g. 4Gi or 512Mi")
+	namespacesQuotaCmd.Flags().Bool("disable", false, "Remove the resource quota")
+	_ = namespacesQuotaCmd.MarkFlagRequired("endpoint")
+
+	kubeconfigCmd.Flags().IntSlice("endpoint", nil, "Kubernetes environment endpoint ID, repeatable (required)")
+	kubeconfigCmd.Flags().Bool("merge", false, "Merge into the existing kubeconfig instead of printing it")
+	kubeconfigCmd.Flags().Bool("no-switch", false, "Keep the current context when merging")
+	kubeconfigCmd.Flags().String("file", "", "Kubeconfig file to write or merge into")
+	_ = kubeconfigCmd.MarkFlagRequired("endpoint")
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestMergeNamespaceQuota(t *testing.T) {
+	current := &portainer.NamespaceQuota{Enabled: true, CPU: "4", Memory: "8Gi"}
+
+	tests := []struct {
+		quota, current *portainer.NamespaceQuota
+		cpu, memory    string
+	}{
+		{&portainer.NamespaceQuota{Enabled: true, CPU: "2"}, current, "2", "8Gi"},
+		{&portainer.NamespaceQuota{Enabled: true, Memory: "2Gi"}, current, "4", "2Gi"},
+		{&portainer.NamespaceQuota{Enabled: true, CPU: "1", Memory: "1Gi"}, current, "1", "1Gi"},
+		{&portainer.NamespaceQuota{Enabled: true, CPU: "2"}, nil, "2", ""},
+	}
+	for _, tt := range tests {
+		merged := mergeNamespaceQuota(tt.quota, tt.current)
+		if !merged.Enabled || merged.CPU != tt.cpu || merged.Memory != tt.memory {
+			t.Errorf("merging %+v into %+v: got %+v", tt.quota, tt.current, merged)
+		}
+	}
+	if current.CPU != "4" || current.Memory != "8Gi" {
+		t.Errorf("expected the current quota to be left unchanged, got %+v", current)
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// listOptions holds the flags shared by all list commands
+type listOptions struct {
+	sort    string
+	reverse bool
+	limit   int
+	offset  int
+}
+
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().String("sort", "", "Sort by column (e.g. name, created, size)")
+	cmd.Flags().Bool("reverse", false, "Reverse the sort order")
+	cmd.Flags().Int("limit", 0, "Maximum number of items to show (0 for all)")
+	cmd.Flags().Int("offset", 0, "Number of items to skip")
+	cmd.Flags().Int("page", 0, "Page number to show, using --limit as the page size")
+}
+
+func getListOptions(cmd *cobra.Command) (listOptions, error) {
+	var opts listOptions
+	var err error
+
+	opts.sort, err = cmd.Flags().GetString("sort")
+	if err != nil {
+		return opts, err
+	}
+	opts.reverse, err = cmd.Flags().GetBool("reverse")
+	if err != nil {
+		return opts, err
+	}
+	opts.limit, err = cmd.Flags().GetInt("limit")
+	if err != nil {
+		return opts, err
+	}
+	opts.offset, err = cmd.Flags().GetInt("offset")
+	if err != nil {
+		return opts, err
+	}
+	page, err := cmd.Flags().GetInt("page")
+	if err != nil {
+		return opts, err
+	}
+
+	if opts.limit < 0 || opts.offset < 0 || page < 0 {
+		return opts, fmt.Errorf("--limit, --offset, and --page must not be negative")
+	}
+	if page > 0 {
+		if opts.limit == 0 {
+			return opts, fmt.Errorf("--page requires --limit")
+		}
+		if cmd.Flags().Changed("offset") {
+			return opts, fmt.Errorf("--page and --offset cannot be used together")
+		}
+		opts.offset = (page - 1) * opts.limit
+	}
+
+	return opts, nil
+}
+
+// paginated reports whether only a window of the results was requested
+func (o listOptions) paginated() bool {
+	return o.limit > 0 || o.offset > 0
+}
+
+// applyItems sorts and paginates the raw API objects for structured
+// (json/yaml) output
+func (o listOptions) applyItems(items interface{}) (interface{}, error) {
+	if o.sort != "" {
+		if err := output.SortByField(items, o.sort, o.reverse); err != nil {
+			return nil, err
+		}
+	}
+	return output.PaginateSlice(items, o.offset, o.limit), nil
+}
+
+// applyTable sorts and paginates the rendered rows for table output. Rows
+// are rendered one per element of items and sorted on its raw values, as
+// applyItems does, so table and structured output agree.
+func (o listOptions) applyTable(table *output.TableData, items interface{}) error {
+	if o.sort != "" {
+		if err := table.SortWith(items, o.sort, o.reverse); err != nil {
+			return err
+		}
+	}
+	table.Paginate(o.offset, o.limit)
+	return nil
+}
+
+// printQuiet writes one identifier per line for --quiet list output, so
+// results can be piped into other commands.
+func printQuiet[T any](opts listOptions, items []T, id func(T) string) error {
+	sorted, err := opts.applyItems(items)
+	if err != nil {
+		return err
+	}
+	for _, item := range sorted.([]T) {
+		fmt.Println(id(item))
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/metrics"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/internal/pool"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var exportMetricsCmd = &cobra.Command{
+	Use:   "export-metrics",
+	Short: "Expose environment metrics for Prometheus",
+	Long: `Poll environments, containers and stacks at a fixed interval and serve the
+results as Prometheus metrics on /metrics.
+
+Exported metrics:
+  portainer_up                              whether the last poll reached Portainer
+  portainer_environment_up                  1 when the environment status is up
+  portainer_environment_snapshot_*          values from the latest environment snapshot
+  portainer_containers                      containers by state (Docker environments)
+  portainer_containers_health               containers by health check status
+  portainer_stack_active                    1 when the stack is active
+  portainer_stacks_poll_success             0 when the stacks could not be listed
+  portainer_exporter_*                      poll duration, errors and last poll time
+
+Examples:
+  # All environments, on the default port
+  portainer-cli export-metrics
+
+  # Selected environments, polled every minute
+  portainer-cli export-metrics --endpoint 1,3 --interval 1m --listen :9273`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listen, err := cmd.Flags().GetString("listen")
+		if err != nil {
+			return err
+		}
+		interval, err := cmd.Flags().GetDuration("interval")
+		if err != nil {
+			return err
+		}
+		if interval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		endpointIDs, err := cmd.Flags().GetIntSlice("endpoint")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		exporter := newMetricsExporter(c.WithContext(ctx), endpointIDs)
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", exporter)
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprintln(w, `<html><body><a href="/metrics">Metrics</a></body></html>`)
+		})
+
+		server := &http.Server{Addr: listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		serverErr := make(chan error, 1)
+		go func() {
+			serverErr <- server.ListenAndServe()
+		}()
+
+		if !GetQuiet() {
+			fmt.Fprintf(os.Stderr, "Serving metrics on http://%s/metrics (polling every %s)\n", listen, interval)
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		exporter.poll()
+		for {
+			select {
+			case <-ticker.C:
+				exporter.poll()
+			case err := <-serverErr:
+				return fmt.Errorf("metrics server failed: %w", err)
+			case <-ctx.Done():
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					return err
+				}
+				return nil
+			}
+		}
+	},
+}
+
+// metricsExporter polls Portainer and serves the metrics of the last poll
+type metricsExporter struct {
+	client      *portainer.Client
+	endpointIDs []int
+
+	mu         sync.RWMutex
+	families   []*metrics.Family
+	pollErrors int
+}
+
+func newMetricsExporter(c *portainer.Client, endpointIDs []int) *metricsExporter {
+	return &metricsExporter{client: c, endpointIDs: endpointIDs}
+}
+
+// poll collects the metrics and replaces those served
+func (e *metricsExporter) poll() {
+	start := time.Now()
+	families, err := e.collect()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	up := metrics.NewGauge("portainer_up", "Whether the last poll reached the Portainer API.")
+	if err != nil {
+		e.pollErrors++
+		up.Add(0, nil)
+		fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("poll failed: %v", err)))
+	} else {
+		up.Add(1, nil)
+	}
+
+	duration := metrics.NewGauge("portainer_exporter_poll_duration_seconds", "Duration of the last poll.")
+	duration.Add(time.Since(start).Seconds(), nil)
+	lastPoll := metrics.NewGauge("portainer_exporter_last_poll_timestamp_seconds", "Time of the last poll.")
+	lastPoll.Add(float64(start.Unix()), nil)
+	pollErrors := metrics.NewCounter("portainer_exporter_poll_errors_total", "Number of polls that failed to reach the Portainer API.")
+	pollErrors.Add(float64(e.pollErrors), nil)
+
+	e.families = append([]*metrics.Family{up, duration, lastPoll, pollErrors}, families...)
+}
+
+func (e *metricsExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := metrics.Write(w, e.families); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// collect fetches the environments, their containers and stacks. Failures
+// for a single environment are reported through
+// portainer_environment_poll_success, and failures to list the stacks
+// through portainer_stacks_poll_success, rather than failing the poll.
+func (e *metricsExporter) collect() ([]*metrics.Family, error) {
+	environments, err := e.environments()
+	if err != nil {
+		return nil, err
+	}
+
+	envUp := metrics.NewGauge("portainer_environment_up", "Whether the environment status is up.")
+	envPolled := metrics.NewGauge("portainer_environment_poll_success", "Whether the containers of the environment could be listed.")
+	snapshotTime := metrics.NewGauge("portainer_environment_snapshot_timestamp_seconds", "Time of the latest environment snapshot.")
+	snapshotCPUs := metrics.NewGauge("portainer_environment_snapshot_cpus", "CPUs reported by the latest snapshot.")
+	snapshotMemory := metrics.NewGauge("portainer_environment_snapshot_memory_bytes", "Memory reported by the latest snapshot.")
+	snapshotContainers := metrics.NewGauge("portainer_environment_snapshot_containers", "Containers by state in the latest snapshot.")
+	snapshotImages := metrics.NewGauge("portainer_environment_snapshot_images", "Images in the latest snapshot.")
+	snapshotVolumes := metrics.NewGauge("portainer_environment_snapshot_volumes", "Volumes in the latest snapshot.")
+	snapshotStacks := metrics.NewGauge("portainer_environment_snapshot_stacks", "Stacks in the latest snapshot.")
+	containers := metrics.NewGauge("portainer_containers", "Containers by state.")
+	containersHealth := metrics.NewGauge("portainer_containers_health", "Containers by health check status.")
+	stackActive := metrics.NewGauge("portainer_stack_active", "Whether the stack is active.")
+
+	// The containers of the environments are listed concurrently up front
+	containerService := portainer.NewContainerService(e.client)
+	type containerList struct {
+		containers []portainer.Container
+		err        error
+	}
+	lists := pool.Map(GetParallel(), environments, func(env portainer.Environment) containerList {
+		if !env.IsDocker() || env.Status != portainer.EnvironmentStatusUp {
+			return containerList{}
+		}
+		list, err := containerService.List(env.Id, true)
+		return containerList{containers: list, err: err}
+	})
+
+	selected := make(map[int]metrics.Labels, len(environments))
+
+	for i, env := range environments {
+		labels := metrics.Labels{"endpoint_id": strconv.Itoa(env.Id), "endpoint": env.Name}
+		selected[env.Id] = labels
+
+		envUp.Add(boolValue(env.Status == portainer.EnvironmentStatusUp), withLabel(labels, "type", env.TypeString()))
+
+		if snapshot := env.GetLatestSnapshot(); snapshot != nil {
+			snapshotTime.Add(float64(snapshot.Time), labels)
+			snapshotCPUs.Add(float64(snapshot.TotalCPU), labels)
+			snapshotMemory.Add(float64(snapshot.TotalMemory), labels)
+			snapshotContainers.Add(float64(snapshot.RunningContainerCount), withLabel(labels, "state", "running"))
+			snapshotContainers.Add(float64(snapshot.StoppedContainerCount), withLabel(labels, "state", "stopped"))
+			snapshotContainers.Add(float64(snapshot.HealthyContainerCount), withLabel(labels, "state", "healthy"))
+			snapshotContainers.Add(float64(snapshot.UnhealthyContainerCount), withLabel(labels, "state", "unhealthy"))
+			snapshotImages.Add(float64(snapshot.ImageCount), labels)
+			snapshotVolumes.Add(float64(snapshot.VolumeCount), labels)
+			snapshotStacks.Add(float64(snapshot.StackCount), labels)
+		}
+
+		if !env.IsDocker() || env.Status != portainer.EnvironmentStatusUp {
+			continue
+		}
+
+		list, err := lists[i].containers, lists[i].err
+		if err != nil {
+			envPolled.Add(0, labels)
+			if GetVerbose() {
+				fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("environment %s: %v", env.Name, err)))
+			}
+			continue
+		}
+		envPolled.Add(1, labels)
+
+		states := map[string]int{}
+		health := map[string]int{}
+		for i := range list {
+			states[list[i].State]++
+			if h := list[i].GetHealth(); h != portainer.HealthStatusNone {
+				health[h]++
+			}
+		}
+		for _, state := range sortedKeys(states) {
+			containers.Add(float64(states[state]), withLabel(labels, "state", state))
+		}
+		for _, h := range sortedKeys(health) {
+			containersHealth.Add(float64(health[h]), withLabel(labels, "health", h))
+		}
+	}
+
+	// Stacks that cannot be listed leave the other metrics in place
+	stacksPolled := metrics.NewGauge("portainer_stacks_poll_success", "Whether the stacks could be listed.")
+	stacks, err := portainer.NewStackService(e.client).List(0)
+	if err != nil {
+		stacksPolled.Add(0, nil)
+		if GetVerbose() {
+			fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("stacks: %v", err)))
+		}
+	} else {
+		stacksPolled.Add(1, nil)
+	}
+	for _, stack := range stacks {
+		labels, ok := selected[stack.EndpointId]
+		if !ok {
+			continue
+		}
+		stackLabels := withLabel(labels, "stack", stack.Name)
+		stackLabels["stack_id"] = strconv.Itoa(stack.Id)
+		stackLabels["type"] = stack.TypeString()
+		stackActive.Add(boolValue(stack.Status == portainer.StackStatusActive), stackLabels)
+	}
+
+	return []*metrics.Family{
+		envUp, envPolled,
+		snapshotTime, snapshotCPUs, snapshotMemory, snapshotContainers, snapshotImages, snapshotVolumes, snapshotStacks,
+		containers, containersHealth,
+		stacksPolled, stackActive,
+	}, nil
+}
+
+// environments returns the environments selected with --endpoint, or all
+// environments
+func (e *metricsExporter) environments() ([]portainer.Environment, error) {
+	envService := portainer.NewEnvironmentService(e.client)
+	if len(e.endpointIDs) == 0 {
+		return envService.List()
+	}
+
+	environments := make([]portainer.Environment, 0, len(e.endpointIDs))
+	for _, id := range e.endpointIDs {
+		env, err := envService.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		environments = append(environments, *env)
+	}
+	return environments, nil
+}
+
+// withLabel returns a copy of labels with name set to value
+func withLabel(labels metrics.Labels, name, value string) metrics.Labels {
+	copied := make(metrics.Labels, len(labels)+1)
+	for k, v := range labels {
+		copied[k] = v
+	}
+	copied[name] = value
+	return copied
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func init() {
+	rootCmd.AddCommand(exportMetricsCmd)
+
+	exportMetricsCmd.Flags().String("listen", "127.0.0.1:9273", "Address to serve metrics on")
+	exportMetricsCmd.Flags().Duration("interval", 30*time.Second, "Polling interval")
+	exportMetricsCmd.Flags().IntSlice("endpoint", nil, "Environment IDs to poll (default: all environments)")
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestMetricsExporter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/endpoints":
+			json.NewEncoder(w).Encode([]portainer.Environment{
+				{
+					Id: 1, Name: "local", Type: portainer.EnvironmentTypeDockerLocal, Status: portainer.EnvironmentStatusUp,
+					Snapshots: []portainer.Snapshot{{Time: 1700000000, RunningContainerCount: 2, ImageCount: 5}},
+				},
+				{Id: 2, Name: "edge", Type: portainer.EnvironmentTypeEdgeAgentOnDocker, Status: portainer.EnvironmentStatusDown},
+			})
+		case "/api/endpoints/1/docker/containers/json":
+			json.NewEncoder(w).Encode([]portainer.Container{
+				{Id: "a", State: "running", Status: "Up 5 minutes (healthy)"},
+				{Id: "b", State: "running", Status: "Up 1 minute (unhealthy)"},
+				{Id: "c", State: "exited", Status: "Exited (0) 1 hour ago"},
+			})
+		case "/api/stacks":
+			json.NewEncoder(w).Encode([]portainer.Stack{
+				{Id: 7, Name: "web", Type: portainer.StackTypeCompose, EndpointId: 1, Status: portainer.StackStatusActive},
+				{Id: 8, Name: "other", Type: portainer.StackTypeCompose, EndpointId: 9, Status: portainer.StackStatusActive},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := portainer.NewClient(&portainer.Config{URL: server.URL, APIKey: "test-key"}, portainer.WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	exporter := newMetricsExporter(c, nil)
+	exporter.poll()
+
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := recorder.Body.String()
+
+	for _, line := range []string{
+		"portainer_up 1",
+		`portainer_environment_up{endpoint="local",endpoint_id="1",type="Docker (Local)"} 1`,
+		`portainer_environment_up{endpoint="edge",endpoint_id="2",type="Docker (Edge)"} 0`,
+		`portainer_environment_snapshot_containers{endpoint="local",endpoint_id="1",state="running"} 2`,
+		`portainer_environment_snapshot_images{endpoint="local",endpoint_id="1"} 5`,
+		`portainer_containers{endpoint="local",endpoint_id="1",state="running"} 2`,
+		`portainer_containers{endpoint="local",endpoint_id="1",state="exited"} 1`,
+		`portainer_containers_health{endpoint="local",endpoint_id="1",health="unhealthy"} 1`,
+		`portainer_stack_active{endpoint="local",endpoint_id="1",stack="web",stack_id="7",type="Compose"} 1`,
+		"portainer_stacks_poll_success 1",
+		"portainer_exporter_poll_errors_total 0",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, `stack="other"`) {
+		t.Error("stacks of unselected environments should not be exported")
+	}
+
+	server.Close()
+	exporter.poll()
+
+	recorder = httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body = recorder.Body.String()
+	if !strings.Contains(body, "portainer_up 0\n") || !strings.Contains(body, "portainer_exporter_poll_errors_total 1\n") {
+		t.Errorf("expected failed poll to be reported:\n%s", body)
+	}
+}
+
+func TestMetricsExporter_StacksUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/endpoints":
+			json.NewEncoder(w).Encode([]portainer.Environment{
+				{Id: 1, Name: "local", Type: portainer.EnvironmentTypeDockerLocal, Status: portainer.EnvironmentStatusUp},
+			})
+		case "/api/endpoints/1/docker/containers/json":
+			json.NewEncoder(w).Encode([]portainer.Container{{Id: "a", State: "running", Status: "Up 5 minutes"}})
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	c, err := portainer.NewClient(&portainer.Config{URL: server.URL, APIKey: "test-key"}, portainer.WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	exporter := newMetricsExporter(c, nil)
+	exporter.poll()
+
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := recorder.Body.String()
+
+	for _, line := range []string{
+		"portainer_up 1",
+		`portainer_containers{endpoint="local",endpoint_id="1",state="running"} 1`,
+		"portainer_stacks_poll_success 0",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, body)
+		}
+	}
+}
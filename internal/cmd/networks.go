@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"net"
+	"os"
+	"strings"
 
-	"github.com/robversluis/portainer-cli/internal/client"
-	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/notify"
 	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
 	"github.com/spf13/cobra"
 )
 
@@ -29,41 +32,66 @@ var networksListCmd = &cobra.Command{
 			return fmt.Errorf("--endpoint flag is required")
 		}
 
-		profile, err := config.GetProfileFromViper()
+		listOpts, err := getListOptions(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to get profile: %w", err)
+			return err
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to create client: %w", err)
+			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		networkService := client.NewNetworkService(c)
-		networks, err := networkService.List(endpointID)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		networkService := portainer.NewNetworkService(c)
+		format := getOutputFormat()
 
-		switch format {
-		case output.FormatJSON, output.FormatYAML:
-			formatter := output.NewFormatter(output.Options{Format: format})
-			return formatter.Format(networks)
+		var values notify.Values
+		listFunc := func() error {
+			networks, err := networkService.List(endpointID)
+			if err != nil {
+				return err
+			}
 
-		default:
-			table := output.NewTableData([]string{"ID", "Name", "Driver", "Scope"})
-			for _, network := range networks {
-				table.AddRow([]string{
-					network.GetShortID(),
-					network.Name,
-					network.Driver,
-					network.Scope,
+			values = notify.Values{"count": float64(len(networks))}
+
+			if GetQuiet() {
+				return printQuiet(listOpts, networks, func(item portainer.Network) string {
+					return item.GetShortID()
 				})
 			}
-			return output.PrintTable(*table)
+
+			switch format {
+			case output.FormatJSON, output.FormatYAML:
+				formatter := newFormatter(format)
+				items, err := listOpts.applyItems(networks)
+				if err != nil {
+					return err
+				}
+				return formatter.Format(items)
+
+			default:
+				table := output.NewTableData([]string{"ID", "Name", "Driver", "Scope"})
+				for _, network := range networks {
+					table.AddRow([]string{
+						network.GetShortID(),
+						network.Name,
+						network.Driver,
+						network.Scope,
+					})
+				}
+				if err := listOpts.applyTable(table, networks); err != nil {
+					return err
+				}
+				return output.PrintTable(*table)
+			}
 		}
+
+		return RunWithWatch(cmd, "networks", listFunc, func() notify.Values { return values })
 	},
 }
 
@@ -83,27 +111,27 @@ var networksInspectCmd = &cobra.Command{
 
 		networkID := args[0]
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		networkService := client.NewNetworkService(c)
+		networkService := portainer.NewNetworkService(c)
 		network, err := networkService.Inspect(endpointID, networkID)
 		if err != nil {
 			return err
 		}
 
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		format := getOutputFormat()
 
 		switch format {
 		case output.FormatJSON, output.FormatYAML:
-			formatter := output.NewFormatter(output.Options{Format: format})
+			formatter := newFormatter(format)
 			return formatter.Format(network)
 
 		default:
@@ -150,8 +178,16 @@ var networksInspectCmd = &cobra.Command{
 var networksCreateCmd = &cobra.Command{
 	Use:   "create [name]",
 	Short: "Create a network",
-	Long:  `Create a new Docker network.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Create a new Docker network.
+
+Subnets, gateways, IP ranges and auxiliary addresses configure the network's
+IPAM. Each gateway, IP range and auxiliary address is assigned to the subnet
+that contains it, so several subnets can be given at once.
+
+Example:
+  portainer-cli networks create mynet --subnet 172.20.0.0/16 --gateway 172.20.0.1 \
+    --ip-range 172.20.10.0/24 --label team=ops --endpoint 1`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		endpointID, err := cmd.Flags().GetInt("endpoint")
 		if err != nil {
@@ -174,25 +210,74 @@ var networksCreateCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		enableIPv6, err := cmd.Flags().GetBool("ipv6")
+		if err != nil {
+			return err
+		}
+		subnets, err := cmd.Flags().GetStringArray("subnet")
+		if err != nil {
+			return err
+		}
+		gateways, err := cmd.Flags().GetStringArray("gateway")
+		if err != nil {
+			return err
+		}
+		ipRanges, err := cmd.Flags().GetStringArray("ip-range")
+		if err != nil {
+			return err
+		}
+		auxAddresses, err := cmd.Flags().GetStringArray("aux-address")
+		if err != nil {
+			return err
+		}
+		optArgs, err := cmd.Flags().GetStringArray("opt")
+		if err != nil {
+			return err
+		}
+		labelArgs, err := cmd.Flags().GetStringArray("label")
+		if err != nil {
+			return err
+		}
 
-		profile, err := config.GetProfileFromViper()
+		ipam, err := buildNetworkIPAM(subnets, gateways, ipRanges, auxAddresses)
+		if err != nil {
+			return err
+		}
+		options, err := parseDriverOptions(optArgs)
+		if err != nil {
+			return err
+		}
+		labels, err := parseLabels(labelArgs)
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		req := &client.NetworkCreateRequest{
+		req := &portainer.NetworkCreateRequest{
 			Name:       networkName,
 			Driver:     driver,
 			Internal:   internal,
 			Attachable: attachable,
+			EnableIPv6: enableIPv6,
+			IPAM:       ipam,
+		}
+		if len(options) > 0 {
+			req.Options = options
+		}
+		if len(labels) > 0 {
+			req.Labels = labels
 		}
 
-		networkService := client.NewNetworkService(c)
+		networkService := portainer.NewNetworkService(c)
 		response, err := networkService.Create(endpointID, req)
 		if err != nil {
 			return err
@@ -201,7 +286,7 @@ var networksCreateCmd = &cobra.Command{
 		if !GetQuiet() {
 			fmt.Printf("Network '%s' created successfully (ID: %s)\n", networkName, response.Id[:12])
 			if response.Warning != "" {
-				fmt.Printf("Warning: %s\n", response.Warning)
+				fmt.Fprintln(os.Stderr, output.Warning(response.Warning))
 			}
 		}
 
@@ -209,6 +294,105 @@ var networksCreateCmd = &cobra.Command{
 	},
 }
 
+// buildNetworkIPAM builds the IPAM configuration of a new network. Like the
+// Docker CLI, each gateway, IP range and auxiliary address (HOST=IP) is
+// matched to the subnet containing it. It returns nil when no subnet is given.
+func buildNetworkIPAM(subnets, gateways, ipRanges, auxAddresses []string) (*portainer.IPAM, error) {
+	if len(subnets) == 0 {
+		if len(gateways) > 0 || len(ipRanges) > 0 || len(auxAddresses) > 0 {
+			return nil, fmt.Errorf("--gateway, --ip-range and --aux-address require --subnet")
+		}
+		return nil, nil
+	}
+
+	nets := make([]*net.IPNet, len(subnets))
+	configs := make([]portainer.IPAMConfig, len(subnets))
+	for i, subnet := range subnets {
+		_, ipNet, err := net.ParseCIDR(subnet)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subnet %q: %w", subnet, err)
+		}
+		for j := 0; j < i; j++ {
+			if nets[j].Contains(ipNet.IP) || ipNet.Contains(nets[j].IP) {
+				return nil, fmt.Errorf("subnets %s and %s overlap", subnets[j], subnet)
+			}
+		}
+		nets[i] = ipNet
+		configs[i].Subnet = subnet
+	}
+
+	// subnetFor returns the index of the subnet containing ip
+	subnetFor := func(ip net.IP, arg string) (int, error) {
+		for i, ipNet := range nets {
+			if ipNet.Contains(ip) {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("%s is not in any of the given subnets", arg)
+	}
+
+	for _, gateway := range gateways {
+		ip := net.ParseIP(gateway)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid gateway %q", gateway)
+		}
+		i, err := subnetFor(ip, "gateway "+gateway)
+		if err != nil {
+			return nil, err
+		}
+		if configs[i].Gateway != "" {
+			return nil, fmt.Errorf("subnet %s has more than one gateway", subnets[i])
+		}
+		configs[i].Gateway = gateway
+	}
+
+	for _, ipRange := range ipRanges {
+		ip, _, err := net.ParseCIDR(ipRange)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP range %q: %w", ipRange, err)
+		}
+		i, err := subnetFor(ip, "IP range "+ipRange)
+		if err != nil {
+			return nil, err
+		}
+		if configs[i].IPRange != "" {
+			return nil, fmt.Errorf("subnet %s has more than one IP range", subnets[i])
+		}
+		configs[i].IPRange = ipRange
+	}
+
+	for _, arg := range auxAddresses {
+		host, addr, ok := strings.Cut(arg, "=")
+		ip := net.ParseIP(addr)
+		if !ok || host == "" || ip == nil {
+			return nil, fmt.Errorf("invalid auxiliary address %q, expected HOST=IP", arg)
+		}
+		i, err := subnetFor(ip, "auxiliary address "+arg)
+		if err != nil {
+			return nil, err
+		}
+		if configs[i].AuxAddress == nil {
+			configs[i].AuxAddress = make(map[string]string)
+		}
+		configs[i].AuxAddress[host] = addr
+	}
+
+	return &portainer.IPAM{Driver: "default", Config: configs}, nil
+}
+
+// parseDriverOptions parses KEY=VALUE driver options
+func parseDriverOptions(args []string) (map[string]string, error) {
+	options := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid driver option %q, expected KEY=VALUE", arg)
+		}
+		options[key] = value
+	}
+	return options, nil
+}
+
 var networksRemoveCmd = &cobra.Command{
 	Use:     "remove [network]",
 	Aliases: []string{"rm"},
@@ -226,17 +410,17 @@ var networksRemoveCmd = &cobra.Command{
 
 		networkID := args[0]
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		networkService := client.NewNetworkService(c)
+		networkService := portainer.NewNetworkService(c)
 		if err := networkService.Remove(endpointID, networkID); err != nil {
 			return err
 		}
@@ -249,10 +433,15 @@ var networksRemoveCmd = &cobra.Command{
 	},
 }
 
-var networksPruneCmd = &cobra.Command{
-	Use:   "prune",
-	Short: "Remove unused networks",
-	Long:  `Remove all unused networks.`,
+var networksConnectCmd = &cobra.Command{
+	Use:   "connect [network] [container]",
+	Short: "Connect a container to a network",
+	Long: `Attach a container to a network, optionally with extra DNS aliases and a
+static IP address from the network's subnet.
+
+Example:
+  portainer-cli networks connect mynet web --alias api --ip 172.20.0.10 --endpoint 1`,
+	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		endpointID, err := cmd.Flags().GetInt("endpoint")
 		if err != nil {
@@ -262,29 +451,143 @@ var networksPruneCmd = &cobra.Command{
 			return fmt.Errorf("--endpoint flag is required")
 		}
 
-		profile, err := config.GetProfileFromViper()
+		aliases, err := cmd.Flags().GetStringArray("alias")
+		if err != nil {
+			return err
+		}
+		ipv4, err := cmd.Flags().GetString("ip")
+		if err != nil {
+			return err
+		}
+		ipv6, err := cmd.Flags().GetString("ip6")
+		if err != nil {
+			return err
+		}
+		if ipv4 != "" {
+			if ip := net.ParseIP(ipv4); ip == nil || ip.To4() == nil {
+				return fmt.Errorf("invalid IPv4 address: %s", ipv4)
+			}
+		}
+		if ipv6 != "" {
+			if ip := net.ParseIP(ipv6); ip == nil || ip.To4() != nil {
+				return fmt.Errorf("invalid IPv6 address: %s", ipv6)
+			}
+		}
+
+		networkID, containerID := args[0], args[1]
+
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		networkService := client.NewNetworkService(c)
-		if err := networkService.Prune(endpointID); err != nil {
+		networkService := portainer.NewNetworkService(c)
+		if err := networkService.Connect(endpointID, networkID, containerID, portainer.NetworkConnectOptions{
+			Aliases:     aliases,
+			IPv4Address: ipv4,
+			IPv6Address: ipv6,
+		}); err != nil {
 			return err
 		}
 
 		if !GetQuiet() {
-			fmt.Println("Networks pruned successfully")
+			fmt.Printf("Container '%s' connected to network '%s'\n", containerID, networkID)
 		}
 
 		return nil
 	},
 }
 
+var networksDisconnectCmd = &cobra.Command{
+	Use:   "disconnect [network] [container]",
+	Short: "Disconnect a container from a network",
+	Long:  `Detach a container from a network.`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			return err
+		}
+
+		networkID, containerID := args[0], args[1]
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		networkService := portainer.NewNetworkService(c)
+		if err := networkService.Disconnect(endpointID, networkID, containerID, force); err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Container '%s' disconnected from network '%s'\n", containerID, networkID)
+		}
+
+		return nil
+	},
+}
+
+var networksPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove unused networks",
+	Long:  `Remove all unused networks.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		networkService := portainer.NewNetworkService(c)
+		emitProgress(progressEvent{Event: "pruning", Endpoint: endpointID, Resource: "networks"})
+		report, err := networkService.Prune(endpointID)
+		if err != nil {
+			emitPruned(endpointID, "networks", 0, 0, err)
+			return err
+		}
+		emitPruned(endpointID, "networks", len(report.NetworksDeleted), -1, nil)
+
+		// Docker does not report space for networks
+		return printPruneReport(report, pruneResult{
+			kind:      "networks",
+			deleted:   report.NetworksDeleted,
+			reclaimed: -1,
+		})
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(networksCmd)
 	networksCmd.AddCommand(networksListCmd)
@@ -292,8 +595,12 @@ func init() {
 	networksCmd.AddCommand(networksCreateCmd)
 	networksCmd.AddCommand(networksRemoveCmd)
 	networksCmd.AddCommand(networksPruneCmd)
+	networksCmd.AddCommand(networksConnectCmd)
+	networksCmd.AddCommand(networksDisconnectCmd)
 
 	networksListCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	AddWatchFlags(networksListCmd)
+	addListFlags(networksListCmd)
 	_ = networksListCmd.MarkFlagRequired("endpoint")
 
 	networksInspectCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
@@ -303,6 +610,13 @@ func init() {
 	networksCreateCmd.Flags().String("driver", "bridge", "Network driver")
 	networksCreateCmd.Flags().Bool("internal", false, "Restrict external access to the network")
 	networksCreateCmd.Flags().Bool("attachable", false, "Enable manual container attachment")
+	networksCreateCmd.Flags().Bool("ipv6", false, "Enable IPv6 networking")
+	networksCreateCmd.Flags().StringArray("subnet", nil, "Subnet in CIDR format (repeatable)")
+	networksCreateCmd.Flags().StringArray("gateway", nil, "Gateway for a subnet (repeatable)")
+	networksCreateCmd.Flags().StringArray("ip-range", nil, "Allocate container IPs from a sub-range of a subnet (repeatable)")
+	networksCreateCmd.Flags().StringArray("aux-address", nil, "Auxiliary address reserved in a subnet (HOST=IP, repeatable)")
+	networksCreateCmd.Flags().StringArray("opt", nil, "Driver specific option (KEY=VALUE, repeatable)")
+	networksCreateCmd.Flags().StringArray("label", nil, "Network label (KEY=VALUE, repeatable)")
 	_ = networksCreateCmd.MarkFlagRequired("endpoint")
 
 	networksRemoveCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
@@ -310,4 +624,14 @@ func init() {
 
 	networksPruneCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
 	_ = networksPruneCmd.MarkFlagRequired("endpoint")
+
+	networksConnectCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	networksConnectCmd.Flags().StringArray("alias", nil, "Add a network-scoped DNS alias for the container (repeatable)")
+	networksConnectCmd.Flags().String("ip", "", "Static IPv4 address for the container")
+	networksConnectCmd.Flags().String("ip6", "", "Static IPv6 address for the container")
+	_ = networksConnectCmd.MarkFlagRequired("endpoint")
+
+	networksDisconnectCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	networksDisconnectCmd.Flags().BoolP("force", "f", false, "Force the container to disconnect")
+	_ = networksDisconnectCmd.MarkFlagRequired("endpoint")
 }
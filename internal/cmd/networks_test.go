@@ -0,0 +1,46 @@
+package cmd
+
+import "testing"
+
+func TestBuildNetworkIPAM(t *testing.T) {
+	ipam, err := buildNetworkIPAM(nil, nil, nil, nil)
+	if err != nil || ipam != nil {
+		t.Fatalf("expected no IPAM without subnets, got %v, %v", ipam, err)
+	}
+
+	ipam, err = buildNetworkIPAM(
+		[]string{"172.20.0.0/16", "fd00:20::/64"},
+		[]string{"fd00:20::1", "172.20.0.1"},
+		[]string{"172.20.10.0/24"},
+		[]string{"router=172.20.0.2"},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ipam.Config) != 2 {
+		t.Fatalf("expected 2 IPAM configs, got %d", len(ipam.Config))
+	}
+	v4, v6 := ipam.Config[0], ipam.Config[1]
+	if v4.Gateway != "172.20.0.1" || v4.IPRange != "172.20.10.0/24" || v4.AuxAddress["router"] != "172.20.0.2" {
+		t.Errorf("unexpected IPv4 config: %+v", v4)
+	}
+	if v6.Subnet != "fd00:20::/64" || v6.Gateway != "fd00:20::1" || v6.IPRange != "" {
+		t.Errorf("unexpected IPv6 config: %+v", v6)
+	}
+
+	invalid := []struct {
+		subnets, gateways, ipRanges, aux []string
+	}{
+		{gateways: []string{"172.20.0.1"}},
+		{subnets: []string{"172.20.0.0"}},
+		{subnets: []string{"172.20.0.0/16", "172.20.5.0/24"}},
+		{subnets: []string{"172.20.0.0/16"}, gateways: []string{"10.0.0.1"}},
+		{subnets: []string{"172.20.0.0/16"}, gateways: []string{"172.20.0.1", "172.20.0.254"}},
+		{subnets: []string{"172.20.0.0/16"}, aux: []string{"router"}},
+	}
+	for _, tc := range invalid {
+		if _, err := buildNetworkIPAM(tc.subnets, tc.gateways, tc.ipRanges, tc.aux); err == nil {
+			t.Errorf("expected error for %+v", tc)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+)
+
+// outputFile is the global --output-file flag
+var outputFile string
+
+// pendingOutput is the temporary file that stands in for stdout while a
+// command runs with --output-file
+type pendingOutput struct {
+	tmp    *os.File
+	path   string
+	stdout *os.File
+}
+
+var activeOutput *pendingOutput
+
+// redirectOutput sends stdout to a temporary file next to --output-file,
+// which finishOutputFile moves into place. Readers of the file never see
+// partial output, and a failed command leaves an existing file untouched.
+func redirectOutput() error {
+	if outputFile == "" || activeOutput != nil {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(outputFile), "."+filepath.Base(outputFile)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	// A replaced file keeps its permissions; new files are readable by
+	// others as with shell redirection
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(outputFile); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to set output file permissions: %w", err)
+	}
+
+	activeOutput = &pendingOutput{tmp: tmp, path: outputFile, stdout: os.Stdout}
+	os.Stdout = tmp
+	output.SetColorEnabled(false)
+	return nil
+}
+
+// finishOutputFile restores stdout and, when the command succeeded,
+// replaces --output-file with the written output
+func finishOutputFile(runErr error) error {
+	pending := activeOutput
+	if pending == nil {
+		return nil
+	}
+	activeOutput = nil
+	os.Stdout = pending.stdout
+
+	if runErr != nil {
+		pending.tmp.Close()
+		os.Remove(pending.tmp.Name())
+		return nil
+	}
+
+	if err := pending.tmp.Sync(); err != nil {
+		pending.tmp.Close()
+		os.Remove(pending.tmp.Name())
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := pending.tmp.Close(); err != nil {
+		os.Remove(pending.tmp.Name())
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := os.Rename(pending.tmp.Name(), pending.path); err != nil {
+		os.Remove(pending.tmp.Name())
+		return fmt.Errorf("failed to replace output file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.json")
+	if err := os.WriteFile(path, []byte("old\n"), 0640); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	outputFile = path
+	defer func() { outputFile = "" }()
+	stdout := os.Stdout
+
+	// A failed command keeps the previous file
+	if err := redirectOutput(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fmt.Println("partial")
+	if err := finishOutputFile(errors.New("request failed")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if os.Stdout != stdout {
+		t.Fatal("expected stdout to be restored")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old\n" {
+		t.Errorf("expected the previous file to be kept, got %q", data)
+	}
+
+	if err := redirectOutput(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fmt.Println(`{"ok": true}`)
+	if err := finishOutputFile(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "{\"ok\": true}\n" {
+		t.Errorf("unexpected output %q", data)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat file: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0640 {
+		t.Errorf("expected the permissions to be kept, got %v", info.Mode().Perm())
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary files to be left, got %d entries", len(entries))
+	}
+}
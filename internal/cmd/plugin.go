@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/internal/plugin"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Manage plugins",
+	Long: `Plugins are executables named portainer-cli-<name> on your PATH. They are run
+as 'portainer-cli <name> [args...]' and receive the connection settings of
+the selected profile through environment variables:
+
+  PORTAINER_URL, PORTAINER_API_KEY, PORTAINER_TOKEN, PORTAINER_INSECURE
+  PORTAINER_PROFILE   name of the selected profile
+  PORTAINER_OUTPUT    requested output format (table, json, yaml)
+  PORTAINER_CLI       path of the portainer-cli executable
+
+Built-in commands take precedence over plugins with the same name. For the
+plugins of the Docker engine, see 'portainer-cli plugins'.`,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List installed plugins",
+	Long:    `List the plugin executables found on PATH.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plugins := plugin.Find(os.Getenv("PATH"))
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			formatter := newFormatter(format)
+			return formatter.Format(plugins)
+		}
+
+		if len(plugins) == 0 {
+			fmt.Println("No plugins found")
+			return nil
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Name", "Path", "Status"})
+		table.SetBorder(false)
+		table.SetColumnSeparator("")
+		table.SetHeaderLine(false)
+
+		for _, p := range plugins {
+			status := "ok"
+			if p.Shadowed {
+				status = "shadowed by an earlier plugin on PATH"
+			} else if isBuiltinCommand(p.Name) {
+				status = "shadowed by built-in command"
+			}
+			table.Append([]string{p.Name, p.Path, status})
+		}
+
+		table.Render()
+		return nil
+	},
+}
+
+// isBuiltinCommand reports whether name is a command or alias of the CLI
+func isBuiltinCommand(name string) bool {
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return name == "help"
+}
+
+// splitPluginArgs finds the first positional argument, skipping the global
+// flags before it. It returns the flags, the plugin name and the arguments
+// for the plugin, or ok=false when the arguments contain an unknown flag
+// before the first positional argument.
+func splitPluginArgs(args []string) (flags []string, name string, rest []string, ok bool) {
+	persistent := rootCmd.PersistentFlags()
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || arg == "-" {
+			return nil, "", nil, false
+		}
+		if !strings.HasPrefix(arg, "-") {
+			return args[:i], arg, args[i+1:], true
+		}
+
+		var flag *pflag.Flag
+		var hasValue bool
+		if strings.HasPrefix(arg, "--") {
+			var flagName string
+			flagName, _, hasValue = strings.Cut(arg[2:], "=")
+			flag = persistent.Lookup(flagName)
+		} else {
+			// -o json, -ojson or -o=json
+			flag = persistent.ShorthandLookup(arg[1:2])
+			hasValue = len(arg) > 2
+		}
+		if flag == nil {
+			return nil, "", nil, false
+		}
+		if !hasValue && flag.NoOptDefVal == "" {
+			// The flag takes its value from the next argument
+			i++
+		}
+	}
+
+	return nil, "", nil, false
+}
+
+// runPlugin runs a plugin when args invoke one. It reports whether a plugin
+// handled the invocation.
+func runPlugin(args []string) (bool, error) {
+	flags, name, rest, ok := splitPluginArgs(args)
+	if !ok || isBuiltinCommand(name) {
+		return false, nil
+	}
+
+	path, found := plugin.Lookup(os.Getenv("PATH"), name)
+	if !found {
+		return false, nil
+	}
+
+	if err := rootCmd.ParseFlags(flags); err != nil {
+		return true, err
+	}
+	initConfig()
+
+	child := exec.Command(path, rest...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+	child.Env = append(os.Environ(), pluginEnv()...)
+
+	if err := child.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// The plugin reports its own errors
+			os.Exit(exitErr.ExitCode())
+		}
+		return true, fmt.Errorf("failed to run plugin %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// pluginEnv returns the environment variables passed to plugins. A profile
+// that cannot be resolved leaves the connection settings unset, so plugins
+// that do not talk to Portainer still run.
+func pluginEnv() []string {
+	env := []string{"PORTAINER_OUTPUT=" + string(getOutputFormat())}
+
+	if executable, err := os.Executable(); err == nil {
+		env = append(env, "PORTAINER_CLI="+executable)
+	}
+
+	profile, err := ResolveProfile(rootCmd)
+	if err != nil {
+		if GetVerbose() {
+			fmt.Fprintln(os.Stderr, output.Warning("no profile passed to plugin: "+err.Error()))
+		}
+		return env
+	}
+
+	env = append(env,
+		"PORTAINER_URL="+profile.URL,
+		"PORTAINER_API_KEY="+profile.APIKey,
+		"PORTAINER_TOKEN="+profile.Token,
+		"PORTAINER_INSECURE="+strconv.FormatBool(profile.Insecure),
+	)
+	if profile.Name != "" {
+		env = append(env, "PORTAINER_PROFILE="+profile.Name)
+	}
+	return env
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitPluginArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		flags  []string
+		plugin string
+		rest   []string
+		ok     bool
+	}{
+		{
+			name:   "plugin only",
+			args:   []string{"backup", "--all"},
+			flags:  []string{},
+			plugin: "backup",
+			rest:   []string{"--all"},
+			ok:     true,
+		},
+		{
+			name:   "global flags with separate values",
+			args:   []string{"--profile", "prod", "-o", "json", "backup", "now"},
+			flags:  []string{"--profile", "prod", "-o", "json"},
+			plugin: "backup",
+			rest:   []string{"now"},
+			ok:     true,
+		},
+		{
+			name:   "inline values and bool flags",
+			args:   []string{"--url=https://p.example.com", "-ojson", "-v", "backup"},
+			flags:  []string{"--url=https://p.example.com", "-ojson", "-v"},
+			plugin: "backup",
+			rest:   []string{},
+			ok:     true,
+		},
+		{
+			name: "unknown flag",
+			args: []string{"--nope", "backup"},
+		},
+		{
+			name: "no positional argument",
+			args: []string{"--verbose"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, plugin, rest, ok := splitPluginArgs(tt.args)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if !reflect.DeepEqual(flags, tt.flags) || plugin != tt.plugin || !reflect.DeepEqual(rest, tt.rest) {
+				t.Errorf("got flags=%v plugin=%q rest=%v", flags, plugin, rest)
+			}
+		})
+	}
+}
+
+func TestIsBuiltinCommand(t *testing.T) {
+	for _, name := range []string{"containers", "help", "ctx"} {
+		if !isBuiltinCommand(name) {
+			t.Errorf("%s should be a built-in command", name)
+		}
+	}
+	if isBuiltinCommand("backup") {
+		t.Error("backup should not be a built-in command")
+	}
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "Manage Docker engine plugins",
+	Long: `List, inspect, enable, and disable the plugins of the Docker engine of an
+environment, such as volume and network drivers.
+
+A stack whose volumes use a driver that is not installed, or not enabled,
+fails to deploy; list the volume drivers to find out:
+
+  portainer-cli plugins list --endpoint 1 --capability volumedriver
+
+For the executables extending portainer-cli itself, see 'portainer-cli plugin'.`,
+}
+
+var pluginsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List engine plugins",
+	Long:    `Display the plugins installed in the Docker engine of an environment.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+		capability, err := cmd.Flags().GetString("capability")
+		if err != nil {
+			return err
+		}
+
+		filters := map[string][]string{}
+		if capability != "" {
+			filters["capability"] = []string{capability}
+		}
+		if cmd.Flags().Changed("enabled") {
+			enabled, err := cmd.Flags().GetBool("enabled")
+			if err != nil {
+				return err
+			}
+			filters["enable"] = []string{strconv.FormatBool(enabled)}
+		}
+
+		pluginService, err := newPluginService(cmd)
+		if err != nil {
+			return err
+		}
+
+		plugins, err := pluginService.List(endpointID, filters)
+		if err != nil {
+			return err
+		}
+
+		if GetQuiet() {
+			for _, plugin := range plugins {
+				fmt.Println(plugin.GetShortID())
+			}
+			return nil
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			formatter := newFormatter(format)
+			return formatter.Format(plugins)
+
+		default:
+			table := output.NewTableData([]string{"ID", "Name", "Description", "Capabilities", "Enabled"})
+			for _, plugin := range plugins {
+				table.AddRow([]string{
+					plugin.GetShortID(),
+					plugin.Name,
+					plugin.Config.Description,
+					strings.Join(plugin.Capabilities(), ", "),
+					strconv.FormatBool(plugin.Enabled),
+				})
+			}
+			return output.PrintTable(*table)
+		}
+	},
+}
+
+var pluginsInspectCmd = &cobra.Command{
+	Use:   "inspect [plugin]",
+	Short: "Inspect an engine plugin",
+	Long:  `Display detailed information about a plugin, given by name (e.g. vieux/sshfs:latest) or ID.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		pluginService, err := newPluginService(cmd)
+		if err != nil {
+			return err
+		}
+
+		plugin, err := pluginService.Inspect(endpointID, args[0])
+		if err != nil {
+			return err
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			formatter := newFormatter(format)
+			return formatter.Format(plugin)
+
+		default:
+			fmt.Printf("ID:           %s\n", plugin.Id)
+			fmt.Printf("Name:         %s\n", plugin.Name)
+			fmt.Printf("Enabled:      %t\n", plugin.Enabled)
+			fmt.Printf("Description:  %s\n", plugin.Config.Description)
+			if plugin.PluginReference != "" {
+				fmt.Printf("Reference:    %s\n", plugin.PluginReference)
+			}
+			if len(plugin.Config.Interface.Types) > 0 {
+				fmt.Printf("\nCapabilities:\n")
+				for _, t := range plugin.Config.Interface.Types {
+					fmt.Printf("  %s.%s/%s\n", t.Prefix, t.Capability, t.Version)
+				}
+			}
+			if len(plugin.Settings.Env) > 0 {
+				fmt.Printf("\nSettings:\n")
+				for _, env := range plugin.Settings.Env {
+					fmt.Printf("  %s\n", env)
+				}
+			}
+			return nil
+		}
+	},
+}
+
+var pluginsEnableCmd = &cobra.Command{
+	Use:   "enable [plugin]",
+	Short: "Enable an engine plugin",
+	Long:  `Enable a plugin, so its drivers can be used by volumes, networks and stacks.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return err
+		}
+		if timeout < 0 {
+			return fmt.Errorf("--timeout cannot be negative")
+		}
+
+		pluginService, err := newPluginService(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := pluginService.Enable(endpointID, args[0], timeout); err != nil {
+			return err
+		}
+
+		if !GetQuiet() && !GetDryRun() {
+			fmt.Printf("Plugin '%s' enabled successfully\n", args[0])
+		}
+		return nil
+	},
+}
+
+var pluginsDisableCmd = &cobra.Command{
+	Use:   "disable [plugin]",
+	Short: "Disable an engine plugin",
+	Long: `Disable a plugin. The engine refuses to disable a plugin that is in use, such
+as the driver of existing volumes, unless --force is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			return err
+		}
+
+		pluginService, err := newPluginService(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := pluginService.Disable(endpointID, args[0], force); err != nil {
+			return err
+		}
+
+		if !GetQuiet() && !GetDryRun() {
+			fmt.Printf("Plugin '%s' disabled successfully\n", args[0])
+		}
+		return nil
+	},
+}
+
+func newPluginService(cmd *cobra.Command) (*portainer.PluginService, error) {
+	profile, err := ResolveProfile(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return portainer.NewPluginService(c), nil
+}
+
+func init() {
+	rootCmd.AddCommand(pluginsCmd)
+	pluginsCmd.AddCommand(pluginsListCmd)
+	pluginsCmd.AddCommand(pluginsInspectCmd)
+	pluginsCmd.AddCommand(pluginsEnableCmd)
+	pluginsCmd.AddCommand(pluginsDisableCmd)
+
+	for _, c := range []*cobra.Command{pluginsListCmd, pluginsInspectCmd, pluginsEnableCmd, pluginsDisableCmd} {
+		c.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+		_ = c.MarkFlagRequired("endpoint")
+	}
+	pluginsListCmd.Flags().String("capability", "", "Only list plugins with this capability (e.g. volumedriver, networkdriver)")
+	pluginsListCmd.Flags().Bool("enabled", false, "Only list enabled plugins (--enabled=false lists disabled plugins)")
+	pluginsEnableCmd.Flags().Duration("timeout", 0, "Time to wait for the plugin to start, e.g. 30s (0 for the engine default)")
+	pluginsDisableCmd.Flags().Bool("force", false, "Disable the plugin even if it is in use")
+}
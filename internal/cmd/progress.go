@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+// progressEvent reports the progress of a long operation, such as a pull,
+// prune or stack deploy. With -o json events are written to stderr as one
+// JSON object per line, so wrappers can show progress while stdout only
+// carries the result.
+type progressEvent struct {
+	Event    string `json:"event"`
+	Endpoint int    `json:"endpoint,omitempty"`
+
+	// Image pulls and pushes
+	Image   string `json:"image,omitempty"`
+	Layer   string `json:"layer,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Current int64  `json:"current,omitempty"`
+	Total   int64  `json:"total,omitempty"`
+	Digest  string `json:"digest,omitempty"`
+
+	// Prunes
+	Resource  string `json:"resource,omitempty"`
+	Deleted   *int   `json:"deleted,omitempty"`
+	Reclaimed *int64 `json:"reclaimed,omitempty"`
+
+	// Stack deploys
+	Stack   string `json:"stack,omitempty"`
+	StackID int    `json:"stack_id,omitempty"`
+	// Action is "created" or "updated" for a deployed stack
+	Action string `json:"action,omitempty"`
+
+	Error string    `json:"error,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+var (
+	// progressOut receives the progress events
+	progressOut io.Writer = os.Stderr
+	progressMu  sync.Mutex
+)
+
+// progressEnabled reports whether progress events are written: with -o json,
+// outside dry-run mode
+func progressEnabled() bool {
+	return output.ParseFormat(outputFormat) == output.FormatJSON && !GetDryRun()
+}
+
+// emitProgress writes a progress event when enabled. It is safe for
+// concurrent use by operations covering several environments.
+func emitProgress(event progressEvent) {
+	if !progressEnabled() {
+		return
+	}
+	event.Time = time.Now().UTC()
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	_, _ = progressOut.Write(append(data, '\n'))
+}
+
+// pullProgressEvent converts a progress message of the engine into a
+// "pulling" event. Messages about the image as a whole, such as the digest,
+// have no layer.
+func pullProgressEvent(endpointID int, image string, progress portainer.PullProgress) progressEvent {
+	event := progressEvent{
+		Event:    "pulling",
+		Endpoint: endpointID,
+		Image:    image,
+		Status:   progress.Status,
+		Current:  progress.ProgressDetail.Current,
+		Total:    progress.ProgressDetail.Total,
+	}
+	if !strings.HasPrefix(progress.Status, "Pulling from") {
+		event.Layer = progress.ID
+	}
+	return event
+}
+
+// pushProgressEvent converts a push progress message of the engine
+func pushProgressEvent(endpointID int, image string, progress portainer.PushProgress) progressEvent {
+	event := progressEvent{
+		Event:    "pushing",
+		Endpoint: endpointID,
+		Image:    image,
+		Layer:    progress.ID,
+		Status:   progress.Status,
+		Current:  progress.ProgressDetail.Current,
+		Total:    progress.ProgressDetail.Total,
+	}
+	if progress.Aux != nil {
+		event.Digest = progress.Aux.Digest
+	}
+	return event
+}
+
+// emitPruned reports the result of pruning a resource in an environment
+func emitPruned(endpointID int, resource string, deleted int, reclaimed int64, err error) {
+	event := progressEvent{Event: "pruned", Endpoint: endpointID, Resource: resource}
+	if err != nil {
+		event.Event = "prune_failed"
+		event.Error = err.Error()
+	} else {
+		event.Deleted = &deleted
+		if reclaimed >= 0 {
+			event.Reclaimed = &reclaimed
+		}
+	}
+	emitProgress(event)
+}
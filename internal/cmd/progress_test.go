@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestEmitProgress(t *testing.T) {
+	savedOut, savedFormat := progressOut, outputFormat
+	defer func() { progressOut, outputFormat = savedOut, savedFormat }()
+	var buf bytes.Buffer
+	progressOut = &buf
+
+	outputFormat = "table"
+	emitProgress(progressEvent{Event: "pruning", Endpoint: 1, Resource: "images"})
+	if buf.Len() != 0 {
+		t.Fatalf("expected no events in table mode, got %q", buf.String())
+	}
+
+	outputFormat = "json"
+	var progress portainer.PullProgress
+	progress.Status, progress.ID = "Downloading", "a2abf6c4d29d"
+	progress.ProgressDetail.Current, progress.ProgressDetail.Total = 512, 1024
+	emitProgress(pullProgressEvent(1, "nginx:latest", progress))
+	emitPruned(1, "images", 0, 2048, nil)
+	emitPruned(2, "volumes", 0, 0, errors.New("environment is down"))
+
+	var events []map[string]interface{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("expected one JSON event per line, got %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	if e := events[0]; e["event"] != "pulling" || e["layer"] != "a2abf6c4d29d" || e["current"] != 512.0 || e["total"] != 1024.0 {
+		t.Errorf("unexpected pull event: %v", e)
+	}
+	if e := events[1]; e["event"] != "pruned" || e["deleted"] != 0.0 || e["reclaimed"] != 2048.0 {
+		t.Errorf("unexpected prune event: %v", e)
+	}
+	if e := events[2]; e["event"] != "prune_failed" || e["error"] != "environment is down" || e["deleted"] != nil {
+		t.Errorf("unexpected failure event: %v", e)
+	}
+}
+
+func TestPushProgressEvent(t *testing.T) {
+	var progress portainer.PushProgress
+	progress.Status, progress.ID = "Pushed", "a2abf6c4d29d"
+	event := pushProgressEvent(1, "registry.example.com/app:1.4", progress)
+	if event.Event != "pushing" || event.Layer != "a2abf6c4d29d" || event.Status != "Pushed" || event.Digest != "" {
+		t.Errorf("unexpected push event: %+v", event)
+	}
+
+	progress = portainer.PushProgress{Aux: &portainer.PushResult{Tag: "1.4", Digest: "sha256:abc"}}
+	if event := pushProgressEvent(1, "registry.example.com/app:1.4", progress); event.Digest != "sha256:abc" {
+		t.Errorf("expected digest sha256:abc, got %+v", event)
+	}
+}
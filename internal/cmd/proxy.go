@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Serve the Docker API of an environment locally",
+	Long: `Run a local proxy that forwards Docker API calls to an environment through
+Portainer, adding the credentials of the selected profile. Point the docker
+CLI, docker compose, or any tool speaking the Docker API at the proxy to
+manage the environment as if its engine were local:
+
+  portainer-cli proxy --endpoint 3 --listen 127.0.0.1:2375
+  DOCKER_HOST=tcp://127.0.0.1:2375 docker ps
+
+--listen takes a TCP address or unix:///path for a socket only your user can
+access. Anyone who can connect to the proxy acts with your credentials, so a
+warning is printed for addresses that are not loopback. The proxy runs until
+interrupted.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+		listen, err := cmd.Flags().GetString("listen")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		handler, err := c.DockerProxy(endpointID)
+		if err != nil {
+			return err
+		}
+		if GetVerbose() {
+			handler = logProxyRequests(handler)
+		}
+
+		listener, dockerHost, err := listenProxy(listen)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+		serverErr := make(chan error, 1)
+		go func() {
+			serverErr <- server.Serve(listener)
+		}()
+
+		if !GetQuiet() {
+			fmt.Fprintf(os.Stderr, "Proxying the Docker API of environment %d on %s (Press Ctrl+C to exit)\n", endpointID, dockerHost)
+			fmt.Fprintf(os.Stderr, "  export DOCKER_HOST=%s\n", dockerHost)
+		}
+
+		select {
+		case err := <-serverErr:
+			return fmt.Errorf("proxy failed: %w", err)
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, context.DeadlineExceeded) {
+				return err
+			}
+			return nil
+		}
+	},
+}
+
+// listenProxy listens on a TCP address, or on a unix socket given as
+// unix:///path, and returns the DOCKER_HOST value of the listener
+func listenProxy(listen string) (net.Listener, string, error) {
+	if path, ok := strings.CutPrefix(listen, "unix://"); ok {
+		if path == "" {
+			return nil, "", fmt.Errorf("invalid --listen: missing socket path")
+		}
+		// A socket left behind by a previous run blocks the address
+		if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(path)
+		}
+		listener, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to listen on %s: %w", listen, err)
+		}
+		if err := os.Chmod(path, 0600); err != nil {
+			listener.Close()
+			return nil, "", fmt.Errorf("failed to restrict access to %s: %w", path, err)
+		}
+		return listener, listen, nil
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to listen on %s: %w", listen, err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	if !addr.IP.IsLoopback() {
+		fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("the proxy on %s is reachable from other hosts, which can use it with your credentials", listen)))
+	}
+	return listener, "tcp://" + addr.String(), nil
+}
+
+// logProxyRequests writes a line per proxied request to stderr
+func logProxyRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		fmt.Fprintf(os.Stderr, "%s %s (%s)\n", r.Method, r.URL.RequestURI(), time.Since(start).Round(time.Millisecond))
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(proxyCmd)
+
+	proxyCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	proxyCmd.Flags().String("listen", "127.0.0.1:2375", "Address to serve the Docker API on, or unix:///path for a socket")
+	_ = proxyCmd.MarkFlagRequired("endpoint")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListenProxyTCP(t *testing.T) {
+	listener, dockerHost, err := listenProxy("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+
+	if dockerHost != "tcp://"+listener.Addr().String() {
+		t.Errorf("expected docker host of the listener, got %s", dockerHost)
+	}
+}
+
+func TestListenProxyUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docker.sock")
+
+	listener, dockerHost, err := listenProxy("unix://" + path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+
+	if dockerHost != "unix://"+path {
+		t.Errorf("expected unix://%s, got %s", path, dockerHost)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("expected socket mode 0600, got %o", perm)
+	}
+}
+
+func TestListenProxyMissingSocketPath(t *testing.T) {
+	_, _, err := listenProxy("unix://")
+	if err == nil || !strings.Contains(err.Error(), "missing socket path") {
+		t.Errorf("expected missing socket path error, got %v", err)
+	}
+}
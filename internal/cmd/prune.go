@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+// pruneResult is what a prune removed, as shown in table output
+type pruneResult struct {
+	// kind names the pruned resources, e.g. "containers"
+	kind string
+	// deleted lists the removed items, one per line
+	deleted []string
+	// reclaimed is the freed disk space, or -1 when the API does not report it
+	reclaimed int64
+}
+
+// printPruneReport prints the API report for json/yaml output. Otherwise it
+// lists the removed items and the reclaimed space, or with --quiet only the
+// removed items, one per line. Nothing is printed in dry-run mode.
+func printPruneReport(report interface{}, result pruneResult) error {
+	if GetDryRun() {
+		return nil
+	}
+
+	format := getOutputFormat()
+	switch format {
+	case output.FormatJSON, output.FormatYAML:
+		return newFormatter(format).Format(report)
+	}
+
+	if GetQuiet() {
+		for _, item := range result.deleted {
+			fmt.Println(item)
+		}
+		return nil
+	}
+
+	if len(result.deleted) == 0 {
+		fmt.Printf("No %s to prune\n", result.kind)
+	} else {
+		fmt.Printf("Deleted %s:\n", result.kind)
+		for _, item := range result.deleted {
+			fmt.Printf("  %s\n", item)
+		}
+	}
+
+	if result.reclaimed >= 0 {
+		fmt.Printf("\nTotal reclaimed space: %s\n", output.FormatSize(result.reclaimed))
+	}
+	return nil
+}
+
+// imagePruneItems formats the removed tags and images as the Docker CLI
+// does, e.g. "untagged: nginx:1.24" and "deleted: sha256:..."
+func imagePruneItems(items []portainer.ImageDeleteItem) []string {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Untagged != "" {
+			lines = append(lines, "untagged: "+item.Untagged)
+		}
+		if item.Deleted != "" {
+			lines = append(lines, "deleted: "+item.Deleted)
+		}
+	}
+	return lines
+}
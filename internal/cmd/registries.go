@@ -1,11 +1,17 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
-	"github.com/robversluis/portainer-cli/internal/client"
-	"github.com/robversluis/portainer-cli/internal/config"
 	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
 	"github.com/spf13/cobra"
 )
 
@@ -21,28 +27,43 @@ var registriesListCmd = &cobra.Command{
 	Short:   "List registries",
 	Long:    `Display a list of all configured container registries.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		profile, err := config.GetProfileFromViper()
+		listOpts, err := getListOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		registryService := client.NewRegistryService(c)
+		registryService := portainer.NewRegistryService(c)
 		registries, err := registryService.List()
 		if err != nil {
 			return err
 		}
 
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		format := getOutputFormat()
+
+		if GetQuiet() {
+			return printQuiet(listOpts, registries, func(item portainer.Registry) string {
+				return strconv.Itoa(item.Id)
+			})
+		}
 
 		switch format {
 		case output.FormatJSON, output.FormatYAML:
-			formatter := output.NewFormatter(output.Options{Format: format})
-			return formatter.Format(registries)
+			formatter := newFormatter(format)
+			items, err := listOpts.applyItems(registries)
+			if err != nil {
+				return err
+			}
+			return formatter.Format(items)
 
 		default:
 			table := output.NewTableData([]string{"ID", "Name", "Type", "URL", "Auth"})
@@ -55,6 +76,9 @@ var registriesListCmd = &cobra.Command{
 					output.FormatBool(registry.Authentication),
 				})
 			}
+			if err := listOpts.applyTable(table, registries); err != nil {
+				return err
+			}
 			return output.PrintTable(*table)
 		}
 	},
@@ -71,27 +95,27 @@ var registriesGetCmd = &cobra.Command{
 			return fmt.Errorf("invalid registry ID: %s", args[0])
 		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		registryService := client.NewRegistryService(c)
+		registryService := portainer.NewRegistryService(c)
 		registry, err := registryService.Get(registryID)
 		if err != nil {
 			return err
 		}
 
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		format := getOutputFormat()
 
 		switch format {
 		case output.FormatJSON, output.FormatYAML:
-			formatter := output.NewFormatter(output.Options{Format: format})
+			formatter := newFormatter(format)
 			return formatter.Format(registry)
 
 		default:
@@ -122,17 +146,17 @@ var registriesDeleteCmd = &cobra.Command{
 			return fmt.Errorf("invalid registry ID: %s", args[0])
 		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		registryService := client.NewRegistryService(c)
+		registryService := portainer.NewRegistryService(c)
 		if err := registryService.Delete(registryID); err != nil {
 			return err
 		}
@@ -145,9 +169,343 @@ var registriesDeleteCmd = &cobra.Command{
 	},
 }
 
+var registriesTestCmd = &cobra.Command{
+	Use:   "test [id]",
+	Short: "Test registry credentials",
+	Long:  `Check that Portainer can authenticate against a registry with its stored credentials.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var registryID int
+		if _, err := fmt.Sscanf(args[0], "%d", &registryID); err != nil {
+			return fmt.Errorf("invalid registry ID: %s", args[0])
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		registryService := portainer.NewRegistryService(c)
+		if err := registryService.Test(registryID); err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Registry %d credentials are valid\n", registryID)
+		}
+
+		return nil
+	},
+}
+
+var registriesBrowseCmd = &cobra.Command{
+	Use:   "browse [id] [repository]",
+	Short: "Browse registry repositories and tags",
+	Long: `List the repositories of a registry, or the tags of a repository when one
+is given.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var registryID int
+		if _, err := fmt.Sscanf(args[0], "%d", &registryID); err != nil {
+			return fmt.Errorf("invalid registry ID: %s", args[0])
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		registryService := portainer.NewRegistryService(c)
+
+		header := "Repository"
+		var names []string
+		if len(args) == 2 {
+			header = "Tag"
+			names, err = registryService.Tags(registryID, args[1])
+		} else {
+			names, err = registryService.Catalog(registryID)
+		}
+		if err != nil {
+			return err
+		}
+		sort.Strings(names)
+
+		if GetQuiet() {
+			for _, name := range names {
+				fmt.Println(name)
+			}
+			return nil
+		}
+
+		format := getOutputFormat()
+
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			formatter := newFormatter(format)
+			return formatter.Format(names)
+
+		default:
+			table := output.NewTableData([]string{header})
+			for _, name := range names {
+				table.AddRow([]string{name})
+			}
+			return output.PrintTable(*table)
+		}
+	},
+}
+
+var registriesCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a registry",
+	Long: `Add a container registry to Portainer.
+
+For --type ecr, --username and --password are an AWS access key ID and secret
+access key; Portainer uses them to obtain registry tokens itself. The region
+is taken from --region or the registry URL. For example:
+  portainer-cli registries create --name prod-ecr --type ecr --region eu-west-1 \
+    --url 123456789012.dkr.ecr.eu-west-1.amazonaws.com --username AKIA... --password ...`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, err := cmd.Flags().GetString("name")
+		if err != nil {
+			return err
+		}
+		registryType, err := cmd.Flags().GetString("type")
+		if err != nil {
+			return err
+		}
+		registryURL, err := cmd.Flags().GetString("url")
+		if err != nil {
+			return err
+		}
+		username, err := cmd.Flags().GetString("username")
+		if err != nil {
+			return err
+		}
+		password, err := cmd.Flags().GetString("password")
+		if err != nil {
+			return err
+		}
+		region, err := cmd.Flags().GetString("region")
+		if err != nil {
+			return err
+		}
+
+		if (username == "") != (password == "") {
+			return fmt.Errorf("--username and --password must be used together")
+		}
+
+		registry := &portainer.Registry{
+			Name:           name,
+			URL:            registryURL,
+			Authentication: username != "",
+			Username:       username,
+			Password:       password,
+		}
+
+		switch strings.ToLower(registryType) {
+		case "custom":
+			registry.Type = portainer.RegistryTypeCustom
+		case "dockerhub":
+			registry.Type = portainer.RegistryTypeDockerHub
+			if registry.URL == "" {
+				registry.URL = "docker.io"
+			}
+		case "quay":
+			registry.Type = portainer.RegistryTypeQuay
+			if registry.URL == "" {
+				registry.URL = "quay.io"
+			}
+		case "azure":
+			registry.Type = portainer.RegistryTypeAzure
+		case "ecr":
+			registry.Type = portainer.RegistryTypeECR
+			if region == "" {
+				region, _ = ecrRegion(registryURL)
+			}
+			if region == "" {
+				return fmt.Errorf("--region is required for ECR registries")
+			}
+			registry.Ecr = &portainer.EcrRegistryData{Region: region}
+		default:
+			return fmt.Errorf("invalid registry type: %s (expected custom, dockerhub, quay, azure, or ecr)", registryType)
+		}
+
+		if registry.URL == "" {
+			return fmt.Errorf("--url flag is required")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		registryService := portainer.NewRegistryService(c)
+		created, err := registryService.Create(registry)
+		if err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Registry %s created successfully (ID: %d)\n", name, created.Id)
+		}
+
+		return nil
+	},
+}
+
+var registriesRefreshECRCmd = &cobra.Command{
+	Use:   "refresh-ecr [id]",
+	Short: "Refresh the ECR token of a registry",
+	Long: `Obtain a fresh Amazon ECR token with the local AWS credentials and store it
+in a registry that authenticates with ECR tokens (user "AWS").
+
+The token is fetched with "aws ecr get-login-password", so the AWS CLI must be
+installed and configured. ECR tokens are valid for 12 hours; run this command
+from cron or a CI schedule to keep the registry usable.
+
+Registries created with --type ecr hold AWS access keys and are refreshed by
+Portainer itself, so they do not need this command.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var registryID int
+		if _, err := fmt.Sscanf(args[0], "%d", &registryID); err != nil {
+			return fmt.Errorf("invalid registry ID: %s", args[0])
+		}
+
+		region, err := cmd.Flags().GetString("region")
+		if err != nil {
+			return err
+		}
+		awsProfile, err := cmd.Flags().GetString("aws-profile")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		registryService := portainer.NewRegistryService(c)
+		registry, err := registryService.Get(registryID)
+		if err != nil {
+			return err
+		}
+
+		if registry.Type == portainer.RegistryTypeECR && !GetDryRun() {
+			return fmt.Errorf("registry %d uses Portainer's built-in ECR support, which refreshes tokens automatically", registryID)
+		}
+
+		if region == "" {
+			var ok bool
+			region, ok = ecrRegion(registry.URL)
+			if !ok && !GetDryRun() {
+				return fmt.Errorf("cannot determine the ECR region from %s; use --region", registry.URL)
+			}
+		}
+
+		token := "<ecr-token>"
+		if !GetDryRun() {
+			token, err = ecrLoginPassword(region, awsProfile)
+			if err != nil {
+				return err
+			}
+		}
+
+		registry.Authentication = true
+		registry.Username = "AWS"
+		registry.Password = token
+
+		if _, err := registryService.Update(registryID, registry); err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Registry %d ECR token refreshed\n", registryID)
+		}
+
+		return nil
+	},
+}
+
+var ecrHostPattern = regexp.MustCompile(`^(?:https?://)?\d{12}\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?(?:[/:]|$)`)
+
+// ecrRegion extracts the AWS region from an ECR registry URL such as
+// 123456789012.dkr.ecr.eu-west-1.amazonaws.com
+func ecrRegion(registryURL string) (string, bool) {
+	match := ecrHostPattern.FindStringSubmatch(registryURL)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// ecrLoginPassword obtains an ECR authorization token with the AWS CLI
+func ecrLoginPassword(region, awsProfile string) (string, error) {
+	args := []string{"ecr", "get-login-password", "--region", region}
+	if awsProfile != "" {
+		args = append(args, "--profile", awsProfile)
+	}
+
+	var stderr bytes.Buffer
+	awsCmd := exec.Command("aws", args...)
+	awsCmd.Stderr = &stderr
+
+	out, err := awsCmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("the AWS CLI is required to obtain ECR tokens: %w", err)
+		}
+		return "", fmt.Errorf("failed to obtain ECR token: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("failed to obtain ECR token: empty response from AWS CLI")
+	}
+	return token, nil
+}
+
 func init() {
 	rootCmd.AddCommand(registriesCmd)
 	registriesCmd.AddCommand(registriesListCmd)
 	registriesCmd.AddCommand(registriesGetCmd)
 	registriesCmd.AddCommand(registriesDeleteCmd)
+	registriesCmd.AddCommand(registriesTestCmd)
+	registriesCmd.AddCommand(registriesBrowseCmd)
+	registriesCmd.AddCommand(registriesCreateCmd)
+	registriesCmd.AddCommand(registriesRefreshECRCmd)
+
+	addListFlags(registriesListCmd)
+
+	registriesCreateCmd.Flags().String("name", "", "Registry name (required)")
+	registriesCreateCmd.Flags().String("type", "custom", "Registry type (custom, dockerhub, quay, azure, ecr)")
+	registriesCreateCmd.Flags().String("url", "", "Registry URL")
+	registriesCreateCmd.Flags().String("username", "", "Registry username (AWS access key ID for ecr)")
+	registriesCreateCmd.Flags().String("password", "", "Registry password (AWS secret access key for ecr)")
+	registriesCreateCmd.Flags().String("region", "", "AWS region for ecr registries")
+	_ = registriesCreateCmd.MarkFlagRequired("name")
+
+	registriesRefreshECRCmd.Flags().String("region", "", "AWS region (derived from the registry URL if omitted)")
+	registriesRefreshECRCmd.Flags().String("aws-profile", "", "AWS CLI profile to use")
 }
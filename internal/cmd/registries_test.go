@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestECRRegion(t *testing.T) {
+	tests := []struct {
+		url    string
+		region string
+		ok     bool
+	}{
+		{url: "123456789012.dkr.ecr.eu-west-1.amazonaws.com", region: "eu-west-1", ok: true},
+		{url: "https://123456789012.dkr.ecr.us-east-2.amazonaws.com/v2/", region: "us-east-2", ok: true},
+		{url: "123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn", region: "cn-north-1", ok: true},
+		{url: "registry.example.com", ok: false},
+		{url: "123.dkr.ecr.eu-west-1.amazonaws.com", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			region, ok := ecrRegion(tt.url)
+			if ok != tt.ok || region != tt.region {
+				t.Errorf("expected (%q, %v), got (%q, %v)", tt.region, tt.ok, region, ok)
+			}
+		})
+	}
+}
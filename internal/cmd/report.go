@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/internal/pool"
+	"github.com/robversluis/portainer-cli/internal/report"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate an inventory report",
+	Long: `Generate an inventory report of environments for ops reviews: engine
+versions, container counts, unhealthy containers, stale images and the
+stacks deployed on each environment.
+
+The report is written as Markdown by default. Use -o html for a standalone
+HTML page, -o json or -o yaml for structured data, or --template to render it
+with your own Go template (templates ending in .html are HTML-escaped).
+
+Images are stale when no container uses them and they were created more than
+--stale-days ago.
+
+Examples:
+  # Markdown report of all environments
+  portainer-cli report
+
+  # HTML report of two environments, written to a file
+  portainer-cli report --endpoints 1,production -o html --file weekly.html
+
+  # Scheduled weekly report (crontab)
+  0 7 * * 1  portainer-cli report -o html --file /srv/reports/$(date +\%F).html`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpoints, err := cmd.Flags().GetStringSlice("endpoints")
+		if err != nil {
+			return err
+		}
+		staleDays, err := cmd.Flags().GetInt("stale-days")
+		if err != nil {
+			return err
+		}
+		if staleDays < 0 {
+			return fmt.Errorf("--stale-days cannot be negative")
+		}
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			return err
+		}
+		title, err := cmd.Flags().GetString("title")
+		if err != nil {
+			return err
+		}
+		templateFile, err := cmd.Flags().GetString("template")
+		if err != nil {
+			return err
+		}
+
+		format, err := reportFormat()
+		if err != nil {
+			return err
+		}
+
+		var templateText string
+		if templateFile != "" {
+			data, err := os.ReadFile(templateFile)
+			if err != nil {
+				return fmt.Errorf("failed to read template: %w", err)
+			}
+			templateText = string(data)
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		all, err := portainer.NewEnvironmentService(c).List()
+		if err != nil {
+			return err
+		}
+		environments, err := selectReportEnvironments(all, endpoints)
+		if err != nil {
+			return err
+		}
+		if len(environments) == 0 {
+			return fmt.Errorf("no environments to report on")
+		}
+
+		stacks, err := portainer.NewStackService(c).List(0)
+		if err != nil {
+			return err
+		}
+
+		r := buildInventoryReport(c, environments, stacks, time.Duration(staleDays)*24*time.Hour, time.Now())
+		r.Title = title
+		r.URL = profile.URL
+		r.StaleAfter = fmt.Sprintf("%d days", staleDays)
+
+		for _, env := range r.Environments {
+			if env.Error != "" {
+				fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("environment %s: %s", env.Name, env.Error)))
+			}
+		}
+
+		var w io.Writer = os.Stdout
+		if file != "" {
+			f, err := os.Create(file)
+			if err != nil {
+				return fmt.Errorf("failed to create report file: %w", err)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		switch {
+		case templateText != "":
+			err = report.RenderTemplate(w, r, templateText, strings.EqualFold(filepath.Ext(templateFile), ".html"))
+		case format == output.FormatYAML:
+			err = output.NewFormatter(output.Options{Format: output.FormatYAML, Writer: w, Query: queryExpr}).Format(r)
+		case format == output.Format(report.FormatJSON) && queryExpr != "":
+			err = output.NewFormatter(output.Options{Format: output.FormatJSON, Writer: w, Query: queryExpr}).Format(r)
+		default:
+			err = report.Render(w, r, report.Format(format))
+		}
+		if err != nil {
+			return err
+		}
+
+		if file != "" && !GetQuiet() {
+			fmt.Fprintf(os.Stderr, "Report written to %s\n", file)
+		}
+		return nil
+	},
+}
+
+// reportFormat maps the global --output flag to a report format. The default
+// table output produces Markdown.
+func reportFormat() (output.Format, error) {
+	switch strings.ToLower(outputFormat) {
+	case "", "table":
+		return output.Format(report.FormatMarkdown), nil
+	case "yaml", "yml":
+		return output.FormatYAML, nil
+	}
+
+	format, err := report.ParseFormat(outputFormat)
+	if err != nil {
+		return "", fmt.Errorf("unsupported output format %q for report (supported: md, html, json, yaml)", outputFormat)
+	}
+	return output.Format(format), nil
+}
+
+// selectReportEnvironments returns the environments matching the --endpoints
+// values, which are environment IDs, names, or "all"
+func selectReportEnvironments(all []portainer.Environment, endpoints []string) ([]portainer.Environment, error) {
+	if len(endpoints) == 0 {
+		return all, nil
+	}
+	for _, endpoint := range endpoints {
+		if strings.EqualFold(endpoint, "all") {
+			return all, nil
+		}
+	}
+
+	selected := make([]portainer.Environment, 0, len(endpoints))
+	seen := map[int]bool{}
+	for _, endpoint := range endpoints {
+		index := -1
+		id, err := strconv.Atoi(endpoint)
+		for i := range all {
+			if (err == nil && all[i].Id == id) || all[i].Name == endpoint {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("environment %q not found", endpoint)
+		}
+		if !seen[all[index].Id] {
+			seen[all[index].Id] = true
+			selected = append(selected, all[index])
+		}
+	}
+	return selected, nil
+}
+
+// buildInventoryReport collects the inventory of the environments
+// concurrently. Counts come from the latest snapshot, and are replaced by
+// live data for Docker environments that are up.
+func buildInventoryReport(c *portainer.Client, environments []portainer.Environment, stacks []portainer.Stack, staleAfter time.Duration, now time.Time) *report.Report {
+	r := &report.Report{
+		GeneratedAt:  now,
+		Environments: make([]report.Environment, len(environments)),
+	}
+
+	pool.Run(GetParallel(), len(environments), func(i int) {
+		r.Environments[i] = inventoryEnvironment(c, environments[i], staleAfter, now)
+	})
+
+	names := make(map[int]string, len(environments))
+	for _, env := range environments {
+		names[env.Id] = env.Name
+	}
+
+	for i := range stacks {
+		stack := &stacks[i]
+		name, ok := names[stack.EndpointId]
+		if !ok {
+			continue
+		}
+		entry := report.Stack{
+			ID:          stack.Id,
+			Name:        stack.Name,
+			Environment: name,
+			Type:        stack.TypeString(),
+			Status:      stack.StatusString(),
+		}
+		if updated := max(stack.UpdateDate, stack.CreationDate); updated > 0 {
+			entry.Updated = time.Unix(updated, 0)
+		}
+		r.Stacks = append(r.Stacks, entry)
+	}
+	sort.Slice(r.Stacks, func(i, j int) bool {
+		if r.Stacks[i].Environment != r.Stacks[j].Environment {
+			return r.Stacks[i].Environment < r.Stacks[j].Environment
+		}
+		return r.Stacks[i].Name < r.Stacks[j].Name
+	})
+
+	r.Summarize()
+	return r
+}
+
+func inventoryEnvironment(c *portainer.Client, env portainer.Environment, staleAfter time.Duration, now time.Time) report.Environment {
+	entry := report.Environment{
+		ID:     env.Id,
+		Name:   env.Name,
+		Type:   env.TypeString(),
+		Status: env.StatusString(),
+	}
+
+	snapshot := env.GetLatestSnapshot()
+	if snapshot != nil {
+		entry.Containers = report.Containers{
+			Total:     snapshot.RunningContainerCount + snapshot.StoppedContainerCount,
+			Running:   snapshot.RunningContainerCount,
+			Stopped:   snapshot.StoppedContainerCount,
+			Healthy:   snapshot.HealthyContainerCount,
+			Unhealthy: snapshot.UnhealthyContainerCount,
+		}
+		if len(snapshot.KubernetesSnapshot) > 0 {
+			var kubernetes struct {
+				KubernetesVersion string `json:"KubernetesVersion"`
+			}
+			if json.Unmarshal(snapshot.KubernetesSnapshot, &kubernetes) == nil {
+				entry.Version = kubernetes.KubernetesVersion
+			}
+		}
+	}
+
+	if !env.IsDocker() || env.Status != portainer.EnvironmentStatusUp {
+		return entry
+	}
+
+	if version, err := portainer.NewHostService(c).Version(env.Id); err == nil {
+		entry.Version = version.Version
+	} else if GetVerbose() {
+		fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("environment %s: %v", env.Name, err)))
+	}
+
+	containers, err := portainer.NewContainerService(c).List(env.Id, true)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+	images, err := portainer.NewImageService(c).List(env.Id)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+
+	entry.Containers = report.Containers{}
+	entry.Unhealthy = nil
+	used := map[string]bool{}
+	for i := range containers {
+		container := &containers[i]
+		used[container.ImageID] = true
+
+		entry.Containers.Total++
+		if container.IsRunning() {
+			entry.Containers.Running++
+		} else {
+			entry.Containers.Stopped++
+		}
+
+		switch container.GetHealth() {
+		case portainer.HealthStatusHealthy:
+			entry.Containers.Healthy++
+		case portainer.HealthStatusUnhealthy:
+			entry.Containers.Unhealthy++
+			entry.Unhealthy = append(entry.Unhealthy, report.Container{
+				Name:   container.GetName(),
+				Image:  container.Image,
+				Status: container.Status,
+			})
+		}
+	}
+
+	for _, image := range images {
+		created := time.Unix(image.Created, 0)
+		if used[image.Id] || now.Sub(created) < staleAfter {
+			continue
+		}
+
+		repository, tag := "<none>", "<none>"
+		if len(image.RepoTags) > 0 {
+			repository, tag = splitImageRef(image.RepoTags[0])
+		}
+		entry.StaleImages = append(entry.StaleImages, report.Image{
+			Repository: repository,
+			Tag:        tag,
+			ID:         image.GetShortID(),
+			Size:       image.Size,
+			Created:    created,
+		})
+	}
+	sort.Slice(entry.StaleImages, func(i, j int) bool {
+		return entry.StaleImages[i].Created.Before(entry.StaleImages[j].Created)
+	})
+
+	return entry
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().StringSlice("endpoints", []string{"all"}, "Environment IDs or names to include, or 'all'")
+	reportCmd.Flags().Int("stale-days", 30, "Age in days after which unused images are reported as stale")
+	reportCmd.Flags().String("file", "", "Write the report to a file instead of stdout")
+	reportCmd.Flags().String("title", "Portainer inventory report", "Report title")
+	reportCmd.Flags().String("template", "", "Render the report with a custom Go template file")
+}
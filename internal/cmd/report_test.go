@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestSelectReportEnvironments(t *testing.T) {
+	all := []portainer.Environment{{Id: 1, Name: "local"}, {Id: 2, Name: "production"}, {Id: 3, Name: "staging"}}
+
+	tests := []struct {
+		name      string
+		endpoints []string
+		expected  []int
+		wantErr   bool
+	}{
+		{name: "default", endpoints: nil, expected: []int{1, 2, 3}},
+		{name: "all", endpoints: []string{"all"}, expected: []int{1, 2, 3}},
+		{name: "ids and names", endpoints: []string{"3", "production"}, expected: []int{3, 2}},
+		{name: "duplicates", endpoints: []string{"1", "local"}, expected: []int{1}},
+		{name: "unknown", endpoints: []string{"missing"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := selectReportEnvironments(all, tt.endpoints)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(selected) != len(tt.expected) {
+				t.Fatalf("expected %d environments, got %d", len(tt.expected), len(selected))
+			}
+			for i, id := range tt.expected {
+				if selected[i].Id != id {
+					t.Errorf("environment %d: expected ID %d, got %d", i, id, selected[i].Id)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildInventoryReport(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/endpoints/1/docker/version":
+			json.NewEncoder(w).Encode(portainer.DockerVersion{Version: "24.0.7"})
+		case "/api/endpoints/1/docker/containers/json":
+			json.NewEncoder(w).Encode([]portainer.Container{
+				{Id: "a", Names: []string{"/web"}, ImageID: "sha256:used", State: "running", Status: "Up 5 minutes (healthy)"},
+				{Id: "b", Names: []string{"/db"}, Image: "postgres:16", ImageID: "sha256:used", State: "running", Status: "Up 1 minute (unhealthy)"},
+				{Id: "c", Names: []string{"/job"}, ImageID: "sha256:used", State: "exited", Status: "Exited (0) 1 hour ago"},
+			})
+		case "/api/endpoints/1/docker/images/json":
+			json.NewEncoder(w).Encode([]portainer.Image{
+				{Id: "sha256:used", RepoTags: []string{"nginx:latest"}, Created: now.AddDate(-1, 0, 0).Unix()},
+				{Id: "sha256:old0000000000", RepoTags: []string{"redis:6"}, Created: now.AddDate(0, -2, 0).Unix(), Size: 1024},
+				{Id: "sha256:new0000000000", RepoTags: []string{"redis:7"}, Created: now.AddDate(0, 0, -1).Unix()},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := portainer.NewClient(&portainer.Config{URL: server.URL, APIKey: "test-key"}, portainer.WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	environments := []portainer.Environment{
+		{Id: 1, Name: "local", Type: portainer.EnvironmentTypeDockerLocal, Status: portainer.EnvironmentStatusUp},
+		{
+			Id: 2, Name: "edge", Type: portainer.EnvironmentTypeEdgeAgentOnDocker, Status: portainer.EnvironmentStatusDown,
+			Snapshots: []portainer.Snapshot{{Time: 1700000000, RunningContainerCount: 4, StoppedContainerCount: 1, UnhealthyContainerCount: 2}},
+		},
+	}
+	stacks := []portainer.Stack{
+		{Id: 7, Name: "web", Type: portainer.StackTypeCompose, EndpointId: 1, Status: portainer.StackStatusActive, CreationDate: now.Unix()},
+		{Id: 8, Name: "other", Type: portainer.StackTypeCompose, EndpointId: 9, Status: portainer.StackStatusActive},
+	}
+
+	r := buildInventoryReport(c, environments, stacks, 30*24*time.Hour, now)
+
+	local := r.Environments[0]
+	if local.Version != "24.0.7" {
+		t.Errorf("expected version 24.0.7, got %q", local.Version)
+	}
+	if local.Containers.Total != 3 || local.Containers.Running != 2 || local.Containers.Stopped != 1 ||
+		local.Containers.Healthy != 1 || local.Containers.Unhealthy != 1 {
+		t.Errorf("unexpected container counts: %+v", local.Containers)
+	}
+	if len(local.Unhealthy) != 1 || local.Unhealthy[0].Name != "db" || local.Unhealthy[0].Image != "postgres:16" {
+		t.Errorf("unexpected unhealthy containers: %+v", local.Unhealthy)
+	}
+	if len(local.StaleImages) != 1 || local.StaleImages[0].Repository != "redis" || local.StaleImages[0].Tag != "6" {
+		t.Errorf("unexpected stale images: %+v", local.StaleImages)
+	}
+
+	// Environments that are down are reported from their latest snapshot
+	edge := r.Environments[1]
+	if edge.Containers.Total != 5 || edge.Containers.Unhealthy != 2 || edge.Error != "" {
+		t.Errorf("unexpected snapshot counts: %+v", edge)
+	}
+
+	if len(r.Stacks) != 1 || r.Stacks[0].Name != "web" || r.Stacks[0].Environment != "local" {
+		t.Errorf("unexpected stacks: %+v", r.Stacks)
+	}
+
+	if r.Summary.Environments != 2 || r.Summary.EnvironmentsUp != 1 || r.Summary.Containers != 8 ||
+		r.Summary.Unhealthy != 3 || r.Summary.StaleImages != 1 || r.Summary.Stacks != 1 {
+		t.Errorf("unexpected summary: %+v", r.Summary)
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+// Global retry flags
+var (
+	retries     int
+	retryDelay  time.Duration
+	retryUnsafe bool
+
+	// Whether --retries and --retry-delay were given, recorded before the
+	// command runs
+	retriesSet    bool
+	retryDelaySet bool
+)
+
+// retryProfile is the profile last returned by ResolveProfile. Its retries
+// and retry_delay settings apply unless the flags are given.
+var retryProfile *config.Profile
+
+// retryOptions returns the client options of --retries, --retry-delay,
+// --retry-unsafe and --no-retry, falling back to the profile settings
+func retryOptions() []portainer.ClientOption {
+	var opts []portainer.ClientOption
+
+	switch {
+	case GetNoRetry():
+		opts = append(opts, portainer.WithMaxRetries(0))
+	case retriesSet:
+		opts = append(opts, portainer.WithMaxRetries(retries))
+	case retryProfile != nil && retryProfile.Retries != nil:
+		opts = append(opts, portainer.WithMaxRetries(*retryProfile.Retries))
+	}
+
+	switch {
+	case retryDelaySet:
+		opts = append(opts, portainer.WithRetryDelay(retryDelay))
+	case retryProfile != nil && retryProfile.RetryDelay != "":
+		delay, err := time.ParseDuration(retryProfile.RetryDelay)
+		if err != nil || delay < 0 {
+			fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("ignoring invalid retry_delay %q of the profile", retryProfile.RetryDelay)))
+			break
+		}
+		opts = append(opts, portainer.WithRetryDelay(delay))
+	}
+
+	if retryUnsafe {
+		opts = append(opts, portainer.WithRetryUnsafe(true))
+	}
+	return opts
+}
+
+// checkRetryFlags rejects negative retry settings, and records which retry
+// flags were given
+func checkRetryFlags(cmd *cobra.Command) error {
+	retriesSet = cmd.Flags().Changed("retries")
+	retryDelaySet = cmd.Flags().Changed("retry-delay")
+	if retries < 0 {
+		return fmt.Errorf("--retries must not be negative")
+	}
+	if retryDelay < 0 {
+		return fmt.Errorf("--retry-delay must not be negative")
+	}
+	return nil
+}
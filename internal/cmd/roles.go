@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var rolesCmd = &cobra.Command{
+	Use:   "roles",
+	Short: "List RBAC roles",
+	Long:  `List the role-based access control roles of Portainer Business Edition.`,
+}
+
+var rolesListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List roles",
+	Long: `Display the RBAC roles that can be assigned to users and teams in an
+environment. When a user has several roles in an environment, the role with
+the lowest priority value applies.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listOpts, err := getListOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		roles, err := portainer.NewRoleService(c).List()
+		if err != nil {
+			return err
+		}
+
+		if GetQuiet() {
+			return printQuiet(listOpts, roles, func(item portainer.Role) string {
+				return strconv.Itoa(item.Id)
+			})
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			items, err := listOpts.applyItems(roles)
+			if err != nil {
+				return err
+			}
+			return newFormatter(format).Format(items)
+
+		default:
+			table := output.NewTableData([]string{"ID", "Name", "Priority", "Description"})
+			for _, role := range roles {
+				table.AddRow([]string{
+					fmt.Sprintf("%d", role.Id),
+					role.Name,
+					fmt.Sprintf("%d", role.Priority),
+					output.TruncateString(role.Description, 60),
+				})
+			}
+			if err := listOpts.applyTable(table, roles); err != nil {
+				return err
+			}
+			return output.PrintTable(*table)
+		}
+	},
+}
+
+// resolveRole looks up a role by ID or name. A name may be abbreviated to
+// a unique prefix, such as "standard" for "Standard user".
+func resolveRole(c *portainer.Client, idOrName string) (*portainer.Role, error) {
+	roles, err := portainer.NewRoleService(c).List()
+	if err != nil {
+		return nil, err
+	}
+	return findRole(roles, idOrName)
+}
+
+func findRole(roles []portainer.Role, idOrName string) (*portainer.Role, error) {
+	if id, err := strconv.Atoi(idOrName); err == nil {
+		for i := range roles {
+			if roles[i].Id == id {
+				return &roles[i], nil
+			}
+		}
+		return nil, fmt.Errorf("role not found: %d", id)
+	}
+
+	var matches []*portainer.Role
+	for i := range roles {
+		if strings.EqualFold(roles[i].Name, idOrName) {
+			return &roles[i], nil
+		}
+		if strings.HasPrefix(strings.ToLower(roles[i].Name), strings.ToLower(idOrName)) {
+			matches = append(matches, &roles[i])
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("role not found: %s", idOrName)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("role %q is ambiguous", idOrName)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(rolesCmd)
+	rolesCmd.AddCommand(rolesListCmd)
+
+	addListFlags(rolesListCmd)
+}
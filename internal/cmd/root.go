@@ -3,8 +3,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
-	"github.com/robversluis/portainer-cli/internal/client"
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/internal/pool"
+	"github.com/robversluis/portainer-cli/internal/terminal"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -19,6 +24,12 @@ var (
 	quiet        bool
 	noRetry      bool
 	dryRun       bool
+	queryExpr    string
+	noColor      bool
+	endpointName string
+	recordDir    string
+	replayDir    string
+	parallel     int
 )
 
 var rootCmd = &cobra.Command{
@@ -30,26 +41,70 @@ orchestration workflows.
 
 Manage Docker, Kubernetes, and Edge environments from the terminal without 
 requiring the web UI.`,
-	SilenceUsage:  true,
-	SilenceErrors: true,
+	SilenceUsage:      true,
+	SilenceErrors:     true,
+	PersistentPreRunE: persistentPreRun,
+}
+
+// persistentPreRun runs before every command
+func persistentPreRun(cmd *cobra.Command, args []string) error {
+	if parallel < 0 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+	if err := checkRetryFlags(cmd); err != nil {
+		return err
+	}
+	if err := applyTarget(cmd); err != nil {
+		return err
+	}
+	if err := resolveEndpointName(cmd, args); err != nil {
+		return err
+	}
+	negotiateServerVersion(cmd)
+	return redirectOutput()
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	if handled, err := runPlugin(os.Args[1:]); handled {
+		return err
+	}
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+	if finishErr := finishOutputFile(err); err == nil {
+		err = finishErr
+	}
+	if showStats {
+		if statsErr := printStats(os.Stderr, cmd.CommandPath(), time.Since(start)); err == nil {
+			err = statsErr
+		}
+	}
+	return err
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.portainer-cli/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $XDG_CONFIG_HOME/portainer-cli/config.yaml or $HOME/.config/portainer-cli/config.yaml)")
 	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "profile/context to use")
 	rootCmd.PersistentFlags().StringVar(&url, "url", "", "Portainer URL (overrides config)")
 	rootCmd.PersistentFlags().StringVar(&apiKey, "api-key", "", "API key for authentication (overrides config)")
 	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "output format (table, json, yaml)")
+	rootCmd.PersistentFlags().StringVar(&outputFile, "output-file", "", "write the output to a file, replaced atomically when the command succeeds")
+	rootCmd.PersistentFlags().StringVar(&queryExpr, "query", "", "JMESPath-style query applied to the output (e.g. '[].Name')")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "quiet mode (minimal output)")
 	rootCmd.PersistentFlags().BoolVar(&noRetry, "no-retry", false, "disable retry on failed requests")
-	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "output curl command instead of executing request")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", 3, "number of retries of failed requests, unless set by retries in the profile")
+	rootCmd.PersistentFlags().DurationVar(&retryDelay, "retry-delay", 2*time.Second, "delay between retries, unless set by retry_delay in the profile")
+	rootCmd.PersistentFlags().BoolVar(&retryUnsafe, "retry-unsafe", false, "also retry requests that are not idempotent, such as stack deploys, which may then be applied twice")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print the requests that would be made as curl commands instead of sending them")
+	rootCmd.PersistentFlags().IntVar(&parallel, "parallel", 0, "maximum number of concurrent requests of commands covering several resources (default based on the CPU count)")
+	rootCmd.PersistentFlags().BoolVar(&showStats, "stats", false, "print the request count, retries, bytes transferred and wall time of the command to stderr")
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "save API responses to a cassette directory for later --replay")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "serve API responses from a cassette directory recorded with --record instead of contacting Portainer")
+	rootCmd.PersistentFlags().StringVar(&targetName, "target", "", "named target of the config file, selecting a profile and an environment (instead of --profile and --endpoint)")
+	rootCmd.PersistentFlags().StringVar(&endpointName, "endpoint-name", "", "environment name, resolved to the --endpoint ID of endpoint-scoped commands")
 
 	_ = viper.BindPFlag("url", rootCmd.PersistentFlags().Lookup("url"))
 	_ = viper.BindPFlag("api_key", rootCmd.PersistentFlags().Lookup("api-key"))
@@ -62,13 +117,23 @@ func initConfig() {
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
 	} else {
-		home, err := os.UserHomeDir()
+		configDir, err := config.GetConfigDir()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting home directory: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error getting config directory: %v\n", err)
 			os.Exit(1)
 		}
 
-		configDir := home + "/.portainer-cli"
+		migration, err := config.Migrate()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to migrate config file: %v\n", err)
+		} else if migration != nil && !quiet {
+			if migration.From != "" {
+				fmt.Fprintf(os.Stderr, "Moved config file from %s to %s (backup: %s)\n", migration.From, migration.To, migration.Backup)
+			} else {
+				fmt.Fprintf(os.Stderr, "Upgraded config file %s to version %d (backup: %s)\n", migration.To, config.CurrentVersion, migration.Backup)
+			}
+		}
+
 		viper.AddConfigPath(configDir)
 		viper.SetConfigType("yaml")
 		viper.SetConfigName("config")
@@ -83,24 +148,69 @@ func initConfig() {
 		}
 	}
 
-	if profile != "" {
-		viper.Set("current_profile", profile)
+	output.SetColorEnabled(useColor(os.Stdout))
+	output.SetStderrColorEnabled(useColor(os.Stderr))
+}
+
+// loadConfig loads the file given with --config, or the default config file
+func loadConfig() (*config.Config, error) {
+	if cfgFile != "" {
+		return config.LoadFile(cfgFile)
+	}
+	return config.Load()
+}
+
+// ResolveProfile returns the connection profile for cmd: the profile selected
+// with --profile or PORTAINER_PROFILE, else the current profile, with --url,
+// --api-key and the PORTAINER_* environment variables applied on top. Every
+// command that talks to Portainer gets its profile from here so overrides
+// behave the same everywhere.
+func ResolveProfile(cmd *cobra.Command) (*config.Profile, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
 	}
 
-	currentProfile := viper.GetString("current_profile")
-	if currentProfile != "" {
-		profileConfig := viper.Sub("profiles." + currentProfile)
-		if profileConfig != nil {
-			if url == "" {
-				url = profileConfig.GetString("url")
-				viper.Set("url", url)
-			}
-			if apiKey == "" {
-				apiKey = profileConfig.GetString("api_key")
-				viper.Set("api_key", apiKey)
-			}
-		}
+	overrides := config.ProfileOverrides{
+		Profile:  flagOrEnv(cmd, "profile", "profile"),
+		URL:      flagOrEnv(cmd, "url", "url"),
+		APIKey:   flagOrEnv(cmd, "api-key", "api_key"),
+		Username: viper.GetString("username"),
+		Token:    viper.GetString("token"),
+	}
+	if viper.IsSet("insecure") {
+		insecure := viper.GetBool("insecure")
+		overrides.Insecure = &insecure
 	}
+
+	resolved, err := cfg.Resolve(overrides)
+	if err != nil {
+		return nil, err
+	}
+	retryProfile = resolved
+	return resolved, nil
+}
+
+// flagOrEnv returns the value of flag when it was set on the command line,
+// falling back to the PORTAINER_ environment variable for key
+func flagOrEnv(cmd *cobra.Command, flag, key string) string {
+	if f := cmd.Flags().Lookup(flag); f != nil && f.Changed {
+		return f.Value.String()
+	}
+	return viper.GetString(key)
+}
+
+// useColor reports whether output should be colored: not disabled via
+// --no-color or NO_COLOR (https://no-color.org), and stdout is a terminal.
+// useColor reports whether output to f is colored
+func useColor(f *os.File) bool {
+	if noColor {
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return terminal.IsTerminal(f)
 }
 
 func GetVerbose() bool {
@@ -119,12 +229,36 @@ func GetDryRun() bool {
 	return dryRun
 }
 
-func GetClientOptions() []client.ClientOption {
-	var opts []client.ClientOption
-	opts = append(opts, client.WithVerbose(GetVerbose()))
-	opts = append(opts, client.WithDryRun(GetDryRun()))
-	if GetNoRetry() {
-		opts = append(opts, client.WithMaxRetries(0))
+// GetParallel returns the worker pool size set with --parallel, or the
+// default of the pool package
+func GetParallel() int {
+	if parallel > 0 {
+		return parallel
+	}
+	return pool.DefaultSize()
+}
+
+func GetClientOptions() []portainer.ClientOption {
+	var opts []portainer.ClientOption
+	opts = append(opts, portainer.WithVerbose(GetVerbose()))
+	opts = append(opts, portainer.WithDryRun(GetDryRun()))
+	opts = append(opts, portainer.WithLogOutput(os.Stdout))
+	opts = append(opts, retryOptions()...)
+	opts = append(opts, portainer.WithDeprecationHandler(reportDeprecation))
+	if recordDir != "" {
+		opts = append(opts, portainer.WithRecord(recordDir))
+	}
+	if replayDir != "" {
+		opts = append(opts, portainer.WithReplay(replayDir))
+	}
+	if serverVersion != "" {
+		opts = append(opts, portainer.WithServerVersion(serverVersion))
+	}
+	if showStats {
+		opts = append(opts, portainer.WithStats(commandStats))
+	}
+	if opt := connectionServerOption(); opt != nil {
+		opts = append(opts, opt)
 	}
 	return opts
 }
@@ -186,6 +320,30 @@ func GetOutput() string {
 	return outputFormat
 }
 
+func GetQuery() string {
+	return queryExpr
+}
+
+// getOutputFormat returns the requested output format. A query always
+// produces structured output, so table output falls back to JSON.
+func getOutputFormat() output.Format {
+	format := output.ParseFormat(outputFormat)
+	if queryExpr != "" && format == output.FormatTable {
+		return output.FormatJSON
+	}
+	return format
+}
+
+// newFormatter creates a formatter that applies the global --query flag.
+// Without an explicit -o, scalar query results are printed one per line.
+func newFormatter(format output.Format) output.Formatter {
+	return output.NewFormatter(output.Options{
+		Format: format,
+		Query:  queryExpr,
+		Raw:    queryExpr != "" && output.ParseFormat(outputFormat) == output.FormatTable,
+	})
+}
+
 func GetURL() string {
 	if url != "" {
 		return url
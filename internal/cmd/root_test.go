@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func TestRootCommand(t *testing.T) {
@@ -76,3 +79,35 @@ func TestGetters(t *testing.T) {
 		t.Errorf("Expected API key to be 'test-key', got '%s'", GetAPIKey())
 	}
 }
+
+// executeCommand runs the CLI with args against the Portainer server at url,
+// resetting the flags of every command afterwards
+func executeCommand(t *testing.T, url string, args ...string) error {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	defer resetFlags(rootCmd)
+	rootCmd.SetArgs(append(args, "--url", url, "--api-key", "test-key"))
+	defer rootCmd.SetArgs(nil)
+	_, err := rootCmd.ExecuteC()
+	return err
+}
+
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if f.Changed {
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				_ = sv.Replace(nil)
+			} else {
+				_ = f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		}
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, child := range cmd.Commands() {
+		resetFlags(child)
+	}
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/internal/runbook"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// runbookLocalFlags are global flags that shape the output of the run
+// command itself, and are not passed on to its steps
+var runbookLocalFlags = map[string]bool{
+	"output":      true,
+	"output-file": true,
+	"query":       true,
+	"stats":       true,
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run a sequence of operations from a file",
+	Long: `Run the operations of a runbook file in order, and report the outcome of
+each. The file is YAML or JSON; each step is a portainer-cli command line
+given as 'run', or as a list of arguments given as 'args':
+
+  on_failure: abort          # default policy of the steps
+  steps:
+    - name: Stop web
+      run: containers stop web --endpoint 1
+    - name: Deploy web
+      run: stacks deploy --name web --file docker-compose.yml --endpoint 1
+    - name: Prune images
+      args: [images, prune, --endpoint, "1", --force]
+      on_failure: continue   # run the next steps even if this one fails
+
+A failed step with the abort policy skips the remaining steps. Steps run in
+the directory of the runbook file, so relative paths resolve next to it, and
+inherit the global flags given to run, such as --profile, --target and
+--dry-run. The report is printed after the last step; with --output json or
+yaml it is the only output on stdout, and the output of the steps goes to
+stderr. The command fails when a step failed.
+
+Examples:
+  portainer-cli run --file deploy.yaml
+  portainer-cli run --file deploy.yaml --target prod-web -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filePath, err := cmd.Flags().GetString("file")
+		if err != nil {
+			return err
+		}
+
+		book, err := runbook.Load(filePath)
+		if err != nil {
+			return err
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the portainer-cli executable: %w", err)
+		}
+
+		format := getOutputFormat()
+		var stepOut io.Writer = os.Stdout
+		if format == output.FormatJSON || format == output.FormatYAML {
+			stepOut = os.Stderr
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		runner := &stepRunner{
+			executable: executable,
+			dir:        filepath.Dir(filePath),
+			flags:      inheritedStepFlags(cmd),
+			stdout:     stepOut,
+			stderr:     os.Stderr,
+			announce:   !GetQuiet() && format != output.FormatJSON && format != output.FormatYAML,
+			steps:      book.Steps,
+		}
+		report := book.Run(ctx, runner.run)
+
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			if err := newFormatter(format).Format(report); err != nil {
+				return err
+			}
+		default:
+			if !GetQuiet() {
+				if err := printRunReport(report); err != nil {
+					return err
+				}
+			}
+		}
+		return report.Err()
+	},
+}
+
+// stepRunner runs the steps of a runbook as portainer-cli processes
+type stepRunner struct {
+	executable string
+	dir        string
+	flags      []string
+	stdout     io.Writer
+	stderr     io.Writer
+	announce   bool
+	steps      []runbook.Step
+}
+
+func (r *stepRunner) run(ctx context.Context, i int, args []string) error {
+	if r.announce {
+		fmt.Fprintf(r.stdout, "==> Step %s: %s\n", r.steps[i].Label(i), strings.Join(args, " "))
+	}
+
+	child := exec.CommandContext(ctx, r.executable, append(append([]string{}, r.flags...), args...)...)
+	child.Dir = r.dir
+	child.Stdout = r.stdout
+	child.Stderr = r.stderr
+
+	if err := child.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("exit status %d", exitErr.ExitCode())
+		}
+		return err
+	}
+	return nil
+}
+
+// inheritedStepFlags returns the global flags given to the run command, to
+// be passed on to its steps
+func inheritedStepFlags(cmd *cobra.Command) []string {
+	var flags []string
+	cmd.InheritedFlags().VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed || runbookLocalFlags[flag.Name] {
+			return
+		}
+		if flag.Name == "profile" && cmd.Flags().Changed("target") {
+			// Set from the target, which the steps apply themselves
+			return
+		}
+		if flag.Name == "config" {
+			// Steps run in the directory of the runbook
+			if abs, err := filepath.Abs(flag.Value.String()); err == nil {
+				flags = append(flags, "--config="+abs)
+				return
+			}
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			for _, value := range slice.GetSlice() {
+				flags = append(flags, "--"+flag.Name+"="+value)
+			}
+			return
+		}
+		flags = append(flags, "--"+flag.Name+"="+flag.Value.String())
+	})
+	return flags
+}
+
+// printRunReport prints the outcome of each step and a summary
+func printRunReport(report *runbook.Report) error {
+	fmt.Println()
+	table := output.NewTableData([]string{"Step", "Name", "Status", "Duration", "Error"})
+	for _, step := range report.Steps {
+		name := step.Name
+		if name == "" {
+			name = strings.Join(step.Args, " ")
+		}
+		table.AddRow([]string{fmt.Sprint(step.Step), name, step.Status, step.Duration, step.Error})
+	}
+	if err := output.PrintTable(*table); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d succeeded, %d failed, %d skipped in %s\n", report.Succeeded, report.Failed, report.Skipped, report.Duration)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().StringP("file", "f", "", "Runbook file (YAML or JSON) (required)")
+	_ = runCmd.MarkFlagRequired("file")
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+// serverMode is the --server flag of the root command
+var serverMode bool
+
+// serverSocketPath returns the socket of the connection server: in
+// XDG_RUNTIME_DIR when set, which is private to the user and cleared on
+// logout, else in the config directory
+func serverSocketPath() (string, error) {
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		return filepath.Join(runtimeDir, "portainer-cli.sock"), nil
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "server.sock"), nil
+}
+
+// connectionServerOption routes the requests of commands through a running
+// connection server; clients connect directly when none is running
+func connectionServerOption() portainer.ClientOption {
+	if serverMode {
+		return nil
+	}
+	socket, err := serverSocketPath()
+	if err != nil {
+		return nil
+	}
+	if _, err := os.Stat(socket); err != nil {
+		return nil
+	}
+	return portainer.WithConnectionServer(socket)
+}
+
+// runConnectionServer serves portainer.NewConnectionServer on the socket
+// until interrupted, or until no request arrived for idleTimeout
+func runConnectionServer(idleTimeout time.Duration) error {
+	socket, err := serverSocketPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(socket), 0o700); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	if conn, err := net.Dial("unix", socket); err == nil {
+		conn.Close()
+		return fmt.Errorf("a connection server is already running on %s", socket)
+	}
+	// A socket left behind by a server that did not shut down cleanly
+	_ = os.Remove(socket)
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socket, err)
+	}
+	defer os.Remove(socket)
+	// Requests carry the credentials of the profile, so only the user may
+	// connect
+	if err := os.Chmod(socket, 0o600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+
+	var lastRequest atomic.Int64
+	lastRequest.Store(time.Now().UnixNano())
+	handler := portainer.NewConnectionServer()
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lastRequest.Store(time.Now().UnixNano())
+			if GetVerbose() {
+				fmt.Fprintf(os.Stderr, "%s %s\n", r.Method, r.URL.Path)
+			}
+			handler.ServeHTTP(w, r)
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Serve(listener)
+	}()
+
+	if !GetQuiet() {
+		fmt.Fprintf(os.Stderr, "Connection server listening on %s\n", socket)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var idle <-chan time.Time
+	if idleTimeout > 0 {
+		ticker := time.NewTicker(min(idleTimeout, time.Minute))
+		defer ticker.Stop()
+		idle = ticker.C
+	}
+
+	for {
+		select {
+		case err := <-serverErr:
+			return fmt.Errorf("connection server failed: %w", err)
+		case <-idle:
+			if time.Since(time.Unix(0, lastRequest.Load())) < idleTimeout {
+				continue
+			}
+			if !GetQuiet() {
+				fmt.Fprintf(os.Stderr, "Connection server idle for %s, exiting\n", idleTimeout)
+			}
+		case <-ctx.Done():
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// runRoot runs the connection server with --server, and shows the help of
+// the CLI otherwise
+func runRoot(cmd *cobra.Command, args []string) error {
+	if !serverMode {
+		return cmd.Help()
+	}
+	idleTimeout, err := cmd.Flags().GetDuration("server-idle-timeout")
+	if err != nil {
+		return err
+	}
+	return runConnectionServer(idleTimeout)
+}
+
+func init() {
+	rootCmd.RunE = runRoot
+	rootCmd.Flags().BoolVar(&serverMode, "server", false, "run a connection server that keeps connections to Portainer open for later invocations")
+	rootCmd.Flags().Duration("server-idle-timeout", 0, "stop the connection server after this long without requests (0 to keep it running)")
+}
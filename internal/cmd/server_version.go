@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+// serverVersion is the version of the Portainer server of the profile,
+// passed to every client so requests match the server's API
+var serverVersion string
+
+// offlineCommands are the top-level commands that do not talk to Portainer
+// through a profile, so no server version is negotiated for them
+var offlineCommands = map[string]bool{
+	"completion":       true,
+	"config":           true,
+	"ctx":              true,
+	"docs":             true,
+	"doctor":           true,
+	"help":             true,
+	"init":             true,
+	"plugin":           true,
+	"version":          true,
+	"__complete":       true,
+	"__completeNoDesc": true,
+}
+
+// offlineAnnotation marks subcommands of other commands that do not talk to
+// Portainer either, such as stacks validate
+const offlineAnnotation = "offline"
+
+// negotiateServerVersion sets serverVersion from the version cache, asking
+// the server on first use of a profile and once the cached version has
+// expired. It warns when the server version is not supported. Failures only
+// leave the version unknown; the command reports connection problems itself.
+func negotiateServerVersion(cmd *cobra.Command) {
+	if !cmd.HasParent() {
+		return
+	}
+	top := cmd
+	for top.Parent().HasParent() {
+		top = top.Parent()
+	}
+	if offlineCommands[top.Name()] || cmd.Annotations[offlineAnnotation] != "" {
+		return
+	}
+
+	profile, err := ResolveProfile(cmd)
+	if err != nil {
+		return
+	}
+
+	version, err := lookupServerVersion(profile, time.Now())
+	if err != nil {
+		if GetVerbose() {
+			fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("failed to get the server version: %v", err)))
+		}
+		return
+	}
+	serverVersion = version.String()
+
+	if !version.Supported() && !GetQuiet() {
+		min := portainer.MinSupportedVersion
+		fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf(
+			"Portainer %s is not supported by this version of portainer-cli (supported: %d.%d and later %d.x releases); some commands may fail",
+			version, min.Major, min.Minor, min.Major)))
+	}
+}
+
+// lookupServerVersion returns the cached server version of the profile's
+// instance, requesting it when it is not cached or has expired
+func lookupServerVersion(profile *config.Profile, now time.Time) (portainer.Version, error) {
+	cache, err := config.LoadVersionCache()
+	if err != nil {
+		// A broken cache only costs a request
+		if GetVerbose() {
+			fmt.Fprintln(os.Stderr, output.Warning(err.Error()))
+		}
+		cache = &config.VersionCache{}
+	}
+
+	// Recorded sessions always include the request, so replaying them does
+	// not depend on the contents of the local cache
+	usingCassette := recordDir != "" || replayDir != ""
+	if cached, ok := cache.Lookup(profile.URL, now); ok && !usingCassette {
+		if version, err := portainer.ParseVersion(cached); err == nil {
+			return version, nil
+		}
+	}
+
+	opts := append(GetClientOptions(), portainer.WithTimeout(10*time.Second), portainer.WithMaxRetries(0))
+	c, err := portainer.NewClient(profile.ClientConfig(), opts...)
+	if err != nil {
+		return portainer.Version{}, err
+	}
+
+	version, err := c.DetectServerVersion()
+	if err != nil {
+		return portainer.Version{}, err
+	}
+
+	cache.Set(profile.URL, version.String(), now)
+	if err := cache.Save(); err != nil && GetVerbose() {
+		fmt.Fprintln(os.Stderr, output.Warning(err.Error()))
+	}
+	return version, nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestNegotiateServerVersion(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/status" {
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+		requests++
+		json.NewEncoder(w).Encode(portainer.StatusResponse{Version: "2.19.4"})
+	}))
+	defer server.Close()
+
+	viper.Set("url", server.URL)
+	viper.Set("api_key", "test-key")
+	defer viper.Set("url", "")
+	defer viper.Set("api_key", "")
+	defer func() { serverVersion = "" }()
+
+	root := &cobra.Command{Use: "root"}
+	stacks := &cobra.Command{Use: "stacks"}
+	list := &cobra.Command{Use: "list"}
+	config := &cobra.Command{Use: "config"}
+	root.AddCommand(stacks, config)
+	stacks.AddCommand(list)
+
+	negotiateServerVersion(config)
+	if serverVersion != "" || requests != 0 {
+		t.Errorf("expected no negotiation for config commands, got %q after %d requests", serverVersion, requests)
+	}
+
+	negotiateServerVersion(list)
+	if serverVersion != "2.19.4" {
+		t.Errorf("expected server version 2.19.4, got %q", serverVersion)
+	}
+
+	// The version is cached per instance
+	serverVersion = ""
+	negotiateServerVersion(list)
+	if serverVersion != "2.19.4" || requests != 1 {
+		t.Errorf("expected the cached version, got %q after %d requests", serverVersion, requests)
+	}
+
+	profile, err := ResolveProfile(list)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := lookupServerVersion(profile, time.Now().Add(25*time.Hour)); err != nil || requests != 2 {
+		t.Errorf("expected an expired version to be requested again, got %v after %d requests", err, requests)
+	}
+}
@@ -1,18 +1,19 @@
 package cmd
 
 import (
-	"context"
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/signal"
+	"sort"
+	"strconv"
 	"strings"
-	"syscall"
-	"time"
 
-	"github.com/robversluis/portainer-cli/internal/client"
-	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/notify"
 	"github.com/robversluis/portainer-cli/internal/output"
-	"github.com/robversluis/portainer-cli/internal/watch"
+	"github.com/robversluis/portainer-cli/internal/terminal"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
 	"github.com/spf13/cobra"
 )
 
@@ -23,93 +24,136 @@ var stacksCmd = &cobra.Command{
 }
 
 var stacksListCmd = &cobra.Command{
-	Use:     "list",
-	Aliases: []string{"ls"},
-	Short:   "List stacks",
-	Long:    `Display a list of all deployed stacks.`,
+	Use:         "list",
+	Aliases:     []string{"ls"},
+	Annotations: map[string]string{noDefaultEndpoint: ""},
+	Short:       "List stacks",
+	Long:        `Display a list of deployed stacks, across all environments unless --endpoint is given.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		endpointID, err := cmd.Flags().GetInt("endpoint")
 		if err != nil {
 			return err
 		}
-		if endpointID == 0 {
-			return fmt.Errorf("--endpoint flag is required")
-		}
-
-		watchMode, err := cmd.Flags().GetBool("watch")
-		if err != nil {
-			return err
-		}
 
-		interval, err := cmd.Flags().GetInt("interval")
+		listOpts, err := getListOptions(cmd)
 		if err != nil {
 			return err
 		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		stackService := client.NewStackService(c)
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		stackService := portainer.NewStackService(c)
+		format := getOutputFormat()
 
+		var values notify.Values
 		listFunc := func() error {
 			stacks, err := stackService.List(endpointID)
 			if err != nil {
 				return err
 			}
 
+			values = stackWatchValues(stacks)
+
+			if GetQuiet() {
+				return printQuiet(listOpts, stacks, func(item portainer.Stack) string {
+					return strconv.Itoa(item.Id)
+				})
+			}
+
 			switch format {
 			case output.FormatJSON, output.FormatYAML:
-				formatter := output.NewFormatter(output.Options{Format: format})
-				return formatter.Format(stacks)
+				formatter := newFormatter(format)
+				items, err := listOpts.applyItems(stacks)
+				if err != nil {
+					return err
+				}
+				return formatter.Format(items)
 
 			default:
-				table := output.NewTableData([]string{"ID", "Name", "Type", "Status"})
+				headers := []string{"ID", "Name", "Type", "Status"}
+				if endpointID == 0 {
+					headers = append(headers, "Endpoint")
+				}
+				table := output.NewTableData(headers)
 				for _, stack := range stacks {
-					table.AddRow([]string{
+					row := []string{
 						fmt.Sprintf("%d", stack.Id),
 						stack.Name,
 						stack.TypeString(),
 						stack.StatusString(),
-					})
+					}
+					if endpointID == 0 {
+						row = append(row, strconv.Itoa(stack.EndpointId))
+					}
+					table.AddRow(row)
+				}
+				if err := listOpts.applyTable(table, stacks); err != nil {
+					return err
 				}
 				return output.PrintTable(*table)
 			}
 		}
 
-		if watchMode {
-			ctx, cancel := context.WithCancel(context.Background())
-			defer cancel()
-
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-			go func() {
-				<-sigChan
-				cancel()
-			}()
-
-			opts := watch.DefaultOptions()
-			opts.Interval = time.Duration(interval) * time.Second
-
-			fmt.Println("Watching stacks... (Press Ctrl+C to exit)")
-			return watch.Watch(ctx, opts, listFunc)
-		}
-
-		return listFunc()
+		return RunWithWatch(cmd, "stacks", listFunc, func() notify.Values { return values })
 	},
 }
 
 var stacksDeployCmd = &cobra.Command{
 	Use:   "deploy",
 	Short: "Deploy a stack",
-	Long:  `Deploy a new stack from a Docker Compose file.`,
+	Long: `Deploy a new stack from a Docker Compose file.
+
+When the environment is a Swarm manager the stack is deployed as a Swarm
+stack, otherwise as a standalone Compose stack. Use --swarm to require a
+Swarm stack, or --swarm=false to deploy a Compose stack to a Swarm manager.
+
+With --kubernetes the stack is deployed to a Kubernetes environment from a
+manifest file, or from a Git repository with --repository-url. Compose files
+are converted to manifests with --compose-format.
+
+Compose files are checked as with "stacks validate" before they are
+deployed: errors abort the deployment unless --skip-validation is set.
+
+With --registry-mirror, Docker Hub images are pulled from a mirror registry
+instead; --image-prefix puts a registry path in front of every image. Both
+default to the registry_mirror and image_prefix settings of the profile, so
+one compose file deploys to air-gapped and public environments alike.
+
+With --set or --values, the stack file is a Go template rendered before it is
+checked and uploaded, so one template can drive many similar stacks:
+  image: {{ .image }}:{{ .tag | default "latest" }}
+Besides the text/template builtins, templates can use default, required and
+quote. Rendering fails when the template uses a value that is not set.
+
+With --update-if-exists, a stack with the same name on the environment is
+updated with the file instead, as with "stacks update", so pipelines can run
+the same deploy every time. Without --env the stack keeps its variables.
+
+--env values can reference secrets, which are resolved when deploying so
+they never appear in stack files or shell history:
+  vault://secret/data/web#password  HashiCorp Vault (VAULT_ADDR, VAULT_TOKEN)
+  ssm:///prod/db/password           AWS Systems Manager parameter
+  awssm://prod/web#api_key          AWS Secrets Manager secret
+  envfile://.env.production#KEY     variable of a dotenv file
+
+Examples:
+  portainer-cli stacks deploy --endpoint 1 --name web --file docker-compose.yml
+  portainer-cli stacks deploy --endpoint 1 --name web --file docker-compose.yml --update-if-exists
+  envsubst < compose.tmpl.yml | portainer-cli stacks deploy --endpoint 1 --name web --file -
+  portainer-cli stacks deploy --endpoint 1 --name web-eu --file web.tmpl.yml --values prod.yaml --set region=eu
+  portainer-cli stacks deploy --endpoint 5 --name web --file docker-compose.yml --registry-mirror mirror.internal/hub
+  portainer-cli stacks deploy --endpoint 1 --name web --file docker-compose.yml --env DB_PASSWORD=vault://secret/data/web#db_password
+  portainer-cli stacks deploy --endpoint 4 --name web --kubernetes --namespace web --file web.yaml
+  portainer-cli stacks deploy --endpoint 4 --name web --kubernetes --namespace web \
+    --repository-url https://github.com/acme/web --repository-file k8s/web.yaml`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		endpointID, err := cmd.Flags().GetInt("endpoint")
 		if err != nil {
@@ -127,6 +171,25 @@ var stacksDeployCmd = &cobra.Command{
 			return fmt.Errorf("--name flag is required")
 		}
 
+		updateIfExists, err := cmd.Flags().GetBool("update-if-exists")
+		if err != nil {
+			return err
+		}
+
+		kubernetes, err := cmd.Flags().GetBool("kubernetes")
+		if err != nil {
+			return err
+		}
+		if kubernetes {
+			if updateIfExists {
+				return fmt.Errorf("--update-if-exists is not supported with --kubernetes")
+			}
+			return deployKubernetesStack(cmd, endpointID, name)
+		}
+		if err := checkKubernetesDeployFlags(cmd); err != nil {
+			return err
+		}
+
 		filePath, err := cmd.Flags().GetString("file")
 		if err != nil {
 			return err
@@ -140,41 +203,177 @@ var stacksDeployCmd = &cobra.Command{
 			return err
 		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		var env []client.StackEnv
+		var env []portainer.StackEnv
 		for _, e := range envVars {
 			parts := strings.SplitN(e, "=", 2)
 			if len(parts) == 2 {
-				env = append(env, client.StackEnv{
+				env = append(env, portainer.StackEnv{
 					Name:  parts[0],
 					Value: parts[1],
 				})
 			}
 		}
+		if env, err = resolveStackSecrets(env); err != nil {
+			return err
+		}
+
+		content, err := readStackFile(filePath, os.Stdin)
+		if err != nil {
+			return err
+		}
+		if content, err = renderStackTemplate(cmd, filePath, content); err != nil {
+			return err
+		}
+		if content, err = rewriteStackImages(cmd, profile, content); err != nil {
+			return err
+		}
 
-		stackService := client.NewStackService(c)
-		stack, err := stackService.DeployFromFile(endpointID, name, filePath, env)
+		stackService := portainer.NewStackService(c)
+		swarmID, err := deploySwarmID(cmd, stackService, endpointID)
 		if err != nil {
 			return err
 		}
 
-		if !GetQuiet() {
-			fmt.Printf("Stack '%s' deployed successfully (ID: %d)\n", stack.Name, stack.Id)
+		if err := lintStackFile(cmd, filePath, content, env, swarmID != ""); err != nil {
+			return err
+		}
+
+		if updateIfExists {
+			existing, err := findStack(stackService, endpointID, name)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				return updateExistingStack(stackService, existing, swarmID != "", content, env)
+			}
 		}
 
+		emitProgress(progressEvent{Event: "deploying", Endpoint: endpointID, Stack: name})
+		var stack *portainer.Stack
+		if swarmID != "" {
+			stack, err = stackService.DeploySwarm(endpointID, swarmID, name, content, env)
+		} else {
+			stack, err = stackService.Deploy(endpointID, name, content, env)
+		}
+		if err != nil {
+			emitProgress(progressEvent{Event: "deploy_failed", Endpoint: endpointID, Stack: name, Error: err.Error()})
+			return err
+		}
+
+		printStackDeployed(stack)
 		return nil
 	},
 }
 
+// readStackFile returns the content of the --file flag: a file, or stdin
+// for "-" so pipelines can deploy templated stacks without a temporary file
+func readStackFile(path string, stdin io.Reader) (string, error) {
+	if path != "-" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read stack file: %w", err)
+		}
+		return string(content), nil
+	}
+
+	if f, ok := stdin.(*os.File); ok && terminal.IsTerminal(f) {
+		return "", fmt.Errorf("--file - reads the stack file from stdin, which is a terminal; pipe the file in")
+	}
+	content, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stack file from stdin: %w", err)
+	}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return "", fmt.Errorf("the stack file read from stdin is empty")
+	}
+	return string(content), nil
+}
+
+func printStackDeployed(stack *portainer.Stack) {
+	emitProgress(progressEvent{Event: "deployed", Endpoint: stack.EndpointId, Stack: stack.Name, StackID: stack.Id, Action: "created"})
+	if !GetQuiet() && !GetDryRun() {
+		fmt.Printf("Stack '%s' deployed successfully (ID: %d)\n", stack.Name, stack.Id)
+	}
+}
+
+// findStack returns the stack named name on an environment, or nil when
+// there is none
+func findStack(stackService *portainer.StackService, endpointID int, name string) (*portainer.Stack, error) {
+	stack, err := stackService.GetByName(endpointID, name)
+	var notFound *portainer.StackNotFoundError
+	if errors.As(err, &notFound) {
+		return nil, nil
+	}
+	return stack, err
+}
+
+// updateExistingStack updates a stack found by stacks deploy
+// --update-if-exists with the deployed file. Without --env the stack keeps
+// its variables, as with stacks update.
+func updateExistingStack(stackService *portainer.StackService, stack *portainer.Stack, swarm bool, content string, env []portainer.StackEnv) error {
+	if (stack.Type == portainer.StackTypeSwarm) != swarm {
+		kind := "Compose"
+		if stack.Type == portainer.StackTypeSwarm {
+			kind = "Swarm"
+		}
+		return fmt.Errorf("stack '%s' exists as a %s stack (ID: %d), which --update-if-exists cannot change", stack.Name, kind, stack.Id)
+	}
+	if len(env) == 0 {
+		env = stack.Env
+	}
+
+	emitProgress(progressEvent{Event: "deploying", Endpoint: stack.EndpointId, Stack: stack.Name, StackID: stack.Id})
+	if err := stackService.Update(stack.Id, stack.EndpointId, content, env); err != nil {
+		emitProgress(progressEvent{Event: "deploy_failed", Endpoint: stack.EndpointId, Stack: stack.Name, StackID: stack.Id, Error: err.Error()})
+		return err
+	}
+
+	emitProgress(progressEvent{Event: "deployed", Endpoint: stack.EndpointId, Stack: stack.Name, StackID: stack.Id, Action: "updated"})
+	if !GetQuiet() && !GetDryRun() {
+		fmt.Printf("Stack '%s' updated successfully (ID: %d)\n", stack.Name, stack.Id)
+	}
+	return nil
+}
+
+// deploySwarmID returns the swarm to deploy a stack to, or an empty string
+// for a standalone Compose stack. Without --swarm the stack type follows the
+// environment: Swarm managers get Swarm stacks.
+func deploySwarmID(cmd *cobra.Command, stackService *portainer.StackService, endpointID int) (string, error) {
+	swarm, err := cmd.Flags().GetBool("swarm")
+	if err != nil {
+		return "", err
+	}
+	if cmd.Flags().Changed("swarm") && !swarm {
+		return "", nil
+	}
+
+	swarmID, err := stackService.SwarmID(endpointID)
+	if err != nil {
+		return "", err
+	}
+	if swarm && swarmID == "" {
+		if GetDryRun() {
+			// The swarm is not inspected in dry-run mode
+			return "<swarm-id>", nil
+		}
+		return "", fmt.Errorf("environment %d is not a Swarm manager", endpointID)
+	}
+	if swarmID != "" && GetVerbose() {
+		fmt.Fprintf(os.Stderr, "Deploying Swarm stack to swarm %s\n", swarmID)
+	}
+	return swarmID, nil
+}
+
 var stacksGetCmd = &cobra.Command{
 	Use:   "get [id or name]",
 	Short: "Get stack details",
@@ -186,19 +385,19 @@ var stacksGetCmd = &cobra.Command{
 			return err
 		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		stackService := client.NewStackService(c)
+		stackService := portainer.NewStackService(c)
 
-		var stack *client.Stack
+		var stack *portainer.Stack
 		var stackID int
 		if _, err := fmt.Sscanf(args[0], "%d", &stackID); err == nil {
 			stack, err = stackService.Get(stackID)
@@ -215,24 +414,41 @@ var stacksGetCmd = &cobra.Command{
 			}
 		}
 
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		format := getOutputFormat()
 
 		switch format {
 		case output.FormatJSON, output.FormatYAML:
-			formatter := output.NewFormatter(output.Options{Format: format})
+			formatter := newFormatter(format)
 			return formatter.Format(stack)
 
 		default:
 			fmt.Printf("ID:          %d\n", stack.Id)
 			fmt.Printf("Name:        %s\n", stack.Name)
 			fmt.Printf("Type:        %s\n", stack.TypeString())
-			fmt.Printf("Status:      %s\n", stack.StatusString())
+			fmt.Printf("Status:      %s\n", output.ColorizeStatus(stack.StatusString()))
 			fmt.Printf("Endpoint ID: %d\n", stack.EndpointId)
 
 			if stack.EntryPoint != "" {
 				fmt.Printf("Entry Point: %s\n", stack.EntryPoint)
 			}
 
+			if stack.GitConfig != nil {
+				fmt.Printf("\nGit Repository:\n")
+				fmt.Printf("  URL:         %s\n", stack.GitConfig.URL)
+				fmt.Printf("  Reference:   %s\n", stack.GitConfig.ReferenceName)
+				fmt.Printf("  File:        %s\n", stack.GitConfig.ConfigFilePath)
+				if stack.AutoUpdate != nil {
+					if stack.AutoUpdate.Interval != "" {
+						fmt.Printf("  Polling:     every %s\n", stack.AutoUpdate.Interval)
+					}
+					if stack.AutoUpdate.Webhook != "" {
+						fmt.Printf("  Webhook:     %s\n", stackService.WebhookURL(stack.AutoUpdate.Webhook))
+					}
+				} else {
+					fmt.Printf("  Auto-update: disabled\n")
+				}
+			}
+
 			if len(stack.Env) > 0 {
 				fmt.Printf("\nEnvironment Variables:\n")
 				for _, env := range stack.Env {
@@ -260,17 +476,17 @@ var stacksRemoveCmd = &cobra.Command{
 			return fmt.Errorf("--endpoint flag is required")
 		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		stackService := client.NewStackService(c)
+		stackService := portainer.NewStackService(c)
 
 		var stackID int
 		if _, err := fmt.Sscanf(args[0], "%d", &stackID); err == nil {
@@ -295,11 +511,229 @@ var stacksRemoveCmd = &cobra.Command{
 	},
 }
 
+var stacksRedeployCmd = &cobra.Command{
+	Use:   "redeploy [id or name]",
+	Short: "Redeploy a stack",
+	Long: `Re-apply a stack with its current configuration, e.g. after pushing a new
+image under the same tag.
+
+Git-backed stacks are redeployed from their repository; other stacks re-use
+their current stack file and environment variables.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+
+		pull, err := cmd.Flags().GetBool("pull")
+		if err != nil {
+			return err
+		}
+
+		prune, err := cmd.Flags().GetBool("prune")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		// The stack is looked up even in dry-run mode, so the printed request
+		// is the one that would be sent
+		reader, err := portainer.NewClient(profile.ClientConfig(), append(GetClientOptions(), portainer.WithDryRun(false))...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		stack, err := resolveStack(portainer.NewStackService(reader), args[0], endpointID)
+		if err != nil {
+			return err
+		}
+
+		if endpointID != 0 {
+			stack.EndpointId = endpointID
+		}
+
+		stackService := portainer.NewStackService(c)
+		if err := stackService.Redeploy(stack, pull, prune); err != nil {
+			return err
+		}
+
+		if !GetQuiet() && !GetDryRun() {
+			fmt.Printf("Stack %s redeployed successfully\n", stack.Name)
+		}
+
+		return nil
+	},
+}
+
+var stacksPsCmd = &cobra.Command{
+	Use:   "ps [id or name]",
+	Short: "List the containers of a stack",
+	Long: `Display the containers belonging to a Compose stack with their service,
+state, health, and ports, matched by the Compose project label.
+
+For Swarm stacks the tasks of the stack's services are shown instead, with
+the node they run on, as "docker stack ps" does, so containers on every
+node of the swarm are covered.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		stackService := portainer.NewStackService(c)
+		stack, err := resolveStack(stackService, args[0], endpointID)
+		if err != nil {
+			return err
+		}
+
+		if stack.Type == portainer.StackTypeSwarm {
+			return printStackTasks(stackService, stack)
+		}
+
+		containers, err := stackService.Containers(stack)
+		if err != nil {
+			return err
+		}
+
+		sort.SliceStable(containers, func(i, j int) bool {
+			if containers[i].GetStackService() != containers[j].GetStackService() {
+				return containers[i].GetStackService() < containers[j].GetStackService()
+			}
+			return containers[i].GetName() < containers[j].GetName()
+		})
+
+		if GetQuiet() {
+			for _, container := range containers {
+				fmt.Println(container.GetShortID())
+			}
+			return nil
+		}
+
+		format := getOutputFormat()
+
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			formatter := newFormatter(format)
+			return formatter.Format(containers)
+
+		default:
+			table := output.NewTableData([]string{"Service", "Name", "Image", "State", "Status", "Health", "Ports"})
+			for _, container := range containers {
+				ports := container.GetPorts()
+				if len(ports) > 50 {
+					ports = output.TruncateString(ports, 50)
+				}
+				healthStatus := container.GetHealth()
+				if healthStatus == portainer.HealthStatusNone {
+					healthStatus = "-"
+				}
+				table.AddRow([]string{
+					container.GetStackService(),
+					container.GetName(),
+					container.Image,
+					container.State,
+					container.GetStatus(),
+					healthStatus,
+					ports,
+				})
+			}
+			return output.PrintTable(*table)
+		}
+	},
+}
+
+// printStackTasks lists the tasks of a Swarm stack for stacks ps, newest
+// first within a slot
+func printStackTasks(stackService *portainer.StackService, stack *portainer.Stack) error {
+	tasks, err := stackService.Tasks(stack)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Name != tasks[j].Name {
+			return tasks[i].Name < tasks[j].Name
+		}
+		return tasks[i].Timestamp > tasks[j].Timestamp
+	})
+
+	if GetQuiet() {
+		for _, task := range tasks {
+			fmt.Println(task.ID)
+		}
+		return nil
+	}
+
+	format := getOutputFormat()
+
+	switch format {
+	case output.FormatJSON, output.FormatYAML:
+		formatter := newFormatter(format)
+		return formatter.Format(tasks)
+
+	default:
+		table := output.NewTableData([]string{"Service", "Name", "Image", "Node", "Desired State", "Current State", "Error"})
+		for _, task := range tasks {
+			table.AddRow([]string{
+				task.Service,
+				task.Name,
+				task.Image,
+				task.Node,
+				task.DesiredState,
+				task.State,
+				output.TruncateString(task.Error, 50),
+			})
+		}
+		return output.PrintTable(*table)
+	}
+}
+
+// resolveStack looks up a stack by ID, or by name within an environment
+func resolveStack(stackService *portainer.StackService, idOrName string, endpointID int) (*portainer.Stack, error) {
+	if stackID, err := strconv.Atoi(idOrName); err == nil {
+		return stackService.Get(stackID)
+	}
+
+	if endpointID == 0 {
+		return nil, fmt.Errorf("--endpoint flag is required when using stack name")
+	}
+	return stackService.GetByName(endpointID, idOrName)
+}
+
 var stacksUpdateCmd = &cobra.Command{
 	Use:   "update [stack-id]",
 	Short: "Update a stack",
-	Long:  `Update an existing stack with a new compose file.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Update an existing stack with a new compose file. Use --file - to read the
+file from stdin. The file is checked as with "stacks validate" first.
+
+Example:
+  portainer-cli stacks update 7 --endpoint 1 --file docker-compose.yml
+  portainer-cli stacks update 7 --endpoint 1 --file - <<EOF
+  services:
+    web:
+      image: nginx:1.25
+  EOF`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var stackID int
 		if _, err := fmt.Sscanf(args[0], "%d", &stackID); err != nil {
@@ -327,41 +761,54 @@ var stacksUpdateCmd = &cobra.Command{
 			return err
 		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		stackService := client.NewStackService(c)
+		stackService := portainer.NewStackService(c)
+
+		var content string
+		if stackFile == "-" {
+			content, err = readStackFile(stackFile, os.Stdin)
+		} else {
+			content, err = portainer.ParseStackFile(stackFile)
+		}
+		if err != nil {
+			return err
+		}
+		if content, err = renderStackTemplate(cmd, stackFile, content); err != nil {
+			return err
+		}
+		if content, err = rewriteStackImages(cmd, profile, content); err != nil {
+			return err
+		}
 
-		content, err := client.ParseStackFile(stackFile)
+		env, err := parseStackEnvFlags(envVars)
 		if err != nil {
 			return err
 		}
+		if env, err = resolveStackSecrets(env); err != nil {
+			return err
+		}
+		existingStack, err := stackService.Get(stackID)
+		if err != nil {
+			return fmt.Errorf("failed to get existing stack: %w", err)
+		}
+		if len(env) == 0 {
+			env = existingStack.Env
+		}
 
-		var env []client.StackEnv
-		if len(envVars) > 0 {
-			for _, envVar := range envVars {
-				parts := strings.SplitN(envVar, "=", 2)
-				if len(parts) != 2 {
-					return fmt.Errorf("invalid env format: %s (expected KEY=VALUE)", envVar)
-				}
-				env = append(env, client.StackEnv{
-					Name:  parts[0],
-					Value: parts[1],
-				})
-			}
-		} else {
-			existingStack, err := stackService.Get(stackID)
-			if err != nil {
-				return fmt.Errorf("failed to get existing stack: %w", err)
+		// Kubernetes manifests are not compose files
+		if existingStack.Type != portainer.StackTypeKubernetes {
+			if err := lintStackFile(cmd, stackFile, content, env, existingStack.Type == portainer.StackTypeSwarm); err != nil {
+				return err
 			}
-			env = existingStack.Env
 		}
 
 		if err := stackService.Update(stackID, endpointID, content, env); err != nil {
@@ -376,24 +823,41 @@ var stacksUpdateCmd = &cobra.Command{
 	},
 }
 
+// stackWatchValues returns the --notify-on variables for stacks: the count
+// and the number that are active and inactive
+func stackWatchValues(stacks []portainer.Stack) notify.Values {
+	values := notify.Values{"count": float64(len(stacks)), "active": 0, "inactive": 0}
+	for _, stack := range stacks {
+		switch stack.Status {
+		case portainer.StackStatusActive:
+			values["active"]++
+		case portainer.StackStatusInactive:
+			values["inactive"]++
+		}
+	}
+	return values
+}
+
 func init() {
 	rootCmd.AddCommand(stacksCmd)
 	stacksCmd.AddCommand(stacksListCmd)
 	stacksCmd.AddCommand(stacksDeployCmd)
 	stacksCmd.AddCommand(stacksGetCmd)
 	stacksCmd.AddCommand(stacksUpdateCmd)
+	stacksCmd.AddCommand(stacksRedeployCmd)
+	stacksCmd.AddCommand(stacksPsCmd)
 	stacksCmd.AddCommand(stacksRemoveCmd)
 
-	stacksListCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
-	stacksListCmd.Flags().BoolP("watch", "w", false, "Watch for changes and continuously update")
-	stacksListCmd.Flags().Int("interval", 2, "Refresh interval in seconds for watch mode")
-	_ = stacksListCmd.MarkFlagRequired("endpoint")
+	stacksListCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (all environments if omitted)")
+	AddWatchFlags(stacksListCmd)
+	addListFlags(stacksListCmd)
 
-	stacksDeployCmd.Flags().String("file", "", "Path to stack file (required)")
+	stacksDeployCmd.Flags().String("file", "", "Path to stack file, or - to read it from stdin")
 	stacksDeployCmd.Flags().String("name", "", "Stack name (required)")
 	stacksDeployCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
-	stacksDeployCmd.Flags().StringArray("env", []string{}, "Environment variables (KEY=VALUE)")
-	_ = stacksDeployCmd.MarkFlagRequired("file")
+	stacksDeployCmd.Flags().StringArray("env", []string{}, "Environment variables (KEY=VALUE); values may be secret references such as vault://path#field")
+	stacksDeployCmd.Flags().Bool("swarm", false, "Deploy a Swarm stack (default: detected from the environment)")
+	stacksDeployCmd.Flags().Bool("update-if-exists", false, "Update the stack when a stack with the same name exists on the environment instead of failing")
 	_ = stacksDeployCmd.MarkFlagRequired("name")
 	_ = stacksDeployCmd.MarkFlagRequired("endpoint")
 
@@ -403,8 +867,14 @@ func init() {
 	_ = stacksRemoveCmd.MarkFlagRequired("endpoint")
 
 	stacksUpdateCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
-	stacksUpdateCmd.Flags().String("file", "", "Path to stack file (required)")
-	stacksUpdateCmd.Flags().StringArray("env", []string{}, "Environment variables (KEY=VALUE)")
+	stacksUpdateCmd.Flags().String("file", "", "Path to stack file, or - to read it from stdin (required)")
+	stacksUpdateCmd.Flags().StringArray("env", []string{}, "Environment variables (KEY=VALUE); values may be secret references such as vault://path#field")
 	_ = stacksUpdateCmd.MarkFlagRequired("endpoint")
 	_ = stacksUpdateCmd.MarkFlagRequired("file")
+
+	stacksPsCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required when using a stack name)")
+
+	stacksRedeployCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required when using a stack name)")
+	stacksRedeployCmd.Flags().Bool("pull", false, "Re-pull images before redeploying")
+	stacksRedeployCmd.Flags().Bool("prune", false, "Remove services that are no longer in the stack file")
 }
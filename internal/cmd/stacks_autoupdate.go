@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var stacksAutoUpdateCmd = &cobra.Command{
+	Use:     "autoupdate",
+	Aliases: []string{"auto-update"},
+	Short:   "Configure automatic updates of Git-backed stacks",
+	Long: `Configure GitOps-style automatic redeployment of stacks deployed from a Git
+repository. Portainer can poll the repository at an interval, redeploy when a
+webhook is called, or both.
+
+Several stacks can be configured at once, e.g. from a script:
+  portainer-cli stacks autoupdate enable 3 7 12 --interval 5m
+  portainer-cli stacks autoupdate enable web --endpoint 1 --webhook
+  portainer-cli stacks autoupdate disable 3 7 12`,
+}
+
+var stacksAutoUpdateEnableCmd = &cobra.Command{
+	Use:   "enable [id or name]...",
+	Short: "Enable automatic updates",
+	Long: `Enable automatic updates of one or more Git-backed stacks, replacing their
+current auto-update settings.
+
+--interval polls the repository and redeploys when it changed. --webhook
+creates a webhook URL that triggers a redeployment; an existing webhook is
+kept so its URL stays valid.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		interval, err := cmd.Flags().GetString("interval")
+		if err != nil {
+			return err
+		}
+		webhook, err := cmd.Flags().GetBool("webhook")
+		if err != nil {
+			return err
+		}
+		forceUpdate, err := cmd.Flags().GetBool("force-update")
+		if err != nil {
+			return err
+		}
+		pull, err := cmd.Flags().GetBool("pull")
+		if err != nil {
+			return err
+		}
+
+		if interval == "" && !webhook {
+			return fmt.Errorf("--interval or --webhook is required")
+		}
+		if interval != "" {
+			d, err := time.ParseDuration(interval)
+			if err != nil {
+				return fmt.Errorf("invalid interval %q: %w", interval, err)
+			}
+			if d < time.Minute {
+				return fmt.Errorf("--interval must be at least 1m")
+			}
+		}
+
+		stackService, err := newStackService(cmd)
+		if err != nil {
+			return err
+		}
+
+		for _, arg := range args {
+			stack, err := resolveStack(stackService, arg, endpointID)
+			if err != nil {
+				return err
+			}
+
+			settings := &portainer.StackAutoUpdate{
+				Interval:       interval,
+				ForceUpdate:    forceUpdate,
+				ForcePullImage: pull,
+			}
+			if webhook {
+				if stack.AutoUpdate != nil && stack.AutoUpdate.Webhook != "" {
+					settings.Webhook = stack.AutoUpdate.Webhook
+				} else if settings.Webhook, err = newUUID(); err != nil {
+					return err
+				}
+			}
+
+			if err := stackService.SetAutoUpdate(stack, settings); err != nil {
+				return err
+			}
+
+			if !GetQuiet() {
+				fmt.Printf("Auto-update enabled for stack %s\n", stack.Name)
+				if settings.Webhook != "" {
+					fmt.Printf("  Webhook: %s\n", stackService.WebhookURL(settings.Webhook))
+				}
+			} else if settings.Webhook != "" {
+				fmt.Println(stackService.WebhookURL(settings.Webhook))
+			}
+		}
+		return nil
+	},
+}
+
+var stacksAutoUpdateDisableCmd = &cobra.Command{
+	Use:   "disable [id or name]...",
+	Short: "Disable automatic updates",
+	Long: `Disable automatic updates of one or more Git-backed stacks. Their webhooks
+stop working.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+
+		stackService, err := newStackService(cmd)
+		if err != nil {
+			return err
+		}
+
+		for _, arg := range args {
+			stack, err := resolveStack(stackService, arg, endpointID)
+			if err != nil {
+				return err
+			}
+
+			if stack.AutoUpdate == nil {
+				if !GetQuiet() {
+					fmt.Printf("Auto-update is already disabled for stack %s\n", stack.Name)
+				}
+				continue
+			}
+
+			if err := stackService.SetAutoUpdate(stack, nil); err != nil {
+				return err
+			}
+
+			if !GetQuiet() {
+				fmt.Printf("Auto-update disabled for stack %s\n", stack.Name)
+			}
+		}
+		return nil
+	},
+}
+
+func newStackService(cmd *cobra.Command) (*portainer.StackService, error) {
+	profile, err := ResolveProfile(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return portainer.NewStackService(c), nil
+}
+
+// newUUID returns a random version 4 UUID, the format Portainer uses
+// for stack webhook IDs and Edge agent IDs
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+func init() {
+	stacksCmd.AddCommand(stacksAutoUpdateCmd)
+	stacksAutoUpdateCmd.AddCommand(stacksAutoUpdateEnableCmd)
+	stacksAutoUpdateCmd.AddCommand(stacksAutoUpdateDisableCmd)
+
+	for _, c := range []*cobra.Command{stacksAutoUpdateEnableCmd, stacksAutoUpdateDisableCmd} {
+		c.Flags().Int("endpoint", 0, "Environment endpoint ID (required when using stack names)")
+	}
+
+	stacksAutoUpdateEnableCmd.Flags().String("interval", "", "Poll the repository at this interval (e.g. 5m, 1h)")
+	stacksAutoUpdateEnableCmd.Flags().Bool("webhook", false, "Redeploy when the stack webhook URL is called")
+	stacksAutoUpdateEnableCmd.Flags().Bool("force-update", false, "Redeploy even when the repository has not changed")
+	stacksAutoUpdateEnableCmd.Flags().Bool("pull", false, "Re-pull images on every redeployment")
+}
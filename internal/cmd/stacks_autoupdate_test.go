@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNewUUID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	first, err := newUUID()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !uuid.MatchString(first) {
+		t.Errorf("expected a version 4 UUID, got %s", first)
+	}
+
+	second, err := newUUID()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first == second {
+		t.Error("expected different IDs")
+	}
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var stacksEnvCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage stack environment variables",
+	Long: `List and change the environment variables of a stack without supplying its
+stack file. Changes redeploy the stack with its current stack file, or from
+its repository for Git-backed stacks.`,
+}
+
+var stacksEnvListCmd = &cobra.Command{
+	Use:     "list [id or name]",
+	Aliases: []string{"ls"},
+	Short:   "List stack environment variables",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, stack, err := stackForEnv(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			env := stack.Env
+			if env == nil {
+				env = []portainer.StackEnv{}
+			}
+			return newFormatter(format).Format(env)
+		}
+
+		if GetQuiet() {
+			for _, env := range stack.Env {
+				fmt.Println(env.Name)
+			}
+			return nil
+		}
+
+		if len(stack.Env) == 0 {
+			fmt.Printf("Stack %s has no environment variables\n", stack.Name)
+			return nil
+		}
+
+		table := output.NewTableData([]string{"Name", "Value"})
+		for _, env := range stack.Env {
+			table.AddRow([]string{env.Name, env.Value})
+		}
+		return output.PrintTable(*table)
+	},
+}
+
+var stacksEnvSetCmd = &cobra.Command{
+	Use:   "set [id or name] KEY=VALUE...",
+	Short: "Set stack environment variables",
+	Long: `Add or change environment variables of a stack and redeploy it. Values may
+be secret references, such as vault://secret/data/web#password, which are
+resolved before the stack is redeployed (see "stacks deploy --help").
+
+Examples:
+  portainer-cli stacks env set 12 LOG_LEVEL=debug
+  portainer-cli stacks env set web --endpoint 1 IMAGE_TAG=1.4.2 REPLICAS=3`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		vars, err := parseStackEnv(args[1:])
+		if err != nil {
+			return err
+		}
+		if vars, err = resolveStackSecrets(vars); err != nil {
+			return err
+		}
+
+		stackService, stack, err := stackForEnv(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		env, changed, err := mergeStackEnv(stack.Env, vars, nil)
+		if err != nil {
+			return err
+		}
+		return updateStackEnv(stackService, stack, env, changed)
+	},
+}
+
+var stacksEnvUnsetCmd = &cobra.Command{
+	Use:   "unset [id or name] KEY...",
+	Short: "Remove stack environment variables",
+	Long: `Remove environment variables from a stack and redeploy it.
+
+Example:
+  portainer-cli stacks env unset 12 DEBUG`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stackService, stack, err := stackForEnv(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		env, changed, err := mergeStackEnv(stack.Env, nil, args[1:])
+		if err != nil {
+			return err
+		}
+		return updateStackEnv(stackService, stack, env, changed)
+	},
+}
+
+// stackForEnv resolves the stack of an env subcommand
+func stackForEnv(cmd *cobra.Command, idOrName string) (*portainer.StackService, *portainer.Stack, error) {
+	endpointID, err := cmd.Flags().GetInt("endpoint")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stackService, err := newStackService(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stack, err := resolveStack(stackService, idOrName, endpointID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if endpointID != 0 {
+		stack.EndpointId = endpointID
+	}
+	return stackService, stack, nil
+}
+
+func updateStackEnv(stackService *portainer.StackService, stack *portainer.Stack, env []portainer.StackEnv, changed bool) error {
+	if !changed {
+		if !GetQuiet() {
+			fmt.Printf("Stack %s is unchanged\n", stack.Name)
+		}
+		return nil
+	}
+
+	if err := stackService.UpdateEnv(stack, env); err != nil {
+		return err
+	}
+
+	if !GetQuiet() {
+		fmt.Printf("Stack %s redeployed with updated environment variables\n", stack.Name)
+	}
+	return nil
+}
+
+// parseStackEnv parses KEY=VALUE arguments
+func parseStackEnv(args []string) ([]portainer.StackEnv, error) {
+	vars := make([]portainer.StackEnv, 0, len(args))
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid env format: %s (expected KEY=VALUE)", arg)
+		}
+		vars = append(vars, portainer.StackEnv{Name: name, Value: value})
+	}
+	return vars, nil
+}
+
+// mergeStackEnv applies set and unset to env, keeping the order of existing
+// variables and appending new ones. It reports whether anything changed;
+// unsetting a variable that is not defined is an error.
+func mergeStackEnv(env, set []portainer.StackEnv, unset []string) ([]portainer.StackEnv, bool, error) {
+	merged := make([]portainer.StackEnv, len(env))
+	copy(merged, env)
+	changed := false
+
+	for _, v := range set {
+		found := false
+		for i := range merged {
+			if merged[i].Name == v.Name {
+				found = true
+				if merged[i].Value != v.Value {
+					merged[i].Value = v.Value
+					changed = true
+				}
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, v)
+			changed = true
+		}
+	}
+
+	for _, name := range unset {
+		index := -1
+		for i := range merged {
+			if merged[i].Name == name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, false, fmt.Errorf("environment variable %s is not set on the stack", name)
+		}
+		merged = append(merged[:index], merged[index+1:]...)
+		changed = true
+	}
+
+	return merged, changed, nil
+}
+
+func init() {
+	stacksCmd.AddCommand(stacksEnvCmd)
+	stacksEnvCmd.AddCommand(stacksEnvListCmd)
+	stacksEnvCmd.AddCommand(stacksEnvSetCmd)
+	stacksEnvCmd.AddCommand(stacksEnvUnsetCmd)
+
+	for _, c := range []*cobra.Command{stacksEnvListCmd, stacksEnvSetCmd, stacksEnvUnsetCmd} {
+		c.Flags().Int("endpoint", 0, "Environment endpoint ID (required when using a stack name)")
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestParseStackEnv(t *testing.T) {
+	vars, err := parseStackEnv([]string{"TAG=1.2", "EMPTY=", "URL=http://x?a=b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []portainer.StackEnv{{Name: "TAG", Value: "1.2"}, {Name: "EMPTY", Value: ""}, {Name: "URL", Value: "http://x?a=b"}}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Errorf("expected %v, got %v", expected, vars)
+	}
+
+	for _, arg := range []string{"TAG", "=value"} {
+		if _, err := parseStackEnv([]string{arg}); err == nil {
+			t.Errorf("expected error for %q", arg)
+		}
+	}
+}
+
+func TestMergeStackEnv(t *testing.T) {
+	env := []portainer.StackEnv{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}, {Name: "C", Value: "3"}}
+
+	tests := []struct {
+		name     string
+		set      []portainer.StackEnv
+		unset    []string
+		expected []portainer.StackEnv
+		changed  bool
+		wantErr  bool
+	}{
+		{
+			name:     "update and append",
+			set:      []portainer.StackEnv{{Name: "B", Value: "20"}, {Name: "D", Value: "4"}},
+			expected: []portainer.StackEnv{{Name: "A", Value: "1"}, {Name: "B", Value: "20"}, {Name: "C", Value: "3"}, {Name: "D", Value: "4"}},
+			changed:  true,
+		},
+		{
+			name:     "same value",
+			set:      []portainer.StackEnv{{Name: "A", Value: "1"}},
+			expected: env,
+			changed:  false,
+		},
+		{
+			name:     "unset",
+			unset:    []string{"A", "C"},
+			expected: []portainer.StackEnv{{Name: "B", Value: "2"}},
+			changed:  true,
+		},
+		{
+			name:    "unset unknown",
+			unset:   []string{"MISSING"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, changed, err := mergeStackEnv(env, tt.set, tt.unset)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(merged, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, merged)
+			}
+			if changed != tt.changed {
+				t.Errorf("expected changed=%v, got %v", tt.changed, changed)
+			}
+		})
+	}
+
+	if env[0].Value != "1" || len(env) != 3 {
+		t.Errorf("expected the original env to be left unchanged, got %v", env)
+	}
+}
+
+func TestResolveStackSecrets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	if err := os.WriteFile(path, []byte("DB_PASSWORD=hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	env := []portainer.StackEnv{
+		{Name: "TAG", Value: "1.2"},
+		{Name: "URL", Value: "https://example.com/#top"},
+		{Name: "DB_PASSWORD", Value: "envfile://" + path + "#DB_PASSWORD"},
+	}
+	resolved, err := resolveStackSecrets(env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []portainer.StackEnv{
+		{Name: "TAG", Value: "1.2"},
+		{Name: "URL", Value: "https://example.com/#top"},
+		{Name: "DB_PASSWORD", Value: "hunter2"},
+	}
+	if !reflect.DeepEqual(resolved, expected) {
+		t.Errorf("expected %v, got %v", expected, resolved)
+	}
+	if env[2].Value == "hunter2" {
+		t.Error("expected the input to be left unchanged")
+	}
+
+	dryRun = true
+	defer func() { dryRun = false }()
+	resolved, err = resolveStackSecrets(env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved[2].Value != dryRunSecret || resolved[0].Value != "1.2" {
+		t.Errorf("expected the secret to be masked with --dry-run, got %v", resolved)
+	}
+	dryRun = false
+
+	_, err = resolveStackSecrets([]portainer.StackEnv{{Name: "KEY", Value: "envfile://" + path + "#MISSING"}})
+	if err == nil || !strings.Contains(err.Error(), "env KEY") {
+		t.Errorf("expected an error naming the variable, got %v", err)
+	}
+}
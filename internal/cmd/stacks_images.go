@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/robversluis/portainer-cli/internal/compose"
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// rewriteStackImages applies --registry-mirror or --image-prefix to the
+// images of a stack file, falling back to the registry_mirror and
+// image_prefix settings of the profile when neither flag is given. An empty
+// flag value turns the profile setting off.
+func rewriteStackImages(cmd *cobra.Command, profile *config.Profile, content string) (string, error) {
+	mirror, prefix := profile.RegistryMirror, profile.ImagePrefix
+	if cmd.Flags().Changed("registry-mirror") || cmd.Flags().Changed("image-prefix") {
+		var err error
+		if mirror, err = cmd.Flags().GetString("registry-mirror"); err != nil {
+			return "", err
+		}
+		if prefix, err = cmd.Flags().GetString("image-prefix"); err != nil {
+			return "", err
+		}
+	}
+
+	var rewrite func(string) string
+	switch {
+	case mirror != "" && prefix != "":
+		return "", fmt.Errorf("a registry mirror and an image prefix cannot be used together")
+	case mirror != "":
+		rewrite = compose.MirrorImage(mirror)
+	case prefix != "":
+		rewrite = compose.PrefixImage(prefix)
+	default:
+		return content, nil
+	}
+
+	rewritten, changes, err := compose.RewriteImages([]byte(content), rewrite)
+	if err != nil {
+		return "", err
+	}
+	if GetVerbose() {
+		for _, change := range changes {
+			fmt.Fprintf(os.Stderr, "Service %s: image %s -> %s\n", change.Service, change.From, change.To)
+		}
+	}
+	return string(rewritten), nil
+}
+
+func addImageRewriteFlags(cmd *cobra.Command) {
+	cmd.Flags().String("registry-mirror", "", "Pull Docker Hub images from this registry, e.g. mirror.internal/hub (default: registry_mirror of the profile)")
+	cmd.Flags().String("image-prefix", "", "Prefix every image with this registry path (default: image_prefix of the profile)")
+}
+
+func init() {
+	addImageRewriteFlags(stacksDeployCmd)
+	addImageRewriteFlags(stacksUpdateCmd)
+}
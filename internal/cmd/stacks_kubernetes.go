@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+// kubernetesDeployFlags are the stacks deploy flags that only apply to
+// Kubernetes stacks
+var kubernetesDeployFlags = []string{
+	"namespace",
+	"compose-format",
+	"repository-url",
+	"repository-ref",
+	"repository-file",
+	"repository-username",
+	"repository-password",
+}
+
+func deployKubernetesStack(cmd *cobra.Command, endpointID int, name string) error {
+	req, err := kubernetesDeployRequest(cmd, name)
+	if err != nil {
+		return err
+	}
+
+	stackService, err := newStackService(cmd)
+	if err != nil {
+		return err
+	}
+
+	emitProgress(progressEvent{Event: "deploying", Endpoint: endpointID, Stack: name})
+	stack, err := stackService.DeployKubernetes(endpointID, req)
+	if err != nil {
+		emitProgress(progressEvent{Event: "deploy_failed", Endpoint: endpointID, Stack: name, Error: err.Error()})
+		return err
+	}
+
+	printStackDeployed(stack)
+	return nil
+}
+
+// kubernetesDeployRequest builds the deploy request from the stacks deploy
+// flags. The stack is deployed from --file, or from --repository-url.
+func kubernetesDeployRequest(cmd *cobra.Command, name string) (*portainer.KubernetesStackDeployRequest, error) {
+	for _, flag := range []string{"env", "swarm"} {
+		if cmd.Flags().Changed(flag) {
+			return nil, fmt.Errorf("--%s is not supported with --kubernetes", flag)
+		}
+	}
+
+	flags := cmd.Flags()
+	filePath, err := flags.GetString("file")
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := flags.GetString("namespace")
+	if err != nil {
+		return nil, err
+	}
+	composeFormat, err := flags.GetBool("compose-format")
+	if err != nil {
+		return nil, err
+	}
+	repositoryURL, err := flags.GetString("repository-url")
+	if err != nil {
+		return nil, err
+	}
+	repositoryRef, err := flags.GetString("repository-ref")
+	if err != nil {
+		return nil, err
+	}
+	repositoryFile, err := flags.GetString("repository-file")
+	if err != nil {
+		return nil, err
+	}
+	username, err := flags.GetString("repository-username")
+	if err != nil {
+		return nil, err
+	}
+	password, err := flags.GetString("repository-password")
+	if err != nil {
+		return nil, err
+	}
+
+	if namespace == "" {
+		return nil, fmt.Errorf("--namespace must not be empty")
+	}
+
+	req := &portainer.KubernetesStackDeployRequest{
+		StackName:     name,
+		Namespace:     namespace,
+		ComposeFormat: composeFormat,
+	}
+
+	switch {
+	case filePath != "" && repositoryURL != "":
+		return nil, fmt.Errorf("--file and --repository-url cannot be used together")
+	case repositoryURL != "":
+		if repositoryFile == "" {
+			return nil, fmt.Errorf("--repository-file is required with --repository-url")
+		}
+		if password != "" && username == "" {
+			return nil, fmt.Errorf("--repository-password requires --repository-username")
+		}
+		req.RepositoryURL = repositoryURL
+		req.RepositoryReferenceName = repositoryRef
+		req.ManifestFile = repositoryFile
+		req.RepositoryAuthentication = username != ""
+		req.RepositoryUsername = username
+		req.RepositoryPassword = password
+	case filePath != "":
+		for _, flag := range []string{"repository-ref", "repository-file", "repository-username", "repository-password"} {
+			if flags.Changed(flag) {
+				return nil, fmt.Errorf("--%s requires --repository-url", flag)
+			}
+		}
+		content, err := readStackFile(filePath, os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		if content, err = renderStackTemplate(cmd, filePath, content); err != nil {
+			return nil, err
+		}
+		req.StackFileContent = content
+	default:
+		return nil, fmt.Errorf("--file or --repository-url is required")
+	}
+
+	return req, nil
+}
+
+// checkKubernetesDeployFlags rejects Kubernetes-only flags when deploying a
+// Docker stack
+func checkKubernetesDeployFlags(cmd *cobra.Command) error {
+	for _, flag := range kubernetesDeployFlags {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s requires --kubernetes", flag)
+		}
+	}
+	return nil
+}
+
+// addKubernetesDeployFlags adds the Kubernetes options of stacks deploy
+func addKubernetesDeployFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("kubernetes", false, "Deploy a Kubernetes stack")
+	cmd.Flags().String("namespace", "default", "Kubernetes namespace to deploy to")
+	cmd.Flags().Bool("compose-format", false, "Convert a Compose file to Kubernetes manifests")
+	cmd.Flags().String("repository-url", "", "Deploy from this Git repository instead of --file")
+	cmd.Flags().String("repository-ref", "", "Git reference to deploy (default: the repository's default branch)")
+	cmd.Flags().String("repository-file", "", "Path of the manifest in the repository")
+	cmd.Flags().String("repository-username", "", "Username for the Git repository")
+	cmd.Flags().String("repository-password", "", "Password or token for the Git repository")
+}
+
+func init() {
+	addKubernetesDeployFlags(stacksDeployCmd)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newKubernetesDeployTestCmd(flags map[string]string) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("file", "", "")
+	cmd.Flags().StringArray("env", nil, "")
+	cmd.Flags().Bool("swarm", false, "")
+	addKubernetesDeployFlags(cmd)
+	addTemplateFlags(cmd)
+	for name, value := range flags {
+		cmd.Flags().Set(name, value)
+	}
+	return cmd
+}
+
+func TestKubernetesDeployRequest(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "web.yaml")
+	if err := os.WriteFile(manifest, []byte("kind: Deployment"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := kubernetesDeployRequest(newKubernetesDeployTestCmd(map[string]string{
+		"file":           manifest,
+		"namespace":      "web",
+		"compose-format": "true",
+	}), "web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.StackName != "web" || req.Namespace != "web" || !req.ComposeFormat || req.StackFileContent != "kind: Deployment" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+
+	req, err = kubernetesDeployRequest(newKubernetesDeployTestCmd(map[string]string{
+		"repository-url":      "https://github.com/acme/web",
+		"repository-file":     "k8s/web.yaml",
+		"repository-username": "bot",
+		"repository-password": "token",
+	}), "web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Namespace != "default" || req.ManifestFile != "k8s/web.yaml" || !req.RepositoryAuthentication || req.StackFileContent != "" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+
+	invalid := []map[string]string{
+		{},
+		{"file": manifest, "repository-url": "https://github.com/acme/web"},
+		{"repository-url": "https://github.com/acme/web"},
+		{"file": manifest, "repository-ref": "main"},
+		{"file": manifest, "env": "A=1"},
+		{"file": manifest, "namespace": ""},
+	}
+	for _, flags := range invalid {
+		if _, err := kubernetesDeployRequest(newKubernetesDeployTestCmd(flags), "web"); err == nil {
+			t.Errorf("expected error for %v", flags)
+		}
+	}
+}
+
+func TestCheckKubernetesDeployFlags(t *testing.T) {
+	if err := checkKubernetesDeployFlags(newKubernetesDeployTestCmd(nil)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkKubernetesDeployFlags(newKubernetesDeployTestCmd(map[string]string{"namespace": "web"})); err == nil {
+		t.Error("expected error for --namespace without --kubernetes")
+	}
+}
+
+func TestStacksDeployKubernetesRepositoryCommand(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/stacks") {
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Write([]byte(`{"Id":7,"Name":"web","Type":3,"EndpointId":4}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	err := executeCommand(t, server.URL, "stacks", "deploy", "--endpoint", "4", "--name", "web", "--kubernetes", "--namespace", "web",
+		"--repository-url", "https://github.com/acme/web", "--repository-file", "k8s/web.yaml", "-q")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body["RepositoryURL"] != "https://github.com/acme/web" || body["ManifestFile"] != "k8s/web.yaml" {
+		t.Errorf("unexpected request body %v", body)
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/robversluis/portainer-cli/internal/secrets"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+// dryRunSecret replaces resolved secrets in the requests printed by
+// --dry-run
+const dryRunSecret = "****"
+
+var (
+	secretResolversOnce sync.Once
+	secretResolvers     secrets.Resolvers
+)
+
+// getSecretResolvers returns the resolvers of secret references in --env
+// values, reading their settings (such as ~/.vault-token) on first use
+func getSecretResolvers() secrets.Resolvers {
+	secretResolversOnce.Do(func() {
+		secretResolvers = secrets.Default()
+	})
+	return secretResolvers
+}
+
+// resolveStackSecrets replaces secret references such as
+// vault://secret/data/web#password in env values with the secret they point
+// at, so secrets are only known at deploy time. Other values are kept. With
+// --dry-run the secrets are still resolved, to check the references, but
+// masked in the printed request.
+func resolveStackSecrets(env []portainer.StackEnv) ([]portainer.StackEnv, error) {
+	resolved := make([]portainer.StackEnv, len(env))
+	for i, e := range env {
+		if !strings.Contains(e.Value, "://") {
+			resolved[i] = e
+			continue
+		}
+		resolvers := getSecretResolvers()
+		ref, ok := resolvers.Parse(e.Value)
+		if !ok {
+			resolved[i] = e
+			continue
+		}
+
+		value, err := resolvers.Resolve(context.Background(), e.Value)
+		if err != nil {
+			return nil, fmt.Errorf("env %s: %w", e.Name, err)
+		}
+		if GetVerbose() {
+			fmt.Fprintf(os.Stderr, "Env %s: resolved from %s\n", e.Name, ref)
+		}
+		if GetDryRun() {
+			value = dryRunSecret
+		}
+		resolved[i] = portainer.StackEnv{Name: e.Name, Value: value}
+	}
+	return resolved, nil
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"github.com/robversluis/portainer-cli/internal/compose"
+	"github.com/spf13/cobra"
+)
+
+// renderStackTemplate renders the stack file as a Go template when --set or
+// --values is given; without them the file is used as it is, so literal
+// braces in existing stack files keep working
+func renderStackTemplate(cmd *cobra.Command, filePath, content string) (string, error) {
+	assignments, err := cmd.Flags().GetStringArray("set")
+	if err != nil {
+		return "", err
+	}
+	valuesFiles, err := cmd.Flags().GetStringArray("values")
+	if err != nil {
+		return "", err
+	}
+	if len(assignments) == 0 && len(valuesFiles) == 0 {
+		return content, nil
+	}
+
+	values := compose.Values{}
+	if err := compose.ReadValues(values, valuesFiles...); err != nil {
+		return "", err
+	}
+	for _, assignment := range assignments {
+		if err := compose.SetValue(values, assignment); err != nil {
+			return "", err
+		}
+	}
+
+	rendered, err := compose.Render(stackFileName(filePath), []byte(content), values)
+	if err != nil {
+		return "", err
+	}
+	return string(rendered), nil
+}
+
+func addTemplateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("set", []string{}, "Render the stack file as a Go template with this value (KEY=VALUE, dotted keys for nested values)")
+	cmd.Flags().StringArray("values", []string{}, "Render the stack file as a Go template with the values of this YAML file (repeatable, later files win; --set overrides)")
+}
+
+func init() {
+	addTemplateFlags(stacksDeployCmd)
+	addTemplateFlags(stacksUpdateCmd)
+	addTemplateFlags(stacksValidateCmd)
+}
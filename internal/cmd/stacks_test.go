@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robversluis/portainer-cli/internal/compose"
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+func TestReadStackFile(t *testing.T) {
+	compose := "services:\n  web:\n    image: nginx\n"
+
+	content, err := readStackFile("-", strings.NewReader(compose))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != compose {
+		t.Errorf("unexpected content %q", content)
+	}
+
+	if _, err := readStackFile("-", strings.NewReader(" \n")); err == nil {
+		t.Error("expected error for empty stdin")
+	}
+
+	path := filepath.Join(t.TempDir(), "docker-compose.yml")
+	if err := os.WriteFile(path, []byte(compose), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	content, err = readStackFile(path, strings.NewReader("ignored"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != compose {
+		t.Errorf("unexpected content %q", content)
+	}
+
+	if _, err := readStackFile(filepath.Join(t.TempDir(), "missing.yml"), nil); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
+
+func TestPrintComposeIssues(t *testing.T) {
+	issues := compose.Lint([]byte("services:\n  web:\n    build: .\n"), compose.Options{})
+
+	var out bytes.Buffer
+	printComposeIssues(&out, "<stdin>", issues)
+	expected := "<stdin>:3:5: error: service web: build is not supported, push the image to a registry and use image\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+	if countComposeErrors(issues) != 1 {
+		t.Errorf("expected 1 error, got %d", countComposeErrors(issues))
+	}
+}
+
+func TestParseStackEnvFlags(t *testing.T) {
+	env, err := parseStackEnvFlags([]string{"TAG=1.4", "URL=http://x/?a=b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m := stackEnvMap(env); len(m) != 2 || m["URL"] != "http://x/?a=b" {
+		t.Errorf("unexpected env %v", m)
+	}
+
+	if _, err := parseStackEnvFlags([]string{"TAG"}); err == nil {
+		t.Error("expected error for missing =")
+	}
+}
+
+func TestRewriteStackImages(t *testing.T) {
+	content := "services:\n  web:\n    image: nginx:1.25\n"
+	profile := &config.Profile{RegistryMirror: "mirror.internal/hub"}
+
+	cmd := &cobra.Command{}
+	addImageRewriteFlags(cmd)
+	rewritten, err := rewriteStackImages(cmd, profile, content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(rewritten, "image: mirror.internal/hub/library/nginx:1.25") {
+		t.Errorf("expected the profile mirror to be used, got %q", rewritten)
+	}
+
+	// A flag replaces the profile settings
+	cmd = &cobra.Command{}
+	addImageRewriteFlags(cmd)
+	_ = cmd.Flags().Set("image-prefix", "registry.internal")
+	rewritten, err = rewriteStackImages(cmd, profile, content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(rewritten, "image: registry.internal/nginx:1.25") {
+		t.Errorf("expected the image prefix to be used, got %q", rewritten)
+	}
+
+	_ = cmd.Flags().Set("registry-mirror", "mirror.internal")
+	if _, err := rewriteStackImages(cmd, profile, content); err == nil {
+		t.Error("expected error for both a mirror and a prefix")
+	}
+}
+
+func TestUpdateExistingStack(t *testing.T) {
+	var update map[string]interface{}
+	var updatePath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`[{"Id":7,"Name":"web","Type":2,"EndpointId":1,"Env":[{"name":"TAG","value":"1.24"}]},{"Id":8,"Name":"api","Type":1,"EndpointId":1}]`))
+		case http.MethodPut:
+			updatePath = r.URL.Path
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := portainer.NewClient(&portainer.Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service := portainer.NewStackService(client)
+
+	missing, err := findStack(service, 1, "db")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if missing != nil {
+		t.Errorf("expected no stack, got %+v", missing)
+	}
+
+	web, err := findStack(service, 1, "web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if web == nil || web.Id != 7 {
+		t.Fatalf("expected stack 7, got %+v", web)
+	}
+	if err := updateExistingStack(service, web, false, "services: {}\n", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updatePath != "/api/stacks/7" {
+		t.Errorf("unexpected update path %s", updatePath)
+	}
+	if update["stackFileContent"] != "services: {}\n" {
+		t.Errorf("unexpected stack file %v", update["stackFileContent"])
+	}
+	// Without --env the stack keeps its variables
+	if env, ok := update["env"].([]interface{}); !ok || len(env) != 1 {
+		t.Errorf("expected the existing variables, got %v", update["env"])
+	}
+
+	api, err := findStack(service, 1, "api")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = updateExistingStack(service, api, false, "services: {}\n", nil)
+	if err == nil || !strings.Contains(err.Error(), "Swarm stack") {
+		t.Errorf("expected a stack type error, got %v", err)
+	}
+}
+
+func TestStacksUpdateLintsByStackType(t *testing.T) {
+	manifest := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n"
+	composeFile := "services:\n  web:\n    image: nginx\n    restart: always\n"
+
+	tests := []struct {
+		name      string
+		stackType int
+		content   string
+		warning   bool
+	}{
+		{"kubernetes manifests are not linted", portainer.StackTypeKubernetes, manifest, false},
+		{"swarm rules apply to swarm stacks", portainer.StackTypeSwarm, composeFile, true},
+		{"compose rules apply to compose stacks", portainer.StackTypeCompose, composeFile, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/api/stacks/5":
+					json.NewEncoder(w).Encode(portainer.Stack{Id: 5, Name: "web", Type: tt.stackType, EndpointId: 1})
+				case r.Method == http.MethodPut && r.URL.Path == "/api/stacks/5":
+					updated = true
+					w.Write([]byte(`{"Id":5}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			file := filepath.Join(t.TempDir(), "stack.yml")
+			if err := os.WriteFile(file, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var err error
+			stderr := captureStderr(t, func() {
+				err = executeCommand(t, server.URL, "stacks", "update", "5", "--endpoint", "1", "--file", file)
+			})
+			if err != nil || !updated {
+				t.Fatalf("expected the stack to be updated, got %v (%s)", err, stderr)
+			}
+			if warned := strings.Contains(stderr, "restart is ignored by Swarm"); warned != tt.warning {
+				t.Errorf("expected Swarm warning %v, got %q", tt.warning, stderr)
+			}
+		})
+	}
+}
+
+// captureStderr returns what fn writes to os.Stderr
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
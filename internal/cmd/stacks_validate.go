@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/compose"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var stacksValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check a stack file before deploying it",
+	Long: `Check a Docker Compose file against the Compose specification and what
+Portainer can deploy, without contacting Portainer. Problems are reported
+with their line:
+  - YAML syntax and schema errors, such as unknown keys
+  - interpolated variables that are not set by --env
+  - directives Portainer cannot deploy, such as build: or include:, since
+    only the stack file is sent to Portainer
+  - with --swarm, options that Swarm ignores, such as container_name
+
+The command fails when errors are found; warnings do not fail it. The same
+checks run before "stacks deploy" and "stacks update".
+
+Examples:
+  portainer-cli stacks validate --file docker-compose.yml
+  portainer-cli stacks validate --file stack.yml --swarm --env TAG=1.4.2
+  envsubst < compose.tmpl.yml | portainer-cli stacks validate --file -`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{offlineAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filePath, err := cmd.Flags().GetString("file")
+		if err != nil {
+			return err
+		}
+		envVars, err := cmd.Flags().GetStringArray("env")
+		if err != nil {
+			return err
+		}
+		swarm, err := cmd.Flags().GetBool("swarm")
+		if err != nil {
+			return err
+		}
+
+		env, err := parseStackEnvFlags(envVars)
+		if err != nil {
+			return err
+		}
+		content, err := readStackFile(filePath, os.Stdin)
+		if err != nil {
+			return err
+		}
+		if content, err = renderStackTemplate(cmd, filePath, content); err != nil {
+			return err
+		}
+
+		issues := compose.Lint([]byte(content), compose.Options{Env: stackEnvMap(env), Swarm: swarm})
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			if issues == nil {
+				issues = []compose.Issue{}
+			}
+			if err := newFormatter(format).Format(issues); err != nil {
+				return err
+			}
+		default:
+			printComposeIssues(os.Stdout, stackFileName(filePath), issues)
+			if len(issues) == 0 && !GetQuiet() {
+				fmt.Printf("%s: no issues found\n", stackFileName(filePath))
+			}
+		}
+
+		if errors := countComposeErrors(issues); errors > 0 {
+			return fmt.Errorf("%s: %d error(s) found", stackFileName(filePath), errors)
+		}
+		return nil
+	},
+}
+
+// lintStackFile checks a stack file before it is deployed unless
+// --skip-validation is set. Warnings are printed to stderr; errors abort the
+// deployment.
+func lintStackFile(cmd *cobra.Command, filePath, content string, env []portainer.StackEnv, swarm bool) error {
+	skip, err := cmd.Flags().GetBool("skip-validation")
+	if err != nil || skip {
+		return err
+	}
+
+	issues := compose.Lint([]byte(content), compose.Options{Env: stackEnvMap(env), Swarm: swarm})
+	if errors := countComposeErrors(issues); errors > 0 {
+		printComposeIssues(os.Stderr, stackFileName(filePath), issues)
+		return fmt.Errorf("%s: %d error(s) found; fix them, or use --skip-validation to deploy anyway", stackFileName(filePath), errors)
+	}
+	if !GetQuiet() {
+		printComposeIssues(os.Stderr, stackFileName(filePath), issues)
+	}
+	return nil
+}
+
+// printComposeIssues prints issues the way compilers do, as
+// file:line:column: severity: message
+func printComposeIssues(w io.Writer, name string, issues []compose.Issue) {
+	for _, issue := range issues {
+		severity := string(issue.Severity)
+		if issue.Severity == compose.SeverityError {
+			severity = output.Colorize(severity, output.ColorRed)
+		} else {
+			severity = output.Colorize(severity, output.ColorYellow)
+		}
+
+		position := name
+		if issue.Line > 0 {
+			position = fmt.Sprintf("%s:%d:%d", name, issue.Line, issue.Column)
+		}
+		fmt.Fprintf(w, "%s: %s: %s\n", position, severity, issue.Message)
+	}
+}
+
+func countComposeErrors(issues []compose.Issue) int {
+	count := 0
+	for _, issue := range issues {
+		if issue.Severity == compose.SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
+// stackFileName names the --file flag value in messages
+func stackFileName(filePath string) string {
+	if filePath == "-" {
+		return "<stdin>"
+	}
+	return filePath
+}
+
+// parseStackEnvFlags parses --env KEY=VALUE flags
+func parseStackEnvFlags(envVars []string) ([]portainer.StackEnv, error) {
+	var env []portainer.StackEnv
+	for _, envVar := range envVars {
+		parts := strings.SplitN(envVar, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid env format: %s (expected KEY=VALUE)", envVar)
+		}
+		env = append(env, portainer.StackEnv{Name: parts[0], Value: parts[1]})
+	}
+	return env, nil
+}
+
+func stackEnvMap(env []portainer.StackEnv) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, e := range env {
+		m[e.Name] = e.Value
+	}
+	return m
+}
+
+func init() {
+	stacksCmd.AddCommand(stacksValidateCmd)
+
+	stacksValidateCmd.Flags().String("file", "", "Path to stack file, or - to read it from stdin (required)")
+	stacksValidateCmd.Flags().StringArray("env", []string{}, "Environment variables for interpolation (KEY=VALUE)")
+	stacksValidateCmd.Flags().Bool("swarm", false, "Check the file for a Swarm stack")
+	_ = stacksValidateCmd.MarkFlagRequired("file")
+
+	stacksDeployCmd.Flags().Bool("skip-validation", false, "Deploy without checking the stack file first")
+	stacksUpdateCmd.Flags().Bool("skip-validation", false, "Update without checking the stack file first")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+// showStats is the global --stats flag
+var showStats bool
+
+// commandStats collects the API traffic of every client of the command
+var commandStats = &portainer.Stats{}
+
+// commandSummary is the --stats summary of a command
+type commandSummary struct {
+	Command string `json:"command"`
+	portainer.StatsSummary
+	WallTime float64 `json:"wall_time_seconds"`
+}
+
+// printStats writes the --stats summary of command to w: a line of text, or
+// with -o json a JSON object so scripts can separate it from the output
+func printStats(w io.Writer, command string, elapsed time.Duration) error {
+	summary := commandSummary{
+		Command:      command,
+		StatsSummary: commandStats.Summary(),
+		WallTime:     elapsed.Seconds(),
+	}
+
+	if getOutputFormat() == output.FormatJSON {
+		data, err := json.Marshal(map[string]commandSummary{"stats": summary})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "Stats: %s: %s, %s sent, %s received in %s\n",
+		summary.Command,
+		pluralize(summary.Requests, "request", "requests")+retrySuffix(summary.Retries),
+		output.FormatSize(summary.BytesSent),
+		output.FormatSize(summary.BytesReceived),
+		elapsed.Round(time.Millisecond))
+	return err
+}
+
+func retrySuffix(retries int64) string {
+	if retries == 0 {
+		return ""
+	}
+	return " (" + pluralize(retries, "retry", "retries") + ")"
+}
+
+func pluralize(n int64, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestPrintStats(t *testing.T) {
+	savedStats, savedFormat := commandStats, outputFormat
+	defer func() { commandStats, outputFormat = savedStats, savedFormat }()
+	commandStats = &portainer.Stats{}
+
+	outputFormat = "table"
+	var buf bytes.Buffer
+	if err := printStats(&buf, "portainer-cli stacks list", 1500*time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "Stats: portainer-cli stacks list: 0 requests, 0 B sent, 0 B received in 1.5s\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	outputFormat = "json"
+	buf.Reset()
+	if err := printStats(&buf, "portainer-cli stacks list", 2*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var trailer map[string]map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &trailer); err != nil {
+		t.Fatalf("expected a JSON trailer, got %q: %v", buf.String(), err)
+	}
+	stats := trailer["stats"]
+	if stats["command"] != "portainer-cli stacks list" || stats["requests"] != 0.0 || stats["wall_time_seconds"] != 2.0 {
+		t.Errorf("unexpected stats: %v", stats)
+	}
+}
+
+func TestRetrySuffix(t *testing.T) {
+	tests := map[int64]string{0: "", 1: " (1 retry)", 3: " (3 retries)"}
+	for retries, expected := range tests {
+		if got := retrySuffix(retries); got != expected {
+			t.Errorf("retrySuffix(%d) = %q, expected %q", retries, got, expected)
+		}
+	}
+}
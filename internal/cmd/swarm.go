@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var swarmCmd = &cobra.Command{
+	Use:   "swarm",
+	Short: "Manage Swarm clusters",
+	Long: `Show the swarm of Swarm environments, its nodes, and the tokens to join it.
+
+The commands talk to the Docker engine of the environment, which must be a
+Swarm manager.`,
+}
+
+var swarmInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show swarm information",
+	Long:  `Display the swarm of an environment: its ID, node counts, and manager addresses.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, swarmService, err := newSwarmService(cmd)
+		if err != nil {
+			return err
+		}
+
+		info, err := swarmService.Info(endpointID)
+		if err != nil {
+			return swarmError(endpointID, err)
+		}
+		if GetDryRun() {
+			return nil
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			return newFormatter(format).Format(info)
+		default:
+			fmt.Printf("Swarm ID:  %s\n", info.ID)
+			fmt.Printf("Created:   %s\n", info.CreatedAt)
+			fmt.Printf("Updated:   %s\n", info.UpdatedAt)
+			fmt.Printf("Nodes:     %d (%d managers, %d workers)\n", info.Nodes, info.Managers, info.Nodes-info.Managers)
+			fmt.Printf("Node ID:   %s\n", info.NodeID)
+			fmt.Printf("Node Addr: %s\n", info.NodeAddr)
+			if len(info.ManagerAddrs) > 0 {
+				fmt.Printf("Managers:  %s\n", strings.Join(info.ManagerAddrs, ", "))
+			}
+			return nil
+		}
+	},
+}
+
+var swarmJoinTokenCmd = &cobra.Command{
+	Use:   "join-token <worker|manager>",
+	Short: "Show the token to join the swarm",
+	Long: `Print the command that joins a node to the swarm as a worker or manager.
+Use --quiet to print only the token, and --rotate to replace the token first
+so a leaked token can no longer be used.
+
+Examples:
+  portainer-cli swarm join-token worker --endpoint 2
+  portainer-cli swarm join-token manager --endpoint 2 --rotate -q`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{portainer.SwarmRoleWorker, portainer.SwarmRoleManager},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		role := args[0]
+		if role != portainer.SwarmRoleWorker && role != portainer.SwarmRoleManager {
+			return fmt.Errorf("unknown role '%s': use worker or manager", role)
+		}
+		rotate, err := cmd.Flags().GetBool("rotate")
+		if err != nil {
+			return err
+		}
+
+		endpointID, swarmService, err := newSwarmService(cmd)
+		if err != nil {
+			return err
+		}
+
+		if rotate {
+			if err := swarmService.RotateJoinToken(endpointID, role); err != nil {
+				return swarmError(endpointID, err)
+			}
+			if !GetQuiet() && !GetDryRun() {
+				fmt.Printf("Successfully rotated %s join token.\n\n", role)
+			}
+		}
+
+		token, err := swarmService.JoinToken(endpointID, role)
+		if err != nil {
+			return swarmError(endpointID, err)
+		}
+		if GetDryRun() {
+			return nil
+		}
+		if GetQuiet() {
+			fmt.Println(token)
+			return nil
+		}
+
+		info, err := swarmService.Info(endpointID)
+		if err != nil {
+			return swarmError(endpointID, err)
+		}
+		addr := "<manager-address>:2377"
+		if len(info.ManagerAddrs) > 0 {
+			addr = info.ManagerAddrs[0]
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			return newFormatter(format).Format(map[string]string{
+				"Role":    role,
+				"Token":   token,
+				"Address": addr,
+			})
+		default:
+			fmt.Printf("To add a %s to this swarm, run the following command:\n\n", role)
+			fmt.Printf("    docker swarm join --token %s %s\n\n", token, addr)
+			return nil
+		}
+	},
+}
+
+var swarmNodesCmd = &cobra.Command{
+	Use:   "nodes",
+	Short: "List swarm nodes",
+	Long: `List the nodes of the swarm with their role, state and availability, as
+"docker node ls" does, followed by a summary of the cluster membership.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, swarmService, err := newSwarmService(cmd)
+		if err != nil {
+			return err
+		}
+
+		nodes, err := swarmService.Nodes(endpointID)
+		if err != nil {
+			return swarmError(endpointID, err)
+		}
+		if GetDryRun() {
+			return nil
+		}
+
+		if GetQuiet() {
+			for _, node := range nodes {
+				fmt.Println(node.ID)
+			}
+			return nil
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			return newFormatter(format).Format(nodes)
+		default:
+			table := output.NewTableData([]string{"ID", "Hostname", "Status", "Availability", "Manager Status", "Engine Version"})
+			for _, node := range nodes {
+				table.AddRow([]string{
+					node.ID,
+					node.Description.Hostname,
+					node.Status.State,
+					node.Spec.Availability,
+					node.ManagerState(),
+					node.Description.Engine.EngineVersion,
+				})
+			}
+			if err := output.PrintTable(*table); err != nil {
+				return err
+			}
+			fmt.Printf("\n%s\n", swarmNodesSummary(nodes))
+			return nil
+		}
+	},
+}
+
+// swarmNodesSummary counts the nodes per role and the nodes that are not
+// ready or not available for tasks, e.g. "3 nodes: 1 manager, 2 workers;
+// 1 down; 1 drained"
+func swarmNodesSummary(nodes []portainer.SwarmNode) string {
+	var managers, down, drained int64
+	for _, node := range nodes {
+		if node.Spec.Role == portainer.SwarmRoleManager {
+			managers++
+		}
+		if node.Status.State != "ready" {
+			down++
+		}
+		if node.Spec.Availability != "active" {
+			drained++
+		}
+	}
+
+	total := int64(len(nodes))
+	summary := fmt.Sprintf("%s: %s, %s",
+		pluralize(total, "node", "nodes"),
+		pluralize(managers, "manager", "managers"),
+		pluralize(total-managers, "worker", "workers"))
+	if down > 0 {
+		summary += fmt.Sprintf("; %d down", down)
+	}
+	if drained > 0 {
+		summary += fmt.Sprintf("; %d drained or paused", drained)
+	}
+	return summary
+}
+
+// newSwarmService returns the --endpoint of a swarm command and a swarm
+// service for it
+func newSwarmService(cmd *cobra.Command) (int, *portainer.SwarmService, error) {
+	endpointID, err := cmd.Flags().GetInt("endpoint")
+	if err != nil {
+		return 0, nil, err
+	}
+	if endpointID == 0 {
+		return 0, nil, fmt.Errorf("--endpoint flag is required")
+	}
+
+	profile, err := ResolveProfile(cmd)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+
+	c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return endpointID, portainer.NewSwarmService(c), nil
+}
+
+// swarmError names the environment when it is not a Swarm manager
+func swarmError(endpointID int, err error) error {
+	if errors.Is(err, portainer.ErrNotSwarmManager) {
+		return fmt.Errorf("environment %d is not a Swarm manager", endpointID)
+	}
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(swarmCmd)
+	swarmCmd.AddCommand(swarmInfoCmd)
+	swarmCmd.AddCommand(swarmJoinTokenCmd)
+	swarmCmd.AddCommand(swarmNodesCmd)
+
+	for _, c := range []*cobra.Command{swarmInfoCmd, swarmJoinTokenCmd, swarmNodesCmd} {
+		c.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+		_ = c.MarkFlagRequired("endpoint")
+	}
+	swarmJoinTokenCmd.Flags().Bool("rotate", false, "Replace the join token before printing it")
+}
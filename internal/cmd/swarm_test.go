@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func TestSwarmNodesSummary(t *testing.T) {
+	node := func(role, state, availability string) portainer.SwarmNode {
+		var n portainer.SwarmNode
+		n.Spec.Role, n.Status.State, n.Spec.Availability = role, state, availability
+		return n
+	}
+
+	nodes := []portainer.SwarmNode{
+		node("manager", "ready", "active"),
+		node("worker", "ready", "active"),
+		node("worker", "down", "drain"),
+	}
+	expected := "3 nodes: 1 manager, 2 workers; 1 down; 1 drained or paused"
+	if got := swarmNodesSummary(nodes); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	expected = "1 node: 1 manager, 0 workers"
+	if got := swarmNodesSummary(nodes[:1]); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
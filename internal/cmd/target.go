@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// targetName is the global --target flag
+var targetName string
+
+// applyTarget sets the --profile flag, and the --endpoint flag of
+// endpoint-scoped commands, from the target selected with --target or
+// PORTAINER_TARGET. A target from the environment gives way to explicit
+// --profile, --endpoint and --endpoint-name flags; the --target flag cannot
+// be combined with them.
+func applyTarget(cmd *cobra.Command) error {
+	name := flagOrEnv(cmd, "target", "target")
+	if name == "" {
+		return nil
+	}
+	fromFlag := cmd.Flags().Changed("target")
+
+	for _, conflicting := range []string{"profile", "endpoint", "endpoint-name"} {
+		if !cmd.Flags().Changed(conflicting) {
+			continue
+		}
+		if fromFlag {
+			return fmt.Errorf("--target and --%s cannot be used together", conflicting)
+		}
+		return nil
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	target, err := cfg.GetTarget(name)
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Flags().Set("profile", target.Profile); err != nil {
+		return err
+	}
+	if target.Endpoint == 0 {
+		return nil
+	}
+	// Commands without an environment, or running on several, only use the
+	// profile of the target
+	if flag := cmd.Flags().Lookup("endpoint"); flag != nil && isEndpointIDFlag(flag) && !fansOut(cmd) {
+		return cmd.Flags().Set("endpoint", strconv.Itoa(target.Endpoint))
+	}
+	return nil
+}
+
+// isEndpointIDFlag reports whether an --endpoint flag takes environment IDs
+func isEndpointIDFlag(flag *pflag.Flag) bool {
+	switch flag.Value.Type() {
+	case "int", "intSlice":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestApplyTarget(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg := &config.Config{
+		CurrentProfile: "dev",
+		Profiles: map[string]*config.Profile{
+			"dev":  {URL: "https://dev.example.com", APIKey: "dev-key"},
+			"prod": {URL: "https://prod.example.com", APIKey: "prod-key"},
+		},
+		Targets: map[string]*config.Target{
+			"prod-web": {Profile: "prod", Endpoint: 4},
+		},
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().String("target", "", "")
+		cmd.Flags().String("profile", "", "")
+		cmd.Flags().String("endpoint-name", "", "")
+		cmd.Flags().Int("endpoint", 0, "")
+		cmd.Flags().Bool("all-endpoints", false, "")
+		cmd.Annotations = map[string]string{fanOutFlag: "all-endpoints"}
+		if err := cmd.Flags().Parse(args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return cmd
+	}
+
+	cmd := newCmd("--target", "prod-web")
+	if err := applyTarget(cmd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile, _ := cmd.Flags().GetString("profile"); profile != "prod" {
+		t.Errorf("expected profile prod, got %s", profile)
+	}
+	if id, _ := cmd.Flags().GetInt("endpoint"); id != 4 {
+		t.Errorf("expected endpoint 4, got %d", id)
+	}
+
+	if err := applyTarget(newCmd("--target", "prod-web", "--endpoint", "2")); err == nil {
+		t.Error("expected error for --target with --endpoint")
+	}
+	if err := applyTarget(newCmd("--target", "missing")); err == nil {
+		t.Error("expected error for unknown target")
+	}
+
+	// A target from the environment gives way to explicit flags
+	viper.Set("target", "prod-web")
+	defer viper.Set("target", "")
+	cmd = newCmd("--endpoint", "2")
+	if err := applyTarget(cmd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+		t.Errorf("expected no profile, got %s", profile)
+	}
+
+	// Commands running on all environments only take the profile
+	viper.Set("target", "")
+	cmd = newCmd("--target", "prod-web", "--all-endpoints")
+	if err := applyTarget(cmd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id, _ := cmd.Flags().GetInt("endpoint"); id != 0 {
+		t.Errorf("expected no endpoint with --all-endpoints, got %d", id)
+	}
+	if profile, _ := cmd.Flags().GetString("profile"); profile != "prod" {
+		t.Errorf("expected profile prod, got %s", profile)
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var usersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "Manage users",
+	Long:  `Manage Portainer users and their access to environments.`,
+}
+
+var usersListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List users",
+	Long: `Display the Portainer users with their ID and role. Servers that paginate
+the list are paged through; use --no-paginate to fetch only the first page.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listOpts, err := getListOptions(cmd)
+		if err != nil {
+			return err
+		}
+		noPaginate, err := cmd.Flags().GetBool("no-paginate")
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		userService := portainer.NewUserService(c)
+		var users []portainer.User
+		if noPaginate {
+			var total int
+			if users, total, err = userService.ListPage(0, portainer.PageSize); err != nil {
+				return err
+			}
+			warnFirstPage(len(users), total, "users")
+		} else if users, err = userService.List(); err != nil {
+			return err
+		}
+
+		if GetQuiet() {
+			return printQuiet(listOpts, users, func(item portainer.User) string {
+				return strconv.Itoa(item.Id)
+			})
+		}
+
+		format := getOutputFormat()
+		switch format {
+		case output.FormatJSON, output.FormatYAML:
+			items, err := listOpts.applyItems(users)
+			if err != nil {
+				return err
+			}
+			return newFormatter(format).Format(items)
+		default:
+			table := output.NewTableData([]string{"ID", "Username", "Role"})
+			for _, user := range users {
+				table.AddRow([]string{strconv.Itoa(user.Id), user.Username, user.RoleString()})
+			}
+			if err := listOpts.applyTable(table, users); err != nil {
+				return err
+			}
+			return output.PrintTable(*table)
+		}
+	},
+}
+
+// warnFirstPage tells that a list fetched with --no-paginate only holds the
+// first page of total items. A negative total means the server returned
+// every item.
+func warnFirstPage(count, total int, resource string) {
+	if total > count && !GetQuiet() {
+		fmt.Fprintln(os.Stderr, output.Warning(fmt.Sprintf("showing the first %d of %d %s; omit --no-paginate to list all", count, total, resource)))
+	}
+}
+
+var usersRolesCmd = &cobra.Command{
+	Use:   "roles",
+	Short: "Manage user roles in environments",
+	Long:  `Assign RBAC roles to users in environments (Business Edition).`,
+}
+
+var usersRolesSetCmd = &cobra.Command{
+	Use:   "set [user]",
+	Short: "Set the role of a user in an environment",
+	Long: `Give a user, by ID or username, access to an environment with an RBAC role,
+by ID or name (see "roles list"). A role the user already has in the
+environment is replaced; the access of other users and teams is kept.
+
+Example:
+  portainer-cli users roles set alice --endpoint 1 --role operator
+  portainer-cli users roles set 7 --endpoint-name production --role "Read-only user"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		roleName, err := cmd.Flags().GetString("role")
+		if err != nil {
+			return err
+		}
+		if roleName == "" {
+			return fmt.Errorf("--role flag is required")
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		userIDs, err := resolveUserIDs(c, args)
+		if err != nil {
+			return err
+		}
+		role, err := resolveRole(c, roleName)
+		if err != nil {
+			return err
+		}
+
+		if err := portainer.NewEnvironmentService(c).SetUserRole(endpointID, userIDs[0], role.Id); err != nil {
+			return err
+		}
+
+		if !GetQuiet() && !GetDryRun() {
+			fmt.Printf("User %s has role %s in environment %d\n", args[0], role.Name, endpointID)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(usersCmd)
+	usersCmd.AddCommand(usersListCmd)
+	usersCmd.AddCommand(usersRolesCmd)
+	usersRolesCmd.AddCommand(usersRolesSetCmd)
+
+	usersRolesSetCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	usersRolesSetCmd.Flags().String("role", "", "Role ID or name (required)")
+	_ = usersRolesSetCmd.MarkFlagRequired("endpoint")
+	_ = usersRolesSetCmd.MarkFlagRequired("role")
+
+	addListFlags(usersListCmd)
+	usersListCmd.Flags().Bool("no-paginate", false, "Fetch only the first page of users from servers that paginate the list")
+}
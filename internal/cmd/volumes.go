@@ -2,10 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
 
-	"github.com/robversluis/portainer-cli/internal/client"
-	"github.com/robversluis/portainer-cli/internal/config"
+	"github.com/robversluis/portainer-cli/internal/notify"
 	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
 	"github.com/spf13/cobra"
 )
 
@@ -29,45 +34,70 @@ var volumesListCmd = &cobra.Command{
 			return fmt.Errorf("--endpoint flag is required")
 		}
 
-		profile, err := config.GetProfileFromViper()
+		listOpts, err := getListOptions(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to get profile: %w", err)
+			return err
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to create client: %w", err)
+			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		volumeService := client.NewVolumeService(c)
-		volumes, err := volumeService.List(endpointID)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		volumeService := portainer.NewVolumeService(c)
+		format := getOutputFormat()
 
-		switch format {
-		case output.FormatJSON, output.FormatYAML:
-			formatter := output.NewFormatter(output.Options{Format: format})
-			return formatter.Format(volumes)
+		var values notify.Values
+		listFunc := func() error {
+			volumes, err := volumeService.List(endpointID)
+			if err != nil {
+				return err
+			}
 
-		default:
-			table := output.NewTableData([]string{"Name", "Driver", "Scope", "Mountpoint"})
-			for _, volume := range volumes {
-				mountpoint := volume.Mountpoint
-				if len(mountpoint) > 50 {
-					mountpoint = output.TruncateString(mountpoint, 50)
-				}
-				table.AddRow([]string{
-					volume.Name,
-					volume.Driver,
-					volume.Scope,
-					mountpoint,
+			values = notify.Values{"count": float64(len(volumes))}
+
+			if GetQuiet() {
+				return printQuiet(listOpts, volumes, func(item portainer.Volume) string {
+					return item.Name
 				})
 			}
-			return output.PrintTable(*table)
+
+			switch format {
+			case output.FormatJSON, output.FormatYAML:
+				formatter := newFormatter(format)
+				items, err := listOpts.applyItems(volumes)
+				if err != nil {
+					return err
+				}
+				return formatter.Format(items)
+
+			default:
+				table := output.NewTableData([]string{"Name", "Driver", "Scope", "Mountpoint"})
+				for _, volume := range volumes {
+					mountpoint := volume.Mountpoint
+					if len(mountpoint) > 50 {
+						mountpoint = output.TruncateString(mountpoint, 50)
+					}
+					table.AddRow([]string{
+						volume.Name,
+						volume.Driver,
+						volume.Scope,
+						mountpoint,
+					})
+				}
+				if err := listOpts.applyTable(table, volumes); err != nil {
+					return err
+				}
+				return output.PrintTable(*table)
+			}
 		}
+
+		return RunWithWatch(cmd, "volumes", listFunc, func() notify.Values { return values })
 	},
 }
 
@@ -87,27 +117,27 @@ var volumesInspectCmd = &cobra.Command{
 
 		volumeName := args[0]
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		volumeService := client.NewVolumeService(c)
+		volumeService := portainer.NewVolumeService(c)
 		volume, err := volumeService.Inspect(endpointID, volumeName)
 		if err != nil {
 			return err
 		}
 
-		format := output.ParseFormat(cmd.Flag("output").Value.String())
+		format := getOutputFormat()
 
 		switch format {
 		case output.FormatJSON, output.FormatYAML:
-			formatter := output.NewFormatter(output.Options{Format: format})
+			formatter := newFormatter(format)
 			return formatter.Format(volume)
 
 		default:
@@ -145,8 +175,15 @@ var volumesInspectCmd = &cobra.Command{
 var volumesCreateCmd = &cobra.Command{
 	Use:   "create [name]",
 	Short: "Create a volume",
-	Long:  `Create a new Docker volume.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Create a new Docker volume.
+
+Driver options configure the volume driver, such as the server and export of
+an NFS volume.
+
+Example:
+  portainer-cli volumes create media --opt type=nfs --opt o=addr=10.0.0.5,rw \
+    --opt device=:/exports/media --label team=ops --endpoint 1`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		endpointID, err := cmd.Flags().GetInt("endpoint")
 		if err != nil {
@@ -161,23 +198,46 @@ var volumesCreateCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		optArgs, err := cmd.Flags().GetStringArray("opt")
+		if err != nil {
+			return err
+		}
+		labelArgs, err := cmd.Flags().GetStringArray("label")
+		if err != nil {
+			return err
+		}
 
-		profile, err := config.GetProfileFromViper()
+		driverOpts, err := parseDriverOptions(optArgs)
+		if err != nil {
+			return err
+		}
+		labels, err := parseLabels(labelArgs)
+		if err != nil {
+			return err
+		}
+
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		req := &client.VolumeCreateRequest{
+		req := &portainer.VolumeCreateRequest{
 			Name:   volumeName,
 			Driver: driver,
 		}
+		if len(driverOpts) > 0 {
+			req.DriverOpts = driverOpts
+		}
+		if len(labels) > 0 {
+			req.Labels = labels
+		}
 
-		volumeService := client.NewVolumeService(c)
+		volumeService := portainer.NewVolumeService(c)
 		volume, err := volumeService.Create(endpointID, req)
 		if err != nil {
 			return err
@@ -191,6 +251,59 @@ var volumesCreateCmd = &cobra.Command{
 	},
 }
 
+var volumesCloneCmd = &cobra.Command{
+	Use:   "clone [source] [destination]",
+	Short: "Copy a volume's data into another volume",
+	Long: `Copy the data of a volume into another volume by running a temporary helper
+container on the environment. The destination volume is created with the
+driver and labels of the source when it does not exist.
+
+Stop containers writing to the source volume first for a consistent copy.
+
+Example:
+  portainer-cli volumes clone pgdata pgdata-backup --endpoint 1`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		helperImage, err := cmd.Flags().GetString("helper-image")
+		if err != nil {
+			return err
+		}
+
+		source, destination := args[0], args[1]
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		volumeService := portainer.NewVolumeService(c)
+		if err := volumeService.Clone(endpointID, source, destination, portainer.VolumeCloneOptions{
+			HelperImage: helperImage,
+		}); err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Volume '%s' cloned to '%s'\n", source, destination)
+		}
+
+		return nil
+	},
+}
+
 var volumesRemoveCmd = &cobra.Command{
 	Use:     "remove [volume]",
 	Aliases: []string{"rm"},
@@ -212,17 +325,17 @@ var volumesRemoveCmd = &cobra.Command{
 			return err
 		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		volumeService := client.NewVolumeService(c)
+		volumeService := portainer.NewVolumeService(c)
 		if err := volumeService.Remove(endpointID, volumeName, force); err != nil {
 			return err
 		}
@@ -248,38 +361,251 @@ var volumesPruneCmd = &cobra.Command{
 			return fmt.Errorf("--endpoint flag is required")
 		}
 
-		profile, err := config.GetProfileFromViper()
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		volumeService := portainer.NewVolumeService(c)
+		emitProgress(progressEvent{Event: "pruning", Endpoint: endpointID, Resource: "volumes"})
+		report, err := volumeService.Prune(endpointID)
+		if err != nil {
+			emitPruned(endpointID, "volumes", 0, 0, err)
+			return err
+		}
+		emitPruned(endpointID, "volumes", len(report.VolumesDeleted), report.SpaceReclaimed, nil)
+
+		return printPruneReport(report, pruneResult{
+			kind:      "volumes",
+			deleted:   report.VolumesDeleted,
+			reclaimed: report.SpaceReclaimed,
+		})
+	},
+}
+
+var volumesBrowseCmd = &cobra.Command{
+	Use:   "browse [volume] [path]",
+	Short: "List files in a volume",
+	Long: `List the files in a volume directory using the Portainer agent volume
+browser. The path defaults to the volume root. Requires an agent environment.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		volumeName := args[0]
+		remotePath := "/"
+		if len(args) > 1 {
+			remotePath = args[1]
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		volumeService := portainer.NewVolumeService(c)
+		files, err := volumeService.Browse(endpointID, volumeName, remotePath)
+		if err != nil {
+			return err
+		}
+
+		return printFileList(files)
+	},
+}
+
+var volumesDownloadCmd = &cobra.Command{
+	Use:   "download [volume] [path]",
+	Short: "Download a file from a volume",
+	Long: `Download a file from a volume using the Portainer agent volume browser.
+
+The file is saved under its own name in the current directory unless --file
+is given. Use --file - to write to standard output. For example:
+  portainer-cli volumes download data /conf/app.conf --endpoint 1 --file app.conf`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			return err
+		}
+
+		volumeName := args[0]
+		remotePath := args[1]
+		if file == "" {
+			file = path.Base(remotePath)
+			if file == "/" || file == "." {
+				return fmt.Errorf("cannot derive a file name from %s, use --file", remotePath)
+			}
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		volumeService := portainer.NewVolumeService(c)
+		body, err := volumeService.Download(endpointID, volumeName, remotePath)
+		if err != nil {
+			return err
+		}
+		if body == nil {
+			return nil
+		}
+		defer body.Close()
+
+		return saveDownload(body, file)
+	},
+}
+
+var volumesUploadCmd = &cobra.Command{
+	Use:   "upload [volume] [local-file] [directory]",
+	Short: "Upload a file to a volume",
+	Long: `Upload a local file into a volume directory using the Portainer agent
+volume browser. The directory defaults to the volume root.`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		volumeName := args[0]
+		localPath := args[1]
+		dir := "/"
+		if len(args) > 2 {
+			dir = args[2]
+		}
+
+		profile, err := ResolveProfile(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to get profile: %w", err)
 		}
 
-		c, err := client.NewClient(profile, GetClientOptions()...)
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
 		if err != nil {
 			return fmt.Errorf("failed to create client: %w", err)
 		}
 
-		volumeService := client.NewVolumeService(c)
-		if err := volumeService.Prune(endpointID); err != nil {
+		volumeService := portainer.NewVolumeService(c)
+		if err := volumeService.Upload(endpointID, volumeName, dir, localPath); err != nil {
 			return err
 		}
 
 		if !GetQuiet() {
-			fmt.Println("Volumes pruned successfully")
+			fmt.Printf("Uploaded %s to %s:%s\n", filepath.Base(localPath), volumeName, path.Join(dir, filepath.Base(localPath)))
 		}
 
 		return nil
 	},
 }
 
+// printFileList prints agent file browser entries, directories first
+func printFileList(files []portainer.FileInfo) error {
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Dir != files[j].Dir {
+			return files[i].Dir
+		}
+		return files[i].Name < files[j].Name
+	})
+
+	format := getOutputFormat()
+
+	switch format {
+	case output.FormatJSON, output.FormatYAML:
+		formatter := newFormatter(format)
+		return formatter.Format(files)
+
+	default:
+		table := output.NewTableData([]string{"Name", "Size", "Modified"})
+		for _, file := range files {
+			name := file.Name
+			size := output.FormatSize(file.Size)
+			if file.Dir {
+				name += "/"
+				size = "-"
+			}
+			table.AddRow([]string{
+				name,
+				size,
+				file.GetModTime().Format("2006-01-02 15:04:05"),
+			})
+		}
+		return output.PrintTable(*table)
+	}
+}
+
+// saveDownload writes a downloaded file to path, or to stdout for "-"
+func saveDownload(body io.Reader, file string) error {
+	if file == "-" {
+		_, err := io.Copy(os.Stdout, body)
+		return err
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	n, err := io.Copy(f, body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if !GetQuiet() {
+		fmt.Printf("Saved %s (%s)\n", file, output.FormatSize(n))
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(volumesCmd)
 	volumesCmd.AddCommand(volumesListCmd)
 	volumesCmd.AddCommand(volumesInspectCmd)
 	volumesCmd.AddCommand(volumesCreateCmd)
 	volumesCmd.AddCommand(volumesRemoveCmd)
+	volumesCmd.AddCommand(volumesCloneCmd)
 	volumesCmd.AddCommand(volumesPruneCmd)
+	volumesCmd.AddCommand(volumesBrowseCmd)
+	volumesCmd.AddCommand(volumesDownloadCmd)
+	volumesCmd.AddCommand(volumesUploadCmd)
 
 	volumesListCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	AddWatchFlags(volumesListCmd)
+	addListFlags(volumesListCmd)
 	_ = volumesListCmd.MarkFlagRequired("endpoint")
 
 	volumesInspectCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
@@ -287,12 +613,28 @@ func init() {
 
 	volumesCreateCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
 	volumesCreateCmd.Flags().String("driver", "local", "Volume driver")
+	volumesCreateCmd.Flags().StringArray("opt", nil, "Driver specific option (KEY=VALUE, repeatable)")
+	volumesCreateCmd.Flags().StringArray("label", nil, "Volume label (KEY=VALUE, repeatable)")
 	_ = volumesCreateCmd.MarkFlagRequired("endpoint")
 
+	volumesCloneCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	volumesCloneCmd.Flags().String("helper-image", portainer.DefaultVolumeHelperImage, "Image of the helper container that copies the data")
+	_ = volumesCloneCmd.MarkFlagRequired("endpoint")
+
 	volumesRemoveCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
 	volumesRemoveCmd.Flags().BoolP("force", "f", false, "Force removal of the volume")
 	_ = volumesRemoveCmd.MarkFlagRequired("endpoint")
 
 	volumesPruneCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
 	_ = volumesPruneCmd.MarkFlagRequired("endpoint")
+
+	volumesBrowseCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	_ = volumesBrowseCmd.MarkFlagRequired("endpoint")
+
+	volumesDownloadCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	volumesDownloadCmd.Flags().StringP("file", "f", "", "Destination file (default: name of the remote file, - for stdout)")
+	_ = volumesDownloadCmd.MarkFlagRequired("endpoint")
+
+	volumesUploadCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	_ = volumesUploadCmd.MarkFlagRequired("endpoint")
 }
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var volumesBackupCmd = &cobra.Command{
+	Use:   "backup [volume]",
+	Short: "Back up a volume to a local tar archive",
+	Long: `Save the content of a volume to a local tar archive. The volume is mounted
+read-only in a short-lived helper container and its files are streamed
+through the Docker archive API, so no agent is needed.
+
+The archive is gzip-compressed when the file name ends in .gz or .tgz and
+defaults to <volume>.tar.gz. Use --output - to write an uncompressed tar to
+standard output. Stop containers writing to the volume first for a
+consistent backup.
+
+Example:
+  portainer-cli volumes backup pgdata -o pgdata.tar.gz --endpoint 1`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		file, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		helperImage, err := cmd.Flags().GetString("helper-image")
+		if err != nil {
+			return err
+		}
+
+		volumeName := args[0]
+		if file == "" {
+			file = volumeName + ".tar.gz"
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		if GetDryRun() {
+			// Print the requests without creating the archive
+			_, err := portainer.NewVolumeService(c).Backup(endpointID, volumeName, io.Discard, portainer.VolumeArchiveOptions{
+				HelperImage: helperImage,
+			})
+			return err
+		}
+
+		w, err := createBackupFile(file)
+		if err != nil {
+			return err
+		}
+
+		volumeService := portainer.NewVolumeService(c)
+		n, err := volumeService.Backup(endpointID, volumeName, w, portainer.VolumeArchiveOptions{
+			HelperImage: helperImage,
+		})
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			if file != "-" {
+				os.Remove(file)
+			}
+			return err
+		}
+
+		if !GetQuiet() && file != "-" {
+			fmt.Printf("Volume '%s' backed up to %s (%s uncompressed)\n", volumeName, file, output.FormatSize(n))
+		}
+
+		return nil
+	},
+}
+
+var volumesRestoreCmd = &cobra.Command{
+	Use:   "restore [volume]",
+	Short: "Restore a volume from a local tar archive",
+	Long: `Extract an archive created by 'volumes backup' into a volume. The volume is
+created when it does not exist. Files already in the volume are kept unless
+the archive replaces them.
+
+Gzip-compressed archives are detected automatically. Use --input - to read
+the archive from standard input.
+
+Example:
+  portainer-cli volumes restore pgdata -i pgdata.tar.gz --endpoint 1`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		if endpointID == 0 {
+			return fmt.Errorf("--endpoint flag is required")
+		}
+
+		file, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+		if file == "" {
+			return fmt.Errorf("--input flag is required")
+		}
+		helperImage, err := cmd.Flags().GetString("helper-image")
+		if err != nil {
+			return err
+		}
+
+		volumeName := args[0]
+
+		r, err := openBackupFile(file)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+
+		volumeService := portainer.NewVolumeService(c)
+		if err := volumeService.Restore(endpointID, volumeName, r, portainer.VolumeArchiveOptions{
+			HelperImage: helperImage,
+		}); err != nil {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Volume '%s' restored from %s\n", volumeName, file)
+		}
+
+		return nil
+	},
+}
+
+// createBackupFile opens the destination of a volume backup, or stdout for
+// "-". Writes are gzip-compressed for .gz and .tgz file names.
+func createBackupFile(file string) (io.WriteCloser, error) {
+	if file == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	if strings.HasSuffix(file, ".gz") || strings.HasSuffix(file, ".tgz") {
+		return &gzipFile{Writer: gzip.NewWriter(f), file: f}, nil
+	}
+	return f, nil
+}
+
+// openBackupFile opens a volume backup, or stdin for "-", and returns a
+// reader of the uncompressed tar archive
+func openBackupFile(file string) (io.ReadCloser, error) {
+	var f io.ReadCloser = io.NopCloser(os.Stdin)
+	if file != "-" {
+		var err error
+		f, err = os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file: %w", err)
+		}
+	}
+
+	r, err := decompressBackup(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return readCloser{Reader: r, Closer: f}, nil
+}
+
+// decompressBackup returns a reader of the tar archive in r, which may be
+// gzip-compressed
+func decompressBackup(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if !bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		return buffered, nil
+	}
+
+	gz, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gzip archive: %w", err)
+	}
+	return gz, nil
+}
+
+// gzipFile closes both the gzip stream and the file it writes to
+type gzipFile struct {
+	*gzip.Writer
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	err := g.Writer.Close()
+	if closeErr := g.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func init() {
+	volumesCmd.AddCommand(volumesBackupCmd)
+	volumesCmd.AddCommand(volumesRestoreCmd)
+
+	volumesBackupCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	volumesBackupCmd.Flags().StringP("output", "o", "", "Archive file (default: <volume>.tar.gz, - for stdout)")
+	volumesBackupCmd.Flags().String("helper-image", portainer.DefaultVolumeHelperImage, "Image of the helper container the volume is mounted in")
+	_ = volumesBackupCmd.MarkFlagRequired("endpoint")
+
+	volumesRestoreCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required)")
+	volumesRestoreCmd.Flags().StringP("input", "i", "", "Archive file, - for stdin (required)")
+	volumesRestoreCmd.Flags().String("helper-image", portainer.DefaultVolumeHelperImage, "Image of the helper container the volume is mounted in")
+	_ = volumesRestoreCmd.MarkFlagRequired("endpoint")
+	_ = volumesRestoreCmd.MarkFlagRequired("input")
+}
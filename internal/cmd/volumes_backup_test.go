@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupFileRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"data.tar.gz", "data.tar"} {
+		file := filepath.Join(dir, name)
+
+		w, err := createBackupFile(file)
+		if err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		if _, err := io.WriteString(w, "tar content"); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("failed to close %s: %v", name, err)
+		}
+
+		raw, _ := os.ReadFile(file)
+		if compressed := len(raw) > 1 && raw[0] == 0x1f && raw[1] == 0x8b; compressed != (name == "data.tar.gz") {
+			t.Errorf("%s: unexpected compression", name)
+		}
+
+		r, err := openBackupFile(file)
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", name, err)
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil || string(content) != "tar content" {
+			t.Errorf("%s: unexpected content %q (%v)", name, content, err)
+		}
+	}
+}
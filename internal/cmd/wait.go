@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+	"github.com/spf13/cobra"
+)
+
+var environmentsWaitCmd = &cobra.Command{
+	Use:   "wait [id or name]",
+	Short: "Wait until an environment reaches a status",
+	Long: `Poll an environment until its status is up (the default) or down, for
+provisioning pipelines that create an environment and must wait for it
+before the next step. The environment is given by ID or name, or with --id.
+
+Exits with an error if the timeout expires.
+
+Examples:
+  portainer-cli environments wait --id 3 --status up --timeout 5m
+  portainer-cli environments wait production`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		idOrName, err := waitTarget(cmd, args)
+		if err != nil {
+			return err
+		}
+		status, err := cmd.Flags().GetString("status")
+		if err != nil {
+			return err
+		}
+		var wanted int
+		switch strings.ToLower(status) {
+		case "up":
+			wanted = portainer.EnvironmentStatusUp
+		case "down":
+			wanted = portainer.EnvironmentStatusDown
+		default:
+			return fmt.Errorf("invalid status '%s': use up or down", status)
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		envService := portainer.NewEnvironmentService(c)
+
+		var env *portainer.Environment
+		err = pollUntil(cmd, "environment "+idOrName, strings.ToLower(status), func() (bool, string, error) {
+			env, err = resolveEnvironment(envService, idOrName)
+			if err != nil {
+				return false, "", err
+			}
+			return env.Status == wanted, strings.ToLower(env.StatusString()), nil
+		})
+		if err != nil || GetDryRun() {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Environment %s (%d) is %s\n", env.Name, env.Id, strings.ToLower(env.StatusString()))
+		}
+		return nil
+	},
+}
+
+var stacksWaitCmd = &cobra.Command{
+	Use:   "wait [id or name]",
+	Short: "Wait until a stack reaches a status",
+	Long: `Poll a stack until its status is active (the default) or inactive, for
+pipelines that deploy or start a stack and must wait for it before the next
+step. Stacks given by name need --endpoint.
+
+Exits with an error if the timeout expires.
+
+Examples:
+  portainer-cli stacks wait 12 --status active
+  portainer-cli stacks wait web --endpoint 1 --status inactive --timeout 2m`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpointID, err := cmd.Flags().GetInt("endpoint")
+		if err != nil {
+			return err
+		}
+		status, err := cmd.Flags().GetString("status")
+		if err != nil {
+			return err
+		}
+		var wanted int
+		switch strings.ToLower(status) {
+		case "active":
+			wanted = portainer.StackStatusActive
+		case "inactive":
+			wanted = portainer.StackStatusInactive
+		default:
+			return fmt.Errorf("invalid status '%s': use active or inactive", status)
+		}
+
+		profile, err := ResolveProfile(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to get profile: %w", err)
+		}
+
+		c, err := portainer.NewClient(profile.ClientConfig(), GetClientOptions()...)
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		stackService := portainer.NewStackService(c)
+
+		var stack *portainer.Stack
+		err = pollUntil(cmd, "stack "+args[0], strings.ToLower(status), func() (bool, string, error) {
+			stack, err = resolveStack(stackService, args[0], endpointID)
+			if err != nil {
+				return false, "", err
+			}
+			return stack.Status == wanted, strings.ToLower(stack.StatusString()), nil
+		})
+		if err != nil || GetDryRun() {
+			return err
+		}
+
+		if !GetQuiet() {
+			fmt.Printf("Stack %s (%d) is %s\n", stack.Name, stack.Id, strings.ToLower(stack.StatusString()))
+		}
+		return nil
+	},
+}
+
+// waitTarget returns the environment of environments wait: the argument,
+// or the --id flag
+func waitTarget(cmd *cobra.Command, args []string) (string, error) {
+	id, err := cmd.Flags().GetInt("id")
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case id != 0 && len(args) > 0:
+		return "", fmt.Errorf("an environment argument cannot be combined with --id")
+	case id != 0:
+		return strconv.Itoa(id), nil
+	case len(args) > 0:
+		return args[0], nil
+	}
+	return "", fmt.Errorf("specify an environment, or --id")
+}
+
+// pollUntil calls check every --interval until it reports done, it fails,
+// or --timeout expires. check returns the current state, such as "down",
+// which is reported with --verbose as it changes and in the timeout error.
+// In dry-run mode check is called once.
+func pollUntil(cmd *cobra.Command, resource, wanted string, check func() (done bool, state string, err error)) error {
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+	if timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+	interval, err := cmd.Flags().GetDuration("interval")
+	if err != nil {
+		return err
+	}
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	ctx, cancelTimeout := context.WithTimeout(ctx, timeout)
+	defer cancelTimeout()
+
+	lastState := ""
+	for {
+		done, state, err := check()
+		if err != nil || done || GetDryRun() {
+			return err
+		}
+		if state != lastState && GetVerbose() {
+			fmt.Fprintf(os.Stderr, "%s is %s, waiting for %s\n", resource, state, wanted)
+		}
+		lastState = state
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("timed out after %s waiting for %s to be %s (status: %s)", timeout, resource, wanted, lastState)
+			}
+			return fmt.Errorf("interrupted while waiting for %s", resource)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// addWaitFlags adds the --timeout and --interval flags of a wait command
+func addWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("timeout", 5*time.Minute, "Maximum time to wait (e.g. 30s, 5m)")
+	cmd.Flags().Duration("interval", 2*time.Second, "Time between status checks")
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsWaitCmd)
+	stacksCmd.AddCommand(stacksWaitCmd)
+
+	environmentsWaitCmd.Flags().Int("id", 0, "Environment ID")
+	environmentsWaitCmd.Flags().String("status", "up", "Status to wait for (up, down)")
+	addWaitFlags(environmentsWaitCmd)
+
+	stacksWaitCmd.Flags().Int("endpoint", 0, "Environment endpoint ID (required for name lookup)")
+	stacksWaitCmd.Flags().String("status", "active", "Status to wait for (active, inactive)")
+	addWaitFlags(stacksWaitCmd)
+}
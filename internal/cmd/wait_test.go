@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestPollUntil(t *testing.T) {
+	newCmd := func(timeout time.Duration) *cobra.Command {
+		cmd := &cobra.Command{Use: "wait"}
+		addWaitFlags(cmd)
+		_ = cmd.Flags().Set("timeout", timeout.String())
+		_ = cmd.Flags().Set("interval", "1ms")
+		return cmd
+	}
+
+	calls := 0
+	err := pollUntil(newCmd(time.Second), "environment 3", "up", func() (bool, string, error) {
+		calls++
+		return calls == 3, "down", nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 checks, got %d", calls)
+	}
+
+	err = pollUntil(newCmd(20*time.Millisecond), "environment 3", "up", func() (bool, string, error) {
+		return false, "down", nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 20ms waiting for environment 3 to be up (status: down)") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWaitTarget(t *testing.T) {
+	newCmd := func(id string) *cobra.Command {
+		cmd := &cobra.Command{Use: "wait"}
+		cmd.Flags().Int("id", 0, "")
+		if id != "" {
+			_ = cmd.Flags().Set("id", id)
+		}
+		return cmd
+	}
+
+	if target, err := waitTarget(newCmd("3"), nil); err != nil || target != "3" {
+		t.Errorf("expected 3, got %q (%v)", target, err)
+	}
+	if target, err := waitTarget(newCmd(""), []string{"prod"}); err != nil || target != "prod" {
+		t.Errorf("expected prod, got %q (%v)", target, err)
+	}
+	if _, err := waitTarget(newCmd("3"), []string{"prod"}); err == nil {
+		t.Error("expected error for argument with --id")
+	}
+	if _, err := waitTarget(newCmd(""), nil); err == nil {
+		t.Error("expected error without environment")
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/notify"
+	"github.com/robversluis/portainer-cli/internal/output"
+	"github.com/robversluis/portainer-cli/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+// watchValues returns the variables available to --notify-on conditions,
+// computed from the items of the last refresh
+type watchValues func() notify.Values
+
+// AddWatchFlags adds the --watch, --interval, --watch-diff and --notify-*
+// flags to a list command
+func AddWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("watch", "w", false, "Watch for changes and continuously update")
+	cmd.Flags().Int("interval", 2, "Refresh interval in seconds for watch mode")
+	cmd.Flags().Bool("watch-diff", false, "Highlight cells that changed since the last refresh in watch mode")
+	cmd.Flags().StringArray("notify-on", nil, "Notify when a condition such as 'unhealthy>0' becomes true (repeatable)")
+	cmd.Flags().Bool("notify-desktop", false, "Show a desktop notification when a --notify-on condition becomes true (default when no other action is set)")
+	cmd.Flags().String("notify-exec", "", "Run a shell command when a --notify-on condition becomes true")
+	cmd.Flags().String("notify-webhook", "", "POST a JSON notification to this URL when a --notify-on condition becomes true")
+}
+
+// RunWithWatch runs fn once, or repeatedly until interrupted when --watch
+// is set. resource names what is being watched in the startup message and
+// notifications; values provides the variables of --notify-on conditions.
+func RunWithWatch(cmd *cobra.Command, resource string, fn func() error, values watchValues) error {
+	watchMode, err := cmd.Flags().GetBool("watch")
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	notifier, err := newWatchNotifier(cmd, resource, values)
+	if err != nil {
+		return err
+	}
+	if notifier != nil {
+		list := fn
+		fn = func() error {
+			if err := list(); err != nil {
+				return err
+			}
+			return notifier.check(ctx)
+		}
+	}
+
+	if !watchMode {
+		return fn()
+	}
+
+	interval, err := cmd.Flags().GetInt("interval")
+	if err != nil {
+		return err
+	}
+	if interval < 1 {
+		return fmt.Errorf("--interval must be at least 1 second")
+	}
+
+	watchDiff, err := cmd.Flags().GetBool("watch-diff")
+	if err != nil {
+		return err
+	}
+
+	opts := watch.DefaultOptions()
+	opts.Interval = time.Duration(interval) * time.Second
+	opts.Diff = watchDiff
+
+	fmt.Printf("Watching %s... (Press Ctrl+C to exit)\n", resource)
+	return watch.Watch(ctx, opts, fn)
+}
+
+// watchNotifier evaluates the --notify-on conditions after each refresh and
+// notifies when one of them becomes true
+type watchNotifier struct {
+	resource  string
+	values    watchValues
+	trigger   *notify.Trigger
+	notifiers []notify.Notifier
+}
+
+// newWatchNotifier builds the notifier from the --notify-* flags. It returns
+// nil when no conditions are set.
+func newWatchNotifier(cmd *cobra.Command, resource string, values watchValues) (*watchNotifier, error) {
+	expressions, err := cmd.Flags().GetStringArray("notify-on")
+	if err != nil {
+		return nil, err
+	}
+	desktop, err := cmd.Flags().GetBool("notify-desktop")
+	if err != nil {
+		return nil, err
+	}
+	command, err := cmd.Flags().GetString("notify-exec")
+	if err != nil {
+		return nil, err
+	}
+	webhook, err := cmd.Flags().GetString("notify-webhook")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(expressions) == 0 {
+		if desktop || command != "" || webhook != "" {
+			return nil, fmt.Errorf("--notify-desktop, --notify-exec and --notify-webhook require --notify-on")
+		}
+		return nil, nil
+	}
+	if values == nil {
+		return nil, fmt.Errorf("--notify-on is not supported by %s", cmd.CommandPath())
+	}
+
+	conditions := make([]*notify.Condition, 0, len(expressions))
+	for _, expression := range expressions {
+		condition, err := notify.Parse(expression)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	var notifiers []notify.Notifier
+	if command != "" {
+		// Notification commands write to stderr, since stdout is redrawn in
+		// watch mode
+		notifiers = append(notifiers, &notify.Command{Command: command, Stdout: os.Stderr, Stderr: os.Stderr})
+	}
+	if webhook != "" {
+		parsed, err := neturl.Parse(webhook)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid webhook URL: %s", webhook)
+		}
+		notifiers = append(notifiers, &notify.Webhook{URL: webhook, Client: &http.Client{Timeout: notify.WebhookTimeout}})
+	}
+	if desktop || len(notifiers) == 0 {
+		notifiers = append(notifiers, notify.Desktop{})
+	}
+
+	return &watchNotifier{
+		resource:  resource,
+		values:    values,
+		trigger:   notify.NewTrigger(conditions),
+		notifiers: notifiers,
+	}, nil
+}
+
+// check evaluates the conditions against the last refresh. Failed
+// notifications are reported as warnings so watching continues; invalid
+// conditions stop it.
+func (n *watchNotifier) check(ctx context.Context) error {
+	values := n.values()
+
+	fired, err := n.trigger.Update(values)
+	if err != nil {
+		return err
+	}
+
+	for _, condition := range fired {
+		event := &notify.Event{
+			Resource:  n.resource,
+			Condition: condition.String(),
+			Values:    values,
+			Time:      time.Now(),
+		}
+		for _, notifier := range n.notifiers {
+			if err := notifier.Notify(ctx, event); err != nil {
+				fmt.Fprintln(os.Stderr, output.Warning(err.Error()))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/robversluis/portainer-cli/internal/notify"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+
+	"github.com/spf13/cobra"
+)
+
+func TestListCommandsHaveWatchFlags(t *testing.T) {
+	commands := map[string]*cobra.Command{
+		"containers":   containersListCmd,
+		"images":       imagesListCmd,
+		"stacks":       stacksListCmd,
+		"volumes":      volumesListCmd,
+		"networks":     networksListCmd,
+		"environments": environmentsListCmd,
+		"helm":         helmListCmd,
+	}
+
+	for name, cmd := range commands {
+		for _, flag := range []string{"watch", "interval", "watch-diff", "notify-on", "notify-exec", "notify-webhook", "notify-desktop"} {
+			if cmd.Flags().Lookup(flag) == nil {
+				t.Errorf("%s list should define --%s", name, flag)
+			}
+		}
+	}
+}
+
+func TestRunWithWatch_RunsOnceWithoutWatch(t *testing.T) {
+	cmd := &cobra.Command{}
+	AddWatchFlags(cmd)
+
+	calls := 0
+	if err := RunWithWatch(cmd, "things", func() error {
+		calls++
+		return nil
+	}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestRunWithWatch_NotifyOn(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("notification command uses sh")
+	}
+
+	file := filepath.Join(t.TempDir(), "notified")
+
+	cmd := &cobra.Command{}
+	AddWatchFlags(cmd)
+	cmd.Flags().Set("notify-on", "unhealthy>0")
+	cmd.Flags().Set("notify-on", "count == 0")
+	cmd.Flags().Set("notify-exec", `echo "$PORTAINER_NOTIFY_CONDITION" >> `+file)
+
+	values := notify.Values{"count": 2, "unhealthy": 1}
+	if err := RunWithWatch(cmd, "things", func() error { return nil }, func() notify.Values { return values }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("expected the command to run: %v", err)
+	}
+	if string(data) != "unhealthy>0\n" {
+		t.Errorf("unexpected notifications: %q", data)
+	}
+}
+
+func TestRunWithWatch_NotifyErrors(t *testing.T) {
+	values := func() notify.Values { return notify.Values{"count": 1} }
+
+	tests := []struct {
+		name   string
+		flags  map[string]string
+		values watchValues
+		errMsg string
+	}{
+		{name: "action without condition", flags: map[string]string{"notify-exec": "true"}, values: values, errMsg: "require --notify-on"},
+		{name: "invalid condition", flags: map[string]string{"notify-on": "count >"}, values: values, errMsg: "invalid condition"},
+		{name: "unknown variable", flags: map[string]string{"notify-on": "missing > 0", "notify-exec": "true"}, values: values, errMsg: `unknown variable "missing"`},
+		{name: "invalid webhook", flags: map[string]string{"notify-on": "count > 0", "notify-webhook": "ftp://example.com"}, values: values, errMsg: "invalid webhook URL"},
+		{name: "unsupported command", flags: map[string]string{"notify-on": "count > 0"}, values: nil, errMsg: "not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			AddWatchFlags(cmd)
+			for name, value := range tt.flags {
+				cmd.Flags().Set(name, value)
+			}
+
+			err := RunWithWatch(cmd, "things", func() error { return nil }, tt.values)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestContainerWatchValues(t *testing.T) {
+	values := containerWatchValues([]portainer.Container{
+		{State: "running", Status: "Up 5 minutes (healthy)"},
+		{State: "running", Status: "Up 1 minute (unhealthy)"},
+		{State: "exited", Status: "Exited (1) 1 hour ago"},
+		{State: "paused", Status: "Up 2 hours (Paused)"},
+	})
+
+	expected := notify.Values{
+		"count": 4, "running": 2, "stopped": 2, "paused": 1, "restarting": 0, "exited": 1,
+		"healthy": 1, "unhealthy": 1, "starting": 0,
+	}
+	for name, value := range expected {
+		if values[name] != value {
+			t.Errorf("%s: expected %v, got %v", name, value, values[name])
+		}
+	}
+}
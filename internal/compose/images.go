@@ -0,0 +1,159 @@
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ImageChange is a service image changed by RewriteImages
+type ImageChange struct {
+	Service string
+	From    string
+	To      string
+}
+
+// RewriteImages changes the image of every service with rewrite. The file is
+// edited in place, so comments and formatting are kept. Images that are
+// interpolated from a variable, such as ${IMAGE}, are left as they are.
+func RewriteImages(content []byte, rewrite func(image string) string) ([]byte, []ImageChange, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse stack file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return content, nil, nil
+	}
+
+	services := lookup(doc.Content[0], []string{"services"})
+	if services == nil || services.Kind != yaml.MappingNode {
+		return content, nil, nil
+	}
+
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	var changes []ImageChange
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		image := lookup(services.Content[i+1], []string{"image"})
+		if image == nil || image.Kind != yaml.ScalarNode || strings.HasPrefix(image.Value, "$") {
+			continue
+		}
+		rewritten := rewrite(image.Value)
+		if rewritten == image.Value {
+			continue
+		}
+		if !replaceScalar(lines, image, rewritten) {
+			return nil, nil, fmt.Errorf("line %d: cannot rewrite image %s written in %s style", image.Line, image.Value, scalarStyle(image))
+		}
+		changes = append(changes, ImageChange{Service: services.Content[i].Value, From: image.Value, To: rewritten})
+	}
+	return bytes.Join(lines, nil), changes, nil
+}
+
+// replaceScalar replaces a single-line scalar at its position, keeping its
+// quotes
+func replaceScalar(lines [][]byte, node *yaml.Node, value string) bool {
+	if node.Line < 1 || node.Line > len(lines) {
+		return false
+	}
+	line := lines[node.Line-1]
+	start := node.Column - 1
+
+	var token string
+	switch node.Style {
+	case 0:
+		token = node.Value
+	case yaml.DoubleQuotedStyle:
+		token = `"` + node.Value + `"`
+		value = `"` + value + `"`
+	case yaml.SingleQuotedStyle:
+		token = "'" + node.Value + "'"
+		value = "'" + value + "'"
+	default:
+		return false
+	}
+	if start < 0 || !bytes.HasPrefix(line[start:], []byte(token)) {
+		return false
+	}
+
+	replaced := make([]byte, 0, len(line)+len(value)-len(token))
+	replaced = append(replaced, line[:start]...)
+	replaced = append(replaced, value...)
+	replaced = append(replaced, line[start+len(token):]...)
+	lines[node.Line-1] = replaced
+	return true
+}
+
+func scalarStyle(node *yaml.Node) string {
+	switch node.Style {
+	case yaml.LiteralStyle, yaml.FoldedStyle:
+		return "block"
+	default:
+		return "escaped"
+	}
+}
+
+// PrefixImage returns a rewrite that puts prefix in front of every image,
+// for registries that hold copies of all images under a path
+func PrefixImage(prefix string) func(string) string {
+	prefix = strings.TrimSuffix(prefix, "/") + "/"
+	return func(image string) string {
+		if strings.HasPrefix(image, prefix) {
+			return image
+		}
+		return prefix + image
+	}
+}
+
+// MirrorImage returns a rewrite that pulls Docker Hub images from mirror,
+// such as a pull-through cache. Official images get their library/ path:
+// nginx becomes mirror/library/nginx. Images of other registries are kept.
+func MirrorImage(mirror string) func(string) string {
+	mirror = strings.TrimSuffix(mirror, "/")
+	return func(image string) string {
+		path, ok := dockerHubPath(image)
+		if !ok {
+			return image
+		}
+		return mirror + "/" + path
+	}
+}
+
+// PinDigest returns a rewrite that pins images to the manifest digests
+// resolved for them, keeping the tag for readers: nginx:1.25 becomes
+// nginx:1.25@sha256:.... Images that have a digest already, or none was
+// resolved for, are kept.
+func PinDigest(digests map[string]string) func(string) string {
+	return func(image string) string {
+		digest := digests[image]
+		if digest == "" || strings.Contains(image, "@") {
+			return image
+		}
+		return image + "@" + digest
+	}
+}
+
+// dockerHubPath returns the repository path of a Docker Hub image, with its
+// tag or digest, and whether the image is on Docker Hub
+func dockerHubPath(image string) (string, bool) {
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && isRegistryHost(parts[0]) {
+		switch parts[0] {
+		case "docker.io", "index.docker.io", "registry-1.docker.io":
+			image = parts[1]
+		default:
+			return "", false
+		}
+	}
+	if !strings.Contains(image, "/") {
+		image = "library/" + image
+	}
+	return image, true
+}
+
+// isRegistryHost reports whether the first path component of an image is a
+// registry host, as Docker decides it
+func isRegistryHost(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost"
+}
@@ -0,0 +1,83 @@
+package compose
+
+import "testing"
+
+func TestMirrorImage(t *testing.T) {
+	rewrite := MirrorImage("mirror.internal/hub/")
+	for image, expected := range map[string]string{
+		"nginx":                        "mirror.internal/hub/library/nginx",
+		"nginx:1.25":                   "mirror.internal/hub/library/nginx:1.25",
+		"grafana/grafana@sha256:abc":   "mirror.internal/hub/grafana/grafana@sha256:abc",
+		"docker.io/library/redis:7":    "mirror.internal/hub/library/redis:7",
+		"docker.io/bitnami/redis":      "mirror.internal/hub/bitnami/redis",
+		"ghcr.io/acme/web:1.0":         "ghcr.io/acme/web:1.0",
+		"localhost/web":                "localhost/web",
+		"registry.example.com:5000/db": "registry.example.com:5000/db",
+	} {
+		if got := rewrite(image); got != expected {
+			t.Errorf("%s: expected %s, got %s", image, expected, got)
+		}
+	}
+}
+
+func TestPrefixImage(t *testing.T) {
+	rewrite := PrefixImage("registry.internal/mirror")
+	if got := rewrite("ghcr.io/acme/web:1.0"); got != "registry.internal/mirror/ghcr.io/acme/web:1.0" {
+		t.Errorf("unexpected image %s", got)
+	}
+	if got := rewrite("registry.internal/mirror/nginx"); got != "registry.internal/mirror/nginx" {
+		t.Errorf("expected prefixed image to be kept, got %s", got)
+	}
+}
+
+func TestPinDigest(t *testing.T) {
+	rewrite := PinDigest(map[string]string{"nginx:1.25": "sha256:abc", "redis@sha256:def": "sha256:def"})
+	for image, expected := range map[string]string{
+		"nginx:1.25":       "nginx:1.25@sha256:abc",
+		"redis@sha256:def": "redis@sha256:def",
+		"postgres:16":      "postgres:16",
+	} {
+		if got := rewrite(image); got != expected {
+			t.Errorf("%s: expected %s, got %s", image, expected, got)
+		}
+	}
+}
+
+func TestRewriteImages(t *testing.T) {
+	content := `# web stack
+services:
+  web:
+    image: nginx:1.25 # pinned
+  api:
+    image: "acme/api:2"
+  worker:
+    image: '${WORKER_IMAGE}'
+  cache:
+    image: ghcr.io/acme/cache
+`
+	expected := `# web stack
+services:
+  web:
+    image: mirror.internal/library/nginx:1.25 # pinned
+  api:
+    image: "mirror.internal/acme/api:2"
+  worker:
+    image: '${WORKER_IMAGE}'
+  cache:
+    image: ghcr.io/acme/cache
+`
+	rewritten, changes, err := RewriteImages([]byte(content), MirrorImage("mirror.internal"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(rewritten) != expected {
+		t.Errorf("unexpected content:\n%s", rewritten)
+	}
+	if len(changes) != 2 || changes[0].Service != "web" || changes[1].From != "acme/api:2" {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+
+	if _, _, err := RewriteImages([]byte("services:\n  web:\n    image: >\n      nginx\n"), MirrorImage("m")); err == nil {
+		t.Error("expected error for a block scalar image")
+	}
+}
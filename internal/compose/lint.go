@@ -0,0 +1,428 @@
+// Package compose checks Docker Compose files before they are deployed as
+// Portainer stacks, reporting problems with the line they are on.
+package compose
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/schema"
+	"github.com/compose-spec/compose-go/v2/template"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/sirupsen/logrus"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// loaderLogMu serializes the compose loader runs that silence the standard
+// logrus logger
+var loaderLogMu sync.Mutex
+
+// Severity tells whether an issue blocks a deployment
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a compose file. Line and Column are 1-based,
+// or 0 when the problem is not tied to a position.
+type Issue struct {
+	Line     int      `json:"Line" yaml:"line"`
+	Column   int      `json:"Column" yaml:"column"`
+	Severity Severity `json:"Severity" yaml:"severity"`
+	Message  string   `json:"Message" yaml:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", i.Line, i.Column, i.Severity, i.Message)
+}
+
+// Options configures Lint
+type Options struct {
+	// Env holds the stack environment variables used for interpolation
+	Env map[string]string
+	// Swarm checks the file for a Swarm stack instead of a standalone
+	// Compose stack
+	Swarm bool
+}
+
+// HasErrors reports whether any of the issues is an error
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint checks a compose file against the Compose specification, the
+// environment variables it interpolates and what Portainer can deploy. The
+// issues are sorted by line.
+func Lint(content []byte, opts Options) []Issue {
+	l := &linter{opts: opts}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		l.issues = append(l.issues, syntaxIssue(err))
+		return l.issues
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		l.add(nil, SeverityError, "the file does not contain a compose mapping")
+		return l.issues
+	}
+	l.root = doc.Content[0]
+
+	l.checkSchema(content)
+	l.checkVariables(l.root)
+	l.checkPortainer()
+
+	// The compose loader reports semantic problems, such as services
+	// depending on undefined ones, but only the first and without a line
+	if !HasErrors(l.issues) {
+		l.checkLoad(content)
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		if l.issues[i].Line != l.issues[j].Line {
+			return l.issues[i].Line < l.issues[j].Line
+		}
+		return l.issues[i].Column < l.issues[j].Column
+	})
+	return l.issues
+}
+
+// syntaxIssue turns a YAML error such as "yaml: line 3: did not find
+// expected key" into an issue on that line
+func syntaxIssue(err error) Issue {
+	issue := Issue{Severity: SeverityError, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+	var line int
+	if _, scanErr := fmt.Sscanf(issue.Message, "line %d:", &line); scanErr == nil {
+		issue.Line, issue.Column = line, 1
+		issue.Message = strings.TrimSpace(issue.Message[strings.Index(issue.Message, ":")+1:])
+	}
+	return issue
+}
+
+type linter struct {
+	opts   Options
+	root   *yaml.Node
+	issues []Issue
+	seen   map[string]bool
+}
+
+func (l *linter) add(node *yaml.Node, severity Severity, format string, args ...interface{}) {
+	issue := Issue{Severity: severity, Message: fmt.Sprintf(format, args...)}
+	if node != nil {
+		issue.Line, issue.Column = node.Line, node.Column
+	}
+	l.issues = append(l.issues, issue)
+}
+
+// checkSchema validates the file against the Compose specification. Values
+// that are interpolated are only checked by checkLoad, once their variables
+// are substituted.
+func (l *linter) checkSchema(content []byte) {
+	dict, err := loader.ParseYAML(content)
+	if err != nil {
+		l.add(nil, SeverityError, "%v", err)
+		return
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewStringLoader(schema.Schema), gojsonschema.NewGoLoader(dict))
+	if err != nil {
+		l.add(nil, SeverityError, "failed to validate the file: %v", err)
+		return
+	}
+
+	// A value failing oneOf or anyOf also fails each alternative. Report
+	// the value once, with the description of the first alternative.
+	var alternatives []string
+	for _, e := range result.Errors() {
+		if e.Type() == "number_one_of" || e.Type() == "number_any_of" {
+			alternatives = append(alternatives, e.Field())
+		}
+	}
+
+	reported := map[string]bool{}
+	for _, e := range result.Errors() {
+		field := e.Field()
+		if e.Type() == "number_one_of" || e.Type() == "number_any_of" {
+			continue
+		}
+		for _, alt := range alternatives {
+			if field == alt || strings.HasPrefix(field, alt+".") {
+				field = alt
+				break
+			}
+		}
+		// Every unknown key of a mapping is an error of the same field
+		var property string
+		if e.Type() == "additional_property_not_allowed" {
+			property = fmt.Sprint(e.Details()["property"])
+		}
+		key := field + "\x00" + property
+		if reported[key] {
+			continue
+		}
+
+		path := fieldPath(field)
+		node := lookup(l.root, path)
+		if node != nil && node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "$") {
+			continue
+		}
+		reported[key] = true
+
+		if e.Type() == "additional_property_not_allowed" {
+			keyNode := lookupKey(node, property)
+			if keyNode == nil {
+				keyNode = node
+			}
+			if len(path) == 0 {
+				l.add(keyNode, SeverityError, "unknown top-level key '%s'", property)
+			} else {
+				l.add(keyNode, SeverityError, "unknown key '%s' in %s", property, field)
+			}
+			continue
+		}
+
+		name := field
+		if len(path) == 0 {
+			name = "the file"
+		}
+		l.add(node, SeverityError, "%s %s", name, describe(e))
+	}
+}
+
+func describe(e gojsonschema.ResultError) string {
+	if e.Type() == "invalid_type" {
+		if expected, ok := e.Details()["expected"].(string); ok {
+			return "must be " + readableType(expected)
+		}
+	}
+	description := e.Description()
+	if description == "" {
+		return "is invalid"
+	}
+	return strings.ToLower(description[:1]) + description[1:]
+}
+
+func readableType(expected string) string {
+	names := strings.Split(expected, ",")
+	for i, name := range names {
+		switch name {
+		case "array", "integer", "object":
+			names[i] = "an " + name
+		default:
+			names[i] = "a " + name
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+// checkVariables reports the interpolated variables that are not set.
+// Variables without a default are reported once, at their first use.
+func (l *linter) checkVariables(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		if !strings.Contains(node.Value, "$") {
+			return
+		}
+		variables := template.ExtractVariables(map[string]interface{}{"value": node.Value}, template.DefaultPattern)
+		names := make([]string, 0, len(variables))
+		for name := range variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if _, ok := l.opts.Env[name]; ok || l.seen[name] {
+				continue
+			}
+			v := variables[name]
+			switch {
+			case v.Required:
+				l.add(node, SeverityError, "required variable %s is not set", name)
+			case v.PresenceValue != "" || hasDefault(node.Value, name):
+				continue
+			default:
+				l.add(node, SeverityWarning, "variable %s is not set, an empty string is used", name)
+			}
+			if l.seen == nil {
+				l.seen = map[string]bool{}
+			}
+			l.seen[name] = true
+		}
+		return
+	}
+
+	for _, child := range node.Content {
+		l.checkVariables(child)
+	}
+}
+
+// hasDefault reports whether the variable is interpolated with a default,
+// as in ${NAME:-default} or ${NAME-default}
+func hasDefault(value, name string) bool {
+	return strings.Contains(value, "${"+name+":-") || strings.Contains(value, "${"+name+"-")
+}
+
+// checkPortainer reports what is valid Compose but cannot be deployed by
+// Portainer, which only receives the compose file: nothing next to it can
+// be built, included or mounted.
+func (l *linter) checkPortainer() {
+	if include := lookupKey(l.root, "include"); include != nil {
+		l.add(include, SeverityError, "include is not supported: Portainer only receives this file, merge the included files into it")
+	}
+	if version := lookupKey(l.root, "version"); version != nil {
+		l.add(version, SeverityWarning, "version is obsolete and ignored")
+	}
+
+	services := lookup(l.root, []string{"services"})
+	if services == nil || services.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		name, service := services.Content[i], services.Content[i+1]
+		if service.Kind != yaml.MappingNode {
+			continue
+		}
+		l.checkService(name, service)
+	}
+}
+
+func (l *linter) checkService(name, service *yaml.Node) {
+	build := lookupKey(service, "build")
+	if build != nil {
+		l.add(build, SeverityError, "service %s: build is not supported, push the image to a registry and use image", name.Value)
+	} else if lookupKey(service, "image") == nil && lookupKey(service, "extends") == nil {
+		l.add(name, SeverityError, "service %s has no image", name.Value)
+	}
+
+	if extends := lookup(service, []string{"extends"}); extends != nil {
+		if file := lookupKey(extends, "file"); file != nil {
+			l.add(file, SeverityError, "service %s: extends from another file is not supported, Portainer only receives this file", name.Value)
+		}
+	}
+	if envFile := lookupKey(service, "env_file"); envFile != nil {
+		l.add(envFile, SeverityWarning, "service %s: env_file is read on the Portainer server, not from this machine; prefer --env", name.Value)
+	}
+
+	if volumes := lookup(service, []string{"volumes"}); volumes != nil && volumes.Kind == yaml.SequenceNode {
+		for _, volume := range volumes.Content {
+			source := volume
+			if volume.Kind == yaml.MappingNode {
+				source = lookup(volume, []string{"source"})
+			}
+			if source == nil || source.Kind != yaml.ScalarNode {
+				continue
+			}
+			if path := strings.SplitN(source.Value, ":", 2)[0]; isRelativePath(path) {
+				l.add(source, SeverityWarning, "service %s: relative bind mount %s is resolved on the Portainer server, not from this machine", name.Value, path)
+			}
+		}
+	}
+
+	if l.opts.Swarm {
+		for _, key := range []string{"container_name", "depends_on", "restart", "links", "network_mode"} {
+			if node := lookupKey(service, key); node != nil {
+				l.add(node, SeverityWarning, "service %s: %s is ignored by Swarm", name.Value, key)
+			}
+		}
+	}
+}
+
+func isRelativePath(path string) bool {
+	return path == "." || path == ".." || strings.HasPrefix(path, "./") ||
+		strings.HasPrefix(path, "../") || strings.HasPrefix(path, "~")
+}
+
+// checkLoad loads the file with the compose loader, which interpolates it
+// and checks that it is consistent
+func (l *linter) checkLoad(content []byte) {
+	details := types.ConfigDetails{
+		WorkingDir:  ".",
+		ConfigFiles: []types.ConfigFile{{Filename: "stack.yml", Content: content}},
+		Environment: l.opts.Env,
+	}
+
+	// The loader logs warnings, such as unset variables, to the standard
+	// logrus logger; Lint reports them itself
+	loaderLogMu.Lock()
+	logger := logrus.StandardLogger()
+	out := logger.Out
+	logger.SetOutput(io.Discard)
+	_, err := loader.LoadWithContext(context.Background(), details, func(o *loader.Options) {
+		o.SetProjectName("stack", true)
+		o.SkipInclude = true
+		o.SkipExtends = true
+		o.SkipResolveEnvironment = true
+		o.ResolvePaths = false
+	})
+	logger.SetOutput(out)
+	loaderLogMu.Unlock()
+	if err != nil {
+		l.add(nil, SeverityError, "%s", strings.TrimPrefix(err.Error(), "validating stack.yml: "))
+	}
+}
+
+// fieldPath splits a JSON schema field such as services.web.ports.0
+func fieldPath(field string) []string {
+	if field == "" || field == "(root)" {
+		return nil
+	}
+	return strings.Split(field, ".")
+}
+
+// lookup returns the node at the path of mapping keys and sequence
+// indexes, or nil
+func lookup(node *yaml.Node, path []string) *yaml.Node {
+	for _, part := range path {
+		if node == nil {
+			return nil
+		}
+		switch node.Kind {
+		case yaml.MappingNode:
+			next := (*yaml.Node)(nil)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == part {
+					next = node.Content[i+1]
+					break
+				}
+			}
+			node = next
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[index]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+// lookupKey returns the key node of a mapping, for issues about the key
+func lookupKey(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package compose
+
+import (
+	"strings"
+	"testing"
+)
+
+func issueStrings(issues []Issue) []string {
+	out := make([]string, len(issues))
+	for i, issue := range issues {
+		out[i] = issue.String()
+	}
+	return out
+}
+
+func TestLint_Valid(t *testing.T) {
+	content := `services:
+  web:
+    image: nginx:${TAG:-1.25}
+    ports:
+      - "${PORT}:80"
+`
+	issues := Lint([]byte(content), Options{Env: map[string]string{"PORT": "8080"}})
+	if len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issueStrings(issues))
+	}
+}
+
+func TestLint_ReportsIssuesWithLines(t *testing.T) {
+	content := `version: "3.8"
+services:
+  web:
+    image: nginx
+    imagee: typo
+    build: .
+    volumes:
+      - ./html:/usr/share/nginx/html
+    environment:
+      PASSWORD: ${PASSWORD:?set a password}
+      DEBUG: ${DEBUG}
+      LEVEL: ${DEBUG}
+  worker:
+    command: run
+`
+	got := issueStrings(Lint([]byte(content), Options{}))
+	expected := []string{
+		"1:1: warning: version is obsolete and ignored",
+		"5:5: error: unknown key 'imagee' in services.web",
+		"6:5: error: service web: build is not supported, push the image to a registry and use image",
+		"8:9: warning: service web: relative bind mount ./html is resolved on the Portainer server, not from this machine",
+		"10:17: error: required variable PASSWORD is not set",
+		"11:14: warning: variable DEBUG is not set, an empty string is used",
+		"13:3: error: service worker has no image",
+	}
+
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected issues:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestLint_UnknownKeys(t *testing.T) {
+	content := `services:
+  web:
+    imagee: nginx
+    image: nginx
+    portz:
+      - "80:80"
+`
+	got := issueStrings(Lint([]byte(content), Options{}))
+	expected := []string{
+		"3:5: error: unknown key 'imagee' in services.web",
+		"5:5: error: unknown key 'portz' in services.web",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected issues:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestLint_SchemaTypes(t *testing.T) {
+	content := `services:
+  web:
+    image: nginx
+    deploy:
+      replicas: three
+  api:
+    image: api
+    deploy:
+      replicas: ${REPLICAS}
+`
+	issues := Lint([]byte(content), Options{Env: map[string]string{"REPLICAS": "2"}})
+	if len(issues) != 1 || issues[0].Line != 5 || issues[0].Message != "services.web.deploy.replicas must be an integer" {
+		t.Errorf("unexpected issues: %v", issueStrings(issues))
+	}
+}
+
+func TestLint_Swarm(t *testing.T) {
+	content := `services:
+  web:
+    image: nginx
+    container_name: web
+    restart: always
+`
+	if issues := Lint([]byte(content), Options{}); len(issues) != 0 {
+		t.Errorf("expected no issues for a Compose stack, got %v", issueStrings(issues))
+	}
+
+	issues := Lint([]byte(content), Options{Swarm: true})
+	if len(issues) != 2 || HasErrors(issues) {
+		t.Fatalf("expected 2 warnings, got %v", issueStrings(issues))
+	}
+	if issues[0].Message != "service web: container_name is ignored by Swarm" {
+		t.Errorf("unexpected message: %s", issues[0].Message)
+	}
+}
+
+func TestLint_SyntaxError(t *testing.T) {
+	issues := Lint([]byte("services:\n  web:\n    image: [nginx\n"), Options{})
+	if len(issues) != 1 || !HasErrors(issues) || issues[0].Line == 0 {
+		t.Errorf("expected a syntax error with a line, got %v", issueStrings(issues))
+	}
+}
+
+func TestLint_LoaderErrors(t *testing.T) {
+	content := `services:
+  web:
+    image: nginx
+    depends_on: [db]
+`
+	issues := Lint([]byte(content), Options{})
+	if len(issues) != 1 || !strings.Contains(issues[0].Message, `depends on undefined service "db"`) {
+		t.Errorf("unexpected issues: %v", issueStrings(issues))
+	}
+}
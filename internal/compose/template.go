@@ -0,0 +1,145 @@
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Values are the variables of a stack template, as read from values files
+// and --set flags. Nested maps are reached with dotted keys.
+type Values map[string]interface{}
+
+// noValue is what text/template prints for a value that is not set
+const noValue = "<no value>"
+
+// templateFuncs are the functions available to stack templates besides the
+// text/template builtins
+var templateFuncs = template.FuncMap{
+	// default returns def when value is not set or empty:
+	// {{ .tag | default "latest" }}
+	"default": func(def, value interface{}) interface{} {
+		if value == nil || value == "" {
+			return def
+		}
+		return value
+	},
+	// required fails the rendering when value is not set:
+	// {{ required "image is required" .image }}
+	"required": func(message string, value interface{}) (interface{}, error) {
+		if value == nil || value == "" {
+			return nil, fmt.Errorf("%s", message)
+		}
+		return value, nil
+	},
+	// quote returns value as a double-quoted YAML string
+	"quote": func(value interface{}) string {
+		if value == nil {
+			return `""`
+		}
+		return fmt.Sprintf("%q", fmt.Sprint(value))
+	},
+}
+
+// Render executes a stack file as a Go template with values, e.g.
+// "image: {{ .image }}:{{ .tag | default "latest" }}". Rendering fails when
+// the template uses a value that is not set, unless it gives a default.
+func Render(name string, content []byte, values Values) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stack template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]interface{}(values)); err != nil {
+		return nil, fmt.Errorf("failed to render stack template: %w", err)
+	}
+
+	rendered := buf.Bytes()
+	if i := bytes.Index(rendered, []byte(noValue)); i >= 0 {
+		line := bytes.Count(rendered[:i], []byte("\n")) + 1
+		return nil, fmt.Errorf("line %d of the rendered stack file: the template uses a value that is not set; set it with --set or --values, or give a default", line)
+	}
+	return rendered, nil
+}
+
+// ReadValues merges YAML values files into values, later files overriding
+// keys of earlier ones
+func ReadValues(values Values, paths ...string) error {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read values file: %w", err)
+		}
+		var file Values
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("failed to parse values file %s: %w", path, err)
+		}
+		mergeValues(values, file)
+	}
+	return nil
+}
+
+// SetValue sets a key=value assignment such as "web.replicas=3", creating
+// the nested maps of dotted keys. Integers and true/false are typed, so
+// {{ if .debug }} works with debug=false; anything else, such as the tag
+// 1.20, stays a string.
+func SetValue(values Values, assignment string) error {
+	key, raw, ok := strings.Cut(assignment, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("invalid value '%s': expected KEY=VALUE", assignment)
+	}
+
+	var value interface{} = raw
+	if n, err := strconv.Atoi(raw); err == nil && strconv.Itoa(n) == raw {
+		value = n
+	} else if raw == "true" || raw == "false" {
+		value = raw == "true"
+	}
+
+	parts := strings.Split(key, ".")
+	current := values
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(Values)
+		if !ok {
+			if m, isMap := current[part].(map[string]interface{}); isMap {
+				next = m
+			} else {
+				next = Values{}
+			}
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+	return nil
+}
+
+// mergeValues copies src into dst, merging nested maps
+func mergeValues(dst, src Values) {
+	for key, value := range src {
+		if srcMap, ok := asValues(value); ok {
+			if dstMap, ok := asValues(dst[key]); ok {
+				mergeValues(dstMap, srcMap)
+				dst[key] = dstMap
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
+func asValues(value interface{}) (Values, bool) {
+	switch m := value.(type) {
+	case Values:
+		return m, true
+	case map[string]interface{}:
+		return m, true
+	}
+	return nil, false
+}
@@ -0,0 +1,87 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	content := `services:
+  {{ .name }}:
+    image: {{ .image }}:{{ .tag | default "latest" }}
+    deploy:
+      replicas: {{ .web.replicas }}
+{{- if .debug }}
+    environment:
+      LOG_LEVEL: debug
+{{- end }}
+    labels:
+      team: {{ quote .team }}
+`
+	values := Values{}
+	for _, assignment := range []string{"name=web", "image=nginx", "web.replicas=3", "debug=false", "team=ops"} {
+		if err := SetValue(values, assignment); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	rendered, err := Render("stack.yml", []byte(content), values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `services:
+  web:
+    image: nginx:latest
+    deploy:
+      replicas: 3
+    labels:
+      team: "ops"
+`
+	if string(rendered) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, rendered)
+	}
+}
+
+func TestRender_MissingValue(t *testing.T) {
+	_, err := Render("stack.yml", []byte("services:\n  web:\n    image: {{ .image }}\n"), Values{})
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected an error naming line 3, got %v", err)
+	}
+
+	_, err = Render("stack.yml", []byte(`image: {{ required "image is required" .image }}`), Values{})
+	if err == nil || !strings.Contains(err.Error(), "image is required") {
+		t.Errorf("expected the required message, got %v", err)
+	}
+}
+
+func TestReadValues(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	prod := filepath.Join(dir, "prod.yaml")
+	os.WriteFile(base, []byte("tag: \"1.20\"\nweb:\n  replicas: 1\n  port: 80\n"), 0644)
+	os.WriteFile(prod, []byte("web:\n  replicas: 5\n"), 0644)
+
+	values := Values{}
+	if err := ReadValues(values, base, prod); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := SetValue(values, "web.port=8080"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rendered, err := Render("stack.yml", []byte("{{ .tag }} {{ .web.replicas }} {{ .web.port }}"), values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(rendered) != "1.20 5 8080" {
+		t.Errorf("expected merged values, got %q", rendered)
+	}
+}
+
+func TestSetValue_Invalid(t *testing.T) {
+	if err := SetValue(Values{}, "replicas"); err == nil {
+		t.Error("expected an error for a value without =")
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Bundle is a set of profiles exported for sharing, e.g. within a team
+type Bundle struct {
+	Profiles map[string]*Profile `yaml:"profiles"`
+}
+
+// Conflict strategies for importing a bundle
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+// ImportResult lists what happened to each imported profile
+type ImportResult struct {
+	Added   []string
+	Updated []string
+	Skipped []string
+	Renamed map[string]string
+}
+
+// Export copies the named profiles, or all profiles when none are named,
+// into a bundle. Unless includeSecrets is set, API keys and tokens are left
+// out so the bundle can be shared.
+func (c *Config) Export(names []string, includeSecrets bool) (*Bundle, error) {
+	if len(names) == 0 {
+		names = c.ListProfiles()
+	}
+
+	bundle := &Bundle{Profiles: make(map[string]*Profile, len(names))}
+	for _, name := range names {
+		profile, exists := c.Profiles[name]
+		if !exists {
+			return nil, fmt.Errorf("profile '%s' not found", name)
+		}
+
+		exported := *profile
+		exported.Name = ""
+		if !includeSecrets {
+			exported.APIKey = ""
+			exported.Token = ""
+		}
+		bundle.Profiles[name] = &exported
+	}
+
+	return bundle, nil
+}
+
+// Import merges the profiles of a bundle into the configuration. Profiles
+// that already exist are handled according to onConflict. Local secrets are
+// kept when an overwriting profile does not carry its own.
+func (c *Config) Import(bundle *Bundle, onConflict string) (*ImportResult, error) {
+	switch onConflict {
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return nil, fmt.Errorf("invalid conflict strategy '%s' (use %s, %s, or %s)", onConflict, ConflictSkip, ConflictOverwrite, ConflictRename)
+	}
+
+	names := make([]string, 0, len(bundle.Profiles))
+	for name := range bundle.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := &ImportResult{Renamed: map[string]string{}}
+	for _, name := range names {
+		incoming := *bundle.Profiles[name]
+		if err := incoming.validateImport(); err != nil {
+			return nil, fmt.Errorf("invalid profile '%s': %w", name, err)
+		}
+
+		existing, exists := c.Profiles[name]
+		if !exists {
+			c.SetProfile(name, &incoming)
+			result.Added = append(result.Added, name)
+			continue
+		}
+
+		switch onConflict {
+		case ConflictSkip:
+			result.Skipped = append(result.Skipped, name)
+
+		case ConflictOverwrite:
+			if incoming.APIKey == "" && incoming.Token == "" {
+				incoming.APIKey = existing.APIKey
+				incoming.Token = existing.Token
+			}
+			c.SetProfile(name, &incoming)
+			result.Updated = append(result.Updated, name)
+
+		case ConflictRename:
+			newName := c.availableProfileName(name + "-imported")
+			c.SetProfile(newName, &incoming)
+			result.Renamed[name] = newName
+		}
+	}
+
+	return result, nil
+}
+
+func (p *Profile) validateImport() error {
+	if p.URL == "" {
+		return fmt.Errorf("URL is required")
+	}
+	return nil
+}
+
+// availableProfileName returns name, or name with a numeric suffix when a
+// profile of that name exists
+func (c *Config) availableProfileName(name string) string {
+	candidate := name
+	for i := 2; ; i++ {
+		if _, exists := c.Profiles[candidate]; !exists {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+}
+
+// LoadBundle reads a bundle from a YAML file. A regular config file is also
+// accepted since it uses the same profiles layout.
+func LoadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	var bundle Bundle
+	if err := yaml.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+
+	for name, profile := range bundle.Profiles {
+		if profile == nil {
+			return nil, fmt.Errorf("profile '%s' in bundle is empty", name)
+		}
+	}
+
+	return &bundle, nil
+}
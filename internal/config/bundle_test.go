@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_Export(t *testing.T) {
+	cfg := &Config{}
+	cfg.SetProfile("prod", &Profile{URL: "https://prod.example.com", APIKey: "secret", Username: "admin"})
+	cfg.SetProfile("dev", &Profile{URL: "https://dev.example.com", Token: "jwt"})
+
+	bundle, err := cfg.Export(nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bundle.Profiles) != 2 {
+		t.Fatalf("expected 2 profiles, got %d", len(bundle.Profiles))
+	}
+	if p := bundle.Profiles["prod"]; p.APIKey != "" || p.Username != "admin" {
+		t.Errorf("expected secrets stripped and username kept, got %+v", p)
+	}
+	if bundle.Profiles["dev"].Token != "" {
+		t.Errorf("expected token to be stripped")
+	}
+	if cfg.Profiles["prod"].APIKey != "secret" {
+		t.Errorf("export must not modify the local profile")
+	}
+
+	bundle, err = cfg.Export([]string{"prod"}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bundle.Profiles) != 1 || bundle.Profiles["prod"].APIKey != "secret" {
+		t.Errorf("expected prod with secrets, got %+v", bundle.Profiles)
+	}
+
+	if _, err := cfg.Export([]string{"missing"}, false); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
+
+func TestConfig_Import(t *testing.T) {
+	newConfig := func() *Config {
+		cfg := &Config{}
+		cfg.SetProfile("prod", &Profile{URL: "https://old.example.com", APIKey: "local-key"})
+		return cfg
+	}
+	bundle := &Bundle{Profiles: map[string]*Profile{
+		"prod":    {URL: "https://prod.example.com"},
+		"staging": {URL: "https://staging.example.com"},
+	}}
+
+	cfg := newConfig()
+	result, err := cfg.Import(bundle, ConflictSkip)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Added) != 1 || len(result.Skipped) != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if cfg.Profiles["prod"].URL != "https://old.example.com" {
+		t.Errorf("expected prod to be kept")
+	}
+
+	cfg = newConfig()
+	if _, err := cfg.Import(bundle, ConflictOverwrite); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := cfg.Profiles["prod"]; p.URL != "https://prod.example.com" || p.APIKey != "local-key" {
+		t.Errorf("expected new URL with local secret, got %+v", p)
+	}
+
+	cfg = newConfig()
+	cfg.SetProfile("prod-imported", &Profile{URL: "https://x.example.com"})
+	result, err = cfg.Import(bundle, ConflictRename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Renamed["prod"] != "prod-imported-2" {
+		t.Errorf("expected prod-imported-2, got %v", result.Renamed)
+	}
+
+	if _, err := newConfig().Import(bundle, "merge"); err == nil {
+		t.Error("expected error for invalid strategy")
+	}
+	if _, err := newConfig().Import(&Bundle{Profiles: map[string]*Profile{"x": {}}}, ConflictSkip); err == nil {
+		t.Error("expected error for profile without URL")
+	}
+}
+
+func TestLoadBundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.yaml")
+	content := "profiles:\n  prod:\n    url: https://prod.example.com\n    username: admin\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle, err := LoadBundle(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := bundle.Profiles["prod"]; p == nil || p.URL != "https://prod.example.com" || p.Username != "admin" {
+		t.Errorf("unexpected bundle: %+v", bundle.Profiles)
+	}
+}
@@ -5,13 +5,26 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/spf13/viper"
+	"github.com/robversluis/portainer-cli/pkg/portainer"
 	"gopkg.in/yaml.v3"
 )
 
+// CurrentVersion is the version of the config file schema written by this
+// release. Files without a version predate versioning and are version 0.
+const CurrentVersion = 1
+
 type Config struct {
+	Version        int                 `yaml:"version" mapstructure:"version"`
 	CurrentProfile string              `yaml:"current_profile" mapstructure:"current_profile"`
 	Profiles       map[string]*Profile `yaml:"profiles" mapstructure:"profiles"`
+	Targets        map[string]*Target  `yaml:"targets,omitempty" mapstructure:"targets"`
+}
+
+// Target is a named combination of a profile and an environment, selected
+// with --target instead of --profile and --endpoint
+type Target struct {
+	Profile  string `yaml:"profile" mapstructure:"profile"`
+	Endpoint int    `yaml:"endpoint,omitempty" mapstructure:"endpoint"`
 }
 
 type Profile struct {
@@ -21,8 +34,20 @@ type Profile struct {
 	Username string `yaml:"username,omitempty" mapstructure:"username"`
 	Token    string `yaml:"token,omitempty" mapstructure:"token"`
 	Insecure bool   `yaml:"insecure,omitempty" mapstructure:"insecure"`
+	// Endpoint is the default environment ID of commands taking --endpoint
+	Endpoint int `yaml:"endpoint,omitempty" mapstructure:"endpoint"`
+	// RegistryMirror and ImagePrefix rewrite the images of deployed stacks
+	RegistryMirror string `yaml:"registry_mirror,omitempty" mapstructure:"registry_mirror"`
+	ImagePrefix    string `yaml:"image_prefix,omitempty" mapstructure:"image_prefix"`
+	// Retries and RetryDelay override the retry defaults of the client;
+	// RetryDelay is a duration such as 500ms or 2s
+	Retries    *int   `yaml:"retries,omitempty" mapstructure:"retries"`
+	RetryDelay string `yaml:"retry_delay,omitempty" mapstructure:"retry_delay"`
 }
 
+// GetConfigDir returns the configuration directory, following the XDG base
+// directory layout: $XDG_CONFIG_HOME/portainer-cli, or
+// ~/.config/portainer-cli when XDG_CONFIG_HOME is not set
 func GetConfigDir() (string, error) {
 	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
 		return filepath.Join(xdgConfig, "portainer-cli"), nil
@@ -33,6 +58,16 @@ func GetConfigDir() (string, error) {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
 
+	return filepath.Join(home, ".config", "portainer-cli"), nil
+}
+
+// GetLegacyConfigDir returns the directory used before the XDG layout
+func GetLegacyConfigDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
 	return filepath.Join(home, ".portainer-cli"), nil
 }
 
@@ -69,7 +104,12 @@ func Load() (*Config, error) {
 		}, nil
 	}
 
-	data, err := os.ReadFile(configPath)
+	return LoadFile(configPath)
+}
+
+// LoadFile reads the config file at path
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
@@ -79,6 +119,10 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	if cfg.Version > CurrentVersion {
+		return nil, fmt.Errorf("config file version %d is newer than supported version %d, please upgrade portainer-cli", cfg.Version, CurrentVersion)
+	}
+
 	if cfg.Profiles == nil {
 		cfg.Profiles = make(map[string]*Profile)
 	}
@@ -96,6 +140,8 @@ func (c *Config) Save() error {
 		return err
 	}
 
+	c.Version = CurrentVersion
+
 	data, err := yaml.Marshal(c)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
@@ -164,62 +210,109 @@ func (c *Config) ListProfiles() []string {
 	return profiles
 }
 
-func (p *Profile) Validate() error {
-	if p.URL == "" {
-		return fmt.Errorf("URL is required")
+// GetTarget returns a target by name
+func (c *Config) GetTarget(name string) (*Target, error) {
+	target, exists := c.Targets[name]
+	if !exists {
+		return nil, fmt.Errorf("target '%s' not found", name)
 	}
-
-	if p.APIKey == "" && p.Username == "" && p.Token == "" {
-		return fmt.Errorf("at least one authentication method is required (api_key, username, or token)")
+	if _, exists := c.Profiles[target.Profile]; !exists {
+		return nil, fmt.Errorf("profile '%s' of target '%s' not found", target.Profile, name)
 	}
+	return target, nil
+}
 
+// SetTarget adds or replaces a target. Its profile must exist.
+func (c *Config) SetTarget(name string, target *Target) error {
+	if _, exists := c.Profiles[target.Profile]; !exists {
+		return fmt.Errorf("profile '%s' not found", target.Profile)
+	}
+	if c.Targets == nil {
+		c.Targets = make(map[string]*Target)
+	}
+	c.Targets[name] = target
 	return nil
 }
 
-func GetCurrentProfile() (*Profile, error) {
-	cfg, err := Load()
-	if err != nil {
-		return nil, err
+func (c *Config) DeleteTarget(name string) error {
+	if _, exists := c.Targets[name]; !exists {
+		return fmt.Errorf("target '%s' not found", name)
 	}
+	delete(c.Targets, name)
+	return nil
+}
 
-	profileName := viper.GetString("current_profile")
-	if profileName == "" {
-		profileName = cfg.CurrentProfile
-	}
+func (p *Profile) Validate() error {
+	return p.ClientConfig().Validate()
+}
 
-	if profileName == "" {
-		return nil, fmt.Errorf("no current profile set")
+// ClientConfig returns the connection settings of the profile for creating
+// an API client
+func (p *Profile) ClientConfig() *portainer.Config {
+	return &portainer.Config{
+		URL:      p.URL,
+		APIKey:   p.APIKey,
+		Token:    p.Token,
+		Username: p.Username,
+		Insecure: p.Insecure,
 	}
+}
 
-	return cfg.GetProfile(profileName)
+// ProfileOverrides holds connection settings that take precedence over the
+// values stored in a profile, such as command-line flags and environment
+// variables. Empty fields and a nil Insecure keep the profile's value.
+type ProfileOverrides struct {
+	Profile  string
+	URL      string
+	APIKey   string
+	Username string
+	Token    string
+	Insecure *bool
 }
 
-func GetProfileFromViper() (*Profile, error) {
-	url := viper.GetString("url")
-	apiKey := viper.GetString("api_key")
-	username := viper.GetString("username")
-	token := viper.GetString("token")
-	insecure := viper.GetBool("insecure")
+// Resolve returns the effective connection settings: the profile named in
+// overrides, or the current profile, with the overrides applied. The result is
+// a copy, so changes to it are not saved. Without a profile the overrides
+// alone must provide a URL and credentials.
+func (c *Config) Resolve(overrides ProfileOverrides) (*Profile, error) {
+	name := overrides.Profile
+	if name == "" {
+		name = c.CurrentProfile
+	}
 
-	if url == "" {
-		profile, err := GetCurrentProfile()
-		if err != nil {
-			return nil, fmt.Errorf("no URL specified and failed to get current profile: %w", err)
+	resolved := &Profile{}
+	if name != "" {
+		stored, exists := c.Profiles[name]
+		switch {
+		case exists:
+			*resolved = *stored
+			resolved.Name = name
+		case overrides.Profile != "" || overrides.URL == "":
+			return nil, fmt.Errorf("profile '%s' not found", name)
 		}
-		return profile, nil
+	} else if overrides.URL == "" {
+		return nil, fmt.Errorf("no URL specified and no current profile set")
 	}
 
-	profile := &Profile{
-		URL:      url,
-		APIKey:   apiKey,
-		Username: username,
-		Token:    token,
-		Insecure: insecure,
+	if overrides.URL != "" {
+		resolved.URL = overrides.URL
+	}
+	if overrides.APIKey != "" {
+		resolved.APIKey = overrides.APIKey
+	}
+	if overrides.Username != "" {
+		resolved.Username = overrides.Username
+	}
+	if overrides.Token != "" {
+		resolved.Token = overrides.Token
+	}
+	if overrides.Insecure != nil {
+		resolved.Insecure = *overrides.Insecure
 	}
 
-	if err := profile.Validate(); err != nil {
+	if err := resolved.Validate(); err != nil {
 		return nil, err
 	}
 
-	return profile, nil
+	return resolved, nil
 }
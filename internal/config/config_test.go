@@ -34,6 +34,9 @@ func TestGetConfigDir(t *testing.T) {
 		if !filepath.IsAbs(dir) {
 			t.Error("config dir should be absolute path")
 		}
+		if filepath.Base(filepath.Dir(dir)) != ".config" {
+			t.Errorf("expected config dir under ~/.config, got %s", dir)
+		}
 	})
 }
 
@@ -367,3 +370,110 @@ func TestEnsureConfigDir(t *testing.T) {
 		t.Errorf("expected directory permissions 0700, got %o", info.Mode().Perm())
 	}
 }
+
+func TestConfig_Resolve(t *testing.T) {
+	cfg := &Config{
+		CurrentProfile: "prod",
+		Profiles: map[string]*Profile{
+			"prod":    {URL: "https://prod.example.com", Token: "prod-token", Insecure: true},
+			"staging": {URL: "https://staging.example.com", APIKey: "staging-key"},
+		},
+	}
+	insecure := false
+
+	tests := []struct {
+		name      string
+		overrides ProfileOverrides
+		expected  Profile
+		wantErr   bool
+	}{
+		{
+			name:      "current profile keeps token and insecure",
+			overrides: ProfileOverrides{},
+			expected:  Profile{Name: "prod", URL: "https://prod.example.com", Token: "prod-token", Insecure: true},
+		},
+		{
+			name:      "selected profile",
+			overrides: ProfileOverrides{Profile: "staging"},
+			expected:  Profile{Name: "staging", URL: "https://staging.example.com", APIKey: "staging-key"},
+		},
+		{
+			name:      "overrides applied on top of profile",
+			overrides: ProfileOverrides{Profile: "staging", URL: "https://other.example.com", Insecure: &insecure},
+			expected:  Profile{Name: "staging", URL: "https://other.example.com", APIKey: "staging-key"},
+		},
+		{
+			name:      "unknown profile",
+			overrides: ProfileOverrides{Profile: "missing"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := cfg.Resolve(tt.overrides)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *profile != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, *profile)
+			}
+		})
+	}
+
+	t.Run("does not modify stored profile", func(t *testing.T) {
+		profile, err := cfg.Resolve(ProfileOverrides{APIKey: "override"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if profile.APIKey != "override" || cfg.Profiles["prod"].APIKey != "" {
+			t.Error("override should only apply to the resolved copy")
+		}
+	})
+
+	t.Run("overrides without profile", func(t *testing.T) {
+		empty := &Config{}
+		if _, err := empty.Resolve(ProfileOverrides{}); err == nil {
+			t.Error("expected error without URL or profile")
+		}
+		profile, err := empty.Resolve(ProfileOverrides{URL: "http://localhost:9000", APIKey: "key"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if profile.URL != "http://localhost:9000" || profile.APIKey != "key" {
+			t.Errorf("unexpected profile %+v", profile)
+		}
+	})
+}
+
+func TestConfig_Targets(t *testing.T) {
+	cfg := &Config{Profiles: map[string]*Profile{"prod": {URL: "https://prod.example.com"}}}
+
+	if err := cfg.SetTarget("prod-web", &Target{Profile: "staging", Endpoint: 4}); err == nil {
+		t.Error("expected an error for a target of an unknown profile")
+	}
+	if err := cfg.SetTarget("prod-web", &Target{Profile: "prod", Endpoint: 4}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	target, err := cfg.GetTarget("prod-web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Profile != "prod" || target.Endpoint != 4 {
+		t.Errorf("unexpected target %+v", target)
+	}
+
+	if err := cfg.DeleteTarget("prod-web"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := cfg.GetTarget("prod-web"); err == nil {
+		t.Error("expected deleted target to be gone")
+	}
+}
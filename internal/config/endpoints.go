@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EndpointCache maps environment names to IDs per Portainer instance so
+// commands can address environments by name without listing them each time
+type EndpointCache struct {
+	Instances map[string]map[string]int `yaml:"instances"`
+}
+
+func GetEndpointCachePath() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "endpoints.yaml"), nil
+}
+
+// LoadEndpointCache reads the cache, returning an empty one when it does
+// not exist yet
+func LoadEndpointCache() (*EndpointCache, error) {
+	cache := &EndpointCache{Instances: make(map[string]map[string]int)}
+
+	cachePath, err := GetEndpointCachePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cache, nil
+		}
+		return nil, fmt.Errorf("failed to read endpoint cache: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("failed to parse endpoint cache: %w", err)
+	}
+
+	if cache.Instances == nil {
+		cache.Instances = make(map[string]map[string]int)
+	}
+
+	return cache, nil
+}
+
+func (c *EndpointCache) Save() error {
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	cachePath, err := GetEndpointCachePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal endpoint cache: %w", err)
+	}
+
+	if err := os.WriteFile(cachePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write endpoint cache: %w", err)
+	}
+
+	return nil
+}
+
+// Lookup returns the cached ID of an environment of the given instance
+func (c *EndpointCache) Lookup(instanceURL, name string) (int, bool) {
+	id, ok := c.Instances[instanceURL][name]
+	return id, ok
+}
+
+// Replace stores the complete name to ID mapping of an instance, dropping
+// environments that no longer exist
+func (c *EndpointCache) Replace(instanceURL string, endpoints map[string]int) {
+	if c.Instances == nil {
+		c.Instances = make(map[string]map[string]int)
+	}
+	c.Instances[instanceURL] = endpoints
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEndpointCache_SaveAndLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalXDG := os.Getenv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", tmpDir)
+	defer os.Setenv("XDG_CONFIG_HOME", originalXDG)
+
+	cache, err := LoadEndpointCache()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cache.Lookup("https://portainer.example.com", "prod"); ok {
+		t.Fatalf("expected empty cache")
+	}
+
+	cache.Replace("https://portainer.example.com", map[string]int{"prod": 3, "staging": 4})
+	cache.Replace("https://other.example.com", map[string]int{"prod": 1})
+	if err := cache.Save(); err != nil {
+		t.Fatalf("failed to save cache: %v", err)
+	}
+
+	loaded, err := LoadEndpointCache()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id, ok := loaded.Lookup("https://portainer.example.com", "prod"); !ok || id != 3 {
+		t.Errorf("expected prod=3, got %d (%v)", id, ok)
+	}
+	if id, ok := loaded.Lookup("https://other.example.com", "prod"); !ok || id != 1 {
+		t.Errorf("expected prod=1 on the other instance, got %d (%v)", id, ok)
+	}
+
+	loaded.Replace("https://portainer.example.com", map[string]int{"prod": 3})
+	if _, ok := loaded.Lookup("https://portainer.example.com", "staging"); ok {
+		t.Errorf("expected staging to be dropped after refresh")
+	}
+}
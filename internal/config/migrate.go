@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MigrationResult describes the changes made by Migrate
+type MigrationResult struct {
+	// From is the legacy config file that was moved, if any
+	From string
+	// To is the config file that was written
+	To string
+	// Backup is the copy of the file as it was before migrating
+	Backup string
+	// FromVersion is the schema version the config was upgraded from
+	FromVersion int
+}
+
+// Migrate moves a config file from the legacy ~/.portainer-cli directory to
+// the XDG location and upgrades it to the current schema version. The
+// original file is kept as a .bak backup. It returns nil when nothing had to
+// be migrated.
+func Migrate() (*MigrationResult, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	sourcePath := configPath
+	result := &MigrationResult{To: configPath}
+
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		legacyDir, err := GetLegacyConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		legacyPath := filepath.Join(legacyDir, "config.yaml")
+		if legacyPath == configPath {
+			return nil, nil
+		}
+		if _, err := os.Stat(legacyPath); os.IsNotExist(err) {
+			return nil, nil
+		}
+		sourcePath = legacyPath
+		result.From = legacyPath
+	}
+
+	data, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var versioned struct {
+		Version int `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(data, &versioned); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	result.FromVersion = versioned.Version
+
+	if result.From == "" && versioned.Version >= CurrentVersion {
+		return nil, nil
+	}
+
+	result.Backup = backupPath(sourcePath, versioned.Version)
+	if err := os.WriteFile(result.Backup, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to back up config file: %w", err)
+	}
+
+	cfg, err := LoadFile(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Save(); err != nil {
+		return nil, err
+	}
+
+	// The backup keeps the legacy contents; removing the original makes the
+	// new location the only one in use
+	if result.From != "" {
+		if err := os.Remove(result.From); err != nil {
+			return nil, fmt.Errorf("failed to remove legacy config file: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+func backupPath(path string, version int) string {
+	return fmt.Sprintf("%s.v%d.bak", path, version)
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupMigrationDirs(t *testing.T) (home, xdg string) {
+	t.Helper()
+	home = t.TempDir()
+	xdg = filepath.Join(home, "xdg")
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	return home, xdg
+}
+
+func TestMigrate_LegacyFile(t *testing.T) {
+	home, xdg := setupMigrationDirs(t)
+
+	legacyDir := filepath.Join(home, ".portainer-cli")
+	if err := os.MkdirAll(legacyDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	legacy := "current_profile: prod\nprofiles:\n  prod:\n    url: https://portainer.example.com\n    api_key: secret\n"
+	legacyPath := filepath.Join(legacyDir, "config.yaml")
+	if err := os.WriteFile(legacyPath, []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Migrate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil {
+		t.Fatal("expected a migration result")
+	}
+
+	expectedPath := filepath.Join(xdg, "portainer-cli", "config.yaml")
+	if result.From != legacyPath || result.To != expectedPath {
+		t.Errorf("unexpected paths: %+v", result)
+	}
+	if result.FromVersion != 0 {
+		t.Errorf("expected from version 0, got %d", result.FromVersion)
+	}
+
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Error("legacy config should be removed")
+	}
+	backup, err := os.ReadFile(result.Backup)
+	if err != nil {
+		t.Fatalf("backup not written: %v", err)
+	}
+	if string(backup) != legacy {
+		t.Error("backup should keep the original contents")
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("failed to load migrated config: %v", err)
+	}
+	if cfg.Version != CurrentVersion {
+		t.Errorf("expected version %d, got %d", CurrentVersion, cfg.Version)
+	}
+	if cfg.CurrentProfile != "prod" || cfg.Profiles["prod"].APIKey != "secret" {
+		t.Errorf("profiles not migrated: %+v", cfg)
+	}
+
+	result, err = Migrate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != nil {
+		t.Errorf("second migration should be a no-op, got %+v", result)
+	}
+}
+
+func TestMigrate_UnversionedFile(t *testing.T) {
+	_, xdg := setupMigrationDirs(t)
+
+	dir := filepath.Join(xdg, "portainer-cli")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("profiles:\n  dev:\n    url: http://localhost:9000\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Migrate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || result.From != "" {
+		t.Fatalf("expected an in-place upgrade, got %+v", result)
+	}
+	if !strings.HasSuffix(result.Backup, ".v0.bak") {
+		t.Errorf("unexpected backup path %s", result.Backup)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "version: 1") {
+		t.Errorf("expected version in upgraded file, got:\n%s", data)
+	}
+}
+
+func TestMigrate_NothingToDo(t *testing.T) {
+	setupMigrationDirs(t)
+
+	result, err := Migrate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != nil {
+		t.Errorf("expected no migration, got %+v", result)
+	}
+}
+
+func TestLoad_NewerVersion(t *testing.T) {
+	_, xdg := setupMigrationDirs(t)
+
+	dir := filepath.Join(xdg, "portainer-cli")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("version: 99\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(); err == nil {
+		t.Error("expected error for a newer config version")
+	}
+}
@@ -0,0 +1,262 @@
+package config
+
+import (
+	"fmt"
+	neturl "net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a problem found in a config file, with its position
+type ValidationError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// fieldKind is the expected YAML type of a config field
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindBool
+	kindInt
+	kindDuration
+)
+
+var profileFields = map[string]fieldKind{
+	"name":            kindString,
+	"url":             kindString,
+	"api_key":         kindString,
+	"username":        kindString,
+	"token":           kindString,
+	"insecure":        kindBool,
+	"endpoint":        kindInt,
+	"registry_mirror": kindString,
+	"image_prefix":    kindString,
+	"retries":         kindInt,
+	"retry_delay":     kindDuration,
+}
+
+// Validate checks config file contents against the config schema: unknown
+// keys, values of the wrong type, profiles without a URL, and a current
+// profile or target profiles that do not exist. All problems are returned,
+// ordered by position.
+func Validate(data []byte) []*ValidationError {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []*ValidationError{yamlSyntaxError(err)}
+	}
+
+	// An empty file is a valid, empty configuration
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	v := &validator{}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		v.addf(root, "expected a mapping at the top level")
+		return v.errors
+	}
+
+	var currentProfile *yaml.Node
+	var targetProfiles []*yaml.Node
+	profileNames := map[string]bool{}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+
+		switch key.Value {
+		case "version":
+			if value.Kind != yaml.ScalarNode || value.Tag != "!!int" {
+				v.addf(value, "version must be an integer")
+			} else if n, err := strconv.Atoi(value.Value); err != nil || n < 0 || n > CurrentVersion {
+				v.addf(value, "unsupported version %s (supported: %d)", value.Value, CurrentVersion)
+			}
+		case "current_profile":
+			if v.expectString(key.Value, value) {
+				currentProfile = value
+			}
+		case "profiles":
+			v.validateProfiles(value, profileNames)
+		case "targets":
+			targetProfiles = v.validateTargets(value)
+		default:
+			v.addf(key, "unknown key '%s'", key.Value)
+		}
+	}
+
+	if currentProfile != nil && currentProfile.Value != "" && !profileNames[currentProfile.Value] {
+		v.addf(currentProfile, "current_profile '%s' is not defined in profiles", currentProfile.Value)
+	}
+	for _, profile := range targetProfiles {
+		if !profileNames[profile.Value] {
+			v.addf(profile, "profile '%s' is not defined in profiles", profile.Value)
+		}
+	}
+
+	sort.SliceStable(v.errors, func(i, j int) bool {
+		if v.errors[i].Line != v.errors[j].Line {
+			return v.errors[i].Line < v.errors[j].Line
+		}
+		return v.errors[i].Column < v.errors[j].Column
+	})
+	return v.errors
+}
+
+type validator struct {
+	errors []*ValidationError
+}
+
+func (v *validator) addf(node *yaml.Node, format string, args ...interface{}) {
+	v.errors = append(v.errors, &ValidationError{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validateProfiles(node *yaml.Node, names map[string]bool) {
+	if node.Tag == "!!null" {
+		return
+	}
+	if node.Kind != yaml.MappingNode {
+		v.addf(node, "profiles must be a mapping of profile names to settings")
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		nameNode, profileNode := node.Content[i], node.Content[i+1]
+		names[nameNode.Value] = true
+
+		if profileNode.Kind != yaml.MappingNode {
+			v.addf(profileNode, "profile '%s' must be a mapping", nameNode.Value)
+			continue
+		}
+
+		hasURL := false
+		for j := 0; j+1 < len(profileNode.Content); j += 2 {
+			key, value := profileNode.Content[j], profileNode.Content[j+1]
+
+			kind, known := profileFields[key.Value]
+			if !known {
+				v.addf(key, "unknown key '%s' in profile '%s'", key.Value, nameNode.Value)
+				continue
+			}
+
+			switch kind {
+			case kindBool:
+				if value.Kind != yaml.ScalarNode || value.Tag != "!!bool" {
+					v.addf(value, "invalid boolean '%s' for %s (use true or false)", value.Value, key.Value)
+				}
+			case kindInt:
+				if value.Kind != yaml.ScalarNode || value.Tag != "!!int" {
+					v.addf(value, "invalid integer '%s' for %s", value.Value, key.Value)
+				}
+			case kindDuration:
+				if !v.expectString(key.Value, value) {
+					continue
+				}
+				if d, err := time.ParseDuration(value.Value); err != nil || d < 0 {
+					v.addf(value, "invalid duration '%s' for %s (e.g. 500ms or 2s)", value.Value, key.Value)
+				}
+			case kindString:
+				if !v.expectString(key.Value, value) {
+					continue
+				}
+				if key.Value == "url" {
+					hasURL = value.Value != ""
+					if hasURL {
+						v.validateURL(value)
+					}
+				}
+			}
+		}
+
+		if !hasURL {
+			v.addf(nameNode, "profile '%s' is missing a url", nameNode.Value)
+		}
+	}
+}
+
+// validateTargets checks the targets and returns the profile nodes they
+// reference, which are checked once all profiles are known
+func (v *validator) validateTargets(node *yaml.Node) []*yaml.Node {
+	if node.Tag == "!!null" {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		v.addf(node, "targets must be a mapping of target names to a profile and endpoint")
+		return nil
+	}
+
+	var profiles []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		nameNode, targetNode := node.Content[i], node.Content[i+1]
+		if targetNode.Kind != yaml.MappingNode {
+			v.addf(targetNode, "target '%s' must be a mapping", nameNode.Value)
+			continue
+		}
+
+		hasProfile := false
+		for j := 0; j+1 < len(targetNode.Content); j += 2 {
+			key, value := targetNode.Content[j], targetNode.Content[j+1]
+			switch key.Value {
+			case "profile":
+				if v.expectString(key.Value, value) && value.Value != "" {
+					hasProfile = true
+					profiles = append(profiles, value)
+				}
+			case "endpoint":
+				if value.Kind != yaml.ScalarNode || value.Tag != "!!int" {
+					v.addf(value, "invalid integer '%s' for endpoint", value.Value)
+				}
+			default:
+				v.addf(key, "unknown key '%s' in target '%s'", key.Value, nameNode.Value)
+			}
+		}
+
+		if !hasProfile {
+			v.addf(nameNode, "target '%s' is missing a profile", nameNode.Value)
+		}
+	}
+	return profiles
+}
+
+func (v *validator) expectString(field string, node *yaml.Node) bool {
+	if node.Kind != yaml.ScalarNode || node.Tag == "!!null" {
+		v.addf(node, "%s must be a string", field)
+		return false
+	}
+	return true
+}
+
+func (v *validator) validateURL(node *yaml.Node) {
+	parsed, err := neturl.Parse(node.Value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		v.addf(node, "invalid url '%s' (expected http:// or https://)", node.Value)
+	}
+}
+
+// yamlSyntaxError converts a YAML parse error, which carries its line in
+// the message, into a ValidationError
+func yamlSyntaxError(err error) *ValidationError {
+	var line int
+	if n, _ := fmt.Sscanf(err.Error(), "yaml: line %d:", &line); n == 1 {
+		message := strings.TrimPrefix(err.Error(), fmt.Sprintf("yaml: line %d:", line))
+		return &ValidationError{Line: line, Column: 1, Message: strings.TrimSpace(message)}
+	}
+	return &ValidationError{Message: err.Error()}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		errors []string
+	}{
+		{
+			name: "valid",
+			data: `current_profile: prod
+profiles:
+  prod:
+    url: https://prod.example.com
+    api_key: key
+    insecure: false
+`,
+		},
+		{
+			name: "versioned",
+			data: `version: 1
+profiles:
+  prod:
+    url: https://prod.example.com
+`,
+		},
+		{
+			name:   "unsupported version",
+			data:   "version: 7\n",
+			errors: []string{"line 1, column 10: unsupported version 7"},
+		},
+		{
+			name: "empty",
+			data: "",
+		},
+		{
+			name: "unknown keys",
+			data: `current_profile: prod
+colour: true
+profiles:
+  prod:
+    url: https://prod.example.com
+    apikey: key
+`,
+			errors: []string{"line 2, column 1: unknown key 'colour'", "line 6, column 5: unknown key 'apikey' in profile 'prod'"},
+		},
+		{
+			name: "invalid boolean and missing url",
+			data: `profiles:
+  dev:
+    insecure: yes
+`,
+			errors: []string{"line 2, column 3: profile 'dev' is missing a url", "line 3, column 15: invalid boolean 'yes'"},
+		},
+		{
+			name: "invalid endpoint",
+			data: `profiles:
+  dev:
+    url: https://dev.example.com
+    endpoint: local
+`,
+			errors: []string{"line 4, column 15: invalid integer 'local' for endpoint"},
+		},
+		{
+			name: "targets",
+			data: `targets:
+  prod-web: {profile: prod, endpoint: 4}
+  staging-web: {profile: staging, endpoint: web}
+  orphan: {endpoint: 1}
+profiles:
+  prod:
+    url: https://prod.example.com
+`,
+			errors: []string{
+				"line 3, column 26: profile 'staging' is not defined in profiles",
+				"line 3, column 45: invalid integer 'web' for endpoint",
+				"line 4, column 3: target 'orphan' is missing a profile",
+			},
+		},
+		{
+			name: "invalid retry delay",
+			data: `profiles:
+  dev:
+    url: https://dev.example.com
+    retries: 5
+    retry_delay: 2
+`,
+			errors: []string{"line 5, column 18: invalid duration '2' for retry_delay"},
+		},
+		{
+			name: "bad url and unknown current profile",
+			data: `current_profile: staging
+profiles:
+  prod:
+    url: portainer.example.com
+`,
+			errors: []string{"line 1, column 18: current_profile 'staging' is not defined", "line 4, column 10: invalid url"},
+		},
+		{
+			name:   "syntax error",
+			data:   "profiles:\n  prod:\n    url: [\n",
+			errors: []string{"line "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Validate([]byte(tt.data))
+			if len(errs) != len(tt.errors) {
+				t.Fatalf("expected %d errors, got %v", len(tt.errors), errs)
+			}
+			for i, want := range tt.errors {
+				if !strings.HasPrefix(errs[i].Error(), want) {
+					t.Errorf("expected error starting with %q, got %q", want, errs[i].Error())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VersionCache records the server version of each Portainer instance, so
+// the version is only requested once per instance and again after VersionTTL
+type VersionCache struct {
+	Instances map[string]ServerVersion `yaml:"instances"`
+}
+
+// ServerVersion is the cached version of a Portainer instance
+type ServerVersion struct {
+	Version   string    `yaml:"version"`
+	CheckedAt time.Time `yaml:"checked_at"`
+}
+
+// VersionTTL is how long a cached version is used, so server upgrades are
+// noticed
+const VersionTTL = 24 * time.Hour
+
+func GetVersionCachePath() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "versions.yaml"), nil
+}
+
+// LoadVersionCache reads the cache, returning an empty one when it does not
+// exist yet
+func LoadVersionCache() (*VersionCache, error) {
+	cache := &VersionCache{Instances: make(map[string]ServerVersion)}
+
+	cachePath, err := GetVersionCachePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cache, nil
+		}
+		return nil, fmt.Errorf("failed to read version cache: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cache); err != nil {
+		return nil, fmt.Errorf("failed to parse version cache: %w", err)
+	}
+
+	if cache.Instances == nil {
+		cache.Instances = make(map[string]ServerVersion)
+	}
+
+	return cache, nil
+}
+
+func (c *VersionCache) Save() error {
+	if err := EnsureConfigDir(); err != nil {
+		return err
+	}
+
+	cachePath, err := GetVersionCachePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal version cache: %w", err)
+	}
+
+	if err := os.WriteFile(cachePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write version cache: %w", err)
+	}
+
+	return nil
+}
+
+// Lookup returns the cached version of an instance unless it is older than
+// VersionTTL at now
+func (c *VersionCache) Lookup(instanceURL string, now time.Time) (string, bool) {
+	cached, ok := c.Instances[instanceURL]
+	if !ok || now.Sub(cached.CheckedAt) > VersionTTL {
+		return "", false
+	}
+	return cached.Version, true
+}
+
+// Set records the version of an instance as checked at now
+func (c *VersionCache) Set(instanceURL, version string, now time.Time) {
+	if c.Instances == nil {
+		c.Instances = make(map[string]ServerVersion)
+	}
+	c.Instances[instanceURL] = ServerVersion{Version: version, CheckedAt: now}
+}
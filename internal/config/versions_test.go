@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVersionCache_SaveAndLoad(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	now := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	cache, err := LoadVersionCache()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cache.Lookup("https://portainer.example.com", now); ok {
+		t.Fatalf("expected empty cache")
+	}
+
+	cache.Set("https://portainer.example.com", "2.19.4", now)
+	if err := cache.Save(); err != nil {
+		t.Fatalf("failed to save cache: %v", err)
+	}
+
+	loaded, err := LoadVersionCache()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version, ok := loaded.Lookup("https://portainer.example.com", now.Add(time.Hour)); !ok || version != "2.19.4" {
+		t.Errorf("expected 2.19.4, got %q (%v)", version, ok)
+	}
+	if _, ok := loaded.Lookup("https://portainer.example.com", now.Add(VersionTTL+time.Minute)); ok {
+		t.Error("expected the version to expire")
+	}
+}
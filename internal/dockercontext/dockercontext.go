@@ -0,0 +1,113 @@
+// Package dockercontext writes contexts to the context store of the Docker
+// CLI, so docker and tools using its contexts can reach an environment.
+package dockercontext
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Context is a Docker CLI context with a Docker endpoint
+type Context struct {
+	Name        string
+	Description string
+	// Host is the address of the Docker API, e.g.
+	// tcp://portainer.example.com:443/api/endpoints/3/docker
+	Host          string
+	SkipTLSVerify bool
+	// CA is the PEM-encoded certificate authority of Host. The Docker CLI
+	// only uses TLS for a context with a CA or SkipTLSVerify.
+	CA []byte
+}
+
+// meta is the meta.json file of a context in the store
+type meta struct {
+	Name      string                  `json:"Name"`
+	Metadata  metadata                `json:"Metadata"`
+	Endpoints map[string]endpointMeta `json:"Endpoints"`
+}
+
+type metadata struct {
+	Description string `json:"Description,omitempty"`
+}
+
+type endpointMeta struct {
+	Host          string `json:"Host"`
+	SkipTLSVerify bool   `json:"SkipTLSVerify"`
+}
+
+// DefaultDir returns the configuration directory of the Docker CLI:
+// DOCKER_CONFIG, or ~/.docker
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".docker"), nil
+}
+
+// contextID returns the directory name of a context in the store
+func contextID(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
+
+// MetaPath returns the path of the meta.json file of a context in the
+// Docker configuration directory dir
+func MetaPath(dir, name string) string {
+	return filepath.Join(dir, "contexts", "meta", contextID(name), "meta.json")
+}
+
+// Exists reports whether the store in dir has a context named name
+func Exists(dir, name string) bool {
+	_, err := os.Stat(MetaPath(dir, name))
+	return err == nil
+}
+
+// Write adds a context to the store in the Docker configuration directory
+// dir, replacing a context with the same name
+func Write(dir string, c *Context) error {
+	data, err := json.Marshal(meta{
+		Name:     c.Name,
+		Metadata: metadata{Description: c.Description},
+		Endpoints: map[string]endpointMeta{
+			"docker": {Host: c.Host, SkipTLSVerify: c.SkipTLSVerify},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode docker context: %w", err)
+	}
+
+	metaPath := MetaPath(dir, c.Name)
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0700); err != nil {
+		return fmt.Errorf("failed to create docker context directory: %w", err)
+	}
+	if err := os.WriteFile(metaPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write docker context: %w", err)
+	}
+
+	// A replaced context must not keep the certificate of its predecessor
+	tlsDir := filepath.Join(dir, "contexts", "tls", contextID(c.Name))
+	if err := os.RemoveAll(tlsDir); err != nil {
+		return fmt.Errorf("failed to remove docker context certificates: %w", err)
+	}
+	if len(c.CA) == 0 {
+		return nil
+	}
+	caPath := filepath.Join(tlsDir, "docker", "ca.pem")
+	if err := os.MkdirAll(filepath.Dir(caPath), 0700); err != nil {
+		return fmt.Errorf("failed to create docker context directory: %w", err)
+	}
+	if err := os.WriteFile(caPath, c.CA, 0600); err != nil {
+		return fmt.Errorf("failed to write docker context certificate: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package dockercontext
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	c := &Context{
+		Name:        "portainer-prod",
+		Description: "Portainer environment prod (3)",
+		Host:        "tcp://portainer.example.com:443/api/endpoints/3/docker",
+		CA:          []byte("-----BEGIN CERTIFICATE-----\n"),
+	}
+	if Exists(dir, c.Name) {
+		t.Fatal("expected context not to exist")
+	}
+	if err := Write(dir, c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !Exists(dir, c.Name) {
+		t.Fatal("expected context to exist")
+	}
+
+	data, err := os.ReadFile(MetaPath(dir, c.Name))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var m struct {
+		Name      string
+		Metadata  struct{ Description string }
+		Endpoints map[string]struct {
+			Host          string
+			SkipTLSVerify bool
+		}
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("failed to parse meta.json: %v", err)
+	}
+	if m.Name != c.Name || m.Metadata.Description != c.Description || m.Endpoints["docker"].Host != c.Host {
+		t.Errorf("unexpected meta.json: %s", data)
+	}
+
+	caPath := filepath.Join(dir, "contexts", "tls", contextID(c.Name), "docker", "ca.pem")
+	if _, err := os.Stat(caPath); err != nil {
+		t.Errorf("expected CA to be written: %v", err)
+	}
+
+	// Replacing the context without a CA removes the previous one
+	c.CA = nil
+	c.SkipTLSVerify = true
+	if err := Write(dir, c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(caPath); !os.IsNotExist(err) {
+		t.Errorf("expected CA to be removed, got %v", err)
+	}
+}
+
+func TestContextID(t *testing.T) {
+	// sha256("default"), as computed by the Docker CLI
+	expected := "37a8eec1ce19687d132fe29051dca629d164e2c4958ba141d5f4133a33f0688f"
+	if id := contextID("default"); id != expected {
+		t.Errorf("expected %s, got %s", expected, id)
+	}
+}
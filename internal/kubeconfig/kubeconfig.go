@@ -0,0 +1,126 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// namedLists are the kubeconfig sections whose entries are identified by name
+var namedLists = []string{"clusters", "users", "contexts"}
+
+// DefaultPath returns the kubeconfig kubectl uses: the first entry of
+// KUBECONFIG, or ~/.kube/config
+func DefaultPath() (string, error) {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return filepath.SplitList(env)[0], nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// Merge adds the clusters, users, and contexts of incoming to existing,
+// replacing entries with the same name. When useContext is true the current
+// context is switched to the one of incoming.
+func Merge(existing, incoming []byte, useContext bool) ([]byte, error) {
+	base := map[string]interface{}{}
+	if err := yaml.Unmarshal(existing, &base); err != nil {
+		return nil, fmt.Errorf("failed to parse existing kubeconfig: %w", err)
+	}
+	if base == nil {
+		base = map[string]interface{}{}
+	}
+
+	var update map[string]interface{}
+	if err := yaml.Unmarshal(incoming, &update); err != nil {
+		return nil, fmt.Errorf("failed to parse downloaded kubeconfig: %w", err)
+	}
+
+	if _, ok := base["apiVersion"]; !ok {
+		base["apiVersion"] = "v1"
+	}
+	if _, ok := base["kind"]; !ok {
+		base["kind"] = "Config"
+	}
+
+	for _, key := range namedLists {
+		merged, err := mergeNamed(base[key], update[key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s section: %w", key, err)
+		}
+		base[key] = merged
+	}
+
+	if current, ok := update["current-context"].(string); ok && current != "" {
+		if useContext || base["current-context"] == nil || base["current-context"] == "" {
+			base["current-context"] = current
+		}
+	}
+
+	return yaml.Marshal(base)
+}
+
+func mergeNamed(existing, incoming interface{}) ([]interface{}, error) {
+	result, err := asList(existing)
+	if err != nil {
+		return nil, err
+	}
+	additions, err := asList(incoming)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range additions {
+		name := entryName(entry)
+		replaced := false
+		for i, current := range result {
+			if name != "" && entryName(current) == name {
+				result[i] = entry
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func asList(value interface{}) ([]interface{}, error) {
+	if value == nil {
+		return []interface{}{}, nil
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a list, got %T", value)
+	}
+	return list, nil
+}
+
+func entryName(entry interface{}) string {
+	if m, ok := entry.(map[string]interface{}); ok {
+		if name, ok := m["name"].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// WriteFile writes a kubeconfig readable only by the current user, creating
+// the parent directory if needed
+func WriteFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create kubeconfig directory: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package kubeconfig
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const existingConfig = `apiVersion: v1
+kind: Config
+current-context: minikube
+clusters:
+- name: minikube
+  cluster:
+    server: https://192.168.49.2:8443
+- name: portainer-ctx-prod
+  cluster:
+    server: https://old.example.com
+contexts:
+- name: minikube
+  context:
+    cluster: minikube
+    user: minikube
+users:
+- name: minikube
+  user:
+    token: abc
+`
+
+const downloadedConfig = `apiVersion: v1
+kind: Config
+current-context: portainer-ctx-prod
+clusters:
+- name: portainer-ctx-prod
+  cluster:
+    server: https://portainer.example.com/api/endpoints/1/kubernetes
+contexts:
+- name: portainer-ctx-prod
+  context:
+    cluster: portainer-ctx-prod
+    user: portainer-sa-clusteradmin
+users:
+- name: portainer-sa-clusteradmin
+  user:
+    token: xyz
+`
+
+type testConfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server string `yaml:"server"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Contexts []struct {
+		Name string `yaml:"name"`
+	} `yaml:"contexts"`
+	Users []struct {
+		Name string `yaml:"name"`
+	} `yaml:"users"`
+}
+
+func TestMerge(t *testing.T) {
+	merged, err := Merge([]byte(existingConfig), []byte(downloadedConfig), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var config testConfig
+	if err := yaml.Unmarshal(merged, &config); err != nil {
+		t.Fatalf("failed to parse merged config: %v", err)
+	}
+
+	if config.CurrentContext != "portainer-ctx-prod" {
+		t.Errorf("expected current context portainer-ctx-prod, got %s", config.CurrentContext)
+	}
+	if len(config.Clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(config.Clusters))
+	}
+	if server := config.Clusters[1].Cluster.Server; server != "https://portainer.example.com/api/endpoints/1/kubernetes" {
+		t.Errorf("expected existing cluster to be replaced, got server %s", server)
+	}
+	if len(config.Contexts) != 2 || len(config.Users) != 2 {
+		t.Errorf("expected 2 contexts and 2 users, got %d and %d", len(config.Contexts), len(config.Users))
+	}
+}
+
+func TestMerge_KeepsCurrentContext(t *testing.T) {
+	merged, err := Merge([]byte(existingConfig), []byte(downloadedConfig), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var config testConfig
+	if err := yaml.Unmarshal(merged, &config); err != nil {
+		t.Fatalf("failed to parse merged config: %v", err)
+	}
+	if config.CurrentContext != "minikube" {
+		t.Errorf("expected current context minikube, got %s", config.CurrentContext)
+	}
+}
+
+func TestMerge_EmptyExisting(t *testing.T) {
+	merged, err := Merge(nil, []byte(downloadedConfig), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var config testConfig
+	if err := yaml.Unmarshal(merged, &config); err != nil {
+		t.Fatalf("failed to parse merged config: %v", err)
+	}
+	if config.CurrentContext != "portainer-ctx-prod" || len(config.Clusters) != 1 {
+		t.Errorf("unexpected merged config: %+v", config)
+	}
+}
@@ -0,0 +1,171 @@
+// Package logfile writes log output to a file that is rotated by size, so
+// long-running log sessions do not fill the disk.
+package logfile
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Options configures rotation
+type Options struct {
+	// MaxSize is the size in bytes after which the file is rotated. Zero
+	// disables rotation.
+	MaxSize int64
+	// MaxBackups is the number of rotated files to keep, named path.1 (the
+	// most recent) to path.N. Zero keeps them all.
+	MaxBackups int
+	// Compress gzips rotated files, which are then named path.N.gz
+	Compress bool
+}
+
+// Writer appends to a log file and rotates it once it grows beyond
+// Options.MaxSize. Each Write goes to a single file, so writing whole lines
+// keeps lines from being split across files.
+type Writer struct {
+	path string
+	opts Options
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Open opens path for appending, creating it when needed
+func Open(path string, opts Options) (*Writer, error) {
+	w := &Writer{path: path, opts: opts}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// Write appends p to the log file, rotating it first when p would take it
+// beyond the maximum size
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if w.opts.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.opts.MaxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// rotate shifts the backups up by one, moves the current file to path.1 and
+// starts a new file
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	w.file = nil
+
+	ext := ""
+	if w.opts.Compress {
+		ext = ".gz"
+	}
+
+	// Find the highest backup so nothing is overwritten when MaxBackups is
+	// not set
+	last := 0
+	for {
+		if _, err := os.Stat(w.backupName(last+1, ext)); err != nil {
+			break
+		}
+		last++
+	}
+	for n := last; n >= 1; n-- {
+		if w.opts.MaxBackups > 0 && n >= w.opts.MaxBackups {
+			if err := os.Remove(w.backupName(n, ext)); err != nil {
+				return fmt.Errorf("failed to remove old log file: %w", err)
+			}
+			continue
+		}
+		if err := os.Rename(w.backupName(n, ext), w.backupName(n+1, ext)); err != nil {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+
+	if w.opts.Compress {
+		if err := compressFile(w.path, w.backupName(1, ext)); err != nil {
+			return err
+		}
+	} else if err := os.Rename(w.path, w.backupName(1, ext)); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	return w.open()
+}
+
+func (w *Writer) backupName(n int, ext string) string {
+	return fmt.Sprintf("%s.%d%s", w.path, n, ext)
+}
+
+// compressFile gzips src into dst and removes src
+func compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create compressed log file: %w", err)
+	}
+
+	gz := gzip.NewWriter(out)
+	_, err = io.Copy(gz, in)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("failed to compress log file: %w", err)
+	}
+
+	return os.Remove(src)
+}
@@ -0,0 +1,76 @@
+package logfile
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriter_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	w, err := Open(path, Options{MaxSize: 10, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	for _, line := range []string{"line one\n", "line two\n", "line three\n", "line four\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("failed to write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	expected := map[string]string{
+		path:        "line four\n",
+		path + ".1": "line three\n",
+		path + ".2": "line two\n",
+	}
+	for file, content := range expected {
+		data, err := os.ReadFile(file)
+		if err != nil || string(data) != content {
+			t.Errorf("%s: expected %q, got %q (%v)", filepath.Base(file), content, data, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups to be kept")
+	}
+}
+
+func TestWriter_Compress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("existing\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := Open(path, Options{MaxSize: 10, Compress: true})
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	if _, err := w.Write([]byte("appended\n")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	w.Close()
+
+	f, err := os.Open(path + ".1.gz")
+	if err != nil {
+		t.Fatalf("expected a compressed backup: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("invalid gzip file: %v", err)
+	}
+	data, _ := io.ReadAll(gz)
+	if string(data) != "existing\n" {
+		t.Errorf("unexpected backup content %q", data)
+	}
+
+	current, _ := os.ReadFile(path)
+	if string(current) != "appended\n" {
+		t.Errorf("unexpected log content %q", current)
+	}
+}
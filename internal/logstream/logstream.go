@@ -0,0 +1,279 @@
+// Package logstream interleaves the Docker log streams of several containers
+// line by line, prefixing each line with the name of the container it came
+// from, like "kubectl logs -l" or stern.
+package logstream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLineSize bounds the length of a single log line
+const maxLineSize = 1024 * 1024
+
+// palette holds the prefix colors, assigned to sources in order
+var palette = []string{
+	"\033[36m", // cyan
+	"\033[33m", // yellow
+	"\033[32m", // green
+	"\033[35m", // magenta
+	"\033[34m", // blue
+	"\033[91m", // bright red
+	"\033[96m", // bright cyan
+	"\033[93m", // bright yellow
+}
+
+const (
+	colorReset = "\033[0m"
+	// highlightColor is bold red, as used by grep --color
+	highlightColor = "\033[1;31m"
+)
+
+// Source is the log stream of one container
+type Source struct {
+	// Name is shown in the line prefix
+	Name   string
+	Reader io.ReadCloser
+}
+
+// Options controls how lines are written
+type Options struct {
+	// Prefix starts each line with the source name in brackets, padded so
+	// the log lines of all sources line up
+	Prefix bool
+	// Color gives each source's prefix its own color and enables Highlight
+	Color bool
+	// Filter keeps only the lines matching it when set
+	Filter *regexp.Regexp
+	// Highlight marks the parts of lines matching it when set
+	Highlight *regexp.Regexp
+	// JSON writes each line as an Entry object instead of text. Prefix,
+	// Color and Highlight do not apply.
+	JSON bool
+}
+
+// Entry is a log line in JSON output
+type Entry struct {
+	// Timestamp is the time Docker received the line, empty when the
+	// stream has no timestamps
+	Timestamp string `json:"timestamp"`
+	// Stream is stdout or stderr
+	Stream    string `json:"stream"`
+	Container string `json:"container"`
+	Message   string `json:"message"`
+}
+
+// Multiplex reads the sources concurrently and writes their lines to w as
+// they arrive. Each source is buffered until a line is complete, so lines
+// of different sources never mix. The Docker stream headers of multiplexed
+// stdout/stderr logs are removed. Multiplex returns once all sources have
+// ended, with the errors of the sources that failed, and closes them. A
+// write error closes all sources so followed streams stop too.
+func Multiplex(w io.Writer, sources []Source, opts Options) error {
+	var closeOnce sync.Once
+	closeAll := func() {
+		closeOnce.Do(func() {
+			for _, source := range sources {
+				source.Reader.Close()
+			}
+		})
+	}
+	defer closeAll()
+
+	width := 0
+	for _, source := range sources {
+		width = max(width, len(source.Name))
+	}
+
+	prefixes := make([]string, len(sources))
+	if opts.Prefix {
+		for i, source := range sources {
+			prefix := fmt.Sprintf("[%s]%s ", source.Name, strings.Repeat(" ", width-len(source.Name)))
+			if opts.Color {
+				prefix = palette[i%len(palette)] + prefix + colorReset
+			}
+			prefixes[i] = prefix
+		}
+	}
+
+	var mu sync.Mutex
+	var writeErr error
+	errs := make([]error, len(sources))
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source Source) {
+			defer wg.Done()
+
+			err := scanLines(source.Reader, func(stream, message string) error {
+				if opts.Filter != nil && !opts.Filter.MatchString(message) {
+					return nil
+				}
+
+				var line string
+				if opts.JSON {
+					timestamp, text := splitTimestamp(message)
+					data, err := json.Marshal(Entry{
+						Timestamp: timestamp,
+						Stream:    stream,
+						Container: source.Name,
+						Message:   text,
+					})
+					if err != nil {
+						return err
+					}
+					line = string(data) + "\n"
+				} else {
+					if opts.Highlight != nil && opts.Color {
+						message = highlight(message, opts.Highlight)
+					}
+					line = prefixes[i] + message + "\n"
+				}
+
+				mu.Lock()
+				if writeErr == nil {
+					_, writeErr = io.WriteString(w, line)
+				}
+				failed := writeErr != nil
+				mu.Unlock()
+				if failed {
+					closeAll()
+					return errWriteFailed
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, errWriteFailed) {
+				errs[i] = fmt.Errorf("%s: %w", source.Name, err)
+			}
+		}(i, source)
+	}
+	wg.Wait()
+
+	if writeErr != nil {
+		// Reading the closed sources failed as well
+		return fmt.Errorf("failed to write logs: %w", writeErr)
+	}
+	return errors.Join(errs...)
+}
+
+// errWriteFailed stops reading a source once writing the output failed
+var errWriteFailed = errors.New("write failed")
+
+// headerSize is the size of the header Docker puts in front of each frame of
+// a multiplexed stdout/stderr stream: the stream, three zero bytes and the
+// big-endian length of the frame
+const headerSize = 8
+
+// scanLines calls fn with each line of a log stream and the stream it was
+// written to, stopping at the first error fn returns. Multiplexed streams
+// are split into frames by their length before the frames are split into
+// lines, so the headers never end up in the lines, whatever bytes their
+// lengths contain. Streams of containers with a TTY have no frames and are
+// all stdout.
+func scanLines(r io.Reader, fn func(stream, line string) error) error {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	head, err := reader.Peek(headerSize)
+	if len(head) < headerSize && err != nil && err != io.EOF {
+		return err
+	}
+	if !isHeader(head) {
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+		for scanner.Scan() {
+			if err := fn("stdout", scanner.Text()); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	}
+
+	// Lines may span frames, so the partial line of each stream is kept
+	// until its end arrives
+	pending := map[string][]byte{}
+	header := make([]byte, headerSize)
+	chunk := make([]byte, 32*1024)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		stream := "stdout"
+		if header[0] == 2 {
+			stream = "stderr"
+		}
+
+		for size := int(binary.BigEndian.Uint32(header[4:])); size > 0; {
+			n, err := io.ReadFull(reader, chunk[:min(size, len(chunk))])
+			if err != nil {
+				return err
+			}
+			size -= n
+
+			buf := append(pending[stream], chunk[:n]...)
+			for {
+				end := bytes.IndexByte(buf, '\n')
+				if end < 0 {
+					break
+				}
+				if err := fn(stream, string(bytes.TrimSuffix(buf[:end], []byte("\r")))); err != nil {
+					return err
+				}
+				buf = buf[end+1:]
+			}
+			if len(buf) > maxLineSize {
+				return bufio.ErrTooLong
+			}
+			pending[stream] = append(pending[stream][:0], buf...)
+		}
+	}
+
+	for _, stream := range []string{"stdout", "stderr"} {
+		if len(pending[stream]) > 0 {
+			if err := fn(stream, string(pending[stream])); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isHeader reports whether data starts with the header of a stdin, stdout
+// or stderr frame
+func isHeader(data []byte) bool {
+	return len(data) >= headerSize && data[0] <= 2 && data[1] == 0 && data[2] == 0 && data[3] == 0
+}
+
+// splitTimestamp splits the RFC 3339 timestamp Docker adds to log lines
+// from the message. Lines without one are returned as the message.
+func splitTimestamp(line string) (string, string) {
+	timestamp, message, ok := strings.Cut(line, " ")
+	if !ok {
+		timestamp, message = line, ""
+	}
+	if _, err := time.Parse(time.RFC3339Nano, timestamp); err != nil {
+		return "", line
+	}
+	return timestamp, message
+}
+
+// highlight colors the non-empty matches of re in line
+func highlight(line string, re *regexp.Regexp) string {
+	return re.ReplaceAllStringFunc(line, func(match string) string {
+		if match == "" {
+			return match
+		}
+		return highlightColor + match + colorReset
+	})
+}
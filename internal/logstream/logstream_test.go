@@ -0,0 +1,164 @@
+package logstream
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// header returns a Docker stream frame header for stdout
+func header(size int) string {
+	return frameHeader(1, size)
+}
+
+// frameHeader returns a Docker stream frame header for stream
+func frameHeader(stream byte, size int) string {
+	h := []byte{stream, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(h[4:], uint32(size))
+	return string(h)
+}
+
+func TestMultiplex(t *testing.T) {
+	sources := []Source{
+		{Name: "web", Reader: io.NopCloser(strings.NewReader(header(6) + "hello\n" + header(13) + "first\nsecond\n"))},
+		{Name: "worker", Reader: io.NopCloser(strings.NewReader("tty line\n"))},
+	}
+
+	var out strings.Builder
+	if err := Multiplex(&out, sources, Options{Prefix: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	expected := map[string]bool{
+		"[web]    hello":    true,
+		"[web]    first":    true,
+		"[web]    second":   true,
+		"[worker] tty line": true,
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %q", len(expected), lines)
+	}
+	for _, line := range lines {
+		if !expected[line] {
+			t.Errorf("unexpected line %q", line)
+		}
+	}
+	hello, first, second := strings.Index(out.String(), "hello"), strings.Index(out.String(), "first"), strings.Index(out.String(), "second")
+	if hello > first || first > second {
+		t.Errorf("expected lines of one source to keep their order: %q", out.String())
+	}
+}
+
+func TestMultiplex_NoPrefix(t *testing.T) {
+	sources := []Source{{Name: "web", Reader: io.NopCloser(strings.NewReader(header(6) + "hello\n"))}}
+
+	var out strings.Builder
+	if err := Multiplex(&out, sources, Options{Color: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "hello\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+func TestMultiplex_SourceError(t *testing.T) {
+	sources := []Source{
+		{Name: "web", Reader: io.NopCloser(failingReader{})},
+		{Name: "db", Reader: io.NopCloser(strings.NewReader("ready\n"))},
+	}
+
+	var out strings.Builder
+	err := Multiplex(&out, sources, Options{Prefix: true})
+	if err == nil || !strings.Contains(err.Error(), "web: connection reset") {
+		t.Errorf("expected the web stream error, got %v", err)
+	}
+	if out.String() != "[db]  ready\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
+
+func TestMultiplex_FilterHighlight(t *testing.T) {
+	sources := []Source{{Name: "web", Reader: io.NopCloser(strings.NewReader("GET /health 200\nGET /login 500\nPOST /login 200\n"))}}
+
+	var out strings.Builder
+	err := Multiplex(&out, sources, Options{
+		Color:     true,
+		Filter:    regexp.MustCompile(`/login`),
+		Highlight: regexp.MustCompile(`\b5\d\d\b`),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "GET /login " + highlightColor + "500" + colorReset + "\nPOST /login 200\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestMultiplex_JSON(t *testing.T) {
+	sources := []Source{{Name: "web", Reader: io.NopCloser(strings.NewReader(
+		header(39) + "2024-05-01T10:00:00.123456789Z started\n" +
+			frameHeader(2, 53) + "2024-05-01T10:00:01Z oops\n2024-05-01T10:00:02Z again\n",
+	))}}
+
+	var out strings.Builder
+	if err := Multiplex(&out, sources, Options{JSON: true, Prefix: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"timestamp":"2024-05-01T10:00:00.123456789Z","stream":"stdout","container":"web","message":"started"}
+{"timestamp":"2024-05-01T10:00:01Z","stream":"stderr","container":"web","message":"oops"}
+{"timestamp":"2024-05-01T10:00:02Z","stream":"stderr","container":"web","message":"again"}
+`
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestMultiplex_Frames(t *testing.T) {
+	// Frame lengths containing a newline byte (10, 266) and lines split
+	// across frames, with stdout and stderr interleaved
+	long := strings.Repeat("x", 265)
+	sources := []Source{{Name: "web", Reader: io.NopCloser(strings.NewReader(
+		header(10) + "ten bytes\n" +
+			header(266) + long + "\n" +
+			header(6) + "split " +
+			frameHeader(2, 7) + "error\r\n" +
+			header(5) + "line\n" +
+			header(4) + "tail",
+	))}}
+
+	var out strings.Builder
+	if err := Multiplex(&out, sources, Options{JSON: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"timestamp":"","stream":"stdout","container":"web","message":"ten bytes"}
+{"timestamp":"","stream":"stdout","container":"web","message":"` + long + `"}
+{"timestamp":"","stream":"stderr","container":"web","message":"error"}
+{"timestamp":"","stream":"stdout","container":"web","message":"split line"}
+{"timestamp":"","stream":"stdout","container":"web","message":"tail"}
+`
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestMultiplex_TruncatedFrame(t *testing.T) {
+	sources := []Source{{Name: "web", Reader: io.NopCloser(strings.NewReader(header(20) + "short\n"))}}
+
+	var out strings.Builder
+	err := Multiplex(&out, sources, Options{})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected an unexpected EOF, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+// Package metrics writes metrics in the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Metric types
+const (
+	TypeGauge   = "gauge"
+	TypeCounter = "counter"
+)
+
+// Labels are the label names and values of a sample
+type Labels map[string]string
+
+// Sample is a single value of a metric family
+type Sample struct {
+	Labels Labels
+	Value  float64
+}
+
+// Family is a named metric with its help text, type and samples
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Add appends a sample to the family
+func (f *Family) Add(value float64, labels Labels) {
+	f.Samples = append(f.Samples, Sample{Labels: labels, Value: value})
+}
+
+// NewGauge returns an empty gauge family
+func NewGauge(name, help string) *Family {
+	return &Family{Name: name, Help: help, Type: TypeGauge}
+}
+
+// NewCounter returns an empty counter family
+func NewCounter(name, help string) *Family {
+	return &Family{Name: name, Help: help, Type: TypeCounter}
+}
+
+// Write writes the families in the Prometheus text format. Families
+// without samples are skipped.
+func Write(w io.Writer, families []*Family) error {
+	var b strings.Builder
+
+	for _, f := range families {
+		if len(f.Samples) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.Name, escapeHelp(f.Help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.Name, f.Type)
+		for _, s := range f.Samples {
+			b.WriteString(f.Name)
+			writeLabels(&b, s.Labels)
+			b.WriteByte(' ')
+			b.WriteString(formatValue(s.Value))
+			b.WriteByte('\n')
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeLabels(b *strings.Builder, labels Labels) {
+	if len(labels) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(b, "%s=\"%s\"", name, escapeLabelValue(labels[name]))
+	}
+	b.WriteByte('}')
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	up := NewGauge("portainer_environment_up", "Whether the environment is up.")
+	up.Add(1, Labels{"endpoint_id": "1", "endpoint": "local"})
+	up.Add(0, Labels{"endpoint_id": "2", "endpoint": `edge "west"`})
+
+	errors := NewCounter("portainer_exporter_poll_errors_total", "Failed polls.\nPer poll.")
+	errors.Add(3, nil)
+
+	empty := NewGauge("portainer_unused", "No samples.")
+
+	nan := NewGauge("portainer_nan", "Not a number.")
+	nan.Add(math.NaN(), nil)
+
+	var b strings.Builder
+	if err := Write(&b, []*Family{up, errors, empty, nan}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `# HELP portainer_environment_up Whether the environment is up.
+# TYPE portainer_environment_up gauge
+portainer_environment_up{endpoint="local",endpoint_id="1"} 1
+portainer_environment_up{endpoint="edge \"west\"",endpoint_id="2"} 0
+# HELP portainer_exporter_poll_errors_total Failed polls.\nPer poll.
+# TYPE portainer_exporter_poll_errors_total counter
+portainer_exporter_poll_errors_total 3
+# HELP portainer_nan Not a number.
+# TYPE portainer_nan gauge
+portainer_nan NaN
+`
+	if b.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", b.String(), expected)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := map[float64]string{
+		0:            "0",
+		1.5:          "1.5",
+		1.7e9:        "1.7e+09",
+		math.Inf(1):  "+Inf",
+		math.Inf(-1): "-Inf",
+	}
+	for value, expected := range tests {
+		if got := formatValue(value); got != expected {
+			t.Errorf("formatValue(%v) = %q, want %q", value, got, expected)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Values are the named numbers a condition is evaluated against, such as
+// the number of unhealthy containers of the last refresh
+type Values map[string]float64
+
+// Condition is a parsed expression such as "unhealthy>0" or
+// "running < 3 && count > 0". Expressions combine variables and numbers
+// with arithmetic (+ - * /), comparisons (> >= < <= == !=), the logical
+// operators && || ! (or and, or, not) and parentheses. A condition holds
+// when it evaluates to a non-zero value.
+type Condition struct {
+	text string
+	eval evalFunc
+}
+
+type evalFunc func(Values) (float64, error)
+
+// Parse parses a condition expression
+func Parse(text string) (*Condition, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", text, err)
+	}
+
+	p := &parser{tokens: tokens}
+	eval, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", text, err)
+	}
+
+	return &Condition{text: text, eval: eval}, nil
+}
+
+// String returns the expression the condition was parsed from
+func (c *Condition) String() string {
+	return c.text
+}
+
+// Eval reports whether the condition holds for values. Referencing a
+// variable that is not in values is an error.
+func (c *Condition) Eval(values Values) (bool, error) {
+	result, err := c.eval(values)
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate %q: %w", c.text, err)
+	}
+	return result != 0, nil
+}
+
+type tokenKind int
+
+const (
+	tokenNumber tokenKind = iota
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value float64
+}
+
+// operators are matched longest first
+var operators = []string{"&&", "||", ">=", "<=", "==", "!=", ">", "<", "!", "+", "-", "*", "/", "(", ")"}
+
+// keywords are the word forms of the logical operators
+var keywords = map[string]string{"and": "&&", "or": "||", "not": "!"}
+
+func tokenize(text string) ([]token, error) {
+	var tokens []token
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			value, err := strconv.ParseFloat(string(runes[start:i]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", string(runes[start:i]))
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), value: value})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			word := string(runes[start:i])
+			if op, ok := keywords[strings.ToLower(word)]; ok {
+				tokens = append(tokens, token{kind: tokenOperator, text: op})
+			} else {
+				tokens = append(tokens, token{kind: tokenIdent, text: word})
+			}
+
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op})
+					i += len([]rune(op))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q", r)
+			}
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	return tokens, nil
+}
+
+// parser is a recursive descent parser compiling tokens to closures. From
+// lowest to highest precedence: ||, &&, !, comparisons, + -, * /, unary -.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// accept consumes the next token if it is one of the operators ops
+func (p *parser) accept(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseOr() (evalFunc, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logical(left, right, true)
+	}
+}
+
+func (p *parser) parseAnd() (evalFunc, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logical(left, right, false)
+	}
+}
+
+func (p *parser) parseNot() (evalFunc, error) {
+	if _, ok := p.accept("!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(v Values) (float64, error) {
+			x, err := operand(v)
+			if err != nil {
+				return 0, err
+			}
+			return truth(x == 0), nil
+		}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (evalFunc, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.accept(">=", "<=", "==", "!=", ">", "<")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	return binary(left, right, func(x, y float64) (float64, error) {
+		switch op {
+		case ">":
+			return truth(x > y), nil
+		case ">=":
+			return truth(x >= y), nil
+		case "<":
+			return truth(x < y), nil
+		case "<=":
+			return truth(x <= y), nil
+		case "==":
+			return truth(x == y), nil
+		default:
+			return truth(x != y), nil
+		}
+	}), nil
+}
+
+func (p *parser) parseSum() (evalFunc, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binary(left, right, func(x, y float64) (float64, error) {
+			if op == "+" {
+				return x + y, nil
+			}
+			return x - y, nil
+		})
+	}
+}
+
+func (p *parser) parseProduct() (evalFunc, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binary(left, right, func(x, y float64) (float64, error) {
+			if op == "*" {
+				return x * y, nil
+			}
+			if y == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return x / y, nil
+		})
+	}
+}
+
+func (p *parser) parseUnary() (evalFunc, error) {
+	if _, ok := p.accept("-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(v Values) (float64, error) {
+			x, err := operand(v)
+			return -x, err
+		}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (evalFunc, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	switch tok.kind {
+	case tokenNumber:
+		return func(Values) (float64, error) {
+			return tok.value, nil
+		}, nil
+
+	case tokenIdent:
+		name := tok.text
+		return func(v Values) (float64, error) {
+			value, ok := v[name]
+			if !ok {
+				return 0, fmt.Errorf("unknown variable %q (available: %s)", name, strings.Join(v.Names(), ", "))
+			}
+			return value, nil
+		}, nil
+	}
+
+	if tok.text == "(" {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return inner, nil
+	}
+
+	return nil, fmt.Errorf("unexpected %q", tok.text)
+}
+
+func binary(left, right evalFunc, op func(x, y float64) (float64, error)) evalFunc {
+	return func(v Values) (float64, error) {
+		x, err := left(v)
+		if err != nil {
+			return 0, err
+		}
+		y, err := right(v)
+		if err != nil {
+			return 0, err
+		}
+		return op(x, y)
+	}
+}
+
+// logical combines two operands with || (or=true) or &&. Both operands are
+// always evaluated so unknown variables are reported on the first refresh.
+func logical(left, right evalFunc, or bool) evalFunc {
+	return binary(left, right, func(x, y float64) (float64, error) {
+		if or {
+			return truth(x != 0 || y != 0), nil
+		}
+		return truth(x != 0 && y != 0), nil
+	})
+}
+
+func truth(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Names returns the variable names in sorted order
+func (v Values) Names() []string {
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
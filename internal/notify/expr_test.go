@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCondition_Eval(t *testing.T) {
+	values := Values{"count": 5, "running": 3, "unhealthy": 1, "size": 2048}
+
+	tests := []struct {
+		expr     string
+		expected bool
+	}{
+		{"unhealthy>0", true},
+		{"unhealthy > 1", false},
+		{"running >= 3", true},
+		{"running < 3", false},
+		{"running <= 3", true},
+		{"count == 5", true},
+		{"count != 5", false},
+		{"count - running > 1", true},
+		{"count - running * 2 > 0", false},
+		{"(count - running) * 2 == 4", true},
+		{"size / 1024 > 1.5", true},
+		{"-running < 0", true},
+		{"unhealthy > 0 && running < 3", false},
+		{"unhealthy > 0 || running < 3", true},
+		{"unhealthy > 0 and not running < 3", true},
+		{"!(count > 1)", false},
+		{"unhealthy", true},
+		{"count > 0 or count < 0 and false_value", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			condition, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			v := Values{"false_value": 0}
+			for name, value := range values {
+				v[name] = value
+			}
+			got, err := condition.Eval(v)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, expr := range []string{"", "count >", "count > > 1", "(count > 1", "count > 1)", "count # 1", "1.2.3 > 0", "count 1"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q): expected error", expr)
+		}
+	}
+}
+
+func TestCondition_EvalErrors(t *testing.T) {
+	condition, err := Parse("missing > 0 || count > 0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = condition.Eval(Values{"count": 1, "running": 1})
+	if err == nil || !strings.Contains(err.Error(), `unknown variable "missing" (available: count, running)`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	condition, err = Parse("count / running > 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := condition.Eval(Values{"count": 1, "running": 0}); err == nil || !strings.Contains(err.Error(), "division by zero") {
+		t.Errorf("expected division by zero error, got %v", err)
+	}
+}
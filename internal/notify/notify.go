@@ -0,0 +1,171 @@
+// Package notify evaluates conditions over watched data and sends
+// notifications when they become true.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookTimeout bounds the delivery of a single webhook notification
+const WebhookTimeout = 10 * time.Second
+
+// Event describes a condition that became true
+type Event struct {
+	Resource  string    `json:"resource"`
+	Condition string    `json:"condition"`
+	Values    Values    `json:"values"`
+	Time      time.Time `json:"time"`
+}
+
+// Message returns a one-line description of the event
+func (e *Event) Message() string {
+	values := make([]string, 0, len(e.Values))
+	for _, name := range e.Values.Names() {
+		values = append(values, name+"="+strconv.FormatFloat(e.Values[name], 'f', -1, 64))
+	}
+	return fmt.Sprintf("%s: %s (%s)", e.Resource, e.Condition, strings.Join(values, ", "))
+}
+
+// Notifier delivers events
+type Notifier interface {
+	Notify(ctx context.Context, event *Event) error
+}
+
+// Trigger tracks conditions across refreshes. A condition fires when it
+// becomes true, and fires again only after it has been false.
+type Trigger struct {
+	conditions []*Condition
+	active     []bool
+}
+
+// NewTrigger creates a trigger for conditions, which all start out false
+func NewTrigger(conditions []*Condition) *Trigger {
+	return &Trigger{conditions: conditions, active: make([]bool, len(conditions))}
+}
+
+// Update evaluates the conditions against values and returns those that
+// changed from false to true
+func (t *Trigger) Update(values Values) ([]*Condition, error) {
+	var fired []*Condition
+	for i, condition := range t.conditions {
+		ok, err := condition.Eval(values)
+		if err != nil {
+			return nil, err
+		}
+		if ok && !t.active[i] {
+			fired = append(fired, condition)
+		}
+		t.active[i] = ok
+	}
+	return fired, nil
+}
+
+// Desktop shows events as desktop notifications with notify-send on Linux
+// and BSD, and osascript on macOS
+type Desktop struct{}
+
+func (Desktop) Notify(ctx context.Context, event *Event) error {
+	name, args, err := desktopCommand(runtime.GOOS, "portainer-cli", event.Message())
+	if err != nil {
+		return err
+	}
+	if out, err := exec.CommandContext(ctx, name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to show desktop notification: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func desktopCommand(goos, title, body string) (string, []string, error) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(body), strconv.Quote(title))
+		return "osascript", []string{"-e", script}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "notify-send", []string{title, body}, nil
+	default:
+		return "", nil, fmt.Errorf("desktop notifications are not supported on %s, use a command or webhook instead", goos)
+	}
+}
+
+// Command runs a shell command for each event. The event is passed as JSON
+// on stdin and summarized in the PORTAINER_NOTIFY_* environment variables.
+type Command struct {
+	Command string
+	Stdout  io.Writer
+	Stderr  io.Writer
+}
+
+func (c *Command) Notify(ctx context.Context, event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", c.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", c.Command)
+	}
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+	cmd.Env = append(os.Environ(),
+		"PORTAINER_NOTIFY_RESOURCE="+event.Resource,
+		"PORTAINER_NOTIFY_CONDITION="+event.Condition,
+		"PORTAINER_NOTIFY_MESSAGE="+event.Message(),
+	)
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("notification command failed: %w", err)
+	}
+	return nil
+}
+
+// Webhook POSTs each event as JSON to a URL
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+func (w *Webhook) Notify(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "portainer-cli")
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: WebhookTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver notification to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrigger_FiresOnTransitions(t *testing.T) {
+	condition, err := Parse("unhealthy > 0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	trigger := NewTrigger([]*Condition{condition})
+
+	// The condition fires when it becomes true, not while it stays true
+	for i, step := range []struct {
+		unhealthy float64
+		fires     bool
+	}{{0, false}, {1, true}, {2, false}, {0, false}, {1, true}} {
+		fired, err := trigger.Update(Values{"unhealthy": step.unhealthy})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if (len(fired) == 1) != step.fires {
+			t.Errorf("step %d: expected fires=%v, got %d conditions", i, step.fires, len(fired))
+		}
+	}
+}
+
+func TestEvent_Message(t *testing.T) {
+	event := &Event{Resource: "containers", Condition: "unhealthy>0", Values: Values{"unhealthy": 2, "count": 5.5}}
+	expected := "containers: unhealthy>0 (count=5.5, unhealthy=2)"
+	if event.Message() != expected {
+		t.Errorf("expected %q, got %q", expected, event.Message())
+	}
+}
+
+func TestDesktopCommand(t *testing.T) {
+	name, args, err := desktopCommand("linux", "portainer-cli", "containers: unhealthy>0")
+	if err != nil || name != "notify-send" || len(args) != 2 || args[1] != "containers: unhealthy>0" {
+		t.Errorf("unexpected linux command: %s %v %v", name, args, err)
+	}
+
+	name, args, err = desktopCommand("darwin", "portainer-cli", `say "hi"`)
+	if err != nil || name != "osascript" || args[1] != `display notification "say \"hi\"" with title "portainer-cli"` {
+		t.Errorf("unexpected darwin command: %s %v %v", name, args, err)
+	}
+
+	if _, _, err := desktopCommand("windows", "portainer-cli", "body"); err == nil {
+		t.Error("expected error for unsupported platform")
+	}
+}
+
+func TestCommand_Notify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test command uses sh")
+	}
+
+	var stdout bytes.Buffer
+	command := &Command{Command: `echo "$PORTAINER_NOTIFY_RESOURCE $PORTAINER_NOTIFY_CONDITION"; cat`, Stdout: &stdout}
+	event := &Event{Resource: "containers", Condition: "unhealthy>0", Values: Values{"unhealthy": 1}}
+
+	if err := command.Notify(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := stdout.String()
+	if !strings.HasPrefix(out, "containers unhealthy>0\n") || !strings.Contains(out, `"values":{"unhealthy":1}`) {
+		t.Errorf("unexpected output: %q", out)
+	}
+
+	if err := (&Command{Command: "exit 3"}).Notify(context.Background(), event); err == nil {
+		t.Error("expected error for failing command")
+	}
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	event := &Event{Resource: "stacks", Condition: "inactive > 0", Values: Values{"inactive": 1}, Time: time.Now()}
+	if err := (&Webhook{URL: server.URL}).Notify(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received.Resource != "stacks" || received.Values["inactive"] != 1 {
+		t.Errorf("unexpected payload: %+v", received)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	if err := (&Webhook{URL: failing.URL}).Notify(context.Background(), event); err == nil || !strings.Contains(err.Error(), "HTTP 502") {
+		t.Errorf("expected HTTP 502 error, got %v", err)
+	}
+}
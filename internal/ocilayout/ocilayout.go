@@ -0,0 +1,447 @@
+// Package ocilayout converts image archives of the Docker engine, as written
+// by docker save, into OCI image layout directories, which tools such as
+// skopeo and crane read without a registry.
+package ocilayout
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Media types and annotations of the OCI image specification
+const (
+	MediaTypeIndex    = "application/vnd.oci.image.index.v1+json"
+	MediaTypeManifest = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeConfig   = "application/vnd.oci.image.config.v1+json"
+	MediaTypeLayer    = "application/vnd.oci.image.layer.v1.tar"
+
+	// AnnotationRefName names an image of a layout, e.g. 1.25 for
+	// skopeo's oci:dir:1.25
+	AnnotationRefName = "org.opencontainers.image.ref.name"
+	// annotationImageName is the full name of an image, as set by Docker
+	annotationImageName = "io.containerd.image.name"
+)
+
+// maxMetadataSize limits the size of the metadata files of an archive, such
+// as manifest.json, which are read into memory
+const maxMetadataSize = 64 << 20
+
+// Descriptor points at a blob of a layout
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type index struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType,omitempty"`
+	Manifests     []Descriptor `json:"manifests"`
+}
+
+type manifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType,omitempty"`
+	Config        Descriptor   `json:"config"`
+	Layers        []Descriptor `json:"layers"`
+}
+
+// dockerManifest is an entry of the manifest.json of a Docker archive
+type dockerManifest struct {
+	Config   string   `json:"Config"`
+	RepoTags []string `json:"RepoTags"`
+	Layers   []string `json:"Layers"`
+}
+
+// Image describes an image added to a layout
+type Image struct {
+	// Manifest is the descriptor of the image in index.json
+	Manifest Descriptor
+	// Layers is the number of layers and Size the size of the layers and
+	// the configuration; both are 0 for multi-platform indexes
+	Layers int
+	Size   int64
+}
+
+// archive holds the entries of a Docker archive: the top-level metadata
+// files, the other files written as blobs, and the symbolic links
+type archive struct {
+	files map[string][]byte
+	blobs map[string]Descriptor
+	links map[string]string
+}
+
+// Write adds the images of a Docker archive to the OCI image layout in dir,
+// creating the layout when dir does not exist or is empty. Images of the
+// index with the same ref name are replaced. ref names the images; when
+// empty, the tag of the archive is used.
+func Write(dir string, r io.Reader, ref string) ([]Image, error) {
+	existing, err := loadIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+	blobDir := filepath.Join(dir, "blobs", "sha256")
+	if err := os.MkdirAll(blobDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create layout directory: %w", err)
+	}
+
+	// Blobs written by this call are removed again when it fails, or when
+	// no image refers to them
+	written := map[string]bool{}
+	a, err := readArchive(r, blobDir, written)
+	if err == nil {
+		var images []Image
+		if images, err = a.images(blobDir, ref, written); err == nil {
+			err = writeIndex(dir, existing, images)
+		}
+		if err == nil {
+			if keep := referenced(blobDir, images); keep != nil {
+				removeBlobs(blobDir, written, keep)
+			}
+			return images, nil
+		}
+	}
+	removeBlobs(blobDir, written, nil)
+	return nil, err
+}
+
+// loadIndex returns the index of the layout in dir, an empty index when dir
+// does not exist or is empty, or an error for directories with other
+// content
+func loadIndex(dir string) (*index, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err == nil {
+		var idx index
+		if err := json.Unmarshal(data, &idx); err != nil {
+			return nil, fmt.Errorf("invalid index.json in %s: %w", dir, err)
+		}
+		return &idx, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		return nil, fmt.Errorf("%s is neither empty nor an OCI image layout", dir)
+	}
+	return &index{SchemaVersion: 2, MediaType: MediaTypeIndex}, nil
+}
+
+// readArchive reads a Docker archive, writing all files below the top level
+// as blobs, so layers are never held in memory
+func readArchive(r io.Reader, blobDir string, written map[string]bool) (*archive, error) {
+	a := &archive{files: map[string][]byte{}, blobs: map[string]Descriptor{}, links: map[string]string{}}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return a, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image archive: %w", err)
+		}
+
+		name := path.Clean(hdr.Name)
+		switch hdr.Typeflag {
+		case tar.TypeSymlink:
+			a.links[name] = path.Join(path.Dir(name), hdr.Linkname)
+		case tar.TypeReg:
+			if !strings.Contains(name, "/") {
+				data, err := io.ReadAll(io.LimitReader(tr, maxMetadataSize+1))
+				if err != nil {
+					return nil, fmt.Errorf("failed to read image archive: %w", err)
+				}
+				if len(data) > maxMetadataSize {
+					return nil, fmt.Errorf("%s of the image archive is too large", name)
+				}
+				a.files[name] = data
+				continue
+			}
+			desc, err := writeBlob(blobDir, tr, written)
+			if err != nil {
+				return nil, err
+			}
+			a.blobs[name] = desc
+		}
+	}
+}
+
+// images returns the images of the archive. Archives of Docker 25 and later
+// are OCI layouts already; older archives get a manifest per image.
+func (a *archive) images(blobDir, ref string, written map[string]bool) ([]Image, error) {
+	if data, ok := a.files["index.json"]; ok {
+		var idx index
+		if err := json.Unmarshal(data, &idx); err != nil {
+			return nil, fmt.Errorf("invalid index.json in image archive: %w", err)
+		}
+		images := make([]Image, 0, len(idx.Manifests))
+		for _, desc := range idx.Manifests {
+			switch {
+			case ref != "":
+				desc.Annotations = withRefName(desc.Annotations, ref)
+			case desc.Annotations[AnnotationRefName] == "" && desc.Annotations[annotationImageName] != "":
+				desc.Annotations = withRefName(desc.Annotations, imageTag(desc.Annotations[annotationImageName]))
+			}
+			image := Image{Manifest: desc}
+			if m, err := readManifest(blobDir, desc); err == nil {
+				image.Layers, image.Size = len(m.Layers), manifestSize(m)
+			}
+			images = append(images, image)
+		}
+		return images, nil
+	}
+
+	data, ok := a.files["manifest.json"]
+	if !ok {
+		return nil, fmt.Errorf("image archive has neither index.json nor manifest.json")
+	}
+	var entries []dockerManifest
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid manifest.json in image archive: %w", err)
+	}
+
+	images := make([]Image, 0, len(entries))
+	for _, entry := range entries {
+		image, err := a.convert(blobDir, entry, ref, written)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, *image)
+	}
+	return images, nil
+}
+
+// convert writes the OCI manifest of an image of a Docker archive
+func (a *archive) convert(blobDir string, entry dockerManifest, ref string, written map[string]bool) (*Image, error) {
+	m := manifest{SchemaVersion: 2, MediaType: MediaTypeManifest}
+
+	config, err := a.blob(blobDir, entry.Config, written)
+	if err != nil {
+		return nil, err
+	}
+	config.MediaType = MediaTypeConfig
+	m.Config = config
+
+	m.Layers = make([]Descriptor, 0, len(entry.Layers))
+	for _, name := range entry.Layers {
+		layer, err := a.blob(blobDir, name, written)
+		if err != nil {
+			return nil, err
+		}
+		layer.MediaType = MediaTypeLayer
+		m.Layers = append(m.Layers, layer)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	desc, err := writeBlob(blobDir, bytes.NewReader(data), written)
+	if err != nil {
+		return nil, err
+	}
+	desc.MediaType = MediaTypeManifest
+
+	var annotations map[string]string
+	if len(entry.RepoTags) > 0 {
+		name := entry.RepoTags[0]
+		annotations = map[string]string{annotationImageName: name}
+		if ref == "" {
+			ref = imageTag(name)
+		}
+	}
+	if ref != "" {
+		annotations = withRefName(annotations, ref)
+	}
+	desc.Annotations = annotations
+
+	return &Image{Manifest: desc, Layers: len(m.Layers), Size: manifestSize(&m)}, nil
+}
+
+// blob returns the descriptor of a file of the archive, following symbolic
+// links, and writes top-level files as blobs
+func (a *archive) blob(blobDir, name string, written map[string]bool) (Descriptor, error) {
+	name = path.Clean(name)
+	for i := 0; i < 10; i++ {
+		target, ok := a.links[name]
+		if !ok {
+			break
+		}
+		name = target
+	}
+
+	if desc, ok := a.blobs[name]; ok {
+		return desc, nil
+	}
+	if data, ok := a.files[name]; ok {
+		return writeBlob(blobDir, bytes.NewReader(data), written)
+	}
+	return Descriptor{}, fmt.Errorf("image archive has no %s", name)
+}
+
+// writeBlob writes the content of r to blobDir under its digest. Blobs the
+// layout has already are kept.
+func writeBlob(blobDir string, r io.Reader, written map[string]bool) (Descriptor, error) {
+	tmp, err := os.CreateTemp(blobDir, ".tmp-")
+	if err != nil {
+		return Descriptor{}, fmt.Errorf("failed to write blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Descriptor{}, fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	hexDigest := hex.EncodeToString(h.Sum(nil))
+	desc := Descriptor{Digest: "sha256:" + hexDigest, Size: size}
+	blobPath := filepath.Join(blobDir, hexDigest)
+	if _, err := os.Stat(blobPath); err == nil {
+		return desc, nil
+	}
+	if err := os.Rename(tmp.Name(), blobPath); err != nil {
+		return Descriptor{}, fmt.Errorf("failed to write blob: %w", err)
+	}
+	written[desc.Digest] = true
+	return desc, nil
+}
+
+// writeIndex adds images to the index of the layout in dir, replacing
+// images with the same ref name or digest, and marks dir as a layout
+func writeIndex(dir string, idx *index, images []Image) error {
+	kept := idx.Manifests[:0]
+	for _, desc := range idx.Manifests {
+		if !replaces(images, desc) {
+			kept = append(kept, desc)
+		}
+	}
+	idx.Manifests = kept
+	for _, image := range images {
+		idx.Manifests = append(idx.Manifests, image.Manifest)
+	}
+	if idx.SchemaVersion == 0 {
+		idx.SchemaVersion = 2
+	}
+
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, "index.json"), data); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`))
+}
+
+// replaces reports whether one of images takes the place of desc in an
+// index: it has the same ref name, or is the same image without one
+func replaces(images []Image, desc Descriptor) bool {
+	ref := desc.Annotations[AnnotationRefName]
+	for _, image := range images {
+		newRef := image.Manifest.Annotations[AnnotationRefName]
+		if (ref != "" && ref == newRef) || (ref == "" && newRef == "" && desc.Digest == image.Manifest.Digest) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeFileAtomic(name string, data []byte) error {
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(name), err)
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(name), err)
+	}
+	return nil
+}
+
+// referenced returns the digests of the manifests of images and of the
+// blobs they refer to, or nil when an image is not a single manifest, such
+// as an index of several platforms
+func referenced(blobDir string, images []Image) map[string]bool {
+	digests := map[string]bool{}
+	for _, image := range images {
+		digests[image.Manifest.Digest] = true
+		m, err := readManifest(blobDir, image.Manifest)
+		if err != nil {
+			return nil
+		}
+		digests[m.Config.Digest] = true
+		for _, layer := range m.Layers {
+			digests[layer.Digest] = true
+		}
+	}
+	return digests
+}
+
+// removeBlobs removes the blobs written by this call that are not in keep
+func removeBlobs(blobDir string, written, keep map[string]bool) {
+	for digest := range written {
+		if !keep[digest] {
+			os.Remove(filepath.Join(blobDir, strings.TrimPrefix(digest, "sha256:")))
+		}
+	}
+}
+
+func readManifest(blobDir string, desc Descriptor) (*manifest, error) {
+	if desc.MediaType != MediaTypeManifest && desc.MediaType != "application/vnd.docker.distribution.manifest.v2+json" {
+		return nil, fmt.Errorf("%s is not an image manifest", desc.Digest)
+	}
+	data, err := os.ReadFile(filepath.Join(blobDir, strings.TrimPrefix(desc.Digest, "sha256:")))
+	if err != nil {
+		return nil, err
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func manifestSize(m *manifest) int64 {
+	size := m.Config.Size
+	for _, layer := range m.Layers {
+		size += layer.Size
+	}
+	return size
+}
+
+func withRefName(annotations map[string]string, ref string) map[string]string {
+	copied := make(map[string]string, len(annotations)+1)
+	for key, value := range annotations {
+		copied[key] = value
+	}
+	copied[AnnotationRefName] = ref
+	return copied
+}
+
+// imageTag returns the tag of an image name, e.g. 1.25 for nginx:1.25, or
+// latest when it has none
+func imageTag(name string) string {
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		return name[i+1:]
+	}
+	return "latest"
+}
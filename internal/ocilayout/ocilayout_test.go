@@ -0,0 +1,218 @@
+package ocilayout
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type tarEntry struct {
+	name string
+	data string
+	link string
+}
+
+func buildArchive(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.data)), Typeflag: tar.TypeReg}
+		if e.link != "" {
+			hdr = &tar.Header{Name: e.name, Mode: 0777, Linkname: e.link, Typeflag: tar.TypeSymlink}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := tw.Write([]byte(e.data)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return &buf
+}
+
+func digestOf(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func readIndex(t *testing.T, dir string) index {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var idx index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return idx
+}
+
+func blobCount(t *testing.T, dir string) int {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Join(dir, "blobs", "sha256"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return len(entries)
+}
+
+func legacyArchive(t *testing.T, tag, config string) *bytes.Buffer {
+	return buildArchive(t, []tarEntry{
+		{name: "aaa/VERSION", data: "1.0"},
+		{name: "aaa/json", data: `{"id":"aaa"}`},
+		{name: "aaa/layer.tar", data: "layer one"},
+		{name: "bbb/layer.tar", link: "../aaa/layer.tar"},
+		{name: "ccc/layer.tar", data: "layer two"},
+		{name: "cfg.json", data: config},
+		{name: "manifest.json", data: `[{"Config":"cfg.json","RepoTags":["` + tag + `"],"Layers":["aaa/layer.tar","bbb/layer.tar","ccc/layer.tar"]}]`},
+		{name: "repositories", data: `{}`},
+	})
+}
+
+func TestWriteLegacyArchive(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "oci")
+
+	images, err := Write(dir, legacyArchive(t, "nginx:1.25", `{"architecture":"amd64"}`), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(images) != 1 {
+		t.Fatalf("expected 1 image, got %d", len(images))
+	}
+	image := images[0]
+	if image.Layers != 3 || image.Manifest.Annotations[AnnotationRefName] != "1.25" || image.Manifest.Annotations[annotationImageName] != "nginx:1.25" {
+		t.Errorf("unexpected image %+v", image)
+	}
+
+	layout, err := os.ReadFile(filepath.Join(dir, "oci-layout"))
+	if err != nil || !strings.Contains(string(layout), `"imageLayoutVersion":"1.0.0"`) {
+		t.Errorf("unexpected oci-layout %q (%v)", layout, err)
+	}
+	idx := readIndex(t, dir)
+	if len(idx.Manifests) != 1 || idx.Manifests[0].Digest != image.Manifest.Digest || idx.Manifests[0].MediaType != MediaTypeManifest {
+		t.Fatalf("unexpected index %+v", idx)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "blobs", "sha256", strings.TrimPrefix(image.Manifest.Digest, "sha256:")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if digestOf(string(data)) != image.Manifest.Digest {
+		t.Errorf("manifest blob does not match its digest")
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Config.Digest != digestOf(`{"architecture":"amd64"}`) || m.Config.MediaType != MediaTypeConfig {
+		t.Errorf("unexpected config %+v", m.Config)
+	}
+	expected := []string{digestOf("layer one"), digestOf("layer one"), digestOf("layer two")}
+	for i, layer := range m.Layers {
+		if layer.Digest != expected[i] || layer.MediaType != MediaTypeLayer {
+			t.Errorf("unexpected layer %d: %+v", i, layer)
+		}
+	}
+
+	// Manifest, config and two layers; VERSION and json are not kept
+	if n := blobCount(t, dir); n != 4 {
+		t.Errorf("expected 4 blobs, got %d", n)
+	}
+}
+
+func TestWriteAddsToLayout(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Write(dir, legacyArchive(t, "nginx:1.25", `{"v":1}`), ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := Write(dir, legacyArchive(t, "nginx:1.26", `{"v":2}`), ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	images, err := Write(dir, legacyArchive(t, "nginx:1.25", `{"v":3}`), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	idx := readIndex(t, dir)
+	refs := map[string]string{}
+	for _, desc := range idx.Manifests {
+		refs[desc.Annotations[AnnotationRefName]] = desc.Digest
+	}
+	if len(idx.Manifests) != 2 || refs["1.25"] != images[0].Manifest.Digest || refs["1.26"] == "" {
+		t.Errorf("expected 1.25 to be replaced and 1.26 kept, got %+v", idx.Manifests)
+	}
+}
+
+func TestWriteOCIArchive(t *testing.T) {
+	config := `{"architecture":"arm64"}`
+	layer := "layer"
+	m := `{"schemaVersion":2,"mediaType":"` + MediaTypeManifest + `","config":{"mediaType":"` + MediaTypeConfig + `","digest":"` + digestOf(config) + `","size":24},"layers":[{"mediaType":"` + MediaTypeLayer + `","digest":"` + digestOf(layer) + `","size":5}]}`
+	idx := `{"schemaVersion":2,"manifests":[{"mediaType":"` + MediaTypeManifest + `","digest":"` + digestOf(m) + `","size":` + itoa(len(m)) + `,"annotations":{"io.containerd.image.name":"docker.io/library/alpine:3.20"}}]}`
+
+	dir := t.TempDir()
+	images, err := Write(dir, buildArchive(t, []tarEntry{
+		{name: "blobs/sha256/" + strings.TrimPrefix(digestOf(config), "sha256:"), data: config},
+		{name: "blobs/sha256/" + strings.TrimPrefix(digestOf(layer), "sha256:"), data: layer},
+		{name: "blobs/sha256/" + strings.TrimPrefix(digestOf(m), "sha256:"), data: m},
+		{name: "index.json", data: idx},
+		{name: "manifest.json", data: `[]`},
+		{name: "oci-layout", data: `{"imageLayoutVersion":"1.0.0"}`},
+	}), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(images) != 1 || images[0].Manifest.Digest != digestOf(m) || images[0].Layers != 1 || images[0].Size != 29 {
+		t.Fatalf("unexpected images %+v", images)
+	}
+	if ref := images[0].Manifest.Annotations[AnnotationRefName]; ref != "3.20" {
+		t.Errorf("expected ref name 3.20, got %q", ref)
+	}
+	if n := blobCount(t, dir); n != 3 {
+		t.Errorf("expected 3 blobs, got %d", n)
+	}
+}
+
+func TestWriteRejectsOtherDirectories(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := Write(dir, legacyArchive(t, "nginx:1.25", `{}`), "")
+	if err == nil || !strings.Contains(err.Error(), "neither empty nor an OCI image layout") {
+		t.Errorf("expected a layout error, got %v", err)
+	}
+}
+
+func TestWriteRemovesBlobsOnFailure(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Write(dir, buildArchive(t, []tarEntry{
+		{name: "aaa/layer.tar", data: "layer"},
+		{name: "manifest.json", data: `[{"Config":"missing.json","Layers":["aaa/layer.tar"]}]`},
+	}), "")
+	if err == nil || !strings.Contains(err.Error(), "missing.json") {
+		t.Fatalf("expected a missing config error, got %v", err)
+	}
+	if n := blobCount(t, dir); n != 0 {
+		t.Errorf("expected no blobs, got %d", n)
+	}
+}
+
+func itoa(n int) string {
+	data, _ := json.Marshal(n)
+	return string(data)
+}
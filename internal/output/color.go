@@ -0,0 +1,143 @@
+package output
+
+import (
+	"strings"
+)
+
+type Color string
+
+const (
+	ColorReset  Color = "\033[0m"
+	ColorRed    Color = "\033[31m"
+	ColorGreen  Color = "\033[32m"
+	ColorYellow Color = "\033[33m"
+	ColorBold   Color = "\033[1m"
+)
+
+// colorEnabled is set once at startup from --no-color, NO_COLOR, and
+// whether stdout is a terminal.
+var colorEnabled = false
+
+// stderrColorEnabled is set like colorEnabled, from whether stderr is a
+// terminal, for the warnings written there.
+var stderrColorEnabled = false
+
+// SetColorEnabled turns colored output on or off for all formatters
+func SetColorEnabled(enabled bool) {
+	colorEnabled = enabled
+}
+
+// SetStderrColorEnabled turns colored warnings on or off
+func SetStderrColorEnabled(enabled bool) {
+	stderrColorEnabled = enabled
+}
+
+// ColorEnabled reports whether colored output is enabled
+func ColorEnabled() bool {
+	return colorEnabled
+}
+
+// Colorize wraps s in the given color when color output is enabled
+func Colorize(s string, color Color) string {
+	if !colorEnabled || s == "" {
+		return s
+	}
+	return string(color) + s + string(ColorReset)
+}
+
+// Warning formats a warning message for stderr, highlighted in yellow when
+// enabled for stderr
+func Warning(message string) string {
+	if !stderrColorEnabled {
+		return "Warning: " + message
+	}
+	return string(ColorYellow) + "Warning: " + message + string(ColorReset)
+}
+
+// statusColumns are the table headers whose values get status highlighting
+var statusColumns = map[string]bool{
+	"status": true,
+	"state":  true,
+	"health": true,
+}
+
+var (
+	goodStatuses = []string{"up", "running", "healthy", "active", "valid", "yes"}
+	badStatuses  = []string{"down", "exited", "dead", "unhealthy", "inactive", "invalid", "error", "failed", "no"}
+	warnStatuses = []string{"paused", "restarting", "starting", "created", "removing", "unknown", "degraded", "health: starting"}
+)
+
+// StatusColor picks a color for a status value such as "Up 5 minutes",
+// "exited", or "unhealthy". It returns an empty color for unknown values.
+func StatusColor(value string) Color {
+	lower := strings.ToLower(strings.TrimSpace(value))
+	if lower == "" || lower == "-" {
+		return ""
+	}
+
+	// Container statuses carry the health in parentheses, which takes priority
+	switch {
+	case strings.Contains(lower, "(unhealthy)"):
+		return ColorRed
+	case strings.Contains(lower, "(health: starting)"):
+		return ColorYellow
+	}
+
+	word := lower
+	if i := strings.IndexAny(word, " ("); i > 0 {
+		word = word[:i]
+	}
+
+	for _, status := range warnStatuses {
+		if word == status || lower == status {
+			return ColorYellow
+		}
+	}
+	for _, status := range badStatuses {
+		if word == status {
+			return ColorRed
+		}
+	}
+	for _, status := range goodStatuses {
+		if word == status {
+			return ColorGreen
+		}
+	}
+	return ""
+}
+
+// ColorizeStatus highlights a status value according to StatusColor
+func ColorizeStatus(value string) string {
+	color := StatusColor(value)
+	if color == "" {
+		return value
+	}
+	return Colorize(value, color)
+}
+
+func colorizeTable(data TableData) TableData {
+	if !colorEnabled {
+		return data
+	}
+
+	var columns []int
+	for i, header := range data.Headers {
+		if statusColumns[strings.ToLower(header)] {
+			columns = append(columns, i)
+		}
+	}
+	if len(columns) == 0 {
+		return data
+	}
+
+	rows := make([][]string, len(data.Rows))
+	for i, row := range data.Rows {
+		rows[i] = append([]string(nil), row...)
+		for _, column := range columns {
+			if column < len(rows[i]) {
+				rows[i][column] = ColorizeStatus(rows[i][column])
+			}
+		}
+	}
+	return TableData{Headers: data.Headers, Rows: rows}
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStatusColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Color
+	}{
+		{value: "Up 5 minutes", want: ColorGreen},
+		{value: "running", want: ColorGreen},
+		{value: "Active", want: ColorGreen},
+		{value: "Up 2 hours (unhealthy)", want: ColorRed},
+		{value: "Up 3 seconds (health: starting)", want: ColorYellow},
+		{value: "Exited (1) 2 days ago", want: ColorRed},
+		{value: "Down", want: ColorRed},
+		{value: "paused", want: ColorYellow},
+		{value: "Degraded", want: ColorYellow},
+		{value: "Unknown", want: ColorYellow},
+		{value: "-", want: ""},
+		{value: "my-container", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := StatusColor(tt.value); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestColorize(t *testing.T) {
+	defer SetColorEnabled(false)
+
+	SetColorEnabled(false)
+	if got := Colorize("text", ColorRed); got != "text" {
+		t.Errorf("expected plain text when disabled, got %q", got)
+	}
+
+	SetColorEnabled(true)
+	if got := Colorize("text", ColorRed); got != string(ColorRed)+"text"+string(ColorReset) {
+		t.Errorf("unexpected colored text: %q", got)
+	}
+}
+
+func TestWarning(t *testing.T) {
+	defer SetColorEnabled(false)
+	defer SetStderrColorEnabled(false)
+
+	// Warnings go to stderr, so stdout colors do not apply
+	SetColorEnabled(true)
+	SetStderrColorEnabled(false)
+	if got := Warning("disk full"); got != "Warning: disk full" {
+		t.Errorf("expected plain warning, got %q", got)
+	}
+
+	SetStderrColorEnabled(true)
+	if got := Warning("disk full"); got != string(ColorYellow)+"Warning: disk full"+string(ColorReset) {
+		t.Errorf("expected yellow warning, got %q", got)
+	}
+}
+
+func TestTableFormatter_Colors(t *testing.T) {
+	defer SetColorEnabled(false)
+	SetColorEnabled(true)
+
+	table := NewTableData([]string{"Name", "Status"})
+	table.AddRow([]string{"running-app", "Up 5 minutes"})
+	table.AddRow([]string{"worker", "Exited (0) 1 hour ago"})
+
+	var buf bytes.Buffer
+	formatter := NewFormatter(Options{Format: FormatTable, Writer: &buf})
+	if err := formatter.Format(*table); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, string(ColorGreen)+"Up 5 minutes") {
+		t.Errorf("expected green status in output: %q", out)
+	}
+	if !strings.Contains(out, string(ColorRed)+"Exited") {
+		t.Errorf("expected red status in output: %q", out)
+	}
+	if strings.Contains(out, string(ColorGreen)+"running-app") {
+		t.Errorf("name column should not be colored: %q", out)
+	}
+	if table.Rows[0][1] != "Up 5 minutes" {
+		t.Errorf("original table should not be modified, got %q", table.Rows[0][1])
+	}
+}
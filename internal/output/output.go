@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/olekukonko/tablewriter"
@@ -29,6 +30,10 @@ type Options struct {
 	Quiet   bool
 	Verbose bool
 	Fields  []string
+	// Query is a JMESPath-style expression applied before formatting
+	Query string
+	// Raw prints query results that are scalars, or lists of scalars, one per line
+	Raw bool
 }
 
 func NewFormatter(opts Options) Formatter {
@@ -36,6 +41,17 @@ func NewFormatter(opts Options) Formatter {
 		opts.Writer = os.Stdout
 	}
 
+	if opts.Query != "" {
+		next := opts
+		next.Query = ""
+		return &QueryFormatter{
+			query:  opts.Query,
+			raw:    opts.Raw,
+			writer: opts.Writer,
+			next:   NewFormatter(next),
+		}
+	}
+
 	switch opts.Format {
 	case FormatJSON:
 		return &JSONFormatter{writer: opts.Writer}
@@ -121,10 +137,12 @@ func (f *TableFormatter) formatTableData(data TableData) error {
 		return nil
 	}
 
+	data = colorizeTable(data)
+
 	table := tablewriter.NewWriter(f.writer)
 	table.SetHeader(data.Headers)
-	table.AppendBulk(data.Rows)
 
+	// Wrapping must be disabled before rows are appended to take effect
 	table.SetAutoWrapText(false)
 	table.SetAutoFormatHeaders(true)
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
@@ -137,6 +155,7 @@ func (f *TableFormatter) formatTableData(data TableData) error {
 	table.SetTablePadding("\t")
 	table.SetNoWhiteSpace(true)
 
+	table.AppendBulk(data.Rows)
 	table.Render()
 	return nil
 }
@@ -240,6 +259,27 @@ func FormatSize(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+// ParseSize parses a byte size such as "512", "100MB", "1.5 GB" or "10k".
+// Units are powers of 1024, like FormatSize, and the trailing B is optional.
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	value = strings.TrimSuffix(value, "B")
+
+	multiplier := int64(1)
+	if n := len(value); n > 0 {
+		if i := strings.IndexByte("KMGTPE", value[n-1]); i >= 0 {
+			multiplier = int64(1) << (10 * (i + 1))
+			value = value[:n-1]
+		}
+	}
+
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 512, 100MB or 1.5GB)", s)
+	}
+	return int64(number * float64(multiplier)), nil
+}
+
 func FormatDuration(seconds int64) string {
 	if seconds < 60 {
 		return fmt.Sprintf("%ds", seconds)
@@ -391,6 +391,32 @@ func TestFormatList(t *testing.T) {
 	}
 }
 
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"512":    512,
+		"512B":   512,
+		"10k":    10 << 10,
+		"100MB":  100 << 20,
+		"1.5 GB": 3 << 29,
+	}
+	for input, expected := range tests {
+		result, err := ParseSize(input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", input, err)
+			continue
+		}
+		if result != expected {
+			t.Errorf("%q: expected %d, got %d", input, expected, result)
+		}
+	}
+
+	for _, input := range []string{"", "MB", "-1", "10XB"} {
+		if _, err := ParseSize(input); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
 func TestFormatSize(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -521,3 +547,155 @@ func TestPrintFunctions(t *testing.T) {
 		}
 	})
 }
+
+func TestTableData_Sort(t *testing.T) {
+	newTable := func() *TableData {
+		table := NewTableData([]string{"Name", "Size", "Created"})
+		table.AddRow([]string{"beta", "1.5 MB", "2d"})
+		table.AddRow([]string{"alpha", "900 B", "3h"})
+		table.AddRow([]string{"Gamma", "2.0 GB", "45s"})
+		return table
+	}
+
+	tests := []struct {
+		name    string
+		column  string
+		reverse bool
+		want    []string
+		wantErr bool
+	}{
+		{name: "by name", column: "name", want: []string{"alpha", "beta", "Gamma"}},
+		{name: "by size", column: "SIZE", want: []string{"alpha", "beta", "Gamma"}},
+		{name: "by size reversed", column: "size", reverse: true, want: []string{"Gamma", "beta", "alpha"}},
+		{name: "by age", column: "created", want: []string{"Gamma", "alpha", "beta"}},
+		{name: "unknown column", column: "status", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newTable()
+			err := table.Sort(tt.column, tt.reverse)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, name := range tt.want {
+				if table.Rows[i][0] != name {
+					t.Errorf("row %d: expected %s, got %s", i, name, table.Rows[i][0])
+				}
+			}
+		})
+	}
+}
+
+func TestSortByField(t *testing.T) {
+	type item struct {
+		Names   []string
+		Created int64
+	}
+
+	items := []item{
+		{Names: []string{"/web"}, Created: 300},
+		{Names: []string{"/api"}, Created: 100},
+		{Names: []string{"/db"}, Created: 200},
+	}
+
+	if err := SortByField(items, "created", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if items[0].Created != 100 || items[2].Created != 300 {
+		t.Errorf("unexpected order: %v", items)
+	}
+
+	if err := SortByField(items, "name", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if items[0].Names[0] != "/web" || items[2].Names[0] != "/api" {
+		t.Errorf("unexpected order: %v", items)
+	}
+
+	if err := SortByField(items, "missing", false); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestTableData_SortWith(t *testing.T) {
+	type item struct {
+		Name    string
+		Created int64
+	}
+	items := []item{{Name: "web", Created: 300}, {Name: "api", Created: 100}, {Name: "db", Created: 200}}
+
+	// Rendered cells such as ages sort differently from the raw values
+	table := NewTableData([]string{"Name", "Created", "Status"})
+	table.AddRow([]string{"web", "just now", "up"})
+	table.AddRow([]string{"api", "3 days ago", "down"})
+	table.AddRow([]string{"db", "2 days ago", "up"})
+
+	if err := table.SortWith(items, "created", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := SortByField(items, "created", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, it := range items {
+		if table.Rows[i][0] != it.Name {
+			t.Errorf("row %d: expected %s as in structured output, got %s", i, it.Name, table.Rows[i][0])
+		}
+	}
+
+	// Columns without a field are sorted on their cells
+	if err := table.SortWith(items, "status", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if table.Rows[0][0] != "api" {
+		t.Errorf("expected the down row first, got %v", table.Rows)
+	}
+	if err := table.SortWith(items, "missing", false); err == nil {
+		t.Error("expected error for unknown column")
+	}
+}
+
+func TestTableData_Paginate(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int
+		limit  int
+		want   []string
+	}{
+		{name: "no limit", offset: 0, limit: 0, want: []string{"a", "b", "c", "d"}},
+		{name: "first page", offset: 0, limit: 2, want: []string{"a", "b"}},
+		{name: "second page", offset: 2, limit: 2, want: []string{"c", "d"}},
+		{name: "partial page", offset: 3, limit: 2, want: []string{"d"}},
+		{name: "past end", offset: 10, limit: 2, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := NewTableData([]string{"Name"})
+			for _, name := range []string{"a", "b", "c", "d"} {
+				table.AddRow([]string{name})
+			}
+
+			table.Paginate(tt.offset, tt.limit)
+			if len(table.Rows) != len(tt.want) {
+				t.Fatalf("expected %d rows, got %d", len(tt.want), len(table.Rows))
+			}
+			for i, name := range tt.want {
+				if table.Rows[i][0] != name {
+					t.Errorf("row %d: expected %s, got %s", i, name, table.Rows[i][0])
+				}
+			}
+
+			items := PaginateSlice([]string{"a", "b", "c", "d"}, tt.offset, tt.limit).([]string)
+			if len(items) != len(tt.want) {
+				t.Errorf("expected %d items, got %d", len(tt.want), len(items))
+			}
+		})
+	}
+}
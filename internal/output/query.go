@@ -0,0 +1,491 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Query applies a JMESPath-style expression to data. The supported subset
+// covers what is most useful on the command line:
+//
+//	Name, Config.Image        field access (exact, then case-insensitive)
+//	[0], [-1]                 index
+//	[], [*]                   projection over a list
+//	[?State=='running']       filter projection (==, !=, <, <=, >, >=)
+//	[Id, Name]                multi-select list
+//	{id: Id, name: Name}      multi-select hash
+//	expr | expr               pipe
+//
+// data is first converted to its JSON representation, so field names match
+// the keys shown by -o json.
+func Query(data interface{}, expression string) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare data for query: %w", err)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("failed to prepare data for query: %w", err)
+	}
+
+	return evalQuery(value, strings.TrimSpace(expression))
+}
+
+func evalQuery(value interface{}, expr string) (interface{}, error) {
+	parts := splitTopLevel(expr, '|')
+	if len(parts) > 1 {
+		var err error
+		for _, part := range parts {
+			value, err = evalQuery(value, strings.TrimSpace(part))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	}
+
+	return evalPath(value, expr)
+}
+
+func evalPath(value interface{}, expr string) (interface{}, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" || expr == "@" {
+		return value, nil
+	}
+
+	switch expr[0] {
+	case '.':
+		return evalPath(value, expr[1:])
+
+	case '{':
+		end, err := matchingBracket(expr, 0)
+		if err != nil {
+			return nil, err
+		}
+		result, err := multiSelectHash(value, expr[1:end])
+		if err != nil {
+			return nil, err
+		}
+		return evalPath(result, expr[end+1:])
+
+	case '[':
+		end, err := matchingBracket(expr, 0)
+		if err != nil {
+			return nil, err
+		}
+		inner := strings.TrimSpace(expr[1:end])
+		rest := expr[end+1:]
+
+		switch {
+		case inner == "" || inner == "*":
+			list, ok := value.([]interface{})
+			if !ok {
+				return nil, nil
+			}
+			if inner == "" {
+				list = flatten(list)
+			}
+			return project(list, rest)
+
+		case strings.HasPrefix(inner, "?"):
+			list, ok := value.([]interface{})
+			if !ok {
+				return nil, nil
+			}
+			var filtered []interface{}
+			for _, item := range list {
+				match, err := evalCondition(item, inner[1:])
+				if err != nil {
+					return nil, err
+				}
+				if match {
+					filtered = append(filtered, item)
+				}
+			}
+			return project(filtered, rest)
+
+		default:
+			if index, err := strconv.Atoi(inner); err == nil {
+				list, ok := value.([]interface{})
+				if !ok {
+					return nil, nil
+				}
+				if index < 0 {
+					index += len(list)
+				}
+				if index < 0 || index >= len(list) {
+					return nil, nil
+				}
+				return evalPath(list[index], rest)
+			}
+
+			result, err := multiSelectList(value, inner)
+			if err != nil {
+				return nil, err
+			}
+			return evalPath(result, rest)
+		}
+
+	default:
+		end := 0
+		for end < len(expr) && expr[end] != '.' && expr[end] != '[' && expr[end] != '{' {
+			end++
+		}
+		name := strings.TrimSpace(expr[:end])
+		if !isIdentifier(name) {
+			return nil, fmt.Errorf("invalid query expression: %s", expr)
+		}
+		return evalPath(field(value, name), expr[end:])
+	}
+}
+
+func project(list []interface{}, rest string) (interface{}, error) {
+	// A nested flatten applies to the projected results as a whole, so
+	// "[].Ports[]" yields a single list of ports.
+	head, tail, nested := strings.Cut(rest, "[]")
+	if nested && strings.Count(head, "[") != strings.Count(head, "]") {
+		head, nested = rest, false
+	}
+
+	results := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		result, err := evalPath(item, head)
+		if err != nil {
+			return nil, err
+		}
+		if result != nil {
+			results = append(results, result)
+		}
+	}
+
+	if nested {
+		return project(flatten(results), tail)
+	}
+	return results, nil
+}
+
+func flatten(list []interface{}) []interface{} {
+	var result []interface{}
+	for _, item := range list {
+		if nested, ok := item.([]interface{}); ok {
+			result = append(result, nested...)
+		} else {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func field(value interface{}, name string) interface{} {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if v, ok := object[name]; ok {
+		return v
+	}
+	for key, v := range object {
+		if strings.EqualFold(key, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+func multiSelectList(value interface{}, inner string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	var result []interface{}
+	for _, part := range splitTopLevel(inner, ',') {
+		v, err := evalQuery(value, strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func multiSelectHash(value interface{}, inner string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	result := map[string]interface{}{}
+	for _, part := range splitTopLevel(inner, ',') {
+		key, expr, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid multi-select hash entry: %s", strings.TrimSpace(part))
+		}
+		v, err := evalQuery(value, strings.TrimSpace(expr))
+		if err != nil {
+			return nil, err
+		}
+		result[strings.TrimSpace(key)] = v
+	}
+	return result, nil
+}
+
+var comparisonOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func evalCondition(item interface{}, condition string) (bool, error) {
+	for _, op := range comparisonOperators {
+		index := indexTopLevel(condition, op)
+		if index < 0 {
+			continue
+		}
+
+		left, err := evalOperand(item, condition[:index])
+		if err != nil {
+			return false, err
+		}
+		right, err := evalOperand(item, condition[index+len(op):])
+		if err != nil {
+			return false, err
+		}
+		return compareOperands(left, right, op), nil
+	}
+
+	value, err := evalQuery(item, condition)
+	if err != nil {
+		return false, err
+	}
+	return truthy(value), nil
+}
+
+func evalOperand(item interface{}, operand string) (interface{}, error) {
+	operand = strings.TrimSpace(operand)
+	if len(operand) >= 2 {
+		switch {
+		case operand[0] == '\'' && operand[len(operand)-1] == '\'':
+			return operand[1 : len(operand)-1], nil
+		case operand[0] == '`' && operand[len(operand)-1] == '`':
+			var literal interface{}
+			if err := json.Unmarshal([]byte(operand[1:len(operand)-1]), &literal); err != nil {
+				return nil, fmt.Errorf("invalid literal %s: %w", operand, err)
+			}
+			return literal, nil
+		}
+	}
+	if number, err := strconv.ParseFloat(operand, 64); err == nil {
+		return number, nil
+	}
+	return evalQuery(item, operand)
+}
+
+func compareOperands(left, right interface{}, op string) bool {
+	if l, ok := left.(float64); ok {
+		if r, ok := right.(float64); ok {
+			switch op {
+			case "==":
+				return l == r
+			case "!=":
+				return l != r
+			case "<":
+				return l < r
+			case "<=":
+				return l <= r
+			case ">":
+				return l > r
+			case ">=":
+				return l >= r
+			}
+		}
+	}
+
+	l, _ := json.Marshal(left)
+	r, _ := json.Marshal(right)
+	switch op {
+	case "==":
+		return string(l) == string(r)
+	case "!=":
+		return string(l) != string(r)
+	default:
+		ls, lok := left.(string)
+		rs, rok := right.(string)
+		if !lok || !rok {
+			return false
+		}
+		switch op {
+		case "<":
+			return ls < rs
+		case "<=":
+			return ls <= rs
+		case ">":
+			return ls > rs
+		default:
+			return ls >= rs
+		}
+	}
+}
+
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func matchingBracket(expr string, start int) (int, error) {
+	depth := 0
+	var quote byte
+	for i := start; i < len(expr); i++ {
+		c := expr[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '`', '"':
+			quote = c
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unbalanced brackets in query: %s", expr)
+}
+
+// splitTopLevel splits expr on sep, ignoring separators nested in brackets
+// or quotes.
+func splitTopLevel(expr string, sep byte) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	last := 0
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '`', '"':
+			quote = c
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, expr[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, expr[last:])
+}
+
+func indexTopLevel(expr, substr string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '`', '"':
+			quote = c
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			depth--
+		default:
+			if depth == 0 && strings.HasPrefix(expr[i:], substr) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// QueryFormatter applies a query before handing the result to another
+// formatter. In raw mode, strings and lists of scalars are written one per
+// line so they can be piped into other tools.
+type QueryFormatter struct {
+	query  string
+	raw    bool
+	writer io.Writer
+	next   Formatter
+}
+
+func (f *QueryFormatter) Format(data interface{}) error {
+	result, err := Query(data, f.query)
+	if err != nil {
+		return err
+	}
+
+	if f.raw {
+		if lines, ok := scalarLines(result); ok {
+			for _, line := range lines {
+				fmt.Fprintln(f.writer, line)
+			}
+			return nil
+		}
+	}
+
+	return f.next.Format(result)
+}
+
+func scalarLines(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, true
+	case string:
+		return []string{v}, true
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}, true
+	case bool:
+		return []string{strconv.FormatBool(v)}, true
+	case []interface{}:
+		lines := make([]string, 0, len(v))
+		for _, item := range v {
+			itemLines, ok := scalarLines(item)
+			if !ok || len(itemLines) != 1 {
+				return nil, false
+			}
+			lines = append(lines, itemLines[0])
+		}
+		return lines, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+type queryContainer struct {
+	Id     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	State  string            `json:"State"`
+	Ports  []int             `json:"Ports"`
+	Labels map[string]string `json:"Labels"`
+}
+
+func queryFixture() []queryContainer {
+	return []queryContainer{
+		{Id: "a1", Names: []string{"/web"}, State: "running", Ports: []int{80, 443}, Labels: map[string]string{"env": "prod"}},
+		{Id: "b2", Names: []string{"/db"}, State: "exited", Ports: []int{5432}, Labels: map[string]string{"env": "dev"}},
+		{Id: "c3", Names: []string{"/cache"}, State: "running", Labels: map[string]string{}},
+	}
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{name: "projection", query: "[].Id", want: `["a1","b2","c3"]`},
+		{name: "star projection", query: "[*].State", want: `["running","exited","running"]`},
+		{name: "index", query: "[1].Id", want: `"b2"`},
+		{name: "negative index", query: "[-1].Id", want: `"c3"`},
+		{name: "nested index", query: "[0].Names[0]", want: `"/web"`},
+		{name: "case-insensitive field", query: "[0].id", want: `"a1"`},
+		{name: "nested field", query: "[0].Labels.env", want: `"prod"`},
+		{name: "filter", query: "[?State=='running'].Id", want: `["a1","c3"]`},
+		{name: "filter with literal", query: "[?Labels.env==`\"dev\"`].Id", want: `["b2"]`},
+		{name: "flatten", query: "[].Ports[]", want: `[80,443,5432]`},
+		{name: "multi-select list", query: "[0].[Id, State]", want: `["a1","running"]`},
+		{name: "multi-select hash", query: "[0].{id: Id, state: State}", want: `{"id":"a1","state":"running"}`},
+		{name: "pipe", query: "[?State=='running'].Id | [0]", want: `"a1"`},
+		{name: "missing field", query: "[0].Missing", want: `null`},
+		{name: "unbalanced", query: "[0", wantErr: true},
+		{name: "invalid", query: "[0].@@", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Query(queryFixture(), tt.query)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := json.Marshal(result)
+			if err != nil {
+				t.Fatalf("failed to marshal result: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestQueryFormatter_Raw(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := NewFormatter(Options{
+		Format: FormatJSON,
+		Writer: &buf,
+		Query:  "[].Names[0]",
+		Raw:    true,
+	})
+
+	if err := formatter.Format(queryFixture()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "/web\n/db\n/cache\n"
+	if buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
@@ -0,0 +1,243 @@
+package output
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Sort orders the table rows by the named column. Column names are matched
+// case-insensitively against the headers. Values produced by FormatSize and
+// FormatDuration, as well as plain numbers, are compared numerically.
+func (t *TableData) Sort(column string, reverse bool) error {
+	index := -1
+	for i, header := range t.Headers {
+		if strings.EqualFold(header, column) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("unknown sort column: %s (available: %s)", column, strings.ToLower(strings.Join(t.Headers, ", ")))
+	}
+
+	sort.SliceStable(t.Rows, func(i, j int) bool {
+		a, b := cell(t.Rows[i], index), cell(t.Rows[j], index)
+		if reverse {
+			return compareValues(b, a) < 0
+		}
+		return compareValues(a, b) < 0
+	})
+	return nil
+}
+
+// SortWith orders table rows rendered one per element of data by the raw
+// values of the named field of data, so tables come out in the same order as
+// SortByField sorts structured output. Columns without a matching field are
+// sorted on their cells.
+func (t *TableData) SortWith(data interface{}, column string, reverse bool) error {
+	order, err := fieldOrder(data, column, reverse)
+	if err != nil || len(order) != len(t.Rows) {
+		return t.Sort(column, reverse)
+	}
+
+	rows := make([][]string, len(order))
+	for i, index := range order {
+		rows[i] = t.Rows[index]
+	}
+	t.Rows = rows
+	return nil
+}
+
+// SortByField orders a slice of structs (or pointers to structs) by the
+// named field, matched case-insensitively. It is used for structured output
+// where there is no table to sort.
+func SortByField(data interface{}, field string, reverse bool) error {
+	order, err := fieldOrder(data, field, reverse)
+	if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(data)
+	sorted := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	for i, index := range order {
+		sorted.Index(i).Set(v.Index(index))
+	}
+	reflect.Copy(v, sorted)
+	return nil
+}
+
+// fieldOrder returns the indexes of the elements of data ordered by the
+// named field
+func fieldOrder(data interface{}, field string, reverse bool) ([]int, error) {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("cannot sort %T", data)
+	}
+
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot sort %T", data)
+	}
+
+	fieldIndex := -1
+	var names []string
+	for i := 0; i < elemType.NumField(); i++ {
+		f := elemType.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		names = append(names, strings.ToLower(f.Name))
+		if strings.EqualFold(f.Name, field) || strings.EqualFold(f.Name, field+"s") {
+			fieldIndex = i
+			break
+		}
+	}
+	if fieldIndex < 0 {
+		return nil, fmt.Errorf("unknown sort field: %s (available: %s)", field, strings.Join(names, ", "))
+	}
+
+	values := make([]string, v.Len())
+	order := make([]int, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		values[i] = fieldString(elem.Field(fieldIndex))
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := values[order[i]], values[order[j]]
+		if reverse {
+			return compareValues(b, a) < 0
+		}
+		return compareValues(a, b) < 0
+	})
+	return order, nil
+}
+
+func fieldString(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return ""
+		}
+		return fieldString(v.Index(0))
+	case reflect.Ptr:
+		if v.IsNil() {
+			return ""
+		}
+		return fieldString(v.Elem())
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func cell(row []string, index int) string {
+	if index < len(row) {
+		return row[index]
+	}
+	return ""
+}
+
+func compareValues(a, b string) int {
+	if x, ok := parseNumeric(a); ok {
+		if y, ok := parseNumeric(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// parseNumeric understands plain numbers and the human-readable values
+// produced by FormatSize ("1.5 MB") and FormatDuration ("3h").
+func parseNumeric(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+
+	if number, unit, ok := strings.Cut(s, " "); ok {
+		f, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, false
+		}
+		multiplier := 1.0
+		switch unit {
+		case "B":
+		case "KB":
+			multiplier = 1 << 10
+		case "MB":
+			multiplier = 1 << 20
+		case "GB":
+			multiplier = 1 << 30
+		case "TB":
+			multiplier = 1 << 40
+		case "PB":
+			multiplier = 1 << 50
+		case "EB":
+			multiplier = 1 << 60
+		default:
+			return 0, false
+		}
+		return f * multiplier, true
+	}
+
+	multipliers := map[byte]float64{'s': 1, 'm': 60, 'h': 3600, 'd': 86400}
+	if multiplier, ok := multipliers[s[len(s)-1]]; ok {
+		if n, err := strconv.ParseInt(s[:len(s)-1], 10, 64); err == nil {
+			return float64(n) * multiplier, true
+		}
+	}
+
+	return 0, false
+}
+
+// Paginate keeps at most limit rows starting at offset. A limit of zero
+// keeps all remaining rows.
+func (t *TableData) Paginate(offset, limit int) {
+	start, end := pageBounds(len(t.Rows), offset, limit)
+	t.Rows = t.Rows[start:end]
+}
+
+// PaginateSlice returns the window of data described by offset and limit
+func PaginateSlice(data interface{}, offset, limit int) interface{} {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return data
+	}
+	start, end := pageBounds(v.Len(), offset, limit)
+	return v.Slice(start, end).Interface()
+}
+
+func pageBounds(length, offset, limit int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > length {
+		offset = length
+	}
+	end := length
+	if limit > 0 && offset+limit < length {
+		end = offset + limit
+	}
+	return offset, end
+}
@@ -0,0 +1,108 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// TableStream writes a table row by row, for lists too long to be held in
+// memory before printing. Column widths are taken from the first batch of
+// rows, and later cells that are wider simply push the row out. A table
+// that fits in one batch is printed exactly like PrintTable.
+type TableStream struct {
+	w         io.Writer
+	headers   []string
+	batchSize int
+	rows      [][]string
+	widths    []int
+	status    map[int]bool
+}
+
+// NewTableStream creates a stream writing to w. batchSize is the number of
+// rows buffered to size the columns.
+func NewTableStream(w io.Writer, headers []string, batchSize int) *TableStream {
+	status := map[int]bool{}
+	for i, header := range headers {
+		if statusColumns[strings.ToLower(header)] {
+			status[i] = true
+		}
+	}
+	return &TableStream{w: w, headers: headers, batchSize: max(batchSize, 1), status: status}
+}
+
+// AddRow buffers row until the first batch is full, and writes it directly
+// afterwards
+func (t *TableStream) AddRow(row []string) error {
+	if t.widths != nil {
+		return t.writeRow(row)
+	}
+
+	t.rows = append(t.rows, row)
+	if len(t.rows) < t.batchSize {
+		return nil
+	}
+
+	t.widths = make([]int, len(t.headers))
+	for i, header := range t.headers {
+		t.widths[i] = utf8.RuneCountInString(header)
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if i < len(t.widths) {
+				t.widths[i] = max(t.widths[i], utf8.RuneCountInString(cell))
+			}
+		}
+	}
+
+	headers := make([]string, len(t.headers))
+	for i, header := range t.headers {
+		headers[i] = strings.ToUpper(header)
+	}
+	if err := t.writeLine(headers, false); err != nil {
+		return err
+	}
+	for _, row := range t.rows {
+		if err := t.writeRow(row); err != nil {
+			return err
+		}
+	}
+	t.rows = nil
+	return nil
+}
+
+// Close writes the rows of a table shorter than one batch
+func (t *TableStream) Close() error {
+	if t.widths != nil {
+		return nil
+	}
+	data := TableData{Headers: t.headers, Rows: t.rows}
+	return NewFormatter(Options{Format: FormatTable, Writer: t.w}).Format(data)
+}
+
+func (t *TableStream) writeRow(row []string) error {
+	return t.writeLine(row, true)
+}
+
+func (t *TableStream) writeLine(cells []string, colorize bool) error {
+	var line strings.Builder
+	for i, cell := range cells {
+		if i > 0 {
+			line.WriteByte('\t')
+		}
+		padding := 0
+		if i < len(t.widths) {
+			padding = max(0, t.widths[i]-utf8.RuneCountInString(cell))
+		}
+		if colorize && t.status[i] {
+			cell = ColorizeStatus(cell)
+		}
+		line.WriteString(cell)
+		if i < len(cells)-1 {
+			line.WriteString(strings.Repeat(" ", padding))
+		}
+	}
+	_, err := fmt.Fprintln(t.w, line.String())
+	return err
+}
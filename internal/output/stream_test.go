@@ -0,0 +1,53 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTableStream(t *testing.T) {
+	var buf bytes.Buffer
+	stream := NewTableStream(&buf, []string{"ID", "Name"}, 2)
+	for _, row := range [][]string{{"abc", "web"}, {"a", "worker"}, {"abcdef", "db"}} {
+		if err := stream.AddRow(row); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "ID \tNAME\n" +
+		"abc\tweb\n" +
+		"a  \tworker\n" +
+		"abcdef\tdb\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, buf.String())
+	}
+}
+
+func TestTableStream_ShortTable(t *testing.T) {
+	rows := [][]string{{"abc", "web"}, {"a", "longer-name"}}
+
+	var streamed bytes.Buffer
+	stream := NewTableStream(&streamed, []string{"ID", "Name"}, 10)
+	for _, row := range rows {
+		if err := stream.AddRow(row); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var printed bytes.Buffer
+	table := NewTableData([]string{"ID", "Name"})
+	table.AddRows(rows)
+	if err := NewFormatter(Options{Format: FormatTable, Writer: &printed}).Format(*table); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if streamed.String() != printed.String() {
+		t.Errorf("expected a short table to match PrintTable:\n%q\ngot:\n%q", printed.String(), streamed.String())
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package plugin
+
+import "os"
+
+func isExecutable(name string, info os.FileInfo) bool {
+	return info.Mode().Perm()&0111 != 0
+}
+
+func executableExt(name string) string {
+	return ""
+}
@@ -0,0 +1,32 @@
+//go:build windows
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// executableExts returns the extensions Windows treats as executable
+func executableExts() []string {
+	pathExt := os.Getenv("PATHEXT")
+	if pathExt == "" {
+		pathExt = ".com;.exe;.bat;.cmd"
+	}
+	return strings.Split(strings.ToLower(pathExt), ";")
+}
+
+func isExecutable(name string, info os.FileInfo) bool {
+	return executableExt(name) != ""
+}
+
+func executableExt(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range executableExts() {
+		if e != "" && ext == e {
+			return filepath.Ext(name)
+		}
+	}
+	return ""
+}
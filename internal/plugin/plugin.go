@@ -0,0 +1,82 @@
+// Package plugin discovers external subcommands: executables named
+// portainer-cli-<name> on PATH, which are run as `portainer-cli <name>`.
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Prefix is the file name prefix of plugin executables
+const Prefix = "portainer-cli-"
+
+// Plugin is a plugin executable found on PATH
+type Plugin struct {
+	Name string
+	Path string
+	// Shadowed is set when a plugin with the same name appears earlier on
+	// PATH and is the one that runs
+	Shadowed bool
+}
+
+// Find returns the plugins in the directories of pathList, a PATH-style
+// list, in PATH order
+func Find(pathList string) []Plugin {
+	var plugins []Plugin
+	seen := make(map[string]bool)
+	seenDirs := make(map[string]bool)
+
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" || seenDirs[dir] {
+			continue
+		}
+		seenDirs[dir] = true
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasPrefix(entry.Name(), Prefix) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || !isExecutable(entry.Name(), info) {
+				continue
+			}
+
+			name := pluginName(entry.Name())
+			if name == "" {
+				continue
+			}
+
+			plugins = append(plugins, Plugin{
+				Name:     name,
+				Path:     filepath.Join(dir, entry.Name()),
+				Shadowed: seen[name],
+			})
+			seen[name] = true
+		}
+	}
+
+	return plugins
+}
+
+// Lookup returns the path of the plugin called name, the first match on
+// pathList
+func Lookup(pathList, name string) (string, bool) {
+	for _, p := range Find(pathList) {
+		if p.Name == name && !p.Shadowed {
+			return p.Path, true
+		}
+	}
+	return "", false
+}
+
+// pluginName returns the subcommand name for an executable file name
+func pluginName(file string) string {
+	name := strings.TrimPrefix(file, Prefix)
+	return strings.TrimSuffix(name, executableExt(name))
+}
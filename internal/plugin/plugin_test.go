@@ -0,0 +1,58 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeFile(t *testing.T, path string, mode os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executable bits are not used on Windows")
+	}
+
+	first := t.TempDir()
+	second := t.TempDir()
+
+	writeFile(t, filepath.Join(first, "portainer-cli-backup"), 0755)
+	writeFile(t, filepath.Join(first, "portainer-cli-notes"), 0644)
+	writeFile(t, filepath.Join(first, "other-tool"), 0755)
+	writeFile(t, filepath.Join(second, "portainer-cli-backup"), 0755)
+	writeFile(t, filepath.Join(second, "portainer-cli-audit"), 0755)
+	if err := os.Mkdir(filepath.Join(second, "portainer-cli-dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	pathList := first + string(os.PathListSeparator) + second + string(os.PathListSeparator) + first
+	plugins := Find(pathList)
+
+	expected := []Plugin{
+		{Name: "backup", Path: filepath.Join(first, "portainer-cli-backup")},
+		{Name: "audit", Path: filepath.Join(second, "portainer-cli-audit")},
+		{Name: "backup", Path: filepath.Join(second, "portainer-cli-backup"), Shadowed: true},
+	}
+	if len(plugins) != len(expected) {
+		t.Fatalf("expected %d plugins, got %+v", len(expected), plugins)
+	}
+	for i, p := range expected {
+		if plugins[i] != p {
+			t.Errorf("plugin %d: expected %+v, got %+v", i, p, plugins[i])
+		}
+	}
+
+	path, ok := Lookup(pathList, "backup")
+	if !ok || path != filepath.Join(first, "portainer-cli-backup") {
+		t.Errorf("expected first backup plugin, got %q", path)
+	}
+	if _, ok := Lookup(pathList, "notes"); ok {
+		t.Error("non-executable file should not be a plugin")
+	}
+}
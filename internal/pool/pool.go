@@ -0,0 +1,54 @@
+// Package pool runs independent tasks, such as one API request per
+// environment, on a bounded number of goroutines.
+package pool
+
+import (
+	"runtime"
+	"sync"
+)
+
+// DefaultSize is the number of workers used when none is configured. The
+// tasks mostly wait on the network, so it is a multiple of the CPU count.
+func DefaultSize() int {
+	return max(4, 2*runtime.NumCPU())
+}
+
+// Run calls fn for each index in [0, n) on at most size goroutines and
+// returns once all calls have finished. A size below 1 runs the calls one
+// at a time. fn must only write state belonging to its index.
+func Run(size, n int, fn func(i int)) {
+	size = min(max(size, 1), n)
+	if size <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < size; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// Map calls fn for each item on at most size goroutines and returns the
+// results in the order of items
+func Map[T, R any](size int, items []T, fn func(item T) R) []R {
+	results := make([]R, len(items))
+	Run(size, len(items), func(i int) {
+		results[i] = fn(items[i])
+	})
+	return results
+}
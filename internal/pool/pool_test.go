@@ -0,0 +1,56 @@
+package pool
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun_LimitsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	var calls atomic.Int32
+
+	Run(3, 20, func(i int) {
+		calls.Add(1)
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+	})
+
+	if calls.Load() != 20 {
+		t.Errorf("expected 20 calls, got %d", calls.Load())
+	}
+	if peak.Load() > 3 {
+		t.Errorf("expected at most 3 concurrent calls, got %d", peak.Load())
+	}
+}
+
+func TestRun_Sequential(t *testing.T) {
+	var order []int
+	Run(0, 4, func(i int) {
+		order = append(order, i)
+	})
+	if !reflect.DeepEqual(order, []int{0, 1, 2, 3}) {
+		t.Errorf("expected calls in order, got %v", order)
+	}
+
+	Run(4, 0, func(i int) {
+		t.Error("expected no calls")
+	})
+}
+
+func TestMap(t *testing.T) {
+	squares := Map(4, []int{1, 2, 3, 4, 5}, func(n int) int {
+		return n * n
+	})
+	if !reflect.DeepEqual(squares, []int{1, 4, 9, 16, 25}) {
+		t.Errorf("unexpected results: %v", squares)
+	}
+}
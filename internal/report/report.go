@@ -0,0 +1,204 @@
+// Package report renders inventory reports of Portainer environments as
+// Markdown, HTML or JSON.
+package report
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/robversluis/portainer-cli/internal/output"
+)
+
+// Format is a report output format
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatHTML     Format = "html"
+	FormatJSON     Format = "json"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// Report is an inventory of environments, their containers and images, and
+// the stacks deployed on them
+type Report struct {
+	Title        string        `json:"Title"`
+	URL          string        `json:"URL"`
+	GeneratedAt  time.Time     `json:"GeneratedAt"`
+	StaleAfter   string        `json:"StaleAfter"`
+	Summary      Summary       `json:"Summary"`
+	Environments []Environment `json:"Environments"`
+	Stacks       []Stack       `json:"Stacks"`
+}
+
+// Summary totals the report across environments
+type Summary struct {
+	Environments   int `json:"Environments"`
+	EnvironmentsUp int `json:"EnvironmentsUp"`
+	Containers     int `json:"Containers"`
+	Running        int `json:"Running"`
+	Unhealthy      int `json:"Unhealthy"`
+	StaleImages    int `json:"StaleImages"`
+	Stacks         int `json:"Stacks"`
+}
+
+// Environment is the inventory of one environment. Container and image
+// details are only available for Docker environments that are up; Error
+// records why they could not be listed.
+type Environment struct {
+	ID          int         `json:"ID"`
+	Name        string      `json:"Name"`
+	Type        string      `json:"Type"`
+	Status      string      `json:"Status"`
+	Version     string      `json:"Version,omitempty"`
+	Containers  Containers  `json:"Containers"`
+	Unhealthy   []Container `json:"Unhealthy"`
+	StaleImages []Image     `json:"StaleImages"`
+	Error       string      `json:"Error,omitempty"`
+}
+
+// Containers counts the containers of an environment by state
+type Containers struct {
+	Total     int `json:"Total"`
+	Running   int `json:"Running"`
+	Stopped   int `json:"Stopped"`
+	Healthy   int `json:"Healthy"`
+	Unhealthy int `json:"Unhealthy"`
+}
+
+// Container identifies a container in the report
+type Container struct {
+	Name   string `json:"Name"`
+	Image  string `json:"Image"`
+	Status string `json:"Status"`
+}
+
+// Image is an image that no container uses
+type Image struct {
+	Repository string    `json:"Repository"`
+	Tag        string    `json:"Tag"`
+	ID         string    `json:"ID"`
+	Size       int64     `json:"Size"`
+	Created    time.Time `json:"Created"`
+}
+
+// Stack is a stack deployed on one of the reported environments
+type Stack struct {
+	ID          int       `json:"ID"`
+	Name        string    `json:"Name"`
+	Environment string    `json:"Environment"`
+	Type        string    `json:"Type"`
+	Status      string    `json:"Status"`
+	Updated     time.Time `json:"Updated,omitempty"`
+}
+
+// ParseFormat parses a report format name
+func ParseFormat(format string) (Format, error) {
+	switch strings.ToLower(format) {
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	case "html":
+		return FormatHTML, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported report format %q (supported: md, html, json)", format)
+	}
+}
+
+// Summarize computes the summary from the environments and stacks
+func (r *Report) Summarize() {
+	r.Summary = Summary{Environments: len(r.Environments), Stacks: len(r.Stacks)}
+	for _, env := range r.Environments {
+		if env.Status == "Up" {
+			r.Summary.EnvironmentsUp++
+		}
+		r.Summary.Containers += env.Containers.Total
+		r.Summary.Running += env.Containers.Running
+		r.Summary.Unhealthy += env.Containers.Unhealthy
+		r.Summary.StaleImages += len(env.StaleImages)
+	}
+}
+
+// Render writes the report in the given format with the built-in templates
+func Render(w io.Writer, r *Report, format Format) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case FormatMarkdown:
+		tmpl, err := texttemplate.New("report.md.tmpl").Funcs(texttemplate.FuncMap(funcs)).ParseFS(templates, "templates/report.md.tmpl")
+		if err != nil {
+			return fmt.Errorf("failed to parse report template: %w", err)
+		}
+		return execute(tmpl, w, r)
+	case FormatHTML:
+		tmpl, err := htmltemplate.New("report.html.tmpl").Funcs(htmltemplate.FuncMap(funcs)).ParseFS(templates, "templates/report.html.tmpl")
+		if err != nil {
+			return fmt.Errorf("failed to parse report template: %w", err)
+		}
+		return execute(tmpl, w, r)
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+}
+
+// RenderTemplate writes the report with a custom template. HTML templates
+// escape their output; any other template is rendered as plain text.
+func RenderTemplate(w io.Writer, r *Report, text string, html bool) error {
+	if html {
+		tmpl, err := htmltemplate.New("report").Funcs(htmltemplate.FuncMap(funcs)).Parse(text)
+		if err != nil {
+			return fmt.Errorf("failed to parse report template: %w", err)
+		}
+		return execute(tmpl, w, r)
+	}
+
+	tmpl, err := texttemplate.New("report").Funcs(texttemplate.FuncMap(funcs)).Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse report template: %w", err)
+	}
+	return execute(tmpl, w, r)
+}
+
+type template interface {
+	Execute(w io.Writer, data any) error
+}
+
+func execute(tmpl template, w io.Writer, r *Report) error {
+	if err := tmpl.Execute(w, r); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// funcs are the functions available to report templates
+var funcs = map[string]any{
+	"size": output.FormatSize,
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format("2006-01-02")
+	},
+	"datetime": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04 MST")
+	},
+	// cell escapes a value for a Markdown table cell
+	"cell": func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		s = strings.ReplaceAll(s, "|", `\|`)
+		return strings.Join(strings.Fields(s), " ")
+	},
+}
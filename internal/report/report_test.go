@@ -0,0 +1,130 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReport() *Report {
+	r := &Report{
+		Title:       "Weekly report",
+		URL:         "https://portainer.example.com",
+		GeneratedAt: time.Date(2024, 6, 1, 7, 0, 0, 0, time.UTC),
+		StaleAfter:  "30 days",
+		Environments: []Environment{
+			{
+				ID: 1, Name: "local", Type: "Docker (Local)", Status: "Up", Version: "24.0.7",
+				Containers:  Containers{Total: 3, Running: 2, Stopped: 1, Unhealthy: 1},
+				Unhealthy:   []Container{{Name: "db", Image: "postgres:16", Status: "Up 1 minute (unhealthy)"}},
+				StaleImages: []Image{{Repository: "redis", Tag: "6", ID: "abc123", Size: 1024, Created: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}},
+			},
+			{ID: 2, Name: "<edge> | west", Type: "Docker (Edge)", Status: "Down", Error: "connection refused"},
+		},
+		Stacks: []Stack{{ID: 7, Name: "web", Environment: "local", Type: "Compose", Status: "Active"}},
+	}
+	r.Summarize()
+	return r
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, expected := range map[string]Format{"md": FormatMarkdown, "Markdown": FormatMarkdown, "html": FormatHTML, "json": FormatJSON} {
+		format, err := ParseFormat(input)
+		if err != nil || format != expected {
+			t.Errorf("ParseFormat(%q) = %q, %v; expected %q", input, format, err, expected)
+		}
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	s := testReport().Summary
+	if s.Environments != 2 || s.EnvironmentsUp != 1 || s.Containers != 3 || s.Running != 2 ||
+		s.Unhealthy != 1 || s.StaleImages != 1 || s.Stacks != 1 {
+		t.Errorf("unexpected summary: %+v", s)
+	}
+}
+
+func TestRender_Markdown(t *testing.T) {
+	var b strings.Builder
+	if err := Render(&b, testReport(), FormatMarkdown); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := b.String()
+
+	for _, expected := range []string{
+		"# Weekly report\n",
+		"Generated 2024-06-01 07:00 UTC from https://portainer.example.com.",
+		"| 2 | 1 | 3 | 2 | 1 | 1 | 1 |",
+		"| 1 | local | Docker (Local) | Up | 24.0.7 | 3 | 2 | 1 | 1 |",
+		`| 2 | <edge> \| west | Docker (Edge) | Down | - | 0 | 0 | 0 | 0 |`,
+		`> **<edge> | west:** connection refused`,
+		"| db | postgres:16 | Up 1 minute (unhealthy) |",
+		"| redis | 6 | abc123 | 1.0 KB | 2024-03-01 |",
+		"| 7 | web | local | Compose | Active | - |",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("missing %q in:\n%s", expected, out)
+		}
+	}
+}
+
+func TestRender_HTML(t *testing.T) {
+	var b strings.Builder
+	if err := Render(&b, testReport(), FormatHTML); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := b.String()
+
+	if !strings.HasPrefix(out, "<!DOCTYPE html>") {
+		t.Errorf("expected an HTML document, got:\n%s", out)
+	}
+	if !strings.Contains(out, "&lt;edge&gt; | west") {
+		t.Error("expected environment names to be escaped")
+	}
+	if !strings.Contains(out, "<td>db</td><td>postgres:16</td>") {
+		t.Errorf("missing unhealthy container in:\n%s", out)
+	}
+}
+
+func TestRender_JSON(t *testing.T) {
+	var b strings.Builder
+	if err := Render(&b, testReport(), FormatJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded Report
+	if err := json.Unmarshal([]byte(b.String()), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(decoded.Environments) != 2 || decoded.Summary.Unhealthy != 1 {
+		t.Errorf("unexpected report: %+v", decoded)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	text := "{{range .Environments}}{{.Name}}={{.Containers.Total}};{{end}}"
+
+	var b strings.Builder
+	if err := RenderTemplate(&b, testReport(), text, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.String() != "local=3;<edge> | west=0;" {
+		t.Errorf("unexpected output: %q", b.String())
+	}
+
+	b.Reset()
+	if err := RenderTemplate(&b, testReport(), text, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.String() != "local=3;&lt;edge&gt; | west=0;" {
+		t.Errorf("unexpected output: %q", b.String())
+	}
+
+	if err := RenderTemplate(&b, testReport(), "{{.Missing", false); err == nil {
+		t.Error("expected parse error")
+	}
+}
@@ -0,0 +1,266 @@
+// Package runbook reads files describing a sequence of CLI operations and
+// runs them in order, stopping or continuing after a failed step as the
+// file says.
+package runbook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Failure policies of a step
+const (
+	Abort    = "abort"
+	Continue = "continue"
+)
+
+// Step statuses of a report
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+)
+
+// Runbook is a sequence of CLI operations, read from YAML or JSON:
+//
+//	on_failure: abort
+//	steps:
+//	  - name: Stop web
+//	    run: containers stop web --endpoint 1
+//	  - name: Prune images
+//	    args: [images, prune, --endpoint, "1", --force]
+//	    on_failure: continue
+type Runbook struct {
+	// OnFailure is the default policy of the steps, abort when empty
+	OnFailure string `yaml:"on_failure,omitempty" json:"on_failure,omitempty"`
+	Steps     []Step `yaml:"steps" json:"steps"`
+}
+
+// Step is a CLI operation: the arguments of portainer-cli, given as a
+// command line in Run or as a list in Args
+type Step struct {
+	Name      string   `yaml:"name,omitempty" json:"name,omitempty"`
+	Run       string   `yaml:"run,omitempty" json:"run,omitempty"`
+	Args      []string `yaml:"args,omitempty" json:"args,omitempty"`
+	OnFailure string   `yaml:"on_failure,omitempty" json:"on_failure,omitempty"`
+}
+
+// Load reads a runbook file
+func Load(path string) (*Runbook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read runbook: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a runbook. JSON is read as YAML, of which it
+// is a subset.
+func Parse(data []byte) (*Runbook, error) {
+	var runbook Runbook
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&runbook); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("runbook has no steps")
+		}
+		return nil, fmt.Errorf("failed to parse runbook: %w", err)
+	}
+	if err := runbook.Validate(); err != nil {
+		return nil, err
+	}
+	return &runbook, nil
+}
+
+// Validate checks the steps and failure policies of the runbook
+func (r *Runbook) Validate() error {
+	if len(r.Steps) == 0 {
+		return fmt.Errorf("runbook has no steps")
+	}
+	if err := checkPolicy(r.OnFailure); err != nil {
+		return err
+	}
+	for i := range r.Steps {
+		step := &r.Steps[i]
+		if step.Run != "" && len(step.Args) > 0 {
+			return fmt.Errorf("step %s: run and args cannot be used together", step.Label(i))
+		}
+		args, err := step.CommandArgs()
+		if err != nil {
+			return fmt.Errorf("step %s: %w", step.Label(i), err)
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("step %s: run or args is required", step.Label(i))
+		}
+		if err := checkPolicy(step.OnFailure); err != nil {
+			return fmt.Errorf("step %s: %w", step.Label(i), err)
+		}
+	}
+	return nil
+}
+
+func checkPolicy(policy string) error {
+	switch policy {
+	case "", Abort, Continue:
+		return nil
+	}
+	return fmt.Errorf("invalid on_failure '%s': use abort or continue", policy)
+}
+
+// Label names the step i of a runbook in messages: its name, or its number
+func (s *Step) Label(i int) string {
+	if s.Name != "" {
+		return fmt.Sprintf("%d (%s)", i+1, s.Name)
+	}
+	return fmt.Sprint(i + 1)
+}
+
+// CommandArgs returns the arguments of the step. A Run command line is split
+// on spaces, keeping quoted strings together as a shell does.
+func (s *Step) CommandArgs() ([]string, error) {
+	if len(s.Args) > 0 {
+		return s.Args, nil
+	}
+	return splitCommand(s.Run)
+}
+
+// policy returns the failure policy of the step within r
+func (r *Runbook) policy(s *Step) string {
+	if s.OnFailure != "" {
+		return s.OnFailure
+	}
+	if r.OnFailure != "" {
+		return r.OnFailure
+	}
+	return Abort
+}
+
+// StepResult is the outcome of a step
+type StepResult struct {
+	Step     int      `json:"step" yaml:"step"`
+	Name     string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Args     []string `json:"args" yaml:"args"`
+	Status   string   `json:"status" yaml:"status"`
+	Error    string   `json:"error,omitempty" yaml:"error,omitempty"`
+	Duration string   `json:"duration,omitempty" yaml:"duration,omitempty"`
+}
+
+// Report is the outcome of a runbook
+type Report struct {
+	Steps     []StepResult `json:"steps" yaml:"steps"`
+	Succeeded int          `json:"succeeded" yaml:"succeeded"`
+	Failed    int          `json:"failed" yaml:"failed"`
+	Skipped   int          `json:"skipped" yaml:"skipped"`
+	Duration  string       `json:"duration" yaml:"duration"`
+}
+
+// Err returns an error when a step failed
+func (r *Report) Err() error {
+	if r.Failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d steps failed", r.Failed, len(r.Steps))
+}
+
+// Executor runs the arguments of step i of a runbook
+type Executor func(ctx context.Context, i int, args []string) error
+
+// Run executes the steps in order. A failed step with the abort policy, or
+// a cancelled context, skips the remaining steps.
+func (r *Runbook) Run(ctx context.Context, exec Executor) *Report {
+	start := time.Now()
+	report := &Report{Steps: make([]StepResult, 0, len(r.Steps))}
+	aborted := false
+
+	for i := range r.Steps {
+		step := &r.Steps[i]
+		args, _ := step.CommandArgs()
+		result := StepResult{Step: i + 1, Name: step.Name, Args: args}
+
+		if aborted || ctx.Err() != nil {
+			result.Status = StatusSkipped
+			report.Skipped++
+			report.Steps = append(report.Steps, result)
+			continue
+		}
+
+		stepStart := time.Now()
+		err := exec(ctx, i, args)
+		result.Duration = time.Since(stepStart).Round(time.Millisecond).String()
+		if err != nil {
+			result.Status = StatusFailed
+			result.Error = err.Error()
+			report.Failed++
+			aborted = r.policy(step) == Abort
+		} else {
+			result.Status = StatusSucceeded
+			report.Succeeded++
+		}
+		report.Steps = append(report.Steps, result)
+	}
+
+	report.Duration = time.Since(start).Round(time.Millisecond).String()
+	return report
+}
+
+// splitCommand splits a command line into arguments. Single and double
+// quotes group words, and a backslash escapes the next character outside
+// single quotes.
+func splitCommand(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\\':
+			if i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			}
+			inArg = true
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in run: %s", line)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
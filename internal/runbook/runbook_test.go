@@ -0,0 +1,125 @@
+package runbook
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	book, err := Parse([]byte(`
+on_failure: continue
+steps:
+  - name: Stop web
+    run: containers stop "web app" --endpoint 1
+  - args: [images, prune, --endpoint, "1"]
+    on_failure: abort
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(book.Steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(book.Steps))
+	}
+	args, err := book.Steps[0].CommandArgs()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"containers", "stop", "web app", "--endpoint", "1"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+
+	// JSON runbooks
+	if _, err := Parse([]byte(`{"steps": [{"run": "images list --endpoint 1"}]}`)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		runbook  string
+		contains string
+	}{
+		{"empty", ``, "no steps"},
+		{"no steps", `steps: []`, "no steps"},
+		{"unknown key", "steps:\n  - run: images list\n    command: x", "command"},
+		{"missing command", "steps:\n  - name: nothing", "step 1 (nothing): run or args is required"},
+		{"run and args", "steps:\n  - run: images list\n    args: [images]", "cannot be used together"},
+		{"invalid policy", "steps:\n  - run: images list\n    on_failure: retry", "invalid on_failure 'retry'"},
+		{"unterminated quote", "steps:\n  - run: containers stop 'web", "unterminated quote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.runbook))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("expected error containing %q, got %v", tt.contains, err)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	book := &Runbook{Steps: []Step{
+		{Run: "ok"},
+		{Run: "fail", OnFailure: Continue},
+		{Run: "fail"},
+		{Run: "ok"},
+	}}
+
+	var ran []int
+	report := book.Run(context.Background(), func(ctx context.Context, i int, args []string) error {
+		ran = append(ran, i)
+		if args[0] == "fail" {
+			return fmt.Errorf("exit status 1")
+		}
+		return nil
+	})
+
+	if !reflect.DeepEqual(ran, []int{0, 1, 2}) {
+		t.Errorf("expected steps 0-2 to run, got %v", ran)
+	}
+	statuses := make([]string, 0, len(report.Steps))
+	for _, step := range report.Steps {
+		statuses = append(statuses, step.Status)
+	}
+	expected := []string{StatusSucceeded, StatusFailed, StatusFailed, StatusSkipped}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("expected %v, got %v", expected, statuses)
+	}
+	if report.Succeeded != 1 || report.Failed != 2 || report.Skipped != 1 {
+		t.Errorf("unexpected counts: %+v", report)
+	}
+	if err := report.Err(); err == nil || err.Error() != "2 of 4 steps failed" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"images list", []string{"images", "list"}},
+		{"  a   b ", []string{"a", "b"}},
+		{`exec web -- sh -c "echo 'hi there'"`, []string{"exec", "web", "--", "sh", "-c", "echo 'hi there'"}},
+		{`a\ b ''`, []string{"a b", ""}},
+	}
+
+	for _, tt := range tests {
+		args, err := splitCommand(tt.line)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(args, tt.expected) {
+			t.Errorf("splitCommand(%q): expected %q, got %q", tt.line, tt.expected, args)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// AWS services holding secrets
+const (
+	ServiceSSM            = "ssm"
+	ServiceSecretsManager = "secretsmanager"
+)
+
+// AWS reads SSM parameters (decrypting SecureString parameters) and Secrets
+// Manager secrets with the aws CLI. A field selects a key of a secret that
+// holds a JSON object.
+type AWS struct {
+	Service string
+	// Run runs a command and returns its standard output; it defaults to
+	// running the command with os/exec
+	Run func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+func (a *AWS) Resolve(ctx context.Context, ref Reference) (string, error) {
+	var args []string
+	switch a.Service {
+	case ServiceSSM:
+		args = []string{"ssm", "get-parameter", "--name", ref.Path, "--with-decryption",
+			"--query", "Parameter.Value", "--output", "text"}
+	case ServiceSecretsManager:
+		args = []string{"secretsmanager", "get-secret-value", "--secret-id", ref.Path,
+			"--query", "SecretString", "--output", "text"}
+	default:
+		return "", fmt.Errorf("unsupported AWS service %q", a.Service)
+	}
+
+	run := a.Run
+	if run == nil {
+		run = runCommand
+	}
+	out, err := run(ctx, "aws", args...)
+	if err != nil {
+		return "", err
+	}
+
+	// --output text ends the value with a newline
+	secret := strings.TrimSuffix(string(out), "\n")
+	if ref.Field == "" {
+		return secret, nil
+	}
+	return jsonField(secret, ref.Field)
+}
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("%s not found on PATH", name)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s", name, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, nil
+}
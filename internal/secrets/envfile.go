@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvFile reads a variable of a dotenv file: envfile://.env.production#DB_PASSWORD.
+// Lines are KEY=VALUE, optionally prefixed with export; blank lines and
+// lines starting with # are ignored, and quoted values are unquoted.
+type EnvFile struct{}
+
+func (EnvFile) Resolve(_ context.Context, ref Reference) (string, error) {
+	if ref.Field == "" {
+		return "", fmt.Errorf("select a variable of the env file with #KEY")
+	}
+
+	f, err := os.Open(ref.Path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return "", fmt.Errorf("%s:%d: expected KEY=VALUE", ref.Path, lineNo)
+		}
+		if strings.TrimSpace(name) != ref.Field {
+			continue
+		}
+		return unquote(strings.TrimSpace(value)), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s has no variable %s", ref.Path, ref.Field)
+}
+
+func unquote(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+	switch value[0] {
+	case '"':
+		if s, err := strconv.Unquote(value); err == nil {
+			return s
+		}
+	case '\'':
+		if value[len(value)-1] == '\'' {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
@@ -0,0 +1,122 @@
+// Package secrets resolves references to secrets kept in external secret
+// managers, such as vault://secret/data/web#password, so their values are
+// only known at deploy time and never written to stack files or typed on
+// the command line.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Reference points at a secret: scheme://path#field. Field selects a key of
+// secrets that hold several values, such as a JSON object.
+type Reference struct {
+	Scheme string
+	Path   string
+	Field  string
+}
+
+func (r Reference) String() string {
+	s := r.Scheme + "://" + r.Path
+	if r.Field != "" {
+		s += "#" + r.Field
+	}
+	return s
+}
+
+// Resolver looks up the value of a reference in one secret manager
+type Resolver interface {
+	Resolve(ctx context.Context, ref Reference) (string, error)
+}
+
+// Resolvers maps reference schemes to the resolver handling them
+type Resolvers map[string]Resolver
+
+// Default returns the resolvers of the built-in schemes:
+//   - vault://path#field, a HashiCorp Vault secret read with VAULT_ADDR and
+//     VAULT_TOKEN (or ~/.vault-token)
+//   - ssm://name[#field], an AWS Systems Manager parameter
+//   - awssm://secret-id[#field], an AWS Secrets Manager secret
+//   - envfile://path#KEY, a variable of a dotenv file
+//
+// The AWS schemes run the aws CLI, so its usual credentials and region
+// settings apply.
+func Default() Resolvers {
+	return Resolvers{
+		"vault":   NewVault(),
+		"ssm":     &AWS{Service: ServiceSSM},
+		"awssm":   &AWS{Service: ServiceSecretsManager},
+		"envfile": EnvFile{},
+	}
+}
+
+// Schemes returns the schemes of r in alphabetical order
+func (r Resolvers) Schemes() []string {
+	schemes := make([]string, 0, len(r))
+	for scheme := range r {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Parse returns the reference in value when it starts with one of the
+// schemes of r. Other values, including URLs of unknown schemes such as
+// https://, are not references.
+func (r Resolvers) Parse(value string) (Reference, bool) {
+	scheme, rest, ok := strings.Cut(value, "://")
+	if !ok {
+		return Reference{}, false
+	}
+	if _, known := r[scheme]; !known {
+		return Reference{}, false
+	}
+	path, field, _ := strings.Cut(rest, "#")
+	return Reference{Scheme: scheme, Path: path, Field: field}, true
+}
+
+// Resolve returns the secret value of a reference, and value unchanged when
+// it is not a reference
+func (r Resolvers) Resolve(ctx context.Context, value string) (string, error) {
+	ref, ok := r.Parse(value)
+	if !ok {
+		return value, nil
+	}
+	if ref.Path == "" {
+		return "", fmt.Errorf("invalid secret reference %s: missing path", ref)
+	}
+	secret, err := r[ref.Scheme].Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return secret, nil
+}
+
+// jsonField returns field of a secret holding a JSON object. String values
+// are returned as-is, other values in their JSON form.
+func jsonField(secret, field string) (string, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &object); err != nil {
+		return "", fmt.Errorf("field %q requested, but the secret is not a JSON object", field)
+	}
+	return objectField(object, field)
+}
+
+func objectField(object map[string]interface{}, field string) (string, error) {
+	value, ok := object[field]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
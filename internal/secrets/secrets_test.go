@@ -0,0 +1,135 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolvers_Parse(t *testing.T) {
+	resolvers := Default()
+
+	tests := []struct {
+		value    string
+		expected Reference
+		ok       bool
+	}{
+		{"vault://secret/data/web#password", Reference{Scheme: "vault", Path: "secret/data/web", Field: "password"}, true},
+		{"ssm:///prod/db/password", Reference{Scheme: "ssm", Path: "/prod/db/password"}, true},
+		{"awssm://prod/web#api_key", Reference{Scheme: "awssm", Path: "prod/web", Field: "api_key"}, true},
+		{"envfile://.env#TOKEN", Reference{Scheme: "envfile", Path: ".env", Field: "TOKEN"}, true},
+		{"https://example.com/#anchor", Reference{}, false},
+		{"plain value", Reference{}, false},
+	}
+
+	for _, tt := range tests {
+		ref, ok := resolvers.Parse(tt.value)
+		if ok != tt.ok || ref != tt.expected {
+			t.Errorf("Parse(%q) = %+v, %v; expected %+v, %v", tt.value, ref, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestResolvers_ResolvePassesValuesThrough(t *testing.T) {
+	value, err := Default().Resolve(context.Background(), "postgres://db:5432")
+	if err != nil || value != "postgres://db:5432" {
+		t.Errorf("expected the value unchanged, got %q (%v)", value, err)
+	}
+}
+
+func TestVault_Resolve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/web":
+			w.Write([]byte(`{"data":{"data":{"password":"hunter2","port":5432},"metadata":{"version":3}}}`))
+		case "/v1/kv/web":
+			w.Write([]byte(`{"data":{"password":"v1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	resolvers := Resolvers{"vault": &Vault{Addr: server.URL, Token: "s.token"}}
+	tests := map[string]string{
+		"vault://secret/data/web#password": "hunter2",
+		"vault://secret/data/web#port":     "5432",
+		"vault://kv/web#password":          "v1-secret",
+	}
+	for ref, expected := range tests {
+		value, err := resolvers.Resolve(context.Background(), ref)
+		if err != nil || value != expected {
+			t.Errorf("%s: expected %q, got %q (%v)", ref, expected, value, err)
+		}
+	}
+
+	if _, err := resolvers.Resolve(context.Background(), "vault://secret/data/web#missing"); err == nil {
+		t.Error("expected an error for a missing field")
+	}
+	if _, err := resolvers.Resolve(context.Background(), "vault://secret/data/other#password"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected a 404 error, got %v", err)
+	}
+}
+
+func TestAWS_Resolve(t *testing.T) {
+	var calls [][]string
+	run := func(_ context.Context, name string, args ...string) ([]byte, error) {
+		calls = append(calls, append([]string{name}, args...))
+		if args[0] == "ssm" {
+			return []byte("s3cret\n"), nil
+		}
+		return []byte(`{"username":"web","password":"pa55"}` + "\n"), nil
+	}
+
+	resolvers := Resolvers{
+		"ssm":   &AWS{Service: ServiceSSM, Run: run},
+		"awssm": &AWS{Service: ServiceSecretsManager, Run: run},
+	}
+
+	value, err := resolvers.Resolve(context.Background(), "ssm:///prod/db/password")
+	if err != nil || value != "s3cret" {
+		t.Errorf("expected s3cret, got %q (%v)", value, err)
+	}
+	value, err = resolvers.Resolve(context.Background(), "awssm://prod/web#password")
+	if err != nil || value != "pa55" {
+		t.Errorf("expected pa55, got %q (%v)", value, err)
+	}
+
+	expected := [][]string{
+		{"aws", "ssm", "get-parameter", "--name", "/prod/db/password", "--with-decryption", "--query", "Parameter.Value", "--output", "text"},
+		{"aws", "secretsmanager", "get-secret-value", "--secret-id", "prod/web", "--query", "SecretString", "--output", "text"},
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("unexpected aws calls: %v", calls)
+	}
+}
+
+func TestEnvFile_Resolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := "# database\nexport DB_USER=web\nDB_PASSWORD=\"p a#ss\"\nAPI_KEY='abc'\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	resolvers := Resolvers{"envfile": EnvFile{}}
+	tests := map[string]string{"DB_USER": "web", "DB_PASSWORD": "p a#ss", "API_KEY": "abc"}
+	for key, expected := range tests {
+		value, err := resolvers.Resolve(context.Background(), "envfile://"+path+"#"+key)
+		if err != nil || value != expected {
+			t.Errorf("%s: expected %q, got %q (%v)", key, expected, value, err)
+		}
+	}
+
+	if _, err := resolvers.Resolve(context.Background(), "envfile://"+path+"#MISSING"); err == nil {
+		t.Error("expected an error for a missing variable")
+	}
+}
@@ -0,0 +1,109 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// vaultTimeout bounds a single Vault request
+const vaultTimeout = 30 * time.Second
+
+// Vault reads secrets from the HTTP API of HashiCorp Vault. The path is the
+// API path below /v1, so secrets of a KV version 2 engine include data/:
+// vault://secret/data/web#password.
+type Vault struct {
+	// Addr, Token and Namespace default to VAULT_ADDR, VAULT_TOKEN (or the
+	// ~/.vault-token file of vault login) and VAULT_NAMESPACE
+	Addr      string
+	Token     string
+	Namespace string
+	Client    *http.Client
+}
+
+// NewVault creates a Vault resolver configured from the environment
+func NewVault() *Vault {
+	return &Vault{
+		Addr:      os.Getenv("VAULT_ADDR"),
+		Token:     vaultToken(),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+		Client:    &http.Client{Timeout: vaultTimeout},
+	}
+}
+
+func vaultToken() string {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func (v *Vault) Resolve(ctx context.Context, ref Reference) (string, error) {
+	if v.Addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	if v.Token == "" {
+		return "", fmt.Errorf("no Vault token: set VAULT_TOKEN or run vault login")
+	}
+	if ref.Field == "" {
+		return "", fmt.Errorf("Vault secrets hold several fields; select one with #field")
+	}
+
+	url := strings.TrimSuffix(v.Addr, "/") + "/v1/" + strings.TrimPrefix(ref.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Vault returned %s", resp.Status)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("failed to decode Vault response: %w", err)
+	}
+
+	data := secret.Data
+	// KV version 2 nests the secret in data.data next to data.metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	return objectField(data, ref.Field)
+}
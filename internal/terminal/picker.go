@@ -0,0 +1,219 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrCancelled is returned by Pick when the user aborts the selection
+var ErrCancelled = errors.New("selection cancelled")
+
+// maxPickerRows is the number of matching items shown at once
+const maxPickerRows = 10
+
+type pickerKey int
+
+const (
+	keyRune pickerKey = iota
+	keyUp
+	keyDown
+	keyEnter
+	keyBackspace
+	keyCancel
+	keyIgnore
+)
+
+// FuzzyMatch reports whether all characters of pattern appear in s in order,
+// ignoring case
+func FuzzyMatch(pattern, s string) bool {
+	s = strings.ToLower(s)
+	for _, r := range strings.ToLower(pattern) {
+		idx := strings.IndexRune(s, r)
+		if idx < 0 {
+			return false
+		}
+		s = s[idx+utf8.RuneLen(r):]
+	}
+	return true
+}
+
+type picker struct {
+	items    []string
+	query    string
+	matches  []int
+	selected int
+	offset   int
+}
+
+func newPicker(items []string, initial int) *picker {
+	p := &picker{items: items}
+	p.filter()
+	if initial >= 0 && initial < len(items) {
+		p.selected = initial
+		p.scroll()
+	}
+	return p
+}
+
+func (p *picker) filter() {
+	p.matches = p.matches[:0]
+	for i, item := range p.items {
+		if FuzzyMatch(p.query, item) {
+			p.matches = append(p.matches, i)
+		}
+	}
+	p.selected = 0
+	p.offset = 0
+}
+
+func (p *picker) scroll() {
+	if p.selected < p.offset {
+		p.offset = p.selected
+	}
+	if p.selected >= p.offset+maxPickerRows {
+		p.offset = p.selected - maxPickerRows + 1
+	}
+}
+
+// handle applies a key press. It returns the chosen item index once the
+// selection is confirmed, or ErrCancelled.
+func (p *picker) handle(key pickerKey, r rune) (int, bool, error) {
+	switch key {
+	case keyRune:
+		p.query += string(r)
+		p.filter()
+	case keyBackspace:
+		if p.query != "" {
+			_, size := utf8.DecodeLastRuneInString(p.query)
+			p.query = p.query[:len(p.query)-size]
+			p.filter()
+		}
+	case keyUp:
+		if p.selected > 0 {
+			p.selected--
+			p.scroll()
+		}
+	case keyDown:
+		if p.selected < len(p.matches)-1 {
+			p.selected++
+			p.scroll()
+		}
+	case keyEnter:
+		if len(p.matches) > 0 {
+			return p.matches[p.selected], true, nil
+		}
+	case keyCancel:
+		return -1, true, ErrCancelled
+	}
+	return -1, false, nil
+}
+
+// render draws the prompt and visible matches and returns the number of
+// lines written below the prompt line
+func (p *picker) render(w io.Writer, prompt string) int {
+	fmt.Fprintf(w, "\r\x1b[J%s%s\r\n", prompt, p.query)
+
+	end := p.offset + maxPickerRows
+	if end > len(p.matches) {
+		end = len(p.matches)
+	}
+
+	lines := 0
+	for i := p.offset; i < end; i++ {
+		marker := "  "
+		if i == p.selected {
+			marker = "> "
+		}
+		fmt.Fprintf(w, "%s%s\r\n", marker, p.items[p.matches[i]])
+		lines++
+	}
+	if len(p.matches) == 0 {
+		fmt.Fprint(w, "  (no matches)\r\n")
+		lines++
+	}
+	return lines + 1
+}
+
+// decodeKey maps raw terminal input to a key press
+func decodeKey(buf []byte) (pickerKey, rune) {
+	switch {
+	case len(buf) == 0:
+		return keyIgnore, 0
+	case buf[0] == 0x1b:
+		if len(buf) == 1 {
+			return keyCancel, 0
+		}
+		if len(buf) >= 3 && (buf[1] == '[' || buf[1] == 'O') {
+			switch buf[2] {
+			case 'A':
+				return keyUp, 0
+			case 'B':
+				return keyDown, 0
+			}
+		}
+		return keyIgnore, 0
+	case buf[0] == '\r' || buf[0] == '\n':
+		return keyEnter, 0
+	case buf[0] == 0x7f || buf[0] == 0x08:
+		return keyBackspace, 0
+	case buf[0] == 0x03 || buf[0] == 0x04:
+		return keyCancel, 0
+	case buf[0] == 0x10 || buf[0] == 0x0b:
+		// ctrl-p, ctrl-k
+		return keyUp, 0
+	case buf[0] == 0x0e:
+		// ctrl-n
+		return keyDown, 0
+	}
+
+	r, _ := utf8.DecodeRune(buf)
+	if r == utf8.RuneError || !unicode.IsPrint(r) {
+		return keyIgnore, 0
+	}
+	return keyRune, r
+}
+
+// Pick shows an interactive, fuzzy-searchable list of items on the terminal
+// and returns the index of the chosen item. initial is the index selected
+// when the list is first shown. Typing filters the list, the arrow keys (or
+// ctrl-p/ctrl-n) move the selection, enter confirms and esc or ctrl-c
+// cancels with ErrCancelled.
+func Pick(in *os.File, out io.Writer, prompt string, items []string, initial int) (int, error) {
+	if len(items) == 0 {
+		return -1, errors.New("nothing to choose from")
+	}
+
+	state, err := MakeRaw(in)
+	if err != nil {
+		return -1, err
+	}
+	defer func() { _ = state.Restore() }()
+
+	p := newPicker(items, initial)
+	lines := p.render(out, prompt)
+	erase := func() {
+		fmt.Fprintf(out, "\x1b[%dA\r\x1b[J", lines)
+	}
+
+	buf := make([]byte, 16)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			erase()
+			return -1, fmt.Errorf("failed to read input: %w", err)
+		}
+
+		key, r := decodeKey(buf[:n])
+		index, done, err := p.handle(key, r)
+		erase()
+		if done {
+			return index, err
+		}
+		lines = p.render(out, prompt)
+	}
+}
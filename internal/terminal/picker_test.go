@@ -0,0 +1,117 @@
+package terminal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{"", "production", true},
+		{"prod", "production", true},
+		{"pdn", "production", true},
+		{"PRD", "production", true},
+		{"stg", "staging", true},
+		{"gts", "staging", false},
+		{"prodx", "production", false},
+	}
+
+	for _, tt := range tests {
+		if got := FuzzyMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("FuzzyMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeKey(t *testing.T) {
+	tests := []struct {
+		input string
+		key   pickerKey
+		r     rune
+	}{
+		{"a", keyRune, 'a'},
+		{"\r", keyEnter, 0},
+		{"\x7f", keyBackspace, 0},
+		{"\x1b", keyCancel, 0},
+		{"\x03", keyCancel, 0},
+		{"\x1b[A", keyUp, 0},
+		{"\x1b[B", keyDown, 0},
+		{"\x10", keyUp, 0},
+		{"\x0e", keyDown, 0},
+		{"\x1b[C", keyIgnore, 0},
+	}
+
+	for _, tt := range tests {
+		key, r := decodeKey([]byte(tt.input))
+		if key != tt.key || r != tt.r {
+			t.Errorf("decodeKey(%q) = %v %q, want %v %q", tt.input, key, r, tt.key, tt.r)
+		}
+	}
+}
+
+func TestPicker(t *testing.T) {
+	items := []string{"production", "staging", "dev"}
+
+	t.Run("starts at initial item", func(t *testing.T) {
+		p := newPicker(items, 1)
+		index, done, err := p.handle(keyEnter, 0)
+		if !done || err != nil || index != 1 {
+			t.Errorf("expected staging to be chosen, got %d %v %v", index, done, err)
+		}
+	})
+
+	t.Run("filters and navigates", func(t *testing.T) {
+		p := newPicker(items, 0)
+		for _, r := range "d" {
+			p.handle(keyRune, r)
+		}
+		if len(p.matches) != 2 {
+			t.Fatalf("expected 2 matches, got %d", len(p.matches))
+		}
+		p.handle(keyDown, 0)
+		p.handle(keyDown, 0)
+		index, _, _ := p.handle(keyEnter, 0)
+		if index != 2 {
+			t.Errorf("expected dev to be chosen, got %d", index)
+		}
+	})
+
+	t.Run("backspace widens the filter", func(t *testing.T) {
+		p := newPicker(items, 0)
+		p.handle(keyRune, 'x')
+		if len(p.matches) != 0 {
+			t.Fatalf("expected no matches, got %d", len(p.matches))
+		}
+		if _, done, _ := p.handle(keyEnter, 0); done {
+			t.Error("enter without matches should not finish")
+		}
+		p.handle(keyBackspace, 0)
+		if len(p.matches) != len(items) {
+			t.Errorf("expected all items to match, got %d", len(p.matches))
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		p := newPicker(items, 0)
+		if _, done, err := p.handle(keyCancel, 0); !done || err != ErrCancelled {
+			t.Errorf("expected ErrCancelled, got %v", err)
+		}
+	})
+
+	t.Run("render marks selection", func(t *testing.T) {
+		p := newPicker(items, 1)
+		var buf bytes.Buffer
+		lines := p.render(&buf, "profile: ")
+		if lines != len(items)+1 {
+			t.Errorf("expected %d lines, got %d", len(items)+1, lines)
+		}
+		if !strings.Contains(buf.String(), "> staging") {
+			t.Errorf("selected item not marked: %q", buf.String())
+		}
+	})
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package terminal
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// NotifyResize sends on ch whenever the terminal window is resized. The
+// returned function stops the notifications.
+func NotifyResize(ch chan<- os.Signal) func() {
+	signal.Notify(ch, syscall.SIGWINCH)
+	return func() { signal.Stop(ch) }
+}
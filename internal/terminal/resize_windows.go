@@ -0,0 +1,12 @@
+//go:build windows
+
+package terminal
+
+import (
+	"os"
+)
+
+// NotifyResize is a no-op on Windows, which has no resize signal
+func NotifyResize(ch chan<- os.Signal) func() {
+	return func() {}
+}
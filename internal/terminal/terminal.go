@@ -0,0 +1,161 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// DefaultDetachKeys is the key sequence used to detach from an interactive
+// session when none is configured, matching the Docker CLI.
+const DefaultDetachKeys = "ctrl-p,ctrl-q"
+
+// ErrDetached is returned by a DetachReader once the detach sequence is read
+var ErrDetached = errors.New("detached from container")
+
+// State holds the terminal state to restore after raw mode
+type State struct {
+	fd    int
+	state *term.State
+}
+
+// IsTerminal reports whether the given file is attached to a terminal
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// MakeRaw puts the terminal connected to f into raw mode
+func MakeRaw(f *os.File) (*State, error) {
+	fd := int(f.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set raw terminal mode: %w", err)
+	}
+	return &State{fd: fd, state: state}, nil
+}
+
+// Restore returns the terminal to the state saved by MakeRaw
+func (s *State) Restore() error {
+	if s == nil {
+		return nil
+	}
+	return term.Restore(s.fd, s.state)
+}
+
+// Size returns the height and width of the terminal connected to f
+func Size(f *os.File) (int, int, error) {
+	width, height, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0, 0, err
+	}
+	return height, width, nil
+}
+
+// ParseDetachKeys converts a comma-separated key description such as
+// "ctrl-p,ctrl-q" into the byte sequence to match on stdin
+func ParseDetachKeys(keys string) ([]byte, error) {
+	if keys == "" {
+		keys = DefaultDetachKeys
+	}
+
+	var seq []byte
+	for _, key := range strings.Split(keys, ",") {
+		key = strings.TrimSpace(key)
+		lower := strings.ToLower(key)
+
+		switch {
+		case len(key) == 1:
+			seq = append(seq, key[0])
+		case strings.HasPrefix(lower, "ctrl-") && len(lower) == 6:
+			c := lower[5]
+			switch {
+			case c >= 'a' && c <= 'z':
+				seq = append(seq, c-'a'+1)
+			case c == '@':
+				seq = append(seq, 0)
+			case c == '[':
+				seq = append(seq, 27)
+			case c == '\\':
+				seq = append(seq, 28)
+			case c == ']':
+				seq = append(seq, 29)
+			case c == '^':
+				seq = append(seq, 30)
+			case c == '_':
+				seq = append(seq, 31)
+			default:
+				return nil, fmt.Errorf("invalid detach key: %s", key)
+			}
+		default:
+			return nil, fmt.Errorf("invalid detach key: %s", key)
+		}
+	}
+
+	return seq, nil
+}
+
+// DetachReader passes input through until the detach sequence is read,
+// after which it returns ErrDetached. Partial matches that are not
+// completed are forwarded unchanged.
+type DetachReader struct {
+	r        io.Reader
+	keys     []byte
+	matched  int
+	pending  []byte
+	detached bool
+}
+
+// NewDetachReader wraps r so that reading keys ends the stream
+func NewDetachReader(r io.Reader, keys []byte) *DetachReader {
+	return &DetachReader{r: r, keys: keys}
+}
+
+func (d *DetachReader) Read(p []byte) (int, error) {
+	for {
+		if len(d.pending) > 0 {
+			n := copy(p, d.pending)
+			d.pending = d.pending[n:]
+			return n, nil
+		}
+		if d.detached {
+			return 0, ErrDetached
+		}
+		if len(d.keys) == 0 {
+			return d.r.Read(p)
+		}
+
+		buf := make([]byte, len(p))
+		n, err := d.r.Read(buf)
+		for _, b := range buf[:n] {
+			if b == d.keys[d.matched] {
+				d.matched++
+				if d.matched == len(d.keys) {
+					d.detached = true
+					break
+				}
+				continue
+			}
+			if d.matched > 0 {
+				d.pending = append(d.pending, d.keys[:d.matched]...)
+				d.matched = 0
+				if b == d.keys[0] {
+					d.matched = 1
+					continue
+				}
+			}
+			d.pending = append(d.pending, b)
+		}
+
+		if err != nil && d.matched > 0 && !d.detached {
+			d.pending = append(d.pending, d.keys[:d.matched]...)
+			d.matched = 0
+		}
+		if err != nil && len(d.pending) == 0 && !d.detached {
+			return 0, err
+		}
+	}
+}
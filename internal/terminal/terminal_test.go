@@ -0,0 +1,98 @@
+package terminal
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParseDetachKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    string
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "default",
+			keys: "",
+			want: []byte{16, 17},
+		},
+		{
+			name: "ctrl and plain keys",
+			keys: "ctrl-a,x",
+			want: []byte{1, 'x'},
+		},
+		{
+			name: "ctrl bracket",
+			keys: "ctrl-[",
+			want: []byte{27},
+		},
+		{
+			name:    "invalid key",
+			keys:    "ctrl-1",
+			wantErr: true,
+		},
+		{
+			name:    "multi-character key",
+			keys:    "abc",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDetachKeys(tt.keys)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDetachReader(t *testing.T) {
+	keys := []byte{16, 17}
+
+	t.Run("passes input through", func(t *testing.T) {
+		r := NewDetachReader(strings.NewReader("hello"), keys)
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(data) != "hello" {
+			t.Errorf("expected 'hello', got '%s'", data)
+		}
+	})
+
+	t.Run("stops at detach sequence", func(t *testing.T) {
+		r := NewDetachReader(strings.NewReader("ab\x10\x11cd"), keys)
+		data, err := io.ReadAll(r)
+		if err != ErrDetached {
+			t.Fatalf("expected ErrDetached, got %v", err)
+		}
+		if string(data) != "ab" {
+			t.Errorf("expected 'ab', got '%q'", data)
+		}
+	})
+
+	t.Run("forwards partial sequence", func(t *testing.T) {
+		r := NewDetachReader(strings.NewReader("a\x10b"), keys)
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(data) != "a\x10b" {
+			t.Errorf("expected 'a\\x10b', got '%q'", data)
+		}
+	})
+}
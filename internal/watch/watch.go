@@ -1,18 +1,30 @@
 package watch
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
-	"runtime"
+	"strings"
 	"time"
 )
 
+const (
+	// clearSequence moves the cursor home and clears the screen
+	clearSequence = "\033[H\033[2J"
+
+	highlightStart = "\033[7m"
+	highlightEnd   = "\033[0m"
+)
+
 // Options configures the watch behavior
 type Options struct {
 	Interval time.Duration
 	Clear    bool
+	// Diff highlights the table cells that changed since the previous redraw
+	Diff bool
 }
 
 // DefaultOptions returns the default watch options
@@ -23,15 +35,15 @@ func DefaultOptions() Options {
 	}
 }
 
-// Watch executes a function repeatedly at the specified interval
-// It clears the screen between executions if Clear is true
+// Watch executes a function repeatedly at the specified interval.
+// The output of fn is captured and only redrawn when it changes; the screen
+// is cleared between redraws if Clear is true.
 // Returns when the context is cancelled or the function returns an error
 func Watch(ctx context.Context, opts Options, fn func() error) error {
+	w := &watcher{opts: opts, out: os.Stdout}
+
 	// Execute immediately
-	if opts.Clear {
-		clearScreen()
-	}
-	if err := fn(); err != nil {
+	if err := w.run(fn, false); err != nil {
 		return err
 	}
 
@@ -43,31 +55,120 @@ func Watch(ctx context.Context, opts Options, fn func() error) error {
 		case <-ctx.Done():
 			return nil
 		case <-ticker.C:
-			if opts.Clear {
-				clearScreen()
-			}
-			fmt.Printf("\n[Last update: %s]\n\n", time.Now().Format("15:04:05"))
-			if err := fn(); err != nil {
+			if err := w.run(fn, true); err != nil {
 				return err
 			}
 		}
 	}
 }
 
-// clearScreen clears the terminal screen
-func clearScreen() {
-	var cmd *exec.Cmd
+type watcher struct {
+	opts     Options
+	out      io.Writer
+	lastHash [sha256.Size]byte
+	last     string
+	drawn    bool
+}
 
-	switch runtime.GOOS {
-	case "windows":
-		cmd = exec.Command("cmd", "/c", "cls")
-	default:
-		cmd = exec.Command("clear")
+func (w *watcher) run(fn func() error, showTime bool) error {
+	rendered, err := capture(fn)
+	if err != nil {
+		// Show whatever was produced before the failure
+		fmt.Fprint(w.out, rendered)
+		return err
 	}
+	w.render(rendered, showTime)
+	return nil
+}
 
-	cmd.Stdout = os.Stdout
-	if err := cmd.Run(); err != nil {
-		// Silently ignore errors - clearing screen is not critical
+// render writes rendered to the output unless it is identical to the
+// previous redraw
+func (w *watcher) render(rendered string, showTime bool) {
+	hash := sha256.Sum256([]byte(rendered))
+	if w.drawn && hash == w.lastHash {
 		return
 	}
+
+	display := rendered
+	if w.opts.Diff && w.drawn {
+		display = highlightChanges(w.last, rendered)
+	}
+
+	if w.opts.Clear {
+		fmt.Fprint(w.out, clearSequence)
+	}
+	if showTime {
+		fmt.Fprintf(w.out, "\n[Last update: %s]\n\n", time.Now().Format("15:04:05"))
+	}
+	fmt.Fprint(w.out, display)
+
+	w.lastHash = hash
+	w.last = rendered
+	w.drawn = true
+}
+
+// capture runs fn with os.Stdout redirected and returns what it printed
+func capture(fn func() error) (string, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to capture output: %w", err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = writer
+
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, reader)
+		done <- buf.String()
+	}()
+
+	fnErr := fn()
+
+	os.Stdout = stdout
+	writer.Close()
+	rendered := <-done
+	reader.Close()
+
+	return rendered, fnErr
+}
+
+// highlightChanges marks the cells of current that differ from the cell at
+// the same row and column in previous. Table output separates cells with
+// tabs, so other output is compared line by line.
+func highlightChanges(previous, current string) string {
+	oldLines := strings.Split(previous, "\n")
+	lines := strings.Split(current, "\n")
+
+	for i, line := range lines {
+		var oldCells []string
+		if i < len(oldLines) {
+			if oldLines[i] == line {
+				continue
+			}
+			oldCells = strings.Split(oldLines[i], "\t")
+		}
+
+		cells := strings.Split(line, "\t")
+		for j, cell := range cells {
+			if j < len(oldCells) && oldCells[j] == cell {
+				continue
+			}
+			cells[j] = highlight(cell)
+		}
+		lines[i] = strings.Join(cells, "\t")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// highlight renders the visible part of a cell in reverse video, leaving
+// trailing padding untouched so columns stay aligned
+func highlight(cell string) string {
+	trimmed := strings.TrimRight(cell, " ")
+	if trimmed == "" {
+		return cell
+	}
+	return highlightStart + trimmed + highlightEnd + cell[len(trimmed):]
 }
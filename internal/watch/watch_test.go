@@ -0,0 +1,53 @@
+package watch
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHighlightChanges(t *testing.T) {
+	previous := "ID\tSTATUS\nabc\tUp 1 minute\n"
+	current := "ID\tSTATUS\nabc\tExited (0)\ndef\tUp 1 second\n"
+
+	got := highlightChanges(previous, current)
+	want := "ID\tSTATUS\n" +
+		"abc\t" + highlightStart + "Exited (0)" + highlightEnd + "\n" +
+		highlightStart + "def" + highlightEnd + "\t" + highlightStart + "Up 1 second" + highlightEnd + "\n"
+
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestHighlight_KeepsPadding(t *testing.T) {
+	got := highlight("abc  ")
+	want := highlightStart + "abc" + highlightEnd + "  "
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestWatcher_SkipsUnchangedOutput(t *testing.T) {
+	var buf bytes.Buffer
+	w := &watcher{opts: Options{Clear: true}, out: &buf}
+
+	outputs := []string{"one\n", "one\n", "two\n"}
+	for _, out := range outputs {
+		out := out
+		if err := w.run(func() error {
+			fmt.Print(out)
+			return nil
+		}, false); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if n := strings.Count(buf.String(), clearSequence); n != 2 {
+		t.Errorf("expected 2 redraws, got %d", n)
+	}
+	if !strings.HasSuffix(buf.String(), "two\n") {
+		t.Errorf("expected last redraw to show new output, got %q", buf.String())
+	}
+}
@@ -0,0 +1,49 @@
+package portainer
+
+import "strconv"
+
+// AccessPolicies maps user or team IDs to their access to an environment or
+// environment group
+type AccessPolicies map[string]AccessPolicy
+
+// AccessPolicy grants access with an RBAC role. RoleId is 0 on Community
+// Edition, which has no roles.
+type AccessPolicy struct {
+	RoleId int `json:"RoleId"`
+}
+
+// AccessUpdate changes the access policies of an environment or environment
+// group. Users and Teams map user and team IDs to their new policy; a nil
+// policy revokes the access. Users and teams not listed keep their access.
+type AccessUpdate struct {
+	Users map[int]*AccessPolicy
+	Teams map[int]*AccessPolicy
+}
+
+// payload returns the update request body for the current policies. Only
+// the changed policy maps are sent, so the other one is left alone.
+func (u AccessUpdate) payload(users, teams AccessPolicies) map[string]interface{} {
+	payload := map[string]interface{}{}
+	if len(u.Users) > 0 {
+		payload["UserAccessPolicies"] = applyAccessChanges(users, u.Users)
+	}
+	if len(u.Teams) > 0 {
+		payload["TeamAccessPolicies"] = applyAccessChanges(teams, u.Teams)
+	}
+	return payload
+}
+
+func applyAccessChanges(current AccessPolicies, changes map[int]*AccessPolicy) AccessPolicies {
+	policies := AccessPolicies{}
+	for id, policy := range current {
+		policies[id] = policy
+	}
+	for id, policy := range changes {
+		if policy == nil {
+			delete(policies, strconv.Itoa(id))
+			continue
+		}
+		policies[strconv.Itoa(id)] = *policy
+	}
+	return policies
+}
@@ -0,0 +1,53 @@
+package portainer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestActivityService_ListUserActivity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/useractivity/logs" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("after") != "1700000000" || query.Get("keyword") != "alice" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+
+		offset, _ := strconv.Atoi(query.Get("offset"))
+		logs := []map[string]interface{}{}
+		// Two pages of 100 and 50 entries
+		for i := offset; i < min(offset+activityPageSize, 150); i++ {
+			logs = append(logs, map[string]interface{}{
+				"id": i, "timestamp": 1700000000 + i, "username": "alice",
+				"context": "local", "action": "POST /stacks", "payload": []byte(`{"Name":"web"}`),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"logs": logs, "totalCount": 150})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	logs, err := NewActivityService(client).ListUserActivity(ActivityLogOptions{
+		After:   time.Unix(1700000000, 0),
+		Keyword: "alice",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(logs) != 150 {
+		t.Fatalf("expected 150 entries, got %d", len(logs))
+	}
+	if logs[149].ID != 149 || string(logs[0].Payload) != `{"Name":"web"}` {
+		t.Errorf("unexpected entries %+v, %+v", logs[0], logs[149])
+	}
+}
@@ -1,10 +1,8 @@
-package client
+package portainer
 
 import (
 	"fmt"
 	"net/http"
-
-	"github.com/robversluis/portainer-cli/internal/config"
 )
 
 type AuthService struct {
@@ -100,45 +98,9 @@ func (s *AuthService) GetStatus() (*StatusResponse, error) {
 	return &status, nil
 }
 
-func LoginAndSaveToken(profile *config.Profile, username, password string) (string, error) {
-	client, err := NewClient(profile, WithVerbose(false))
-	if err != nil {
-		return "", err
-	}
-
-	authService := NewAuthService(client)
-	token, err := authService.Login(username, password)
-	if err != nil {
-		return "", err
-	}
-
-	cfg, err := config.Load()
-	if err != nil {
-		return token, fmt.Errorf("logged in but failed to load config: %w", err)
-	}
-
-	profileName := cfg.CurrentProfile
-	if profileName == "" {
-		return token, fmt.Errorf("logged in but no current profile set")
-	}
-
-	storedProfile, err := cfg.GetProfile(profileName)
-	if err != nil {
-		return token, fmt.Errorf("logged in but failed to get profile: %w", err)
-	}
-
-	storedProfile.Token = token
-	storedProfile.Username = username
-
-	if err := cfg.Save(); err != nil {
-		return token, fmt.Errorf("logged in but failed to save token: %w", err)
-	}
-
-	return token, nil
-}
-
-func ValidateAuthentication(profile *config.Profile) error {
-	client, err := NewClient(profile, WithVerbose(false))
+// ValidateAuthentication checks that the credentials in cfg are accepted
+func ValidateAuthentication(cfg *Config) error {
+	client, err := NewClient(cfg, WithVerbose(false))
 	if err != nil {
 		return err
 	}
@@ -1,12 +1,10 @@
-package client
+package portainer
 
 import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
-
-	"github.com/robversluis/portainer-cli/internal/config"
 )
 
 func TestAuthService_Login(t *testing.T) {
@@ -81,12 +79,12 @@ func TestAuthService_Login(t *testing.T) {
 			server := httptest.NewServer(http.HandlerFunc(tt.serverFunc))
 			defer server.Close()
 
-			profile := &config.Profile{
+			cfg := &Config{
 				URL:      server.URL,
 				Username: "admin",
 			}
 
-			client, err := NewClient(profile)
+			client, err := NewClient(cfg)
 			if err != nil {
 				t.Fatalf("failed to create client: %v", err)
 			}
@@ -128,12 +126,12 @@ func TestAuthService_GetStatus(t *testing.T) {
 	}))
 	defer server.Close()
 
-	profile := &config.Profile{
+	cfg := &Config{
 		URL:    server.URL,
 		APIKey: "test-key",
 	}
 
-	client, err := NewClient(profile)
+	client, err := NewClient(cfg)
 	if err != nil {
 		t.Fatalf("failed to create client: %v", err)
 	}
@@ -170,12 +168,12 @@ func TestAuthService_ValidateToken(t *testing.T) {
 	defer server.Close()
 
 	t.Run("valid token", func(t *testing.T) {
-		profile := &config.Profile{
+		cfg := &Config{
 			URL:   server.URL,
 			Token: "valid-token",
 		}
 
-		client, err := NewClient(profile)
+		client, err := NewClient(cfg)
 		if err != nil {
 			t.Fatalf("failed to create client: %v", err)
 		}
@@ -196,12 +194,12 @@ func TestAuthService_ValidateToken(t *testing.T) {
 	})
 
 	t.Run("invalid token", func(t *testing.T) {
-		profile := &config.Profile{
+		cfg := &Config{
 			URL:   server.URL,
 			Token: "invalid-token",
 		}
 
-		client, err := NewClient(profile)
+		client, err := NewClient(cfg)
 		if err != nil {
 			t.Fatalf("failed to create client: %v", err)
 		}
@@ -224,12 +222,12 @@ func TestAuthService_Logout(t *testing.T) {
 	}))
 	defer server.Close()
 
-	profile := &config.Profile{
+	cfg := &Config{
 		URL:   server.URL,
 		Token: "test-token",
 	}
 
-	client, err := NewClient(profile)
+	client, err := NewClient(cfg)
 	if err != nil {
 		t.Fatalf("failed to create client: %v", err)
 	}
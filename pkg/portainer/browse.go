@@ -0,0 +1,108 @@
+package portainer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// agentAPIVersion is the Portainer agent API version used by the file
+// browser endpoints
+const agentAPIVersion = 2
+
+// FileInfo is an entry returned by the Portainer agent file browser
+type FileInfo struct {
+	Name    string `json:"Name"`
+	Size    int64  `json:"Size"`
+	Dir     bool   `json:"Dir"`
+	ModTime int64  `json:"ModTime"`
+}
+
+// GetModTime returns the modification time of the entry
+func (f *FileInfo) GetModTime() time.Time {
+	return time.Unix(f.ModTime, 0)
+}
+
+// browsePath builds an agent browser path. An empty volumeID browses the
+// host filesystem instead of a volume.
+func browsePath(endpointID int, action, volumeID, path string) string {
+	query := url.Values{}
+	if volumeID != "" {
+		query.Set("volumeID", volumeID)
+	}
+	if path != "" {
+		query.Set("path", path)
+	}
+
+	p := fmt.Sprintf("endpoints/%d/docker/v%d/browse/%s", endpointID, agentAPIVersion, action)
+	if encoded := query.Encode(); encoded != "" {
+		p += "?" + encoded
+	}
+	return p
+}
+
+func (c *Client) browseList(endpointID int, volumeID, path string) ([]FileInfo, error) {
+	var files []FileInfo
+	if err := c.Get(browsePath(endpointID, "ls", volumeID, path), &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (c *Client) browseGet(endpointID int, volumeID, path string) (io.ReadCloser, error) {
+	return c.stream(http.MethodGet, browsePath(endpointID, "get", volumeID, path))
+}
+
+// browsePut uploads a local file into dir
+func (c *Client) browsePut(endpointID int, volumeID, dir, localPath string) error {
+	content, err := os.ReadFile(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	if err := writer.WriteField("Path", dir); err != nil {
+		return fmt.Errorf("failed to write path field: %w", err)
+	}
+
+	part, err := writer.CreateFormFile("file", filepath.Base(localPath))
+	if err != nil {
+		return fmt.Errorf("failed to create file field: %w", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		return fmt.Errorf("failed to write file field: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	req, err := c.newRequest(http.MethodPost, browsePath(endpointID, "put", volumeID, ""), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Body = io.NopCloser(body)
+
+	if c.dryRun {
+		fmt.Fprintln(c.logOutput, c.generateCurlCommand(req))
+		return nil
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}
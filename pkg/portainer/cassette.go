@@ -0,0 +1,428 @@
+package portainer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrReplayUnsupported is returned for connections that cannot be served
+// from a cassette, such as attach and exec sessions
+var ErrReplayUnsupported = errors.New("interactive connections are not supported in replay mode")
+
+// cassetteBoundary replaces multipart boundaries when computing request keys,
+// since they are random for every request
+const cassetteBoundary = "cassette-boundary"
+
+// Interaction is a recorded request and its response, stored as one JSON
+// file in a cassette directory
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest identifies the request of an interaction. Credentials are
+// never recorded: headers are left out, and passwords, tokens and stack
+// environment values in bodies are redacted, see redactBody.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Body   string `json:"body,omitempty"`
+}
+
+// RecordedResponse is the response of an interaction. Body is base64 encoded
+// when Encoding is "base64", which is used for non-UTF-8 content.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+	Encoding   string      `json:"encoding,omitempty"`
+}
+
+// WithRecord saves every API response to the cassette directory dir, so the
+// session can later be replayed with WithReplay
+func WithRecord(dir string) ClientOption {
+	return func(c *Client) {
+		c.recordDir = dir
+	}
+}
+
+// WithReplay serves API responses from the cassette directory dir instead of
+// contacting the server. Requests without a recorded response fail.
+func WithReplay(dir string) ClientOption {
+	return func(c *Client) {
+		c.replayDir = dir
+	}
+}
+
+// cassette matches requests to interaction files. Identical requests are
+// numbered in the order they are made, so a session that polls the same
+// endpoint replays the responses in sequence.
+type cassette struct {
+	dir    string
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newCassette(dir string) *cassette {
+	return &cassette{dir: dir, counts: make(map[string]int)}
+}
+
+// next returns the file for the next occurrence of the request
+func (c *cassette) next(req *http.Request, body []byte) string {
+	key := requestKey(req, body)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts[key]++
+	return c.file(key, c.counts[key])
+}
+
+func (c *cassette) file(key string, n int) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%s-%d.json", key, n))
+}
+
+func requestKey(req *http.Request, body []byte) string {
+	if _, params, err := mime.ParseMediaType(req.Header.Get("Content-Type")); err == nil && params["boundary"] != "" {
+		body = bytes.ReplaceAll(body, []byte(params["boundary"]), []byte(cassetteBoundary))
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return req.Method + "-" + hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+// recordingTransport passes requests to the server and saves each response
+// once its body has been read and closed
+type recordingTransport struct {
+	base     http.RoundTripper
+	cassette *cassette
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body = redactBody(req.Header, body)
+	requestBody, _ := encodeBody(body)
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   req.URL.RequestURI(),
+			Body:   requestBody,
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(resp.Header),
+		},
+	}
+
+	// Streams such as followed logs are recorded up to the point where the
+	// caller stops reading
+	resp.Body = &recordingBody{
+		ReadCloser:  resp.Body,
+		interaction: interaction,
+		path:        t.cassette.next(req, body),
+	}
+	return resp, nil
+}
+
+type recordingBody struct {
+	io.ReadCloser
+	interaction *Interaction
+	path        string
+	buf         bytes.Buffer
+	once        sync.Once
+	err         error
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.err = b.save()
+	})
+	if err != nil {
+		return err
+	}
+	return b.err
+}
+
+func (b *recordingBody) save() error {
+	body := redactBody(b.interaction.Response.Header, b.buf.Bytes())
+	b.interaction.Response.Body, b.interaction.Response.Encoding = encodeBody(body)
+
+	data, err := json.MarshalIndent(b.interaction, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recorded response: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(b.path), 0700); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(b.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save recorded response: %w", err)
+	}
+	return nil
+}
+
+// replayTransport serves responses from a cassette without network access
+type replayTransport struct {
+	cassette *cassette
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Requests are recorded redacted, so they are matched the same way
+	path := t.cassette.next(req, redactBody(req.Header, body))
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no recorded response for %s %s in %s", req.Method, req.URL.RequestURI(), t.cassette.dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded response: %w", err)
+	}
+
+	var interaction Interaction
+	if err := json.Unmarshal(data, &interaction); err != nil {
+		return nil, fmt.Errorf("failed to parse recorded response %s: %w", path, err)
+	}
+
+	responseBody := []byte(interaction.Response.Body)
+	if interaction.Response.Encoding == "base64" {
+		responseBody, err = base64.StdEncoding.DecodeString(interaction.Response.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode recorded response %s: %w", path, err)
+		}
+	}
+
+	header := interaction.Response.Header
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(responseBody)),
+		ContentLength: int64(len(responseBody)),
+		Request:       req,
+	}, nil
+}
+
+// redacted replaces the secrets in recorded interactions
+const redacted = "REDACTED"
+
+// secretKeys are the parts of JSON keys whose string values are redacted,
+// such as password, jwt and rawAPIKey
+var secretKeys = []string{"password", "passwd", "jwt", "token", "apikey", "secret", "edgekey"}
+
+// redactBody returns body with the passwords, tokens and stack environment
+// values of a JSON or multipart form body replaced, or body itself when it
+// has none
+func redactBody(header http.Header, body []byte) []byte {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err == nil && strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		return redactMultipart(body, params["boundary"])
+	}
+	if redactedBody, ok := redactJSON(body, false); ok {
+		return redactedBody
+	}
+	return body
+}
+
+// redactMultipart redacts the fields of a multipart form, such as the Env
+// field of a stack created from a file
+func redactMultipart(body []byte, boundary string) []byte {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var out bytes.Buffer
+	writer := multipart.NewWriter(&out)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return body
+	}
+
+	changed := false
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return body
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return body
+		}
+		if part.FileName() == "" {
+			if redactedData, ok := redactJSON(data, strings.EqualFold(part.FormName(), "env")); ok {
+				data, changed = redactedData, true
+			} else if isSecretKey(part.FormName()) && len(data) > 0 {
+				data, changed = []byte(redacted), true
+			}
+		}
+		w, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return body
+		}
+		w.Write(data)
+	}
+	if !changed || writer.Close() != nil {
+		return body
+	}
+	return out.Bytes()
+}
+
+// redactJSON redacts a JSON document, or a list of env variables, reporting
+// whether it had secrets
+func redactJSON(data []byte, env bool) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil || decoder.More() {
+		return nil, false
+	}
+	if env && !redactEnv(doc) || !env && !redactValue(doc) {
+		return nil, false
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, false
+	}
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n")), true
+}
+
+// redactValue redacts the secrets of a decoded JSON value in place: the
+// string values of secret keys and the values of env lists, as sent and
+// returned for stacks. It reports whether anything was redacted.
+func redactValue(value interface{}) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			switch {
+			case isSecretKey(key):
+				if s, ok := item.(string); ok && s != "" {
+					v[key] = redacted
+					changed = true
+					continue
+				}
+			case strings.EqualFold(key, "env"):
+				if redactEnv(item) {
+					changed = true
+					continue
+				}
+			}
+			if redactValue(item) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if redactValue(item) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// redactEnv redacts the values of a list of {name, value} variables
+func redactEnv(value interface{}) bool {
+	list, ok := value.([]interface{})
+	if !ok {
+		return false
+	}
+	changed := false
+	for _, item := range list {
+		variable, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, v := range variable {
+			if s, ok := v.(string); ok && s != "" && strings.EqualFold(key, "value") {
+				variable[key] = redacted
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeader returns a copy of a response header without the cookies the
+// server sets, which may hold the session token
+func redactHeader(header http.Header) http.Header {
+	clone := header.Clone()
+	for i := range clone["Set-Cookie"] {
+		clone["Set-Cookie"][i] = redacted
+	}
+	return clone
+}
@@ -0,0 +1,189 @@
+package portainer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cassette")
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/api/endpoints":
+			json.NewEncoder(w).Encode([]Environment{{Id: calls, Name: "local"}})
+		case "/api/blob":
+			w.Write([]byte{0xff, 0x00, 0xfe})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "not found"})
+		}
+	}))
+
+	recorder, err := NewClient(&Config{URL: server.URL, APIKey: "secret-key"}, WithRecord(dir))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	first, err := NewEnvironmentService(recorder).List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := NewEnvironmentService(recorder).List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	blob, err := recorder.getRaw("blob", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := recorder.Get("missing", nil); !IsNotFoundError(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	server.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 4 {
+		t.Fatalf("expected 4 recorded interactions, got %d", len(files))
+	}
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		if strings.Contains(string(data), "secret-key") {
+			t.Errorf("credentials recorded in %s", file)
+		}
+	}
+
+	replayer, err := NewClient(&Config{URL: server.URL, APIKey: "other-key"}, WithReplay(dir))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	replayedFirst, err := NewEnvironmentService(replayer).List()
+	if err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+	replayedSecond, err := NewEnvironmentService(replayer).List()
+	if err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+	if replayedFirst[0].Id != first[0].Id || replayedSecond[0].Id != second[0].Id {
+		t.Errorf("identical requests should replay in recorded order")
+	}
+
+	replayedBlob, err := replayer.getRaw("blob", "")
+	if err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+	if !bytes.Equal(replayedBlob, blob) {
+		t.Errorf("binary body not replayed: %v", replayedBlob)
+	}
+
+	if err := replayer.Get("missing", nil); !IsNotFoundError(err) {
+		t.Errorf("expected recorded not found error, got %v", err)
+	}
+
+	if _, err := NewEnvironmentService(replayer).List(); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("expected error for unrecorded request, got %v", err)
+	}
+
+	if _, err := replayer.hijack(http.MethodPost, "attach"); err == nil || !strings.Contains(err.Error(), ErrReplayUnsupported.Error()) {
+		t.Errorf("expected ErrReplayUnsupported, got %v", err)
+	}
+}
+
+func TestRequestKey_MultipartBoundary(t *testing.T) {
+	newRequest := func() (*http.Request, []byte) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		writer.WriteField("Name", "web")
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/stacks?type=2", bytes.NewReader(body.Bytes()))
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		data, _ := io.ReadAll(req.Body)
+		return req, data
+	}
+
+	first, firstBody := newRequest()
+	second, secondBody := newRequest()
+	if requestKey(first, firstBody) != requestKey(second, secondBody) {
+		t.Error("multipart requests with the same fields should have the same key")
+	}
+}
+
+func TestNewClient_RecordAndReplay(t *testing.T) {
+	_, err := NewClient(&Config{URL: "https://test.example.com", APIKey: "key"}, WithRecord("a"), WithReplay("b"))
+	if err == nil {
+		t.Error("expected error when recording and replaying at once")
+	}
+}
+
+func TestCassette_RedactsSecrets(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cassette")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/auth":
+			http.SetCookie(w, &http.Cookie{Name: "portainer_api_key", Value: "jwt-secret-token"})
+			w.Write([]byte(`{"jwt":"jwt-secret-token"}`))
+		case "/api/stacks/5":
+			w.Write([]byte(`{"Id":5,"Name":"web","Env":[{"name":"DB_PASSWORD","value":"env-secret-value"}]}`))
+		default:
+			w.Write([]byte(`{"Id":6}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "key"}, WithRecord(dir))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := NewAuthService(client).Login("admin", "login-secret-password"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := NewStackService(client).Get(5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := NewStackService(client).Update(5, 1, "services: {}", []StackEnv{{Name: "DB_PASSWORD", Value: "env-secret-value"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := NewStackService(client).Deploy(1, "web", "services: {}", []StackEnv{{Name: "API_TOKEN", Value: "multipart-secret-value"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 4 {
+		t.Fatalf("expected 4 recorded interactions, got %d", len(files))
+	}
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		for _, secret := range []string{"login-secret-password", "jwt-secret-token", "env-secret-value", "multipart-secret-value"} {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("%s recorded in %s", secret, file)
+			}
+		}
+		if !strings.Contains(string(data), redacted) {
+			t.Errorf("expected redacted values in %s", file)
+		}
+	}
+
+	// Replaying matches the redacted requests, whatever the secrets
+	replayer, err := NewClient(&Config{URL: server.URL, APIKey: "key"}, WithReplay(dir))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := NewAuthService(replayer).Login("admin", "another-password"); err != nil {
+		t.Errorf("unexpected replay error: %v", err)
+	}
+}
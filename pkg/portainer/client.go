@@ -0,0 +1,916 @@
+package portainer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout    = 300 * time.Second
+	defaultMaxRetries = 3
+	defaultRetryDelay = 2 * time.Second
+	userAgent         = "portainer-cli"
+
+	// RequestIDHeader carries the unique ID of each request, to find the
+	// request in the logs of Portainer and of the proxies in front of it
+	RequestIDHeader = "X-Request-Id"
+)
+
+// Config holds the connection settings of a Portainer instance. URL and one
+// of APIKey, Token or Username are required.
+type Config struct {
+	// URL is the base URL of the Portainer server, e.g. https://portainer:9443
+	URL string
+	// APIKey is a Portainer access token, sent as X-API-KEY
+	APIKey string
+	// Token is a JWT obtained with AuthService.Login
+	Token string
+	// Username is the user the JWT belongs to
+	Username string
+	// Insecure disables TLS certificate verification
+	Insecure bool
+}
+
+// Validate checks that the URL and an authentication method are set
+func (c *Config) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("URL is required")
+	}
+
+	if c.APIKey == "" && c.Username == "" && c.Token == "" {
+		return fmt.Errorf("at least one authentication method is required (api_key, username, or token)")
+	}
+
+	return nil
+}
+
+// Client is a Portainer API client. The service types (ContainerService,
+// StackService, ...) wrap a Client and provide the typed API calls.
+type Client struct {
+	ctx        context.Context
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+	token      string
+	username   string
+	verbose    bool
+	dryRun     bool
+	maxRetries int
+	retryDelay time.Duration
+	// retryUnsafe also retries requests that are not idempotent
+	retryUnsafe bool
+	recordDir   string
+	replayDir   string
+	// serverVersion selects between API variants, see WithServerVersion
+	serverVersion Version
+	stats         *Stats
+	// deprecationHandler receives deprecation notices, see
+	// WithDeprecationHandler
+	deprecationHandler func(Deprecation)
+	// insecure and tlsConfig select the transport, see NewClient
+	insecure  bool
+	tlsConfig *tls.Config
+	// serverSocket is the socket of a connection server, see
+	// WithConnectionServer
+	serverSocket string
+	// logOutput receives verbose and dry-run output, see WithLogOutput
+	logOutput io.Writer
+	// edge remembers the environments that are not Edge environments
+	edge *edgeCache
+}
+
+type ClientOption func(*Client)
+
+func WithVerbose(verbose bool) ClientOption {
+	return func(c *Client) {
+		c.verbose = verbose
+	}
+}
+
+func WithDryRun(dryRun bool) ClientOption {
+	return func(c *Client) {
+		c.dryRun = dryRun
+	}
+}
+
+// WithLogOutput sets where verbose output and the curl commands of dry runs
+// are written. Without it they are discarded.
+func WithLogOutput(w io.Writer) ClientOption {
+	return func(c *Client) {
+		if w == nil {
+			w = io.Discard
+		}
+		c.logOutput = w
+	}
+}
+
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+func WithMaxRetries(retries int) ClientOption {
+	return func(c *Client) {
+		c.maxRetries = retries
+	}
+}
+
+// WithRetryDelay sets the delay between retries of a failed request
+func WithRetryDelay(delay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.retryDelay = delay
+	}
+}
+
+// WithRetryUnsafe retries requests that are not idempotent, such as the
+// POST that deploys a stack, after server errors and lost connections. By
+// default they are only retried when the connection could not be opened,
+// since the server may have acted on a request whose response was lost.
+func WithRetryUnsafe(retryUnsafe bool) ClientOption {
+	return func(c *Client) {
+		c.retryUnsafe = retryUnsafe
+	}
+}
+
+func WithInsecure(insecure bool) ClientOption {
+	return func(c *Client) {
+		if insecure {
+			c.insecure = true
+		}
+	}
+}
+
+// WithCustomCA uses certPool as the TLS configuration of the client, which
+// then gets a transport of its own instead of a shared one
+func WithCustomCA(certPool *tls.Config) ClientOption {
+	return func(c *Client) {
+		c.tlsConfig = certPool
+	}
+}
+
+// NewClient creates a client for the Portainer instance described by cfg
+func NewClient(cfg *Config, opts ...ClientOption) (*Client, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	baseURL := strings.TrimSuffix(cfg.URL, "/")
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		return nil, fmt.Errorf("invalid URL: must start with http:// or https://")
+	}
+
+	client := &Client{
+		ctx:     context.Background(),
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: defaultTimeout,
+		},
+		apiKey:     cfg.APIKey,
+		token:      cfg.Token,
+		username:   cfg.Username,
+		maxRetries: defaultMaxRetries,
+		retryDelay: defaultRetryDelay,
+		logOutput:  io.Discard,
+		edge:       &edgeCache{notEdge: map[int]bool{}},
+	}
+
+	if cfg.Insecure {
+		opts = append(opts, WithInsecure(true))
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(client)
+		}
+	}
+
+	// Clients with the same TLS settings share a transport, so connections
+	// are reused across clients. A custom TLS configuration gets its own.
+	var transport *http.Transport
+	switch {
+	case client.tlsConfig != nil:
+		transport = newTransport(client.tlsConfig)
+	default:
+		transport = sharedTransport(client.insecure)
+	}
+	client.httpClient.Transport = transport
+	if client.serverSocket != "" && client.tlsConfig == nil {
+		client.httpClient.Transport = newServerTransport(client.serverSocket, transport, client.insecure)
+	}
+
+	// The cassette transports wrap the configured transport, so they are
+	// installed once all options have been applied
+	switch {
+	case client.recordDir != "" && client.replayDir != "":
+		return nil, fmt.Errorf("record and replay cannot be used together")
+	case client.replayDir != "":
+		client.httpClient.Transport = &replayTransport{cassette: newCassette(client.replayDir)}
+	case client.recordDir != "":
+		client.httpClient.Transport = &recordingTransport{
+			base:     client.httpClient.Transport,
+			cassette: newCassette(client.recordDir),
+		}
+	}
+	if client.deprecationHandler != nil {
+		client.httpClient.Transport = &deprecationTransport{base: client.httpClient.Transport, handler: client.deprecationHandler}
+	}
+	if client.stats != nil {
+		client.httpClient.Transport = &statsTransport{base: client.httpClient.Transport, stats: client.stats}
+	}
+
+	return client, nil
+}
+
+// transport returns the underlying HTTP transport, or nil when responses are
+// replayed from a cassette
+func (c *Client) transport() *http.Transport {
+	rt := c.httpClient.Transport
+	for {
+		switch t := rt.(type) {
+		case *http.Transport:
+			return t
+		case *statsTransport:
+			rt = t.base
+		case *deprecationTransport:
+			rt = t.base
+		case *recordingTransport:
+			rt = t.base
+		case *serverTransport:
+			rt = t.direct
+		default:
+			return nil
+		}
+	}
+}
+
+// WithContext returns a shallow copy of the client whose requests use ctx.
+// Cancelling ctx aborts in-flight requests and pending retries. Services
+// created from the copy share its context:
+//
+//	containers := portainer.NewContainerService(c.WithContext(ctx))
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+	copied := *c
+	copied.ctx = ctx
+	return &copied
+}
+
+// Context returns the context used for the client's requests
+func (c *Client) Context() context.Context {
+	return c.ctx
+}
+
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+func (c *Client) GetToken() string {
+	return c.token
+}
+
+func (c *Client) buildURL(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	return fmt.Sprintf("%s/api/%s", c.baseURL, path)
+}
+
+func (c *Client) newRequest(method, path string, body interface{}) (*http.Request, error) {
+	url := c.buildURL(path)
+
+	var bodyReader io.Reader
+	var jsonData []byte
+	if body != nil {
+		var err error
+		jsonData, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		bodyReader = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, url, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if body != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(jsonData)), nil
+		}
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(RequestIDHeader, newRequestID())
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.apiKey != "" {
+		req.Header.Set("X-API-KEY", c.apiKey)
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return req, nil
+}
+
+// newRequestID returns a random version 4 UUID identifying a request. Retries
+// of a request keep its ID.
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// RequestID returns the ID the client gave to a request, or an empty string
+func RequestID(req *http.Request) string {
+	return req.Header.Get(RequestIDHeader)
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	// Requests that can change state are never sent in dry-run mode, also
+	// on paths without a dry-run check of their own such as image pulls
+	if c.dryRun && !isSafeMethod(req.Method) {
+		fmt.Fprintln(c.logOutput, c.generateCurlCommand(req))
+		return dryRunResponse(req), nil
+	}
+
+	var resp *http.Response
+	var err error
+	// Requests that may have been applied are only resent when they are
+	// idempotent, and bodies that cannot be read again are never resent
+	safe := c.retryUnsafe || isIdempotent(req.Method)
+	rewindable := canRewind(req)
+
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			c.countRetry()
+			if c.verbose {
+				fmt.Fprintf(c.logOutput, "Retry attempt %d/%d after %v\n", attempt, c.maxRetries, c.retryDelay)
+			}
+			select {
+			case <-time.After(c.retryDelay):
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+
+			if err := rewindBody(req); err != nil {
+				return nil, err
+			}
+		}
+
+		if c.verbose {
+			fmt.Fprintf(c.logOutput, "%s %s (request ID %s)\n", req.Method, req.URL.String(), RequestID(req))
+		}
+
+		resp, err = c.httpClient.Do(req)
+		if err != nil {
+			if attempt < c.maxRetries && rewindable && isRetryableError(err) && (safe || isDialError(err)) {
+				continue
+			}
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+
+		if resp.StatusCode >= 500 && attempt < c.maxRetries && safe && rewindable {
+			resp.Body.Close()
+			continue
+		}
+
+		break
+	}
+
+	if safe && rewindable && isEdgeTunnelCandidate(req, resp) {
+		retry, err := c.awaitEdgeTunnel(req)
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if retry {
+			resp.Body.Close()
+			c.countRetry()
+			if err := rewindBody(req); err != nil {
+				return nil, err
+			}
+			if resp, err = c.httpClient.Do(req); err != nil {
+				return nil, fmt.Errorf("request failed: %w", err)
+			}
+		}
+	}
+
+	if c.verbose && resp != nil {
+		fmt.Fprintf(c.logOutput, "Response: %d %s\n", resp.StatusCode, resp.Status)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) generateCurlCommand(req *http.Request) string {
+	var curlCmd strings.Builder
+	curlCmd.WriteString("curl -X ")
+	curlCmd.WriteString(req.Method)
+
+	for key, values := range req.Header {
+		for _, value := range values {
+			curlCmd.WriteString(" \\\n  -H '")
+			curlCmd.WriteString(key)
+			curlCmd.WriteString(": ")
+			curlCmd.WriteString(value)
+			curlCmd.WriteString("'")
+		}
+	}
+
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err == nil {
+			bodyBytes, err := io.ReadAll(body)
+			if err == nil && len(bodyBytes) > 0 {
+				curlCmd.WriteString(" \\\n  -d '")
+				curlCmd.WriteString(string(bodyBytes))
+				curlCmd.WriteString("'")
+			}
+		}
+	}
+
+	curlCmd.WriteString(" \\\n  '")
+	curlCmd.WriteString(req.URL.String())
+	curlCmd.WriteString("'")
+
+	return curlCmd.String()
+}
+
+func (c *Client) DoRequest(method, path string, body interface{}, result interface{}) error {
+	_, err := c.doRequestWithHeaders(method, path, body, result)
+	return err
+}
+
+func (c *Client) doRequestWithHeaders(method, path string, body interface{}, result interface{}) (http.Header, error) {
+	req, err := c.newRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.dryRun {
+		fmt.Fprintln(c.logOutput, c.generateCurlCommand(req))
+		return http.Header{}, nil
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	if result != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return resp.Header, nil
+}
+
+// getRaw performs a GET request and returns the undecoded response body.
+// accept sets the Accept header when not empty. In dry-run mode the request
+// is printed and nil is returned.
+func (c *Client) getRaw(path, accept string) ([]byte, error) {
+	req, err := c.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	if c.dryRun {
+		fmt.Fprintln(c.logOutput, c.generateCurlCommand(req))
+		return nil, nil
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
+}
+
+// getArray performs a GET request whose response is a JSON array and calls
+// fn with the decoder positioned at each element, so long lists are decoded
+// one element at a time instead of all at once. An error returned by fn
+// stops decoding and is returned. In dry-run mode the request is printed and
+// fn is not called.
+func (c *Client) getArray(path string, fn func(dec *json.Decoder) error) error {
+	req, err := c.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+
+	if c.dryRun {
+		fmt.Fprintln(c.logOutput, c.generateCurlCommand(req))
+		return nil
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if token == nil {
+		// A null list has no elements
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("failed to decode response: expected a JSON array")
+	}
+	for dec.More() {
+		if err := fn(dec); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// stream performs a request whose response body is read incrementally, such
+// as the Docker events feed. The client timeout is not applied so the
+// connection can stay open; the caller must close the returned body.
+func (c *Client) stream(method, path string) (io.ReadCloser, error) {
+	req, err := c.newRequest(method, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.dryRun {
+		fmt.Fprintln(c.logOutput, c.generateCurlCommand(req))
+		return nil, nil
+	}
+
+	if c.verbose {
+		fmt.Fprintf(c.logOutput, "%s %s (stream, request ID %s)\n", req.Method, req.URL.String(), RequestID(req))
+	}
+
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if (c.retryUnsafe || isIdempotent(req.Method)) && isEdgeTunnelCandidate(req, resp) {
+		retry, err := c.awaitEdgeTunnel(req)
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if retry {
+			resp.Body.Close()
+			if resp, err = streamClient.Do(req); err != nil {
+				return nil, fmt.Errorf("request failed: %w", err)
+			}
+		}
+	}
+
+	if err := checkResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// upload sends body as the payload of a request without buffering it, for
+// archives that can be larger than memory. The request is not retried and
+// the client timeout is not applied.
+func (c *Client) upload(method, path, contentType string, body io.Reader) error {
+	req, err := c.newRequest(method, path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	if c.dryRun {
+		fmt.Fprintln(c.logOutput, c.generateCurlCommand(req))
+		return nil
+	}
+
+	req.Body = io.NopCloser(body)
+
+	if c.verbose {
+		fmt.Fprintf(c.logOutput, "%s %s (upload, request ID %s)\n", req.Method, req.URL.String(), RequestID(req))
+	}
+
+	uploadClient := *c.httpClient
+	uploadClient.Timeout = 0
+
+	resp, err := uploadClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}
+
+func (c *Client) Get(path string, result interface{}) error {
+	return c.DoRequest(http.MethodGet, path, nil, result)
+}
+
+// GetWithHeaders performs a GET request and also returns the response
+// headers, e.g. to read pagination totals.
+func (c *Client) GetWithHeaders(path string, result interface{}) (http.Header, error) {
+	return c.doRequestWithHeaders(http.MethodGet, path, nil, result)
+}
+
+func (c *Client) Post(path string, body interface{}, result interface{}) error {
+	return c.DoRequest(http.MethodPost, path, body, result)
+}
+
+func (c *Client) Put(path string, body interface{}, result interface{}) error {
+	return c.DoRequest(http.MethodPut, path, body, result)
+}
+
+func (c *Client) Delete(path string) error {
+	return c.DoRequest(http.MethodDelete, path, nil, nil)
+}
+
+// RawResponse is the response of a RawRequest
+type RawResponse struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+}
+
+// RawRequest sends a request with a pre-encoded body to an API path such as
+// "endpoints/1/docker/info" and returns the undecoded response. It is meant
+// for API calls that have no typed service method. header is added to the
+// request and may override the default headers. Responses with an error
+// status are returned as *APIError. In dry-run mode the request is printed
+// and nil is returned.
+func (c *Client) RawRequest(method, path string, body []byte, header http.Header) (*RawResponse, error) {
+	req, err := c.newRequest(method, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.ContentLength = int64(len(body))
+	}
+	for key, values := range header {
+		req.Header.Del(key)
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	if c.dryRun {
+		fmt.Fprintln(c.logOutput, c.generateCurlCommand(req))
+		return nil, nil
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return &RawResponse{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+		Body:       data,
+	}, nil
+}
+
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	requestID := responseRequestID(resp)
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("HTTP %d: failed to read response body", resp.StatusCode),
+			RequestID:  requestID,
+		}
+	}
+	bodyString := strings.TrimSpace(string(bodyBytes))
+
+	// Portainer errors have a message and details, errors of the Docker
+	// engine only a message
+	var apiError APIError
+	if err := json.Unmarshal(bodyBytes, &apiError); err == nil && (apiError.Message != "" || apiError.Details != "") {
+		if apiError.Message == "" {
+			apiError.Message, apiError.Details = apiError.Details, ""
+		} else if apiError.Details == apiError.Message {
+			apiError.Details = ""
+		}
+		apiError.StatusCode = resp.StatusCode
+		apiError.RequestID = requestID
+		return &apiError
+	}
+
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    fmt.Sprintf("HTTP %d: %s", resp.StatusCode, bodyString),
+		RequestID:  requestID,
+	}
+}
+
+// responseRequestID returns the request ID of a response: the ID echoed by
+// the server or a proxy in front of it, or else the ID the request was sent
+// with
+func responseRequestID(resp *http.Response) string {
+	if id := resp.Header.Get(RequestIDHeader); id != "" {
+		return id
+	}
+	if resp.Request != nil {
+		return RequestID(resp.Request)
+	}
+	return ""
+}
+
+func isRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if urlErr, ok := err.(*url.Error); ok {
+		if urlErr.Timeout() || urlErr.Temporary() {
+			return true
+		}
+	}
+
+	errStr := err.Error()
+	return strings.Contains(errStr, "connection refused") ||
+		strings.Contains(errStr, "connection reset") ||
+		strings.Contains(errStr, "timeout")
+}
+
+// isSafeMethod reports whether a request with method only reads state
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// dryRunResponse returns the response standing in for a request that was not
+// sent in dry-run mode: a success without content
+func dryRunResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:     "204 No Content",
+		StatusCode: http.StatusNoContent,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}
+}
+
+// isIdempotent reports whether repeating a request with method has the same
+// effect as sending it once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// canRewind reports whether the body of a request can be read again to
+// resend it
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindBody resets the body of a request that is sent again
+func rewindBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return fmt.Errorf("failed to reset request body: %w", err)
+	}
+	req.Body = body
+	return nil
+}
+
+// isDialError reports whether err occurred while opening the connection,
+// before any part of the request was sent
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Message    string `json:"message"`
+	Details    string `json:"details,omitempty"`
+	// RequestID is the X-Request-Id of the failed request, to find it in
+	// the server logs
+	RequestID string `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("API error (HTTP %d): %s", e.StatusCode, e.Message)
+	if e.Details != "" {
+		msg += " - " + e.Details
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request ID: %s)", e.RequestID)
+	}
+	return msg
+}
+
+func IsNotFoundError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+func IsUnauthorizedError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusUnauthorized
+	}
+	return false
+}
+
+func IsForbiddenError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusForbidden
+	}
+	return false
+}
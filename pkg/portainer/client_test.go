@@ -0,0 +1,680 @@
+package portainer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       *Config
+		wantError bool
+	}{
+		{
+			name: "valid config with API key",
+			cfg: &Config{
+				URL:    "https://test.example.com",
+				APIKey: "test-key",
+			},
+			wantError: false,
+		},
+		{
+			name: "valid config with token",
+			cfg: &Config{
+				URL:   "https://test.example.com",
+				Token: "jwt-token",
+			},
+			wantError: false,
+		},
+		{
+			name: "valid config with username",
+			cfg: &Config{
+				URL:      "https://test.example.com",
+				Username: "admin",
+			},
+			wantError: false,
+		},
+		{
+			name:      "nil config",
+			cfg:       nil,
+			wantError: true,
+		},
+		{
+			name: "invalid URL - no scheme",
+			cfg: &Config{
+				URL:    "test.example.com",
+				APIKey: "test-key",
+			},
+			wantError: true,
+		},
+		{
+			name: "missing URL",
+			cfg: &Config{
+				APIKey: "test-key",
+			},
+			wantError: true,
+		},
+		{
+			name: "missing auth method",
+			cfg: &Config{
+				URL: "https://test.example.com",
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.cfg)
+			if tt.wantError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if client == nil {
+				t.Error("client should not be nil")
+			}
+		})
+	}
+}
+
+func TestClient_SetToken(t *testing.T) {
+	cfg := &Config{
+		URL:    "https://test.example.com",
+		APIKey: "test-key",
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	token := "new-jwt-token"
+	client.SetToken(token)
+
+	if client.GetToken() != token {
+		t.Errorf("expected token %s, got %s", token, client.GetToken())
+	}
+}
+
+func TestClient_buildURL(t *testing.T) {
+	cfg := &Config{
+		URL:    "https://test.example.com",
+		APIKey: "test-key",
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{
+			path:     "auth",
+			expected: "https://test.example.com/api/auth",
+		},
+		{
+			path:     "/auth",
+			expected: "https://test.example.com/api/auth",
+		},
+		{
+			path:     "users/1",
+			expected: "https://test.example.com/api/users/1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			result := client.buildURL(tt.path)
+			if result != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestClient_DoRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-KEY") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"message": "unauthorized"})
+			return
+		}
+
+		if r.URL.Path == "/api/test" {
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]string{"result": "success"})
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "not found"})
+	}))
+	defer server.Close()
+
+	cfg := &Config{
+		URL:    server.URL,
+		APIKey: "test-key",
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	t.Run("successful request", func(t *testing.T) {
+		var result map[string]string
+		err := client.Get("test", &result)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		if result["result"] != "success" {
+			t.Errorf("expected result 'success', got '%s'", result["result"])
+		}
+	})
+
+	t.Run("not found error", func(t *testing.T) {
+		var result map[string]string
+		err := client.Get("nonexistent", &result)
+		if err == nil {
+			t.Error("expected error but got none")
+		}
+
+		if !IsNotFoundError(err) {
+			t.Errorf("expected not found error, got: %v", err)
+		}
+	})
+}
+
+func TestClient_WithOptions(t *testing.T) {
+	cfg := &Config{
+		URL:    "https://test.example.com",
+		APIKey: "test-key",
+	}
+
+	t.Run("with verbose", func(t *testing.T) {
+		client, err := NewClient(cfg, WithVerbose(true))
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		if !client.verbose {
+			t.Error("verbose should be true")
+		}
+	})
+
+	t.Run("with timeout", func(t *testing.T) {
+		timeout := 5 * time.Second
+		client, err := NewClient(cfg, WithTimeout(timeout))
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		if client.httpClient.Timeout != timeout {
+			t.Errorf("expected timeout %v, got %v", timeout, client.httpClient.Timeout)
+		}
+	})
+
+	t.Run("with max retries", func(t *testing.T) {
+		retries := 5
+		client, err := NewClient(cfg, WithMaxRetries(retries))
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		if client.maxRetries != retries {
+			t.Errorf("expected max retries %d, got %d", retries, client.maxRetries)
+		}
+	})
+
+	t.Run("with insecure", func(t *testing.T) {
+		client, err := NewClient(cfg, WithInsecure(true))
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+
+		transport := client.httpClient.Transport.(*http.Transport)
+		if !transport.TLSClientConfig.InsecureSkipVerify {
+			t.Error("InsecureSkipVerify should be true")
+		}
+	})
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *APIError
+		expected string
+	}{
+		{
+			name: "with details",
+			err: &APIError{
+				StatusCode: 400,
+				Message:    "Bad Request",
+				Details:    "Invalid parameter",
+			},
+			expected: "API error (HTTP 400): Bad Request - Invalid parameter",
+		},
+		{
+			name: "without details",
+			err: &APIError{
+				StatusCode: 404,
+				Message:    "Not Found",
+			},
+			expected: "API error (HTTP 404): Not Found",
+		},
+		{
+			name: "with request ID",
+			err: &APIError{
+				StatusCode: 500,
+				Message:    "Unable to retrieve stacks",
+				Details:    "database is locked",
+				RequestID:  "6f1c2a3b-0d4e-4f5a-8b6c-7d8e9f0a1b2c",
+			},
+			expected: "API error (HTTP 500): Unable to retrieve stacks - database is locked (request ID: 6f1c2a3b-0d4e-4f5a-8b6c-7d8e9f0a1b2c)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.err.Error()
+			if result != tt.expected {
+				t.Errorf("expected '%s', got '%s'", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestErrorCheckers(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		is404 bool
+		is401 bool
+		is403 bool
+	}{
+		{
+			name:  "404 error",
+			err:   &APIError{StatusCode: 404, Message: "Not Found"},
+			is404: true,
+			is401: false,
+			is403: false,
+		},
+		{
+			name:  "401 error",
+			err:   &APIError{StatusCode: 401, Message: "Unauthorized"},
+			is404: false,
+			is401: true,
+			is403: false,
+		},
+		{
+			name:  "403 error",
+			err:   &APIError{StatusCode: 403, Message: "Forbidden"},
+			is404: false,
+			is401: false,
+			is403: true,
+		},
+		{
+			name:  "non-API error",
+			err:   http.ErrServerClosed,
+			is404: false,
+			is401: false,
+			is403: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if IsNotFoundError(tt.err) != tt.is404 {
+				t.Errorf("IsNotFoundError: expected %v, got %v", tt.is404, IsNotFoundError(tt.err))
+			}
+			if IsUnauthorizedError(tt.err) != tt.is401 {
+				t.Errorf("IsUnauthorizedError: expected %v, got %v", tt.is401, IsUnauthorizedError(tt.err))
+			}
+			if IsForbiddenError(tt.err) != tt.is403 {
+				t.Errorf("IsForbiddenError: expected %v, got %v", tt.is403, IsForbiddenError(tt.err))
+			}
+		})
+	}
+}
+
+func TestClient_HTTPMethods(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := map[string]string{
+			"method": r.Method,
+			"path":   r.URL.Path,
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	cfg := &Config{
+		URL:    server.URL,
+		APIKey: "test-key",
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		method         func() error
+		expectedMethod string
+	}{
+		{
+			name: "GET",
+			method: func() error {
+				var result map[string]string
+				return client.Get("test", &result)
+			},
+			expectedMethod: "GET",
+		},
+		{
+			name: "POST",
+			method: func() error {
+				var result map[string]string
+				return client.Post("test", map[string]string{"key": "value"}, &result)
+			},
+			expectedMethod: "POST",
+		},
+		{
+			name: "PUT",
+			method: func() error {
+				var result map[string]string
+				return client.Put("test", map[string]string{"key": "value"}, &result)
+			},
+			expectedMethod: "PUT",
+		},
+		{
+			name: "DELETE",
+			method: func() error {
+				return client.Delete("test")
+			},
+			expectedMethod: "DELETE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.method()
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_WithContext(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	client.retryDelay = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scoped := client.WithContext(ctx)
+	if scoped == client || client.Context() != context.Background() {
+		t.Fatal("WithContext should return a copy and leave the original client unchanged")
+	}
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	err = scoped.Get("test", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected the retry to be abandoned after 1 request, got %d", requests)
+	}
+}
+
+func TestClient_RawRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/endpoints/1/docker/containers/create":
+			body, _ := io.ReadAll(r.Body)
+			if r.Method != http.MethodPost || string(body) != `{"Image":"nginx"}` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if r.Header.Get("Content-Type") != "application/json" || r.URL.Query().Get("name") != "web" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("X-Test", "ok")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"Id":"abc"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "not found"})
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	header := http.Header{"Content-Type": {"application/json"}}
+	resp, err := client.RawRequest(http.MethodPost, "endpoints/1/docker/containers/create?name=web", []byte(`{"Image":"nginx"}`), header)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated || string(resp.Body) != `{"Id":"abc"}` || resp.Header.Get("X-Test") != "ok" {
+		t.Errorf("unexpected response: %d %s %v", resp.StatusCode, resp.Body, resp.Header)
+	}
+
+	if _, err := client.RawRequest(http.MethodGet, "missing", nil, nil); !IsNotFoundError(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestClient_RetryUnsafe(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		method   string
+		opts     []ClientOption
+		expected int
+	}{
+		{name: "idempotent", method: http.MethodGet, expected: 3},
+		{name: "not idempotent", method: http.MethodPost, expected: 1},
+		{name: "not idempotent with retry unsafe", method: http.MethodPost, opts: []ClientOption{WithRetryUnsafe(true)}, expected: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			opts := append([]ClientOption{WithMaxRetries(2), WithRetryDelay(time.Millisecond)}, tt.opts...)
+			client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, opts...)
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+
+			if err := client.DoRequest(tt.method, "stacks", nil, nil); err == nil {
+				t.Fatal("expected an error")
+			}
+			if requests != tt.expected {
+				t.Errorf("expected %d requests, got %d", tt.expected, requests)
+			}
+		})
+	}
+}
+
+func TestClient_RetryRewindsBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, WithMaxRetries(2), WithRetryDelay(time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if err := client.Put("stacks/1", map[string]string{"Name": "web"}, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(bodies) != 3 || bodies[0] == "" || bodies[1] != bodies[0] || bodies[2] != bodies[0] {
+		t.Errorf("expected every attempt to send the body, got %q", bodies)
+	}
+
+	// A body that cannot be read again is sent once
+	bodies = nil
+	req, err := client.newRequest(http.MethodPut, "stacks/1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req.Body = io.NopCloser(strings.NewReader("stream"))
+	req.GetBody = nil
+	resp, err := client.do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if len(bodies) != 1 {
+		t.Errorf("expected a single attempt, got %q", bodies)
+	}
+}
+
+func TestClient_DryRunSkipsChanges(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var log strings.Builder
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, WithDryRun(true), WithLogOutput(&log))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	images := NewImageService(client)
+
+	if err := images.PullWithProgress(1, "nginx:latest", 0, func(PullProgress) {
+		t.Error("unexpected pull progress")
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := images.Tag(1, "nginx:latest", "registry.example.com/nginx", "1.25"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := images.PushWithProgress(1, "registry.example.com/nginx:1.25", 0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != nil {
+		t.Errorf("expected no push result, got %+v", result)
+	}
+	if _, err := client.DockerProxy(1); err == nil {
+		t.Error("expected the Docker proxy to be refused in dry-run mode")
+	}
+
+	if len(requests) != 0 {
+		t.Errorf("expected no requests in dry-run mode, got %v", requests)
+	}
+	if !strings.Contains(log.String(), "curl -X POST") || !strings.Contains(log.String(), "/images/create") {
+		t.Errorf("expected the skipped requests in the log output, got %q", log.String())
+	}
+}
+
+func TestClient_RequestID(t *testing.T) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(RequestIDHeader))
+		switch r.URL.Path {
+		case "/api/stacks":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message":"Unable to retrieve stacks","details":"database is locked"}`))
+		case "/api/echo":
+			w.Header().Set(RequestIDHeader, "proxy-id")
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("bad gateway\n"))
+		case "/api/details":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"details":"Invalid request payload"}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, WithMaxRetries(1), WithRetryDelay(time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if err := client.Get("status", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.Get("status", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 2 || ids[0] == "" || ids[0] == ids[1] {
+		t.Fatalf("expected a unique ID per request, got %v", ids)
+	}
+
+	ids = nil
+	err = client.Get("stacks", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an API error, got %v", err)
+	}
+	// A retry is the same request and keeps its ID
+	if len(ids) != 2 || ids[0] != ids[1] {
+		t.Fatalf("expected the retry to keep the request ID, got %v", ids)
+	}
+	if apiErr.RequestID != ids[0] || apiErr.Details != "database is locked" {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+
+	err = client.Get("echo", nil)
+	if !errors.As(err, &apiErr) || apiErr.RequestID != "proxy-id" || apiErr.Message != "HTTP 502: bad gateway" {
+		t.Errorf("expected the echoed request ID, got %v", err)
+	}
+
+	err = client.Get("details", nil)
+	if !errors.As(err, &apiErr) || apiErr.Message != "Invalid request payload" || apiErr.Details != "" {
+		t.Errorf("expected the details as message, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package portainer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers carrying the destination of a request forwarded through a
+// connection server
+const (
+	serverTargetHeader   = "X-Portainer-Cli-Target"
+	serverInsecureHeader = "X-Portainer-Cli-Insecure"
+)
+
+// serverProbeTimeout bounds the check whether a connection server listens
+const serverProbeTimeout = time.Second
+
+// hopHeaders are not forwarded by a connection server
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Connection", "Proxy-Authenticate", "Proxy-Authorization",
+	"Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// WithConnectionServer sends the requests of the client through the
+// connection server listening on the unix socket path, which keeps the
+// connections to Portainer open between short-lived processes. When no
+// server listens there the client connects directly. Clients with a custom
+// TLS configuration, and attach and exec sessions, always connect directly.
+func WithConnectionServer(socket string) ClientOption {
+	return func(c *Client) {
+		c.serverSocket = socket
+	}
+}
+
+// serverTransport forwards requests to a connection server, or sends them
+// with the direct transport when the server is not running
+type serverTransport struct {
+	socket   string
+	direct   *http.Transport
+	server   *http.Transport
+	insecure bool
+
+	probe     sync.Once
+	available bool
+}
+
+func newServerTransport(socket string, direct *http.Transport, insecure bool) *serverTransport {
+	dialer := &net.Dialer{Timeout: serverProbeTimeout}
+	return &serverTransport{
+		socket:   socket,
+		direct:   direct,
+		insecure: insecure,
+		server: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+			MaxIdleConnsPerHost: maxIdleConnsPerHost,
+			IdleConnTimeout:     idleConnTimeout,
+		},
+	}
+}
+
+func (t *serverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.probe.Do(func() {
+		conn, err := net.DialTimeout("unix", t.socket, serverProbeTimeout)
+		if err == nil {
+			conn.Close()
+			t.available = true
+		}
+	})
+	if !t.available {
+		return t.direct.RoundTrip(req)
+	}
+
+	forwarded := req.Clone(req.Context())
+	forwarded.URL = &url.URL{Scheme: "http", Host: "portainer-cli", Path: req.URL.Path, RawQuery: req.URL.RawQuery}
+	forwarded.Host = ""
+	forwarded.Header.Set(serverTargetHeader, req.URL.String())
+	if t.insecure {
+		forwarded.Header.Set(serverInsecureHeader, "true")
+	}
+	return t.server.RoundTrip(forwarded)
+}
+
+// NewConnectionServer returns the handler of a connection server: it sends
+// the requests of clients created WithConnectionServer to Portainer over
+// shared transports, so TLS connections stay open between invocations.
+// Responses are streamed back as they arrive.
+func NewConnectionServer() http.Handler {
+	return http.HandlerFunc(serveForwarded)
+}
+
+func serveForwarded(w http.ResponseWriter, r *http.Request) {
+	target, err := url.Parse(r.Header.Get(serverTargetHeader))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		http.Error(w, "missing or invalid "+serverTargetHeader+" header", http.StatusBadRequest)
+		return
+	}
+	insecure, _ := strconv.ParseBool(r.Header.Get(serverInsecureHeader))
+
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	out.URL = target
+	out.Host = target.Host
+	out.Header.Del(serverTargetHeader)
+	out.Header.Del(serverInsecureHeader)
+	for _, header := range hopHeaders {
+		out.Header.Del(header)
+	}
+	if r.ContentLength == 0 {
+		out.Body = nil
+	}
+
+	resp, err := sharedTransport(insecure).RoundTrip(out)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("connection server: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	for _, header := range hopHeaders {
+		w.Header().Del(header)
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	// Flush every chunk so streamed responses, such as events and logs,
+	// reach the client without delay
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
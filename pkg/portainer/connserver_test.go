@@ -0,0 +1,89 @@
+package portainer
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConnectionServer(t *testing.T) {
+	portainer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-KEY") != "test-key" || r.Header.Get(serverTargetHeader) != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"Version":"2.21.0","path":"` + r.URL.Path + `","query":"` + r.URL.RawQuery + `"}`))
+	}))
+	defer portainer.Close()
+
+	socket := filepath.Join(t.TempDir(), "server.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets are not available: %v", err)
+	}
+	var forwarded atomic.Int32
+	handler := NewConnectionServer()
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded.Add(1)
+		handler.ServeHTTP(w, r)
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: portainer.URL, APIKey: "test-key"}, WithConnectionServer(socket))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var result map[string]string
+	if err := client.Get("status?verbose=true", &result); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if result["path"] != "/api/status" || result["query"] != "verbose=true" {
+		t.Errorf("unexpected request at Portainer: %v", result)
+	}
+	if forwarded.Load() != 1 {
+		t.Errorf("expected the request to go through the connection server, got %d forwarded", forwarded.Load())
+	}
+}
+
+func TestConnectionServer_FallsBackToDirect(t *testing.T) {
+	portainer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Version":"2.21.0"}`))
+	}))
+	defer portainer.Close()
+
+	socket := filepath.Join(t.TempDir(), "missing.sock")
+	client, err := NewClient(&Config{URL: portainer.URL, APIKey: "test-key"}, WithConnectionServer(socket))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var result map[string]string
+	if err := client.Get("status", &result); err != nil {
+		t.Fatalf("expected a direct request without a connection server, got %v", err)
+	}
+	if client.transport() == nil {
+		t.Error("expected the direct transport to be found below the server transport")
+	}
+}
+
+func TestSharedTransport(t *testing.T) {
+	cfg := &Config{URL: "https://test.example.com", APIKey: "test-key"}
+	first, _ := NewClient(cfg)
+	second, _ := NewClient(cfg)
+	insecure, _ := NewClient(cfg, WithInsecure(true))
+
+	if first.transport() != second.transport() {
+		t.Error("expected clients with the same TLS settings to share a transport")
+	}
+	if first.transport() == insecure.transport() {
+		t.Error("expected insecure clients to use another transport")
+	}
+	if !first.transport().ForceAttemptHTTP2 || first.transport().MaxIdleConnsPerHost != maxIdleConnsPerHost {
+		t.Error("expected HTTP/2 and connection pooling to be configured")
+	}
+}
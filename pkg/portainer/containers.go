@@ -0,0 +1,1026 @@
+package portainer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type ContainerService struct {
+	client *Client
+}
+
+type Container struct {
+	Id              string            `json:"Id"`
+	Names           []string          `json:"Names"`
+	Image           string            `json:"Image"`
+	ImageID         string            `json:"ImageID"`
+	Command         string            `json:"Command"`
+	Created         int64             `json:"Created"`
+	State           string            `json:"State"`
+	Status          string            `json:"Status"`
+	Ports           []Port            `json:"Ports"`
+	Labels          map[string]string `json:"Labels"`
+	SizeRw          int64             `json:"SizeRw,omitempty"`
+	SizeRootFs      int64             `json:"SizeRootFs,omitempty"`
+	HostConfig      HostConfig        `json:"HostConfig,omitempty"`
+	NetworkSettings NetworkSettings   `json:"NetworkSettings,omitempty"`
+	Mounts          []Mount           `json:"Mounts"`
+}
+
+type ContainerDetails struct {
+	Id              string                   `json:"Id"`
+	Created         string                   `json:"Created"`
+	Path            string                   `json:"Path"`
+	Args            []string                 `json:"Args"`
+	State           ContainerState           `json:"State"`
+	Image           string                   `json:"Image"`
+	ResolvConfPath  string                   `json:"ResolvConfPath"`
+	HostnamePath    string                   `json:"HostnamePath"`
+	HostsPath       string                   `json:"HostsPath"`
+	LogPath         string                   `json:"LogPath"`
+	Name            string                   `json:"Name"`
+	RestartCount    int                      `json:"RestartCount"`
+	Driver          string                   `json:"Driver"`
+	Platform        string                   `json:"Platform"`
+	MountLabel      string                   `json:"MountLabel"`
+	ProcessLabel    string                   `json:"ProcessLabel"`
+	AppArmorProfile string                   `json:"AppArmorProfile"`
+	Config          ContainerConfig          `json:"Config"`
+	HostConfig      ContainerHostConfig      `json:"HostConfig"`
+	NetworkSettings ContainerNetworkSettings `json:"NetworkSettings"`
+	Mounts          []Mount                  `json:"Mounts"`
+	Portainer       *PortainerMetadata       `json:"Portainer,omitempty"`
+}
+
+type ContainerState struct {
+	Status     string  `json:"Status"`
+	Running    bool    `json:"Running"`
+	Paused     bool    `json:"Paused"`
+	Restarting bool    `json:"Restarting"`
+	OOMKilled  bool    `json:"OOMKilled"`
+	Dead       bool    `json:"Dead"`
+	Pid        int     `json:"Pid"`
+	ExitCode   int     `json:"ExitCode"`
+	Error      string  `json:"Error"`
+	StartedAt  string  `json:"StartedAt"`
+	FinishedAt string  `json:"FinishedAt"`
+	Health     *Health `json:"Health,omitempty"`
+}
+
+type Health struct {
+	Status        string        `json:"Status"`
+	FailingStreak int           `json:"FailingStreak"`
+	Log           []HealthCheck `json:"Log,omitempty"`
+}
+
+type HealthCheck struct {
+	Start    string `json:"Start"`
+	End      string `json:"End"`
+	ExitCode int    `json:"ExitCode"`
+	Output   string `json:"Output"`
+}
+
+type ContainerConfig struct {
+	Hostname     string              `json:"Hostname"`
+	Domainname   string              `json:"Domainname"`
+	User         string              `json:"User"`
+	AttachStdin  bool                `json:"AttachStdin"`
+	AttachStdout bool                `json:"AttachStdout"`
+	AttachStderr bool                `json:"AttachStderr"`
+	Tty          bool                `json:"Tty"`
+	OpenStdin    bool                `json:"OpenStdin"`
+	StdinOnce    bool                `json:"StdinOnce"`
+	Env          []string            `json:"Env"`
+	Cmd          []string            `json:"Cmd"`
+	Image        string              `json:"Image"`
+	Volumes      map[string]struct{} `json:"Volumes"`
+	WorkingDir   string              `json:"WorkingDir"`
+	Entrypoint   []string            `json:"Entrypoint"`
+	Labels       map[string]string   `json:"Labels"`
+}
+
+type ContainerNetworkSettings struct {
+	Bridge                 string                      `json:"Bridge"`
+	SandboxID              string                      `json:"SandboxID"`
+	HairpinMode            bool                        `json:"HairpinMode"`
+	LinkLocalIPv6Address   string                      `json:"LinkLocalIPv6Address"`
+	LinkLocalIPv6PrefixLen int                         `json:"LinkLocalIPv6PrefixLen"`
+	Ports                  map[string][]PortBinding    `json:"Ports"`
+	SandboxKey             string                      `json:"SandboxKey"`
+	SecondaryIPAddresses   []string                    `json:"SecondaryIPAddresses"`
+	SecondaryIPv6Addresses []string                    `json:"SecondaryIPv6Addresses"`
+	EndpointID             string                      `json:"EndpointID"`
+	Gateway                string                      `json:"Gateway"`
+	GlobalIPv6Address      string                      `json:"GlobalIPv6Address"`
+	GlobalIPv6PrefixLen    int                         `json:"GlobalIPv6PrefixLen"`
+	IPAddress              string                      `json:"IPAddress"`
+	IPPrefixLen            int                         `json:"IPPrefixLen"`
+	IPv6Gateway            string                      `json:"IPv6Gateway"`
+	MacAddress             string                      `json:"MacAddress"`
+	Networks               map[string]EndpointSettings `json:"Networks"`
+}
+
+type Port struct {
+	IP          string `json:"IP,omitempty"`
+	PrivatePort int    `json:"PrivatePort"`
+	PublicPort  int    `json:"PublicPort,omitempty"`
+	Type        string `json:"Type"`
+}
+
+type PortBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string `json:"HostPort"`
+}
+
+type HostConfig struct {
+	NetworkMode string `json:"NetworkMode,omitempty"`
+}
+
+// ContainerHostConfig is the host configuration of an inspected container
+type ContainerHostConfig struct {
+	RestartPolicy RestartPolicy `json:"RestartPolicy"`
+	AutoRemove    bool          `json:"AutoRemove"`
+}
+
+// RestartPolicy tells the engine when to restart a container: Name is no,
+// always, unless-stopped or on-failure, which retries MaximumRetryCount
+// times, or indefinitely when 0
+type RestartPolicy struct {
+	Name              string `json:"Name"`
+	MaximumRetryCount int    `json:"MaximumRetryCount,omitempty"`
+}
+
+// String returns the policy as given to docker run --restart, e.g.
+// "on-failure:5". An empty name is the default policy, "no".
+func (p RestartPolicy) String() string {
+	name := p.Name
+	if name == "" {
+		name = RestartPolicyNo
+	}
+	if name == RestartPolicyOnFailure && p.MaximumRetryCount > 0 {
+		return fmt.Sprintf("%s:%d", name, p.MaximumRetryCount)
+	}
+	return name
+}
+
+const (
+	RestartPolicyNo            = "no"
+	RestartPolicyAlways        = "always"
+	RestartPolicyUnlessStopped = "unless-stopped"
+	RestartPolicyOnFailure     = "on-failure"
+)
+
+// ParseRestartPolicy parses a restart policy as given to docker run
+// --restart: no, always, unless-stopped, or on-failure[:max-retries]
+func ParseRestartPolicy(s string) (RestartPolicy, error) {
+	name, retries, hasRetries := strings.Cut(s, ":")
+	switch name {
+	case RestartPolicyNo, RestartPolicyAlways, RestartPolicyUnlessStopped:
+		if hasRetries {
+			return RestartPolicy{}, fmt.Errorf("maximum retry count cannot be used with restart policy '%s'", name)
+		}
+		return RestartPolicy{Name: name}, nil
+	case RestartPolicyOnFailure:
+		policy := RestartPolicy{Name: name}
+		if hasRetries {
+			n, err := strconv.Atoi(retries)
+			if err != nil || n < 0 {
+				return RestartPolicy{}, fmt.Errorf("invalid maximum retry count: %s", retries)
+			}
+			policy.MaximumRetryCount = n
+		}
+		return policy, nil
+	}
+	return RestartPolicy{}, fmt.Errorf("invalid restart policy '%s': use no, always, unless-stopped or on-failure[:max-retries]", s)
+}
+
+type NetworkSettings struct {
+	Networks map[string]EndpointSettings `json:"Networks,omitempty"`
+}
+
+type EndpointSettings struct {
+	IPAMConfig          *EndpointIPAMConfig `json:"IPAMConfig,omitempty"`
+	Links               []string            `json:"Links"`
+	Aliases             []string            `json:"Aliases"`
+	NetworkID           string              `json:"NetworkID"`
+	EndpointID          string              `json:"EndpointID"`
+	Gateway             string              `json:"Gateway"`
+	IPAddress           string              `json:"IPAddress"`
+	IPPrefixLen         int                 `json:"IPPrefixLen"`
+	IPv6Gateway         string              `json:"IPv6Gateway"`
+	GlobalIPv6Address   string              `json:"GlobalIPv6Address"`
+	GlobalIPv6PrefixLen int                 `json:"GlobalIPv6PrefixLen"`
+	MacAddress          string              `json:"MacAddress"`
+}
+
+type EndpointIPAMConfig struct {
+	IPv4Address string `json:"IPv4Address,omitempty"`
+	IPv6Address string `json:"IPv6Address,omitempty"`
+}
+
+type Mount struct {
+	Type        string `json:"Type"`
+	Name        string `json:"Name,omitempty"`
+	Source      string `json:"Source"`
+	Destination string `json:"Destination"`
+	Driver      string `json:"Driver,omitempty"`
+	Mode        string `json:"Mode"`
+	RW          bool   `json:"RW"`
+	Propagation string `json:"Propagation"`
+}
+
+func NewContainerService(client *Client) *ContainerService {
+	return &ContainerService{client: client}
+}
+
+const (
+	HealthStatusHealthy   = "healthy"
+	HealthStatusUnhealthy = "unhealthy"
+	HealthStatusStarting  = "starting"
+	HealthStatusNone      = "none"
+)
+
+func (s *ContainerService) List(endpointID int, all bool) ([]Container, error) {
+	return s.ListWithFilters(endpointID, all, nil)
+}
+
+func (s *ContainerService) ListWithFilters(endpointID int, all bool, filters map[string][]string) ([]Container, error) {
+	return s.ListWithOptions(endpointID, ContainerListOptions{All: all, Filters: filters})
+}
+
+// ContainerListOptions controls which containers are listed and what is
+// reported about them
+type ContainerListOptions struct {
+	// All includes stopped containers
+	All bool
+	// Filters are Docker list filters, such as {"health": ["unhealthy"]}
+	Filters map[string][]string
+	// Size reports SizeRw and SizeRootFs, which makes the engine compute
+	// the disk usage of every container and slows the request down
+	Size bool
+}
+
+func (s *ContainerService) ListWithOptions(endpointID int, opts ContainerListOptions) ([]Container, error) {
+	var containers []Container
+	if err := s.client.Get(containerListPath(endpointID, opts), &containers); err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	return containers, nil
+}
+
+// Each lists containers like ListWithOptions, but decodes the response one
+// container at a time and calls fn for each, so memory stays flat on hosts
+// running thousands of containers. An error returned by fn stops the
+// listing and is returned unchanged.
+func (s *ContainerService) Each(endpointID int, opts ContainerListOptions, fn func(container *Container) error) error {
+	var fnErr error
+	err := s.client.getArray(containerListPath(endpointID, opts), func(dec *json.Decoder) error {
+		var container Container
+		if err := dec.Decode(&container); err != nil {
+			return err
+		}
+		fnErr = fn(&container)
+		return fnErr
+	})
+	if err != nil && fnErr == nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+	return err
+}
+
+func containerListPath(endpointID int, opts ContainerListOptions) string {
+	params := url.Values{}
+	if opts.All {
+		params.Set("all", "true")
+	}
+	if opts.Size {
+		params.Set("size", "true")
+	}
+	if len(opts.Filters) > 0 {
+		// Marshaling a map of string slices cannot fail
+		filtersJSON, _ := json.Marshal(opts.Filters)
+		params.Set("filters", string(filtersJSON))
+	}
+
+	path := fmt.Sprintf("endpoints/%d/docker/containers/json", endpointID)
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	return path
+}
+
+func (s *ContainerService) Inspect(endpointID int, containerID string) (*ContainerDetails, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/json", endpointID, containerID)
+
+	var container ContainerDetails
+	if err := s.client.Get(path, &container); err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	return &container, nil
+}
+
+// Logs opens the log stream of a container. The caller must close the
+// returned reader, which is nil in dry-run mode.
+func (s *ContainerService) Logs(endpointID int, containerID string, follow bool, tail int, stdout, stderr bool) (io.ReadCloser, error) {
+	params := url.Values{}
+	params.Set("stdout", fmt.Sprintf("%t", stdout))
+	params.Set("stderr", fmt.Sprintf("%t", stderr))
+	params.Set("follow", fmt.Sprintf("%t", follow))
+	if tail > 0 {
+		params.Set("tail", fmt.Sprintf("%d", tail))
+	} else {
+		params.Set("tail", "all")
+	}
+	params.Set("timestamps", "true")
+
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/logs?%s", endpointID, containerID, params.Encode())
+
+	// Followed logs stay open beyond the client timeout
+	body, err := s.client.stream(http.MethodGet, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs: %w", err)
+	}
+	return body, nil
+}
+
+func (s *ContainerService) Start(endpointID int, containerID string) error {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/start", endpointID, containerID)
+	return s.client.Post(path, nil, nil)
+}
+
+func (s *ContainerService) Stop(endpointID int, containerID string) error {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/stop", endpointID, containerID)
+	return s.client.Post(path, nil, nil)
+}
+
+func (s *ContainerService) Restart(endpointID int, containerID string) error {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/restart", endpointID, containerID)
+	return s.client.Post(path, nil, nil)
+}
+
+func (s *ContainerService) Remove(endpointID int, containerID string, force bool) error {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s", endpointID, containerID)
+	if force {
+		path += "?force=true"
+	}
+	return s.client.Delete(path)
+}
+
+// ContainerUpdateResponse is the response of a container update
+type ContainerUpdateResponse struct {
+	Warnings []string `json:"Warnings"`
+}
+
+// UpdateRestartPolicy changes the restart policy of a container in place,
+// without recreating it
+func (s *ContainerService) UpdateRestartPolicy(endpointID int, containerID string, policy RestartPolicy) ([]string, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/update", endpointID, containerID)
+	body := map[string]interface{}{"RestartPolicy": policy}
+
+	var response ContainerUpdateResponse
+	if err := s.client.Post(path, body, &response); err != nil {
+		return nil, fmt.Errorf("failed to update container: %w", err)
+	}
+	return response.Warnings, nil
+}
+
+func (s *ContainerService) Rename(endpointID int, containerID, newName string) error {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/rename?name=%s", endpointID, containerID, url.QueryEscape(newName))
+	return s.client.Post(path, nil, nil)
+}
+
+func (s *ContainerService) Pause(endpointID int, containerID string) error {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/pause", endpointID, containerID)
+	return s.client.Post(path, nil, nil)
+}
+
+func (s *ContainerService) Unpause(endpointID int, containerID string) error {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/unpause", endpointID, containerID)
+	return s.client.Post(path, nil, nil)
+}
+
+func (s *ContainerService) Kill(endpointID int, containerID, signal string) error {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/kill", endpointID, containerID)
+	if signal != "" {
+		path += "?signal=" + url.QueryEscape(signal)
+	}
+	return s.client.Post(path, nil, nil)
+}
+
+// ContainerChange is a change of the filesystem of a container relative to
+// its image
+type ContainerChange struct {
+	Path string `json:"Path"`
+	Kind int    `json:"Kind"`
+}
+
+// Kinds of ContainerChange
+const (
+	ContainerChangeModified = 0
+	ContainerChangeAdded    = 1
+	ContainerChangeDeleted  = 2
+)
+
+// KindString returns the letter docker diff shows for the change: A for
+// added, C for changed and D for deleted paths
+func (c *ContainerChange) KindString() string {
+	switch c.Kind {
+	case ContainerChangeAdded:
+		return "A"
+	case ContainerChangeDeleted:
+		return "D"
+	default:
+		return "C"
+	}
+}
+
+// Changes returns the files and directories added, changed or deleted in a
+// container relative to its image
+func (s *ContainerService) Changes(endpointID int, containerID string) ([]ContainerChange, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/changes", endpointID, containerID)
+
+	var changes []ContainerChange
+	if err := s.client.Get(path, &changes); err != nil {
+		return nil, fmt.Errorf("failed to get container changes: %w", err)
+	}
+	return changes, nil
+}
+
+// CommitOptions configures the image created by Commit
+type CommitOptions struct {
+	// Repository and Tag name the image; without a repository the image is
+	// untagged
+	Repository string
+	Tag        string
+	// Comment is the commit message and Author the author of the image
+	Comment string
+	Author  string
+	// Changes are Dockerfile instructions applied to the image, e.g.
+	// CMD ["nginx", "-g", "daemon off;"]
+	Changes []string
+	// Pause pauses the container while its filesystem is captured, so the
+	// image is consistent
+	Pause bool
+}
+
+// Commit creates an image from the filesystem and configuration of a
+// container and returns the image ID, which is empty in dry-run mode
+func (s *ContainerService) Commit(endpointID int, containerID string, opts CommitOptions) (string, error) {
+	params := url.Values{}
+	params.Set("container", containerID)
+	if opts.Repository != "" {
+		params.Set("repo", opts.Repository)
+		if opts.Tag != "" {
+			params.Set("tag", opts.Tag)
+		}
+	}
+	if opts.Comment != "" {
+		params.Set("comment", opts.Comment)
+	}
+	if opts.Author != "" {
+		params.Set("author", opts.Author)
+	}
+	for _, change := range opts.Changes {
+		params.Add("changes", change)
+	}
+	// The engine pauses containers unless told otherwise
+	params.Set("pause", strconv.FormatBool(opts.Pause))
+	path := fmt.Sprintf("endpoints/%d/docker/commit?%s", endpointID, params.Encode())
+
+	var response struct {
+		Id string `json:"Id"`
+	}
+	if err := s.client.Post(path, nil, &response); err != nil {
+		return "", fmt.Errorf("failed to commit container: %w", err)
+	}
+	return response.Id, nil
+}
+
+// ContainerPruneReport lists the containers removed by a prune and the disk
+// space that was freed
+type ContainerPruneReport struct {
+	ContainersDeleted []string `json:"ContainersDeleted"`
+	SpaceReclaimed    int64    `json:"SpaceReclaimed"`
+}
+
+func (s *ContainerService) Prune(endpointID int, filters map[string][]string) (*ContainerPruneReport, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/prune", endpointID)
+
+	if len(filters) > 0 {
+		filtersJSON, err := json.Marshal(filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal filters: %w", err)
+		}
+		path += "?filters=" + url.QueryEscape(string(filtersJSON))
+	}
+
+	var report ContainerPruneReport
+	if err := s.client.Post(path, nil, &report); err != nil {
+		return nil, fmt.Errorf("failed to prune containers: %w", err)
+	}
+	return &report, nil
+}
+
+func (s *ContainerService) Attach(endpointID int, containerID string, stdin bool) (*HijackedResponse, error) {
+	params := url.Values{}
+	params.Set("stream", "true")
+	params.Set("stdin", fmt.Sprintf("%t", stdin))
+	params.Set("stdout", "true")
+	params.Set("stderr", "true")
+
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/attach?%s", endpointID, containerID, params.Encode())
+
+	resp, err := s.client.hijack(http.MethodPost, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach to container: %w", err)
+	}
+	return resp, nil
+}
+
+func (s *ContainerService) Resize(endpointID int, containerID string, height, width int) error {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/resize?h=%d&w=%d", endpointID, containerID, height, width)
+	return s.client.Post(path, nil, nil)
+}
+
+// ExecConfig describes a command to run inside a container
+type ExecConfig struct {
+	AttachStdin  bool     `json:"AttachStdin"`
+	AttachStdout bool     `json:"AttachStdout"`
+	AttachStderr bool     `json:"AttachStderr"`
+	Tty          bool     `json:"Tty"`
+	Cmd          []string `json:"Cmd"`
+	User         string   `json:"User,omitempty"`
+	WorkingDir   string   `json:"WorkingDir,omitempty"`
+	Env          []string `json:"Env,omitempty"`
+}
+
+// CreateExec prepares a command to run in a container and returns the exec ID
+func (s *ContainerService) CreateExec(endpointID int, containerID string, config *ExecConfig) (string, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/exec", endpointID, containerID)
+
+	var response struct {
+		Id string `json:"Id"`
+	}
+	if err := s.client.Post(path, config, &response); err != nil {
+		return "", fmt.Errorf("failed to create exec: %w", err)
+	}
+	return response.Id, nil
+}
+
+func (s *ContainerService) ResizeExec(endpointID int, execID string, height, width int) error {
+	path := fmt.Sprintf("endpoints/%d/docker/exec/%s/resize?h=%d&w=%d", endpointID, execID, height, width)
+	return s.client.Post(path, nil, nil)
+}
+
+// Console opens Portainer's exec websocket for a created exec instance.
+// Portainer starts the exec and relays the terminal over the websocket,
+// which also works for agent and Edge environments.
+func (s *ContainerService) Console(endpointID int, execID string) (*WebSocketConn, error) {
+	params := url.Values{}
+	params.Set("endpointId", strconv.Itoa(endpointID))
+	params.Set("id", execID)
+
+	ws, err := s.client.DialWebSocket("websocket/exec?" + params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open console: %w", err)
+	}
+	return ws, nil
+}
+
+// RecreateOptions controls how a container is recreated
+type RecreateOptions struct {
+	// PullImage pulls the container's image reference before recreating so
+	// the new container uses the latest image for its tag
+	PullImage bool
+	// RegistryID is the Portainer registry used to authenticate the pull
+	RegistryID int
+	// Labels adds or replaces labels of the new container
+	Labels map[string]string
+	// RemoveLabels are the label keys dropped from the new container
+	RemoveLabels []string
+}
+
+// recreateInspect is the part of an inspect response needed to recreate a
+// container. Config and HostConfig are kept as raw maps so settings the CLI
+// does not model are preserved.
+type recreateInspect struct {
+	Id              string                 `json:"Id"`
+	Name            string                 `json:"Name"`
+	Config          map[string]interface{} `json:"Config"`
+	HostConfig      map[string]interface{} `json:"HostConfig"`
+	NetworkSettings struct {
+		Networks map[string]map[string]interface{} `json:"Networks"`
+	} `json:"NetworkSettings"`
+	State struct {
+		Running bool `json:"Running"`
+	} `json:"State"`
+	Mounts []struct {
+		Type        string `json:"Type"`
+		Name        string `json:"Name"`
+		Destination string `json:"Destination"`
+		RW          bool   `json:"RW"`
+	} `json:"Mounts"`
+}
+
+// Recreate replaces a container with a new one built from the same
+// configuration, host configuration, networks, and mounts, optionally
+// pulling its image first. The old container is renamed while the new one
+// is created and only removed once creation succeeded. It returns the ID of
+// the new container.
+func (s *ContainerService) Recreate(endpointID int, containerID string, opts RecreateOptions) (string, error) {
+	raw, err := s.client.getRaw(fmt.Sprintf("endpoints/%d/docker/containers/%s/json", endpointID, containerID), "")
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if raw == nil {
+		// Dry-run: the remaining requests depend on the inspect response
+		return "", nil
+	}
+
+	var current recreateInspect
+	if err := json.Unmarshal(raw, &current); err != nil {
+		return "", fmt.Errorf("failed to decode container: %w", err)
+	}
+
+	name := strings.TrimPrefix(current.Name, "/")
+	image, _ := current.Config["Image"].(string)
+	if image == "" {
+		return "", fmt.Errorf("container %s has no image reference", name)
+	}
+
+	if opts.PullImage {
+		if err := NewImageService(s.client).Pull(endpointID, image, opts.RegistryID); err != nil {
+			return "", err
+		}
+	}
+
+	if len(opts.Labels) > 0 || len(opts.RemoveLabels) > 0 {
+		current.Config["Labels"] = updateLabels(current.Config["Labels"], opts.Labels, opts.RemoveLabels)
+	}
+
+	current.keepVolumes()
+	body, networkNames := current.createBody()
+
+	backupName := name + "-old"
+	if err := s.Rename(endpointID, current.Id, backupName); err != nil {
+		return "", fmt.Errorf("failed to rename container: %w", err)
+	}
+
+	var created struct {
+		Id string `json:"Id"`
+	}
+	createPath := fmt.Sprintf("endpoints/%d/docker/containers/create?name=%s", endpointID, url.QueryEscape(name))
+	if err := s.client.Post(createPath, body, &created); err != nil {
+		if renameErr := s.Rename(endpointID, current.Id, name); renameErr != nil {
+			return "", fmt.Errorf("failed to create container: %w (the original container is left as %s)", err, backupName)
+		}
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+
+	if err := s.connectNetworks(endpointID, created.Id, &current, networkNames); err != nil {
+		return created.Id, err
+	}
+
+	if err := s.Remove(endpointID, current.Id, true); err != nil {
+		return created.Id, fmt.Errorf("failed to remove old container %s: %w", backupName, err)
+	}
+
+	if current.State.Running {
+		if err := s.Start(endpointID, created.Id); err != nil {
+			return created.Id, fmt.Errorf("failed to start container: %w", err)
+		}
+	}
+
+	return created.Id, nil
+}
+
+// CloneOptions are the settings that differ between a container and its
+// clone
+type CloneOptions struct {
+	// Name is the name of the new container
+	Name string
+	// Image replaces the image of the source container when set
+	Image string
+	// PullImage pulls the image of the clone before creating it
+	PullImage bool
+	// RegistryID is the Portainer registry used to authenticate the pull
+	RegistryID int
+	// Env adds or replaces environment variables, as KEY=VALUE
+	Env []string
+	// NoPorts drops the published host ports, which the source container
+	// may still be using
+	NoPorts bool
+	// Start starts the clone after it has been created
+	Start bool
+}
+
+// Clone creates a new container from the configuration, host configuration,
+// networks, and mounts of an existing one, with the overrides of opts. Static
+// IP and MAC addresses are not copied since they belong to the source
+// container. It returns the ID of the new container.
+func (s *ContainerService) Clone(endpointID int, containerID string, opts CloneOptions) (string, error) {
+	if opts.Name == "" {
+		return "", fmt.Errorf("a name is required for the cloned container")
+	}
+
+	raw, err := s.client.getRaw(fmt.Sprintf("endpoints/%d/docker/containers/%s/json", endpointID, containerID), "")
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if raw == nil {
+		// Dry-run: the remaining requests depend on the inspect response
+		return "", nil
+	}
+
+	var source recreateInspect
+	if err := json.Unmarshal(raw, &source); err != nil {
+		return "", fmt.Errorf("failed to decode container: %w", err)
+	}
+
+	if opts.Image != "" {
+		source.Config["Image"] = opts.Image
+	}
+	image, _ := source.Config["Image"].(string)
+	if image == "" {
+		return "", fmt.Errorf("container %s has no image reference", strings.TrimPrefix(source.Name, "/"))
+	}
+
+	if opts.PullImage {
+		if err := NewImageService(s.client).Pull(endpointID, image, opts.RegistryID); err != nil {
+			return "", err
+		}
+	}
+
+	if len(opts.Env) > 0 {
+		source.Config["Env"] = mergeEnv(source.Config["Env"], opts.Env)
+	}
+	delete(source.Config, "MacAddress")
+	if opts.NoPorts && source.HostConfig != nil {
+		delete(source.HostConfig, "PortBindings")
+		delete(source.HostConfig, "PublishAllPorts")
+	}
+	for _, settings := range source.NetworkSettings.Networks {
+		delete(settings, "IPAMConfig")
+	}
+
+	body, networkNames := source.createBody()
+
+	var created struct {
+		Id string `json:"Id"`
+	}
+	createPath := fmt.Sprintf("endpoints/%d/docker/containers/create?name=%s", endpointID, url.QueryEscape(opts.Name))
+	if err := s.client.Post(createPath, body, &created); err != nil {
+		return "", fmt.Errorf("failed to create container: %w", err)
+	}
+
+	if err := s.connectNetworks(endpointID, created.Id, &source, networkNames); err != nil {
+		return created.Id, err
+	}
+
+	if opts.Start {
+		if err := s.Start(endpointID, created.Id); err != nil {
+			return created.Id, fmt.Errorf("failed to start container: %w", err)
+		}
+	}
+
+	return created.Id, nil
+}
+
+// keepVolumes binds the volumes mounted without a bind or mount entry, such
+// as the anonymous volumes of an image's VOLUME paths, to the same
+// destinations so the new container keeps their data, like
+// docker run --volumes-from does. The old container is removed without its
+// volumes.
+func (c *recreateInspect) keepVolumes() {
+	if c.HostConfig == nil {
+		c.HostConfig = map[string]interface{}{}
+	}
+
+	covered := map[string]bool{}
+	binds, _ := c.HostConfig["Binds"].([]interface{})
+	for _, bind := range binds {
+		if parts := strings.Split(fmt.Sprint(bind), ":"); len(parts) > 1 {
+			covered[parts[1]] = true
+		}
+	}
+	mounts, _ := c.HostConfig["Mounts"].([]interface{})
+	for _, mount := range mounts {
+		if m, ok := mount.(map[string]interface{}); ok {
+			if target, _ := m["Target"].(string); target != "" {
+				covered[target] = true
+			}
+		}
+	}
+
+	for _, mount := range c.Mounts {
+		if mount.Type != "volume" || mount.Name == "" || covered[mount.Destination] {
+			continue
+		}
+		bind := mount.Name + ":" + mount.Destination
+		if !mount.RW {
+			bind += ":ro"
+		}
+		binds = append(binds, bind)
+		covered[mount.Destination] = true
+	}
+	if len(binds) > 0 {
+		c.HostConfig["Binds"] = binds
+	}
+}
+
+// createBody builds a container create request from an inspected
+// container. It returns the request and the sorted names of the container's
+// networks; only the first is attached by the request.
+func (c *recreateInspect) createBody() (map[string]interface{}, []string) {
+	// Let the new container pick its own hostname unless one was set
+	if hostname, _ := c.Config["Hostname"].(string); hostname != "" && strings.HasPrefix(c.Id, hostname) {
+		delete(c.Config, "Hostname")
+	}
+
+	networkNames := make([]string, 0, len(c.NetworkSettings.Networks))
+	for networkName := range c.NetworkSettings.Networks {
+		networkNames = append(networkNames, networkName)
+	}
+	sort.Strings(networkNames)
+
+	body := map[string]interface{}{}
+	for key, value := range c.Config {
+		body[key] = value
+	}
+	body["HostConfig"] = c.HostConfig
+
+	// Older engines accept a single network at creation; the others are
+	// connected afterwards
+	if len(networkNames) > 0 {
+		first := networkNames[0]
+		body["NetworkingConfig"] = map[string]interface{}{
+			"EndpointsConfig": map[string]interface{}{
+				first: recreateEndpointSettings(c.NetworkSettings.Networks[first], c.Id),
+			},
+		}
+	}
+
+	return body, networkNames
+}
+
+// connectNetworks connects a new container to the networks of source that
+// the create request did not attach
+func (s *ContainerService) connectNetworks(endpointID int, containerID string, source *recreateInspect, networkNames []string) error {
+	if len(networkNames) < 2 {
+		return nil
+	}
+	for _, networkName := range networkNames[1:] {
+		connect := map[string]interface{}{
+			"Container":      containerID,
+			"EndpointConfig": recreateEndpointSettings(source.NetworkSettings.Networks[networkName], source.Id),
+		}
+		path := fmt.Sprintf("endpoints/%d/docker/networks/%s/connect", endpointID, url.PathEscape(networkName))
+		if err := s.client.Post(path, connect, nil); err != nil {
+			return fmt.Errorf("failed to connect network %s: %w", networkName, err)
+		}
+	}
+	return nil
+}
+
+// mergeEnv applies KEY=VALUE overrides to a container's environment,
+// replacing variables with the same name and keeping the order
+func mergeEnv(current interface{}, overrides []string) []interface{} {
+	existing, _ := current.([]interface{})
+	merged := make([]interface{}, 0, len(existing)+len(overrides))
+	index := map[string]int{}
+	for _, entry := range existing {
+		value, ok := entry.(string)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(value, "=")
+		index[name] = len(merged)
+		merged = append(merged, value)
+	}
+	for _, override := range overrides {
+		name, _, _ := strings.Cut(override, "=")
+		if i, ok := index[name]; ok {
+			merged[i] = override
+			continue
+		}
+		index[name] = len(merged)
+		merged = append(merged, override)
+	}
+	return merged
+}
+
+// updateLabels applies label changes to the labels of an inspected
+// container
+func updateLabels(current interface{}, add map[string]string, remove []string) map[string]interface{} {
+	existing, _ := current.(map[string]interface{})
+	labels := make(map[string]interface{}, len(existing)+len(add))
+	for key, value := range existing {
+		labels[key] = value
+	}
+	for _, key := range remove {
+		delete(labels, key)
+	}
+	for key, value := range add {
+		labels[key] = value
+	}
+	return labels
+}
+
+// recreateEndpointSettings keeps the user-defined parts of a network
+// attachment and drops the state assigned by the engine
+func recreateEndpointSettings(settings map[string]interface{}, containerID string) map[string]interface{} {
+	endpoint := map[string]interface{}{}
+	for _, key := range []string{"IPAMConfig", "Links", "DriverOpts"} {
+		if value, ok := settings[key]; ok && value != nil {
+			endpoint[key] = value
+		}
+	}
+
+	// The short container ID alias is added by the engine
+	if aliases, ok := settings["Aliases"].([]interface{}); ok {
+		kept := []interface{}{}
+		for _, alias := range aliases {
+			if a, ok := alias.(string); ok && strings.HasPrefix(containerID, a) {
+				continue
+			}
+			kept = append(kept, alias)
+		}
+		endpoint["Aliases"] = kept
+	}
+
+	return endpoint
+}
+
+func (c *Container) GetName() string {
+	if len(c.Names) > 0 {
+		name := c.Names[0]
+		return strings.TrimPrefix(name, "/")
+	}
+	return c.Id[:12]
+}
+
+func (c *Container) GetShortID() string {
+	if len(c.Id) > 12 {
+		return c.Id[:12]
+	}
+	return c.Id
+}
+
+// GetShortImageID returns the abbreviated ID of the container's image
+func (c *Container) GetShortImageID() string {
+	return (&Image{Id: c.ImageID}).GetShortID()
+}
+
+func (c *Container) GetPorts() string {
+	if len(c.Ports) == 0 {
+		return "-"
+	}
+
+	var ports []string
+	for _, port := range c.Ports {
+		if port.PublicPort > 0 {
+			ports = append(ports, fmt.Sprintf("%d->%d/%s", port.PublicPort, port.PrivatePort, port.Type))
+		} else {
+			ports = append(ports, fmt.Sprintf("%d/%s", port.PrivatePort, port.Type))
+		}
+	}
+	return strings.Join(ports, ", ")
+}
+
+func (c *Container) GetStatus() string {
+	return c.Status
+}
+
+// GetHealth derives the health status from the container status text,
+// e.g. "Up 5 minutes (healthy)". Containers without a health check report "none".
+func (c *Container) GetHealth() string {
+	switch {
+	case strings.Contains(c.Status, "(healthy)"):
+		return HealthStatusHealthy
+	case strings.Contains(c.Status, "(unhealthy)"):
+		return HealthStatusUnhealthy
+	case strings.Contains(c.Status, "(health: starting)"):
+		return HealthStatusStarting
+	default:
+		return HealthStatusNone
+	}
+}
+
+// GetStackService returns the Compose or Swarm service the container
+// belongs to
+func (c *Container) GetStackService() string {
+	if service := c.Labels[ComposeServiceLabel]; service != "" {
+		return service
+	}
+	return c.Labels[SwarmServiceLabel]
+}
+
+func (c *Container) IsRunning() bool {
+	return c.State == "running"
+}
@@ -0,0 +1,510 @@
+package portainer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContainer_GetHealth(t *testing.T) {
+	tests := []struct {
+		status string
+		want   string
+	}{
+		{status: "Up 5 minutes (healthy)", want: HealthStatusHealthy},
+		{status: "Up 2 hours (unhealthy)", want: HealthStatusUnhealthy},
+		{status: "Up 3 seconds (health: starting)", want: HealthStatusStarting},
+		{status: "Up 5 minutes", want: HealthStatusNone},
+		{status: "Exited (0) 2 days ago", want: HealthStatusNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			c := Container{Status: tt.status}
+			if got := c.GetHealth(); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestContainerService_ListWithFilters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/endpoints/1/docker/containers/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.URL.Query().Get("all") != "true" {
+			t.Errorf("expected all=true, got '%s'", r.URL.Query().Get("all"))
+		}
+
+		var filters map[string][]string
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters); err != nil {
+			t.Errorf("failed to parse filters: %v", err)
+		}
+		if len(filters["health"]) != 1 || filters["health"][0] != "unhealthy" {
+			t.Errorf("expected health filter 'unhealthy', got %v", filters["health"])
+		}
+
+		containers := []Container{
+			{Id: "abc123def456789", Names: []string{"/web"}, Status: "Up 1 hour (unhealthy)"},
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(containers)
+	}))
+	defer server.Close()
+
+	cfg := &Config{
+		URL:    server.URL,
+		APIKey: "test-key",
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	containerService := NewContainerService(client)
+	containers, err := containerService.ListWithFilters(1, true, map[string][]string{"health": {"unhealthy"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(containers) != 1 {
+		t.Fatalf("expected 1 container, got %d", len(containers))
+	}
+
+	if containers[0].GetName() != "web" {
+		t.Errorf("expected name 'web', got '%s'", containers[0].GetName())
+	}
+}
+
+func TestContainerService_ListWithOptionsSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("size") != "true" {
+			t.Errorf("expected size=true, got '%s'", r.URL.Query().Get("size"))
+		}
+		if r.URL.Query().Has("all") {
+			t.Errorf("unexpected all parameter")
+		}
+		w.Write([]byte(`[{"Id": "abc123def456789", "SizeRw": 1024, "SizeRootFs": 1048576}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	containers, err := NewContainerService(client).ListWithOptions(1, ContainerListOptions{Size: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(containers) != 1 || containers[0].SizeRw != 1024 || containers[0].SizeRootFs != 1048576 {
+		t.Errorf("unexpected containers: %+v", containers)
+	}
+}
+
+func TestContainerService_Each(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("all") != "true" {
+			t.Errorf("expected all=true, got '%s'", r.URL.Query().Get("all"))
+		}
+		w.Write([]byte(`[{"Id": "a1", "Names": ["/web"]}, {"Id": "b2", "Names": ["/db"]}, {"Id": "c3", "Names": ["/cache"]}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	containerService := NewContainerService(client)
+
+	var names []string
+	err = containerService.Each(1, ContainerListOptions{All: true}, func(container *Container) error {
+		names = append(names, container.GetName())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(names, ",") != "web,db,cache" {
+		t.Errorf("unexpected containers: %v", names)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = containerService.Each(1, ContainerListOptions{All: true}, func(container *Container) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("expected the listing to stop with the callback error after 1 call, got %v after %d", err, calls)
+	}
+}
+
+func TestContainerService_Recreate(t *testing.T) {
+	var calls []string
+	var createBody map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/endpoints/1/docker/containers/web/json":
+			w.Write([]byte(`{
+				"Id": "abc123def456789",
+				"Name": "/web",
+				"Config": {"Hostname": "abc123def456", "Image": "nginx:latest", "Env": ["A=1"], "Labels": {"team": "web", "tier": "frontend"}},
+				"HostConfig": {"Binds": ["data:/data"], "RestartPolicy": {"Name": "always"}},
+				"NetworkSettings": {"Networks": {
+					"backend": {"Aliases": ["web", "abc123def456"], "IPAddress": "172.18.0.2"},
+					"frontend": {"Aliases": null}
+				}},
+				"State": {"Running": true},
+				"Mounts": [
+					{"Type": "volume", "Name": "data", "Destination": "/data", "RW": true},
+					{"Type": "volume", "Name": "3f9a0c", "Destination": "/var/cache/nginx", "RW": true}
+				]
+			}`))
+		case r.Method == http.MethodDelete:
+			if r.URL.Query().Has("v") {
+				t.Errorf("expected the old container's volumes to be kept, got %s", r.URL.RawQuery)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/api/endpoints/1/docker/images/create":
+			w.Write([]byte(`{"status":"Pulling from library/nginx"}` + "\n" + `{"status":"Status: Image is up to date"}`))
+		case r.URL.Path == "/api/endpoints/1/docker/containers/create":
+			if r.URL.Query().Get("name") != "web" {
+				t.Errorf("expected name web, got %s", r.URL.Query().Get("name"))
+			}
+			json.NewDecoder(r.Body).Decode(&createBody)
+			w.Write([]byte(`{"Id":"new123"}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	id, err := NewContainerService(client).Recreate(1, "web", RecreateOptions{
+		PullImage:    true,
+		Labels:       map[string]string{"team": "ops", "monitoring": "true"},
+		RemoveLabels: []string{"tier"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "new123" {
+		t.Errorf("expected new123, got %s", id)
+	}
+
+	expected := []string{
+		"GET /api/endpoints/1/docker/containers/web/json",
+		"POST /api/endpoints/1/docker/images/create",
+		"POST /api/endpoints/1/docker/containers/abc123def456789/rename",
+		"POST /api/endpoints/1/docker/containers/create",
+		"POST /api/endpoints/1/docker/networks/frontend/connect",
+		"DELETE /api/endpoints/1/docker/containers/abc123def456789",
+		"POST /api/endpoints/1/docker/containers/new123/start",
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("call %d: expected %s, got %s", i, expected[i], calls[i])
+		}
+	}
+
+	if _, ok := createBody["Hostname"]; ok {
+		t.Errorf("expected generated hostname to be dropped")
+	}
+	if createBody["Image"] != "nginx:latest" {
+		t.Errorf("expected image nginx:latest, got %v", createBody["Image"])
+	}
+	labels, _ := createBody["Labels"].(map[string]interface{})
+	if len(labels) != 2 || labels["team"] != "ops" || labels["monitoring"] != "true" {
+		t.Errorf("unexpected labels: %v", labels)
+	}
+	hostConfig, _ := createBody["HostConfig"].(map[string]interface{})
+	binds, _ := hostConfig["Binds"].([]interface{})
+	if len(binds) != 2 || binds[0] != "data:/data" || binds[1] != "3f9a0c:/var/cache/nginx" {
+		t.Errorf("expected binds and the anonymous volume to be preserved, got %v", hostConfig)
+	}
+
+	endpoints := createBody["NetworkingConfig"].(map[string]interface{})["EndpointsConfig"].(map[string]interface{})
+	backend, ok := endpoints["backend"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected backend network at creation, got %v", endpoints)
+	}
+	if aliases := backend["Aliases"].([]interface{}); len(aliases) != 1 || aliases[0] != "web" {
+		t.Errorf("expected only the user alias, got %v", aliases)
+	}
+	if _, ok := backend["IPAddress"]; ok {
+		t.Errorf("expected engine-assigned address to be dropped")
+	}
+}
+
+func TestContainerService_Clone(t *testing.T) {
+	var calls []string
+	var createBody map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/endpoints/1/docker/containers/web/json":
+			w.Write([]byte(`{
+				"Id": "abc123def456789",
+				"Name": "/web",
+				"Config": {"Hostname": "abc123def456", "Image": "nginx:1.24", "Env": ["A=1", "B=2"], "MacAddress": "02:42:ac:12:00:02"},
+				"HostConfig": {"Binds": ["data:/data"], "PortBindings": {"80/tcp": [{"HostPort": "8080"}]}},
+				"NetworkSettings": {"Networks": {
+					"backend": {"Aliases": ["web"], "IPAMConfig": {"IPv4Address": "172.18.0.10"}}
+				}},
+				"State": {"Running": true}
+			}`))
+		case r.URL.Path == "/api/endpoints/1/docker/containers/create":
+			if r.URL.Query().Get("name") != "web-green" {
+				t.Errorf("expected name web-green, got %s", r.URL.Query().Get("name"))
+			}
+			json.NewDecoder(r.Body).Decode(&createBody)
+			w.Write([]byte(`{"Id":"new123"}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	id, err := NewContainerService(client).Clone(1, "web", CloneOptions{
+		Name:    "web-green",
+		Image:   "nginx:1.25",
+		Env:     []string{"B=3", "C=4"},
+		NoPorts: true,
+		Start:   true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "new123" {
+		t.Errorf("expected new123, got %s", id)
+	}
+
+	expected := []string{
+		"GET /api/endpoints/1/docker/containers/web/json",
+		"POST /api/endpoints/1/docker/containers/create",
+		"POST /api/endpoints/1/docker/containers/new123/start",
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("call %d: expected %s, got %s", i, expected[i], calls[i])
+		}
+	}
+
+	if createBody["Image"] != "nginx:1.25" {
+		t.Errorf("expected image nginx:1.25, got %v", createBody["Image"])
+	}
+	env, _ := createBody["Env"].([]interface{})
+	if len(env) != 3 || env[0] != "A=1" || env[1] != "B=3" || env[2] != "C=4" {
+		t.Errorf("unexpected env: %v", env)
+	}
+	if _, ok := createBody["MacAddress"]; ok {
+		t.Errorf("expected MAC address to be dropped")
+	}
+	hostConfig, _ := createBody["HostConfig"].(map[string]interface{})
+	if _, ok := hostConfig["PortBindings"]; ok {
+		t.Errorf("expected port bindings to be dropped, got %v", hostConfig)
+	}
+	if binds, _ := hostConfig["Binds"].([]interface{}); len(binds) != 1 {
+		t.Errorf("expected binds to be preserved, got %v", hostConfig)
+	}
+	endpoints := createBody["NetworkingConfig"].(map[string]interface{})["EndpointsConfig"].(map[string]interface{})
+	if backend := endpoints["backend"].(map[string]interface{}); backend["IPAMConfig"] != nil {
+		t.Errorf("expected static IP to be dropped, got %v", backend)
+	}
+
+	if _, err := NewContainerService(client).Clone(1, "web", CloneOptions{}); err == nil {
+		t.Error("expected error without a name")
+	}
+}
+func TestContainerService_Prune(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/endpoints/1/docker/containers/prune" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if filters := r.URL.Query().Get("filters"); filters != `{"until":["24h"]}` {
+			t.Errorf("unexpected filters: %s", filters)
+		}
+		w.Write([]byte(`{"ContainersDeleted":["abc","def"],"SpaceReclaimed":4096}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	report, err := NewContainerService(client).Prune(1, map[string][]string{"until": {"24h"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.ContainersDeleted) != 2 || report.SpaceReclaimed != 4096 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestParseRestartPolicy(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    RestartPolicy
+		wantErr bool
+	}{
+		{input: "no", want: RestartPolicy{Name: "no"}},
+		{input: "unless-stopped", want: RestartPolicy{Name: "unless-stopped"}},
+		{input: "on-failure", want: RestartPolicy{Name: "on-failure"}},
+		{input: "on-failure:5", want: RestartPolicy{Name: "on-failure", MaximumRetryCount: 5}},
+		{input: "always:3", wantErr: true},
+		{input: "on-failure:x", wantErr: true},
+		{input: "sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRestartPolicy(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+			if got.String() != tt.input {
+				t.Errorf("expected %s, got %s", tt.input, got.String())
+			}
+		})
+	}
+}
+
+func TestContainerService_UpdateRestartPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/endpoints/1/docker/containers/web/update" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]RestartPolicy
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if policy := body["RestartPolicy"]; policy.Name != "on-failure" || policy.MaximumRetryCount != 3 {
+			t.Errorf("unexpected restart policy: %+v", policy)
+		}
+		w.Write([]byte(`{"Warnings":["restart policy ignored for auto-removed container"]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	warnings, err := NewContainerService(client).UpdateRestartPolicy(1, "web", RestartPolicy{Name: "on-failure", MaximumRetryCount: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected 1 warning, got %v", warnings)
+	}
+}
+
+func TestContainerService_Changes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/endpoints/1/docker/containers/web/changes" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`[{"Path":"/etc","Kind":0},{"Path":"/etc/app.conf","Kind":1},{"Path":"/tmp/cache","Kind":2}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	changes, err := NewContainerService(client).Changes(1, "web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 3 || changes[1].Path != "/etc/app.conf" || changes[1].KindString() != "A" || changes[2].KindString() != "D" {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestContainerService_Commit(t *testing.T) {
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/endpoints/1/docker/commit" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		query = r.URL.Query()
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id":"sha256:abc"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	id, err := NewContainerService(client).Commit(1, "web", CommitOptions{
+		Repository: "myrepo/web",
+		Tag:        "debug",
+		Comment:    "state before restart",
+		Changes:    []string{"ENV DEBUG=1", `CMD ["sleep", "infinity"]`},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "sha256:abc" {
+		t.Errorf("expected sha256:abc, got %s", id)
+	}
+
+	expected := map[string][]string{
+		"container": {"web"},
+		"repo":      {"myrepo/web"},
+		"tag":       {"debug"},
+		"comment":   {"state before restart"},
+		"changes":   {"ENV DEBUG=1", `CMD ["sleep", "infinity"]`},
+		"pause":     {"false"},
+	}
+	for key, values := range expected {
+		if strings.Join(query[key], "|") != strings.Join(values, "|") {
+			t.Errorf("expected %s=%v, got %v", key, values, query[key])
+		}
+	}
+	if _, ok := query["author"]; ok {
+		t.Errorf("unexpected author parameter")
+	}
+}
@@ -0,0 +1,215 @@
+package portainer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDeprecationBody is the size up to which JSON responses are read for
+// deprecation warnings; larger or streamed responses are left alone
+const maxDeprecationBody = 64 << 10
+
+// Deprecation is a notice of the server that an API route is deprecated, as
+// given by the Deprecation, Sunset, Link and Warning headers of a response or
+// the Warnings field of a Docker Engine response
+type Deprecation struct {
+	Method string
+	// Path is the path of the request, e.g. /api/stacks
+	Path string
+	// Date is when the route was deprecated, zero when not given
+	Date time.Time
+	// Sunset is when the route will be removed, zero when not given
+	Sunset time.Time
+	// Link documents the deprecation or the route replacing it
+	Link string
+	// Message is the text of a deprecation warning
+	Message string
+}
+
+// WithDeprecationHandler calls fn with the deprecation notice of every
+// response that reports its route as deprecated. fn may be called
+// concurrently by requests running in parallel.
+func WithDeprecationHandler(fn func(Deprecation)) ClientOption {
+	return func(c *Client) {
+		c.deprecationHandler = fn
+	}
+}
+
+// deprecationTransport reports the deprecation notices of the responses
+// passing through base
+type deprecationTransport struct {
+	base    http.RoundTripper
+	handler func(Deprecation)
+}
+
+func (t *deprecationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := parseDeprecation(req, resp.Header); ok {
+		t.handler(d)
+		return resp, nil
+	}
+
+	message, err := bodyDeprecation(resp)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if message != "" {
+		t.handler(Deprecation{Method: req.Method, Path: req.URL.Path, Message: message})
+	}
+	return resp, nil
+}
+
+// bodyDeprecation returns the first deprecation warning in the Warnings or
+// Warning field of a small JSON response, as the Docker Engine reports
+// deprecated options of a request through the Docker proxy. The body is
+// restored for the caller.
+func bodyDeprecation(resp *http.Response) (string, error) {
+	if resp.ContentLength <= 0 || resp.ContentLength > maxDeprecationBody {
+		return "", nil
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return "", nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var fields struct {
+		Warnings []string
+		Warning  string
+	}
+	// Responses other than objects carry no warnings
+	if json.Unmarshal(body, &fields) != nil {
+		return "", nil
+	}
+	for _, warning := range append(fields.Warnings, fields.Warning) {
+		if strings.Contains(strings.ToLower(warning), "deprecat") {
+			return warning, nil
+		}
+	}
+	return "", nil
+}
+
+// parseDeprecation returns the deprecation notice of a response, reporting
+// whether there is one. A route is deprecated when the response has a
+// Deprecation header (RFC 9745) other than "false", a Sunset header
+// (RFC 8594), or a Warning mentioning the deprecation.
+func parseDeprecation(req *http.Request, header http.Header) (Deprecation, bool) {
+	d := Deprecation{Method: req.Method, Path: req.URL.Path}
+	deprecated := false
+
+	if value := strings.TrimSpace(header.Get("Deprecation")); value != "" && !strings.EqualFold(value, "false") {
+		deprecated = true
+		d.Date = parseDeprecationDate(value)
+	}
+	if value := header.Get("Sunset"); value != "" {
+		if sunset, err := http.ParseTime(value); err == nil {
+			deprecated = true
+			d.Sunset = sunset
+		}
+	}
+	for _, value := range header.Values("Warning") {
+		if text := warningText(value); strings.Contains(strings.ToLower(text), "deprecat") {
+			deprecated = true
+			d.Message = text
+			break
+		}
+	}
+	if !deprecated {
+		return Deprecation{}, false
+	}
+
+	d.Link = deprecationLink(header.Values("Link"))
+	return d, true
+}
+
+// parseDeprecationDate parses the value of a Deprecation header: @ and a Unix
+// time, or an HTTP date in the draft versions of the header. Other values,
+// such as "true", give no date.
+func parseDeprecationDate(value string) time.Time {
+	if seconds, ok := strings.CutPrefix(value, "@"); ok {
+		if unix, err := strconv.ParseInt(seconds, 10, 64); err == nil {
+			return time.Unix(unix, 0).UTC()
+		}
+		return time.Time{}
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date
+	}
+	return time.Time{}
+}
+
+// warningText returns the text of a Warning header value, e.g. "Deprecated
+// route" for 299 - "Deprecated route", or the value itself when it has no
+// quoted text
+func warningText(value string) string {
+	start := strings.Index(value, `"`)
+	if start < 0 {
+		return strings.TrimSpace(value)
+	}
+	end := strings.Index(value[start+1:], `"`)
+	if end < 0 {
+		return strings.TrimSpace(value[start+1:])
+	}
+	return value[start+1 : start+1+end]
+}
+
+// deprecationLink returns the target of the first Link with the relation
+// deprecation, successor-version or sunset, e.g.
+// <https://docs.example.com/api>; rel="deprecation"
+func deprecationLink(values []string) string {
+	for _, value := range values {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				name, rel, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, r := range strings.Fields(strings.Trim(rel, `"`)) {
+					switch strings.ToLower(r) {
+					case "deprecation", "successor-version", "sunset":
+						return strings.Trim(target, "<>")
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// String describes the notice, e.g. "POST /api/stacks is deprecated and will
+// be removed on 2027-01-01: use /api/stacks/create (see https://...)"
+func (d Deprecation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s is deprecated", d.Method, d.Path)
+	if !d.Sunset.IsZero() {
+		fmt.Fprintf(&b, " and will be removed on %s", d.Sunset.Format("2006-01-02"))
+	}
+	if d.Message != "" {
+		fmt.Fprintf(&b, ": %s", d.Message)
+	}
+	if d.Link != "" {
+		fmt.Fprintf(&b, " (see %s)", d.Link)
+	}
+	return b.String()
+}
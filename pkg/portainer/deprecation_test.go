@@ -0,0 +1,124 @@
+package portainer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseDeprecation(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "https://portainer.example.com/api/stacks?type=2", nil)
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected Deprecation
+		ok       bool
+	}{
+		{
+			name: "deprecation with sunset and link",
+			header: http.Header{
+				"Deprecation": {"@1767225600"},
+				"Sunset":      {"Fri, 01 Jan 2027 00:00:00 GMT"},
+				"Link":        {`<https://docs.example.com/api/stacks>; rel="successor-version", <https://example.com>; rel="alternate"`},
+			},
+			expected: Deprecation{
+				Method: http.MethodPost,
+				Path:   "/api/stacks",
+				Date:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				Sunset: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+				Link:   "https://docs.example.com/api/stacks",
+			},
+			ok: true,
+		},
+		{
+			name:     "deprecation without date",
+			header:   http.Header{"Deprecation": {"true"}},
+			expected: Deprecation{Method: http.MethodPost, Path: "/api/stacks"},
+			ok:       true,
+		},
+		{
+			name:     "warning",
+			header:   http.Header{"Warning": {`299 - "Deprecated route, use /api/stacks/create"`}},
+			expected: Deprecation{Method: http.MethodPost, Path: "/api/stacks", Message: "Deprecated route, use /api/stacks/create"},
+			ok:       true,
+		},
+		{
+			name:   "unrelated warning",
+			header: http.Header{"Warning": {`199 - "Miscellaneous warning"`}},
+		},
+		{
+			name:   "not deprecated",
+			header: http.Header{"Deprecation": {"false"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ok := parseDeprecation(req, tt.header)
+			if ok != tt.ok {
+				t.Fatalf("expected ok %t, got %t", tt.ok, ok)
+			}
+			if !d.Date.Equal(tt.expected.Date) || !d.Sunset.Equal(tt.expected.Sunset) {
+				t.Errorf("expected dates %s and %s, got %s and %s", tt.expected.Date, tt.expected.Sunset, d.Date, d.Sunset)
+			}
+			d.Date, d.Sunset = tt.expected.Date, tt.expected.Sunset
+			if d != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, d)
+			}
+		})
+	}
+}
+
+func TestClient_DeprecationHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/endpoints":
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Sunset", "Fri, 01 Jan 2027 00:00:00 GMT")
+		case "/api/endpoints/1/docker/containers/create":
+			w.Write([]byte(`{"Id":"abc","Warnings":["Mounting a volume over /sys is discouraged","The kernel memory limit is deprecated"]}`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	var notices []Deprecation
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, WithDeprecationHandler(func(d Deprecation) {
+		notices = append(notices, d)
+	}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if err := client.Get("stacks", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.Get("endpoints", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var created struct{ Id string }
+	if err := client.Post("endpoints/1/docker/containers/create", map[string]string{}, &created); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.Id != "abc" {
+		t.Errorf("expected the response body to be decoded, got ID %q", created.Id)
+	}
+
+	if len(notices) != 2 {
+		t.Fatalf("expected 2 notices, got %v", notices)
+	}
+	expected := []string{
+		"GET /api/endpoints is deprecated and will be removed on 2027-01-01",
+		"POST /api/endpoints/1/docker/containers/create is deprecated: The kernel memory limit is deprecated",
+	}
+	for i, notice := range notices {
+		if got := notice.String(); got != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], got)
+		}
+	}
+}
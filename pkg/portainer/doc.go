@@ -0,0 +1,29 @@
+// Package portainer is a Go client for the Portainer API. It is the client
+// used by portainer-cli and can be imported by other programs.
+//
+// Create a Client from a Config and wrap it in the service for the API area
+// you need:
+//
+//	c, err := portainer.NewClient(&portainer.Config{
+//		URL:    "https://portainer.example.com:9443",
+//		APIKey: os.Getenv("PORTAINER_API_KEY"),
+//	})
+//	if err != nil {
+//		return err
+//	}
+//
+//	environments, err := portainer.NewEnvironmentService(c).List()
+//
+// Requests use context.Background by default. Client.WithContext returns a
+// copy of the client bound to another context, so deadlines and cancellation
+// apply to every call made through services created from it:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	containers, err := portainer.NewContainerService(c.WithContext(ctx)).List(endpointID, true)
+//
+// Errors returned by the API are *APIError values; IsNotFoundError reports
+// whether an error is a 404. Every request carries a unique X-Request-Id
+// header, which APIError.RequestID reports to find a failed request in the
+// server logs.
+package portainer
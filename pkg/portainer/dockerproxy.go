@@ -0,0 +1,78 @@
+package portainer
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+)
+
+// DockerProxy returns a handler that forwards Docker API requests, such as
+// GET /v1.45/containers/json, to the Docker API of an environment as
+// proxied by Portainer, authenticated with the credentials of the client.
+// Connections upgraded by the engine, as for attach and exec, are
+// forwarded too, and streamed responses such as logs and events are flushed
+// as they arrive.
+//
+// Anyone who can reach the handler acts with the client's credentials, so
+// it should only be served on loopback addresses or sockets only the user
+// can access. Proxying is not supported in dry-run mode, since the requests
+// of the Docker clients cannot be answered without sending them.
+func (c *Client) DockerProxy(endpointID int) (http.Handler, error) {
+	if c.dryRun {
+		return nil, fmt.Errorf("the Docker proxy is not supported in dry-run mode")
+	}
+	target, err := url.Parse(c.buildURL(fmt.Sprintf("endpoints/%d/docker", endpointID)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Portainer URL: %w", err)
+	}
+
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			if r.Out.Header.Get(RequestIDHeader) == "" {
+				r.Out.Header.Set(RequestIDHeader, newRequestID())
+			}
+			// Credentials of the local client are not passed on
+			r.Out.Header.Del("Authorization")
+			r.Out.Header.Del("X-Api-Key")
+			if c.apiKey != "" {
+				r.Out.Header.Set("X-API-KEY", c.apiKey)
+			} else if c.token != "" {
+				r.Out.Header.Set("Authorization", "Bearer "+c.token)
+			}
+		},
+		Transport:     c.proxyTransport(),
+		FlushInterval: -1,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			// Docker clients show the message of JSON error responses
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadGateway)
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"message": fmt.Sprintf("portainer proxy: %v", err),
+			})
+		},
+	}, nil
+}
+
+// proxyTransport returns a transport for the Docker proxy that only speaks
+// HTTP/1.1: the connection upgrades of attach and exec do not exist in
+// HTTP/2, and the connection server would drop their Upgrade headers
+func (c *Client) proxyTransport() *http.Transport {
+	base := c.transport()
+	if base == nil {
+		base = sharedTransport(c.insecure)
+	}
+	transport := base.Clone()
+	transport.ForceAttemptHTTP2 = false
+	transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	if transport.TLSClientConfig != nil {
+		transport.TLSClientConfig = transport.TLSClientConfig.Clone()
+	} else {
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	transport.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	return transport
+}
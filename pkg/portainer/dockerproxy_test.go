@@ -0,0 +1,151 @@
+package portainer
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_DockerProxy(t *testing.T) {
+	backend := httptest.NewServer(dockerProxyBackend(t))
+	defer backend.Close()
+
+	client, err := NewClient(&Config{URL: backend.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	handler, err := client.DockerProxy(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	proxy := httptest.NewServer(handler)
+	defer proxy.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, proxy.URL+"/v1.45/containers/json?all=1", nil)
+	req.Header.Set("X-API-Key", "local-key")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != `[{"Id":"abc"}]` {
+		t.Errorf("unexpected response: %d %s", resp.StatusCode, body)
+	}
+
+	testProxyAttach(t, proxy.URL)
+}
+
+func TestClient_DockerProxyUpgradeOverHTTP2(t *testing.T) {
+	backend := httptest.NewUnstartedServer(dockerProxyBackend(t))
+	backend.EnableHTTP2 = true
+	backend.StartTLS()
+	defer backend.Close()
+
+	client, err := NewClient(&Config{URL: backend.URL, APIKey: "test-key", Insecure: true})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	handler, err := client.DockerProxy(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	proxy := httptest.NewServer(handler)
+	defer proxy.Close()
+
+	testProxyAttach(t, proxy.URL)
+}
+
+// dockerProxyBackend serves a container list and an attach endpoint that
+// echoes a line over the upgraded connection
+func dockerProxyBackend(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/endpoints/3/docker/v1.45/containers/json":
+			if r.URL.Query().Get("all") != "1" {
+				t.Errorf("expected query to be kept, got %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`[{"Id":"abc"}]`))
+		case "/api/endpoints/3/docker/v1.45/containers/abc/attach":
+			// The engine upgrades the connection for attach
+			if r.Header.Get("Upgrade") != "tcp" {
+				t.Errorf("expected Upgrade header, got %q", r.Header.Get("Upgrade"))
+			}
+			hijacker, ok := w.(http.Hijacker)
+			if !ok {
+				t.Errorf("expected an HTTP/1.1 connection, got %s", r.Proto)
+				w.WriteHeader(http.StatusHTTPVersionNotSupported)
+				return
+			}
+			conn, buf, err := hijacker.Hijack()
+			if err != nil {
+				t.Errorf("failed to hijack: %v", err)
+				return
+			}
+			defer conn.Close()
+			buf.WriteString("HTTP/1.1 101 UPGRADED\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+			buf.Flush()
+			line, _ := buf.ReadString('\n')
+			conn.Write([]byte("echo " + line))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+// testProxyAttach attaches to a container through the proxy at proxyURL
+func testProxyAttach(t *testing.T, proxyURL string) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(proxyURL, "http://"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("POST /v1.45/containers/abc/attach?stream=1 HTTP/1.1\r\nHost: docker\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n"))
+	reader := bufio.NewReader(conn)
+	upgraded, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if upgraded.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", upgraded.StatusCode)
+	}
+	conn.Write([]byte("hello\n"))
+	if line, _ := reader.ReadString('\n'); line != "echo hello\n" {
+		t.Errorf("expected echo over the upgraded connection, got %q", line)
+	}
+}
+
+func TestClient_DockerProxyError(t *testing.T) {
+	backend := httptest.NewServer(http.NotFoundHandler())
+	backendURL := backend.URL
+	backend.Close()
+
+	client, err := NewClient(&Config{URL: backendURL, APIKey: "test-key"}, WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	handler, err := client.DockerProxy(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/_ping", nil))
+	if recorder.Code != http.StatusBadGateway {
+		t.Errorf("expected 502, got %d", recorder.Code)
+	}
+	var message map[string]string
+	if err := json.Unmarshal(recorder.Body.Bytes(), &message); err != nil || !strings.Contains(message["message"], "portainer proxy") {
+		t.Errorf("unexpected error body: %s", recorder.Body.String())
+	}
+}
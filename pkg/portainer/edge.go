@@ -0,0 +1,150 @@
+package portainer
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultEdgeCheckinInterval is the Portainer default for environments
+	// without their own check-in interval, in seconds
+	defaultEdgeCheckinInterval = 5
+	// edgeTunnelPollInterval is the delay between checks of whether the
+	// tunnel to an Edge agent is open
+	edgeTunnelPollInterval = time.Second
+)
+
+// dockerProxyPath matches the Docker proxy paths of an environment
+var dockerProxyPath = regexp.MustCompile(`/api/endpoints/(\d+)/docker/`)
+
+// EdgeOfflineError is returned for Docker requests to an Edge environment
+// whose agent has not checked in recently, so no tunnel can be opened
+type EdgeOfflineError struct {
+	Environment string
+	// LastCheckIn is zero when the agent never checked in
+	LastCheckIn time.Time
+}
+
+func (e *EdgeOfflineError) Error() string {
+	if e.LastCheckIn.IsZero() {
+		return fmt.Sprintf("edge device of environment %s is offline, it never checked in", e.Environment)
+	}
+	return fmt.Sprintf("edge device of environment %s is offline, last check-in %s ago",
+		e.Environment, time.Since(e.LastCheckIn).Round(time.Second))
+}
+
+// edgeCache remembers the environments known not to be Edge environments,
+// so server errors of their Docker proxy do not look them up every time
+type edgeCache struct {
+	mu      sync.Mutex
+	notEdge map[int]bool
+}
+
+func (e *edgeCache) isNotEdge(endpointID int) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.notEdge[endpointID]
+}
+
+func (e *edgeCache) setNotEdge(endpointID int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.notEdge[endpointID] = true
+}
+
+// awaitEdgeTunnel is called when a request that may be sent again failed
+// with a server error. The Docker proxy of an Edge environment fails until
+// the agent has opened its tunnel, which Portainer requests on the first
+// Docker request and the agent does on its next check-in. For such requests
+// awaitEdgeTunnel waits for the tunnel and reports true when the request can
+// be sent again. It returns an EdgeOfflineError when the agent has not
+// checked in recently, and false without an error for other requests.
+func (c *Client) awaitEdgeTunnel(req *http.Request) (bool, error) {
+	match := dockerProxyPath.FindStringSubmatch(req.URL.Path)
+	if match == nil || c.replayDir != "" {
+		return false, nil
+	}
+	endpointID, err := strconv.Atoi(match[1])
+	if err != nil || c.edge.isNotEdge(endpointID) {
+		return false, nil
+	}
+
+	var env Environment
+	if err := c.Get(fmt.Sprintf("endpoints/%d", endpointID), &env); err != nil {
+		return false, nil
+	}
+	if env.Type != EnvironmentTypeEdgeAgentOnDocker {
+		c.edge.setNotEdge(endpointID)
+		return false, nil
+	}
+
+	interval := time.Duration(env.EdgeCheckinInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultEdgeCheckinInterval * time.Second
+	}
+	// Portainer considers an agent up for two intervals and 20 seconds after
+	// its last check-in
+	grace := 2*interval + 20*time.Second
+
+	var lastCheckIn time.Time
+	if env.LastCheckInDate > 0 {
+		lastCheckIn = time.Unix(env.LastCheckInDate, 0)
+	}
+	if lastCheckIn.IsZero() || time.Since(lastCheckIn) > grace {
+		return false, &EdgeOfflineError{Environment: env.Name, LastCheckIn: lastCheckIn}
+	}
+
+	if c.verbose {
+		fmt.Fprintf(c.logOutput, "Waiting for the edge tunnel of environment %s\n", env.Name)
+	}
+	if err := c.pingEdge(endpointID, grace); err != nil {
+		return false, fmt.Errorf("edge device of environment %s did not open a tunnel within %s: %w", env.Name, grace, err)
+	}
+	return true, nil
+}
+
+// pingEdge pings the Docker engine of an Edge environment until it answers
+// or timeout has passed
+func (c *Client) pingEdge(endpointID int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	path := fmt.Sprintf("endpoints/%d/docker/_ping", endpointID)
+
+	for {
+		req, err := c.newRequest(http.MethodGet, path, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err == nil {
+			err = checkResponse(resp)
+			resp.Body.Close()
+			if err == nil {
+				return nil
+			}
+			// Only server errors mean the tunnel is not open yet
+			if apiErr, ok := err.(*APIError); ok && apiErr.StatusCode < 500 {
+				return err
+			}
+		}
+
+		if time.Now().Add(edgeTunnelPollInterval).After(deadline) {
+			return err
+		}
+		select {
+		case <-time.After(edgeTunnelPollInterval):
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		}
+	}
+}
+
+// isEdgeTunnelCandidate reports whether a response may come from the Docker
+// proxy of an Edge environment whose tunnel is closed
+func isEdgeTunnelCandidate(req *http.Request, resp *http.Response) bool {
+	return resp.StatusCode >= 500 && dockerProxyPath.MatchString(req.URL.Path)
+}
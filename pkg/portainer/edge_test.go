@@ -0,0 +1,119 @@
+package portainer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newEdgeTestServer(t *testing.T, lastCheckIn int64) *httptest.Server {
+	tunnelOpen := false
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/endpoints/5":
+			json.NewEncoder(w).Encode(Environment{
+				Id:                  5,
+				Name:                "edge-site",
+				Type:                EnvironmentTypeEdgeAgentOnDocker,
+				EdgeCheckinInterval: 1,
+				LastCheckInDate:     lastCheckIn,
+			})
+		case "/api/endpoints/5/docker/_ping":
+			// The agent connects on its check-in after the first request
+			if !tunnelOpen {
+				tunnelOpen = true
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		case "/api/endpoints/5/docker/version":
+			if !tunnelOpen {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				json.NewEncoder(w).Encode(map[string]string{"message": "Unable to reach the edge agent"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"Version": "24.0.7"})
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+}
+
+func TestClient_EdgeTunnel(t *testing.T) {
+	server := newEdgeTestServer(t, time.Now().Unix())
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var version map[string]string
+	if err := client.Get("endpoints/5/docker/version", &version); err != nil {
+		t.Fatalf("expected the request to succeed once the tunnel is open: %v", err)
+	}
+	if version["Version"] != "24.0.7" {
+		t.Errorf("unexpected response %v", version)
+	}
+}
+
+func TestClient_EdgeOffline(t *testing.T) {
+	server := newEdgeTestServer(t, time.Now().Add(-time.Hour).Unix())
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	err = client.Get("endpoints/5/docker/version", nil)
+	var offline *EdgeOfflineError
+	if !errors.As(err, &offline) {
+		t.Fatalf("expected an EdgeOfflineError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "edge device of environment edge-site is offline, last check-in 1h0m") {
+		t.Errorf("unexpected message: %v", err)
+	}
+}
+
+func TestClient_EdgeTunnelSafeRequestsOnly(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/api/endpoints/1":
+			json.NewEncoder(w).Encode(Environment{Id: 1, Name: "local", Type: EnvironmentTypeDockerLocal})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// A failed request that is not idempotent is not sent again
+	if err := client.Post("endpoints/1/docker/containers/create", map[string]string{"Image": "nginx"}, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+	// The environment is looked up once for requests to the same environment
+	for i := 0; i < 2; i++ {
+		if err := client.Get("endpoints/1/docker/version", nil); err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+
+	expected := []string{
+		"POST /api/endpoints/1/docker/containers/create",
+		"GET /api/endpoints/1/docker/version",
+		"GET /api/endpoints/1",
+		"GET /api/endpoints/1/docker/version",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+}
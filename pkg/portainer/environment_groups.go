@@ -0,0 +1,73 @@
+package portainer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnvironmentGroupService manages environment (endpoint) groups
+type EnvironmentGroupService struct {
+	client *Client
+}
+
+type EnvironmentGroup struct {
+	Id                 int            `json:"Id"`
+	Name               string         `json:"Name"`
+	Description        string         `json:"Description,omitempty"`
+	TagIds             []int          `json:"TagIds,omitempty"`
+	UserAccessPolicies AccessPolicies `json:"UserAccessPolicies,omitempty"`
+	TeamAccessPolicies AccessPolicies `json:"TeamAccessPolicies,omitempty"`
+}
+
+func NewEnvironmentGroupService(client *Client) *EnvironmentGroupService {
+	return &EnvironmentGroupService{client: client}
+}
+
+func (s *EnvironmentGroupService) List() ([]EnvironmentGroup, error) {
+	var groups []EnvironmentGroup
+	if err := s.client.Get("endpoint_groups", &groups); err != nil {
+		return nil, fmt.Errorf("failed to list environment groups: %w", err)
+	}
+	return groups, nil
+}
+
+func (s *EnvironmentGroupService) Get(id int) (*EnvironmentGroup, error) {
+	var group EnvironmentGroup
+	path := fmt.Sprintf("endpoint_groups/%d", id)
+	if err := s.client.Get(path, &group); err != nil {
+		return nil, fmt.Errorf("failed to get environment group %d: %w", id, err)
+	}
+	return &group, nil
+}
+
+// GetByName returns the group with the given name, ignoring case
+func (s *EnvironmentGroupService) GetByName(name string) (*EnvironmentGroup, error) {
+	groups, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		if strings.EqualFold(group.Name, name) {
+			return &group, nil
+		}
+	}
+
+	return nil, fmt.Errorf("environment group not found: %s", name)
+}
+
+// UpdateAccess changes the user and team access policies of a group, which
+// apply to all environments in it
+func (s *EnvironmentGroupService) UpdateAccess(id int, update AccessUpdate) error {
+	group, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("endpoint_groups/%d", id)
+	payload := update.payload(group.UserAccessPolicies, group.TeamAccessPolicies)
+	if err := s.client.Put(path, payload, nil); err != nil {
+		return fmt.Errorf("failed to update access to environment group %d: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package portainer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnvironmentGroupService_UpdateAccess(t *testing.T) {
+	var payload map[string]AccessPolicies
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/endpoint_groups/2" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"Id": 2, "Name": "production", "UserAccessPolicies": {"4": {"RoleId": 1}}, "TeamAccessPolicies": {"1": {"RoleId": 2}, "3": {"RoleId": 5}}}`))
+		case http.MethodPut:
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Errorf("failed to decode payload: %v", err)
+			}
+			w.Write([]byte(`{"Id": 2}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	update := AccessUpdate{Teams: map[int]*AccessPolicy{3: nil, 6: {RoleId: 4}}}
+	if err := NewEnvironmentGroupService(client).UpdateAccess(2, update); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := payload["UserAccessPolicies"]; ok {
+		t.Error("expected the user policies to be left alone")
+	}
+	expected := AccessPolicies{"1": {RoleId: 2}, "6": {RoleId: 4}}
+	if fmt.Sprint(payload["TeamAccessPolicies"]) != fmt.Sprint(expected) {
+		t.Errorf("expected team policies %v, got %v", expected, payload["TeamAccessPolicies"])
+	}
+}
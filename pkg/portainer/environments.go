@@ -1,4 +1,4 @@
-package client
+package portainer
 
 import (
 	"encoding/json"
@@ -18,11 +18,12 @@ type Environment struct {
 	GroupId             int              `json:"GroupId"`
 	Status              int              `json:"Status"`
 	Snapshots           []Snapshot       `json:"Snapshots,omitempty"`
-	UserAccessPolicies  map[string]int   `json:"UserAccessPolicies,omitempty"`
-	TeamAccessPolicies  map[string]int   `json:"TeamAccessPolicies,omitempty"`
+	UserAccessPolicies  AccessPolicies   `json:"UserAccessPolicies,omitempty"`
+	TeamAccessPolicies  AccessPolicies   `json:"TeamAccessPolicies,omitempty"`
 	EdgeID              string           `json:"EdgeID,omitempty"`
 	EdgeKey             string           `json:"EdgeKey,omitempty"`
 	EdgeCheckinInterval int              `json:"EdgeCheckinInterval,omitempty"`
+	LastCheckInDate     int64            `json:"LastCheckInDate,omitempty"`
 	TagIds              []int            `json:"TagIds,omitempty"`
 	TLSConfig           TLSConfiguration `json:"TLSConfig,omitempty"`
 	AzureCredentials    AzureCredentials `json:"AzureCredentials,omitempty"`
@@ -33,11 +34,14 @@ type Environment struct {
 
 type Snapshot struct {
 	Time                    int64           `json:"Time"`
+	DockerVersion           string          `json:"DockerVersion,omitempty"`
 	DockerSnapshotRaw       json.RawMessage `json:"DockerSnapshotRaw,omitempty"`
 	KubernetesSnapshot      json.RawMessage `json:"KubernetesSnapshot,omitempty"`
 	Swarm                   bool            `json:"Swarm"`
 	TotalCPU                int             `json:"TotalCPU"`
 	TotalMemory             int64           `json:"TotalMemory"`
+	NodeCount               int             `json:"NodeCount,omitempty"`
+	ContainerCount          int             `json:"ContainerCount"`
 	RunningContainerCount   int             `json:"RunningContainerCount"`
 	StoppedContainerCount   int             `json:"StoppedContainerCount"`
 	HealthyContainerCount   int             `json:"HealthyContainerCount"`
@@ -101,12 +105,29 @@ func NewEnvironmentService(client *Client) *EnvironmentService {
 	return &EnvironmentService{client: client}
 }
 
+// List returns all environments, fetching them page by page when the
+// server reports a total count.
 func (s *EnvironmentService) List() ([]Environment, error) {
+	return listAll(s.ListPage)
+}
+
+// ListPage returns up to limit environments starting at start, along with
+// the total number of environments reported by the server. The total is -1
+// when the server does not support pagination, in which case all
+// environments are returned.
+func (s *EnvironmentService) ListPage(start, limit int) ([]Environment, int, error) {
+	path := "endpoints"
+	if limit > 0 {
+		path = fmt.Sprintf("endpoints?start=%d&limit=%d", start, limit)
+	}
+
 	var environments []Environment
-	if err := s.client.Get("endpoints", &environments); err != nil {
-		return nil, fmt.Errorf("failed to list environments: %w", err)
+	headers, err := s.client.GetWithHeaders(path, &environments)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list environments: %w", err)
 	}
-	return environments, nil
+
+	return environments, totalCount(headers), nil
 }
 
 func (s *EnvironmentService) Get(id int) (*Environment, error) {
@@ -141,6 +162,46 @@ func (s *EnvironmentService) Delete(id int) error {
 	return nil
 }
 
+// SetUserRole gives a user access to an environment with an RBAC role,
+// replacing the user's previous role there. The access policies of other
+// users and teams are kept.
+func (s *EnvironmentService) SetUserRole(id, userID, roleID int) error {
+	return s.UpdateAccess(id, AccessUpdate{Users: map[int]*AccessPolicy{userID: {RoleId: roleID}}})
+}
+
+// UpdateAccess changes the user and team access policies of an environment
+func (s *EnvironmentService) UpdateAccess(id int, update AccessUpdate) error {
+	env, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("endpoints/%d", id)
+	payload := update.payload(env.UserAccessPolicies, env.TeamAccessPolicies)
+	if err := s.client.Put(path, payload, nil); err != nil {
+		return fmt.Errorf("failed to update access to environment %d: %w", id, err)
+	}
+	return nil
+}
+
+// Snapshot makes Portainer take a new snapshot of an environment, so its
+// status and resource counts are current
+func (s *EnvironmentService) Snapshot(id int) error {
+	path := fmt.Sprintf("endpoints/%d/snapshot", id)
+	if err := s.client.Post(path, nil, nil); err != nil {
+		return fmt.Errorf("failed to snapshot environment %d: %w", id, err)
+	}
+	return nil
+}
+
+// SnapshotAll makes Portainer take a new snapshot of every environment
+func (s *EnvironmentService) SnapshotAll() error {
+	if err := s.client.Post("endpoints/snapshot", nil, nil); err != nil {
+		return fmt.Errorf("failed to snapshot environments: %w", err)
+	}
+	return nil
+}
+
 func (env *Environment) TypeString() string {
 	switch env.Type {
 	case EnvironmentTypeDockerLocal:
@@ -162,6 +223,16 @@ func (env *Environment) TypeString() string {
 	}
 }
 
+// IsDocker reports whether the environment runs a Docker engine
+func (env *Environment) IsDocker() bool {
+	switch env.Type {
+	case EnvironmentTypeDockerLocal, EnvironmentTypeAgentOnDocker, EnvironmentTypeEdgeAgentOnDocker:
+		return true
+	default:
+		return false
+	}
+}
+
 func (env *Environment) StatusString() string {
 	switch env.Status {
 	case EnvironmentStatusUp:
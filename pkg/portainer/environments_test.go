@@ -1,12 +1,12 @@
-package client
+package portainer
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
-
-	"github.com/robversluis/portainer-cli/internal/config"
 )
 
 func TestEnvironmentService_List(t *testing.T) {
@@ -38,12 +38,12 @@ func TestEnvironmentService_List(t *testing.T) {
 	}))
 	defer server.Close()
 
-	profile := &config.Profile{
+	cfg := &Config{
 		URL:    server.URL,
 		APIKey: "test-key",
 	}
 
-	client, err := NewClient(profile)
+	client, err := NewClient(cfg)
 	if err != nil {
 		t.Fatalf("failed to create client: %v", err)
 	}
@@ -88,12 +88,12 @@ func TestEnvironmentService_Get(t *testing.T) {
 	}))
 	defer server.Close()
 
-	profile := &config.Profile{
+	cfg := &Config{
 		URL:    server.URL,
 		APIKey: "test-key",
 	}
 
-	client, err := NewClient(profile)
+	client, err := NewClient(cfg)
 	if err != nil {
 		t.Fatalf("failed to create client: %v", err)
 	}
@@ -138,12 +138,12 @@ func TestEnvironmentService_GetByName(t *testing.T) {
 	}))
 	defer server.Close()
 
-	profile := &config.Profile{
+	cfg := &Config{
 		URL:    server.URL,
 		APIKey: "test-key",
 	}
 
-	client, err := NewClient(profile)
+	client, err := NewClient(cfg)
 	if err != nil {
 		t.Fatalf("failed to create client: %v", err)
 	}
@@ -190,12 +190,12 @@ func TestEnvironmentService_Delete(t *testing.T) {
 	}))
 	defer server.Close()
 
-	profile := &config.Profile{
+	cfg := &Config{
 		URL:    server.URL,
 		APIKey: "test-key",
 	}
 
-	client, err := NewClient(profile)
+	client, err := NewClient(cfg)
 	if err != nil {
 		t.Fatalf("failed to create client: %v", err)
 	}
@@ -217,6 +217,78 @@ func TestEnvironmentService_Delete(t *testing.T) {
 	})
 }
 
+func TestEnvironmentService_SetUserRole(t *testing.T) {
+	var payload map[string]AccessPolicies
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/endpoints/1" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"Id": 1, "Name": "production", "UserAccessPolicies": {"2": {"RoleId": 1}, "7": {"RoleId": 5}}}`))
+		case http.MethodPut:
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Errorf("failed to decode payload: %v", err)
+			}
+			w.Write([]byte(`{"Id": 1}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if err := NewEnvironmentService(client).SetUserRole(1, 7, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := AccessPolicies{"2": {RoleId: 1}, "7": {RoleId: 3}}
+	if fmt.Sprint(payload["UserAccessPolicies"]) != fmt.Sprint(expected) {
+		t.Errorf("expected policies %v, got %v", expected, payload["UserAccessPolicies"])
+	}
+}
+
+func TestEnvironmentService_Snapshot(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/api/endpoints/999/snapshot" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "not found"})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	envService := NewEnvironmentService(client)
+
+	if err := envService.Snapshot(1); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := envService.SnapshotAll(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := envService.Snapshot(999); err == nil {
+		t.Error("expected error for unknown environment")
+	}
+
+	expected := []string{
+		"POST /api/endpoints/1/snapshot",
+		"POST /api/endpoints/snapshot",
+		"POST /api/endpoints/999/snapshot",
+	}
+	if fmt.Sprint(requests) != fmt.Sprint(expected) {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+}
+
 func TestEnvironment_TypeString(t *testing.T) {
 	tests := []struct {
 		envType  int
@@ -308,3 +380,60 @@ func TestEnvironment_GetLatestSnapshot(t *testing.T) {
 		}
 	})
 }
+
+func TestEnvironmentService_ListPaginated(t *testing.T) {
+	all := make([]Environment, 250)
+	for i := range all {
+		all[i] = Environment{Id: i + 1, Name: fmt.Sprintf("env-%d", i+1)}
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := start + limit
+		if end > len(all) {
+			end = len(all)
+		}
+
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(all)))
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(all[start:end])
+	}))
+	defer server.Close()
+
+	cfg := &Config{
+		URL:    server.URL,
+		APIKey: "test-key",
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	envService := NewEnvironmentService(client)
+	environments, err := envService.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(environments) != 250 {
+		t.Errorf("expected 250 environments, got %d", len(environments))
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+
+	page, total, err := envService.ListPage(10, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 250 {
+		t.Errorf("expected total 250, got %d", total)
+	}
+	if len(page) != 5 || page[0].Id != 11 {
+		t.Errorf("unexpected page: %v", page)
+	}
+}
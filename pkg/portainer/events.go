@@ -0,0 +1,105 @@
+package portainer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type EventService struct {
+	client *Client
+}
+
+// Event is a message from the Docker events stream
+type Event struct {
+	Type     string     `json:"Type"`
+	Action   string     `json:"Action"`
+	Actor    EventActor `json:"Actor"`
+	Scope    string     `json:"scope,omitempty"`
+	Time     int64      `json:"time"`
+	TimeNano int64      `json:"timeNano"`
+}
+
+type EventActor struct {
+	ID         string            `json:"ID"`
+	Attributes map[string]string `json:"Attributes,omitempty"`
+}
+
+// EventStream decodes events from an open events connection
+type EventStream struct {
+	body    io.ReadCloser
+	decoder *json.Decoder
+}
+
+func NewEventService(client *Client) *EventService {
+	return &EventService{client: client}
+}
+
+// Stream opens the events feed of an environment. Filters use the Docker
+// filter keys, e.g. {"type": {"container"}, "event": {"die"}}. In dry-run
+// mode the request is printed and a nil stream is returned.
+func (s *EventService) Stream(endpointID int, filters map[string][]string) (*EventStream, error) {
+	params := url.Values{}
+	if len(filters) > 0 {
+		filtersJSON, err := json.Marshal(filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal filters: %w", err)
+		}
+		params.Set("filters", string(filtersJSON))
+	}
+
+	path := fmt.Sprintf("endpoints/%d/docker/events", endpointID)
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	body, err := s.client.stream(http.MethodGet, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream events: %w", err)
+	}
+	if body == nil {
+		return nil, nil
+	}
+
+	return &EventStream{body: body, decoder: json.NewDecoder(body)}, nil
+}
+
+// Next blocks until the next event arrives. It returns io.EOF when the
+// stream ends.
+func (s *EventStream) Next() (*Event, error) {
+	var event Event
+	if err := s.decoder.Decode(&event); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+	return &event, nil
+}
+
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
+
+// GetTime returns when the event happened
+func (e *Event) GetTime() time.Time {
+	if e.TimeNano > 0 {
+		return time.Unix(0, e.TimeNano)
+	}
+	return time.Unix(e.Time, 0)
+}
+
+// GetActorName returns the name attribute of the actor, falling back to its
+// short ID
+func (e *Event) GetActorName() string {
+	if name := e.Actor.Attributes["name"]; name != "" {
+		return name
+	}
+	if len(e.Actor.ID) > 12 {
+		return e.Actor.ID[:12]
+	}
+	return e.Actor.ID
+}
@@ -0,0 +1,65 @@
+package portainer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEventService_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/endpoints/1/docker/events" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var filters map[string][]string
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters); err != nil {
+			t.Errorf("failed to parse filters: %v", err)
+		}
+		if len(filters["event"]) != 1 || filters["event"][0] != "die" {
+			t.Errorf("expected event filter 'die', got %v", filters["event"])
+		}
+
+		w.WriteHeader(http.StatusOK)
+		encoder := json.NewEncoder(w)
+		encoder.Encode(Event{Type: "container", Action: "die", Actor: EventActor{ID: "abc123def456789", Attributes: map[string]string{"name": "web"}}})
+		encoder.Encode(Event{Type: "container", Action: "die", Actor: EventActor{ID: "def456abc123789"}})
+	}))
+	defer server.Close()
+
+	cfg := &Config{
+		URL:    server.URL,
+		APIKey: "test-key",
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	eventService := NewEventService(client)
+	stream, err := eventService.Stream(1, map[string][]string{"event": {"die"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer stream.Close()
+
+	var names []string
+	for {
+		event, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		names = append(names, event.GetActorName())
+	}
+
+	if len(names) != 2 || names[0] != "web" || names[1] != "def456abc123" {
+		t.Errorf("expected [web def456abc123], got %v", names)
+	}
+}
@@ -0,0 +1,34 @@
+package portainer_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/robversluis/portainer-cli/pkg/portainer"
+)
+
+func Example() {
+	c, err := portainer.NewClient(&portainer.Config{
+		URL:    "https://portainer.example.com:9443",
+		APIKey: os.Getenv("PORTAINER_API_KEY"),
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	environments, err := portainer.NewEnvironmentService(c.WithContext(ctx)).List()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, env := range environments {
+		fmt.Println(env.Id, env.Name)
+	}
+}
@@ -0,0 +1,72 @@
+package portainer
+
+import (
+	"fmt"
+	"net/url"
+)
+
+type HelmService struct {
+	client *Client
+}
+
+// HelmRelease is a Helm release installed in a Kubernetes environment
+type HelmRelease struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Revision   string `json:"revision"`
+	Updated    string `json:"updated"`
+	Status     string `json:"status"`
+	Chart      string `json:"chart"`
+	AppVersion string `json:"app_version"`
+}
+
+// HelmInstallRequest describes a chart to install
+type HelmInstallRequest struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Chart     string `json:"chart"`
+	Repo      string `json:"repo"`
+	// Values is the content of a values file in YAML
+	Values string `json:"values,omitempty"`
+}
+
+func NewHelmService(client *Client) *HelmService {
+	return &HelmService{client: client}
+}
+
+// List returns the Helm releases of a Kubernetes environment, optionally
+// limited to a namespace
+func (s *HelmService) List(endpointID int, namespace string) ([]HelmRelease, error) {
+	path := fmt.Sprintf("endpoints/%d/kubernetes/helm", endpointID)
+	if namespace != "" {
+		path += "?" + url.Values{"namespace": {namespace}}.Encode()
+	}
+
+	var releases []HelmRelease
+	if err := s.client.Get(path, &releases); err != nil {
+		return nil, fmt.Errorf("failed to list helm releases: %w", err)
+	}
+	return releases, nil
+}
+
+func (s *HelmService) Install(endpointID int, req *HelmInstallRequest) (*HelmRelease, error) {
+	path := fmt.Sprintf("endpoints/%d/kubernetes/helm", endpointID)
+
+	var release HelmRelease
+	if err := s.client.Post(path, req, &release); err != nil {
+		return nil, fmt.Errorf("failed to install helm chart: %w", err)
+	}
+	return &release, nil
+}
+
+func (s *HelmService) Uninstall(endpointID int, release, namespace string) error {
+	path := fmt.Sprintf("endpoints/%d/kubernetes/helm/%s", endpointID, url.PathEscape(release))
+	if namespace != "" {
+		path += "?" + url.Values{"namespace": {namespace}}.Encode()
+	}
+
+	if err := s.client.Delete(path); err != nil {
+		return fmt.Errorf("failed to uninstall helm release: %w", err)
+	}
+	return nil
+}
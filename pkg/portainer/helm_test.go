@@ -0,0 +1,61 @@
+package portainer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHelmService(t *testing.T) {
+	var installed HelmInstallRequest
+	var uninstalled string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/endpoints/3/kubernetes/helm":
+			if r.URL.Query().Get("namespace") != "web" {
+				t.Errorf("expected namespace 'web', got '%s'", r.URL.Query().Get("namespace"))
+			}
+			json.NewEncoder(w).Encode([]HelmRelease{{Name: "nginx", Namespace: "web", Status: "deployed"}})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/endpoints/3/kubernetes/helm":
+			json.NewDecoder(r.Body).Decode(&installed)
+			json.NewEncoder(w).Encode(HelmRelease{Name: installed.Name, Status: "deployed"})
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/endpoints/3/kubernetes/helm/nginx":
+			uninstalled = r.URL.Query().Get("namespace")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	helmService := NewHelmService(client)
+
+	releases, err := helmService.List(3, "web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(releases) != 1 || releases[0].Name != "nginx" {
+		t.Errorf("unexpected releases: %+v", releases)
+	}
+
+	release, err := helmService.Install(3, &HelmInstallRequest{Name: "nginx", Chart: "nginx", Repo: "https://charts.example.com", Values: "replicaCount: 2\n"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if release.Status != "deployed" || installed.Values != "replicaCount: 2\n" {
+		t.Errorf("unexpected install: %+v (request %+v)", release, installed)
+	}
+
+	if err := helmService.Uninstall(3, "nginx", "web"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if uninstalled != "web" {
+		t.Errorf("expected uninstall in namespace 'web', got '%s'", uninstalled)
+	}
+}
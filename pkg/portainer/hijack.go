@@ -0,0 +1,143 @@
+package portainer
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const hijackDialTimeout = 30 * time.Second
+
+// HijackedResponse is a raw bidirectional connection obtained by upgrading
+// an HTTP request, as used by the Docker attach endpoint.
+type HijackedResponse struct {
+	Conn   net.Conn
+	Reader *bufio.Reader
+}
+
+func (h *HijackedResponse) Close() error {
+	return h.Conn.Close()
+}
+
+// CloseWrite half-closes the connection so the server sees EOF on stdin
+// while output can still be read.
+func (h *HijackedResponse) CloseWrite() error {
+	if cw, ok := h.Conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return nil
+}
+
+func (c *Client) hijack(method, path string) (*HijackedResponse, error) {
+	req, err := c.newRequest(method, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+
+	if c.dryRun {
+		fmt.Fprintln(c.logOutput, c.generateCurlCommand(req))
+		return nil, nil
+	}
+
+	if c.verbose {
+		fmt.Fprintf(c.logOutput, "%s %s (upgrade, request ID %s)\n", req.Method, req.URL.String(), RequestID(req))
+	}
+
+	conn, err := c.dial(req.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols && resp.StatusCode != http.StatusOK {
+		defer conn.Close()
+		return nil, checkResponse(resp)
+	}
+
+	return &HijackedResponse{Conn: conn, Reader: reader}, nil
+}
+
+func (c *Client) dial(u *url.URL) (net.Conn, error) {
+	if c.replayDir != "" {
+		return nil, ErrReplayUnsupported
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		if u.Scheme == "https" {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: hijackDialTimeout}
+	if u.Scheme != "https" {
+		return dialer.DialContext(c.ctx, "tcp", host)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if transport := c.transport(); transport != nil && transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = u.Hostname()
+	}
+	// The upgrade is an HTTP/1.1 exchange, even when the transport of the
+	// client negotiates HTTP/2
+	tlsConfig.NextProtos = []string{"http/1.1"}
+
+	tlsDialer := &tls.Dialer{NetDialer: dialer, Config: tlsConfig}
+	return tlsDialer.DialContext(c.ctx, "tcp", host)
+}
+
+// StdCopy demultiplexes a Docker stream that carries stdout and stderr
+// frames with an 8-byte header, as produced for containers without a TTY.
+func StdCopy(stdout, stderr io.Writer, src io.Reader) error {
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(src, header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+
+		var dst io.Writer
+		switch header[0] {
+		case 0, 1:
+			dst = stdout
+		case 2:
+			dst = stderr
+		default:
+			return fmt.Errorf("unexpected stream type %d", header[0])
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		if _, err := io.CopyN(dst, src, size); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
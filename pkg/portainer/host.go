@@ -0,0 +1,133 @@
+package portainer
+
+import (
+	"fmt"
+)
+
+type HostService struct {
+	client *Client
+}
+
+// DockerInfo is the subset of the Docker engine /info response shown by the CLI
+type DockerInfo struct {
+	Name              string           `json:"Name"`
+	OperatingSystem   string           `json:"OperatingSystem"`
+	OSType            string           `json:"OSType"`
+	Architecture      string           `json:"Architecture"`
+	KernelVersion     string           `json:"KernelVersion"`
+	ServerVersion     string           `json:"ServerVersion"`
+	NCPU              int              `json:"NCPU"`
+	MemTotal          int64            `json:"MemTotal"`
+	Driver            string           `json:"Driver"`
+	DockerRootDir     string           `json:"DockerRootDir"`
+	Containers        int              `json:"Containers"`
+	ContainersRunning int              `json:"ContainersRunning"`
+	ContainersPaused  int              `json:"ContainersPaused"`
+	ContainersStopped int              `json:"ContainersStopped"`
+	Images            int              `json:"Images"`
+	Swarm             *DockerSwarmInfo `json:"Swarm,omitempty"`
+}
+
+type DockerSwarmInfo struct {
+	NodeID           string `json:"NodeID"`
+	LocalNodeState   string `json:"LocalNodeState"`
+	ControlAvailable bool   `json:"ControlAvailable"`
+}
+
+// DockerVersion is the subset of the Docker engine /version response shown by
+// the CLI
+type DockerVersion struct {
+	Version       string `json:"Version"`
+	APIVersion    string `json:"ApiVersion"`
+	Os            string `json:"Os"`
+	Arch          string `json:"Arch"`
+	KernelVersion string `json:"KernelVersion"`
+}
+
+// AgentHostInfo is the hardware information reported by the Portainer agent
+type AgentHostInfo struct {
+	PCIDevices    []PCIDevice    `json:"PCIDevices"`
+	PhysicalDisks []PhysicalDisk `json:"PhysicalDisks"`
+}
+
+type PCIDevice struct {
+	Vendor string `json:"Vendor"`
+	Name   string `json:"Name"`
+}
+
+type PhysicalDisk struct {
+	Vendor string `json:"Vendor"`
+	Size   int64  `json:"Size"`
+}
+
+// HostInfo combines the engine and agent views of an environment's host
+type HostInfo struct {
+	Engine *DockerInfo    `json:"Engine"`
+	Agent  *AgentHostInfo `json:"Agent,omitempty"`
+}
+
+func NewHostService(client *Client) *HostService {
+	return &HostService{client: client}
+}
+
+// Info returns the Docker engine details of the host and, for agent
+// environments, its hardware information. Agent details are omitted when the
+// environment is not served by an agent.
+func (s *HostService) Info(endpointID int) (*HostInfo, error) {
+	var engine DockerInfo
+	if err := s.client.Get(fmt.Sprintf("endpoints/%d/docker/info", endpointID), &engine); err != nil {
+		return nil, fmt.Errorf("failed to get host info: %w", err)
+	}
+
+	info := &HostInfo{Engine: &engine}
+
+	var agent AgentHostInfo
+	path := fmt.Sprintf("endpoints/%d/docker/v%d/host/info", endpointID, agentAPIVersion)
+	if err := s.client.Get(path, &agent); err != nil {
+		if !IsNotFoundError(err) {
+			return nil, fmt.Errorf("failed to get agent host info: %w", err)
+		}
+	} else {
+		info.Agent = &agent
+	}
+
+	return info, nil
+}
+
+// Version returns the version of the Docker engine of an environment
+func (s *HostService) Version(endpointID int) (*DockerVersion, error) {
+	var version DockerVersion
+	if err := s.client.Get(fmt.Sprintf("endpoints/%d/docker/version", endpointID), &version); err != nil {
+		return nil, fmt.Errorf("failed to get engine version: %w", err)
+	}
+	return &version, nil
+}
+
+// DiskUsage is the engine's view of the disk space used by images,
+// containers and volumes, as reported by "docker system df -v"
+type DiskUsage struct {
+	LayersSize int64           `json:"LayersSize"`
+	Images     []Image         `json:"Images"`
+	Containers []Container     `json:"Containers"`
+	Volumes    []VolumeDetails `json:"Volumes"`
+}
+
+// DiskUsage returns the disk usage of an environment's Docker engine. The
+// engine computes the size of every container and volume, so the request
+// can be slow on busy hosts.
+func (s *HostService) DiskUsage(endpointID int) (*DiskUsage, error) {
+	var usage DiskUsage
+	if err := s.client.Get(fmt.Sprintf("endpoints/%d/docker/system/df", endpointID), &usage); err != nil {
+		return nil, fmt.Errorf("failed to get disk usage: %w", err)
+	}
+	return &usage, nil
+}
+
+// Browse lists a directory of the host filesystem through the Portainer agent
+func (s *HostService) Browse(endpointID int, path string) ([]FileInfo, error) {
+	files, err := s.client.browseList(endpointID, "", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to browse host: %w", err)
+	}
+	return files, nil
+}
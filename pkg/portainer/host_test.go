@@ -0,0 +1,101 @@
+package portainer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHostService_Info(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/endpoints/1/docker/info", "/api/endpoints/2/docker/info":
+			json.NewEncoder(w).Encode(DockerInfo{Name: "node1", ServerVersion: "24.0.7", NCPU: 4})
+		case "/api/endpoints/1/docker/v2/host/info":
+			json.NewEncoder(w).Encode(AgentHostInfo{PhysicalDisks: []PhysicalDisk{{Vendor: "ATA", Size: 1 << 30}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	hostService := NewHostService(client)
+
+	info, err := hostService.Info(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Engine.ServerVersion != "24.0.7" || info.Engine.NCPU != 4 {
+		t.Errorf("unexpected engine info: %+v", info.Engine)
+	}
+	if info.Agent == nil || len(info.Agent.PhysicalDisks) != 1 {
+		t.Errorf("expected agent info, got %+v", info.Agent)
+	}
+
+	// Environments without an agent only report engine details
+	info, err = hostService.Info(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Agent != nil {
+		t.Errorf("expected no agent info, got %+v", info.Agent)
+	}
+}
+
+func TestHostService_Version(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/endpoints/1/docker/version" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"Version":"24.0.7","ApiVersion":"1.43","Os":"linux","Arch":"amd64"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	version, err := NewHostService(client).Version(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version.Version != "24.0.7" || version.APIVersion != "1.43" {
+		t.Errorf("unexpected version: %+v", version)
+	}
+}
+
+func TestHostService_DiskUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/endpoints/1/docker/system/df" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"LayersSize": 1024, "Images": [{"Id": "sha256:abc", "Size": 512}],
+			"Containers": [{"Id": "c1", "ImageID": "sha256:abc", "SizeRw": 8}],
+			"Volumes": [{"Name": "data", "UsageData": {"Size": 64, "RefCount": 0}}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	usage, err := NewHostService(client).DiskUsage(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.LayersSize != 1024 || len(usage.Images) != 1 || len(usage.Containers) != 1 || len(usage.Volumes) != 1 {
+		t.Fatalf("unexpected disk usage: %+v", usage)
+	}
+	if usage.Volumes[0].UsageData == nil || usage.Volumes[0].UsageData.Size != 64 {
+		t.Errorf("unexpected volume usage: %+v", usage.Volumes[0].UsageData)
+	}
+}
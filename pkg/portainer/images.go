@@ -0,0 +1,544 @@
+package portainer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type ImageService struct {
+	client *Client
+}
+
+type Image struct {
+	Id            string            `json:"Id"`
+	RepoTags      []string          `json:"RepoTags"`
+	RepoDigests   []string          `json:"RepoDigests"`
+	Parent        string            `json:"Parent"`
+	Comment       string            `json:"Comment"`
+	Created       int64             `json:"Created"`
+	Container     string            `json:"Container"`
+	DockerVersion string            `json:"DockerVersion"`
+	Author        string            `json:"Author"`
+	Architecture  string            `json:"Architecture"`
+	Os            string            `json:"Os"`
+	Size          int64             `json:"Size"`
+	VirtualSize   int64             `json:"VirtualSize"`
+	Labels        map[string]string `json:"Labels"`
+}
+
+type ImageDetails struct {
+	Id              string                 `json:"Id"`
+	RepoTags        []string               `json:"RepoTags"`
+	RepoDigests     []string               `json:"RepoDigests"`
+	Parent          string                 `json:"Parent"`
+	Comment         string                 `json:"Comment"`
+	Created         string                 `json:"Created"`
+	Container       string                 `json:"Container"`
+	ContainerConfig *ContainerConfig       `json:"ContainerConfig"`
+	DockerVersion   string                 `json:"DockerVersion"`
+	Author          string                 `json:"Author"`
+	Config          *ContainerConfig       `json:"Config"`
+	Architecture    string                 `json:"Architecture"`
+	Os              string                 `json:"Os"`
+	Size            int64                  `json:"Size"`
+	VirtualSize     int64                  `json:"VirtualSize"`
+	GraphDriver     GraphDriver            `json:"GraphDriver"`
+	RootFS          RootFS                 `json:"RootFS"`
+	Metadata        map[string]interface{} `json:"Metadata"`
+}
+
+type GraphDriver struct {
+	Name string            `json:"Name"`
+	Data map[string]string `json:"Data"`
+}
+
+type RootFS struct {
+	Type   string   `json:"Type"`
+	Layers []string `json:"Layers"`
+}
+
+// ImageHistoryItem is a layer entry of an image's build history
+type ImageHistoryItem struct {
+	Id        string   `json:"Id"`
+	Created   int64    `json:"Created"`
+	CreatedBy string   `json:"CreatedBy"`
+	Tags      []string `json:"Tags"`
+	Size      int64    `json:"Size"`
+	Comment   string   `json:"Comment"`
+}
+
+// ImagePruneReport lists the images removed by a prune and the disk space
+// that was freed
+type ImagePruneReport struct {
+	ImagesDeleted  []ImageDeleteItem `json:"ImagesDeleted"`
+	SpaceReclaimed int64             `json:"SpaceReclaimed"`
+}
+
+// ImageDeleteItem is a tag that was removed or an image that was deleted
+type ImageDeleteItem struct {
+	Untagged string `json:"Untagged,omitempty"`
+	Deleted  string `json:"Deleted,omitempty"`
+}
+
+// DistributionInspect is the registry manifest information of an image
+// reference as resolved by the Docker engine
+type DistributionInspect struct {
+	Descriptor DistributionDescriptor `json:"Descriptor"`
+}
+
+type DistributionDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type ImagePullRequest struct {
+	Image    string `json:"Image"`
+	Registry string `json:"Registry,omitempty"`
+}
+
+type Registry struct {
+	Id                      int                 `json:"Id"`
+	Type                    int                 `json:"Type"`
+	Name                    string              `json:"Name"`
+	URL                     string              `json:"URL"`
+	Authentication          bool                `json:"Authentication"`
+	Username                string              `json:"Username"`
+	Password                string              `json:"Password,omitempty"`
+	RegistryAccesses        *RegistryAccesses   `json:"RegistryAccesses,omitempty"`
+	Gitlab                  *GitlabRegistryData `json:"Gitlab,omitempty"`
+	Quay                    *QuayRegistryData   `json:"Quay,omitempty"`
+	Ecr                     *EcrRegistryData    `json:"Ecr,omitempty"`
+	ManagementConfiguration *ManagementConfig   `json:"ManagementConfiguration,omitempty"`
+}
+
+type RegistryAccesses struct {
+	UserAccessPolicies map[int]RegistryAccessPolicy `json:"UserAccessPolicies"`
+	TeamAccessPolicies map[int]RegistryAccessPolicy `json:"TeamAccessPolicies"`
+	Namespaces         []string                     `json:"Namespaces"`
+}
+
+type RegistryAccessPolicy struct {
+	RoleId int `json:"RoleId"`
+}
+
+type GitlabRegistryData struct {
+	ProjectId   int    `json:"ProjectId"`
+	InstanceURL string `json:"InstanceURL"`
+	ProjectPath string `json:"ProjectPath"`
+}
+
+type EcrRegistryData struct {
+	Region string `json:"Region"`
+}
+
+type QuayRegistryData struct {
+	UseOrganisation  bool   `json:"UseOrganisation"`
+	OrganisationName string `json:"OrganisationName"`
+}
+
+type ManagementConfig struct {
+	Type           int              `json:"Type"`
+	Authentication bool             `json:"Authentication"`
+	Username       string           `json:"Username"`
+	Password       string           `json:"Password,omitempty"`
+	TLSConfig      TLSConfiguration `json:"TLSConfig"`
+}
+
+const (
+	RegistryTypeQuay      = 1
+	RegistryTypeAzure     = 2
+	RegistryTypeCustom    = 3
+	RegistryTypeGitlab    = 4
+	RegistryTypeProGet    = 5
+	RegistryTypeDockerHub = 6
+	RegistryTypeECR       = 7
+)
+
+func NewImageService(client *Client) *ImageService {
+	return &ImageService{client: client}
+}
+
+func (s *ImageService) List(endpointID int) ([]Image, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/images/json", endpointID)
+
+	var images []Image
+	if err := s.client.Get(path, &images); err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	return images, nil
+}
+
+func (s *ImageService) Inspect(endpointID int, imageID string) (*ImageDetails, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/images/%s/json", endpointID, url.PathEscape(imageID))
+
+	var image ImageDetails
+	if err := s.client.Get(path, &image); err != nil {
+		return nil, fmt.Errorf("failed to inspect image: %w", err)
+	}
+	return &image, nil
+}
+
+// History returns the layers of an image, newest first
+func (s *ImageService) History(endpointID int, imageID string) ([]ImageHistoryItem, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/images/%s/history", endpointID, url.PathEscape(imageID))
+
+	var history []ImageHistoryItem
+	if err := s.client.Get(path, &history); err != nil {
+		return nil, fmt.Errorf("failed to get image history: %w", err)
+	}
+	return history, nil
+}
+
+// RegistryDigest asks the Docker engine of an environment to resolve an image
+// reference against its registry and returns the current manifest digest
+func (s *ImageService) RegistryDigest(endpointID int, imageName string) (string, error) {
+	return s.RegistryDigestWithAuth(endpointID, imageName, 0)
+}
+
+// RegistryDigestWithAuth resolves an image reference like RegistryDigest,
+// authenticating with the credentials of a Portainer registry when
+// registryID is not 0, for private repositories
+func (s *ImageService) RegistryDigestWithAuth(endpointID int, imageName string, registryID int) (string, error) {
+	segments := strings.Split(imageName, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	path := fmt.Sprintf("endpoints/%d/docker/distribution/%s/json", endpointID, strings.Join(segments, "/"))
+
+	var inspect DistributionInspect
+	if registryID == 0 {
+		if err := s.client.Get(path, &inspect); err != nil {
+			return "", fmt.Errorf("failed to look up %s in registry: %w", imageName, err)
+		}
+		return inspect.Descriptor.Digest, nil
+	}
+
+	req, err := s.client.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+	// Portainer replaces the registry ID with the credentials of the registry
+	auth, err := json.Marshal(map[string]int{"registryId": registryID})
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Registry-Auth", base64.URLEncoding.EncodeToString(auth))
+
+	if s.client.dryRun {
+		fmt.Fprintln(s.client.logOutput, s.client.generateCurlCommand(req))
+		return "", nil
+	}
+
+	resp, err := s.client.do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up %s in registry: %w", imageName, err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return "", fmt.Errorf("failed to look up %s in registry: %w", imageName, err)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&inspect); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return inspect.Descriptor.Digest, nil
+}
+
+// PullProgress is a progress message of an image pull, as reported by the
+// Docker engine
+type PullProgress struct {
+	// Status is e.g. "Downloading", "Pull complete" or "Digest: sha256:..."
+	Status string `json:"status"`
+	// ID is the layer the status refers to, or the tag for "Pulling from"
+	ID             string `json:"id,omitempty"`
+	ProgressDetail struct {
+		Current int64 `json:"current,omitempty"`
+		Total   int64 `json:"total,omitempty"`
+	} `json:"progressDetail"`
+	Error string `json:"error,omitempty"`
+}
+
+func (s *ImageService) Pull(endpointID int, imageName string, registryID int) error {
+	return s.PullWithProgress(endpointID, imageName, registryID, nil)
+}
+
+// PullWithProgress pulls an image like Pull and calls fn, when not nil, with
+// every progress message of the engine
+func (s *ImageService) PullWithProgress(endpointID int, imageName string, registryID int, fn func(PullProgress)) error {
+	path := fmt.Sprintf("endpoints/%d/docker/images/create?fromImage=%s", endpointID, url.QueryEscape(imageName))
+
+	if registryID > 0 {
+		path += fmt.Sprintf("&X-Registry-Auth=%d", registryID)
+	}
+
+	req, err := s.client.newRequest(http.MethodPost, path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+
+	// The engine reports progress as a JSON stream and only finishes the
+	// pull once the stream has been read to the end
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var message PullProgress
+		if err := decoder.Decode(&message); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to read pull progress: %w", err)
+		}
+		if message.Error != "" {
+			return fmt.Errorf("failed to pull image: %s", message.Error)
+		}
+		if fn != nil {
+			fn(message)
+		}
+	}
+}
+
+// Save opens the archive of an image as written by docker save: a tar of
+// the image layers and configuration, in the OCI image layout with Docker
+// 25 and later. The caller must close the returned reader, which is nil in
+// dry-run mode.
+func (s *ImageService) Save(endpointID int, imageName string) (io.ReadCloser, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/images/%s/get", endpointID, url.PathEscape(imageName))
+
+	body, err := s.client.stream(http.MethodGet, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save image: %w", err)
+	}
+	return body, nil
+}
+
+func (s *ImageService) Remove(endpointID int, imageID string, force bool) error {
+	path := fmt.Sprintf("endpoints/%d/docker/images/%s?force=%t", endpointID, url.PathEscape(imageID), force)
+
+	if err := s.client.Delete(path); err != nil {
+		return fmt.Errorf("failed to remove image: %w", err)
+	}
+	return nil
+}
+
+func (s *ImageService) Tag(endpointID int, imageID, repo, tag string) error {
+	path := fmt.Sprintf("endpoints/%d/docker/images/%s/tag?repo=%s&tag=%s",
+		endpointID, url.PathEscape(imageID), url.QueryEscape(repo), url.QueryEscape(tag))
+
+	req, err := s.client.newRequest(http.MethodPost, path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to tag image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}
+
+// PushProgress is a progress message of an image push. Its last message
+// carries the result of the push in Aux.
+type PushProgress struct {
+	PullProgress
+	Aux *PushResult `json:"aux,omitempty"`
+}
+
+// PushResult is the tag, manifest digest and size of a pushed image
+type PushResult struct {
+	Tag    string `json:"Tag"`
+	Digest string `json:"Digest"`
+	Size   int64  `json:"Size"`
+}
+
+func (s *ImageService) Push(endpointID int, imageName string, registryID int) error {
+	_, err := s.PushWithProgress(endpointID, imageName, registryID, nil)
+	return err
+}
+
+// PushWithProgress pushes an image like Push and calls fn, when not nil,
+// with every progress message of the engine. It returns the pushed digest,
+// or nil when the engine did not report it.
+func (s *ImageService) PushWithProgress(endpointID int, imageName string, registryID int, fn func(PushProgress)) (*PushResult, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/images/%s/push", endpointID, url.PathEscape(imageName))
+
+	if registryID > 0 {
+		path += fmt.Sprintf("?X-Registry-Auth=%d", registryID)
+	}
+
+	req, err := s.client.newRequest(http.MethodPost, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to push image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	// Like pulls, pushes report errors such as denied access in the stream
+	// and only finish once it has been read to the end
+	var result *PushResult
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var message PushProgress
+		if err := decoder.Decode(&message); err != nil {
+			if err == io.EOF {
+				return result, nil
+			}
+			return nil, fmt.Errorf("failed to read push progress: %w", err)
+		}
+		if message.Error != "" {
+			return nil, fmt.Errorf("failed to push image: %s", message.Error)
+		}
+		if message.Aux != nil {
+			result = message.Aux
+		}
+		if fn != nil {
+			fn(message)
+		}
+	}
+}
+
+// Prune removes unused images matching the Docker prune filters, such as
+// dangling=false to remove all unused images and until=168h to keep recent
+// ones. Docker only removes dangling images unless dangling=false is given.
+func (s *ImageService) Prune(endpointID int, filters map[string][]string) (*ImagePruneReport, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/images/prune", endpointID)
+
+	if len(filters) > 0 {
+		filtersJSON, err := json.Marshal(filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal filters: %w", err)
+		}
+		path += "?filters=" + url.QueryEscape(string(filtersJSON))
+	}
+
+	var report ImagePruneReport
+	if err := s.client.Post(path, nil, &report); err != nil {
+		return nil, fmt.Errorf("failed to prune images: %w", err)
+	}
+	return &report, nil
+}
+
+// DeletedCount returns the number of deleted images, not counting removed
+// tags
+func (r *ImagePruneReport) DeletedCount() int {
+	count := 0
+	for _, item := range r.ImagesDeleted {
+		if item.Deleted != "" {
+			count++
+		}
+	}
+	return count
+}
+
+func (image *Image) GetShortID() string {
+	if strings.HasPrefix(image.Id, "sha256:") {
+		return image.Id[7:19]
+	}
+	if len(image.Id) > 12 {
+		return image.Id[:12]
+	}
+	return image.Id
+}
+
+// HasDigest reports whether the image was pulled from repository with the
+// given manifest digest
+func (image *Image) HasDigest(repository, digest string) bool {
+	for _, repoDigest := range image.RepoDigests {
+		if repoDigest == repository+"@"+digest {
+			return true
+		}
+	}
+	return false
+}
+
+func (image *Image) GetPrimaryTag() string {
+	if len(image.RepoTags) > 0 && image.RepoTags[0] != "<none>:<none>" {
+		return image.RepoTags[0]
+	}
+	if len(image.RepoDigests) > 0 {
+		return image.RepoDigests[0]
+	}
+	return "<none>"
+}
+
+func (image *Image) GetRepository() string {
+	tag := image.GetPrimaryTag()
+	if tag == "<none>" {
+		return "<none>"
+	}
+	parts := strings.Split(tag, ":")
+	if len(parts) > 0 {
+		return parts[0]
+	}
+	return tag
+}
+
+func (image *Image) GetTag() string {
+	tag := image.GetPrimaryTag()
+	if tag == "<none>" {
+		return "<none>"
+	}
+	parts := strings.Split(tag, ":")
+	if len(parts) > 1 {
+		return parts[1]
+	}
+	return "latest"
+}
+
+// GetShortID returns the abbreviated layer ID. Layers built elsewhere are
+// reported by Docker as "<missing>".
+func (item *ImageHistoryItem) GetShortID() string {
+	return (&Image{Id: item.Id}).GetShortID()
+}
+
+// GetCreatedBy returns the build instruction of the layer without the
+// shell wrapper Docker records for Dockerfile metadata instructions
+func (item *ImageHistoryItem) GetCreatedBy() string {
+	createdBy := strings.TrimPrefix(item.CreatedBy, "/bin/sh -c #(nop) ")
+	createdBy = strings.TrimPrefix(createdBy, "/bin/sh -c ")
+	return strings.TrimSpace(createdBy)
+}
+
+func (r *Registry) TypeString() string {
+	switch r.Type {
+	case RegistryTypeQuay:
+		return "Quay"
+	case RegistryTypeAzure:
+		return "Azure"
+	case RegistryTypeCustom:
+		return "Custom"
+	case RegistryTypeGitlab:
+		return "GitLab"
+	case RegistryTypeProGet:
+		return "ProGet"
+	case RegistryTypeDockerHub:
+		return "DockerHub"
+	case RegistryTypeECR:
+		return "ECR"
+	default:
+		return fmt.Sprintf("Unknown (%d)", r.Type)
+	}
+}
@@ -0,0 +1,248 @@
+package portainer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestImageService_History(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/endpoints/1/docker/images/nginx:latest/history" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]ImageHistoryItem{
+			{Id: "sha256:abc", CreatedBy: "/bin/sh -c #(nop)  CMD [\"nginx\"]", Size: 0},
+			{Id: "<missing>", CreatedBy: "/bin/sh -c apt-get update", Size: 52428800},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	history, err := NewImageService(client).History(1, "nginx:latest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 layers, got %d", len(history))
+	}
+	if got := history[0].GetCreatedBy(); got != `CMD ["nginx"]` {
+		t.Errorf("unexpected created by %q", got)
+	}
+	if got := history[1].GetCreatedBy(); got != "apt-get update" {
+		t.Errorf("unexpected created by %q", got)
+	}
+}
+
+func TestImageService_RegistryDigest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/endpoints/1/docker/distribution/registry.example.com/team/app:1.0/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"Descriptor":{"digest":"sha256:abc"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	digest, err := NewImageService(client).RegistryDigest(1, "registry.example.com/team/app:1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if digest != "sha256:abc" {
+		t.Errorf("expected sha256:abc, got %s", digest)
+	}
+
+	image := Image{RepoDigests: []string{"registry.example.com/team/app@sha256:abc"}}
+	if !image.HasDigest("registry.example.com/team/app", digest) {
+		t.Errorf("expected image to match registry digest")
+	}
+}
+
+func TestImageService_Prune(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/endpoints/1/docker/images/prune" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if filters := r.URL.Query().Get("filters"); filters != `{"dangling":["false"],"until":["168h0m0s"]}` {
+			t.Errorf("unexpected filters: %s", filters)
+		}
+		w.Write([]byte(`{"ImagesDeleted":[{"Untagged":"nginx:1.24"},{"Deleted":"sha256:aaa"},{"Deleted":"sha256:bbb"}],"SpaceReclaimed":1048576}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	report, err := NewImageService(client).Prune(1, map[string][]string{"dangling": {"false"}, "until": {"168h0m0s"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.SpaceReclaimed != 1048576 || len(report.ImagesDeleted) != 3 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if report.DeletedCount() != 2 {
+		t.Errorf("expected 2 deleted images, got %d", report.DeletedCount())
+	}
+}
+
+func TestImageService_PullWithProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/endpoints/1/docker/images/create" || r.URL.Query().Get("fromImage") != "nginx:latest" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"status":"Pulling from library/nginx","id":"latest"}
+{"status":"Downloading","progressDetail":{"current":512,"total":1024},"progress":"[=====>     ]","id":"a2abf6c4d29d"}
+{"status":"Pull complete","progressDetail":{},"id":"a2abf6c4d29d"}
+`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var progress []PullProgress
+	if err := NewImageService(client).PullWithProgress(1, "nginx:latest", 0, func(p PullProgress) {
+		progress = append(progress, p)
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(progress) != 3 {
+		t.Fatalf("expected 3 progress messages, got %d", len(progress))
+	}
+	if p := progress[1]; p.Status != "Downloading" || p.ID != "a2abf6c4d29d" || p.ProgressDetail.Current != 512 || p.ProgressDetail.Total != 1024 {
+		t.Errorf("unexpected progress %+v", p)
+	}
+}
+
+func TestImageService_PushWithProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/endpoints/1/docker/images/registry.example.com/app:1.2/push" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("X-Registry-Auth") != "2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"status":"The push refers to repository [registry.example.com/app]"}
+{"status":"Pushing","progressDetail":{"current":512,"total":1024},"id":"a2abf6c4d29d"}
+{"status":"Pushed","progressDetail":{},"id":"a2abf6c4d29d"}
+{"status":"1.2: digest: sha256:abc size: 1570"}
+{"progressDetail":{},"aux":{"Tag":"1.2","Digest":"sha256:abc","Size":1570}}
+`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var progress []PushProgress
+	result, err := NewImageService(client).PushWithProgress(1, "registry.example.com/app:1.2", 2, func(p PushProgress) {
+		progress = append(progress, p)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(progress) != 5 {
+		t.Fatalf("expected 5 progress messages, got %d", len(progress))
+	}
+	if p := progress[1]; p.Status != "Pushing" || p.ID != "a2abf6c4d29d" || p.ProgressDetail.Current != 512 {
+		t.Errorf("unexpected progress %+v", p)
+	}
+	if result == nil || result.Digest != "sha256:abc" || result.Tag != "1.2" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestImageService_PushWithProgressError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"The push refers to repository [registry.example.com/app]"}
+{"errorDetail":{"message":"denied: requested access to the resource is denied"},"error":"denied: requested access to the resource is denied"}
+`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err = NewImageService(client).PushWithProgress(1, "registry.example.com/app:1.2", 0, nil)
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("expected denied error, got %v", err)
+	}
+}
+
+func TestImageService_RegistryDigestWithAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, err := base64.URLEncoding.DecodeString(r.Header.Get("X-Registry-Auth"))
+		if err != nil || string(auth) != `{"registryId":3}` {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"Descriptor":{"digest":"sha256:def"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	digest, err := NewImageService(client).RegistryDigestWithAuth(1, "team/app:1.0", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if digest != "sha256:def" {
+		t.Errorf("expected sha256:def, got %s", digest)
+	}
+}
+
+func TestImageService_Save(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.EscapedPath() != "/api/endpoints/1/docker/images/team%2Fapp:2.3/get" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("archive"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	body, err := NewImageService(client).Save(1, "team/app:2.3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "archive" {
+		t.Errorf("expected the archive, got %q", data)
+	}
+}
@@ -0,0 +1,168 @@
+package portainer
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type KubernetesService struct {
+	client *Client
+}
+
+// Namespace is a Kubernetes namespace as reported by Portainer
+type Namespace struct {
+	Id             string            `json:"Id"`
+	Name           string            `json:"Name"`
+	Status         NamespaceStatus   `json:"Status"`
+	Annotations    map[string]string `json:"Annotations,omitempty"`
+	CreationDate   string            `json:"CreationDate"`
+	NamespaceOwner string            `json:"NamespaceOwner"`
+	IsSystem       bool              `json:"IsSystem"`
+	IsDefault      bool              `json:"IsDefault"`
+	// ResourceQuota is the quota object of the namespace, nil when it has
+	// none; see Quota
+	ResourceQuota *ResourceQuota `json:"ResourceQuota,omitempty"`
+}
+
+// ResourceQuota is a Kubernetes ResourceQuota, as Portainer returns it with
+// the namespaces. Hard and Used map resource names such as limits.cpu to
+// quantities.
+type ResourceQuota struct {
+	Spec struct {
+		Hard map[string]string `json:"hard,omitempty"`
+	} `json:"spec"`
+	Status struct {
+		Hard map[string]string `json:"hard,omitempty"`
+		Used map[string]string `json:"used,omitempty"`
+	} `json:"status"`
+}
+
+type NamespaceStatus struct {
+	Phase string `json:"phase"`
+}
+
+// NamespaceQuota limits the CPU and memory a namespace may request.
+// Values use Kubernetes quantities, e.g. "2" or "500m" for CPU and "4Gi"
+// for memory.
+type NamespaceQuota struct {
+	Enabled bool   `json:"enabled"`
+	CPU     string `json:"cpu,omitempty"`
+	Memory  string `json:"memory,omitempty"`
+}
+
+// NamespaceRequest creates or updates a namespace
+type NamespaceRequest struct {
+	Name          string            `json:"Name"`
+	Owner         string            `json:"Owner,omitempty"`
+	Annotations   map[string]string `json:"Annotations,omitempty"`
+	ResourceQuota *NamespaceQuota   `json:"ResourceQuota,omitempty"`
+}
+
+func NewKubernetesService(client *Client) *KubernetesService {
+	return &KubernetesService{client: client}
+}
+
+func (s *KubernetesService) ListNamespaces(endpointID int) ([]Namespace, error) {
+	path := fmt.Sprintf("kubernetes/%d/namespaces?withResourceQuota=true", endpointID)
+
+	var namespaces []Namespace
+	if err := s.client.Get(path, &namespaces); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	return namespaces, nil
+}
+
+func (s *KubernetesService) GetNamespace(endpointID int, name string) (*Namespace, error) {
+	path := fmt.Sprintf("kubernetes/%d/namespaces/%s?withResourceQuota=true", endpointID, url.PathEscape(name))
+
+	var namespace Namespace
+	if err := s.client.Get(path, &namespace); err != nil {
+		return nil, fmt.Errorf("failed to get namespace: %w", err)
+	}
+	return &namespace, nil
+}
+
+func (s *KubernetesService) CreateNamespace(endpointID int, req *NamespaceRequest) error {
+	path := fmt.Sprintf("kubernetes/%d/namespaces", endpointID)
+
+	if err := s.client.Post(path, req, nil); err != nil {
+		return fmt.Errorf("failed to create namespace: %w", err)
+	}
+	return nil
+}
+
+// UpdateNamespace replaces the owner, annotations and resource quota of the
+// namespace req.Name. Portainer 2.19 moved the route to the namespace; the
+// older route is used when the server version is unknown.
+func (s *KubernetesService) UpdateNamespace(endpointID int, req *NamespaceRequest) error {
+	path := fmt.Sprintf("kubernetes/%d/namespaces", endpointID)
+	if s.client.serverVersion.AtLeast(versionNamespaceUpdateRoute) {
+		path = fmt.Sprintf("kubernetes/%d/namespaces/%s", endpointID, url.PathEscape(req.Name))
+	}
+
+	if err := s.client.Put(path, req, nil); err != nil {
+		return fmt.Errorf("failed to update namespace: %w", err)
+	}
+	return nil
+}
+
+func (s *KubernetesService) DeleteNamespaces(endpointID int, names []string) error {
+	path := fmt.Sprintf("kubernetes/%d/namespaces", endpointID)
+
+	if err := s.client.DoRequest(http.MethodDelete, path, names, nil); err != nil {
+		return fmt.Errorf("failed to delete namespace: %w", err)
+	}
+	return nil
+}
+
+// Kubeconfig downloads a kubeconfig in YAML for the current user, with one
+// context per environment
+func (s *KubernetesService) Kubeconfig(endpointIDs []int) ([]byte, error) {
+	params := url.Values{}
+	for _, id := range endpointIDs {
+		params.Add("ids[]", strconv.Itoa(id))
+	}
+
+	content, err := s.client.getRaw("kubernetes/config?"+params.Encode(), "text/yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	return content, nil
+}
+
+// Quota returns the CPU and memory limits of the namespace's resource
+// quota, or nil when it has none. Portainer sets the same value for the
+// requests and limits of a resource.
+func (n *Namespace) Quota() *NamespaceQuota {
+	if n.ResourceQuota == nil {
+		return nil
+	}
+	hard := n.ResourceQuota.Spec.Hard
+	quota := &NamespaceQuota{Enabled: true}
+	for _, name := range []string{"limits.cpu", "requests.cpu", "cpu"} {
+		if value := hard[name]; value != "" {
+			quota.CPU = value
+			break
+		}
+	}
+	for _, name := range []string{"limits.memory", "requests.memory", "memory"} {
+		if value := hard[name]; value != "" {
+			quota.Memory = value
+			break
+		}
+	}
+	if quota.CPU == "" && quota.Memory == "" {
+		return nil
+	}
+	return quota
+}
+
+// StatusString returns the namespace phase, e.g. Active or Terminating
+func (n *Namespace) StatusString() string {
+	if n.Status.Phase != "" {
+		return n.Status.Phase
+	}
+	return "-"
+}
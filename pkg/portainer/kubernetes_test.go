@@ -0,0 +1,92 @@
+package portainer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKubernetesService_GetNamespace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/kubernetes/3/namespaces/team-a" || r.URL.Query().Get("withResourceQuota") != "true" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{
+			"Name": "team-a",
+			"NamespaceOwner": "alice",
+			"ResourceQuota": {
+				"metadata": {"name": "portainer-rq-team-a"},
+				"spec": {"hard": {"limits.cpu": "4", "requests.cpu": "4", "limits.memory": "8Gi", "requests.memory": "8Gi"}},
+				"status": {"used": {"limits.cpu": "500m"}}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	namespace, err := NewKubernetesService(client).GetNamespace(3, "team-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	quota := namespace.Quota()
+	if quota == nil || !quota.Enabled || quota.CPU != "4" || quota.Memory != "8Gi" {
+		t.Errorf("unexpected quota %+v", quota)
+	}
+	if used := namespace.ResourceQuota.Status.Used["limits.cpu"]; used != "500m" {
+		t.Errorf("expected used CPU 500m, got %q", used)
+	}
+
+	if quota := (&Namespace{Name: "default"}).Quota(); quota != nil {
+		t.Errorf("expected no quota, got %+v", quota)
+	}
+}
+
+func TestKubernetesService_UpdateNamespace(t *testing.T) {
+	tests := []struct {
+		version string
+		path    string
+	}{
+		{"", "/api/kubernetes/3/namespaces"},
+		{"2.18.4", "/api/kubernetes/3/namespaces"},
+		{"2.21.0", "/api/kubernetes/3/namespaces/team-a"},
+	}
+
+	for _, tt := range tests {
+		var path string
+		var body NamespaceRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPut {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			path = r.URL.Path
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, WithServerVersion(tt.version))
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+		err = NewKubernetesService(client).UpdateNamespace(3, &NamespaceRequest{
+			Name:          "team-a",
+			ResourceQuota: &NamespaceQuota{Enabled: true, CPU: "2", Memory: "4Gi"},
+		})
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.version, err)
+		}
+		if path != tt.path {
+			t.Errorf("%s: expected %s, got %s", tt.version, tt.path, path)
+		}
+		if body.Name != "team-a" || body.ResourceQuota == nil || body.ResourceQuota.CPU != "2" || body.ResourceQuota.Memory != "4Gi" {
+			t.Errorf("%s: unexpected body %+v", tt.version, body)
+		}
+	}
+}
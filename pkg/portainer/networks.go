@@ -1,4 +1,4 @@
-package client
+package portainer
 
 import (
 	"fmt"
@@ -113,15 +113,77 @@ func (s *NetworkService) Remove(endpointID int, networkID string) error {
 	return nil
 }
 
-func (s *NetworkService) Prune(endpointID int) error {
-	path := fmt.Sprintf("endpoints/%d/docker/networks/prune", endpointID)
+// NetworkConnectOptions are the optional settings of a container's
+// attachment to a network
+type NetworkConnectOptions struct {
+	// Aliases are additional DNS names of the container on the network
+	Aliases []string
+	// IPv4Address and IPv6Address are static addresses for the container
+	IPv4Address string
+	IPv6Address string
+}
+
+type networkConnectRequest struct {
+	Container      string                 `json:"Container"`
+	EndpointConfig *networkEndpointConfig `json:"EndpointConfig,omitempty"`
+}
+
+type networkEndpointConfig struct {
+	Aliases    []string            `json:"Aliases,omitempty"`
+	IPAMConfig *EndpointIPAMConfig `json:"IPAMConfig,omitempty"`
+}
 
-	if err := s.client.Post(path, nil, nil); err != nil {
-		return fmt.Errorf("failed to prune networks: %w", err)
+// Connect attaches a container to a network
+func (s *NetworkService) Connect(endpointID int, networkID, containerID string, opts NetworkConnectOptions) error {
+	path := fmt.Sprintf("endpoints/%d/docker/networks/%s/connect", endpointID, url.PathEscape(networkID))
+
+	req := networkConnectRequest{Container: containerID}
+	if len(opts.Aliases) > 0 || opts.IPv4Address != "" || opts.IPv6Address != "" {
+		req.EndpointConfig = &networkEndpointConfig{Aliases: opts.Aliases}
+		if opts.IPv4Address != "" || opts.IPv6Address != "" {
+			req.EndpointConfig.IPAMConfig = &EndpointIPAMConfig{
+				IPv4Address: opts.IPv4Address,
+				IPv6Address: opts.IPv6Address,
+			}
+		}
+	}
+
+	if err := s.client.Post(path, req, nil); err != nil {
+		return fmt.Errorf("failed to connect container to network: %w", err)
 	}
 	return nil
 }
 
+// Disconnect detaches a container from a network. force disconnects a
+// container that is no longer running on the network's node.
+func (s *NetworkService) Disconnect(endpointID int, networkID, containerID string, force bool) error {
+	path := fmt.Sprintf("endpoints/%d/docker/networks/%s/disconnect", endpointID, url.PathEscape(networkID))
+
+	req := map[string]interface{}{
+		"Container": containerID,
+		"Force":     force,
+	}
+	if err := s.client.Post(path, req, nil); err != nil {
+		return fmt.Errorf("failed to disconnect container from network: %w", err)
+	}
+	return nil
+}
+
+// NetworkPruneReport lists the networks removed by a prune
+type NetworkPruneReport struct {
+	NetworksDeleted []string `json:"NetworksDeleted"`
+}
+
+func (s *NetworkService) Prune(endpointID int) (*NetworkPruneReport, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/networks/prune", endpointID)
+
+	var report NetworkPruneReport
+	if err := s.client.Post(path, nil, &report); err != nil {
+		return nil, fmt.Errorf("failed to prune networks: %w", err)
+	}
+	return &report, nil
+}
+
 func (n *Network) GetShortID() string {
 	if len(n.Id) > 12 {
 		return n.Id[:12]
@@ -0,0 +1,62 @@
+package portainer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNetworkService_ConnectDisconnect(t *testing.T) {
+	var path string
+	var payload map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		payload = nil
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	networkService := NewNetworkService(client)
+
+	if err := networkService.Connect(1, "mynet", "web", NetworkConnectOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/api/endpoints/1/docker/networks/mynet/connect" {
+		t.Errorf("unexpected path: %s", path)
+	}
+	if _, ok := payload["EndpointConfig"]; ok || payload["Container"] != "web" {
+		t.Errorf("unexpected payload: %v", payload)
+	}
+
+	if err := networkService.Connect(1, "mynet", "web", NetworkConnectOptions{
+		Aliases:     []string{"api"},
+		IPv4Address: "172.20.0.10",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config, _ := payload["EndpointConfig"].(map[string]interface{})
+	aliases, _ := config["Aliases"].([]interface{})
+	ipam, _ := config["IPAMConfig"].(map[string]interface{})
+	if len(aliases) != 1 || aliases[0] != "api" || ipam["IPv4Address"] != "172.20.0.10" {
+		t.Errorf("unexpected endpoint config: %v", config)
+	}
+
+	if err := networkService.Disconnect(1, "mynet", "web", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/api/endpoints/1/docker/networks/mynet/disconnect" {
+		t.Errorf("unexpected path: %s", path)
+	}
+	if payload["Container"] != "web" || payload["Force"] != true {
+		t.Errorf("unexpected payload: %v", payload)
+	}
+}
@@ -0,0 +1,38 @@
+package portainer
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// PageSize is the number of items the list methods request per page from
+// servers that paginate
+const PageSize = 100
+
+// totalCount returns the total number of items of a paginated list as given
+// by the X-Total-Count header, or -1 when the server did not paginate
+func totalCount(headers http.Header) int {
+	if value := headers.Get("X-Total-Count"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return -1
+}
+
+// listAll calls listPage until it has all items of a list. Servers without
+// pagination return every item with the first page.
+func listAll[T any](listPage func(start, limit int) ([]T, int, error)) ([]T, error) {
+	var items []T
+	for {
+		page, total, err := listPage(len(items), PageSize)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+
+		if total < 0 || len(page) == 0 || len(items) >= total {
+			return items, nil
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package portainer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PluginService manages the plugins of the Docker engine of an environment,
+// such as volume and network drivers
+type PluginService struct {
+	client *Client
+}
+
+func NewPluginService(client *Client) *PluginService {
+	return &PluginService{client: client}
+}
+
+// Plugin is a Docker engine plugin, as listed by "docker plugin ls"
+type Plugin struct {
+	Id              string         `json:"Id"`
+	Name            string         `json:"Name"`
+	Enabled         bool           `json:"Enabled"`
+	PluginReference string         `json:"PluginReference,omitempty"`
+	Settings        PluginSettings `json:"Settings"`
+	Config          PluginConfig   `json:"Config"`
+}
+
+type PluginSettings struct {
+	Env  []string `json:"Env"`
+	Args []string `json:"Args"`
+}
+
+type PluginConfig struct {
+	Description   string `json:"Description"`
+	Documentation string `json:"Documentation,omitempty"`
+	Interface     struct {
+		Types  []PluginType `json:"Types"`
+		Socket string       `json:"Socket"`
+	} `json:"Interface"`
+}
+
+// PluginType is a capability a plugin provides, e.g. docker.volumedriver/1.0
+type PluginType struct {
+	Prefix     string `json:"Prefix"`
+	Capability string `json:"Capability"`
+	Version    string `json:"Version"`
+}
+
+func (p *Plugin) GetShortID() string {
+	if len(p.Id) > 12 {
+		return p.Id[:12]
+	}
+	return p.Id
+}
+
+// Capabilities returns the capabilities of the plugin, e.g. "volumedriver"
+func (p *Plugin) Capabilities() []string {
+	capabilities := make([]string, 0, len(p.Config.Interface.Types))
+	for _, t := range p.Config.Interface.Types {
+		capabilities = append(capabilities, t.Capability)
+	}
+	return capabilities
+}
+
+// List returns the plugins installed in the engine of an environment.
+// filters are Docker plugin filters, such as {"capability": ["volumedriver"]}
+// or {"enable": ["true"]}.
+func (s *PluginService) List(endpointID int, filters map[string][]string) ([]Plugin, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/plugins", endpointID)
+	if len(filters) > 0 {
+		filtersJSON, err := json.Marshal(filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal filters: %w", err)
+		}
+		path += "?filters=" + url.QueryEscape(string(filtersJSON))
+	}
+
+	var plugins []Plugin
+	if err := s.client.Get(path, &plugins); err != nil {
+		return nil, fmt.Errorf("failed to list plugins: %w", err)
+	}
+	return plugins, nil
+}
+
+// Inspect returns a plugin by name, e.g. "vieux/sshfs:latest", or ID
+func (s *PluginService) Inspect(endpointID int, name string) (*Plugin, error) {
+	var plugin Plugin
+	if err := s.client.Get(pluginPath(endpointID, name, "json"), &plugin); err != nil {
+		return nil, fmt.Errorf("failed to inspect plugin: %w", err)
+	}
+	return &plugin, nil
+}
+
+// Enable activates a plugin. timeout is how long the engine waits for the
+// plugin to start, in whole seconds rounded up, 0 for the default.
+func (s *PluginService) Enable(endpointID int, name string, timeout time.Duration) error {
+	path := pluginPath(endpointID, name, "enable")
+	if timeout > 0 {
+		path += fmt.Sprintf("?timeout=%d", int64(math.Ceil(timeout.Seconds())))
+	}
+	if err := s.client.Post(path, nil, nil); err != nil {
+		return fmt.Errorf("failed to enable plugin: %w", err)
+	}
+	return nil
+}
+
+// Disable deactivates a plugin. The engine refuses to disable a plugin in
+// use, such as the driver of existing volumes, unless force is set.
+func (s *PluginService) Disable(endpointID int, name string, force bool) error {
+	path := pluginPath(endpointID, name, "disable")
+	if force {
+		path += "?force=true"
+	}
+	if err := s.client.Post(path, nil, nil); err != nil {
+		return fmt.Errorf("failed to disable plugin: %w", err)
+	}
+	return nil
+}
+
+// pluginPath returns the engine path of an action on a plugin. Plugin names
+// contain slashes, which the engine expects unescaped.
+func pluginPath(endpointID int, name, action string) string {
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf("endpoints/%d/docker/plugins/%s/%s", endpointID, strings.Join(segments, "/"), action)
+}
@@ -0,0 +1,73 @@
+package portainer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPluginService_List(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/endpoints/1/docker/plugins" || r.URL.Query().Get("filters") != `{"capability":["volumedriver"]}` {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`[{"Id":"5724e2c8652da337ab2eedd19fc6fc0ec908e4bd907c7421bf6a8dfc70c4c078","Name":"vieux/sshfs:latest","Enabled":true,
+			"Config":{"Description":"sshFS plugin for Docker","Interface":{"Types":[{"Prefix":"docker","Capability":"volumedriver","Version":"1.0"}]}}}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	plugins, err := NewPluginService(client).List(1, map[string][]string{"capability": {"volumedriver"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plugins) != 1 {
+		t.Fatalf("expected 1 plugin, got %d", len(plugins))
+	}
+	if plugins[0].GetShortID() != "5724e2c8652d" || !plugins[0].Enabled {
+		t.Errorf("unexpected plugin %+v", plugins[0])
+	}
+	if capabilities := plugins[0].Capabilities(); len(capabilities) != 1 || capabilities[0] != "volumedriver" {
+		t.Errorf("expected volumedriver capability, got %v", capabilities)
+	}
+}
+
+func TestPluginService_EnableDisable(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	pluginService := NewPluginService(client)
+
+	if err := pluginService.Enable(1, "vieux/sshfs:latest", 29500*time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := pluginService.Disable(1, "vieux/sshfs:latest", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"POST /api/endpoints/1/docker/plugins/vieux/sshfs:latest/enable?timeout=30",
+		"POST /api/endpoints/1/docker/plugins/vieux/sshfs:latest/disable?force=true",
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("expected %d requests, got %v", len(expected), requests)
+	}
+	for i, want := range expected {
+		if requests[i] != want {
+			t.Errorf("expected %q, got %q", want, requests[i])
+		}
+	}
+}
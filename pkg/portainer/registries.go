@@ -0,0 +1,105 @@
+package portainer
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type RegistryService struct {
+	client *Client
+}
+
+func NewRegistryService(client *Client) *RegistryService {
+	return &RegistryService{client: client}
+}
+
+func (s *RegistryService) List() ([]Registry, error) {
+	var registries []Registry
+	if err := s.client.Get("registries", &registries); err != nil {
+		return nil, fmt.Errorf("failed to list registries: %w", err)
+	}
+	return registries, nil
+}
+
+func (s *RegistryService) Get(id int) (*Registry, error) {
+	path := fmt.Sprintf("registries/%d", id)
+
+	var registry Registry
+	if err := s.client.Get(path, &registry); err != nil {
+		return nil, fmt.Errorf("failed to get registry: %w", err)
+	}
+	return &registry, nil
+}
+
+func (s *RegistryService) Create(registry *Registry) (*Registry, error) {
+	var result Registry
+	if err := s.client.Post("registries", registry, &result); err != nil {
+		return nil, fmt.Errorf("failed to create registry: %w", err)
+	}
+	return &result, nil
+}
+
+func (s *RegistryService) Update(id int, registry *Registry) (*Registry, error) {
+	path := fmt.Sprintf("registries/%d", id)
+
+	var result Registry
+	if err := s.client.Put(path, registry, &result); err != nil {
+		return nil, fmt.Errorf("failed to update registry: %w", err)
+	}
+	return &result, nil
+}
+
+func (s *RegistryService) Delete(id int) error {
+	path := fmt.Sprintf("registries/%d", id)
+
+	if err := s.client.Delete(path); err != nil {
+		return fmt.Errorf("failed to delete registry: %w", err)
+	}
+	return nil
+}
+
+// Test checks the stored credentials of a registry by calling the registry
+// API base endpoint through Portainer's registry proxy
+func (s *RegistryService) Test(id int) error {
+	path := fmt.Sprintf("registries/%d/v2/", id)
+
+	if err := s.client.Get(path, nil); err != nil {
+		if IsUnauthorizedError(err) {
+			return fmt.Errorf("registry rejected the stored credentials: %w", err)
+		}
+		return fmt.Errorf("failed to reach registry: %w", err)
+	}
+	return nil
+}
+
+// Catalog lists the repositories of a registry
+func (s *RegistryService) Catalog(id int) ([]string, error) {
+	path := fmt.Sprintf("registries/%d/v2/_catalog", id)
+
+	var response struct {
+		Repositories []string `json:"repositories"`
+	}
+	if err := s.client.Get(path, &response); err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	return response.Repositories, nil
+}
+
+// Tags lists the tags of a repository in a registry
+func (s *RegistryService) Tags(id int, repository string) ([]string, error) {
+	segments := strings.Split(strings.Trim(repository, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	path := fmt.Sprintf("registries/%d/v2/%s/tags/list", id, strings.Join(segments, "/"))
+
+	var response struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	if err := s.client.Get(path, &response); err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return response.Tags, nil
+}
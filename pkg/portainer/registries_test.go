@@ -0,0 +1,57 @@
+package portainer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryService_Browse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/registries/2/v2/":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"unauthorized"}`))
+		case "/api/registries/1/v2/":
+			w.Write([]byte("{}"))
+		case "/api/registries/1/v2/_catalog":
+			json.NewEncoder(w).Encode(map[string][]string{"repositories": {"team/api", "web"}})
+		case "/api/registries/1/v2/team/api/tags/list":
+			json.NewEncoder(w).Encode(map[string]interface{}{"name": "team/api", "tags": []string{"latest", "v1"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	registryService := NewRegistryService(client)
+
+	if err := registryService.Test(1); err != nil {
+		t.Errorf("expected valid credentials, got %v", err)
+	}
+	if err := registryService.Test(2); err == nil || !strings.Contains(err.Error(), "credentials") {
+		t.Errorf("expected credentials error, got %v", err)
+	}
+
+	repositories, err := registryService.Catalog(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repositories) != 2 {
+		t.Errorf("expected 2 repositories, got %v", repositories)
+	}
+
+	tags, err := registryService.Tags(1, "team/api")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tags) != 2 || tags[0] != "latest" {
+		t.Errorf("expected [latest v1], got %v", tags)
+	}
+}
@@ -0,0 +1,144 @@
+package portainer
+
+import (
+	"fmt"
+)
+
+type ResourceControlService struct {
+	client *Client
+}
+
+// ResourceControl describes who may access a Docker resource managed by
+// Portainer
+type ResourceControl struct {
+	Id                 int                  `json:"Id"`
+	ResourceId         string               `json:"ResourceId"`
+	SubResourceIds     []string             `json:"SubResourceIds"`
+	Type               int                  `json:"Type"`
+	UserAccesses       []UserResourceAccess `json:"UserAccesses"`
+	TeamAccesses       []TeamResourceAccess `json:"TeamAccesses"`
+	Public             bool                 `json:"Public"`
+	AdministratorsOnly bool                 `json:"AdministratorsOnly"`
+	System             bool                 `json:"System"`
+}
+
+type UserResourceAccess struct {
+	UserId      int `json:"UserId"`
+	AccessLevel int `json:"AccessLevel"`
+}
+
+type TeamResourceAccess struct {
+	TeamId      int `json:"TeamId"`
+	AccessLevel int `json:"AccessLevel"`
+}
+
+// ResourceControlCreateRequest creates access rules for a resource that has
+// none yet
+type ResourceControlCreateRequest struct {
+	ResourceID         string   `json:"ResourceID"`
+	Type               int      `json:"Type"`
+	Public             bool     `json:"Public"`
+	AdministratorsOnly bool     `json:"AdministratorsOnly"`
+	Users              []int    `json:"Users"`
+	Teams              []int    `json:"Teams"`
+	SubResourceIDs     []string `json:"SubResourceIDs"`
+}
+
+// ResourceControlUpdateRequest replaces the access rules of a resource
+type ResourceControlUpdateRequest struct {
+	Public             bool  `json:"Public"`
+	AdministratorsOnly bool  `json:"AdministratorsOnly"`
+	Users              []int `json:"Users"`
+	Teams              []int `json:"Teams"`
+}
+
+// PortainerMetadata is added by Portainer to Docker API responses it proxies
+type PortainerMetadata struct {
+	ResourceControl *ResourceControl `json:"ResourceControl,omitempty"`
+}
+
+const (
+	ResourceControlTypeContainer = 1
+	ResourceControlTypeService   = 2
+	ResourceControlTypeVolume    = 3
+	ResourceControlTypeNetwork   = 4
+	ResourceControlTypeSecret    = 5
+	ResourceControlTypeStack     = 6
+	ResourceControlTypeConfig    = 7
+)
+
+func NewResourceControlService(client *Client) *ResourceControlService {
+	return &ResourceControlService{client: client}
+}
+
+func (s *ResourceControlService) Create(req *ResourceControlCreateRequest) (*ResourceControl, error) {
+	var result ResourceControl
+	if err := s.client.Post("resource_controls", req, &result); err != nil {
+		return nil, fmt.Errorf("failed to create resource control: %w", err)
+	}
+	return &result, nil
+}
+
+func (s *ResourceControlService) Update(id int, req *ResourceControlUpdateRequest) (*ResourceControl, error) {
+	path := fmt.Sprintf("resource_controls/%d", id)
+
+	var result ResourceControl
+	if err := s.client.Put(path, req, &result); err != nil {
+		return nil, fmt.Errorf("failed to update resource control: %w", err)
+	}
+	return &result, nil
+}
+
+func (s *ResourceControlService) Delete(id int) error {
+	path := fmt.Sprintf("resource_controls/%d", id)
+
+	if err := s.client.Delete(path); err != nil {
+		return fmt.Errorf("failed to delete resource control: %w", err)
+	}
+	return nil
+}
+
+// StackResourceID returns the identifier Portainer uses for the access
+// rules of a stack
+func StackResourceID(endpointID int, stackName string) string {
+	return fmt.Sprintf("%d_%s", endpointID, stackName)
+}
+
+func (rc *ResourceControl) TypeString() string {
+	switch rc.Type {
+	case ResourceControlTypeContainer:
+		return "container"
+	case ResourceControlTypeService:
+		return "service"
+	case ResourceControlTypeVolume:
+		return "volume"
+	case ResourceControlTypeNetwork:
+		return "network"
+	case ResourceControlTypeSecret:
+		return "secret"
+	case ResourceControlTypeStack:
+		return "stack"
+	case ResourceControlTypeConfig:
+		return "config"
+	default:
+		return "unknown"
+	}
+}
+
+// UserIDs returns the IDs of the users granted access
+func (rc *ResourceControl) UserIDs() []int {
+	ids := make([]int, 0, len(rc.UserAccesses))
+	for _, access := range rc.UserAccesses {
+		ids = append(ids, access.UserId)
+	}
+	return ids
+}
+
+// TeamIDs returns the IDs of the teams granted access
+func (rc *ResourceControl) TeamIDs() []int {
+	ids := make([]int, 0, len(rc.TeamAccesses))
+	for _, access := range rc.TeamAccesses {
+		ids = append(ids, access.TeamId)
+	}
+	return ids
+}
@@ -0,0 +1,54 @@
+package portainer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResourceControlService_Create(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/resource_controls" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var req ResourceControlCreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if req.ResourceID != "1_web" || req.Type != ResourceControlTypeStack {
+			t.Errorf("unexpected resource: %s (type %d)", req.ResourceID, req.Type)
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"Id":           7,
+			"ResourceId":   req.ResourceID,
+			"Type":         req.Type,
+			"TeamAccesses": []map[string]int{{"TeamId": 3, "AccessLevel": 1}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	rc, err := NewResourceControlService(client).Create(&ResourceControlCreateRequest{
+		ResourceID: StackResourceID(1, "web"),
+		Type:       ResourceControlTypeStack,
+		Teams:      []int{3},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rc.Id != 7 || rc.TypeString() != "stack" {
+		t.Errorf("unexpected resource control: %+v", rc)
+	}
+	if ids := rc.TeamIDs(); len(ids) != 1 || ids[0] != 3 {
+		t.Errorf("expected team IDs [3], got %v", ids)
+	}
+}
@@ -0,0 +1,49 @@
+package portainer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RoleService lists the RBAC roles of Portainer Business Edition
+type RoleService struct {
+	client *Client
+}
+
+type Role struct {
+	Id             int             `json:"Id"`
+	Name           string          `json:"Name"`
+	Description    string          `json:"Description"`
+	Authorizations map[string]bool `json:"Authorizations,omitempty"`
+	// Priority decides which role applies when a user has several roles in
+	// an environment, the lowest value winning
+	Priority int `json:"Priority"`
+}
+
+func NewRoleService(client *Client) *RoleService {
+	return &RoleService{client: client}
+}
+
+func (s *RoleService) List() ([]Role, error) {
+	var roles []Role
+	if err := s.client.Get("roles", &roles); err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	return roles, nil
+}
+
+// GetByName returns the role with the given name, ignoring case
+func (s *RoleService) GetByName(name string) (*Role, error) {
+	roles, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range roles {
+		if strings.EqualFold(role.Name, name) {
+			return &role, nil
+		}
+	}
+
+	return nil, fmt.Errorf("role not found: %s", name)
+}
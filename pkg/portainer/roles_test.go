@@ -0,0 +1,39 @@
+package portainer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRoleService_GetByName(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/roles" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode([]Role{
+			{Id: 1, Name: "Environment administrator", Priority: 1},
+			{Id: 3, Name: "Operator", Priority: 3},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	roleService := NewRoleService(client)
+
+	role, err := roleService.GetByName("operator")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if role.Id != 3 {
+		t.Errorf("expected role 3, got %d", role.Id)
+	}
+
+	if _, err := roleService.GetByName("helpdesk"); err == nil {
+		t.Error("expected error for unknown role")
+	}
+}
@@ -0,0 +1,579 @@
+package portainer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type StackService struct {
+	client *Client
+}
+
+type Stack struct {
+	Id              int              `json:"Id"`
+	Name            string           `json:"Name"`
+	Type            int              `json:"Type"`
+	EndpointId      int              `json:"EndpointId"`
+	SwarmId         string           `json:"SwarmId,omitempty"`
+	EntryPoint      string           `json:"EntryPoint"`
+	Env             []StackEnv       `json:"Env"`
+	ResourceControl *ResourceControl `json:"ResourceControl,omitempty"`
+	Status          int              `json:"Status,omitempty"`
+	ProjectPath     string           `json:"ProjectPath,omitempty"`
+	CreationDate    int64            `json:"CreationDate,omitempty"`
+	CreatedBy       string           `json:"CreatedBy,omitempty"`
+	UpdateDate      int64            `json:"UpdateDate,omitempty"`
+	UpdatedBy       string           `json:"UpdatedBy,omitempty"`
+	AdditionalFiles []string         `json:"AdditionalFiles,omitempty"`
+	AutoUpdate      *StackAutoUpdate `json:"AutoUpdate,omitempty"`
+	GitConfig       *StackGitConfig  `json:"GitConfig,omitempty"`
+	FromAppTemplate bool             `json:"FromAppTemplate,omitempty"`
+	Namespace       string           `json:"Namespace,omitempty"`
+	IsComposeFormat bool             `json:"IsComposeFormat,omitempty"`
+}
+
+type StackEnv struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// StackAutoUpdate configures the automatic redeployment of a Git-backed
+// stack: by polling the repository every Interval, and/or when the webhook
+// with ID Webhook is called
+type StackAutoUpdate struct {
+	Interval string `json:"Interval,omitempty"`
+	Webhook  string `json:"Webhook,omitempty"`
+	// ForceUpdate redeploys even when the repository has not changed
+	ForceUpdate bool `json:"ForceUpdate,omitempty"`
+	// ForcePullImage re-pulls images on every redeployment
+	ForcePullImage bool `json:"ForcePullImage,omitempty"`
+}
+
+type StackGitConfig struct {
+	URL            string             `json:"URL"`
+	ReferenceName  string             `json:"ReferenceName"`
+	ConfigFilePath string             `json:"ConfigFilePath"`
+	Authentication *GitAuthentication `json:"Authentication,omitempty"`
+}
+
+type GitAuthentication struct {
+	Username string `json:"Username,omitempty"`
+	Password string `json:"Password,omitempty"`
+}
+
+type StackDeployRequest struct {
+	Name             string     `json:"Name"`
+	SwarmID          string     `json:"SwarmID,omitempty"`
+	StackFileContent string     `json:"StackFileContent,omitempty"`
+	Env              []StackEnv `json:"Env,omitempty"`
+	FromAppTemplate  bool       `json:"FromAppTemplate,omitempty"`
+}
+
+// KubernetesStackDeployRequest describes a Kubernetes stack deployed from
+// StackFileContent, or from a Git repository when RepositoryURL is set.
+// ComposeFormat marks the content as a Compose file that Portainer converts
+// to Kubernetes manifests.
+type KubernetesStackDeployRequest struct {
+	StackName                string `json:"StackName"`
+	Namespace                string `json:"Namespace"`
+	ComposeFormat            bool   `json:"ComposeFormat"`
+	StackFileContent         string `json:"StackFileContent,omitempty"`
+	RepositoryURL            string `json:"RepositoryURL,omitempty"`
+	RepositoryReferenceName  string `json:"RepositoryReferenceName,omitempty"`
+	RepositoryAuthentication bool   `json:"RepositoryAuthentication,omitempty"`
+	RepositoryUsername       string `json:"RepositoryUsername,omitempty"`
+	RepositoryPassword       string `json:"RepositoryPassword,omitempty"`
+	ManifestFile             string `json:"ManifestFile,omitempty"`
+}
+
+const (
+	StackTypeSwarm      = 1
+	StackTypeCompose    = 2
+	StackTypeKubernetes = 3
+)
+
+// Labels Docker sets on the containers of a stack
+const (
+	ComposeProjectLabel = "com.docker.compose.project"
+	ComposeServiceLabel = "com.docker.compose.service"
+	SwarmStackLabel     = "com.docker.stack.namespace"
+	SwarmServiceLabel   = "com.docker.swarm.service.name"
+)
+
+const (
+	StackStatusActive   = 1
+	StackStatusInactive = 2
+)
+
+func NewStackService(client *Client) *StackService {
+	return &StackService{client: client}
+}
+
+// List returns the stacks deployed to an environment, or the stacks of all
+// environments when endpointID is 0
+func (s *StackService) List(endpointID int) ([]Stack, error) {
+	path := "stacks"
+	if endpointID != 0 {
+		path = fmt.Sprintf("stacks?filters={\"EndpointId\":%d}", endpointID)
+	}
+
+	var stacks []Stack
+	if err := s.client.Get(path, &stacks); err != nil {
+		return nil, fmt.Errorf("failed to list stacks: %w", err)
+	}
+	return stacks, nil
+}
+
+func (s *StackService) Get(id int) (*Stack, error) {
+	path := fmt.Sprintf("stacks/%d", id)
+
+	var stack Stack
+	if err := s.client.Get(path, &stack); err != nil {
+		return nil, fmt.Errorf("failed to get stack: %w", err)
+	}
+	return &stack, nil
+}
+
+// StackNotFoundError is returned by GetByName when no stack of the
+// environment has the name
+type StackNotFoundError struct {
+	Name string
+}
+
+func (e *StackNotFoundError) Error() string {
+	return fmt.Sprintf("stack '%s' not found", e.Name)
+}
+
+func (s *StackService) GetByName(endpointID int, name string) (*Stack, error) {
+	stacks, err := s.List(endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stack := range stacks {
+		if stack.Name == name {
+			return &stack, nil
+		}
+	}
+
+	return nil, &StackNotFoundError{Name: name}
+}
+
+func (s *StackService) DeployFromFile(endpointID int, name, filePath string, env []StackEnv) (*Stack, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stack file: %w", err)
+	}
+
+	return s.Deploy(endpointID, name, string(content), env)
+}
+
+func (s *StackService) Deploy(endpointID int, name, stackFileContent string, env []StackEnv) (*Stack, error) {
+	return s.deploy(endpointID, StackTypeCompose, "", name, stackFileContent, env)
+}
+
+// DeploySwarm deploys a Swarm stack to the swarm swarmID managed by the
+// environment, see SwarmID
+func (s *StackService) DeploySwarm(endpointID int, swarmID, name, stackFileContent string, env []StackEnv) (*Stack, error) {
+	if swarmID == "" {
+		return nil, fmt.Errorf("swarm ID is required to deploy a Swarm stack")
+	}
+	return s.deploy(endpointID, StackTypeSwarm, swarmID, name, stackFileContent, env)
+}
+
+// SwarmID returns the ID of the swarm managed by the environment's Docker
+// engine, or an empty string when the engine is not a swarm manager
+func (s *StackService) SwarmID(endpointID int) (string, error) {
+	var swarm struct {
+		ID string `json:"ID"`
+	}
+	if err := s.client.Get(fmt.Sprintf("endpoints/%d/docker/swarm", endpointID), &swarm); err != nil {
+		if isNotSwarmManagerError(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to inspect swarm: %w", err)
+	}
+	return swarm.ID, nil
+}
+
+// DeployKubernetes deploys a Kubernetes stack to a namespace of the
+// environment
+func (s *StackService) DeployKubernetes(endpointID int, req *KubernetesStackDeployRequest) (*Stack, error) {
+	method := "string"
+	if req.RepositoryURL != "" {
+		method = "repository"
+	}
+	path := s.createPath(StackTypeKubernetes, method, endpointID)
+
+	var stack Stack
+	if err := s.client.Post(path, req, &stack); err != nil {
+		return nil, fmt.Errorf("failed to deploy Kubernetes stack: %w", err)
+	}
+	return &stack, nil
+}
+
+// createPath returns the path that creates a stack of stackType with the
+// given method. Portainer 2.19 replaced the query parameters with path
+// segments; the older form is used when the server version is unknown.
+func (s *StackService) createPath(stackType int, method string, endpointID int) string {
+	if !s.client.serverVersion.AtLeast(versionStackCreateRoutes) {
+		return fmt.Sprintf("stacks?type=%d&method=%s&endpointId=%d", stackType, method, endpointID)
+	}
+
+	kind := "standalone"
+	switch stackType {
+	case StackTypeSwarm:
+		kind = "swarm"
+	case StackTypeKubernetes:
+		kind = "kubernetes"
+	}
+	return fmt.Sprintf("stacks/create/%s/%s?endpointId=%d", kind, method, endpointID)
+}
+
+func (s *StackService) deploy(endpointID, stackType int, swarmID, name, stackFileContent string, env []StackEnv) (*Stack, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	if err := writer.WriteField("Name", name); err != nil {
+		return nil, fmt.Errorf("failed to write name field: %w", err)
+	}
+
+	if swarmID != "" {
+		if err := writer.WriteField("SwarmID", swarmID); err != nil {
+			return nil, fmt.Errorf("failed to write swarm ID field: %w", err)
+		}
+	}
+
+	if err := writer.WriteField("StackFileContent", stackFileContent); err != nil {
+		return nil, fmt.Errorf("failed to write stack file content: %w", err)
+	}
+
+	if len(env) > 0 {
+		envJSON, err := json.Marshal(env)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal env: %w", err)
+		}
+		if err := writer.WriteField("Env", string(envJSON)); err != nil {
+			return nil, fmt.Errorf("failed to write env field: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	path := s.createPath(stackType, "string", endpointID)
+
+	req, err := s.client.newRequest(http.MethodPost, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Body = io.NopCloser(body)
+
+	if s.client.dryRun {
+		fmt.Fprintln(s.client.logOutput, s.client.generateCurlCommand(req))
+		return &Stack{Name: name, Type: stackType, EndpointId: endpointID, SwarmId: swarmID}, nil
+	}
+
+	resp, err := s.client.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deploy stack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var stack Stack
+	if err := json.NewDecoder(resp.Body).Decode(&stack); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &stack, nil
+}
+
+func (s *StackService) Update(stackID, endpointID int, stackFileContent string, env []StackEnv) error {
+	return s.update(stackID, endpointID, stackUpdatePayload{
+		StackFileContent: stackFileContent,
+		Env:              env,
+	})
+}
+
+type stackUpdatePayload struct {
+	StackFileContent string     `json:"stackFileContent"`
+	Env              []StackEnv `json:"env,omitempty"`
+	Prune            bool       `json:"prune,omitempty"`
+	PullImage        bool       `json:"pullImage,omitempty"`
+}
+
+func (s *StackService) update(stackID, endpointID int, payload stackUpdatePayload) error {
+	path := fmt.Sprintf("stacks/%d?endpointId=%d", stackID, endpointID)
+
+	return s.client.DoRequest(http.MethodPut, path, payload, nil)
+}
+
+// Redeploy re-applies a stack with its current configuration. Git-backed
+// stacks are redeployed from their repository; other stacks re-use their
+// current stack file. pull re-pulls images and prune removes services that
+// are no longer defined.
+func (s *StackService) Redeploy(stack *Stack, pull, prune bool) error {
+	if stack.GitConfig != nil {
+		payload := struct {
+			Env       []StackEnv `json:"env,omitempty"`
+			Prune     bool       `json:"prune"`
+			PullImage bool       `json:"pullImage"`
+		}{
+			Env:       stack.Env,
+			Prune:     prune,
+			PullImage: pull,
+		}
+
+		path := fmt.Sprintf("stacks/%d/git/redeploy?endpointId=%d", stack.Id, stack.EndpointId)
+		if err := s.client.DoRequest(http.MethodPut, path, payload, nil); err != nil {
+			return fmt.Errorf("failed to redeploy stack: %w", err)
+		}
+		return nil
+	}
+
+	// The file is read even in dry-run mode, so the printed request carries
+	// the stack file that would be deployed
+	reader := *s.client
+	reader.dryRun = false
+	content, err := NewStackService(&reader).GetFile(stack.Id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.update(stack.Id, stack.EndpointId, stackUpdatePayload{
+		StackFileContent: content,
+		Env:              stack.Env,
+		Prune:            prune,
+		PullImage:        pull,
+	}); err != nil {
+		return fmt.Errorf("failed to redeploy stack: %w", err)
+	}
+	return nil
+}
+
+// UpdateEnv replaces the environment variables of a stack and redeploys it
+// with its current stack file or Git configuration
+func (s *StackService) UpdateEnv(stack *Stack, env []StackEnv) error {
+	updated := *stack
+	updated.Env = env
+	return s.Redeploy(&updated, false, false)
+}
+
+// stackGitUpdatePayload updates the Git settings of a stack. Credentials
+// are kept by Portainer when RepositoryAuthentication is set without a
+// password.
+type stackGitUpdatePayload struct {
+	AutoUpdate               *StackAutoUpdate `json:"AutoUpdate"`
+	Env                      []StackEnv       `json:"Env"`
+	RepositoryReferenceName  string           `json:"RepositoryReferenceName"`
+	RepositoryAuthentication bool             `json:"RepositoryAuthentication"`
+	RepositoryUsername       string           `json:"RepositoryUsername,omitempty"`
+}
+
+// SetAutoUpdate enables automatic updates of a Git-backed stack, or disables
+// them when autoUpdate is nil. The stack is not redeployed.
+func (s *StackService) SetAutoUpdate(stack *Stack, autoUpdate *StackAutoUpdate) error {
+	if stack.GitConfig == nil {
+		return fmt.Errorf("stack %s is not deployed from a Git repository, auto-update requires a Git-backed stack", stack.Name)
+	}
+
+	payload := stackGitUpdatePayload{
+		AutoUpdate:              autoUpdate,
+		Env:                     stack.Env,
+		RepositoryReferenceName: stack.GitConfig.ReferenceName,
+	}
+	if auth := stack.GitConfig.Authentication; auth != nil {
+		payload.RepositoryAuthentication = true
+		payload.RepositoryUsername = auth.Username
+	}
+
+	path := fmt.Sprintf("stacks/%d/git?endpointId=%d", stack.Id, stack.EndpointId)
+	if err := s.client.Post(path, payload, nil); err != nil {
+		return fmt.Errorf("failed to update stack auto-update settings: %w", err)
+	}
+	return nil
+}
+
+// WebhookURL returns the URL that triggers the redeployment of a stack
+// with the given webhook ID
+func (s *StackService) WebhookURL(webhookID string) string {
+	return s.client.buildURL("stacks/webhooks/" + url.PathEscape(webhookID))
+}
+
+func (s *StackService) Remove(stackID, endpointID int) error {
+	path := fmt.Sprintf("stacks/%d?endpointId=%d", stackID, endpointID)
+
+	if err := s.client.Delete(path); err != nil {
+		return fmt.Errorf("failed to remove stack: %w", err)
+	}
+	return nil
+}
+
+func (s *StackService) GetFile(stackID int) (string, error) {
+	path := fmt.Sprintf("stacks/%d/file", stackID)
+
+	var response struct {
+		StackFileContent string `json:"StackFileContent"`
+	}
+
+	if err := s.client.Get(path, &response); err != nil {
+		return "", fmt.Errorf("failed to get stack file: %w", err)
+	}
+
+	return response.StackFileContent, nil
+}
+
+// StackTask is a task of a service of a Swarm stack, as shown by
+// "docker stack ps"
+type StackTask struct {
+	ID string `json:"ID"`
+	// Name is the service name and the slot, or the node of global services
+	Name         string `json:"Name"`
+	Service      string `json:"Service"`
+	Image        string `json:"Image"`
+	Node         string `json:"Node"`
+	DesiredState string `json:"DesiredState"`
+	State        string `json:"State"`
+	Timestamp    string `json:"Timestamp"`
+	Error        string `json:"Error,omitempty"`
+	ContainerID  string `json:"ContainerID,omitempty"`
+}
+
+// Tasks lists the tasks of the services of a Swarm stack on every node of
+// the swarm, including finished ones. Unlike Containers, it covers the
+// containers on nodes other than the manager of the environment.
+func (s *StackService) Tasks(stack *Stack) ([]StackTask, error) {
+	if stack.Type != StackTypeSwarm {
+		return nil, fmt.Errorf("listing tasks is not supported for %s stacks", stack.TypeString())
+	}
+
+	swarmService := NewSwarmService(s.client)
+	filters := map[string][]string{"label": {SwarmStackLabel + "=" + stack.Name}}
+	services, err := swarmService.ServiceNames(stack.EndpointId, filters)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := swarmService.Tasks(stack.EndpointId, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nodes are named by their hostname when the nodes can be listed
+	nodes := map[string]string{}
+	if list, err := swarmService.Nodes(stack.EndpointId); err == nil {
+		for _, node := range list {
+			nodes[node.ID] = node.Description.Hostname
+		}
+	}
+
+	result := make([]StackTask, 0, len(tasks))
+	for _, task := range tasks {
+		service := services[task.ServiceID]
+		if service == "" {
+			service = task.ServiceID
+		}
+		node := nodes[task.NodeID]
+		if node == "" {
+			node = task.NodeID
+		}
+		name := fmt.Sprintf("%s.%d", service, task.Slot)
+		if task.Slot == 0 {
+			name = service + "." + task.NodeID
+		}
+		image, _, _ := strings.Cut(task.Spec.ContainerSpec.Image, "@")
+
+		result = append(result, StackTask{
+			ID:           task.ID,
+			Name:         name,
+			Service:      service,
+			Image:        image,
+			Node:         node,
+			DesiredState: task.DesiredState,
+			State:        task.Status.State,
+			Timestamp:    task.Status.Timestamp,
+			Error:        task.Status.Err,
+			ContainerID:  task.Status.ContainerStatus.ContainerID,
+		})
+	}
+	return result, nil
+}
+
+// Containers lists the containers of a Compose or Swarm stack, including
+// stopped ones, matched by the project or stack namespace label. For Swarm
+// stacks these are only the containers on the node of the environment; see
+// Tasks for the whole swarm.
+func (s *StackService) Containers(stack *Stack) ([]Container, error) {
+	var label string
+	switch stack.Type {
+	case StackTypeCompose:
+		label = ComposeProjectLabel
+	case StackTypeSwarm:
+		label = SwarmStackLabel
+	default:
+		return nil, fmt.Errorf("listing containers is not supported for %s stacks", stack.TypeString())
+	}
+
+	filters := map[string][]string{"label": {label + "=" + stack.Name}}
+	containers, err := NewContainerService(s.client).ListWithFilters(stack.EndpointId, true, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stack containers: %w", err)
+	}
+	return containers, nil
+}
+
+func (stack *Stack) TypeString() string {
+	switch stack.Type {
+	case StackTypeSwarm:
+		return "Swarm"
+	case StackTypeCompose:
+		return "Compose"
+	case StackTypeKubernetes:
+		return "Kubernetes"
+	default:
+		return fmt.Sprintf("Unknown (%d)", stack.Type)
+	}
+}
+
+func (stack *Stack) StatusString() string {
+	switch stack.Status {
+	case StackStatusActive:
+		return "Active"
+	case StackStatusInactive:
+		return "Inactive"
+	default:
+		return "Unknown"
+	}
+}
+
+func ParseStackFile(filePath string) (string, error) {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return "", fmt.Errorf("stack file does not exist: %s", filePath)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stack file: %w", err)
+	}
+
+	ext := filepath.Ext(filePath)
+	if ext != ".yml" && ext != ".yaml" {
+		return "", fmt.Errorf("stack file must be a YAML file (.yml or .yaml)")
+	}
+
+	return string(content), nil
+}
@@ -0,0 +1,459 @@
+package portainer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStackService_Redeploy(t *testing.T) {
+	var payload map[string]interface{}
+	var updatePath string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/stacks/5/file":
+			json.NewEncoder(w).Encode(map[string]string{"StackFileContent": "services: {}"})
+		case r.Method == http.MethodPut:
+			updatePath = r.URL.Path + "?" + r.URL.RawQuery
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Errorf("failed to decode payload: %v", err)
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("{}"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	stackService := NewStackService(client)
+
+	stack := &Stack{Id: 5, EndpointId: 2, Env: []StackEnv{{Name: "TAG", Value: "v2"}}}
+	if err := stackService.Redeploy(stack, true, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if updatePath != "/api/stacks/5?endpointId=2" {
+		t.Errorf("unexpected update path: %s", updatePath)
+	}
+	if payload["stackFileContent"] != "services: {}" {
+		t.Errorf("expected current stack file to be re-applied, got %v", payload["stackFileContent"])
+	}
+	if payload["pullImage"] != true {
+		t.Errorf("expected pullImage to be true, got %v", payload["pullImage"])
+	}
+
+	stack.GitConfig = &StackGitConfig{}
+	if err := stackService.Redeploy(stack, false, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updatePath != "/api/stacks/5/git/redeploy?endpointId=2" {
+		t.Errorf("unexpected git redeploy path: %s", updatePath)
+	}
+	if payload["prune"] != true {
+		t.Errorf("expected prune to be true, got %v", payload["prune"])
+	}
+}
+
+func TestStackService_RedeployDryRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/api/stacks/5/file" {
+			json.NewEncoder(w).Encode(map[string]string{"StackFileContent": "services: {}"})
+			return
+		}
+		t.Errorf("unexpected request in dry-run mode: %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	var log strings.Builder
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, WithDryRun(true), WithLogOutput(&log))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if err := NewStackService(client).Redeploy(&Stack{Id: 5, EndpointId: 2}, false, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(log.String(), `"stackFileContent":"services: {}"`) {
+		t.Errorf("expected the printed request to carry the stack file, got %q", log.String())
+	}
+}
+
+func TestStackService_UpdateEnv(t *testing.T) {
+	var payload struct {
+		StackFileContent string     `json:"stackFileContent"`
+		Env              []StackEnv `json:"env"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/stacks/5/file":
+			json.NewEncoder(w).Encode(map[string]string{"StackFileContent": "services: {}"})
+		case r.Method == http.MethodPut && r.URL.Path == "/api/stacks/5":
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Errorf("failed to decode payload: %v", err)
+			}
+			w.Write([]byte("{}"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	stack := &Stack{Id: 5, EndpointId: 2, Env: []StackEnv{{Name: "TAG", Value: "v1"}}}
+	if err := NewStackService(client).UpdateEnv(stack, []StackEnv{{Name: "TAG", Value: "v2"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if payload.StackFileContent != "services: {}" {
+		t.Errorf("expected current stack file to be re-applied, got %q", payload.StackFileContent)
+	}
+	if len(payload.Env) != 1 || payload.Env[0].Value != "v2" {
+		t.Errorf("unexpected env: %+v", payload.Env)
+	}
+	if stack.Env[0].Value != "v1" {
+		t.Error("expected the stack passed in to be left unchanged")
+	}
+}
+
+func TestStackService_SetAutoUpdate(t *testing.T) {
+	var payload map[string]interface{}
+	var path string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected method: %s", r.Method)
+		}
+		path = r.URL.Path + "?" + r.URL.RawQuery
+		payload = nil
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	stackService := NewStackService(client)
+
+	stack := &Stack{
+		Id: 5, EndpointId: 2, Name: "web",
+		Env:       []StackEnv{{Name: "TAG", Value: "v1"}},
+		GitConfig: &StackGitConfig{ReferenceName: "refs/heads/main", Authentication: &GitAuthentication{Username: "bot"}},
+	}
+
+	if err := stackService.SetAutoUpdate(stack, &StackAutoUpdate{Interval: "5m", Webhook: "abc"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/api/stacks/5/git?endpointId=2" {
+		t.Errorf("unexpected path: %s", path)
+	}
+	autoUpdate, _ := payload["AutoUpdate"].(map[string]interface{})
+	if autoUpdate["Interval"] != "5m" || autoUpdate["Webhook"] != "abc" {
+		t.Errorf("unexpected auto-update settings: %v", payload["AutoUpdate"])
+	}
+	if payload["RepositoryReferenceName"] != "refs/heads/main" || payload["RepositoryAuthentication"] != true || payload["RepositoryUsername"] != "bot" {
+		t.Errorf("expected the Git settings to be kept, got %v", payload)
+	}
+	if env, _ := payload["Env"].([]interface{}); len(env) != 1 {
+		t.Errorf("expected the stack env to be kept, got %v", payload["Env"])
+	}
+
+	// Disabling sends an explicit null
+	if err := stackService.SetAutoUpdate(stack, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, ok := payload["AutoUpdate"]; !ok || value != nil {
+		t.Errorf("expected AutoUpdate to be null, got %v", value)
+	}
+
+	if err := stackService.SetAutoUpdate(&Stack{Id: 6, Name: "file"}, &StackAutoUpdate{Interval: "5m"}); err == nil {
+		t.Error("expected error for a stack without Git configuration")
+	}
+
+	if url := stackService.WebhookURL("abc"); url != server.URL+"/api/stacks/webhooks/abc" {
+		t.Errorf("unexpected webhook URL: %s", url)
+	}
+}
+
+func TestStackService_Containers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/endpoints/3/docker/containers/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var filters map[string][]string
+		json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters)
+		if len(filters["label"]) != 1 || filters["label"][0] != "com.docker.compose.project=web" {
+			t.Errorf("expected compose project filter, got %v", filters)
+		}
+
+		json.NewEncoder(w).Encode([]Container{
+			{Id: "abc", Names: []string{"/web-app-1"}, Labels: map[string]string{ComposeServiceLabel: "app"}},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	stackService := NewStackService(client)
+
+	containers, err := stackService.Containers(&Stack{Name: "web", Type: StackTypeCompose, EndpointId: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(containers) != 1 || containers[0].GetStackService() != "app" {
+		t.Errorf("unexpected containers: %+v", containers)
+	}
+
+	if _, err := stackService.Containers(&Stack{Name: "k", Type: StackTypeKubernetes, EndpointId: 3}); err == nil {
+		t.Errorf("expected error for kubernetes stack")
+	}
+}
+
+func TestStackService_Tasks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/endpoints/3/docker/services", "/api/endpoints/3/docker/tasks":
+			var filters map[string][]string
+			json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters)
+			if len(filters["label"]) != 1 || filters["label"][0] != "com.docker.stack.namespace=web" {
+				t.Errorf("expected stack namespace filter, got %v", filters)
+			}
+			if r.URL.Path == "/api/endpoints/3/docker/services" {
+				w.Write([]byte(`[{"ID":"svc1","Spec":{"Name":"web_app"}}]`))
+				return
+			}
+			w.Write([]byte(`[
+				{"ID":"task1","ServiceID":"svc1","NodeID":"node2","Slot":1,"DesiredState":"running",
+				 "Spec":{"ContainerSpec":{"Image":"nginx:1.25@sha256:abc"}},
+				 "Status":{"State":"running","ContainerStatus":{"ContainerID":"c1"}}},
+				{"ID":"task2","ServiceID":"svc1","NodeID":"node3","Slot":2,"DesiredState":"running",
+				 "Spec":{"ContainerSpec":{"Image":"nginx:1.25"}},
+				 "Status":{"State":"rejected","Err":"no suitable node"}}
+			]`))
+		case "/api/endpoints/3/docker/nodes":
+			w.Write([]byte(`[{"ID":"node2","Description":{"Hostname":"worker-1"}}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	stackService := NewStackService(client)
+
+	tasks, err := stackService.Tasks(&Stack{Name: "web", Type: StackTypeSwarm, EndpointId: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %+v", tasks)
+	}
+	if tasks[0].Name != "web_app.1" || tasks[0].Node != "worker-1" || tasks[0].Image != "nginx:1.25" || tasks[0].ContainerID != "c1" {
+		t.Errorf("unexpected first task: %+v", tasks[0])
+	}
+	if tasks[1].Node != "node3" || tasks[1].Error != "no suitable node" {
+		t.Errorf("unexpected second task: %+v", tasks[1])
+	}
+
+	if _, err := stackService.Tasks(&Stack{Name: "web", Type: StackTypeCompose, EndpointId: 3}); err == nil {
+		t.Error("expected error for compose stack")
+	}
+}
+
+func TestStackService_SwarmID(t *testing.T) {
+	manager := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/endpoints/1/docker/swarm" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !manager {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"message":"This node is not a swarm manager."}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"ID": "swarm-abc"})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	stackService := NewStackService(client)
+
+	id, err := stackService.SwarmID(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "swarm-abc" {
+		t.Errorf("expected swarm-abc, got %q", id)
+	}
+
+	manager = false
+	id, err = stackService.SwarmID(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "" {
+		t.Errorf("expected no swarm ID, got %q", id)
+	}
+
+	if _, err := stackService.SwarmID(2); err == nil {
+		t.Error("expected error for unknown environment")
+	}
+}
+
+func TestStackService_DeploySwarm(t *testing.T) {
+	var query string
+	var fields map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("failed to parse multipart form: %v", err)
+		}
+		fields = map[string]string{}
+		for key, values := range r.MultipartForm.Value {
+			fields[key] = values[0]
+		}
+		json.NewEncoder(w).Encode(Stack{Id: 9, Name: fields["Name"], Type: StackTypeSwarm})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	stackService := NewStackService(client)
+
+	stack, err := stackService.DeploySwarm(1, "swarm-abc", "web", "services: {}", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stack.Id != 9 {
+		t.Errorf("expected stack 9, got %d", stack.Id)
+	}
+	if query != "type=1&method=string&endpointId=1" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if fields["SwarmID"] != "swarm-abc" || fields["Name"] != "web" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+
+	if _, err := stackService.Deploy(1, "web", "services: {}", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "type=2&method=string&endpointId=1" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if _, ok := fields["SwarmID"]; ok {
+		t.Error("expected no SwarmID field for a Compose stack")
+	}
+
+	if _, err := stackService.DeploySwarm(1, "", "web", "services: {}", nil); err == nil {
+		t.Error("expected error without swarm ID")
+	}
+}
+
+func TestStackService_DeployKubernetes(t *testing.T) {
+	var query string
+	var payload map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		json.NewEncoder(w).Encode(Stack{Id: 4, Name: "web", Type: StackTypeKubernetes})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	stackService := NewStackService(client)
+
+	stack, err := stackService.DeployKubernetes(3, &KubernetesStackDeployRequest{
+		StackName:        "web",
+		Namespace:        "apps",
+		StackFileContent: "kind: Deployment",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stack.Id != 4 {
+		t.Errorf("expected stack 4, got %d", stack.Id)
+	}
+	if query != "type=3&method=string&endpointId=3" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if payload["Namespace"] != "apps" || payload["StackFileContent"] != "kind: Deployment" {
+		t.Errorf("unexpected payload: %v", payload)
+	}
+
+	if _, err := stackService.DeployKubernetes(3, &KubernetesStackDeployRequest{
+		StackName:     "web",
+		Namespace:     "apps",
+		RepositoryURL: "https://github.com/acme/web",
+		ManifestFile:  "k8s/web.yaml",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "type=3&method=repository&endpointId=3" {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if payload["ManifestFile"] != "k8s/web.yaml" {
+		t.Errorf("unexpected payload: %v", payload)
+	}
+}
+
+func TestStackService_CreatePath(t *testing.T) {
+	legacy, err := NewClient(&Config{URL: "http://localhost", APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if path := NewStackService(legacy).createPath(StackTypeSwarm, "string", 1); path != "stacks?type=1&method=string&endpointId=1" {
+		t.Errorf("unexpected path without a server version: %s", path)
+	}
+
+	current, err := NewClient(&Config{URL: "http://localhost", APIKey: "test-key"}, WithServerVersion("2.19.4"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	stackService := NewStackService(current)
+	tests := map[int]string{
+		StackTypeSwarm:      "stacks/create/swarm/string?endpointId=1",
+		StackTypeCompose:    "stacks/create/standalone/string?endpointId=1",
+		StackTypeKubernetes: "stacks/create/kubernetes/string?endpointId=1",
+	}
+	for stackType, expected := range tests {
+		if path := stackService.createPath(stackType, "string", 1); path != expected {
+			t.Errorf("type %d: expected %s, got %s", stackType, expected, path)
+		}
+	}
+}
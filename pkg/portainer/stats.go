@@ -0,0 +1,83 @@
+package portainer
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// Stats counts the API traffic of the clients created WithStats. One Stats
+// can be shared by several clients and is safe for concurrent use.
+type Stats struct {
+	requests      atomic.Int64
+	retries       atomic.Int64
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+}
+
+// StatsSummary is a snapshot of Stats
+type StatsSummary struct {
+	// Requests counts every HTTP request, including retries
+	Requests int64 `json:"requests"`
+	Retries  int64 `json:"retries"`
+	// BytesSent and BytesReceived count request and response bodies
+	BytesSent     int64 `json:"bytes_sent"`
+	BytesReceived int64 `json:"bytes_received"`
+}
+
+// Summary returns the current counts
+func (s *Stats) Summary() StatsSummary {
+	return StatsSummary{
+		Requests:      s.requests.Load(),
+		Retries:       s.retries.Load(),
+		BytesSent:     s.bytesSent.Load(),
+		BytesReceived: s.bytesReceived.Load(),
+	}
+}
+
+// WithStats counts the requests, retries and transferred bytes of the
+// client in stats. Interactive attach and exec sessions are not counted.
+func WithStats(stats *Stats) ClientOption {
+	return func(c *Client) {
+		c.stats = stats
+	}
+}
+
+func (c *Client) countRetry() {
+	if c.stats != nil {
+		c.stats.retries.Add(1)
+	}
+}
+
+// statsTransport counts requests and body bytes passing through base
+type statsTransport struct {
+	base  http.RoundTripper
+	stats *Stats
+}
+
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.stats.requests.Add(1)
+	if req.Body != nil && req.Body != http.NoBody {
+		counted := *req
+		counted.Body = &countingReadCloser{ReadCloser: req.Body, count: &t.stats.bytesSent}
+		req = &counted
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &countingReadCloser{ReadCloser: resp.Body, count: &t.stats.bytesReceived}
+	return resp, nil
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	count *atomic.Int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.count.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,41 @@
+package portainer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_WithStats(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"Name":"web"}`))
+	}))
+	defer server.Close()
+
+	stats := &Stats{}
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, WithStats(stats))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	client.retryDelay = time.Millisecond
+
+	var result map[string]string
+	if err := client.Put("stacks", map[string]string{"name": "web"}, &result); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	expected := StatsSummary{Requests: 2, Retries: 1, BytesSent: 2 * int64(len(`{"name":"web"}`)), BytesReceived: int64(len(`{"Name":"web"}`))}
+	if summary := stats.Summary(); summary != expected {
+		t.Errorf("expected %+v, got %+v", expected, summary)
+	}
+	if client.transport() == nil {
+		t.Error("expected the HTTP transport to be found below the stats transport")
+	}
+}
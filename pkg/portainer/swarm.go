@@ -0,0 +1,315 @@
+package portainer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Swarm node roles, as used by join tokens and node specs
+const (
+	SwarmRoleWorker  = "worker"
+	SwarmRoleManager = "manager"
+)
+
+// swarmPort is the default port of the Swarm management API
+const swarmPort = "2377"
+
+// ErrNotSwarmManager is returned for environments whose Docker engine is not
+// a manager of a swarm: only managers can report the cluster
+var ErrNotSwarmManager = errors.New("the environment is not a Swarm manager")
+
+type SwarmService struct {
+	client *Client
+}
+
+func NewSwarmService(client *Client) *SwarmService {
+	return &SwarmService{client: client}
+}
+
+// Swarm is the Docker engine's view of the swarm, including the join
+// tokens. Spec is kept undecoded so it can be sent back unchanged on
+// updates.
+type Swarm struct {
+	ID      string `json:"ID"`
+	Version struct {
+		Index uint64 `json:"Index"`
+	} `json:"Version"`
+	CreatedAt  string          `json:"CreatedAt"`
+	UpdatedAt  string          `json:"UpdatedAt"`
+	Spec       json.RawMessage `json:"Spec"`
+	JoinTokens struct {
+		Worker  string `json:"Worker"`
+		Manager string `json:"Manager"`
+	} `json:"JoinTokens"`
+}
+
+// SwarmInfo describes the swarm of an environment and the membership of its
+// node. It carries no join tokens, so it is safe to print.
+type SwarmInfo struct {
+	ID        string `json:"ID"`
+	CreatedAt string `json:"CreatedAt"`
+	UpdatedAt string `json:"UpdatedAt"`
+	NodeID    string `json:"NodeID"`
+	NodeAddr  string `json:"NodeAddr"`
+	Nodes     int    `json:"Nodes"`
+	Managers  int    `json:"Managers"`
+	// ManagerAddrs are the addresses other nodes join the swarm at
+	ManagerAddrs []string `json:"ManagerAddrs"`
+}
+
+// SwarmNode is a node of a swarm, as listed by "docker node ls"
+type SwarmNode struct {
+	ID          string `json:"ID"`
+	Description struct {
+		Hostname string `json:"Hostname"`
+		Platform struct {
+			Architecture string `json:"Architecture"`
+			OS           string `json:"OS"`
+		} `json:"Platform"`
+		Resources struct {
+			NanoCPUs    int64 `json:"NanoCPUs"`
+			MemoryBytes int64 `json:"MemoryBytes"`
+		} `json:"Resources"`
+		Engine struct {
+			EngineVersion string `json:"EngineVersion"`
+		} `json:"Engine"`
+	} `json:"Description"`
+	Spec struct {
+		Role         string            `json:"Role"`
+		Availability string            `json:"Availability"`
+		Labels       map[string]string `json:"Labels,omitempty"`
+	} `json:"Spec"`
+	Status struct {
+		State string `json:"State"`
+		Addr  string `json:"Addr"`
+	} `json:"Status"`
+	ManagerStatus *struct {
+		Leader       bool   `json:"Leader"`
+		Reachability string `json:"Reachability"`
+		Addr         string `json:"Addr"`
+	} `json:"ManagerStatus,omitempty"`
+}
+
+// ManagerState returns "Leader", the reachability of other managers, or an
+// empty string for workers, as shown by "docker node ls"
+func (n *SwarmNode) ManagerState() string {
+	if n.ManagerStatus == nil {
+		return ""
+	}
+	if n.ManagerStatus.Leader {
+		return "Leader"
+	}
+	return n.ManagerStatus.Reachability
+}
+
+// Info returns the swarm of an environment. It fails with ErrNotSwarmManager
+// when the engine is a worker or not part of a swarm.
+func (s *SwarmService) Info(endpointID int) (*SwarmInfo, error) {
+	var engine struct {
+		Swarm struct {
+			NodeID           string `json:"NodeID"`
+			NodeAddr         string `json:"NodeAddr"`
+			ControlAvailable bool   `json:"ControlAvailable"`
+			Nodes            int    `json:"Nodes"`
+			Managers         int    `json:"Managers"`
+			RemoteManagers   []struct {
+				NodeID string `json:"NodeID"`
+				Addr   string `json:"Addr"`
+			} `json:"RemoteManagers"`
+		} `json:"Swarm"`
+	}
+	if err := s.client.Get(fmt.Sprintf("endpoints/%d/docker/info", endpointID), &engine); err != nil {
+		return nil, fmt.Errorf("failed to get engine info: %w", err)
+	}
+	if s.client.dryRun {
+		return &SwarmInfo{}, nil
+	}
+	if !engine.Swarm.ControlAvailable {
+		return nil, ErrNotSwarmManager
+	}
+
+	swarm, err := s.Inspect(endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &SwarmInfo{
+		ID:        swarm.ID,
+		CreatedAt: swarm.CreatedAt,
+		UpdatedAt: swarm.UpdatedAt,
+		NodeID:    engine.Swarm.NodeID,
+		NodeAddr:  engine.Swarm.NodeAddr,
+		Nodes:     engine.Swarm.Nodes,
+		Managers:  engine.Swarm.Managers,
+	}
+	// The address of the node first, as "docker swarm join-token" prints it
+	for _, manager := range engine.Swarm.RemoteManagers {
+		if manager.NodeID == info.NodeID {
+			info.ManagerAddrs = append([]string{manager.Addr}, info.ManagerAddrs...)
+		} else {
+			info.ManagerAddrs = append(info.ManagerAddrs, manager.Addr)
+		}
+	}
+	if len(info.ManagerAddrs) == 0 && info.NodeAddr != "" {
+		info.ManagerAddrs = []string{info.NodeAddr + ":" + swarmPort}
+	}
+	return info, nil
+}
+
+// Inspect returns the swarm of an environment, including its join tokens
+func (s *SwarmService) Inspect(endpointID int) (*Swarm, error) {
+	var swarm Swarm
+	if err := s.client.Get(fmt.Sprintf("endpoints/%d/docker/swarm", endpointID), &swarm); err != nil {
+		if isNotSwarmManagerError(err) {
+			return nil, ErrNotSwarmManager
+		}
+		return nil, fmt.Errorf("failed to inspect swarm: %w", err)
+	}
+	return &swarm, nil
+}
+
+// JoinToken returns the token nodes join the swarm with as role
+func (s *SwarmService) JoinToken(endpointID int, role string) (string, error) {
+	if err := checkSwarmRole(role); err != nil {
+		return "", err
+	}
+	swarm, err := s.Inspect(endpointID)
+	if err != nil {
+		return "", err
+	}
+	if role == SwarmRoleManager {
+		return swarm.JoinTokens.Manager, nil
+	}
+	return swarm.JoinTokens.Worker, nil
+}
+
+// RotateJoinToken replaces the join token of role, so the previous token can
+// no longer be used to join the swarm. Nodes that already joined are not
+// affected.
+func (s *SwarmService) RotateJoinToken(endpointID int, role string) error {
+	if err := checkSwarmRole(role); err != nil {
+		return err
+	}
+	swarm, err := s.Inspect(endpointID)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("version", fmt.Sprintf("%d", swarm.Version.Index))
+	if role == SwarmRoleManager {
+		query.Set("rotateManagerToken", "true")
+	} else {
+		query.Set("rotateWorkerToken", "true")
+	}
+	spec := swarm.Spec
+	if len(spec) == 0 {
+		spec = json.RawMessage("{}")
+	}
+	path := fmt.Sprintf("endpoints/%d/docker/swarm/update?%s", endpointID, query.Encode())
+	if err := s.client.Post(path, spec, nil); err != nil {
+		return fmt.Errorf("failed to rotate %s join token: %w", role, err)
+	}
+	return nil
+}
+
+// Nodes lists the nodes of the swarm of an environment
+func (s *SwarmService) Nodes(endpointID int) ([]SwarmNode, error) {
+	var nodes []SwarmNode
+	if err := s.client.Get(fmt.Sprintf("endpoints/%d/docker/nodes", endpointID), &nodes); err != nil {
+		if isNotSwarmManagerError(err) {
+			return nil, ErrNotSwarmManager
+		}
+		return nil, fmt.Errorf("failed to list swarm nodes: %w", err)
+	}
+	return nodes, nil
+}
+
+// SwarmTask is a task of a Swarm service: the slot one of its containers
+// runs in on a node, as listed by "docker service ps"
+type SwarmTask struct {
+	ID           string `json:"ID"`
+	ServiceID    string `json:"ServiceID"`
+	NodeID       string `json:"NodeID"`
+	Slot         int    `json:"Slot"`
+	DesiredState string `json:"DesiredState"`
+	Spec         struct {
+		ContainerSpec struct {
+			Image string `json:"Image"`
+		} `json:"ContainerSpec"`
+	} `json:"Spec"`
+	Status struct {
+		Timestamp       string `json:"Timestamp"`
+		State           string `json:"State"`
+		Message         string `json:"Message"`
+		Err             string `json:"Err"`
+		ContainerStatus struct {
+			ContainerID string `json:"ContainerID"`
+		} `json:"ContainerStatus"`
+	} `json:"Status"`
+}
+
+// Tasks lists the tasks of the swarm of an environment, narrowed by Docker
+// list filters such as {"label": ["com.docker.stack.namespace=web"]}
+func (s *SwarmService) Tasks(endpointID int, filters map[string][]string) ([]SwarmTask, error) {
+	var tasks []SwarmTask
+	if err := s.client.Get(swarmListPath(endpointID, "tasks", filters), &tasks); err != nil {
+		if isNotSwarmManagerError(err) {
+			return nil, ErrNotSwarmManager
+		}
+		return nil, fmt.Errorf("failed to list swarm tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// ServiceNames returns the names of the Swarm services matching filters,
+// by service ID
+func (s *SwarmService) ServiceNames(endpointID int, filters map[string][]string) (map[string]string, error) {
+	var services []struct {
+		ID   string `json:"ID"`
+		Spec struct {
+			Name string `json:"Name"`
+		} `json:"Spec"`
+	}
+	if err := s.client.Get(swarmListPath(endpointID, "services", filters), &services); err != nil {
+		if isNotSwarmManagerError(err) {
+			return nil, ErrNotSwarmManager
+		}
+		return nil, fmt.Errorf("failed to list swarm services: %w", err)
+	}
+
+	names := make(map[string]string, len(services))
+	for _, service := range services {
+		names[service.ID] = service.Spec.Name
+	}
+	return names, nil
+}
+
+// swarmListPath returns the Docker proxy path listing the tasks or services
+// of a swarm matching filters
+func swarmListPath(endpointID int, resource string, filters map[string][]string) string {
+	path := fmt.Sprintf("endpoints/%d/docker/%s", endpointID, resource)
+	if len(filters) > 0 {
+		// Marshaling a map of string slices cannot fail
+		filtersJSON, _ := json.Marshal(filters)
+		path += "?" + url.Values{"filters": {string(filtersJSON)}}.Encode()
+	}
+	return path
+}
+
+// isNotSwarmManagerError reports whether Docker refused a Swarm request
+// because the node is not part of a swarm (503) or is only a worker
+func isNotSwarmManagerError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusServiceUnavailable || apiErr.StatusCode == http.StatusNotAcceptable)
+}
+
+func checkSwarmRole(role string) error {
+	if role != SwarmRoleWorker && role != SwarmRoleManager {
+		return fmt.Errorf("invalid swarm role %q: must be %s or %s", role, SwarmRoleWorker, SwarmRoleManager)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package portainer
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSwarmService_Info(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/endpoints/1/docker/info":
+			w.Write([]byte(`{"Swarm":{"NodeID":"node2","NodeAddr":"10.0.0.2","ControlAvailable":true,"Nodes":3,"Managers":2,
+				"RemoteManagers":[{"NodeID":"node1","Addr":"10.0.0.1:2377"},{"NodeID":"node2","Addr":"10.0.0.2:2377"}]}}`))
+		case "/api/endpoints/1/docker/swarm":
+			w.Write([]byte(`{"ID":"swarm1","Version":{"Index":42},"Spec":{"Name":"default"},"JoinTokens":{"Worker":"SWMTKN-worker","Manager":"SWMTKN-manager"}}`))
+		case "/api/endpoints/2/docker/info":
+			w.Write([]byte(`{"Swarm":{"NodeID":"","LocalNodeState":"inactive"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	swarmService := NewSwarmService(client)
+
+	info, err := swarmService.Info(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.ID != "swarm1" || info.Nodes != 3 || info.Managers != 2 {
+		t.Errorf("unexpected swarm info %+v", info)
+	}
+	if len(info.ManagerAddrs) != 2 || info.ManagerAddrs[0] != "10.0.0.2:2377" {
+		t.Errorf("expected the address of the node first, got %v", info.ManagerAddrs)
+	}
+
+	token, err := swarmService.JoinToken(1, SwarmRoleManager)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "SWMTKN-manager" {
+		t.Errorf("expected manager token, got %s", token)
+	}
+
+	if _, err := swarmService.Info(2); !errors.Is(err, ErrNotSwarmManager) {
+		t.Errorf("expected ErrNotSwarmManager, got %v", err)
+	}
+	if _, err := swarmService.JoinToken(1, "admin"); err == nil {
+		t.Error("expected an error for an unknown role")
+	}
+}
+
+func TestSwarmService_RotateJoinToken(t *testing.T) {
+	var query, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/endpoints/1/docker/swarm":
+			w.Write([]byte(`{"ID":"swarm1","Version":{"Index":42},"Spec":{"Name":"default"}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/endpoints/1/docker/swarm/update":
+			query = r.URL.RawQuery
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if err := NewSwarmService(client).RotateJoinToken(1, SwarmRoleWorker); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "rotateWorkerToken=true&version=42" {
+		t.Errorf("unexpected query %q", query)
+	}
+	if body != `{"Name":"default"}` {
+		t.Errorf("expected the spec to be sent back unchanged, got %s", body)
+	}
+}
+
+func TestSwarmService_NodesNotManager(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"message":"This node is not a swarm manager."}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := NewSwarmService(client).Nodes(1); !errors.Is(err, ErrNotSwarmManager) {
+		t.Errorf("expected ErrNotSwarmManager, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package portainer
+
+import (
+	"fmt"
+	"strings"
+)
+
+type TeamService struct {
+	client *Client
+}
+
+type Team struct {
+	Id   int    `json:"Id"`
+	Name string `json:"Name"`
+}
+
+func NewTeamService(client *Client) *TeamService {
+	return &TeamService{client: client}
+}
+
+func (s *TeamService) List() ([]Team, error) {
+	var teams []Team
+	if err := s.client.Get("teams", &teams); err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	return teams, nil
+}
+
+func (s *TeamService) GetByName(name string) (*Team, error) {
+	teams, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, team := range teams {
+		if strings.EqualFold(team.Name, name) {
+			return &team, nil
+		}
+	}
+
+	return nil, fmt.Errorf("team not found: %s", name)
+}
@@ -0,0 +1,59 @@
+package portainer
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Connection pool settings of the client transports. Commands covering many
+// environments send their requests concurrently to a single Portainer
+// server, so several idle connections per host are kept.
+const (
+	maxIdleConns        = 100
+	maxIdleConnsPerHost = 16
+	idleConnTimeout     = 90 * time.Second
+	dialTimeout         = 30 * time.Second
+	dialKeepAlive       = 30 * time.Second
+	tlsHandshakeTimeout = 10 * time.Second
+)
+
+var (
+	sharedTransportsMu sync.Mutex
+	sharedTransports   = map[bool]*http.Transport{}
+)
+
+// sharedTransport returns the transport shared by the clients with the
+// given certificate verification setting
+func sharedTransport(insecure bool) *http.Transport {
+	sharedTransportsMu.Lock()
+	defer sharedTransportsMu.Unlock()
+
+	transport, ok := sharedTransports[insecure]
+	if !ok {
+		transport = newTransport(&tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: insecure,
+		})
+		sharedTransports[insecure] = transport
+	}
+	return transport
+}
+
+// newTransport creates a transport that keeps connections alive between
+// requests and negotiates HTTP/2 with servers supporting it
+func newTransport(tlsConfig *tls.Config) *http.Transport {
+	dialer := &net.Dialer{Timeout: dialTimeout, KeepAlive: dialKeepAlive}
+	return &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdleConns,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       idleConnTimeout,
+		TLSHandshakeTimeout:   tlsHandshakeTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+}
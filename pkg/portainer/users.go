@@ -0,0 +1,73 @@
+package portainer
+
+import (
+	"fmt"
+)
+
+type UserService struct {
+	client *Client
+}
+
+type User struct {
+	Id       int    `json:"Id"`
+	Username string `json:"Username"`
+	Role     int    `json:"Role"`
+}
+
+const (
+	UserRoleAdministrator = 1
+	UserRoleStandard      = 2
+)
+
+func (u *User) RoleString() string {
+	switch u.Role {
+	case UserRoleAdministrator:
+		return "Administrator"
+	case UserRoleStandard:
+		return "Standard"
+	default:
+		return "Unknown"
+	}
+}
+
+func NewUserService(client *Client) *UserService {
+	return &UserService{client: client}
+}
+
+// List returns all users, paging through the list on servers that paginate
+// it
+func (s *UserService) List() ([]User, error) {
+	return listAll(s.ListPage)
+}
+
+// ListPage returns up to limit users starting at start, along with the
+// total number of users reported by the server. The total is -1 when the
+// server does not support pagination, in which case all users are returned.
+func (s *UserService) ListPage(start, limit int) ([]User, int, error) {
+	path := "users"
+	if limit > 0 {
+		path = fmt.Sprintf("users?start=%d&limit=%d", start, limit)
+	}
+
+	var users []User
+	headers, err := s.client.GetWithHeaders(path, &users)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, totalCount(headers), nil
+}
+
+func (s *UserService) GetByUsername(username string) (*User, error) {
+	users, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+
+	return nil, fmt.Errorf("user not found: %s", username)
+}
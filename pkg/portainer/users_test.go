@@ -0,0 +1,80 @@
+package portainer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestUserService_List(t *testing.T) {
+	all := make([]User, 130)
+	for i := range all {
+		all[i] = User{Id: i + 1, Username: fmt.Sprintf("user-%d", i+1), Role: UserRoleStandard}
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/api/users" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := start + limit
+		if end > len(all) {
+			end = len(all)
+		}
+
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(all)))
+		json.NewEncoder(w).Encode(all[start:end])
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	userService := NewUserService(client)
+
+	users, err := userService.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 130 || users[129].Username != "user-130" {
+		t.Errorf("expected 130 users, got %d", len(users))
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+
+	page, total, err := userService.ListPage(0, PageSize)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != PageSize || total != 130 {
+		t.Errorf("expected the first page of 130 users, got %d of %d", len(page), total)
+	}
+}
+
+func TestUserService_ListWithoutPagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"Id":1,"Username":"admin","Role":1},{"Id":2,"Username":"alice","Role":2}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	users, err := NewUserService(client).List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 2 || users[0].RoleString() != "Administrator" || users[1].RoleString() != "Standard" {
+		t.Errorf("unexpected users: %+v", users)
+	}
+}
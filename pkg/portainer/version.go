@@ -0,0 +1,125 @@
+package portainer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Version is a Portainer server version. The zero Version means unknown.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// MinSupportedVersion is the oldest Portainer release the client supports.
+// Releases of a newer major version are not supported either.
+var MinSupportedVersion = Version{Major: 2, Minor: 16}
+
+// Version thresholds of API changes the client adapts to
+var (
+	// versionStackCreateRoutes moved stack creation from
+	// stacks?type=&method= to stacks/create/{type}/{method}
+	versionStackCreateRoutes = Version{Major: 2, Minor: 19}
+	// versionNamespaceUpdateRoute moved namespace updates from
+	// kubernetes/{id}/namespaces to kubernetes/{id}/namespaces/{name}
+	versionNamespaceUpdateRoute = Version{Major: 2, Minor: 19}
+)
+
+// ParseVersion parses versions such as 2.19.4, v2.19 or 2.20.0-rc1
+func ParseVersion(s string) (Version, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(trimmed, "-+ "); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+
+	parts := strings.Split(trimmed, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// IsZero reports whether the version is unknown
+func (v Version) IsZero() bool {
+	return v == Version{}
+}
+
+// AtLeast reports whether v is other or a later release
+func (v Version) AtLeast(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor > other.Minor
+	}
+	return v.Patch >= other.Patch
+}
+
+// Supported reports whether the client supports the API of this release
+func (v Version) Supported() bool {
+	return v.AtLeast(MinSupportedVersion) && v.Major == MinSupportedVersion.Major
+}
+
+// WithServerVersion tells the client the version of the Portainer server,
+// for example from a cache, so requests match its API without detecting it
+// first. Invalid versions are ignored.
+func WithServerVersion(version string) ClientOption {
+	return func(c *Client) {
+		if v, err := ParseVersion(version); err == nil {
+			c.serverVersion = v
+		}
+	}
+}
+
+// ServerVersion returns the server version set with WithServerVersion or
+// found by DetectServerVersion, or the zero Version when it is unknown
+func (c *Client) ServerVersion() Version {
+	return c.serverVersion
+}
+
+// DetectServerVersion asks the server for its version and adapts the
+// following requests to its API. The request is sent in dry-run mode too.
+func (c *Client) DetectServerVersion() (Version, error) {
+	req, err := c.newRequest(http.MethodGet, "status", nil)
+	if err != nil {
+		return Version{}, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to get status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return Version{}, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	var status StatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return Version{}, fmt.Errorf("failed to decode status: %w", err)
+	}
+
+	v, err := ParseVersion(status.Version)
+	if err != nil {
+		return Version{}, err
+	}
+	c.serverVersion = v
+	return v, nil
+}
@@ -0,0 +1,83 @@
+package portainer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := map[string]Version{
+		"2.19.4":     {2, 19, 4},
+		"v2.19":      {2, 19, 0},
+		"2.20.0-rc1": {2, 20, 0},
+		" 2.16.2 \n": {2, 16, 2},
+	}
+	for input, expected := range tests {
+		v, err := ParseVersion(input)
+		if err != nil {
+			t.Errorf("ParseVersion(%q): unexpected error: %v", input, err)
+		} else if v != expected {
+			t.Errorf("ParseVersion(%q) = %v, expected %v", input, v, expected)
+		}
+	}
+
+	for _, input := range []string{"", "2", "latest", "2.x.1", "1.2.3.4"} {
+		if _, err := ParseVersion(input); err == nil {
+			t.Errorf("ParseVersion(%q): expected error", input)
+		}
+	}
+}
+
+func TestVersion_Supported(t *testing.T) {
+	tests := map[Version]bool{
+		{2, 15, 9}: false,
+		{2, 16, 0}: true,
+		{2, 21, 4}: true,
+		{3, 0, 0}:  false,
+	}
+	for v, expected := range tests {
+		if v.Supported() != expected {
+			t.Errorf("%s: expected supported %t", v, expected)
+		}
+	}
+
+	if !(Version{2, 19, 0}).AtLeast(Version{2, 18, 7}) || (Version{2, 19, 0}).AtLeast(Version{2, 19, 1}) {
+		t.Error("unexpected AtLeast result")
+	}
+}
+
+func TestClient_DetectServerVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/status" {
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(StatusResponse{Version: "2.19.4"})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, WithDryRun(true))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if !client.ServerVersion().IsZero() {
+		t.Errorf("expected an unknown version, got %s", client.ServerVersion())
+	}
+
+	v, err := client.DetectServerVersion()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != (Version{2, 19, 4}) || client.ServerVersion() != v {
+		t.Errorf("unexpected version %s", v)
+	}
+
+	seeded, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"}, WithServerVersion("2.17.1"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if seeded.ServerVersion() != (Version{2, 17, 1}) {
+		t.Errorf("expected the seeded version, got %s", seeded.ServerVersion())
+	}
+}
@@ -0,0 +1,362 @@
+package portainer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+type VolumeService struct {
+	client *Client
+}
+
+type Volume struct {
+	Name       string            `json:"Name"`
+	Driver     string            `json:"Driver"`
+	Mountpoint string            `json:"Mountpoint"`
+	CreatedAt  string            `json:"CreatedAt"`
+	Status     map[string]string `json:"Status,omitempty"`
+	Labels     map[string]string `json:"Labels"`
+	Scope      string            `json:"Scope"`
+	Options    map[string]string `json:"Options"`
+}
+
+type VolumeListResponse struct {
+	Volumes  []Volume `json:"Volumes"`
+	Warnings []string `json:"Warnings"`
+}
+
+type VolumeDetails struct {
+	Name       string                 `json:"Name"`
+	Driver     string                 `json:"Driver"`
+	Mountpoint string                 `json:"Mountpoint"`
+	CreatedAt  string                 `json:"CreatedAt"`
+	Status     map[string]interface{} `json:"Status,omitempty"`
+	Labels     map[string]string      `json:"Labels"`
+	Scope      string                 `json:"Scope"`
+	Options    map[string]string      `json:"Options"`
+	UsageData  *VolumeUsageData       `json:"UsageData,omitempty"`
+}
+
+type VolumeUsageData struct {
+	Size     int64 `json:"Size"`
+	RefCount int64 `json:"RefCount"`
+}
+
+type VolumeCreateRequest struct {
+	Name       string            `json:"Name"`
+	Driver     string            `json:"Driver,omitempty"`
+	DriverOpts map[string]string `json:"DriverOpts,omitempty"`
+	Labels     map[string]string `json:"Labels,omitempty"`
+}
+
+func NewVolumeService(client *Client) *VolumeService {
+	return &VolumeService{client: client}
+}
+
+func (s *VolumeService) List(endpointID int) ([]Volume, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/volumes", endpointID)
+
+	var response VolumeListResponse
+	if err := s.client.Get(path, &response); err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+	return response.Volumes, nil
+}
+
+func (s *VolumeService) Inspect(endpointID int, volumeName string) (*VolumeDetails, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/volumes/%s", endpointID, url.PathEscape(volumeName))
+
+	var volume VolumeDetails
+	if err := s.client.Get(path, &volume); err != nil {
+		return nil, fmt.Errorf("failed to inspect volume: %w", err)
+	}
+	return &volume, nil
+}
+
+func (s *VolumeService) Create(endpointID int, req *VolumeCreateRequest) (*Volume, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/volumes/create", endpointID)
+
+	var volume Volume
+	if err := s.client.Post(path, req, &volume); err != nil {
+		return nil, fmt.Errorf("failed to create volume: %w", err)
+	}
+	return &volume, nil
+}
+
+func (s *VolumeService) Remove(endpointID int, volumeName string, force bool) error {
+	path := fmt.Sprintf("endpoints/%d/docker/volumes/%s?force=%t", endpointID, url.PathEscape(volumeName), force)
+
+	if err := s.client.Delete(path); err != nil {
+		return fmt.Errorf("failed to remove volume: %w", err)
+	}
+	return nil
+}
+
+// VolumePruneReport lists the volumes removed by a prune and the disk space
+// that was freed
+type VolumePruneReport struct {
+	VolumesDeleted []string `json:"VolumesDeleted"`
+	SpaceReclaimed int64    `json:"SpaceReclaimed"`
+}
+
+func (s *VolumeService) Prune(endpointID int) (*VolumePruneReport, error) {
+	path := fmt.Sprintf("endpoints/%d/docker/volumes/prune", endpointID)
+
+	var report VolumePruneReport
+	if err := s.client.Post(path, nil, &report); err != nil {
+		return nil, fmt.Errorf("failed to prune volumes: %w", err)
+	}
+	return &report, nil
+}
+
+// Browse lists the files in a volume directory through the Portainer agent
+func (s *VolumeService) Browse(endpointID int, volumeName, path string) ([]FileInfo, error) {
+	files, err := s.client.browseList(endpointID, volumeName, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to browse volume: %w", err)
+	}
+	return files, nil
+}
+
+// Download opens a file in a volume for reading. The caller must close the
+// returned reader, which is nil in dry-run mode.
+func (s *VolumeService) Download(endpointID int, volumeName, path string) (io.ReadCloser, error) {
+	body, err := s.client.browseGet(endpointID, volumeName, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	return body, nil
+}
+
+// Upload copies a local file into a directory of a volume
+func (s *VolumeService) Upload(endpointID int, volumeName, dir, localPath string) error {
+	if err := s.client.browsePut(endpointID, volumeName, dir, localPath); err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	return nil
+}
+
+// DefaultVolumeHelperImage is the image of the short-lived containers that
+// access volume data
+const DefaultVolumeHelperImage = "busybox:latest"
+
+// VolumeCloneOptions controls how a volume is cloned
+type VolumeCloneOptions struct {
+	// HelperImage is the image of the container that copies the data. It
+	// needs a shell and cp; DefaultVolumeHelperImage is used when empty.
+	HelperImage string
+}
+
+// Clone copies the data of a volume into another one through a temporary
+// helper container. The destination is created with the driver and labels
+// of the source when it does not exist; the driver options are not copied
+// since they usually point at the source's storage.
+func (s *VolumeService) Clone(endpointID int, source, destination string, opts VolumeCloneOptions) error {
+	if source == destination {
+		return fmt.Errorf("source and destination volume are the same")
+	}
+
+	src, err := s.Inspect(endpointID, source)
+	if err != nil {
+		return err
+	}
+
+	// The inspect error is checked unwrapped to tell a missing volume apart
+	var existing VolumeDetails
+	if err := s.client.Get(fmt.Sprintf("endpoints/%d/docker/volumes/%s", endpointID, url.PathEscape(destination)), &existing); err != nil {
+		if !IsNotFoundError(err) {
+			return fmt.Errorf("failed to inspect volume: %w", err)
+		}
+		if _, err := s.Create(endpointID, &VolumeCreateRequest{
+			Name:   destination,
+			Driver: src.Driver,
+			Labels: src.Labels,
+		}); err != nil {
+			return err
+		}
+	}
+
+	containerID, err := s.createHelper(endpointID, opts.HelperImage, []string{"sh", "-c", "cp -a /from/. /to/"}, []volumeMount{
+		{Type: "volume", Source: source, Target: "/from", ReadOnly: true},
+		{Type: "volume", Source: destination, Target: "/to"},
+	})
+	if err != nil {
+		return err
+	}
+	defer s.removeHelper(endpointID, containerID)
+
+	return s.runHelper(endpointID, containerID)
+}
+
+// VolumeArchiveOptions controls the helper container of a volume backup or
+// restore
+type VolumeArchiveOptions struct {
+	// HelperImage is the image of the container the volume is mounted in;
+	// DefaultVolumeHelperImage is used when empty
+	HelperImage string
+}
+
+// volumeArchiveDir is where the helper container mounts the volume. Backups
+// hold the volume's files under this directory name.
+const volumeArchiveDir = "/volume"
+
+// Backup writes the content of a volume to w as an uncompressed tar archive
+// whose entries are under a top-level "volume" directory. The volume is
+// mounted read-only in a helper container that is never started. It
+// returns the number of bytes written.
+func (s *VolumeService) Backup(endpointID int, volumeName string, w io.Writer, opts VolumeArchiveOptions) (int64, error) {
+	if _, err := s.Inspect(endpointID, volumeName); err != nil {
+		return 0, err
+	}
+
+	containerID, err := s.createHelper(endpointID, opts.HelperImage, nil, []volumeMount{
+		{Type: "volume", Source: volumeName, Target: volumeArchiveDir, ReadOnly: true},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if containerID == "" {
+		// Dry-run: the container was not created
+		return 0, nil
+	}
+	defer s.removeHelper(endpointID, containerID)
+
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/archive?path=%s", endpointID, containerID, url.QueryEscape(volumeArchiveDir))
+	body, err := s.client.stream(http.MethodGet, path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read volume archive: %w", err)
+	}
+	defer body.Close()
+
+	n, err := io.Copy(w, body)
+	if err != nil {
+		return n, fmt.Errorf("failed to write volume archive: %w", err)
+	}
+	return n, nil
+}
+
+// Restore extracts a tar archive created by Backup into a volume, which is
+// created with the default driver when it does not exist. Files already in
+// the volume are kept unless the archive replaces them.
+func (s *VolumeService) Restore(endpointID int, volumeName string, r io.Reader, opts VolumeArchiveOptions) error {
+	// The inspect error is checked unwrapped to tell a missing volume apart
+	var existing VolumeDetails
+	if err := s.client.Get(fmt.Sprintf("endpoints/%d/docker/volumes/%s", endpointID, url.PathEscape(volumeName)), &existing); err != nil {
+		if !IsNotFoundError(err) {
+			return fmt.Errorf("failed to inspect volume: %w", err)
+		}
+		if _, err := s.Create(endpointID, &VolumeCreateRequest{Name: volumeName}); err != nil {
+			return err
+		}
+	}
+
+	containerID, err := s.createHelper(endpointID, opts.HelperImage, nil, []volumeMount{
+		{Type: "volume", Source: volumeName, Target: volumeArchiveDir},
+	})
+	if err != nil {
+		return err
+	}
+	defer s.removeHelper(endpointID, containerID)
+
+	path := fmt.Sprintf("endpoints/%d/docker/containers/%s/archive?path=%s", endpointID, containerID, url.QueryEscape("/"))
+	if err := s.client.upload(http.MethodPut, path, "application/x-tar", r); err != nil {
+		return fmt.Errorf("failed to restore volume archive: %w", err)
+	}
+	return nil
+}
+
+type volumeMount struct {
+	Type     string `json:"Type"`
+	Source   string `json:"Source"`
+	Target   string `json:"Target"`
+	ReadOnly bool   `json:"ReadOnly,omitempty"`
+}
+
+// createHelper creates a container of image that runs cmd, or the image's
+// default command when cmd is empty, with mounts, pulling the image first
+// when the endpoint does not have it. It returns the ID of the container,
+// which is empty in dry-run mode.
+func (s *VolumeService) createHelper(endpointID int, image string, cmd []string, mounts []volumeMount) (string, error) {
+	if image == "" {
+		image = DefaultVolumeHelperImage
+	}
+
+	var details ImageDetails
+	if err := s.client.Get(fmt.Sprintf("endpoints/%d/docker/images/%s/json", endpointID, url.PathEscape(image)), &details); err != nil {
+		if !IsNotFoundError(err) {
+			return "", fmt.Errorf("failed to inspect image: %w", err)
+		}
+		if err := NewImageService(s.client).Pull(endpointID, image, 0); err != nil {
+			return "", err
+		}
+	}
+
+	body := map[string]interface{}{
+		"Image":  image,
+		"Labels": map[string]string{"io.portainer.cli.helper": "volume"},
+		"HostConfig": map[string]interface{}{
+			"Mounts": mounts,
+		},
+	}
+	if len(cmd) > 0 {
+		body["Cmd"] = cmd
+	}
+
+	var created struct {
+		Id string `json:"Id"`
+	}
+	path := fmt.Sprintf("endpoints/%d/docker/containers/create", endpointID)
+	if err := s.client.Post(path, body, &created); err != nil {
+		return "", fmt.Errorf("failed to create helper container: %w", err)
+	}
+	return created.Id, nil
+}
+
+// runHelper starts a helper container and waits for it to exit
+func (s *VolumeService) runHelper(endpointID int, containerID string) error {
+	if containerID == "" {
+		// Dry-run: the container was not created
+		return nil
+	}
+
+	if err := NewContainerService(s.client).Start(endpointID, containerID); err != nil {
+		return fmt.Errorf("failed to start helper container: %w", err)
+	}
+
+	// Copying a large volume can outlast the client timeout, so the wait
+	// request is streamed
+	body, err := s.client.stream(http.MethodPost, fmt.Sprintf("endpoints/%d/docker/containers/%s/wait", endpointID, containerID))
+	if err != nil {
+		return fmt.Errorf("failed to wait for helper container: %w", err)
+	}
+	defer body.Close()
+
+	var result struct {
+		StatusCode int `json:"StatusCode"`
+		Error      *struct {
+			Message string `json:"Message"`
+		} `json:"Error"`
+	}
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode wait response: %w", err)
+	}
+	if result.Error != nil && result.Error.Message != "" {
+		return fmt.Errorf("helper container failed: %s", result.Error.Message)
+	}
+	if result.StatusCode != 0 {
+		return fmt.Errorf("helper container exited with status %d", result.StatusCode)
+	}
+	return nil
+}
+
+// removeHelper removes a helper container. Errors are ignored since the
+// container is labeled and can be cleaned up by hand.
+func (s *VolumeService) removeHelper(endpointID int, containerID string) {
+	if containerID == "" {
+		return
+	}
+	_ = NewContainerService(s.client).Remove(endpointID, containerID, true)
+}
@@ -0,0 +1,236 @@
+package portainer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVolumeService_BrowseDownloadUpload(t *testing.T) {
+	var uploadedPath, uploadedName, uploadedContent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("volumeID") != "data" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/endpoints/1/docker/v2/browse/ls":
+			if r.URL.Query().Get("path") != "/conf" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode([]FileInfo{
+				{Name: "app.conf", Size: 12, ModTime: 1700000000},
+				{Name: "certs", Dir: true},
+			})
+		case "/api/endpoints/1/docker/v2/browse/get":
+			w.Write([]byte("listen 8080\n"))
+		case "/api/endpoints/1/docker/v2/browse/put":
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			defer file.Close()
+			content, _ := io.ReadAll(file)
+			uploadedPath = r.FormValue("Path")
+			uploadedName = header.Filename
+			uploadedContent = string(content)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	volumeService := NewVolumeService(client)
+
+	files, err := volumeService.Browse(1, "data", "/conf")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 2 || files[0].Name != "app.conf" || !files[1].Dir {
+		t.Errorf("unexpected files: %+v", files)
+	}
+
+	body, err := volumeService.Download(1, "data", "/conf/app.conf")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, _ := io.ReadAll(body)
+	body.Close()
+	if string(content) != "listen 8080\n" {
+		t.Errorf("unexpected content %q", content)
+	}
+
+	localPath := filepath.Join(t.TempDir(), "new.conf")
+	if err := os.WriteFile(localPath, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := volumeService.Upload(1, "data", "/conf", localPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if uploadedPath != "/conf" || uploadedName != "new.conf" || uploadedContent != "hello" {
+		t.Errorf("unexpected upload: path=%q name=%q content=%q", uploadedPath, uploadedName, uploadedContent)
+	}
+}
+
+func TestVolumeService_Prune(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/endpoints/1/docker/volumes/prune" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"VolumesDeleted":["data"],"SpaceReclaimed":2048}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	report, err := NewVolumeService(client).Prune(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.VolumesDeleted) != 1 || report.VolumesDeleted[0] != "data" || report.SpaceReclaimed != 2048 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestVolumeService_Clone(t *testing.T) {
+	var requests []string
+	var created VolumeCreateRequest
+	var helper struct {
+		Image      string
+		Cmd        []string
+		HostConfig struct {
+			Mounts []volumeMount
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/endpoints/1/docker/volumes/data":
+			json.NewEncoder(w).Encode(VolumeDetails{Name: "data", Driver: "local", Labels: map[string]string{"team": "ops"}})
+		case "GET /api/endpoints/1/docker/volumes/copy":
+			w.WriteHeader(http.StatusNotFound)
+		case "POST /api/endpoints/1/docker/volumes/create":
+			json.NewDecoder(r.Body).Decode(&created)
+			json.NewEncoder(w).Encode(Volume{Name: created.Name})
+		case "GET /api/endpoints/1/docker/images/busybox:latest/json":
+			json.NewEncoder(w).Encode(ImageDetails{})
+		case "POST /api/endpoints/1/docker/containers/create":
+			json.NewDecoder(r.Body).Decode(&helper)
+			json.NewEncoder(w).Encode(map[string]string{"Id": "helper"})
+		case "POST /api/endpoints/1/docker/containers/helper/start", "DELETE /api/endpoints/1/docker/containers/helper":
+			w.WriteHeader(http.StatusNoContent)
+		case "POST /api/endpoints/1/docker/containers/helper/wait":
+			json.NewEncoder(w).Encode(map[string]int{"StatusCode": 0})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if err := NewVolumeService(client).Clone(1, "data", "copy", VolumeCloneOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if created.Name != "copy" || created.Driver != "local" || created.Labels["team"] != "ops" {
+		t.Errorf("unexpected destination volume: %+v", created)
+	}
+	if helper.Image != DefaultVolumeHelperImage || len(helper.HostConfig.Mounts) != 2 {
+		t.Fatalf("unexpected helper container: %+v", helper)
+	}
+	if from := helper.HostConfig.Mounts[0]; from.Source != "data" || !from.ReadOnly {
+		t.Errorf("unexpected source mount: %+v", from)
+	}
+	if to := helper.HostConfig.Mounts[1]; to.Source != "copy" || to.ReadOnly {
+		t.Errorf("unexpected destination mount: %+v", to)
+	}
+	if last := requests[len(requests)-1]; last != "DELETE /api/endpoints/1/docker/containers/helper" {
+		t.Errorf("expected the helper container to be removed, last request was %s", last)
+	}
+}
+
+func TestVolumeService_BackupRestore(t *testing.T) {
+	var createdVolume string
+	var restored, contentType, archivePath string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/endpoints/1/docker/volumes/data":
+			json.NewEncoder(w).Encode(VolumeDetails{Name: "data"})
+		case "GET /api/endpoints/1/docker/volumes/new":
+			w.WriteHeader(http.StatusNotFound)
+		case "POST /api/endpoints/1/docker/volumes/create":
+			var req VolumeCreateRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			createdVolume = req.Name
+			json.NewEncoder(w).Encode(Volume{Name: req.Name})
+		case "GET /api/endpoints/1/docker/images/busybox:latest/json":
+			json.NewEncoder(w).Encode(ImageDetails{})
+		case "POST /api/endpoints/1/docker/containers/create":
+			json.NewEncoder(w).Encode(map[string]string{"Id": "helper"})
+		case "GET /api/endpoints/1/docker/containers/helper/archive":
+			archivePath = r.URL.Query().Get("path")
+			w.Write([]byte("backup archive"))
+		case "PUT /api/endpoints/1/docker/containers/helper/archive":
+			archivePath = r.URL.Query().Get("path")
+			contentType = r.Header.Get("Content-Type")
+			body, _ := io.ReadAll(r.Body)
+			restored = string(body)
+		case "DELETE /api/endpoints/1/docker/containers/helper":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	volumeService := NewVolumeService(client)
+
+	var backup strings.Builder
+	n, err := volumeService.Backup(1, "data", &backup, VolumeArchiveOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if backup.String() != "backup archive" || n != int64(len("backup archive")) {
+		t.Errorf("unexpected backup %q (%d bytes)", backup.String(), n)
+	}
+	if archivePath != "/volume" {
+		t.Errorf("unexpected archive path: %s", archivePath)
+	}
+
+	if err := volumeService.Restore(1, "new", strings.NewReader("backup archive"), VolumeArchiveOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if createdVolume != "new" {
+		t.Errorf("expected volume new to be created, got %q", createdVolume)
+	}
+	if restored != "backup archive" || contentType != "application/x-tar" || archivePath != "/" {
+		t.Errorf("unexpected restore: %q %s %s", restored, contentType, archivePath)
+	}
+}
@@ -0,0 +1,296 @@
+package portainer
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// WebSocket message types (RFC 6455 opcodes)
+const (
+	WebSocketTextMessage   = 1
+	WebSocketBinaryMessage = 2
+
+	wsOpContinuation = 0
+	wsOpClose        = 8
+	wsOpPing         = 9
+	wsOpPong         = 10
+)
+
+const (
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	// maxWebSocketMessage guards against unbounded allocations from a
+	// malformed frame length
+	maxWebSocketMessage = 64 << 20
+)
+
+// WebSocketConn is a client websocket connection. It implements io.Reader
+// over the payload of incoming messages and io.Writer sending each write as
+// a binary message, so it can be used with io.Copy.
+type WebSocketConn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	writeMu sync.Mutex
+	pending []byte
+}
+
+// DialWebSocket opens a websocket to a Portainer API path such as
+// "websocket/exec?endpointId=1&id=...". It uses the client's TLS settings
+// and authentication. In dry-run mode the handshake request is printed and
+// a nil connection is returned.
+func (c *Client) DialWebSocket(path string) (*WebSocketConn, error) {
+	req, err := c.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes := make([]byte, 16)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate websocket key: %w", err)
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	if c.dryRun {
+		fmt.Fprintln(c.logOutput, c.generateCurlCommand(req))
+		return nil, nil
+	}
+
+	if c.verbose {
+		fmt.Fprintf(c.logOutput, "%s %s (websocket, request ID %s)\n", req.Method, req.URL.String(), RequestID(req))
+	}
+
+	conn, err := c.dial(req.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer conn.Close()
+		if err := checkResponse(resp); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("websocket handshake failed: HTTP %d", resp.StatusCode)
+	}
+
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != websocketAccept(key) {
+		conn.Close()
+		return nil, fmt.Errorf("websocket handshake failed: invalid upgrade response")
+	}
+
+	return &WebSocketConn{conn: conn, reader: reader}, nil
+}
+
+func websocketAccept(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// ReadMessage returns the next data message. Pings are answered
+// automatically; io.EOF is returned once the server closes the connection.
+func (ws *WebSocketConn) ReadMessage() (int, []byte, error) {
+	var messageType int
+	var message []byte
+
+	for {
+		fin, opcode, payload, err := readWebSocketFrame(ws.reader)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case wsOpPing:
+			if err := ws.writeFrame(wsOpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			_ = ws.writeFrame(wsOpClose, payload)
+			return 0, nil, io.EOF
+		case wsOpContinuation:
+			if messageType == 0 {
+				return 0, nil, fmt.Errorf("unexpected websocket continuation frame")
+			}
+		case WebSocketTextMessage, WebSocketBinaryMessage:
+			if messageType != 0 {
+				return 0, nil, fmt.Errorf("unexpected websocket frame in fragmented message")
+			}
+			messageType = int(opcode)
+		default:
+			return 0, nil, fmt.Errorf("unsupported websocket opcode %d", opcode)
+		}
+
+		if len(message)+len(payload) > maxWebSocketMessage {
+			return 0, nil, fmt.Errorf("websocket message too large")
+		}
+		message = append(message, payload...)
+
+		if fin {
+			return messageType, message, nil
+		}
+	}
+}
+
+// WriteMessage sends a text or binary message
+func (ws *WebSocketConn) WriteMessage(messageType int, data []byte) error {
+	if messageType != WebSocketTextMessage && messageType != WebSocketBinaryMessage {
+		return fmt.Errorf("invalid websocket message type %d", messageType)
+	}
+	return ws.writeFrame(byte(messageType), data)
+}
+
+func (ws *WebSocketConn) Read(p []byte) (int, error) {
+	for len(ws.pending) == 0 {
+		_, message, err := ws.ReadMessage()
+		if err != nil {
+			return 0, err
+		}
+		ws.pending = message
+	}
+
+	n := copy(p, ws.pending)
+	ws.pending = ws.pending[n:]
+	return n, nil
+}
+
+func (ws *WebSocketConn) Write(p []byte) (int, error) {
+	if err := ws.WriteMessage(WebSocketBinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close sends a normal closure frame and closes the connection
+func (ws *WebSocketConn) Close() error {
+	closePayload := make([]byte, 2)
+	binary.BigEndian.PutUint16(closePayload, 1000)
+	_ = ws.writeFrame(wsOpClose, closePayload)
+	return ws.conn.Close()
+}
+
+func (ws *WebSocketConn) writeFrame(opcode byte, payload []byte) error {
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+
+	// Client frames must be masked
+	return writeWebSocketFrame(ws.conn, opcode, payload, true)
+}
+
+func writeWebSocketFrame(w io.Writer, opcode byte, payload []byte, mask bool) error {
+	header := []byte{0x80 | opcode}
+
+	maskBit := byte(0)
+	if mask {
+		maskBit = 0x80
+	}
+
+	length := len(payload)
+	switch {
+	case length < 126:
+		header = append(header, maskBit|byte(length))
+	case length <= 0xFFFF:
+		header = append(header, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header = append(header, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+
+	data := payload
+	if mask {
+		key := make([]byte, 4)
+		if _, err := rand.Read(key); err != nil {
+			return fmt.Errorf("failed to generate websocket mask: %w", err)
+		}
+		header = append(header, key...)
+
+		data = make([]byte, length)
+		for i := range payload {
+			data[i] = payload[i] ^ key[i%4]
+		}
+	}
+
+	if _, err := w.Write(append(header, data...)); err != nil {
+		return fmt.Errorf("failed to write websocket frame: %w", err)
+	}
+	return nil
+}
+
+func readWebSocketFrame(r *bufio.Reader) (bool, byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+
+	if length > maxWebSocketMessage {
+		return false, 0, nil, fmt.Errorf("websocket frame too large")
+	}
+
+	var key []byte
+	if masked {
+		key = make([]byte, 4)
+		if _, err := io.ReadFull(r, key); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= key[i%4]
+		}
+	}
+
+	return fin, opcode, payload, nil
+}
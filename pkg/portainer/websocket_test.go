@@ -0,0 +1,104 @@
+package portainer
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// echoWebSocketServer upgrades requests and echoes each message back in two
+// fragments, preceded by a ping
+func echoWebSocketServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-KEY") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		hijacker := w.(http.Hijacker)
+		conn, rw, err := hijacker.Hijack()
+		if err != nil {
+			t.Errorf("failed to hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+		rw.WriteString("Upgrade: websocket\r\nConnection: Upgrade\r\n")
+		rw.WriteString("Sec-WebSocket-Accept: " + websocketAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		rw.Flush()
+
+		reader := bufio.NewReader(conn)
+		for {
+			_, opcode, payload, err := readWebSocketFrame(reader)
+			if err != nil || opcode == wsOpClose {
+				return
+			}
+			if opcode == wsOpPong {
+				continue
+			}
+
+			writeWebSocketFrame(conn, wsOpPing, []byte("hi"), false)
+			half := len(payload) / 2
+			conn.Write([]byte{opcode, byte(half)})
+			conn.Write(payload[:half])
+			writeWebSocketFrame(conn, wsOpContinuation, payload[half:], false)
+		}
+	}))
+}
+
+func TestDialWebSocket(t *testing.T) {
+	server := echoWebSocketServer(t)
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ws, err := client.DialWebSocket("websocket/exec?endpointId=1&id=abc")
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer ws.Close()
+
+	if err := ws.WriteMessage(WebSocketTextMessage, []byte("hello world")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	messageType, message, err := ws.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if messageType != WebSocketTextMessage || string(message) != "hello world" {
+		t.Errorf("expected text 'hello world', got type %d %q", messageType, message)
+	}
+
+	if _, err := ws.Write([]byte("ls\n")); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	buf := make([]byte, 2)
+	n, err := ws.Read(buf)
+	if err != nil || string(buf[:n]) != "ls" {
+		t.Errorf("expected 'ls', got %q (%v)", buf[:n], err)
+	}
+	n, err = ws.Read(buf)
+	if err != nil || string(buf[:n]) != "\n" {
+		t.Errorf("expected newline, got %q (%v)", buf[:n], err)
+	}
+}
+
+func TestDialWebSocket_Unauthorized(t *testing.T) {
+	server := echoWebSocketServer(t)
+	defer server.Close()
+
+	client, err := NewClient(&Config{URL: server.URL, APIKey: "wrong"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	if _, err := client.DialWebSocket("websocket/exec"); !IsUnauthorizedError(err) {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+}